@@ -357,6 +357,36 @@ const pkgs = {
         ImportKey: {
             path: "/kms/import",
             method: "POST",
+        },
+        ListKeys: {
+            path: "/kms/keys",
+            method: "GET",
+        },
+        DescribeKey: {
+            path: "/kms/keys/{keyID}",
+            method: "GET",
+            pathParam:"keyID"
+        },
+        DeleteKey: {
+            path: "/kms/keys/{keyID}/delete",
+            method: "POST",
+            pathParam:"keyID"
+        },
+        BackupKeys: {
+            path: "/kms/backup",
+            method: "POST",
+        },
+        RestoreKeys: {
+            path: "/kms/restore",
+            method: "POST",
+        },
+        RotateMasterKey: {
+            path: "/kms/masterkey/rotate",
+            method: "POST",
+        },
+        ReEncryptKeys: {
+            path: "/kms/masterkey/reencrypt",
+            method: "POST",
         }
     },
     legacykms: {
@@ -364,6 +394,16 @@ const pkgs = {
             path: "/legacykms/keyset",
             method: "POST",
         }
+    },
+    export: {
+        Export: {
+            path: "/data/export",
+            method: "POST",
+        },
+        Import: {
+            path: "/data/import",
+            method: "POST",
+        }
     }
 }
 
@@ -986,6 +986,73 @@ const Aries = function (opts) {
             importKey: async function (req) {
                 return invoke(aw, pending, this.pkgname, "ImportKey", req, "timeout while importing key")
             },
+
+            /**
+             * Lists the metadata of all keys.
+             *
+             * @returns {Promise<Object>}
+             */
+            listKeys: async function () {
+                return invoke(aw, pending, this.pkgname, "ListKeys", {}, "timeout while listing keys")
+            },
+
+            /**
+             * Describes a key.
+             *
+             * @param req - json document containing the key ID.
+             * @returns {Promise<Object>}
+             */
+            describeKey: async function (req) {
+                return invoke(aw, pending, this.pkgname, "DescribeKey", req, "timeout while describing key")
+            },
+
+            /**
+             * Deletes a key.
+             *
+             * @param req - json document containing the key ID.
+             * @returns {Promise<Object>}
+             */
+            deleteKey: async function (req) {
+                return invoke(aw, pending, this.pkgname, "DeleteKey", req, "timeout while deleting key")
+            },
+
+            /**
+             * Exports all keys into an archive encrypted with a key derived from the given passphrase.
+             *
+             * @param req - json document containing passphrase and includeLegacyKeys flag.
+             * @returns {Promise<Object>}
+             */
+            backupKeys: async function (req) {
+                return invoke(aw, pending, this.pkgname, "BackupKeys", req, "timeout while backing up keys")
+            },
+
+            /**
+             * Restores the keys of an archive created by backupKeys under their original key IDs.
+             *
+             * @param req - json document containing archive, passphrase, includeLegacyKeys and dryRun flags.
+             * @returns {Promise<Object>}
+             */
+            restoreKeys: async function (req) {
+                return invoke(aw, pending, this.pkgname, "RestoreKeys", req, "timeout while restoring keys")
+            },
+
+            /**
+             * Rotates the master key of the secret lock and re-encrypts all keys with the new master key.
+             *
+             * @returns {Promise<Object>}
+             */
+            rotateMasterKey: async function () {
+                return invoke(aw, pending, this.pkgname, "RotateMasterKey", {}, "timeout while rotating master key")
+            },
+
+            /**
+             * Re-encrypts all keys with the current master key of the secret lock.
+             *
+             * @returns {Promise<Object>}
+             */
+            reEncryptKeys: async function () {
+                return invoke(aw, pending, this.pkgname, "ReEncryptKeys", {}, "timeout while re-encrypting keys")
+            },
         },
 
         /**
@@ -1003,6 +1070,35 @@ const Aries = function (opts) {
             createKeySet: async function () {
                 return invoke(aw, pending, this.pkgname, "CreateKeySet", {}, "timeout while creating key set")
             },
+        },
+
+        /**
+         * Agent data export and import - Refer to [OpenAPI spec](docs/rest/openapi_spec.md#generate-openapi-spec) for
+         * input params and output return json values.
+         */
+        export: {
+            pkgname: "export",
+
+            /**
+             * Exports the connections, DIDs, credentials, protocol states and keys of the agent into an archive
+             * encrypted with a key derived from the given passphrase.
+             *
+             * @param req - json document containing passphrase and withoutKeys flag.
+             * @returns {Promise<Object>}
+             */
+            export: async function (req) {
+                return invoke(aw, pending, this.pkgname, "Export", req, "timeout while exporting agent data")
+            },
+
+            /**
+             * Imports the records and keys of an archive created by export into the agent.
+             *
+             * @param req - json document containing archive, passphrase, withoutKeys and dryRun flags.
+             * @returns {Promise<Object>}
+             */
+            import: async function (req) {
+                return invoke(aw, pending, this.pkgname, "Import", req, "timeout while importing agent data")
+            },
         }
     }
 
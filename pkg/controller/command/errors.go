@@ -64,6 +64,9 @@ const (
 
 	// Outofband error group for outofband command errors
 	Outofband = 11000
+
+	// Export error group for agent data export and import command errors
+	Export = 12000
 )
 
 // Error is the  interface for representing an command error condition, with the nil value representing no error.
@@ -0,0 +1,155 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/logutil"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	storeexport "github.com/hyperledger/aries-framework-go/pkg/store/export"
+)
+
+var logger = log.New("aries-framework/command/export")
+
+// Error codes
+const (
+	// InvalidRequestErrorCode is typically a code for invalid requests
+	InvalidRequestErrorCode = command.Code(iota + command.Export)
+	// ExportError is for failures while exporting agent data
+	ExportError
+	// ImportError is for failures while importing agent data
+	ImportError
+)
+
+const (
+	// command name
+	commandName = "export"
+
+	// command methods
+	exportCommandMethod = "Export"
+	importCommandMethod = "Import"
+
+	// error messages
+	errEmptyPass    = "passphrase is mandatory"
+	errEmptyArchive = "archive is mandatory"
+)
+
+// provider contains dependencies for the export command and is typically created by using aries.Context().
+type provider interface {
+	StorageProvider() storage.Provider
+	TransientStorageProvider() storage.Provider
+	KMS() kms.KeyManager
+}
+
+// Command contains command operations provided by export controller.
+type Command struct {
+	ctx  provider
+	opts []storeexport.Option
+}
+
+// New returns new export command instance. The options (typically storeexport.WithStores) are applied to every
+// export and import.
+func New(p provider, opts ...storeexport.Option) *Command {
+	return &Command{ctx: p, opts: opts}
+}
+
+// GetHandlers returns list of all commands supported by this controller command.
+func (o *Command) GetHandlers() []command.Handler {
+	return []command.Handler{
+		cmdutil.NewCommandHandler(commandName, exportCommandMethod, o.Export),
+		cmdutil.NewCommandHandler(commandName, importCommandMethod, o.Import),
+	}
+}
+
+// Export exports the connections, DIDs, credentials, protocol states and KMS keys of the agent into an archive
+// encrypted with a key derived from the given passphrase.
+func (o *Command) Export(rw io.Writer, req io.Reader) command.Error {
+	var request ExportRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, exportCommandMethod, err.Error())
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("failed request decode : %w", err))
+	}
+
+	if request.Passphrase == "" {
+		logutil.LogDebug(logger, commandName, exportCommandMethod, errEmptyPass)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyPass))
+	}
+
+	opts := o.options(request.WithoutKeys, false)
+
+	archive, err := storeexport.Export(o.ctx, []byte(request.Passphrase), opts...)
+	if err != nil {
+		logutil.LogError(logger, commandName, exportCommandMethod, err.Error())
+		return command.NewExecuteError(ExportError, err)
+	}
+
+	command.WriteNillableResponse(rw, &ExportResponse{Archive: archive}, logger)
+
+	logutil.LogDebug(logger, commandName, exportCommandMethod, "success")
+
+	return nil
+}
+
+// Import imports the records and keys of an archive created by Export into the stores and the KMS of the agent.
+// In dry run mode the archive is decrypted and checked but nothing is written.
+func (o *Command) Import(rw io.Writer, req io.Reader) command.Error {
+	var request ImportRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, importCommandMethod, err.Error())
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("failed request decode : %w", err))
+	}
+
+	if len(request.Archive) == 0 {
+		logutil.LogDebug(logger, commandName, importCommandMethod, errEmptyArchive)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyArchive))
+	}
+
+	if request.Passphrase == "" {
+		logutil.LogDebug(logger, commandName, importCommandMethod, errEmptyPass)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyPass))
+	}
+
+	opts := o.options(request.WithoutKeys, request.DryRun)
+
+	result, err := storeexport.Import(o.ctx, request.Archive, []byte(request.Passphrase), opts...)
+	if err != nil {
+		logutil.LogError(logger, commandName, importCommandMethod, err.Error())
+		return command.NewExecuteError(ImportError, err)
+	}
+
+	command.WriteNillableResponse(rw, &ImportResponse{ImportResult: *result}, logger)
+
+	logutil.LogDebug(logger, commandName, importCommandMethod, "success",
+		logutil.CreateKeyValueString("dryRun", fmt.Sprint(request.DryRun)))
+
+	return nil
+}
+
+func (o *Command) options(withoutKeys, dryRun bool) []storeexport.Option {
+	opts := append([]storeexport.Option{}, o.opts...)
+
+	if withoutKeys {
+		opts = append(opts, storeexport.WithoutKeys())
+	}
+
+	if dryRun {
+		opts = append(opts, storeexport.WithDryRun())
+	}
+
+	return opts
+}
@@ -0,0 +1,189 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	storeexport "github.com/hyperledger/aries-framework-go/pkg/store/export"
+)
+
+func TestNew(t *testing.T) {
+	cmd := New(&mockprovider.Provider{})
+	require.NotNil(t, cmd)
+
+	handlers := cmd.GetHandlers()
+	require.Equal(t, 2, len(handlers))
+}
+
+func TestExportImport(t *testing.T) {
+	src := newProvider(t)
+
+	store, err := src.StorageProviderValue.OpenStore("custom")
+	require.NoError(t, err)
+	require.NoError(t, store.Put("k", []byte("v")))
+
+	keyID, _, err := src.KMSValue.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	cmd := New(src, storeexport.WithStores(storeexport.Store{Name: "custom"}))
+
+	var rw bytes.Buffer
+	cmdErr := cmd.Export(&rw, bytes.NewBufferString(`{"passphrase":"secret"}`))
+	require.NoError(t, cmdErr)
+
+	exportRes := ExportResponse{}
+	require.NoError(t, json.NewDecoder(&rw).Decode(&exportRes))
+	require.NotEmpty(t, exportRes.Archive)
+
+	t.Run("test import - success", func(t *testing.T) {
+		dst := newProvider(t)
+		cmd := New(dst, storeexport.WithStores(storeexport.Store{Name: "custom"}))
+
+		request, err := json.Marshal(&ImportRequest{Archive: exportRes.Archive, Passphrase: "secret", DryRun: true})
+		require.NoError(t, err)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.Import(&rw, bytes.NewBuffer(request))
+		require.NoError(t, cmdErr)
+
+		response := ImportResponse{}
+		require.NoError(t, json.NewDecoder(&rw).Decode(&response))
+		require.True(t, response.DryRun)
+		require.Equal(t, []string{keyID}, response.Keys.KeyIDs)
+		require.Contains(t, response.Stores, &storeexport.StoreResult{Name: "custom", Records: 1})
+
+		_, err = dst.KMSValue.Get(keyID)
+		require.Error(t, err)
+
+		request, err = json.Marshal(&ImportRequest{Archive: exportRes.Archive, Passphrase: "secret"})
+		require.NoError(t, err)
+
+		rw.Reset()
+		cmdErr = cmd.Import(&rw, bytes.NewBuffer(request))
+		require.NoError(t, cmdErr)
+
+		response = ImportResponse{}
+		require.NoError(t, json.NewDecoder(&rw).Decode(&response))
+		require.False(t, response.DryRun)
+
+		_, err = dst.KMSValue.Get(keyID)
+		require.NoError(t, err)
+
+		dstStore, err := dst.StorageProviderValue.OpenStore("custom")
+		require.NoError(t, err)
+
+		v, err := dstStore.Get("k")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v"), v)
+	})
+
+	t.Run("test export and import - without keys", func(t *testing.T) {
+		p := newProvider(t)
+		p.KMSValue = &mockkms.KeyManager{}
+
+		cmd := New(p)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.Export(&rw, bytes.NewBufferString(`{"passphrase":"secret","withoutKeys":true}`))
+		require.NoError(t, cmdErr)
+
+		request, err := json.Marshal(&ImportRequest{Archive: exportRes.Archive, Passphrase: "secret",
+			WithoutKeys: true})
+		require.NoError(t, err)
+
+		rw.Reset()
+		cmdErr = cmd.Import(&rw, bytes.NewBuffer(request))
+		require.NoError(t, cmdErr)
+
+		response := ImportResponse{}
+		require.NoError(t, json.NewDecoder(&rw).Decode(&response))
+		require.Nil(t, response.Keys)
+	})
+
+	t.Run("test export and import - validation errors", func(t *testing.T) {
+		var rw bytes.Buffer
+		cmdErr := cmd.Export(&rw, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.Export(&rw, bytes.NewBufferString("{}"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errEmptyPass)
+
+		cmdErr = cmd.Import(&rw, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.Import(&rw, bytes.NewBufferString("{}"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errEmptyArchive)
+
+		cmdErr = cmd.Import(&rw, bytes.NewBufferString(`{"archive":{}}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errEmptyPass)
+	})
+
+	t.Run("test export and import - errors", func(t *testing.T) {
+		request, err := json.Marshal(&ImportRequest{Archive: exportRes.Archive, Passphrase: "wrong"})
+		require.NoError(t, err)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.Import(&rw, bytes.NewBuffer(request))
+		require.Error(t, cmdErr)
+		require.Equal(t, ImportError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "invalid passphrase or corrupted archive")
+
+		cmd := New(&mockprovider.Provider{
+			KMSValue:             &mockkms.KeyManager{},
+			StorageProviderValue: &mockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("open store error")},
+		})
+
+		cmdErr = cmd.Export(&rw, bytes.NewBufferString(`{"passphrase":"secret"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, ExportError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "open store error")
+
+		request, err = json.Marshal(&ImportRequest{Archive: exportRes.Archive, Passphrase: "secret"})
+		require.NoError(t, err)
+
+		cmdErr = cmd.Import(&rw, bytes.NewBuffer(request))
+		require.Error(t, cmdErr)
+		require.Equal(t, ImportError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "kms does not support key backup and restore")
+	})
+}
+
+func newProvider(t *testing.T) *mockprovider.Provider {
+	t.Helper()
+
+	storeProvider := mem.NewProvider()
+
+	km, err := localkms.New("local-lock://custom/master/key/", mockkms.NewProviderForKMS(storeProvider, &noop.NoLock{}))
+	require.NoError(t, err)
+
+	return &mockprovider.Provider{
+		KMSValue:                      km,
+		StorageProviderValue:          storeProvider,
+		TransientStorageProviderValue: mem.NewProvider(),
+	}
+}
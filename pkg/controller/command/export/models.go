@@ -0,0 +1,43 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package export
+
+import (
+	"encoding/json"
+
+	storeexport "github.com/hyperledger/aries-framework-go/pkg/store/export"
+)
+
+// ExportRequest is model for export agent data request.
+type ExportRequest struct {
+	// Passphrase used to derive the archive encryption key
+	Passphrase string `json:"passphrase,omitempty"`
+	// WithoutKeys leaves the keys of the KMS out of the archive
+	WithoutKeys bool `json:"withoutKeys,omitempty"`
+}
+
+// ExportResponse for returning the encrypted agent data archive
+type ExportResponse struct {
+	Archive json.RawMessage `json:"archive"`
+}
+
+// ImportRequest is model for import agent data request.
+type ImportRequest struct {
+	// Archive as returned by export
+	Archive json.RawMessage `json:"archive,omitempty"`
+	// Passphrase used when creating the archive
+	Passphrase string `json:"passphrase,omitempty"`
+	// WithoutKeys ignores the keys of the KMS found in the archive
+	WithoutKeys bool `json:"withoutKeys,omitempty"`
+	// DryRun checks the archive and reports the records and keys to import without writing them
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// ImportResponse for returning the imported stores and keys
+type ImportResponse struct {
+	storeexport.ImportResult
+}
@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
@@ -20,6 +21,8 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/internal/logutil"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
 var logger = log.New("aries-framework/command/kms")
@@ -32,6 +35,29 @@ const (
 	CreateKeySetError
 	// ImportKeyError is for failures while importing key
 	ImportKeyError
+	// ListKeysError is for failures while listing keys
+	ListKeysError
+	// DescribeKeyError is for failures while describing key
+	DescribeKeyError
+	// DeleteKeyError is for failures while deleting key
+	DeleteKeyError
+	// BackupKeysError is for failures while backing up keys
+	BackupKeysError
+	// RestoreKeysError is for failures while restoring keys
+	RestoreKeysError
+	// RotateMasterKeyError is for failures while rotating the master key
+	RotateMasterKeyError
+	// ExportKeyError is for failures while exporting public key
+	ExportKeyError
+)
+
+const (
+	// MasterKeyStoreNamespace is the store namespace of the master keys persisted by RotateMasterKey.
+	MasterKeyStoreNamespace = "kmsmasterkey"
+	// MasterKeyStoreKey is the store key of the current master key in the MasterKeyStoreNamespace store.
+	MasterKeyStoreKey = "masterkey"
+	// PreviousMasterKeyStoreKey is the store key of the master key replaced by the last rotation.
+	PreviousMasterKeyStoreKey = "previousmasterkey"
 )
 
 const (
@@ -44,16 +70,42 @@ const (
 	// command methods
 	createKeySetCommandMethod = "CreateKeySet"
 	importKeyCommandMethod    = "ImportKey"
+	exportKeyCommandMethod    = "ExportKey"
+	listKeysCommandMethod     = "ListKeys"
+	describeKeyCommandMethod  = "DescribeKey"
+	deleteKeyCommandMethod    = "DeleteKey"
+	backupKeysCommandMethod   = "BackupKeys"
+	restoreKeysCommandMethod  = "RestoreKeys"
+	rotateMasterKeyMethod     = "RotateMasterKey"
+	reEncryptKeysMethod       = "ReEncryptKeys"
 
 	// error messages
 	errEmptyKeyType = "key type is mandatory"
 	errEmptyKeyID   = "key id is mandatory"
+	errNoInventory  = "kms does not support key listing, describing and deleting"
+	errEmptyPass    = "passphrase is mandatory"
+	errEmptyArchive = "archive is mandatory"
+	errNoBackup     = "kms does not support key backup and restore"
+	errNoRotation   = "kms does not support master key rotation"
 )
 
 // provider contains dependencies for the kms command and is typically created by using aries.Context().
 type provider interface {
 	KMS() kms.KeyManager
 	LegacyKMS() legacykms.KeyManager
+	StorageProvider() storage.Provider
+}
+
+// masterKeyRotation is implemented by KMS supporting the rotation of the master key of their secret lock.
+type masterKeyRotation interface {
+	RotateMasterKey(persist func(protectedMasterKey []byte) error, opts ...localkms.ReEncryptOpt) (int, error)
+	ReEncryptKeys(opts ...localkms.ReEncryptOpt) (int, error)
+}
+
+// keyBackup is implemented by KMS supporting passphrase protected backup and restore of their keys.
+type keyBackup interface {
+	BackupKeys(passphrase []byte, opts ...localkms.BackupOpt) ([]byte, error)
+	RestoreKeys(archive, passphrase []byte, opts ...localkms.BackupOpt) (*localkms.RestoreResult, error)
 }
 
 // Command contains command operations provided by verifiable credential controller.
@@ -83,6 +135,14 @@ func (o *Command) GetHandlers() []command.Handler {
 	return []command.Handler{
 		cmdutil.NewCommandHandler(commandName, createKeySetCommandMethod, o.CreateKeySet),
 		cmdutil.NewCommandHandler(commandName, importKeyCommandMethod, o.ImportKey),
+		cmdutil.NewCommandHandler(commandName, exportKeyCommandMethod, o.ExportKey),
+		cmdutil.NewCommandHandler(commandName, listKeysCommandMethod, o.ListKeys),
+		cmdutil.NewCommandHandler(commandName, describeKeyCommandMethod, o.DescribeKey),
+		cmdutil.NewCommandHandler(commandName, deleteKeyCommandMethod, o.DeleteKey),
+		cmdutil.NewCommandHandler(commandName, backupKeysCommandMethod, o.BackupKeys),
+		cmdutil.NewCommandHandler(commandName, restoreKeysCommandMethod, o.RestoreKeys),
+		cmdutil.NewCommandHandler(commandName, rotateMasterKeyMethod, o.RotateMasterKey),
+		cmdutil.NewCommandHandler(commandName, reEncryptKeysMethod, o.ReEncryptKeys),
 		cmdutil.NewCommandHandler(legacyKMSCommandName, createKeySetCommandMethod, o.CreateKeySetLegacyKMS),
 	}
 }
@@ -142,7 +202,8 @@ func (o *Command) CreateKeySet(rw io.Writer, req io.Reader) command.Error {
 	return nil
 }
 
-// ImportKey import key
+// ImportKey imports a private key given as JWK with the key ID set to the JWK kid.
+// Supported curves are Ed25519, P-256, P-384 and secp256k1.
 func (o *Command) ImportKey(rw io.Writer, req io.Reader) command.Error {
 	buf := new(bytes.Buffer)
 	_, err := buf.ReadFrom(req)
@@ -163,27 +224,343 @@ func (o *Command) ImportKey(rw io.Writer, req io.Reader) command.Error {
 		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyKeyID))
 	}
 
-	var kType kms.KeyType
-
-	switch jwk.Crv {
-	case "Ed25519":
-		kType = kms.ED25519Type
-	case "P-256":
-		kType = kms.ECDSAP256TypeIEEEP1363
-	default:
+	kType, err := kms.JWKKeyType(&jwk)
+	if err != nil {
+		logutil.LogDebug(logger, commandName, importKeyCommandMethod, err.Error())
 		return command.NewValidationError(InvalidRequestErrorCode,
 			fmt.Errorf("import key type not supported %s", jwk.Crv))
 	}
 
-	_, _, err = o.importKey(jwk.Key, kType, kms.WithKeyID(jwk.KeyID))
+	keyID, _, err := o.importKey(jwk.Key, kType, kms.WithKeyID(jwk.KeyID))
 	if err != nil {
 		logutil.LogError(logger, commandName, importKeyCommandMethod, err.Error())
 		return command.NewExecuteError(ImportKeyError, err)
 	}
 
-	command.WriteNillableResponse(rw, nil, logger)
+	command.WriteNillableResponse(rw, &ImportKeyResponse{KeyID: keyID}, logger)
 
 	logutil.LogDebug(logger, commandName, importKeyCommandMethod, "success")
 
 	return nil
 }
+
+// ExportKey exports the public key of a key managed by the KMS as JWK with the kid set to the key ID.
+func (o *Command) ExportKey(rw io.Writer, req io.Reader) command.Error {
+	var request ExportKeyRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, exportKeyCommandMethod, err.Error())
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("failed request decode : %w", err))
+	}
+
+	if request.KeyID == "" {
+		logutil.LogDebug(logger, commandName, exportKeyCommandMethod, errEmptyKeyID)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyKeyID))
+	}
+
+	jwk, err := kms.ExportPubKeyJWK(o.ctx.KMS(), request.KeyID, kms.KeyType(request.KeyType))
+	if err != nil {
+		logutil.LogError(logger, commandName, exportKeyCommandMethod, err.Error(),
+			logutil.CreateKeyValueString("keyID", request.KeyID))
+		return command.NewExecuteError(ExportKeyError, err)
+	}
+
+	command.WriteNillableResponse(rw, &ExportKeyResponse{PublicKey: jwk}, logger)
+
+	logutil.LogDebug(logger, commandName, exportKeyCommandMethod, "success",
+		logutil.CreateKeyValueString("keyID", request.KeyID))
+
+	return nil
+}
+
+// ListKeys lists the metadata of all keys managed by the KMS.
+func (o *Command) ListKeys(rw io.Writer, req io.Reader) command.Error {
+	inventory, err := o.keyInventory()
+	if err != nil {
+		logutil.LogError(logger, commandName, listKeysCommandMethod, err.Error())
+		return command.NewExecuteError(ListKeysError, err)
+	}
+
+	keys, err := inventory.List()
+	if err != nil {
+		logutil.LogError(logger, commandName, listKeysCommandMethod, err.Error())
+		return command.NewExecuteError(ListKeysError, err)
+	}
+
+	command.WriteNillableResponse(rw, &ListKeysResponse{Keys: keys}, logger)
+
+	logutil.LogDebug(logger, commandName, listKeysCommandMethod, "success")
+
+	return nil
+}
+
+// DescribeKey returns the metadata of a key managed by the KMS.
+func (o *Command) DescribeKey(rw io.Writer, req io.Reader) command.Error {
+	var request KeyIDRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, describeKeyCommandMethod, err.Error())
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("failed request decode : %w", err))
+	}
+
+	if request.KeyID == "" {
+		logutil.LogDebug(logger, commandName, describeKeyCommandMethod, errEmptyKeyID)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyKeyID))
+	}
+
+	inventory, err := o.keyInventory()
+	if err != nil {
+		logutil.LogError(logger, commandName, describeKeyCommandMethod, err.Error())
+		return command.NewExecuteError(DescribeKeyError, err)
+	}
+
+	md, err := inventory.Describe(request.KeyID)
+	if err != nil {
+		logutil.LogError(logger, commandName, describeKeyCommandMethod, err.Error(),
+			logutil.CreateKeyValueString("keyID", request.KeyID))
+		return command.NewExecuteError(DescribeKeyError, err)
+	}
+
+	command.WriteNillableResponse(rw, &DescribeKeyResponse{Key: md}, logger)
+
+	logutil.LogDebug(logger, commandName, describeKeyCommandMethod, "success",
+		logutil.CreateKeyValueString("keyID", request.KeyID))
+
+	return nil
+}
+
+// DeleteKey deletes a key managed by the KMS.
+func (o *Command) DeleteKey(rw io.Writer, req io.Reader) command.Error {
+	var request KeyIDRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, deleteKeyCommandMethod, err.Error())
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("failed request decode : %w", err))
+	}
+
+	if request.KeyID == "" {
+		logutil.LogDebug(logger, commandName, deleteKeyCommandMethod, errEmptyKeyID)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyKeyID))
+	}
+
+	inventory, err := o.keyInventory()
+	if err != nil {
+		logutil.LogError(logger, commandName, deleteKeyCommandMethod, err.Error())
+		return command.NewExecuteError(DeleteKeyError, err)
+	}
+
+	err = inventory.Delete(request.KeyID)
+	if err != nil {
+		logutil.LogError(logger, commandName, deleteKeyCommandMethod, err.Error(),
+			logutil.CreateKeyValueString("keyID", request.KeyID))
+		return command.NewExecuteError(DeleteKeyError, err)
+	}
+
+	command.WriteNillableResponse(rw, nil, logger)
+
+	logutil.LogDebug(logger, commandName, deleteKeyCommandMethod, "success",
+		logutil.CreateKeyValueString("keyID", request.KeyID))
+
+	return nil
+}
+
+// BackupKeys exports all keys managed by the KMS (and optionally legacy KMS key pairs) into an archive
+// encrypted with a key derived from the given passphrase.
+func (o *Command) BackupKeys(rw io.Writer, req io.Reader) command.Error {
+	var request BackupKeysRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, backupKeysCommandMethod, err.Error())
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("failed request decode : %w", err))
+	}
+
+	if request.Passphrase == "" {
+		logutil.LogDebug(logger, commandName, backupKeysCommandMethod, errEmptyPass)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyPass))
+	}
+
+	backup, opts, err := o.keyBackup(request.IncludeLegacyKeys)
+	if err != nil {
+		logutil.LogError(logger, commandName, backupKeysCommandMethod, err.Error())
+		return command.NewExecuteError(BackupKeysError, err)
+	}
+
+	archive, err := backup.BackupKeys([]byte(request.Passphrase), opts...)
+	if err != nil {
+		logutil.LogError(logger, commandName, backupKeysCommandMethod, err.Error())
+		return command.NewExecuteError(BackupKeysError, err)
+	}
+
+	command.WriteNillableResponse(rw, &BackupKeysResponse{Archive: archive}, logger)
+
+	logutil.LogDebug(logger, commandName, backupKeysCommandMethod, "success")
+
+	return nil
+}
+
+// RestoreKeys restores the keys of an archive created by BackupKeys under their original key IDs.
+// Keys already present in the KMS are skipped. In dry run mode the archive is decrypted and checked
+// but nothing is written.
+func (o *Command) RestoreKeys(rw io.Writer, req io.Reader) command.Error {
+	var request RestoreKeysRequest
+
+	err := json.NewDecoder(req).Decode(&request)
+	if err != nil {
+		logutil.LogInfo(logger, commandName, restoreKeysCommandMethod, err.Error())
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("failed request decode : %w", err))
+	}
+
+	if len(request.Archive) == 0 {
+		logutil.LogDebug(logger, commandName, restoreKeysCommandMethod, errEmptyArchive)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyArchive))
+	}
+
+	if request.Passphrase == "" {
+		logutil.LogDebug(logger, commandName, restoreKeysCommandMethod, errEmptyPass)
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyPass))
+	}
+
+	backup, opts, err := o.keyBackup(request.IncludeLegacyKeys)
+	if err != nil {
+		logutil.LogError(logger, commandName, restoreKeysCommandMethod, err.Error())
+		return command.NewExecuteError(RestoreKeysError, err)
+	}
+
+	if request.DryRun {
+		opts = append(opts, localkms.WithDryRun())
+	}
+
+	result, err := backup.RestoreKeys(request.Archive, []byte(request.Passphrase), opts...)
+	if err != nil {
+		logutil.LogError(logger, commandName, restoreKeysCommandMethod, err.Error())
+		return command.NewExecuteError(RestoreKeysError, err)
+	}
+
+	command.WriteNillableResponse(rw, &RestoreKeysResponse{RestoreResult: *result}, logger)
+
+	logutil.LogDebug(logger, commandName, restoreKeysCommandMethod, "success",
+		logutil.CreateKeyValueString("dryRun", fmt.Sprint(request.DryRun)))
+
+	return nil
+}
+
+// RotateMasterKey rotates the master key of the KMS secret lock and re-encrypts all keys with the new master key.
+// The new master key, protected by the master lock of the secret lock, is stored under MasterKeyStoreKey in the
+// MasterKeyStoreNamespace store before any key is re-encrypted, the master key it replaces is kept under
+// PreviousMasterKeyStoreKey. The secret lock must be created with the stored master key (see
+// local.MasterKeyFromStore) when the agent restarts. If the re-encryption of the keys fails, the rotation must be
+// resumed with ReEncryptKeys while the agent is still running.
+func (o *Command) RotateMasterKey(rw io.Writer, req io.Reader) command.Error {
+	rotation, ok := o.ctx.KMS().(masterKeyRotation)
+	if !ok {
+		logutil.LogError(logger, commandName, rotateMasterKeyMethod, errNoRotation)
+		return command.NewExecuteError(RotateMasterKeyError, fmt.Errorf(errNoRotation))
+	}
+
+	store, err := o.ctx.StorageProvider().OpenStore(MasterKeyStoreNamespace)
+	if err != nil {
+		logutil.LogError(logger, commandName, rotateMasterKeyMethod, err.Error())
+		return command.NewExecuteError(RotateMasterKeyError, fmt.Errorf("open master key store: %w", err))
+	}
+
+	n, err := rotation.RotateMasterKey(func(protectedMasterKey []byte) error {
+		return persistMasterKey(store, protectedMasterKey)
+	}, localkms.WithProgress(logRotationProgress(rotateMasterKeyMethod)))
+	if err != nil {
+		logutil.LogError(logger, commandName, rotateMasterKeyMethod, err.Error(),
+			logutil.CreateKeyValueString("reEncrypted", fmt.Sprint(n)))
+		return command.NewExecuteError(RotateMasterKeyError, err)
+	}
+
+	command.WriteNillableResponse(rw, &RotateMasterKeyResponse{ReEncrypted: n}, logger)
+
+	logutil.LogDebug(logger, commandName, rotateMasterKeyMethod, "success",
+		logutil.CreateKeyValueString("reEncrypted", fmt.Sprint(n)))
+
+	return nil
+}
+
+// persistMasterKey stores the new protected master key and moves the current one, if any, to the previous master key.
+func persistMasterKey(store storage.Store, protectedMasterKey []byte) error {
+	ops := []storage.Operation{storage.PutOperation(MasterKeyStoreKey, protectedMasterKey)}
+
+	current, err := store.Get(MasterKeyStoreKey)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("read current master key: %w", err)
+	}
+
+	if err == nil {
+		ops = append(ops, storage.PutOperation(PreviousMasterKeyStoreKey, current))
+	}
+
+	err = store.Batch(ops)
+	if err != nil {
+		return fmt.Errorf("store new master key: %w", err)
+	}
+
+	return nil
+}
+
+// ReEncryptKeys re-encrypts all keys with the current master key of the KMS secret lock. It resumes an interrupted
+// master key rotation.
+func (o *Command) ReEncryptKeys(rw io.Writer, req io.Reader) command.Error {
+	rotation, ok := o.ctx.KMS().(masterKeyRotation)
+	if !ok {
+		logutil.LogError(logger, commandName, reEncryptKeysMethod, errNoRotation)
+		return command.NewExecuteError(RotateMasterKeyError, fmt.Errorf(errNoRotation))
+	}
+
+	n, err := rotation.ReEncryptKeys(localkms.WithProgress(logRotationProgress(reEncryptKeysMethod)))
+	if err != nil {
+		logutil.LogError(logger, commandName, reEncryptKeysMethod, err.Error())
+		return command.NewExecuteError(RotateMasterKeyError, err)
+	}
+
+	command.WriteNillableResponse(rw, &ReEncryptKeysResponse{ReEncrypted: n}, logger)
+
+	logutil.LogDebug(logger, commandName, reEncryptKeysMethod, "success",
+		logutil.CreateKeyValueString("reEncrypted", fmt.Sprint(n)))
+
+	return nil
+}
+
+func logRotationProgress(method string) func(p *localkms.MasterKeyRotationProgress) {
+	return func(p *localkms.MasterKeyRotationProgress) {
+		logutil.LogDebug(logger, commandName, method, "key re-encrypted",
+			logutil.CreateKeyValueString("keyID", p.KeyID),
+			logutil.CreateKeyValueString("progress", fmt.Sprintf("%d/%d", p.Done, p.Total)))
+	}
+}
+
+func (o *Command) keyBackup(includeLegacyKeys bool) (keyBackup, []localkms.BackupOpt, error) {
+	backup, ok := o.ctx.KMS().(keyBackup)
+	if !ok {
+		return nil, nil, fmt.Errorf(errNoBackup)
+	}
+
+	var opts []localkms.BackupOpt
+
+	if includeLegacyKeys {
+		store, err := o.ctx.StorageProvider().OpenStore(legacykms.KeyStoreNamespace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open legacy kms store: %w", err)
+		}
+
+		opts = append(opts, localkms.WithLegacyKeyStore(store))
+	}
+
+	return backup, opts, nil
+}
+
+func (o *Command) keyInventory() (kms.KeyInventory, error) {
+	inventory, ok := o.ctx.KMS().(kms.KeyInventory)
+	if !ok {
+		return nil, fmt.Errorf(errNoInventory)
+	}
+
+	return inventory, nil
+}
@@ -14,17 +14,27 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/google/tink/go/subtle/random"
 	"github.com/square/go-jose/v3"
 	"github.com/stretchr/testify/require"
 
 	ariesjose "github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
 	mocklegacykms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
 )
 
 func TestNew(t *testing.T) {
@@ -35,7 +45,7 @@ func TestNew(t *testing.T) {
 		require.NotNil(t, cmd)
 
 		handlers := cmd.GetHandlers()
-		require.Equal(t, 3, len(handlers))
+		require.Equal(t, 11, len(handlers))
 	})
 
 	t.Run("test new command - error from export public key", func(t *testing.T) {
@@ -308,4 +318,594 @@ func TestImportKey(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "failed request decode")
 	})
+
+	t.Run("test import key - P-384 and secp256k1 keys", func(t *testing.T) {
+		km, err := localkms.New("local-lock://custom/master/key/",
+			mockkms.NewProviderForKMS(mem.NewProvider(), &noop.NoLock{}))
+		require.NoError(t, err)
+
+		cmd := New(&mockprovider.Provider{KMSValue: km})
+		require.NotNil(t, cmd)
+
+		for c, expectedKT := range map[elliptic.Curve]kms.KeyType{
+			elliptic.P384(): kms.ECDSAP384TypeIEEEP1363,
+			btcec.S256():    kms.ECDSASecp256k1TypeIEEEP1363,
+		} {
+			privateKey, err := ecdsa.GenerateKey(c, rand.Reader)
+			require.NoError(t, err)
+
+			kid := string(expectedKT)
+
+			jwkBytes, err := json.Marshal(&ariesjose.JWK{JSONWebKey: jose.JSONWebKey{Key: privateKey, KeyID: kid}})
+			require.NoError(t, err)
+
+			var getRW bytes.Buffer
+			cmdErr := cmd.ImportKey(&getRW, bytes.NewBuffer(jwkBytes))
+			require.NoError(t, cmdErr)
+
+			response := &ImportKeyResponse{}
+			require.NoError(t, json.Unmarshal(getRW.Bytes(), response))
+			require.Equal(t, kid, response.KeyID)
+
+			md, err := km.Describe(kid)
+			require.NoError(t, err)
+			require.Equal(t, expectedKT, md.KeyType)
+
+			pubJWK, err := kms.ExportPubKeyJWK(km, kid, "")
+			require.NoError(t, err)
+			require.Equal(t, &privateKey.PublicKey, pubJWK.Key)
+		}
+	})
+}
+
+func TestExportKey(t *testing.T) {
+	km, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(mem.NewProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	cmd := New(&mockprovider.Provider{KMSValue: km})
+	require.NotNil(t, cmd)
+
+	t.Run("test export key - success", func(t *testing.T) {
+		pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		keyID, _, err := km.ImportPrivateKey(privKey, kms.ED25519Type)
+		require.NoError(t, err)
+
+		for _, keyType := range []kms.KeyType{"", kms.ED25519Type} {
+			req, err := json.Marshal(&ExportKeyRequest{KeyID: keyID, KeyType: string(keyType)})
+			require.NoError(t, err)
+
+			var rw bytes.Buffer
+			cmdErr := cmd.ExportKey(&rw, bytes.NewBuffer(req))
+			require.NoError(t, cmdErr)
+
+			response := &ExportKeyResponse{}
+			require.NoError(t, json.Unmarshal(rw.Bytes(), response))
+			require.Equal(t, keyID, response.PublicKey.KeyID)
+			require.Equal(t, "Ed25519", response.PublicKey.Crv)
+			require.Equal(t, pubKey, response.PublicKey.Key)
+		}
+	})
+
+	t.Run("test export key - key not found", func(t *testing.T) {
+		var rw bytes.Buffer
+		cmdErr := cmd.ExportKey(&rw, bytes.NewBufferString(`{"keyID":"unknown"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, ExportKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "exportPubKeyJWK")
+	})
+
+	t.Run("test export key - missing key ID", func(t *testing.T) {
+		var rw bytes.Buffer
+		cmdErr := cmd.ExportKey(&rw, bytes.NewBufferString(`{}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errEmptyKeyID)
+	})
+
+	t.Run("test export key - error request decode", func(t *testing.T) {
+		var rw bytes.Buffer
+		cmdErr := cmd.ExportKey(&rw, bytes.NewBuffer(nil))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), "failed request decode")
+	})
+}
+
+func TestListKeys(t *testing.T) {
+	t.Run("test list keys - success", func(t *testing.T) {
+		keys := []*kms.KeyMetadata{
+			{KeyID: "k1", KeyType: kms.ED25519Type, Status: kms.KeyEnabled},
+			{KeyID: "k2", KeyType: kms.ED25519Type, Status: kms.KeyDisabled, RotatedTo: "k3"},
+		}
+
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{ListValue: keys},
+		})
+		require.NotNil(t, cmd)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.ListKeys(&rw, nil)
+		require.NoError(t, cmdErr)
+
+		response := ListKeysResponse{}
+		err := json.NewDecoder(&rw).Decode(&response)
+		require.NoError(t, err)
+		require.Equal(t, keys, response.Keys)
+	})
+
+	t.Run("test list keys - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{ListErr: fmt.Errorf("error list keys")},
+		})
+		require.NotNil(t, cmd)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.ListKeys(&rw, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, ListKeysError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "error list keys")
+	})
+
+	t.Run("test list keys - kms does not support key listing", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: struct{ kms.KeyManager }{&mockkms.KeyManager{}},
+		})
+		require.NotNil(t, cmd)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.ListKeys(&rw, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, ListKeysError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errNoInventory)
+	})
+}
+
+func TestDescribeKey(t *testing.T) {
+	t.Run("test describe key - success", func(t *testing.T) {
+		md := &kms.KeyMetadata{KeyID: "k1", KeyType: kms.ED25519Type, Status: kms.KeyEnabled}
+
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{DescribeValue: md},
+		})
+		require.NotNil(t, cmd)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.DescribeKey(&rw, bytes.NewBufferString(`{"keyID":"k1"}`))
+		require.NoError(t, cmdErr)
+
+		response := DescribeKeyResponse{}
+		err := json.NewDecoder(&rw).Decode(&response)
+		require.NoError(t, err)
+		require.Equal(t, md, response.Key)
+	})
+
+	t.Run("test describe key - validation errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{},
+		})
+		require.NotNil(t, cmd)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.DescribeKey(&rw, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.DescribeKey(&rw, bytes.NewBufferString("{}"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errEmptyKeyID)
+	})
+
+	t.Run("test describe key - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{DescribeErr: fmt.Errorf("error describe key")},
+		})
+		require.NotNil(t, cmd)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.DescribeKey(&rw, bytes.NewBufferString(`{"keyID":"k1"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, DescribeKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "error describe key")
+
+		cmd = New(&mockprovider.Provider{
+			KMSValue: struct{ kms.KeyManager }{&mockkms.KeyManager{}},
+		})
+
+		cmdErr = cmd.DescribeKey(&rw, bytes.NewBufferString(`{"keyID":"k1"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, DescribeKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errNoInventory)
+	})
+}
+
+func TestDeleteKey(t *testing.T) {
+	t.Run("test delete key - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{},
+		})
+		require.NotNil(t, cmd)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.DeleteKey(&rw, bytes.NewBufferString(`{"keyID":"k1"}`))
+		require.NoError(t, cmdErr)
+	})
+
+	t.Run("test delete key - validation errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{},
+		})
+		require.NotNil(t, cmd)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.DeleteKey(&rw, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.DeleteKey(&rw, bytes.NewBufferString("{}"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errEmptyKeyID)
+	})
+
+	t.Run("test delete key - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{DeleteErr: fmt.Errorf("error delete key")},
+		})
+		require.NotNil(t, cmd)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.DeleteKey(&rw, bytes.NewBufferString(`{"keyID":"k1"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, DeleteKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "error delete key")
+
+		cmd = New(&mockprovider.Provider{
+			KMSValue: struct{ kms.KeyManager }{&mockkms.KeyManager{}},
+		})
+
+		cmdErr = cmd.DeleteKey(&rw, bytes.NewBufferString(`{"keyID":"k1"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, DeleteKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errNoInventory)
+	})
+}
+
+func TestBackupRestoreKeys(t *testing.T) {
+	storeProvider := mem.NewProvider()
+
+	km, err := localkms.New("local-lock://custom/master/key/", mockkms.NewProviderForKMS(storeProvider, &noop.NoLock{}))
+	require.NoError(t, err)
+
+	keyID, _, err := km.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	legacyStore, err := storeProvider.OpenStore(legacykms.KeyStoreNamespace)
+	require.NoError(t, err)
+	require.NoError(t, legacyStore.Put("verKey", []byte("keys")))
+
+	cmd := New(&mockprovider.Provider{KMSValue: km, StorageProviderValue: storeProvider})
+	require.NotNil(t, cmd)
+
+	var rw bytes.Buffer
+	cmdErr := cmd.BackupKeys(&rw, bytes.NewBufferString(`{"passphrase":"secret","includeLegacyKeys":true}`))
+	require.NoError(t, cmdErr)
+
+	backupRes := BackupKeysResponse{}
+	require.NoError(t, json.NewDecoder(&rw).Decode(&backupRes))
+	require.NotEmpty(t, backupRes.Archive)
+
+	t.Run("test restore keys - success", func(t *testing.T) {
+		dstStoreProvider := mem.NewProvider()
+
+		dstKM, err := localkms.New("local-lock://custom/master/key/",
+			mockkms.NewProviderForKMS(dstStoreProvider, &noop.NoLock{}))
+		require.NoError(t, err)
+
+		cmd := New(&mockprovider.Provider{KMSValue: dstKM, StorageProviderValue: dstStoreProvider})
+		require.NotNil(t, cmd)
+
+		request, err := json.Marshal(&RestoreKeysRequest{
+			Archive:           backupRes.Archive,
+			Passphrase:        "secret",
+			IncludeLegacyKeys: true,
+			DryRun:            true,
+		})
+		require.NoError(t, err)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.RestoreKeys(&rw, bytes.NewBuffer(request))
+		require.NoError(t, cmdErr)
+
+		response := RestoreKeysResponse{}
+		require.NoError(t, json.NewDecoder(&rw).Decode(&response))
+		require.True(t, response.DryRun)
+		require.Equal(t, []string{keyID}, response.KeyIDs)
+		require.Equal(t, 1, response.LegacyKeys)
+
+		_, err = dstKM.Get(keyID)
+		require.Error(t, err)
+
+		request, err = json.Marshal(&RestoreKeysRequest{
+			Archive:           backupRes.Archive,
+			Passphrase:        "secret",
+			IncludeLegacyKeys: true,
+		})
+		require.NoError(t, err)
+
+		rw.Reset()
+		cmdErr = cmd.RestoreKeys(&rw, bytes.NewBuffer(request))
+		require.NoError(t, cmdErr)
+
+		response = RestoreKeysResponse{}
+		require.NoError(t, json.NewDecoder(&rw).Decode(&response))
+		require.False(t, response.DryRun)
+		require.Equal(t, []string{keyID}, response.KeyIDs)
+
+		_, err = dstKM.Get(keyID)
+		require.NoError(t, err)
+
+		dstLegacyStore, err := dstStoreProvider.OpenStore(legacykms.KeyStoreNamespace)
+		require.NoError(t, err)
+
+		v, err := dstLegacyStore.Get("verKey")
+		require.NoError(t, err)
+		require.Equal(t, []byte("keys"), v)
+	})
+
+	t.Run("test backup and restore keys - validation errors", func(t *testing.T) {
+		var rw bytes.Buffer
+		cmdErr := cmd.BackupKeys(&rw, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.BackupKeys(&rw, bytes.NewBufferString("{}"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errEmptyPass)
+
+		cmdErr = cmd.RestoreKeys(&rw, bytes.NewBufferString("{"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+
+		cmdErr = cmd.RestoreKeys(&rw, bytes.NewBufferString("{}"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errEmptyArchive)
+
+		cmdErr = cmd.RestoreKeys(&rw, bytes.NewBufferString(`{"archive":{}}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errEmptyPass)
+	})
+
+	t.Run("test backup and restore keys - errors", func(t *testing.T) {
+		request, err := json.Marshal(&RestoreKeysRequest{Archive: backupRes.Archive, Passphrase: "wrong"})
+		require.NoError(t, err)
+
+		var rw bytes.Buffer
+		cmdErr := cmd.RestoreKeys(&rw, bytes.NewBuffer(request))
+		require.Error(t, cmdErr)
+		require.Equal(t, RestoreKeysError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "invalid passphrase or corrupted archive")
+
+		cmd := New(&mockprovider.Provider{
+			KMSValue:             km,
+			StorageProviderValue: &mockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("open store error")},
+		})
+
+		cmdErr = cmd.BackupKeys(&rw, bytes.NewBufferString(`{"passphrase":"secret","includeLegacyKeys":true}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, BackupKeysError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "open store error")
+
+		cmdErr = cmd.RestoreKeys(&rw, bytes.NewBufferString(`{"archive":{},"passphrase":"secret",`+
+			`"includeLegacyKeys":true}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, RestoreKeysError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "open store error")
+
+		cmd = New(&mockprovider.Provider{KMSValue: &mockkms.KeyManager{}})
+
+		cmdErr = cmd.BackupKeys(&rw, bytes.NewBufferString(`{"passphrase":"secret"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, BackupKeysError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errNoBackup)
+
+		cmdErr = cmd.RestoreKeys(&rw, bytes.NewBufferString(`{"archive":{},"passphrase":"secret"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, RestoreKeysError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errNoBackup)
+
+		cmd = New(&mockprovider.Provider{KMSValue: &failingBackupKMS{}})
+
+		cmdErr = cmd.BackupKeys(&rw, bytes.NewBufferString(`{"passphrase":"secret"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, BackupKeysError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "backup error")
+	})
+}
+
+type failingBackupKMS struct {
+	mockkms.KeyManager
+}
+
+func (k *failingBackupKMS) BackupKeys(passphrase []byte, opts ...localkms.BackupOpt) ([]byte, error) {
+	return nil, fmt.Errorf("backup error")
+}
+
+func (k *failingBackupKMS) RestoreKeys(archive, passphrase []byte,
+	opts ...localkms.BackupOpt) (*localkms.RestoreResult, error) {
+	return nil, fmt.Errorf("restore error")
+}
+
+func TestRotateMasterKey(t *testing.T) {
+	kmsStoreProvider := mem.NewProvider()
+
+	secretLock, err := local.NewService(bytes.NewReader(
+		[]byte(base64.URLEncoding.EncodeToString(random.GetRandomBytes(32)))), nil)
+	require.NoError(t, err)
+
+	km, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(kmsStoreProvider, secretLock))
+	require.NoError(t, err)
+
+	keyID, _, err := km.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	t.Run("test rotate master key - success", func(t *testing.T) {
+		storeProvider := mem.NewProvider()
+		cmd := New(&mockprovider.Provider{KMSValue: km, StorageProviderValue: storeProvider})
+
+		var rw bytes.Buffer
+		cmdErr := cmd.RotateMasterKey(&rw, nil)
+		require.NoError(t, cmdErr)
+
+		require.NotContains(t, rw.String(), "masterKey")
+
+		response := RotateMasterKeyResponse{}
+		require.NoError(t, json.NewDecoder(&rw).Decode(&response))
+		require.Equal(t, 1, response.ReEncrypted)
+
+		store, err := storeProvider.OpenStore(MasterKeyStoreNamespace)
+		require.NoError(t, err)
+
+		firstMasterKey, err := store.Get(MasterKeyStoreKey)
+		require.NoError(t, err)
+
+		_, err = store.Get(PreviousMasterKeyStoreKey)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		rw.Reset()
+		cmdErr = cmd.RotateMasterKey(&rw, nil)
+		require.NoError(t, cmdErr)
+
+		previousMasterKey, err := store.Get(PreviousMasterKeyStoreKey)
+		require.NoError(t, err)
+		require.Equal(t, firstMasterKey, previousMasterKey)
+
+		// the stored master key decrypts the re-encrypted keys after a restart
+		masterKeyReader, err := local.MasterKeyFromStore(store, MasterKeyStoreKey)
+		require.NoError(t, err)
+
+		restartedLock, err := local.NewService(masterKeyReader, nil)
+		require.NoError(t, err)
+
+		restartedKMS, err := localkms.New("local-lock://custom/master/key/",
+			mockkms.NewProviderForKMS(kmsStoreProvider, restartedLock))
+		require.NoError(t, err)
+
+		_, err = restartedKMS.Get(keyID)
+		require.NoError(t, err)
+
+		rw.Reset()
+		cmdErr = cmd.ReEncryptKeys(&rw, nil)
+		require.NoError(t, cmdErr)
+
+		reEncryptResponse := ReEncryptKeysResponse{}
+		require.NoError(t, json.NewDecoder(&rw).Decode(&reEncryptResponse))
+		require.Equal(t, 1, reEncryptResponse.ReEncrypted)
+	})
+
+	t.Run("test rotate master key - re-encryption error", func(t *testing.T) {
+		storeProvider := mem.NewProvider()
+		cmd := New(&mockprovider.Provider{KMSValue: &failingRotationKMS{}, StorageProviderValue: storeProvider})
+
+		var rw bytes.Buffer
+		cmdErr := cmd.RotateMasterKey(&rw, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, RotateMasterKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "re-encrypt error")
+		require.Empty(t, rw.String())
+
+		// the new master key was persisted before the re-encryption
+		store, err := storeProvider.OpenStore(MasterKeyStoreNamespace)
+		require.NoError(t, err)
+
+		masterKey, err := store.Get(MasterKeyStoreKey)
+		require.NoError(t, err)
+		require.Equal(t, "new master key", string(masterKey))
+
+		cmdErr = cmd.ReEncryptKeys(&rw, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, RotateMasterKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "re-encrypt error")
+	})
+
+	t.Run("test rotate master key - persist errors", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{KMSValue: km, StorageProviderValue: &mockstorage.MockStoreProvider{
+			ErrOpenStoreHandle: fmt.Errorf("open error"),
+		}})
+
+		var rw bytes.Buffer
+		cmdErr := cmd.RotateMasterKey(&rw, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, RotateMasterKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "open master key store: open error")
+
+		cmd = New(&mockprovider.Provider{KMSValue: km, StorageProviderValue: mockstorage.NewCustomMockStoreProvider(
+			&mockstorage.MockStore{Store: make(map[string][]byte), ErrBatch: fmt.Errorf("batch error")})})
+
+		cmdErr = cmd.RotateMasterKey(&rw, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, RotateMasterKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "store new master key: batch error")
+
+		cmd = New(&mockprovider.Provider{KMSValue: km, StorageProviderValue: mockstorage.NewCustomMockStoreProvider(
+			&mockstorage.MockStore{Store: make(map[string][]byte), ErrGet: fmt.Errorf("get error")})})
+
+		cmdErr = cmd.RotateMasterKey(&rw, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, RotateMasterKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "read current master key: get error")
+
+		// the keys are still readable with the current master key
+		_, err = km.Get(keyID)
+		require.NoError(t, err)
+	})
+
+	t.Run("test rotate master key - errors", func(t *testing.T) {
+		noopKM, err := localkms.New("local-lock://custom/master/key/",
+			mockkms.NewProviderForKMS(mem.NewProvider(), &noop.NoLock{}))
+		require.NoError(t, err)
+
+		cmd := New(&mockprovider.Provider{KMSValue: noopKM, StorageProviderValue: mem.NewProvider()})
+
+		var rw bytes.Buffer
+		cmdErr := cmd.RotateMasterKey(&rw, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, RotateMasterKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "secret lock does not support master key rotation")
+
+		cmd = New(&mockprovider.Provider{KMSValue: &mockkms.KeyManager{}})
+
+		cmdErr = cmd.RotateMasterKey(&rw, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, RotateMasterKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errNoRotation)
+
+		cmdErr = cmd.ReEncryptKeys(&rw, nil)
+		require.Error(t, cmdErr)
+		require.Equal(t, RotateMasterKeyError, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), errNoRotation)
+	})
+}
+
+type failingRotationKMS struct {
+	mockkms.KeyManager
+}
+
+func (k *failingRotationKMS) RotateMasterKey(persist func([]byte) error,
+	opts ...localkms.ReEncryptOpt) (int, error) {
+	if err := persist([]byte("new master key")); err != nil {
+		return 0, err
+	}
+
+	return 0, fmt.Errorf("re-encrypt error")
+}
+
+func (k *failingRotationKMS) ReEncryptKeys(opts ...localkms.ReEncryptOpt) (int, error) {
+	return 0, fmt.Errorf("re-encrypt error")
 }
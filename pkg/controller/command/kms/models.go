@@ -6,6 +6,14 @@ SPDX-License-Identifier: Apache-2.0
 
 package kms
 
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+)
+
 // CreateKeySetRequest is model for createKeySey request.
 type CreateKeySetRequest struct {
 	KeyType string `json:"keyType,omitempty"`
@@ -30,3 +38,76 @@ type JSONWebKey struct {
 	Y   string `json:"y,omitempty"`
 	D   string `json:"d,omitempty"`
 }
+
+// ImportKeyResponse for returning the key ID of the imported key
+type ImportKeyResponse struct {
+	KeyID string `json:"keyID,omitempty"`
+}
+
+// ExportKeyRequest is model for export key request.
+type ExportKeyRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+	// KeyType of the key, optional if the KMS keeps the type of its keys
+	KeyType string `json:"keyType,omitempty"`
+}
+
+// ExportKeyResponse for returning the public key as JWK
+type ExportKeyResponse struct {
+	PublicKey *jose.JWK `json:"publicKey"`
+}
+
+// KeyIDRequest is model for describe key and delete key requests.
+type KeyIDRequest struct {
+	KeyID string `json:"keyID,omitempty"`
+}
+
+// ListKeysResponse for returning the metadata of all keys
+type ListKeysResponse struct {
+	Keys []*kms.KeyMetadata `json:"keys"`
+}
+
+// DescribeKeyResponse for returning the metadata of a key
+type DescribeKeyResponse struct {
+	Key *kms.KeyMetadata `json:"key"`
+}
+
+// BackupKeysRequest is model for backup keys request.
+type BackupKeysRequest struct {
+	// Passphrase used to derive the archive encryption key
+	Passphrase string `json:"passphrase,omitempty"`
+	// IncludeLegacyKeys adds the legacy KMS key pairs to the archive
+	IncludeLegacyKeys bool `json:"includeLegacyKeys,omitempty"`
+}
+
+// BackupKeysResponse for returning the encrypted keys archive
+type BackupKeysResponse struct {
+	Archive json.RawMessage `json:"archive"`
+}
+
+// RestoreKeysRequest is model for restore keys request.
+type RestoreKeysRequest struct {
+	// Archive as returned by backup keys
+	Archive json.RawMessage `json:"archive,omitempty"`
+	// Passphrase used when creating the archive
+	Passphrase string `json:"passphrase,omitempty"`
+	// IncludeLegacyKeys restores the legacy KMS key pairs found in the archive
+	IncludeLegacyKeys bool `json:"includeLegacyKeys,omitempty"`
+	// DryRun checks the archive and reports the keys to restore without writing them
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// RestoreKeysResponse for returning the restored keys
+type RestoreKeysResponse struct {
+	localkms.RestoreResult
+}
+
+// RotateMasterKeyResponse for returning the number of keys re-encrypted with the new master key
+type RotateMasterKeyResponse struct {
+	// ReEncrypted is the number of keys re-encrypted with the new master key
+	ReEncrypted int `json:"reEncrypted"`
+}
+
+// ReEncryptKeysResponse for returning the number of re-encrypted keys
+type ReEncryptKeysResponse struct {
+	ReEncrypted int `json:"reEncrypted"`
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
 	didexchangecmd "github.com/hyperledger/aries-framework-go/pkg/controller/command/didexchange"
+	exportcmd "github.com/hyperledger/aries-framework-go/pkg/controller/command/export"
 	introducecmd "github.com/hyperledger/aries-framework-go/pkg/controller/command/introduce"
 	issuecredentialcmd "github.com/hyperledger/aries-framework-go/pkg/controller/command/issuecredential"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command/kms"
@@ -22,6 +23,8 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
 	didexchangerest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/didexchange"
+	didresolverrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/didresolver"
+	exportrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/export"
 	introducerest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/introduce"
 	issuecredentialrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/issuecredential"
 	kmsrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/kms"
@@ -36,11 +39,12 @@ import (
 )
 
 type allOpts struct {
-	webhookURLs  []string
-	defaultLabel string
-	autoAccept   bool
-	msgHandler   command.MessageHandler
-	notifier     command.Notifier
+	webhookURLs       []string
+	defaultLabel      string
+	autoAccept        bool
+	universalResolver bool
+	msgHandler        command.MessageHandler
+	notifier          command.Notifier
 }
 
 const wsPath = "/ws"
@@ -76,6 +80,14 @@ func WithAutoAccept(autoAccept bool) Opt {
 	}
 }
 
+// WithUniversalResolver is an option allowing to expose agent's VDRI registry as Universal Resolver compatible
+// REST endpoint (GET /1.0/identifiers/{did}). It is used by REST handlers only.
+func WithUniversalResolver(enabled bool) Opt {
+	return func(opts *allOpts) {
+		opts.universalResolver = enabled
+	}
+}
+
 // WithMessageHandler is an option allowing for the message handler to be set.
 func WithMessageHandler(handler command.MessageHandler) Opt {
 	return func(opts *allOpts) {
@@ -154,6 +166,9 @@ func GetRESTHandlers(ctx *context.Provider, opts ...Opt) ([]rest.Handler, error)
 	// kms command operation
 	kmscmd := kmsrest.New(ctx)
 
+	// export REST operation
+	exportOp := exportrest.New(ctx)
+
 	// creat handlers from all operations
 	var allHandlers []rest.Handler
 	allHandlers = append(allHandlers, exchangeOp.GetRESTHandlers()...)
@@ -166,6 +181,12 @@ func GetRESTHandlers(ctx *context.Provider, opts ...Opt) ([]rest.Handler, error)
 	allHandlers = append(allHandlers, introduceOp.GetRESTHandlers()...)
 	allHandlers = append(allHandlers, outofbandOp.GetRESTHandlers()...)
 	allHandlers = append(allHandlers, kmscmd.GetRESTHandlers()...)
+	allHandlers = append(allHandlers, exportOp.GetRESTHandlers()...)
+
+	if restAPIOpts.universalResolver {
+		// DID resolver REST operation
+		allHandlers = append(allHandlers, didresolverrest.New(ctx).GetRESTHandlers()...)
+	}
 
 	nhp, ok := notifier.(handlerProvider)
 	if ok {
@@ -250,6 +271,9 @@ func GetCommandHandlers(ctx *context.Provider, opts ...Opt) ([]command.Handler,
 	// kms command operation
 	kmscmd := kms.New(ctx)
 
+	// export command operation
+	export := exportcmd.New(ctx)
+
 	var allHandlers []command.Handler
 	allHandlers = append(allHandlers, didexcmd.GetHandlers()...)
 	allHandlers = append(allHandlers, vcmd.GetHandlers()...)
@@ -261,6 +285,7 @@ func GetCommandHandlers(ctx *context.Provider, opts ...Opt) ([]command.Handler,
 	allHandlers = append(allHandlers, presentproof.GetHandlers()...)
 	allHandlers = append(allHandlers, introduce.GetHandlers()...)
 	allHandlers = append(allHandlers, outofband.GetHandlers()...)
+	allHandlers = append(allHandlers, export.GetHandlers()...)
 
 	return allHandlers, nil
 }
@@ -116,6 +116,34 @@ func TestGetRESTHandlers_Success(t *testing.T) {
 		require.NoError(t, err)
 		require.NotEmpty(t, handlers)
 	})
+	t.Run("with universal resolver", func(t *testing.T) {
+		path, cleanup := generateTempDir(t)
+		defer cleanup()
+
+		framework, err := aries.New(defaults.WithStorePath(path), defaults.WithInboundHTTPAddr(":26509", ""))
+		require.NoError(t, err)
+		require.NotNil(t, framework)
+
+		defer func() { require.NoError(t, framework.Close()) }()
+
+		ctx, err := framework.Context()
+		require.NoError(t, err)
+		require.NotNil(t, ctx)
+
+		handlers, err := GetRESTHandlers(ctx, WithUniversalResolver(true))
+		require.NoError(t, err)
+		require.NotEmpty(t, handlers)
+
+		found := false
+
+		for _, h := range handlers {
+			if h.Path() == "/1.0/identifiers/{did}" {
+				found = true
+			}
+		}
+
+		require.True(t, found)
+	})
 }
 
 func TestWithWebhookNotifierOption(t *testing.T) {
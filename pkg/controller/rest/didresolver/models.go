@@ -0,0 +1,61 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didresolver
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// resolveIdentifierReq model
+//
+// This is used to resolve DID in Universal Resolver format.
+//
+// swagger:parameters resolveIdentifierReq
+type resolveIdentifierReq struct { // nolint: unused,deadcode
+	// DID to be resolved
+	//
+	// in: path
+	// required: true
+	DID string `json:"did"`
+}
+
+// didDocumentRes model
+//
+// This is used for returning resolved DID document (application/did+ld+json).
+//
+// swagger:response didDocumentRes
+type didDocumentRes struct { // nolint: unused,deadcode
+
+	// in: body
+	DIDDocument json.RawMessage
+}
+
+// resolutionResultRes model
+//
+// This is used for returning DID resolution result.
+//
+// swagger:response resolutionResultRes
+type resolutionResultRes struct { // nolint: unused,deadcode
+
+	// in: body
+	Result resolutionResult
+}
+
+// resolutionResult is DID resolution result as defined by https://w3c-ccg.github.io/did-resolution/#did-resolution-result
+type resolutionResult struct {
+	Context          string           `json:"@context"`
+	DIDDocument      json.RawMessage  `json:"didDocument"`
+	ResolverMetadata resolverMetadata `json:"resolverMetadata"`
+	MethodMetadata   struct{}         `json:"methodMetadata"`
+}
+
+type resolverMetadata struct {
+	Duration   int64     `json:"duration"`
+	Identifier string    `json:"identifier"`
+	Retrieved  time.Time `json:"retrieved"`
+}
@@ -0,0 +1,173 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didresolver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+)
+
+var logger = log.New("aries-framework/rest/didresolver")
+
+const (
+	resolverOperationID = "/1.0/identifiers"
+	resolveDIDPath      = resolverOperationID + "/{did}"
+
+	// DIDLDJSONMediaType is a media type of DID document.
+	DIDLDJSONMediaType = "application/did+ld+json"
+
+	// ResolutionResultMediaType is a media type of DID resolution result.
+	ResolutionResultMediaType = `application/ld+json;profile="https://w3id.org/did-resolution"`
+
+	resolutionResultContext = "https://w3id.org/did-resolution/v1"
+)
+
+// provider contains dependencies for the DID resolver operations
+// and is typically created by using aries.Context()
+type provider interface {
+	VDRIRegistry() vdriapi.Registry
+}
+
+// Operation contains Universal Resolver compatible DID resolution endpoint backed by the agent's VDRI registry.
+// It allows to use the agent as a DID resolver for vdri/httpbinding of another agent.
+type Operation struct {
+	handlers []rest.Handler
+	registry vdriapi.Registry
+}
+
+// New returns new DID resolver rest client instance
+func New(ctx provider) *Operation {
+	o := &Operation{registry: ctx.VDRIRegistry()}
+	o.registerHandler()
+
+	return o
+}
+
+// GetRESTHandlers get all controller API handler available for this service
+func (o *Operation) GetRESTHandlers() []rest.Handler {
+	return o.handlers
+}
+
+// registerHandler register handlers to be exposed from this service as REST API endpoints
+func (o *Operation) registerHandler() {
+	o.handlers = []rest.Handler{
+		cmdutil.NewHTTPHandler(resolveDIDPath, http.MethodGet, o.ResolveIdentifier),
+	}
+}
+
+// ResolveIdentifier swagger:route GET /1.0/identifiers/{did} didresolver resolveIdentifierReq
+//
+// Resolves DID in Universal Resolver format. DID document is returned if "application/did+ld+json" is accepted,
+// DID resolution result is returned otherwise.
+//
+// Produces:
+// - application/did+ld+json
+// - application/ld+json;profile="https://w3id.org/did-resolution"
+//
+// Responses:
+//    default: genericError
+//        200: resolutionResultRes
+func (o *Operation) ResolveIdentifier(rw http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["did"]
+
+	if _, err := did.Parse(id); err != nil {
+		rest.SendHTTPStatusError(rw, http.StatusBadRequest, vdri.InvalidRequestErrorCode, err)
+		return
+	}
+
+	start := time.Now()
+
+	doc, err := o.registry.Resolve(id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, vdriapi.ErrNotFound) {
+			status = http.StatusNotFound
+		}
+
+		rest.SendHTTPStatusError(rw, status, vdri.ResolveDIDErrorCode, fmt.Errorf("resolve did: %w", err))
+
+		return
+	}
+
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		rest.SendHTTPStatusError(rw, http.StatusInternalServerError, vdri.ResolveDIDErrorCode,
+			fmt.Errorf("marshal did document: %w", err))
+
+		return
+	}
+
+	if acceptsDIDDocument(req.Header.Get("Accept")) {
+		writeResponse(rw, DIDLDJSONMediaType, docBytes)
+
+		return
+	}
+
+	resultBytes, err := json.Marshal(&resolutionResult{
+		Context:     resolutionResultContext,
+		DIDDocument: docBytes,
+		ResolverMetadata: resolverMetadata{
+			Duration:   time.Since(start).Milliseconds(),
+			Identifier: id,
+			Retrieved:  time.Now().UTC(),
+		},
+	})
+	if err != nil {
+		rest.SendHTTPStatusError(rw, http.StatusInternalServerError, vdri.ResolveDIDErrorCode,
+			fmt.Errorf("marshal resolution result: %w", err))
+
+		return
+	}
+
+	writeResponse(rw, ResolutionResultMediaType, resultBytes)
+}
+
+// acceptsDIDDocument checks if DID document media type is preferred over resolution result by the client.
+// Accept header values are checked in the order they are listed, quality values are not taken into account.
+func acceptsDIDDocument(accept string) bool {
+	for _, v := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case DIDLDJSONMediaType:
+			return true
+		case "application/ld+json":
+			if strings.Contains(params["profile"], "did-resolution") {
+				return false
+			}
+		case "application/json", "*/*":
+			return false
+		}
+	}
+
+	return false
+}
+
+func writeResponse(rw http.ResponseWriter, contentType string, body []byte) {
+	rw.Header().Set("Content-Type", contentType)
+
+	if _, err := rw.Write(body); err != nil {
+		logger.Errorf("Unable to send resolution response, %s", err)
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didresolver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/httpbinding"
+)
+
+const (
+	sampleDID = "did:peer:21tDAKCERh95uGgKbJNHYp"
+	sampleDoc = `{
+  "@context": ["https://w3id.org/did/v1"],
+  "id": "did:peer:21tDAKCERh95uGgKbJNHYp",
+  "publicKey": [
+    {
+      "id": "did:peer:21tDAKCERh95uGgKbJNHYp#keys-1",
+      "type": "Ed25519VerificationKey2018",
+      "controller": "did:peer:21tDAKCERh95uGgKbJNHYp",
+      "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+    }
+  ]
+}`
+)
+
+func TestNew(t *testing.T) {
+	op := New(&mockprovider.Provider{VDRIRegistryValue: &mockvdri.MockVDRIRegistry{}})
+	require.NotNil(t, op)
+	require.Len(t, op.GetRESTHandlers(), 1)
+	require.Equal(t, "/1.0/identifiers/{did}", op.GetRESTHandlers()[0].Path())
+	require.Equal(t, http.MethodGet, op.GetRESTHandlers()[0].Method())
+}
+
+func TestOperation_ResolveIdentifier(t *testing.T) {
+	doc, err := did.ParseDocument([]byte(sampleDoc))
+	require.NoError(t, err)
+
+	t.Run("resolve DID document", func(t *testing.T) {
+		op := New(&mockprovider.Provider{VDRIRegistryValue: &mockvdri.MockVDRIRegistry{ResolveValue: doc}})
+
+		for _, accept := range []string{DIDLDJSONMediaType, "text/html, application/did+ld+json;q=0.9"} {
+			rr := serve(t, op, sampleDID, accept)
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, DIDLDJSONMediaType, rr.Header().Get("Content-Type"))
+
+			resolved, err := did.ParseDocument(rr.Body.Bytes())
+			require.NoError(t, err)
+			require.Equal(t, sampleDID, resolved.ID)
+		}
+	})
+
+	t.Run("resolve DID resolution result", func(t *testing.T) {
+		op := New(&mockprovider.Provider{VDRIRegistryValue: &mockvdri.MockVDRIRegistry{ResolveValue: doc}})
+
+		for _, accept := range []string{"", "*/*", "application/json", ResolutionResultMediaType} {
+			rr := serve(t, op, sampleDID, accept)
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, ResolutionResultMediaType, rr.Header().Get("Content-Type"))
+
+			var result resolutionResult
+
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+			require.Equal(t, resolutionResultContext, result.Context)
+			require.Equal(t, sampleDID, result.ResolverMetadata.Identifier)
+			require.False(t, result.ResolverMetadata.Retrieved.IsZero())
+
+			resolved, err := did.ParseDocument(result.DIDDocument)
+			require.NoError(t, err)
+			require.Equal(t, sampleDID, resolved.ID)
+		}
+	})
+
+	t.Run("invalid DID", func(t *testing.T) {
+		op := New(&mockprovider.Provider{VDRIRegistryValue: &mockvdri.MockVDRIRegistry{ResolveValue: doc}})
+
+		rr := serve(t, op, "invalid", "")
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		verifyError(t, vdri.InvalidRequestErrorCode, "invalid did", rr.Body.Bytes())
+	})
+
+	t.Run("DID not found", func(t *testing.T) {
+		op := New(&mockprovider.Provider{VDRIRegistryValue: &mockvdri.MockVDRIRegistry{}})
+
+		rr := serve(t, op, sampleDID, DIDLDJSONMediaType)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		verifyError(t, vdri.ResolveDIDErrorCode, "DID not found", rr.Body.Bytes())
+	})
+
+	t.Run("resolve error", func(t *testing.T) {
+		op := New(&mockprovider.Provider{VDRIRegistryValue: &mockvdri.MockVDRIRegistry{
+			ResolveErr: errors.New("resolve error"),
+		}})
+
+		rr := serve(t, op, sampleDID, DIDLDJSONMediaType)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		verifyError(t, vdri.ResolveDIDErrorCode, "resolve error", rr.Body.Bytes())
+	})
+
+}
+
+func TestHTTPBindingInterop(t *testing.T) {
+	doc, err := did.ParseDocument([]byte(sampleDoc))
+	require.NoError(t, err)
+
+	op := New(&mockprovider.Provider{VDRIRegistryValue: &mockvdri.MockVDRIRegistry{ResolveValue: doc}})
+
+	router := mux.NewRouter()
+
+	for _, handler := range op.GetRESTHandlers() {
+		router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
+	}
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	v, err := httpbinding.New(server.URL + resolverOperationID)
+	require.NoError(t, err)
+
+	resolved, err := v.Read(sampleDID)
+	require.NoError(t, err)
+	require.Equal(t, sampleDID, resolved.ID)
+	require.Len(t, resolved.PublicKey, 1)
+}
+
+func serve(t *testing.T, op *Operation, id, accept string) *httptest.ResponseRecorder {
+	handler := op.GetRESTHandlers()[0]
+
+	req, err := http.NewRequest(handler.Method(), resolverOperationID+"/"+id, nil)
+	require.NoError(t, err)
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func verifyError(t *testing.T, expectedCode command.Code, expectedMsg string, data []byte) {
+	errResponse := struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}{}
+
+	require.NoError(t, json.Unmarshal(data, &errResponse))
+	require.EqualValues(t, expectedCode, errResponse.Code)
+	require.Contains(t, errResponse.Message, expectedMsg)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package export
+
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command/export"
+)
+
+// exportReq model
+//
+// This is used for export agent data request
+//
+// swagger:parameters exportData
+type exportReq struct { // nolint: unused,deadcode
+	// in: body
+	export.ExportRequest
+}
+
+// exportRes model
+//
+// This is used for returning the encrypted agent data archive
+//
+// swagger:response exportRes
+type exportRes struct { // nolint: unused,deadcode
+	// in: body
+	export.ExportResponse
+}
+
+// importReq model
+//
+// This is used for import agent data request
+//
+// swagger:parameters importData
+type importReq struct { // nolint: unused,deadcode
+	// in: body
+	export.ImportRequest
+}
+
+// importRes model
+//
+// This is used for returning the imported stores and keys
+//
+// swagger:response importRes
+type importRes struct { // nolint: unused,deadcode
+	// in: body
+	export.ImportResponse
+}
@@ -0,0 +1,90 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package export
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	cmdexport "github.com/hyperledger/aries-framework-go/pkg/controller/command/export"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	storeexport "github.com/hyperledger/aries-framework-go/pkg/store/export"
+)
+
+const (
+	exportOperationID = "/data"
+	exportPath        = exportOperationID + "/export"
+	importPath        = exportOperationID + "/import"
+)
+
+// provider contains dependencies for the export command and is typically created by using aries.Context().
+type provider interface {
+	StorageProvider() storage.Provider
+	TransientStorageProvider() storage.Provider
+	KMS() kms.KeyManager
+}
+
+type exportCommand interface {
+	Export(rw io.Writer, req io.Reader) command.Error
+	Import(rw io.Writer, req io.Reader) command.Error
+}
+
+// Operation contains basic common operations provided by controller REST API
+type Operation struct {
+	handlers []rest.Handler
+	command  exportCommand
+}
+
+// New returns new export operations rest client instance
+func New(p provider, opts ...storeexport.Option) *Operation {
+	cmd := cmdexport.New(p, opts...)
+
+	o := &Operation{command: cmd}
+	o.registerHandler()
+
+	return o
+}
+
+// GetRESTHandlers get all controller API handler available for this service
+func (o *Operation) GetRESTHandlers() []rest.Handler {
+	return o.handlers
+}
+
+// registerHandler register handlers to be exposed from this protocol service as REST API endpoints
+func (o *Operation) registerHandler() {
+	o.handlers = []rest.Handler{
+		cmdutil.NewHTTPHandler(exportPath, http.MethodPost, o.Export),
+		cmdutil.NewHTTPHandler(importPath, http.MethodPost, o.Import),
+	}
+}
+
+// Export swagger:route POST /data/export export exportData
+//
+// Exports the connections, DIDs, credentials, protocol states and keys of the agent into an archive encrypted with
+// a passphrase.
+//
+// Responses:
+//    default: genericError
+//        200: exportRes
+func (o *Operation) Export(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.Export, rw, req.Body)
+}
+
+// Import swagger:route POST /data/import export importData
+//
+// Imports the records and keys of an encrypted archive into the stores and the KMS of the agent.
+//
+// Responses:
+//    default: genericError
+//        200: importRes
+func (o *Operation) Import(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.Import, rw, req.Body)
+}
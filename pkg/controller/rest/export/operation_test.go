@@ -0,0 +1,160 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command/export"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestNew(t *testing.T) {
+	cmd := New(&mockprovider.Provider{})
+	require.NotNil(t, cmd)
+	require.Equal(t, 2, len(cmd.GetRESTHandlers()))
+}
+
+func TestExportImport(t *testing.T) {
+	storeProvider := mem.NewProvider()
+
+	km, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(storeProvider, &noop.NoLock{}))
+	require.NoError(t, err)
+
+	keyID, _, err := km.Create(kmsapi.ED25519Type)
+	require.NoError(t, err)
+
+	cmd := New(&mockprovider.Provider{
+		KMSValue:                      km,
+		StorageProviderValue:          storeProvider,
+		TransientStorageProviderValue: mem.NewProvider(),
+	})
+	require.NotNil(t, cmd)
+
+	var archive json.RawMessage
+
+	t.Run("test export - success", func(t *testing.T) {
+		handler := lookupHandler(t, cmd, exportPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(`{"passphrase":"secret"}`), exportPath)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+
+		response := export.ExportResponse{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.NotEmpty(t, response.Archive)
+
+		archive = response.Archive
+	})
+
+	t.Run("test import - success", func(t *testing.T) {
+		request, err := json.Marshal(&export.ImportRequest{Archive: archive, Passphrase: "secret", DryRun: true})
+		require.NoError(t, err)
+
+		handler := lookupHandler(t, cmd, importPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBuffer(request), importPath)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+
+		response := export.ImportResponse{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.True(t, response.DryRun)
+		require.NotEmpty(t, response.Stores)
+		require.Equal(t, []string{keyID}, response.Keys.SkippedKeyIDs)
+	})
+
+	t.Run("test export and import - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue:                      &mockkms.KeyManager{},
+			StorageProviderValue:          mem.NewProvider(),
+			TransientStorageProviderValue: mem.NewProvider(),
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, exportPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(`{"passphrase":"secret"}`), exportPath)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, export.ExportError, "kms does not support key backup and restore", buf.Bytes())
+
+		handler = lookupHandler(t, cmd, importPath, http.MethodPost)
+		buf, code, err = sendRequestToHandler(handler, bytes.NewBufferString(`{}`), importPath)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, code)
+		verifyError(t, export.InvalidRequestErrorCode, "archive is mandatory", buf.Bytes())
+	})
+}
+
+func lookupHandler(t *testing.T, op *Operation, path, method string) rest.Handler {
+	handlers := op.GetRESTHandlers()
+	require.NotEmpty(t, handlers)
+
+	for _, h := range handlers {
+		if h.Path() == path && h.Method() == method {
+			return h
+		}
+	}
+
+	require.Fail(t, "unable to find handler")
+
+	return nil
+}
+
+// sendRequestToHandler reads response from given http handle func.
+func sendRequestToHandler(handler rest.Handler, requestBody io.Reader, path string) (*bytes.Buffer, int, error) {
+	// prepare request
+	req, err := http.NewRequest(handler.Method(), path, requestBody)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// prepare router
+	router := mux.NewRouter()
+
+	router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
+
+	// create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
+	rr := httptest.NewRecorder()
+
+	// serve http on given response and request
+	router.ServeHTTP(rr, req)
+
+	return rr.Body, rr.Code, nil
+}
+
+func verifyError(t *testing.T, expectedCode command.Code, expectedMsg string, data []byte) {
+	// Parser generic error response
+	errResponse := struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}{}
+	err := json.Unmarshal(data, &errResponse)
+	require.NoError(t, err)
+
+	// verify response
+	require.EqualValues(t, expectedCode, errResponse.Code)
+	require.NotEmpty(t, errResponse.Message)
+
+	if expectedMsg != "" {
+		require.Contains(t, errResponse.Message, expectedMsg)
+	}
+}
@@ -43,3 +43,147 @@ type importKeyReq struct { // nolint: unused,deadcode
 	// in: body
 	kms.JSONWebKey
 }
+
+// importKeyRes model
+//
+// This is used for returning the key ID of the imported key
+//
+// swagger:response importKeyRes
+type importKeyRes struct { // nolint: unused,deadcode
+	// in: body
+	kms.ImportKeyResponse
+}
+
+// exportKeyReq model
+//
+// This is used for export key request
+//
+// swagger:parameters exportKeyReq
+type exportKeyReq struct { // nolint: unused,deadcode
+	// Key ID
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"keyID"`
+
+	// Key type, optional if the KMS keeps the type of its keys
+	//
+	// in: query
+	KeyType string `json:"keyType"`
+}
+
+// exportKeyRes model
+//
+// This is used for returning the public key as JWK
+//
+// swagger:response exportKeyRes
+type exportKeyRes struct { // nolint: unused,deadcode
+	// in: body
+	kms.ExportKeyResponse
+}
+
+// listKeysRes model
+//
+// This is used for returning the metadata of all keys
+//
+// swagger:response listKeysRes
+type listKeysRes struct { // nolint: unused,deadcode
+	// in: body
+	kms.ListKeysResponse
+}
+
+// describeKeyReq model
+//
+// This is used for describe key request
+//
+// swagger:parameters describeKeyReq
+type describeKeyReq struct { // nolint: unused,deadcode
+	// Key ID
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"keyID"`
+}
+
+// describeKeyRes model
+//
+// This is used for returning the metadata of a key
+//
+// swagger:response describeKeyRes
+type describeKeyRes struct { // nolint: unused,deadcode
+	// in: body
+	kms.DescribeKeyResponse
+}
+
+// deleteKeyReq model
+//
+// This is used for delete key request
+//
+// swagger:parameters deleteKeyReq
+type deleteKeyReq struct { // nolint: unused,deadcode
+	// Key ID
+	//
+	// in: path
+	// required: true
+	KeyID string `json:"keyID"`
+}
+
+// backupKeysReq model
+//
+// This is used for backup keys request
+//
+// swagger:parameters backupKeys
+type backupKeysReq struct { // nolint: unused,deadcode
+	// in: body
+	kms.BackupKeysRequest
+}
+
+// backupKeysRes model
+//
+// This is used for returning the encrypted keys archive
+//
+// swagger:response backupKeysRes
+type backupKeysRes struct { // nolint: unused,deadcode
+	// in: body
+	kms.BackupKeysResponse
+}
+
+// restoreKeysReq model
+//
+// This is used for restore keys request
+//
+// swagger:parameters restoreKeys
+type restoreKeysReq struct { // nolint: unused,deadcode
+	// in: body
+	kms.RestoreKeysRequest
+}
+
+// restoreKeysRes model
+//
+// This is used for returning the restored keys
+//
+// swagger:response restoreKeysRes
+type restoreKeysRes struct { // nolint: unused,deadcode
+	// in: body
+	kms.RestoreKeysResponse
+}
+
+// rotateMasterKeyRes model
+//
+// This is used for returning the number of keys re-encrypted with the new master key
+//
+// swagger:response rotateMasterKeyRes
+type rotateMasterKeyRes struct { // nolint: unused,deadcode
+	// in: body
+	kms.RotateMasterKeyResponse
+}
+
+// reEncryptKeysRes model
+//
+// This is used for returning the number of re-encrypted keys
+//
+// swagger:response reEncryptKeysRes
+type reEncryptKeysRes struct { // nolint: unused,deadcode
+	// in: body
+	kms.ReEncryptKeysResponse
+}
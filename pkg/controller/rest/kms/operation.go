@@ -7,15 +7,20 @@ SPDX-License-Identifier: Apache-2.0
 package kms
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 	"net/http"
 
+	"github.com/gorilla/mux"
+
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
 	cmdkms "github.com/hyperledger/aries-framework-go/pkg/controller/command/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
 const (
@@ -23,6 +28,14 @@ const (
 	legacykmseOperationID     = "/legacykms"
 	createKeySetPath          = kmseOperationID + "/keyset"
 	importKeyPath             = kmseOperationID + "/import"
+	keysPath                  = kmseOperationID + "/keys"
+	keyPath                   = keysPath + "/{keyID}"
+	deleteKeyPath             = keyPath + "/delete"
+	exportKeyPath             = keyPath + "/export"
+	backupKeysPath            = kmseOperationID + "/backup"
+	restoreKeysPath           = kmseOperationID + "/restore"
+	rotateMasterKeyPath       = kmseOperationID + "/masterkey/rotate"
+	reEncryptKeysPath         = kmseOperationID + "/masterkey/reencrypt"
 	createKeySetLegacyKMSPath = legacykmseOperationID + "/keyset"
 )
 
@@ -30,12 +43,21 @@ const (
 type provider interface {
 	KMS() kms.KeyManager
 	LegacyKMS() legacykms.KeyManager
+	StorageProvider() storage.Provider
 }
 
 type kmsCommand interface {
 	CreateKeySet(rw io.Writer, req io.Reader) command.Error
 	CreateKeySetLegacyKMS(rw io.Writer, req io.Reader) command.Error
 	ImportKey(rw io.Writer, req io.Reader) command.Error
+	ExportKey(rw io.Writer, req io.Reader) command.Error
+	ListKeys(rw io.Writer, req io.Reader) command.Error
+	DescribeKey(rw io.Writer, req io.Reader) command.Error
+	DeleteKey(rw io.Writer, req io.Reader) command.Error
+	BackupKeys(rw io.Writer, req io.Reader) command.Error
+	RestoreKeys(rw io.Writer, req io.Reader) command.Error
+	RotateMasterKey(rw io.Writer, req io.Reader) command.Error
+	ReEncryptKeys(rw io.Writer, req io.Reader) command.Error
 }
 
 // Operation contains basic common operations provided by controller REST API
@@ -64,6 +86,14 @@ func (o *Operation) registerHandler() {
 	o.handlers = []rest.Handler{
 		cmdutil.NewHTTPHandler(createKeySetPath, http.MethodPost, o.CreateKeySet),
 		cmdutil.NewHTTPHandler(importKeyPath, http.MethodPost, o.ImportKey),
+		cmdutil.NewHTTPHandler(keysPath, http.MethodGet, o.ListKeys),
+		cmdutil.NewHTTPHandler(keyPath, http.MethodGet, o.DescribeKey),
+		cmdutil.NewHTTPHandler(deleteKeyPath, http.MethodPost, o.DeleteKey),
+		cmdutil.NewHTTPHandler(exportKeyPath, http.MethodGet, o.ExportKey),
+		cmdutil.NewHTTPHandler(backupKeysPath, http.MethodPost, o.BackupKeys),
+		cmdutil.NewHTTPHandler(restoreKeysPath, http.MethodPost, o.RestoreKeys),
+		cmdutil.NewHTTPHandler(rotateMasterKeyPath, http.MethodPost, o.RotateMasterKey),
+		cmdutil.NewHTTPHandler(reEncryptKeysPath, http.MethodPost, o.ReEncryptKeys),
 		cmdutil.NewHTTPHandler(createKeySetLegacyKMSPath, http.MethodPost, o.CreateKeySetLegacyKms),
 	}
 }
@@ -92,10 +122,113 @@ func (o *Operation) CreateKeySet(rw http.ResponseWriter, req *http.Request) {
 
 // ImportKey swagger:route POST /kms/import kms importKey
 //
-// Import key.
+// Imports a private key given as JWK, the key ID is set to the JWK kid.
 //
 // Responses:
 //    default: genericError
+//        200: importKeyRes
 func (o *Operation) ImportKey(rw http.ResponseWriter, req *http.Request) {
 	rest.Execute(o.command.ImportKey, rw, req.Body)
 }
+
+// ListKeys swagger:route GET /kms/keys kms listKeys
+//
+// Lists the metadata of all keys.
+//
+// Responses:
+//    default: genericError
+//        200: listKeysRes
+func (o *Operation) ListKeys(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.ListKeys, rw, req.Body)
+}
+
+// DescribeKey swagger:route GET /kms/keys/{keyID} kms describeKeyReq
+//
+// Describes a key.
+//
+// Responses:
+//    default: genericError
+//        200: describeKeyRes
+func (o *Operation) DescribeKey(rw http.ResponseWriter, req *http.Request) {
+	executeWithRequest(o.command.DescribeKey, rw, &cmdkms.KeyIDRequest{KeyID: mux.Vars(req)["keyID"]})
+}
+
+// DeleteKey swagger:route POST /kms/keys/{keyID}/delete kms deleteKeyReq
+//
+// Deletes a key.
+//
+// Responses:
+//    default: genericError
+func (o *Operation) DeleteKey(rw http.ResponseWriter, req *http.Request) {
+	executeWithRequest(o.command.DeleteKey, rw, &cmdkms.KeyIDRequest{KeyID: mux.Vars(req)["keyID"]})
+}
+
+// ExportKey swagger:route GET /kms/keys/{keyID}/export kms exportKeyReq
+//
+// Exports the public key of a key as JWK.
+//
+// Responses:
+//    default: genericError
+//        200: exportKeyRes
+func (o *Operation) ExportKey(rw http.ResponseWriter, req *http.Request) {
+	executeWithRequest(o.command.ExportKey, rw, &cmdkms.ExportKeyRequest{
+		KeyID:   mux.Vars(req)["keyID"],
+		KeyType: req.URL.Query().Get("keyType"),
+	})
+}
+
+// BackupKeys swagger:route POST /kms/backup kms backupKeys
+//
+// Exports all keys into an archive encrypted with a passphrase.
+//
+// Responses:
+//    default: genericError
+//        200: backupKeysRes
+func (o *Operation) BackupKeys(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.BackupKeys, rw, req.Body)
+}
+
+// RestoreKeys swagger:route POST /kms/restore kms restoreKeys
+//
+// Restores the keys of an encrypted archive.
+//
+// Responses:
+//    default: genericError
+//        200: restoreKeysRes
+func (o *Operation) RestoreKeys(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.RestoreKeys, rw, req.Body)
+}
+
+// RotateMasterKey swagger:route POST /kms/masterkey/rotate kms rotateMasterKey
+//
+// Rotates the master key of the secret lock and re-encrypts all keys with the new master key.
+//
+// Responses:
+//    default: genericError
+//        200: rotateMasterKeyRes
+func (o *Operation) RotateMasterKey(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.RotateMasterKey, rw, req.Body)
+}
+
+// ReEncryptKeys swagger:route POST /kms/masterkey/reencrypt kms reEncryptKeys
+//
+// Re-encrypts all keys with the current master key of the secret lock.
+//
+// Responses:
+//    default: genericError
+//        200: reEncryptKeysRes
+func (o *Operation) ReEncryptKeys(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.ReEncryptKeys, rw, req.Body)
+}
+
+// executeWithRequest executes the command with the request built from the path and query parameters.
+func executeWithRequest(exec command.Exec, rw http.ResponseWriter, request interface{}) {
+	reqBytes, err := json.Marshal(request)
+	if err != nil {
+		rest.SendHTTPStatusError(rw, http.StatusBadRequest, cmdkms.InvalidRequestErrorCode, err)
+
+		return
+	}
+
+	rest.Execute(exec, rw, bytes.NewReader(reqBytes))
+}
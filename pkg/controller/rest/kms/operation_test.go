@@ -21,9 +21,13 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
 	mocklegacykms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
 )
 
 func TestNew(t *testing.T) {
@@ -32,7 +36,7 @@ func TestNew(t *testing.T) {
 			KMSValue: &mockkms.KeyManager{},
 		})
 		require.NotNil(t, cmd)
-		require.Equal(t, 3, len(cmd.GetRESTHandlers()))
+		require.Equal(t, 11, len(cmd.GetRESTHandlers()))
 	})
 }
 
@@ -44,7 +48,7 @@ func TestCreateKeySetLegacyKMS(t *testing.T) {
 		})
 		require.NotNil(t, cmd)
 
-		handler := lookupHandler(t, cmd, createKeySetLegacyKMSPath)
+		handler := lookupHandler(t, cmd, createKeySetLegacyKMSPath, http.MethodPost)
 		err := getSuccessResponseFromHandler(handler, createKeySetLegacyKMSPath)
 		require.NoError(t, err)
 	})
@@ -55,7 +59,7 @@ func TestCreateKeySetLegacyKMS(t *testing.T) {
 		})
 		require.NotNil(t, cmd)
 
-		handler := lookupHandler(t, cmd, createKeySetLegacyKMSPath)
+		handler := lookupHandler(t, cmd, createKeySetLegacyKMSPath, http.MethodPost)
 		buf, code, err := sendRequestToHandler(handler, nil, createKeySetLegacyKMSPath)
 		require.NoError(t, err)
 		require.NotEmpty(t, buf)
@@ -72,7 +76,7 @@ func TestCreateKeySet(t *testing.T) {
 		})
 		cmd.command = &mockKMSCommand{}
 
-		handler := lookupHandler(t, cmd, createKeySetPath)
+		handler := lookupHandler(t, cmd, createKeySetPath, http.MethodPost)
 		err := getSuccessResponseFromHandler(handler, createKeySetPath)
 		require.NoError(t, err)
 	})
@@ -83,7 +87,7 @@ func TestCreateKeySet(t *testing.T) {
 		})
 		require.NotNil(t, cmd)
 
-		handler := lookupHandler(t, cmd, createKeySetPath)
+		handler := lookupHandler(t, cmd, createKeySetPath, http.MethodPost)
 
 		req := createKeySetReq{CreateKeySetRequest: kms.CreateKeySetRequest{
 			KeyType: "ED25519",
@@ -105,7 +109,7 @@ func TestImportKey(t *testing.T) {
 		cmd := New(&mockprovider.Provider{})
 		cmd.command = &mockKMSCommand{}
 
-		handler := lookupHandler(t, cmd, importKeyPath)
+		handler := lookupHandler(t, cmd, importKeyPath, http.MethodPost)
 		err := getSuccessResponseFromHandler(handler, importKeyPath)
 		require.NoError(t, err)
 	})
@@ -117,7 +121,7 @@ func TestImportKey(t *testing.T) {
 		cmd.command = &mockKMSCommand{importKeyError: command.NewExecuteError(kms.ImportKeyError,
 			fmt.Errorf("failed to import key"))}
 
-		handler := lookupHandler(t, cmd, importKeyPath)
+		handler := lookupHandler(t, cmd, importKeyPath, http.MethodPost)
 
 		req := importKeyReq{JSONWebKey: kms.JSONWebKey{Kid: "k1"}}
 		reqBytes, err := json.Marshal(req)
@@ -132,12 +136,243 @@ func TestImportKey(t *testing.T) {
 	})
 }
 
-func lookupHandler(t *testing.T, op *Operation, path string) rest.Handler {
+func TestListKeys(t *testing.T) {
+	t.Run("test list keys - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{ListValue: []*kmsapi.KeyMetadata{{KeyID: "k1"}}},
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, keysPath, http.MethodGet)
+		buf, code, err := sendRequestToHandler(handler, nil, keysPath)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+
+		response := kms.ListKeysResponse{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.Len(t, response.Keys, 1)
+		require.Equal(t, "k1", response.Keys[0].KeyID)
+	})
+
+	t.Run("test list keys - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{ListErr: fmt.Errorf("failed to list keys")},
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, keysPath, http.MethodGet)
+		buf, code, err := sendRequestToHandler(handler, nil, keysPath)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.ListKeysError, "failed to list keys", buf.Bytes())
+	})
+}
+
+func TestDescribeKey(t *testing.T) {
+	t.Run("test describe key - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{DescribeValue: &kmsapi.KeyMetadata{KeyID: "k1", Status: kmsapi.KeyEnabled}},
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, keyPath, http.MethodGet)
+		buf, code, err := sendRequestToHandler(handler, nil, keysPath+"/k1")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+
+		response := kms.DescribeKeyResponse{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.Equal(t, "k1", response.Key.KeyID)
+		require.Equal(t, kmsapi.KeyEnabled, response.Key.Status)
+
+		// the key ID is escaped in the command request
+		_, code, err = sendRequestToHandler(handler, nil, keysPath+"/k%7F%221")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("test describe key - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{DescribeErr: fmt.Errorf("failed to describe key")},
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, keyPath, http.MethodGet)
+		buf, code, err := sendRequestToHandler(handler, nil, keysPath+"/k1")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.DescribeKeyError, "failed to describe key", buf.Bytes())
+	})
+}
+
+func TestExportKey(t *testing.T) {
+	km, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(mem.NewProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	keyID, _, err := km.Create(kmsapi.ECDSAP256TypeIEEEP1363)
+	require.NoError(t, err)
+
+	cmd := New(&mockprovider.Provider{KMSValue: km})
+	require.NotNil(t, cmd)
+
+	handler := lookupHandler(t, cmd, exportKeyPath, http.MethodGet)
+
+	t.Run("test export key - success", func(t *testing.T) {
+		for _, query := range []string{"", "?keyType=" + kmsapi.ECDSAP256IEEEP1363} {
+			buf, code, err := sendRequestToHandler(handler, nil, keysPath+"/"+keyID+"/export"+query)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, code)
+
+			response := kms.ExportKeyResponse{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+			require.Equal(t, keyID, response.PublicKey.KeyID)
+			require.Equal(t, "P-256", response.PublicKey.Crv)
+		}
+	})
+
+	t.Run("test export key - error", func(t *testing.T) {
+		buf, code, err := sendRequestToHandler(handler, nil, keysPath+"/"+keyID+"/export?keyType=ED25519")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.ExportKeyError, "invalid Ed25519 public key", buf.Bytes())
+
+		// the key ID and type are escaped in the command request
+		buf, code, err = sendRequestToHandler(handler, nil, keysPath+"/"+keyID+"/export?keyType=%7F%22")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.ExportKeyError, "", buf.Bytes())
+	})
+}
+
+func TestDeleteKey(t *testing.T) {
+	t.Run("test delete key - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{},
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, deleteKeyPath, http.MethodPost)
+		err := getSuccessResponseFromHandler(handler, keysPath+"/k1/delete")
+		require.NoError(t, err)
+
+		err = getSuccessResponseFromHandler(handler, keysPath+"/k%7F%221/delete")
+		require.NoError(t, err)
+	})
+
+	t.Run("test delete key - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{
+			KMSValue: &mockkms.KeyManager{DeleteErr: fmt.Errorf("failed to delete key")},
+		})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, deleteKeyPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, nil, keysPath+"/k1/delete")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.DeleteKeyError, "failed to delete key", buf.Bytes())
+	})
+}
+
+func TestBackupRestoreKeys(t *testing.T) {
+	km, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(mem.NewProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	keyID, _, err := km.Create(kmsapi.ED25519Type)
+	require.NoError(t, err)
+
+	cmd := New(&mockprovider.Provider{KMSValue: km})
+	require.NotNil(t, cmd)
+
+	var archive json.RawMessage
+
+	t.Run("test backup keys - success", func(t *testing.T) {
+		handler := lookupHandler(t, cmd, backupKeysPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(`{"passphrase":"secret"}`),
+			backupKeysPath)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+
+		response := kms.BackupKeysResponse{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.NotEmpty(t, response.Archive)
+
+		archive = response.Archive
+	})
+
+	t.Run("test restore keys - success", func(t *testing.T) {
+		request, err := json.Marshal(&kms.RestoreKeysRequest{Archive: archive, Passphrase: "secret", DryRun: true})
+		require.NoError(t, err)
+
+		handler := lookupHandler(t, cmd, restoreKeysPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBuffer(request), restoreKeysPath)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+
+		response := kms.RestoreKeysResponse{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+		require.True(t, response.DryRun)
+		require.Empty(t, response.KeyIDs)
+		require.Equal(t, []string{keyID}, response.SkippedKeyIDs)
+	})
+
+	t.Run("test backup and restore keys - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{KMSValue: &mockkms.KeyManager{}})
+		require.NotNil(t, cmd)
+
+		handler := lookupHandler(t, cmd, backupKeysPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(`{"passphrase":"secret"}`),
+			backupKeysPath)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.BackupKeysError, "kms does not support key backup and restore", buf.Bytes())
+
+		handler = lookupHandler(t, cmd, restoreKeysPath, http.MethodPost)
+		buf, code, err = sendRequestToHandler(handler, bytes.NewBufferString(`{}`), restoreKeysPath)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, code)
+		verifyError(t, kms.InvalidRequestErrorCode, "archive is mandatory", buf.Bytes())
+	})
+}
+
+func TestRotateMasterKey(t *testing.T) {
+	t.Run("test rotate master key - success", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{KMSValue: &mockkms.KeyManager{}})
+		cmd.command = &mockKMSCommand{}
+
+		handler := lookupHandler(t, cmd, rotateMasterKeyPath, http.MethodPost)
+		err := getSuccessResponseFromHandler(handler, rotateMasterKeyPath)
+		require.NoError(t, err)
+
+		handler = lookupHandler(t, cmd, reEncryptKeysPath, http.MethodPost)
+		err = getSuccessResponseFromHandler(handler, reEncryptKeysPath)
+		require.NoError(t, err)
+	})
+
+	t.Run("test rotate master key - error", func(t *testing.T) {
+		cmd := New(&mockprovider.Provider{KMSValue: &mockkms.KeyManager{}})
+
+		handler := lookupHandler(t, cmd, rotateMasterKeyPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, nil, rotateMasterKeyPath)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.RotateMasterKeyError, "kms does not support master key rotation", buf.Bytes())
+
+		handler = lookupHandler(t, cmd, reEncryptKeysPath, http.MethodPost)
+		buf, code, err = sendRequestToHandler(handler, nil, reEncryptKeysPath)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, kms.RotateMasterKeyError, "kms does not support master key rotation", buf.Bytes())
+	})
+}
+
+func lookupHandler(t *testing.T, op *Operation, path, method string) rest.Handler {
 	handlers := op.GetRESTHandlers()
 	require.NotEmpty(t, handlers)
 
 	for _, h := range handlers {
-		if h.Path() == path && h.Method() == http.MethodPost {
+		if h.Path() == path && h.Method() == method {
 			return h
 		}
 	}
@@ -215,3 +450,35 @@ func (m *mockKMSCommand) CreateKeySetLegacyKMS(rw io.Writer, req io.Reader) comm
 func (m *mockKMSCommand) ImportKey(rw io.Writer, req io.Reader) command.Error {
 	return m.importKeyError
 }
+
+func (m *mockKMSCommand) ExportKey(rw io.Writer, req io.Reader) command.Error {
+	return nil
+}
+
+func (m *mockKMSCommand) ListKeys(rw io.Writer, req io.Reader) command.Error {
+	return nil
+}
+
+func (m *mockKMSCommand) DescribeKey(rw io.Writer, req io.Reader) command.Error {
+	return nil
+}
+
+func (m *mockKMSCommand) DeleteKey(rw io.Writer, req io.Reader) command.Error {
+	return nil
+}
+
+func (m *mockKMSCommand) BackupKeys(rw io.Writer, req io.Reader) command.Error {
+	return nil
+}
+
+func (m *mockKMSCommand) RestoreKeys(rw io.Writer, req io.Reader) command.Error {
+	return nil
+}
+
+func (m *mockKMSCommand) RotateMasterKey(rw io.Writer, req io.Reader) command.Error {
+	return nil
+}
+
+func (m *mockKMSCommand) ReEncryptKeys(rw io.Writer, req io.Reader) command.Error {
+	return nil
+}
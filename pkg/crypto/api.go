@@ -37,4 +37,37 @@ type Crypto interface {
 	// VerifyMAC determines if mac is a correct authentication code (MAC) for data
 	// using a matching MAC primitive in kh key handle and returns nil if so, otherwise it returns an error.
 	VerifyMAC(mac, data []byte, kh interface{}) error
+	// WrapKey will execute key wrapping of cek using apu, apv and recipient public key 'recPubKey'.
+	// 'opts' allows setting the optional sender key handle using WithSender() option. This option is used for
+	// ECDH-1PU key wrapping (ECDH-ES is used if it is not set).
+	// returns:
+	// 		RecipientWrappedKey containing the wrapped cek value
+	// 		error in case of errors
+	WrapKey(cek, apu, apv []byte, recPubKey *PublicKey, opts ...WrapKeyOpts) (*RecipientWrappedKey, error)
+	// UnwrapKey unwraps a key in recWK using recipient private key kh.
+	// 'opts' allows setting the optional sender key handle (or *PublicKey) using WithSender() option. This option
+	// is used for ECDH-1PU key unwrapping.
+	// returns:
+	// 		unwrapped key in raw bytes
+	// 		error in case of errors
+	UnwrapKey(recWK *RecipientWrappedKey, kh interface{}, opts ...WrapKeyOpts) ([]byte, error)
+}
+
+// RecipientWrappedKey contains recipient key material required to unwrap CEK
+type RecipientWrappedKey struct {
+	KID          string    `json:"kid,omitempty"`
+	EncryptedCEK []byte    `json:"encryptedcek,omitempty"`
+	EPK          PublicKey `json:"epk,omitempty"`
+	Alg          string    `json:"alg,omitempty"`
+	APU          []byte    `json:"apu,omitempty"`
+	APV          []byte    `json:"apv,omitempty"`
+}
+
+// PublicKey mainly to exchange EPK in RecipientWrappedKey
+type PublicKey struct {
+	KID   string `json:"kid,omitempty"`
+	X     []byte `json:"x,omitempty"`
+	Y     []byte `json:"y,omitempty"`
+	Curve string `json:"curve,omitempty"`
+	Type  string `json:"type,omitempty"`
 }
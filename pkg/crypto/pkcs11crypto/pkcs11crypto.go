@@ -0,0 +1,138 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+
+	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	sigverifier "github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/pkcs11kms"
+)
+
+// package pkcs11crypto provides a crypto.Crypto signing messages with the keys of a PKCS#11 token managed by
+// pkcs11kms. Only signature primitives are supported: encryption, MAC and key wrapping keys are not managed by the
+// PKCS#11 KMS.
+
+const ed25519VerificationKey2018 = "Ed25519VerificationKey2018"
+
+var (
+	errBadKeyHandleFormat = errors.New("bad key handle format")
+	errNotSupported       = errors.New("operation not supported by PKCS#11 crypto")
+)
+
+// Crypto is a crypto.Crypto executing signatures inside a PKCS#11 token.
+type Crypto struct{}
+
+// New creates a new PKCS#11 Crypto.
+func New() *Crypto {
+	return &Crypto{}
+}
+
+// Encrypt is not supported by PKCS#11 crypto.
+func (c *Crypto) Encrypt(msg, aad []byte, kh interface{}) ([]byte, []byte, error) {
+	return nil, nil, errNotSupported
+}
+
+// Decrypt is not supported by PKCS#11 crypto.
+func (c *Crypto) Decrypt(cipher, aad, nonce []byte, kh interface{}) ([]byte, error) {
+	return nil, errNotSupported
+}
+
+// Sign will sign msg inside the token using the private key kh.
+// kh must be a *pkcs11kms.KeyHandle returned by pkcs11kms.
+// returns:
+// 		signature in []byte
+//		error in case of errors
+func (c *Crypto) Sign(msg []byte, kh interface{}) ([]byte, error) {
+	keyHandle, ok := kh.(*pkcs11kms.KeyHandle)
+	if !ok {
+		return nil, errBadKeyHandleFormat
+	}
+
+	return keyHandle.Sign(msg)
+}
+
+// Verify will verify signature of msg using the public key of kh.
+// kh can be a *pkcs11kms.KeyHandle (the public key is read from the token), a *pkcs11kms.PublicKeyHandle or the
+// *verifier.PublicKey of an Ed25519VerificationKey2018 key passed by the signature suites.
+// returns:
+// 		error in case of errors or nil if signature verification was successful
+func (c *Crypto) Verify(signature, msg []byte, kh interface{}) error {
+	pubKH, err := publicKeyHandle(kh)
+	if err != nil {
+		return err
+	}
+
+	return pubKH.Verify(signature, msg)
+}
+
+// ComputeMAC is not supported by PKCS#11 crypto.
+func (c *Crypto) ComputeMAC(data []byte, kh interface{}) ([]byte, error) {
+	return nil, errNotSupported
+}
+
+// VerifyMAC is not supported by PKCS#11 crypto.
+func (c *Crypto) VerifyMAC(mac, data []byte, kh interface{}) error {
+	return errNotSupported
+}
+
+// WrapKey is not supported by PKCS#11 crypto.
+func (c *Crypto) WrapKey(cek, apu, apv []byte, recPubKey *cryptoapi.PublicKey,
+	opts ...cryptoapi.WrapKeyOpts) (*cryptoapi.RecipientWrappedKey, error) {
+	return nil, errNotSupported
+}
+
+// UnwrapKey is not supported by PKCS#11 crypto.
+func (c *Crypto) UnwrapKey(recWK *cryptoapi.RecipientWrappedKey, kh interface{},
+	opts ...cryptoapi.WrapKeyOpts) ([]byte, error) {
+	return nil, errNotSupported
+}
+
+func publicKeyHandle(kh interface{}) (*pkcs11kms.PublicKeyHandle, error) {
+	switch keyHandle := kh.(type) {
+	case *pkcs11kms.PublicKeyHandle:
+		return keyHandle, nil
+	case *pkcs11kms.KeyHandle:
+		return keyHandle.PublicKey()
+	case *sigverifier.PublicKey:
+		return verifierPublicKeyHandle(keyHandle)
+	default:
+		return nil, errBadKeyHandleFormat
+	}
+}
+
+// verifierPublicKeyHandle converts a public key resolved by the signature suites: JWK keys are expected to sign with
+// the JWS algorithms (IEEE P1363 encoded ECDSA signatures).
+func verifierPublicKeyHandle(pubKey *sigverifier.PublicKey) (*pkcs11kms.PublicKeyHandle, error) {
+	if pubKey.JWK == nil {
+		if pubKey.Type != ed25519VerificationKey2018 || len(pubKey.Value) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported public key type '%s'", pubKey.Type)
+		}
+
+		return &pkcs11kms.PublicKeyHandle{KeyType: kms.ED25519Type, PublicKey: ed25519.PublicKey(pubKey.Value)}, nil
+	}
+
+	switch key := pubKey.JWK.Key.(type) {
+	case ed25519.PublicKey:
+		return &pkcs11kms.PublicKeyHandle{KeyType: kms.ED25519Type, PublicKey: key}, nil
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return &pkcs11kms.PublicKeyHandle{KeyType: kms.ECDSAP256TypeIEEEP1363, PublicKey: key}, nil
+		case elliptic.P384():
+			return &pkcs11kms.PublicKeyHandle{KeyType: kms.ECDSAP384TypeIEEEP1363, PublicKey: key}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported JWK public key (kty '%s', crv '%s')", pubKey.JWK.Kty, pubKey.JWK.Crv)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	sigverifier "github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/pkcs11kms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockpkcs11 "github.com/hyperledger/aries-framework-go/pkg/mock/kms/pkcs11kms"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func newTestKMS(t *testing.T) *pkcs11kms.KMS {
+	t.Helper()
+
+	token, err := pkcs11kms.NewToken(mockpkcs11.NewContext(), "aries", "1234")
+	require.NoError(t, err)
+
+	return pkcs11kms.New(token)
+}
+
+func TestCrypto_SignVerify(t *testing.T) {
+	var _ cryptoapi.Crypto = (*Crypto)(nil)
+
+	k := newTestKMS(t)
+	c := New()
+	msg := []byte("test message")
+
+	for _, kt := range []kms.KeyType{
+		kms.ECDSAP256TypeDER,
+		kms.ECDSAP384TypeDER,
+		kms.ECDSAP256TypeIEEEP1363,
+		kms.ECDSAP384TypeIEEEP1363,
+		kms.ED25519Type,
+	} {
+		kt := kt
+
+		t.Run(string(kt), func(t *testing.T) {
+			keyID, kh, err := k.Create(kt)
+			require.NoError(t, err)
+
+			sig, err := c.Sign(msg, kh)
+			require.NoError(t, err)
+
+			require.NoError(t, c.Verify(sig, msg, kh))
+			require.Error(t, c.Verify(sig, []byte("other message"), kh))
+
+			pubKeyBytes, err := k.ExportPubKeyBytes(keyID)
+			require.NoError(t, err)
+
+			pubKH, err := k.PubKeyBytesToHandle(pubKeyBytes, kt)
+			require.NoError(t, err)
+			require.NoError(t, c.Verify(sig, msg, pubKH))
+		})
+	}
+
+	t.Run("bad key handle", func(t *testing.T) {
+		_, err := c.Sign(msg, "kh")
+		require.EqualError(t, err, "bad key handle format")
+
+		err = c.Verify([]byte("sig"), msg, "kh")
+		require.EqualError(t, err, "bad key handle format")
+	})
+}
+
+func TestCrypto_VerifyWithTinkCrypto(t *testing.T) {
+	k := newTestKMS(t)
+	c := New()
+	msg := []byte("test message")
+
+	localKMS, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(mem.NewProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	tinkCrypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	// PKCS#11 signatures of key types having the same hash function in both KMS are verified by tink
+	for _, kt := range []kms.KeyType{kms.ECDSAP256TypeDER, kms.ECDSAP384TypeDER, kms.ECDSAP256TypeIEEEP1363,
+		kms.ED25519Type} {
+		keyID, kh, err := k.Create(kt)
+		require.NoError(t, err)
+
+		sig, err := c.Sign(msg, kh)
+		require.NoError(t, err)
+
+		pubKeyBytes, err := k.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+
+		pubKH, err := localKMS.PubKeyBytesToHandle(pubKeyBytes, kt)
+		require.NoError(t, err, kt)
+
+		require.NoError(t, tinkCrypto.Verify(sig, msg, pubKH), kt)
+	}
+}
+
+func TestCrypto_SignatureSuite(t *testing.T) {
+	k := newTestKMS(t)
+	c := New()
+	msg := []byte("test message")
+
+	t.Run("Ed25519VerificationKey2018", func(t *testing.T) {
+		keyID, kh, err := k.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		sig, err := suite.NewCryptoSigner(c, kh).Sign(msg)
+		require.NoError(t, err)
+
+		pubKeyBytes, err := k.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+
+		err = suite.NewCryptoVerifier(c).Verify(&sigverifier.PublicKey{
+			Type:  "Ed25519VerificationKey2018",
+			Value: pubKeyBytes,
+		}, msg, sig)
+		require.NoError(t, err)
+
+		err = suite.NewCryptoVerifier(c).Verify(&sigverifier.PublicKey{
+			Type:  "X25519KeyAgreementKey2019",
+			Value: pubKeyBytes,
+		}, msg, sig)
+		require.EqualError(t, err, "unsupported public key type 'X25519KeyAgreementKey2019'")
+	})
+
+	t.Run("JWK", func(t *testing.T) {
+		for _, kt := range []kms.KeyType{kms.ECDSAP256TypeIEEEP1363, kms.ECDSAP384TypeIEEEP1363, kms.ED25519Type} {
+			_, kh, err := k.Create(kt)
+			require.NoError(t, err)
+
+			sig, err := suite.NewCryptoSigner(c, kh).Sign(msg)
+			require.NoError(t, err)
+
+			pubKH, err := kh.(*pkcs11kms.KeyHandle).PublicKey()
+			require.NoError(t, err)
+
+			jwk, err := jose.JWKFromPublicKey(pubKH.PublicKey)
+			require.NoError(t, err)
+
+			err = suite.NewCryptoVerifier(c).Verify(&sigverifier.PublicKey{Type: "JwsVerificationKey2020", JWK: jwk},
+				msg, sig)
+			require.NoError(t, err, kt)
+		}
+
+		jwk := &jose.JWK{Kty: "OKP", Crv: "X25519"}
+
+		err := suite.NewCryptoVerifier(c).Verify(&sigverifier.PublicKey{Type: "JwsVerificationKey2020", JWK: jwk},
+			msg, []byte("sig"))
+		require.EqualError(t, err, "unsupported JWK public key (kty 'OKP', crv 'X25519')")
+	})
+}
+
+func TestCrypto_NotSupported(t *testing.T) {
+	c := New()
+
+	_, _, err := c.Encrypt(nil, nil, nil)
+	require.EqualError(t, err, errNotSupported.Error())
+
+	_, err = c.Decrypt(nil, nil, nil, nil)
+	require.EqualError(t, err, errNotSupported.Error())
+
+	_, err = c.ComputeMAC(nil, nil)
+	require.EqualError(t, err, errNotSupported.Error())
+
+	err = c.VerifyMAC(nil, nil, nil)
+	require.EqualError(t, err, errNotSupported.Error())
+
+	_, err = c.WrapKey(nil, nil, nil, nil)
+	require.EqualError(t, err, errNotSupported.Error())
+
+	_, err = c.UnwrapKey(nil, nil)
+	require.EqualError(t, err, errNotSupported.Error())
+}
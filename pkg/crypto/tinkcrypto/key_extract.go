@@ -0,0 +1,167 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tinkcrypto
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	hybrid "github.com/google/tink/go/hybrid/subtle"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	ecdh1pupb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdh1pu_aead_go_proto"
+	ecdhespb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdhes_aead_go_proto"
+)
+
+const (
+	ecdhesAESPrivateKeyTypeURL    = "type.hyperledger.org/hyperledger.aries.crypto.tink.EcdhesAesAeadPrivateKey"
+	ecdhesAESPublicKeyTypeURL     = "type.hyperledger.org/hyperledger.aries.crypto.tink.EcdhesAesAeadPublicKey"
+	ecdh1puAESPrivateKeyTypeURL   = "type.hyperledger.org/hyperledger.aries.crypto.tink.Ecdh1puAesAeadPrivateKey"
+	ecdh1puAESPublicKeyTypeURL    = "type.hyperledger.org/hyperledger.aries.crypto.tink.Ecdh1puAesAeadPublicKey"
+	ecdhesX25519PrivateKeyTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.EcdhesX25519AeadPrivateKey"
+	ecdhesX25519PublicKeyTypeURL  = "type.hyperledger.org/hyperledger.aries.crypto.tink.EcdhesX25519AeadPublicKey"
+)
+
+// rawECDHKey holds key values read from ECDH-ES/ECDH-1PU key protos.
+type rawECDHKey struct {
+	curve    commonpb.EllipticCurveType
+	x, y, d  []byte
+	isX25519 bool
+}
+
+// extractPrivKey returns *ecdsa.PrivateKey or x25519PrivateKey of the primary key of the private key handle kh.
+func extractPrivKey(kh interface{}) (interface{}, error) {
+	key, err := readPrimaryKey(kh, tinkpb.KeyData_ASYMMETRIC_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.isX25519 {
+		return x25519PrivateKey(key.d), nil
+	}
+
+	c, err := hybrid.GetCurve(key.curve.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return ecPrivateKey(c, key.d), nil
+}
+
+// extractPubKey returns *ecdsa.PublicKey or x25519PublicKey of pubKey which is either a *crypto.PublicKey or a
+// key handle (public or private).
+func extractPubKey(pubKey interface{}) (interface{}, error) {
+	if pk, ok := pubKey.(*cryptoapi.PublicKey); ok {
+		return convertPublicKey(pk)
+	}
+
+	key, err := readPrimaryKey(pubKey, tinkpb.KeyData_ASYMMETRIC_PUBLIC)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.isX25519 {
+		return convertPublicKey(&cryptoapi.PublicKey{X: key.x, Curve: x25519Curve, Type: okpKeyType})
+	}
+
+	return convertPublicKey(&cryptoapi.PublicKey{X: key.x, Y: key.y, Curve: key.curve.String(), Type: ecKeyType})
+}
+
+// readPrimaryKey reads the primary key of the key handle. Private keys are accepted when a public key is expected.
+func readPrimaryKey(kh interface{}, materialType tinkpb.KeyData_KeyMaterialType) (*rawECDHKey, error) {
+	keyHandle, ok := kh.(*keyset.Handle)
+	if !ok {
+		return nil, errBadKeyHandleFormat
+	}
+
+	ks := insecurecleartextkeyset.KeysetMaterial(keyHandle)
+
+	for _, k := range ks.Key {
+		if k.KeyId != ks.PrimaryKeyId || k.KeyData == nil {
+			continue
+		}
+
+		if materialType == tinkpb.KeyData_ASYMMETRIC_PRIVATE &&
+			k.KeyData.KeyMaterialType != tinkpb.KeyData_ASYMMETRIC_PRIVATE {
+			return nil, errors.New("private key handle is required")
+		}
+
+		return unmarshalECDHKey(k.KeyData)
+	}
+
+	return nil, errors.New("primary key not found in key handle")
+}
+
+func unmarshalECDHKey(keyData *tinkpb.KeyData) (*rawECDHKey, error) {
+	switch keyData.TypeUrl {
+	case ecdhesAESPrivateKeyTypeURL, ecdhesX25519PrivateKeyTypeURL:
+		key := new(ecdhespb.EcdhesAeadPrivateKey)
+		if err := proto.Unmarshal(keyData.Value, key); err != nil || key.PublicKey == nil {
+			return nil, errors.New("invalid ECDH-ES private key")
+		}
+
+		raw := ecdhesPublicKey(key.PublicKey)
+		raw.d = key.KeyValue
+		raw.isX25519 = keyData.TypeUrl == ecdhesX25519PrivateKeyTypeURL
+
+		return raw, nil
+	case ecdhesAESPublicKeyTypeURL, ecdhesX25519PublicKeyTypeURL:
+		key := new(ecdhespb.EcdhesAeadPublicKey)
+		if err := proto.Unmarshal(keyData.Value, key); err != nil {
+			return nil, errors.New("invalid ECDH-ES public key")
+		}
+
+		raw := ecdhesPublicKey(key)
+		raw.isX25519 = keyData.TypeUrl == ecdhesX25519PublicKeyTypeURL
+
+		return raw, nil
+	case ecdh1puAESPrivateKeyTypeURL:
+		key := new(ecdh1pupb.Ecdh1PuAeadPrivateKey)
+		if err := proto.Unmarshal(keyData.Value, key); err != nil || key.PublicKey == nil {
+			return nil, errors.New("invalid ECDH-1PU private key")
+		}
+
+		raw := ecdh1puPublicKey(key.PublicKey)
+		raw.d = key.KeyValue
+
+		return raw, nil
+	case ecdh1puAESPublicKeyTypeURL:
+		key := new(ecdh1pupb.Ecdh1PuAeadPublicKey)
+		if err := proto.Unmarshal(keyData.Value, key); err != nil {
+			return nil, errors.New("invalid ECDH-1PU public key")
+		}
+
+		return ecdh1puPublicKey(key), nil
+	default:
+		return nil, fmt.Errorf("key type '%s' is not supported for key wrapping", keyData.TypeUrl)
+	}
+}
+
+func ecdhesPublicKey(key *ecdhespb.EcdhesAeadPublicKey) *rawECDHKey {
+	raw := &rawECDHKey{x: key.X, y: key.Y}
+
+	if key.Params != nil && key.Params.KwParams != nil {
+		raw.curve = key.Params.KwParams.CurveType
+	}
+
+	return raw
+}
+
+func ecdh1puPublicKey(key *ecdh1pupb.Ecdh1PuAeadPublicKey) *rawECDHKey {
+	raw := &rawECDHKey{x: key.X, y: key.Y}
+
+	if key.Params != nil && key.Params.KwParams != nil {
+		raw.curve = key.Params.KwParams.CurveType
+	}
+
+	return raw
+}
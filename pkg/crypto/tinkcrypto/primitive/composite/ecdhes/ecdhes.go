@@ -104,4 +104,9 @@ func init() {
 	if err != nil {
 		panic(fmt.Sprintf("ecdhes.init() failed: %v", err))
 	}
+
+	err = registry.RegisterKeyManager(newECDHESX25519PrivateKeyManager())
+	if err != nil {
+		panic(fmt.Sprintf("ecdhes.init() failed: %v", err))
+	}
 }
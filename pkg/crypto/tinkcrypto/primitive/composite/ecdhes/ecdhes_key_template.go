@@ -47,6 +47,17 @@ func ECDHES521KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
 	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P521, nil)
 }
 
+// ECDHESX25519KWAES256GCMKeyTemplate is a KeyTemplate that generates an X25519 key agreement key. It is used to
+// represent a recipient (or ECDH-1PU sender) key for crypto.WrapKey() and crypto.UnwrapKey() with the following
+// parameters:
+//  - Key Wrapping: ECDH-ES over A256KW as per https://tools.ietf.org/html/rfc7518#appendix-A.2
+//  - KDF: Concat KDF as per https://tools.ietf.org/html/rfc7518#section-4.6
+// Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS
+func ECDHESX25519KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
+	return newKeyTemplate(ecdhesX25519PrivateKeyTypeURL, commonpb.EllipticCurveType_CURVE25519,
+		compositepb.KeyType_OKP, nil)
+}
+
 // ECDHES256KWAES256GCMKeyTemplateWithRecipients is similar to ECDHES256KWAES256GCMKeyTemplate but adding recipients
 // keys to execute the CompositeEncrypt primitive for encrypting a message targeted to one ore more recipients.
 // Keys from this template offer valid CompositeEncrypt primitive execution only and should not be stored in the KMS
@@ -117,11 +128,16 @@ func createECDHESPublicKeys(recRawPublicKeys []composite.PublicKey) ([]*composit
 // createKeyTemplate creates a new ECDHES-AEAD key template with the given key
 // size in bytes.
 func createKeyTemplate(c commonpb.EllipticCurveType, r []*compositepb.ECPublicKey) *tinkpb.KeyTemplate {
+	return newKeyTemplate(ecdhesAESPrivateKeyTypeURL, c, compositepb.KeyType_EC, r)
+}
+
+func newKeyTemplate(typeURL string, c commonpb.EllipticCurveType, kt compositepb.KeyType,
+	r []*compositepb.ECPublicKey) *tinkpb.KeyTemplate {
 	format := &ecdhespb.EcdhesAeadKeyFormat{
 		Params: &ecdhespb.EcdhesAeadParams{
 			KwParams: &ecdhespb.EcdhesKwParams{
 				CurveType:  c,
-				KeyType:    compositepb.KeyType_EC,
+				KeyType:    kt,
 				Recipients: r,
 			},
 			EncParams: &ecdhespb.EcdhesAeadEncParams{
@@ -137,7 +153,7 @@ func createKeyTemplate(c commonpb.EllipticCurveType, r []*compositepb.ECPublicKe
 	}
 
 	return &tinkpb.KeyTemplate{
-		TypeUrl:          ecdhesAESPrivateKeyTypeURL,
+		TypeUrl:          typeURL,
 		Value:            serializedFormat,
 		OutputPrefixType: tinkpb.OutputPrefixType_RAW,
 	}
@@ -6,4 +6,167 @@ SPDX-License-Identifier: Apache-2.0
 
 package ecdhes
 
-// TODO implement
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/core/registry"
+	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"golang.org/x/crypto/curve25519"
+
+	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+	ecdhespb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdhes_aead_go_proto"
+)
+
+const (
+	ecdhesX25519PrivateKeyVersion = 0
+	ecdhesX25519PrivateKeyTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.EcdhesX25519AeadPrivateKey"
+	ecdhesX25519PublicKeyTypeURL  = "type.hyperledger.org/hyperledger.aries.crypto.tink.EcdhesX25519AeadPublicKey"
+)
+
+// common errors
+var errInvalidECDHESX25519PrivateKey = errors.New("ecdhes_x25519_private_key_manager: invalid key")
+var errInvalidECDHESX25519PrivateKeyFormat = errors.New("ecdhes_x25519_private_key_manager: invalid key format")
+
+// ecdhesX25519PrivateKeyManager is an implementation of PrivateKeyManager interface for X25519 key agreement keys.
+// It generates new ECDHESPrivateKey (OKP) keys to be used for ECDH-ES/ECDH-1PU key wrapping via crypto.WrapKey()
+// and crypto.UnwrapKey(). Composite decryption primitive is not supported for these keys yet.
+type ecdhesX25519PrivateKeyManager struct{}
+
+// Assert that ecdhesX25519PrivateKeyManager implements the PrivateKeyManager interface.
+var _ registry.PrivateKeyManager = (*ecdhesX25519PrivateKeyManager)(nil)
+
+// newECDHESX25519PrivateKeyManager creates a new ecdhesX25519PrivateKeyManager.
+func newECDHESX25519PrivateKeyManager() *ecdhesX25519PrivateKeyManager {
+	return new(ecdhesX25519PrivateKeyManager)
+}
+
+// Primitive is not supported for X25519 keys, use crypto.UnwrapKey() instead.
+func (km *ecdhesX25519PrivateKeyManager) Primitive(serializedKey []byte) (interface{}, error) {
+	return nil, errors.New("ecdhes_x25519_private_key_manager: composite decrypt primitive is not supported")
+}
+
+// NewKey creates a new key according to the specification of ECDHESPrivateKey format.
+func (km *ecdhesX25519PrivateKeyManager) NewKey(serializedKeyFormat []byte) (proto.Message, error) {
+	if len(serializedKeyFormat) == 0 {
+		return nil, errInvalidECDHESX25519PrivateKeyFormat
+	}
+
+	keyFormat := new(ecdhespb.EcdhesAeadKeyFormat)
+
+	err := proto.Unmarshal(serializedKeyFormat, keyFormat)
+	if err != nil {
+		return nil, errInvalidECDHESX25519PrivateKeyFormat
+	}
+
+	err = validateX25519KeyFormat(keyFormat.Params)
+	if err != nil {
+		return nil, errInvalidECDHESX25519PrivateKeyFormat
+	}
+
+	privKey := make([]byte, curve25519.ScalarSize)
+
+	_, err = rand.Read(privKey)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes_x25519_private_key_manager: generate key failed: %w", err)
+	}
+
+	pubKey, err := curve25519.X25519(privKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes_x25519_private_key_manager: generate key failed: %w", err)
+	}
+
+	return &ecdhespb.EcdhesAeadPrivateKey{
+		Version:  ecdhesX25519PrivateKeyVersion,
+		KeyValue: privKey,
+		PublicKey: &ecdhespb.EcdhesAeadPublicKey{
+			Version: ecdhesX25519PrivateKeyVersion,
+			Params:  keyFormat.Params,
+			X:       pubKey,
+		},
+	}, nil
+}
+
+// NewKeyData creates a new KeyData according to the specification of ECDHESPrivateKey Format.
+// It should be used solely by the key management API.
+func (km *ecdhesX25519PrivateKeyManager) NewKeyData(serializedKeyFormat []byte) (*tinkpb.KeyData, error) {
+	key, err := km.NewKey(serializedKeyFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	serializedKey, err := proto.Marshal(key)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes_x25519_private_key_manager: Proto.Marshal failed: %w", err)
+	}
+
+	return &tinkpb.KeyData{
+		TypeUrl:         ecdhesX25519PrivateKeyTypeURL,
+		Value:           serializedKey,
+		KeyMaterialType: tinkpb.KeyData_ASYMMETRIC_PRIVATE,
+	}, nil
+}
+
+// PublicKeyData returns the enclosed public key data of serializedPrivKey
+func (km *ecdhesX25519PrivateKeyManager) PublicKeyData(serializedPrivKey []byte) (*tinkpb.KeyData, error) {
+	privKey := new(ecdhespb.EcdhesAeadPrivateKey)
+
+	err := proto.Unmarshal(serializedPrivKey, privKey)
+	if err != nil {
+		return nil, errInvalidECDHESX25519PrivateKey
+	}
+
+	err = keyset.ValidateKeyVersion(privKey.Version, ecdhesX25519PrivateKeyVersion)
+	if err != nil || privKey.PublicKey == nil {
+		return nil, errInvalidECDHESX25519PrivateKey
+	}
+
+	serializedPubKey, err := proto.Marshal(privKey.PublicKey)
+	if err != nil {
+		return nil, errInvalidECDHESX25519PrivateKey
+	}
+
+	return &tinkpb.KeyData{
+		TypeUrl:         ecdhesX25519PublicKeyTypeURL,
+		Value:           serializedPubKey,
+		KeyMaterialType: tinkpb.KeyData_ASYMMETRIC_PUBLIC,
+	}, nil
+}
+
+// DoesSupport indicates if this key manager supports the given key type.
+func (km *ecdhesX25519PrivateKeyManager) DoesSupport(typeURL string) bool {
+	return typeURL == ecdhesX25519PrivateKeyTypeURL
+}
+
+// TypeURL returns the key type of keys managed by this key manager.
+func (km *ecdhesX25519PrivateKeyManager) TypeURL() string {
+	return ecdhesX25519PrivateKeyTypeURL
+}
+
+// validateX25519KeyFormat validates the given X25519 ECDHESKeyFormat.
+func validateX25519KeyFormat(params *ecdhespb.EcdhesAeadParams) error {
+	if params == nil || params.KwParams == nil || params.EncParams == nil {
+		return errors.New("ecdhes_x25519_private_key_manager: missing key params")
+	}
+
+	if params.KwParams.CurveType != commonpb.EllipticCurveType_CURVE25519 ||
+		params.KwParams.KeyType != compositepb.KeyType_OKP {
+		return errors.New("ecdhes_x25519_private_key_manager: curve must be X25519 with OKP key type")
+	}
+
+	km, err := registry.GetKeyManager(params.EncParams.AeadEnc.TypeUrl)
+	if err != nil {
+		return fmt.Errorf("ecdhes_x25519_private_key_manager: GetKeyManager error: %w", err)
+	}
+
+	_, err = km.NewKeyData(params.EncParams.AeadEnc.Value)
+	if err != nil {
+		return fmt.Errorf("ecdhes_x25519_private_key_manager: NewKeyData error: %w", err)
+	}
+
+	return nil
+}
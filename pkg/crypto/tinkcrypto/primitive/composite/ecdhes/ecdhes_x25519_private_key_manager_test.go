@@ -0,0 +1,71 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ecdhes
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/curve25519"
+
+	ecdhespb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdhes_aead_go_proto"
+)
+
+func TestECDHESX25519PrivateKeyManager(t *testing.T) {
+	km := newECDHESX25519PrivateKeyManager()
+	require.True(t, km.DoesSupport(ecdhesX25519PrivateKeyTypeURL))
+	require.Equal(t, ecdhesX25519PrivateKeyTypeURL, km.TypeURL())
+
+	t.Run("create new key", func(t *testing.T) {
+		keyData, err := km.NewKeyData(ECDHESX25519KWAES256GCMKeyTemplate().Value)
+		require.NoError(t, err)
+		require.Equal(t, ecdhesX25519PrivateKeyTypeURL, keyData.TypeUrl)
+
+		privKey := new(ecdhespb.EcdhesAeadPrivateKey)
+		require.NoError(t, proto.Unmarshal(keyData.Value, privKey))
+		require.Len(t, privKey.KeyValue, curve25519.ScalarSize)
+
+		pubKey, err := curve25519.X25519(privKey.KeyValue, curve25519.Basepoint)
+		require.NoError(t, err)
+		require.Equal(t, pubKey, privKey.PublicKey.X)
+
+		pubKeyData, err := km.PublicKeyData(keyData.Value)
+		require.NoError(t, err)
+		require.Equal(t, ecdhesX25519PublicKeyTypeURL, pubKeyData.TypeUrl)
+
+		_, err = km.Primitive(keyData.Value)
+		require.EqualError(t, err, "ecdhes_x25519_private_key_manager: composite decrypt primitive is not supported")
+	})
+
+	t.Run("create new key handle", func(t *testing.T) {
+		kh, err := keyset.NewHandle(ECDHESX25519KWAES256GCMKeyTemplate())
+		require.NoError(t, err)
+
+		pubKH, err := kh.Public()
+		require.NoError(t, err)
+		require.NotNil(t, pubKH)
+	})
+
+	t.Run("invalid key format", func(t *testing.T) {
+		_, err := km.NewKey(nil)
+		require.EqualError(t, err, errInvalidECDHESX25519PrivateKeyFormat.Error())
+
+		_, err = km.NewKey([]byte("bad format"))
+		require.EqualError(t, err, errInvalidECDHESX25519PrivateKeyFormat.Error())
+
+		// EC key format is not accepted
+		_, err = km.NewKey(ECDHES256KWAES256GCMKeyTemplate().Value)
+		require.EqualError(t, err, errInvalidECDHESX25519PrivateKeyFormat.Error())
+	})
+
+	t.Run("invalid private key", func(t *testing.T) {
+		_, err := km.PublicKeyData([]byte("bad key"))
+		require.EqualError(t, err, errInvalidECDHESX25519PrivateKey.Error())
+	})
+}
@@ -8,6 +8,7 @@ package keyio
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
@@ -25,8 +26,11 @@ import (
 // key (aka PublicKeyToHandle to be used as a valid Tink key)
 
 const (
-	ecdhesAESPublicKeyTypeURL  = "type.hyperledger.org/hyperledger.aries.crypto.tink.EcdhesAesAeadPublicKey"
-	ecdh1puAESPublicKeyTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.Ecdh1puAesAeadPublicKey"
+	ecdhesAESPublicKeyTypeURL    = "type.hyperledger.org/hyperledger.aries.crypto.tink.EcdhesAesAeadPublicKey"
+	ecdh1puAESPublicKeyTypeURL   = "type.hyperledger.org/hyperledger.aries.crypto.tink.Ecdh1puAesAeadPublicKey"
+	ecdhesX25519PublicKeyTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.EcdhesX25519AeadPublicKey"
+
+	x25519Curve = "X25519"
 )
 
 // PubKeyWriter will write the raw bytes of a Tink KeySet's primary public key. The raw bytes are a marshaled
@@ -117,6 +121,8 @@ func protoToCompositeKey(keyData *tinkpb.KeyData) (*composite.PublicKey, error)
 		if err != nil {
 			return nil, err
 		}
+	case ecdhesX25519PublicKeyTypeURL:
+		return newX25519Key(keyData.Value)
 	default:
 		return nil, fmt.Errorf("can't export key with keyURL:%s", keyData.TypeUrl)
 	}
@@ -195,6 +201,31 @@ func (e *ecdhesKey) y() []byte {
 	return e.protoKey.Y
 }
 
+func newX25519Key(mKey []byte) (*composite.PublicKey, error) {
+	pubKeyProto := new(ecdhespb.EcdhesAeadPublicKey)
+
+	err := proto.Unmarshal(mKey, pubKeyProto)
+	if err != nil {
+		return nil, err
+	}
+
+	if pubKeyProto.Params == nil || pubKeyProto.Params.KwParams == nil {
+		return nil, errors.New("invalid key: missing key wrapping params")
+	}
+
+	// validate key type
+	if pubKeyProto.Params.KwParams.KeyType != commonpb.KeyType_OKP {
+		return nil, fmt.Errorf("undefined key type: '%s'", pubKeyProto.Params.KwParams.KeyType)
+	}
+
+	return &composite.PublicKey{
+		KID:   pubKeyProto.KID,
+		Type:  pubKeyProto.Params.KwParams.KeyType.String(),
+		Curve: x25519Curve,
+		X:     pubKeyProto.X,
+	}, nil
+}
+
 type ecdh1puKey struct {
 	protoKey *ecdh1pupb.Ecdh1PuAeadPublicKey
 }
@@ -53,6 +53,10 @@ func TestPubKeyExport(t *testing.T) {
 			tcName:      "export then read AES256GCM with ECDH1PU P-521 public key",
 			keyTemplate: ecdh1pu.ECDH1PU521KWAES256GCMKeyTemplate(),
 		},
+		{
+			tcName:      "export then read ECDHES X25519 public key",
+			keyTemplate: ecdhes.ECDHESX25519KWAES256GCMKeyTemplate(),
+		},
 	}
 
 	for _, tc := range flagTests {
@@ -136,6 +140,17 @@ func TestNegativeCases(t *testing.T) {
 		require.EqualError(t, err, "undefined key type: 'UNKNOWN_KEY_TYPE'")
 	})
 
+	t.Run("test protoToCompositeKey() with X25519 key without params", func(t *testing.T) {
+		mKey, err := proto.Marshal(&ecdhespb.EcdhesAeadPublicKey{KID: "0123", X: []byte("x")})
+		require.NoError(t, err)
+
+		_, err = protoToCompositeKey(&tinkpb.KeyData{
+			TypeUrl: ecdhesX25519PublicKeyTypeURL,
+			Value:   mKey,
+		})
+		require.EqualError(t, err, "invalid key: missing key wrapping params")
+	})
+
 	t.Run("test WriteEncrypted() should fail since it's not supported by Writer", func(t *testing.T) {
 		kh, err := keyset.NewHandle(ecdhes.ECDHES256KWAES256GCMKeyTemplate())
 		require.NoError(t, err)
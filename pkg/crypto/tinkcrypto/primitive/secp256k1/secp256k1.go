@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package secp256k1 provides Tink key managers for ECDSA signing keys on the secp256k1 curve, which Tink does not
+// support. Keys are stored with the Tink ECDSA key protos under their own type URLs, the curve of their params is
+// left unknown. Signatures are SHA-256 digests signed in the IEEE P1363 format (as used by JWS ES256K).
+//
+// Example:
+//
+//  package main
+//
+//  import (
+//      "github.com/google/tink/go/keyset"
+//      "github.com/google/tink/go/signature"
+//
+//      "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/secp256k1"
+//  )
+//
+//  func main() {
+//      kh, err := keyset.NewHandle(secp256k1.KeyTemplate())
+//      if err != nil {
+//          // handle error
+//      }
+//
+//      s, err := signature.NewSigner(kh)
+//      if err != nil {
+//          // handle error
+//      }
+//
+//      sig, err := s.Sign([]byte("message"))
+//  }
+package secp256k1
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/core/registry"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+)
+
+// TODO - avoid the tink registry singleton.
+func init() {
+	err := registry.RegisterKeyManager(newSignerKeyManager())
+	if err != nil {
+		panic(fmt.Sprintf("secp256k1.init() failed: %v", err))
+	}
+
+	err = registry.RegisterKeyManager(newVerifierKeyManager())
+	if err != nil {
+		panic(fmt.Sprintf("secp256k1.init() failed: %v", err))
+	}
+}
+
+// KeyTemplate is a KeyTemplate that generates a new secp256k1 ECDSA private key signing SHA-256 digests in the
+// IEEE P1363 format. Signatures are not prefixed (RAW output prefix).
+func KeyTemplate() *tinkpb.KeyTemplate {
+	format := &ecdsapb.EcdsaKeyFormat{Params: Params()}
+	serializedFormat, _ := proto.Marshal(format) //nolint:errcheck
+
+	return &tinkpb.KeyTemplate{
+		TypeUrl:          privateKeyTypeURL,
+		Value:            serializedFormat,
+		OutputPrefixType: tinkpb.OutputPrefixType_RAW,
+	}
+}
+
+// Params returns the ECDSA params of secp256k1 keys.
+func Params() *ecdsapb.EcdsaParams {
+	return &ecdsapb.EcdsaParams{
+		HashType: commonpb.HashType_SHA256,
+		Curve:    commonpb.EllipticCurveType_UNKNOWN_CURVE,
+		Encoding: ecdsapb.EcdsaSignatureEncoding_IEEE_P1363,
+	}
+}
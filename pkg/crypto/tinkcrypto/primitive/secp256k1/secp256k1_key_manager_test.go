@@ -0,0 +1,173 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package secp256k1
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
+	"github.com/google/tink/go/signature"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	kh, err := keyset.NewHandle(KeyTemplate())
+	require.NoError(t, err)
+
+	s, err := signature.NewSigner(kh)
+	require.NoError(t, err)
+
+	msg := []byte("test message")
+
+	sig, err := s.Sign(msg)
+	require.NoError(t, err)
+	require.Len(t, sig, 2*coordinateSize)
+
+	pubKH, err := kh.Public()
+	require.NoError(t, err)
+
+	v, err := signature.NewVerifier(pubKH)
+	require.NoError(t, err)
+
+	require.NoError(t, v.Verify(sig, msg))
+	require.Error(t, v.Verify(sig, []byte("other message")))
+
+	pubKeyData, err := newSignerKeyManager().PublicKeyData(mustNewKeyData(t))
+	require.NoError(t, err)
+
+	p, err := newVerifierKeyManager().Primitive(pubKeyData.Value)
+	require.NoError(t, err)
+
+	require.EqualError(t, p.(*verifier).Verify(sig, msg), "secp256k1_verifier: invalid signature")
+	require.EqualError(t, p.(*verifier).Verify(sig[1:], msg), "secp256k1_verifier: invalid signature size")
+}
+
+func TestSignerKeyManager(t *testing.T) {
+	km := newSignerKeyManager()
+
+	require.True(t, km.DoesSupport(privateKeyTypeURL))
+	require.False(t, km.DoesSupport(publicKeyTypeURL))
+	require.Equal(t, privateKeyTypeURL, km.TypeURL())
+
+	keyData, err := km.NewKeyData(KeyTemplate().Value)
+	require.NoError(t, err)
+
+	t.Run("invalid key formats", func(t *testing.T) {
+		_, err := km.NewKey(nil)
+		require.EqualError(t, err, errInvalidPrivateKeyFormat.Error())
+
+		_, err = km.NewKey([]byte("bad format"))
+		require.EqualError(t, err, errInvalidPrivateKeyFormat.Error())
+
+		badFormat, err := proto.Marshal(&ecdsapb.EcdsaKeyFormat{Params: &ecdsapb.EcdsaParams{
+			HashType: commonpb.HashType_SHA512,
+			Encoding: ecdsapb.EcdsaSignatureEncoding_IEEE_P1363,
+		}})
+		require.NoError(t, err)
+
+		_, err = km.NewKeyData(badFormat)
+		require.EqualError(t, err, errInvalidPrivateKeyFormat.Error())
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		_, err := km.Primitive(nil)
+		require.EqualError(t, err, errInvalidPrivateKey.Error())
+
+		_, err = km.Primitive([]byte("bad key"))
+		require.EqualError(t, err, errInvalidPrivateKey.Error())
+
+		_, err = km.PublicKeyData([]byte("bad key"))
+		require.EqualError(t, err, errInvalidPrivateKey.Error())
+
+		key := new(ecdsapb.EcdsaPrivateKey)
+		require.NoError(t, proto.Unmarshal(keyData.Value, key))
+
+		// private key not matching its public key
+		otherKeyData, err := km.NewKeyData(KeyTemplate().Value)
+		require.NoError(t, err)
+
+		otherKey := new(ecdsapb.EcdsaPrivateKey)
+		require.NoError(t, proto.Unmarshal(otherKeyData.Value, otherKey))
+
+		otherKey.PublicKey = key.PublicKey
+
+		serialized, err := proto.Marshal(otherKey)
+		require.NoError(t, err)
+
+		_, err = km.Primitive(serialized)
+		require.EqualError(t, err, errInvalidPrivateKey.Error())
+
+		// public key not on the curve
+		key.PublicKey.X = []byte{1}
+
+		serialized, err = proto.Marshal(key)
+		require.NoError(t, err)
+
+		_, err = km.Primitive(serialized)
+		require.EqualError(t, err,
+			"secp256k1_signer_key_manager: public key is not on the secp256k1 curve")
+
+		// unsupported version
+		key.Version = privateKeyVersion + 1
+
+		serialized, err = proto.Marshal(key)
+		require.NoError(t, err)
+
+		_, err = km.Primitive(serialized)
+		require.EqualError(t, err, errInvalidPrivateKey.Error())
+
+		_, err = km.PublicKeyData(serialized)
+		require.EqualError(t, err, errInvalidPrivateKey.Error())
+	})
+}
+
+func TestVerifierKeyManager(t *testing.T) {
+	km := newVerifierKeyManager()
+
+	require.True(t, km.DoesSupport(publicKeyTypeURL))
+	require.False(t, km.DoesSupport(privateKeyTypeURL))
+	require.Equal(t, publicKeyTypeURL, km.TypeURL())
+
+	_, err := km.NewKey(nil)
+	require.EqualError(t, err, errVerifierNotImplemented.Error())
+
+	_, err = km.NewKeyData(nil)
+	require.EqualError(t, err, errVerifierNotImplemented.Error())
+
+	_, err = km.Primitive(nil)
+	require.EqualError(t, err, errInvalidPublicKey.Error())
+
+	_, err = km.Primitive([]byte("bad key"))
+	require.EqualError(t, err, errInvalidPublicKey.Error())
+
+	pubKeyData, err := newSignerKeyManager().PublicKeyData(mustNewKeyData(t))
+	require.NoError(t, err)
+
+	key := new(ecdsapb.EcdsaPublicKey)
+	require.NoError(t, proto.Unmarshal(pubKeyData.Value, key))
+
+	key.Params.Encoding = ecdsapb.EcdsaSignatureEncoding_DER
+
+	serialized, err := proto.Marshal(key)
+	require.NoError(t, err)
+
+	_, err = km.Primitive(serialized)
+	require.EqualError(t, err, "secp256k1_verifier_key_manager: invalid params: SHA-256 hash and IEEE P1363 "+
+		"encoding are required")
+}
+
+func mustNewKeyData(t *testing.T) []byte {
+	t.Helper()
+
+	keyData, err := newSignerKeyManager().NewKeyData(KeyTemplate().Value)
+	require.NoError(t, err)
+
+	return keyData.Value
+}
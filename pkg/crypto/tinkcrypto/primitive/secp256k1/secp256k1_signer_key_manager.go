@@ -0,0 +1,181 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package secp256k1
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/core/registry"
+	"github.com/google/tink/go/keyset"
+	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+)
+
+const (
+	privateKeyVersion = 0
+	privateKeyTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.Secp256k1EcdsaPrivateKey"
+)
+
+// common errors
+var errInvalidPrivateKey = errors.New("secp256k1_signer_key_manager: invalid key")
+var errInvalidPrivateKeyFormat = errors.New("secp256k1_signer_key_manager: invalid key format")
+
+// signerKeyManager is an implementation of PrivateKeyManager interface for secp256k1 ECDSA keys.
+// It generates new EcdsaPrivateKey keys and produces new instances of the signer primitive.
+type signerKeyManager struct{}
+
+// Assert that signerKeyManager implements the PrivateKeyManager interface.
+var _ registry.PrivateKeyManager = (*signerKeyManager)(nil)
+
+// newSignerKeyManager creates a new signerKeyManager.
+func newSignerKeyManager() *signerKeyManager {
+	return new(signerKeyManager)
+}
+
+// Primitive creates a signer for the given serialized EcdsaPrivateKey proto.
+func (km *signerKeyManager) Primitive(serializedKey []byte) (interface{}, error) {
+	if len(serializedKey) == 0 {
+		return nil, errInvalidPrivateKey
+	}
+
+	key := new(ecdsapb.EcdsaPrivateKey)
+
+	err := proto.Unmarshal(serializedKey, key)
+	if err != nil {
+		return nil, errInvalidPrivateKey
+	}
+
+	err = keyset.ValidateKeyVersion(key.Version, privateKeyVersion)
+	if err != nil || key.PublicKey == nil {
+		return nil, errInvalidPrivateKey
+	}
+
+	pubKey, err := publicKeyFromProto(key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("secp256k1_signer_key_manager: %w", err)
+	}
+
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), key.KeyValue)
+	if privKey.X.Cmp(pubKey.X) != 0 || privKey.Y.Cmp(pubKey.Y) != 0 {
+		return nil, errInvalidPrivateKey
+	}
+
+	return &signer{privateKey: privKey.ToECDSA()}, nil
+}
+
+// NewKey creates a new key according to the specification of EcdsaKeyFormat.
+func (km *signerKeyManager) NewKey(serializedKeyFormat []byte) (proto.Message, error) {
+	if len(serializedKeyFormat) == 0 {
+		return nil, errInvalidPrivateKeyFormat
+	}
+
+	keyFormat := new(ecdsapb.EcdsaKeyFormat)
+
+	err := proto.Unmarshal(serializedKeyFormat, keyFormat)
+	if err != nil {
+		return nil, errInvalidPrivateKeyFormat
+	}
+
+	err = validateParams(keyFormat.Params)
+	if err != nil {
+		return nil, errInvalidPrivateKeyFormat
+	}
+
+	privKey, err := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("secp256k1_signer_key_manager: generate key failed: %w", err)
+	}
+
+	return &ecdsapb.EcdsaPrivateKey{
+		Version:   privateKeyVersion,
+		PublicKey: newPublicKeyProto(&privKey.PublicKey),
+		KeyValue:  privKey.D.Bytes(),
+	}, nil
+}
+
+// NewKeyData creates a new KeyData according to the specification of EcdsaKeyFormat.
+// It should be used solely by the key management API.
+func (km *signerKeyManager) NewKeyData(serializedKeyFormat []byte) (*tinkpb.KeyData, error) {
+	key, err := km.NewKey(serializedKeyFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	serializedKey, err := proto.Marshal(key)
+	if err != nil {
+		return nil, fmt.Errorf("secp256k1_signer_key_manager: Proto.Marshal failed: %w", err)
+	}
+
+	return &tinkpb.KeyData{
+		TypeUrl:         privateKeyTypeURL,
+		Value:           serializedKey,
+		KeyMaterialType: tinkpb.KeyData_ASYMMETRIC_PRIVATE,
+	}, nil
+}
+
+// PublicKeyData returns the enclosed public key data of serializedPrivKey.
+func (km *signerKeyManager) PublicKeyData(serializedPrivKey []byte) (*tinkpb.KeyData, error) {
+	privKey := new(ecdsapb.EcdsaPrivateKey)
+
+	err := proto.Unmarshal(serializedPrivKey, privKey)
+	if err != nil {
+		return nil, errInvalidPrivateKey
+	}
+
+	err = keyset.ValidateKeyVersion(privKey.Version, privateKeyVersion)
+	if err != nil || privKey.PublicKey == nil {
+		return nil, errInvalidPrivateKey
+	}
+
+	serializedPubKey, err := proto.Marshal(privKey.PublicKey)
+	if err != nil {
+		return nil, errInvalidPrivateKey
+	}
+
+	return &tinkpb.KeyData{
+		TypeUrl:         publicKeyTypeURL,
+		Value:           serializedPubKey,
+		KeyMaterialType: tinkpb.KeyData_ASYMMETRIC_PUBLIC,
+	}, nil
+}
+
+// DoesSupport indicates if this key manager supports the given key type.
+func (km *signerKeyManager) DoesSupport(typeURL string) bool {
+	return typeURL == privateKeyTypeURL
+}
+
+// TypeURL returns the key type of keys managed by this key manager.
+func (km *signerKeyManager) TypeURL() string {
+	return privateKeyTypeURL
+}
+
+// signer signs SHA-256 digests with a secp256k1 private key in the IEEE P1363 format, it implements tink.Signer.
+type signer struct {
+	privateKey *ecdsa.PrivateKey
+}
+
+// Sign computes a signature for the given data.
+func (s *signer) Sign(data []byte) ([]byte, error) {
+	r, ss, err := ecdsa.Sign(rand.Reader, s.privateKey, digest(data))
+	if err != nil {
+		return nil, fmt.Errorf("secp256k1_signer: %w", err)
+	}
+
+	return append(padded(r.Bytes()), padded(ss.Bytes())...), nil
+}
+
+func padded(b []byte) []byte {
+	dest := make([]byte, coordinateSize)
+	copy(dest[coordinateSize-len(b):], b)
+
+	return dest
+}
@@ -0,0 +1,158 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package secp256k1
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/core/registry"
+	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+)
+
+const (
+	publicKeyVersion = 0
+	publicKeyTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.Secp256k1EcdsaPublicKey"
+
+	// size of the r and s values of signatures and of the key coordinates
+	coordinateSize = 32
+)
+
+// common errors
+var errInvalidPublicKey = errors.New("secp256k1_verifier_key_manager: invalid key")
+var errVerifierNotImplemented = errors.New("secp256k1_verifier_key_manager: not implemented")
+
+// verifierKeyManager is an implementation of KeyManager interface for secp256k1 ECDSA public keys.
+// It doesn't support key generation.
+type verifierKeyManager struct{}
+
+// Assert that verifierKeyManager implements the KeyManager interface.
+var _ registry.KeyManager = (*verifierKeyManager)(nil)
+
+// newVerifierKeyManager creates a new verifierKeyManager.
+func newVerifierKeyManager() *verifierKeyManager {
+	return new(verifierKeyManager)
+}
+
+// Primitive creates a verifier for the given serialized EcdsaPublicKey proto.
+func (km *verifierKeyManager) Primitive(serializedKey []byte) (interface{}, error) {
+	if len(serializedKey) == 0 {
+		return nil, errInvalidPublicKey
+	}
+
+	key := new(ecdsapb.EcdsaPublicKey)
+
+	err := proto.Unmarshal(serializedKey, key)
+	if err != nil {
+		return nil, errInvalidPublicKey
+	}
+
+	pubKey, err := publicKeyFromProto(key)
+	if err != nil {
+		return nil, fmt.Errorf("secp256k1_verifier_key_manager: %w", err)
+	}
+
+	return &verifier{publicKey: pubKey}, nil
+}
+
+// NewKey is not implemented for public keys.
+func (km *verifierKeyManager) NewKey(serializedKeyFormat []byte) (proto.Message, error) {
+	return nil, errVerifierNotImplemented
+}
+
+// NewKeyData is not implemented for public keys.
+func (km *verifierKeyManager) NewKeyData(serializedKeyFormat []byte) (*tinkpb.KeyData, error) {
+	return nil, errVerifierNotImplemented
+}
+
+// DoesSupport indicates if this key manager supports the given key type.
+func (km *verifierKeyManager) DoesSupport(typeURL string) bool {
+	return typeURL == publicKeyTypeURL
+}
+
+// TypeURL returns the key type of keys managed by this key manager.
+func (km *verifierKeyManager) TypeURL() string {
+	return publicKeyTypeURL
+}
+
+// verifier verifies IEEE P1363 signatures of SHA-256 digests with a secp256k1 public key, it implements
+// tink.Verifier.
+type verifier struct {
+	publicKey *ecdsa.PublicKey
+}
+
+// Verify verifies whether the given signature is valid for the given data.
+func (v *verifier) Verify(signature, data []byte) error {
+	if len(signature) != 2*coordinateSize {
+		return errors.New("secp256k1_verifier: invalid signature size")
+	}
+
+	r := new(big.Int).SetBytes(signature[:coordinateSize])
+	s := new(big.Int).SetBytes(signature[coordinateSize:])
+
+	if !ecdsa.Verify(v.publicKey, digest(data), r, s) {
+		return errors.New("secp256k1_verifier: invalid signature")
+	}
+
+	return nil
+}
+
+func publicKeyFromProto(key *ecdsapb.EcdsaPublicKey) (*ecdsa.PublicKey, error) {
+	err := keyset.ValidateKeyVersion(key.Version, publicKeyVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key version: %w", err)
+	}
+
+	err = validateParams(key.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey := &ecdsa.PublicKey{
+		Curve: btcec.S256(),
+		X:     new(big.Int).SetBytes(key.X),
+		Y:     new(big.Int).SetBytes(key.Y),
+	}
+
+	if !pubKey.Curve.IsOnCurve(pubKey.X, pubKey.Y) {
+		return nil, errors.New("public key is not on the secp256k1 curve")
+	}
+
+	return pubKey, nil
+}
+
+func newPublicKeyProto(pubKey *ecdsa.PublicKey) *ecdsapb.EcdsaPublicKey {
+	return &ecdsapb.EcdsaPublicKey{
+		Version: publicKeyVersion,
+		Params:  Params(),
+		X:       pubKey.X.Bytes(),
+		Y:       pubKey.Y.Bytes(),
+	}
+}
+
+func validateParams(params *ecdsapb.EcdsaParams) error {
+	if params == nil || params.HashType != commonpb.HashType_SHA256 ||
+		params.Encoding != ecdsapb.EcdsaSignatureEncoding_IEEE_P1363 {
+		return errors.New("invalid params: SHA-256 hash and IEEE P1363 encoding are required")
+	}
+
+	return nil
+}
+
+func digest(data []byte) []byte {
+	h := sha256.Sum256(data)
+
+	return h[:]
+}
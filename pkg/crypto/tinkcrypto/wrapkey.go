@@ -0,0 +1,325 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tinkcrypto
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	hybrid "github.com/google/tink/go/hybrid/subtle"
+	josecipher "github.com/square/go-jose/v3/cipher"
+	"golang.org/x/crypto/curve25519"
+
+	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+)
+
+const (
+	// ECDHESA256KWAlg is the ECDH-ES with AES key wrapping (256 bits KEK) algorithm.
+	ECDHESA256KWAlg = "ECDH-ES+A256KW"
+	// ECDH1PUA256KWAlg is the ECDH-1PU with AES key wrapping (256 bits KEK) algorithm.
+	ECDH1PUA256KWAlg = "ECDH-1PU+A256KW"
+
+	ecKeyType   = "EC"
+	okpKeyType  = "OKP"
+	x25519Curve = "X25519"
+
+	kekSize = 32
+)
+
+// x25519PrivateKey and x25519PublicKey represent raw X25519 keys. EC keys are represented by ecdsa.PrivateKey and
+// ecdsa.PublicKey types.
+type (
+	x25519PrivateKey []byte
+	x25519PublicKey  []byte
+)
+
+// WrapKey will do ECDH (ES or 1PU) key wrapping of cek using apu, apv and recipient public key 'recPubKey'.
+// The ECDH-1PU key wrapping is executed when the sender private key handle is set via crypto.WithSender() option.
+func (t *Crypto) WrapKey(cek, apu, apv []byte, recPubKey *cryptoapi.PublicKey,
+	wrapKeyOpts ...cryptoapi.WrapKeyOpts) (*cryptoapi.RecipientWrappedKey, error) {
+	if recPubKey == nil {
+		return nil, errors.New("wrapKey: recipient public key is required")
+	}
+
+	pOpts := cryptoapi.NewOpt()
+
+	for _, opt := range wrapKeyOpts {
+		opt(pOpts)
+	}
+
+	recKey, err := convertPublicKey(recPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: %w", err)
+	}
+
+	ephemeralPriv, epk, err := generateEphemeralKey(recKey)
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: %w", err)
+	}
+
+	alg := ECDHESA256KWAlg
+
+	z, err := ecdh(ephemeralPriv, recKey)
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: %w", err)
+	}
+
+	if pOpts.SenderKey() != nil {
+		alg = ECDH1PUA256KWAlg
+
+		senderPriv, e := extractPrivKey(pOpts.SenderKey())
+		if e != nil {
+			return nil, fmt.Errorf("wrapKey: sender key: %w", e)
+		}
+
+		zs, e := ecdh(senderPriv, recKey)
+		if e != nil {
+			return nil, fmt.Errorf("wrapKey: sender key: %w", e)
+		}
+
+		z = append(z, zs...)
+	}
+
+	kek, err := deriveKEK(alg, z, apu, apv)
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: %w", err)
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: %w", err)
+	}
+
+	wk, err := josecipher.KeyWrap(block, cek)
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: %w", err)
+	}
+
+	return &cryptoapi.RecipientWrappedKey{
+		KID:          recPubKey.KID,
+		EncryptedCEK: wk,
+		EPK:          *epk,
+		Alg:          alg,
+		APU:          apu,
+		APV:          apv,
+	}, nil
+}
+
+// UnwrapKey unwraps a key in recWK using recipient private key kh. The sender public key (handle or
+// *crypto.PublicKey) must be set via crypto.WithSender() option to unwrap ECDH-1PU wrapped keys.
+func (t *Crypto) UnwrapKey(recWK *cryptoapi.RecipientWrappedKey, kh interface{},
+	wrapKeyOpts ...cryptoapi.WrapKeyOpts) ([]byte, error) {
+	if recWK == nil {
+		return nil, errors.New("unwrapKey: RecipientWrappedKey is empty")
+	}
+
+	pOpts := cryptoapi.NewOpt()
+
+	for _, opt := range wrapKeyOpts {
+		opt(pOpts)
+	}
+
+	recPriv, err := extractPrivKey(kh)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: %w", err)
+	}
+
+	epk, err := convertPublicKey(&recWK.EPK)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: epk: %w", err)
+	}
+
+	z, err := ecdh(recPriv, epk)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: %w", err)
+	}
+
+	switch recWK.Alg {
+	case ECDHESA256KWAlg:
+	case ECDH1PUA256KWAlg:
+		if pOpts.SenderKey() == nil {
+			return nil, errors.New("unwrapKey: sender public key is required for ECDH-1PU key unwrapping")
+		}
+
+		senderPub, e := extractPubKey(pOpts.SenderKey())
+		if e != nil {
+			return nil, fmt.Errorf("unwrapKey: sender key: %w", e)
+		}
+
+		zs, e := ecdh(recPriv, senderPub)
+		if e != nil {
+			return nil, fmt.Errorf("unwrapKey: sender key: %w", e)
+		}
+
+		z = append(z, zs...)
+	default:
+		return nil, fmt.Errorf("unwrapKey: unsupported key wrapping algorithm '%s'", recWK.Alg)
+	}
+
+	kek, err := deriveKEK(recWK.Alg, z, recWK.APU, recWK.APV)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: %w", err)
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: %w", err)
+	}
+
+	cek, err := josecipher.KeyUnwrap(block, recWK.EncryptedCEK)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: %w", err)
+	}
+
+	return cek, nil
+}
+
+// convertPublicKey converts crypto.PublicKey into *ecdsa.PublicKey or x25519PublicKey.
+func convertPublicKey(pubKey *cryptoapi.PublicKey) (interface{}, error) {
+	switch pubKey.Type {
+	case ecKeyType:
+		c, err := hybrid.GetCurve(pubKey.Curve)
+		if err != nil {
+			return nil, err
+		}
+
+		ecPubKey := &ecdsa.PublicKey{
+			Curve: c,
+			X:     new(big.Int).SetBytes(pubKey.X),
+			Y:     new(big.Int).SetBytes(pubKey.Y),
+		}
+
+		if !c.IsOnCurve(ecPubKey.X, ecPubKey.Y) {
+			return nil, errors.New("public key is not on curve " + c.Params().Name)
+		}
+
+		return ecPubKey, nil
+	case okpKeyType:
+		if pubKey.Curve != x25519Curve {
+			return nil, fmt.Errorf("unsupported OKP curve '%s'", pubKey.Curve)
+		}
+
+		if len(pubKey.X) != curve25519.PointSize {
+			return nil, errors.New("invalid X25519 public key size")
+		}
+
+		return x25519PublicKey(pubKey.X), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type '%s'", pubKey.Type)
+	}
+}
+
+// generateEphemeralKey generates ephemeral private key on the same curve as recipient key and returns it with its
+// crypto.PublicKey representation (epk).
+func generateEphemeralKey(recKey interface{}) (interface{}, *cryptoapi.PublicKey, error) {
+	switch k := recKey.(type) {
+	case *ecdsa.PublicKey:
+		ephemeralPriv, err := ecdsa.GenerateKey(k.Curve, rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return ephemeralPriv, &cryptoapi.PublicKey{
+			X:     ephemeralPriv.PublicKey.X.Bytes(),
+			Y:     ephemeralPriv.PublicKey.Y.Bytes(),
+			Curve: ephemeralPriv.PublicKey.Curve.Params().Name,
+			Type:  ecKeyType,
+		}, nil
+	case x25519PublicKey:
+		ephemeralPriv := make([]byte, curve25519.ScalarSize)
+
+		_, err := rand.Read(ephemeralPriv)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ephemeralPub, err := curve25519.X25519(ephemeralPriv, curve25519.Basepoint)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return x25519PrivateKey(ephemeralPriv), &cryptoapi.PublicKey{
+			X:     ephemeralPub,
+			Curve: x25519Curve,
+			Type:  okpKeyType,
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported public key type %T", recKey)
+	}
+}
+
+// ecdh computes ECDH shared secret (Z) of the private and public keys.
+func ecdh(privKey, pubKey interface{}) ([]byte, error) {
+	switch priv := privKey.(type) {
+	case *ecdsa.PrivateKey:
+		pub, ok := pubKey.(*ecdsa.PublicKey)
+		if !ok || pub.Curve.Params().Name != priv.Curve.Params().Name {
+			return nil, errors.New("ecdh: private and public keys are not on the same curve")
+		}
+
+		x, _ := priv.Curve.ScalarMult(pub.X, pub.Y, priv.D.Bytes())
+
+		// Z is the x-coordinate of the shared point padded to the curve size as per RFC7518 section 4.6.2
+		z := make([]byte, (priv.Curve.Params().BitSize+7)/8)
+		xBytes := x.Bytes()
+		copy(z[len(z)-len(xBytes):], xBytes)
+
+		return z, nil
+	case x25519PrivateKey:
+		pub, ok := pubKey.(x25519PublicKey)
+		if !ok {
+			return nil, errors.New("ecdh: private and public keys are not on the same curve")
+		}
+
+		return curve25519.X25519(priv, pub)
+	default:
+		return nil, fmt.Errorf("ecdh: unsupported private key type %T", privKey)
+	}
+}
+
+// deriveKEK derives key encryption key from shared secret z using Concat KDF as per RFC7518 section 4.6.2.
+func deriveKEK(alg string, z, apu, apv []byte) ([]byte, error) {
+	algID := cryptoutil.LengthPrefix([]byte(alg))
+	ptyUInfo := cryptoutil.LengthPrefix(apu)
+	ptyVInfo := cryptoutil.LengthPrefix(apv)
+
+	supPubInfo := make([]byte, 4)
+	binary.BigEndian.PutUint32(supPubInfo, uint32(kekSize)*8)
+
+	reader := josecipher.NewConcatKDF(crypto.SHA256, z, algID, ptyUInfo, ptyVInfo, supPubInfo, []byte{})
+
+	kek := make([]byte, kekSize)
+
+	_, err := reader.Read(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	return kek, nil
+}
+
+// ecPrivateKey builds ecdsa.PrivateKey from the curve and the raw private key value.
+func ecPrivateKey(c elliptic.Curve, d []byte) *ecdsa.PrivateKey {
+	pvt := hybrid.GetECPrivateKey(c, d)
+
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: pvt.PublicKey.Curve,
+			X:     pvt.PublicKey.Point.X,
+			Y:     pvt.PublicKey.Point.Y,
+		},
+		D: pvt.D,
+	}
+}
@@ -0,0 +1,268 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tinkcrypto
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/google/tink/go/signature"
+	josecipher "github.com/square/go-jose/v3/cipher"
+	"github.com/stretchr/testify/require"
+
+	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdh1pu"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdhes"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/keyio"
+)
+
+func TestCrypto_WrapUnwrapKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		template *tinkpb.KeyTemplate
+	}{
+		{name: "P-256", template: ecdhes.ECDHES256KWAES256GCMKeyTemplate()},
+		{name: "P-384", template: ecdhes.ECDHES384KWAES256GCMKeyTemplate()},
+		{name: "P-521", template: ecdhes.ECDHES521KWAES256GCMKeyTemplate()},
+		{name: "P-256 (ECDH-1PU key)", template: ecdh1pu.ECDH1PU256KWAES256GCMKeyTemplate()},
+		{name: "X25519", template: ecdhes.ECDHESX25519KWAES256GCMKeyTemplate()},
+	}
+
+	c, err := New()
+	require.NoError(t, err)
+
+	cek := random(t, 32)
+	apu := []byte("sender")
+	apv := []byte("recipient")
+
+	for _, tc := range tests {
+		tt := tc
+
+		t.Run("ECDH-ES key wrapping with "+tt.name, func(t *testing.T) {
+			recKH, recPubKey := newKey(t, tt.template)
+
+			wrappedKey, err := c.WrapKey(cek, apu, apv, recPubKey)
+			require.NoError(t, err)
+			require.Equal(t, ECDHESA256KWAlg, wrappedKey.Alg)
+			require.Equal(t, recPubKey.Type, wrappedKey.EPK.Type)
+			require.NotEqual(t, cek, wrappedKey.EncryptedCEK)
+
+			unwrappedKey, err := c.UnwrapKey(wrappedKey, recKH)
+			require.NoError(t, err)
+			require.Equal(t, cek, unwrappedKey)
+
+			// unwrap with other recipient key
+			otherKH, _ := newKey(t, tt.template)
+
+			_, err = c.UnwrapKey(wrappedKey, otherKH)
+			require.Error(t, err)
+		})
+
+		t.Run("ECDH-1PU key wrapping with "+tt.name, func(t *testing.T) {
+			recKH, recPubKey := newKey(t, tt.template)
+			senderKH, senderPubKey := newKey(t, tt.template)
+
+			wrappedKey, err := c.WrapKey(cek, apu, apv, recPubKey, cryptoapi.WithSender(senderKH))
+			require.NoError(t, err)
+			require.Equal(t, ECDH1PUA256KWAlg, wrappedKey.Alg)
+
+			senderPubKH, err := senderKH.Public()
+			require.NoError(t, err)
+
+			// sender public key as key handle
+			unwrappedKey, err := c.UnwrapKey(wrappedKey, recKH, cryptoapi.WithSender(senderPubKH))
+			require.NoError(t, err)
+			require.Equal(t, cek, unwrappedKey)
+
+			// sender public key as crypto.PublicKey
+			unwrappedKey, err = c.UnwrapKey(wrappedKey, recKH, cryptoapi.WithSender(senderPubKey))
+			require.NoError(t, err)
+			require.Equal(t, cek, unwrappedKey)
+
+			// sender key is missing
+			_, err = c.UnwrapKey(wrappedKey, recKH)
+			require.EqualError(t, err, "unwrapKey: sender public key is required for ECDH-1PU key unwrapping")
+
+			// wrong sender key
+			_, otherPubKey := newKey(t, tt.template)
+
+			_, err = c.UnwrapKey(wrappedKey, recKH, cryptoapi.WithSender(otherPubKey))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCrypto_WrapKeyFailures(t *testing.T) {
+	c := &Crypto{}
+	cek := random(t, 32)
+
+	_, p256PubKey := newKey(t, ecdhes.ECDHES256KWAES256GCMKeyTemplate())
+	x25519KH, x25519PubKey := newKey(t, ecdhes.ECDHESX25519KWAES256GCMKeyTemplate())
+
+	t.Run("missing recipient key", func(t *testing.T) {
+		_, err := c.WrapKey(cek, nil, nil, nil)
+		require.EqualError(t, err, "wrapKey: recipient public key is required")
+	})
+
+	t.Run("unsupported recipient key", func(t *testing.T) {
+		_, err := c.WrapKey(cek, nil, nil, &cryptoapi.PublicKey{Type: "RSA"})
+		require.EqualError(t, err, "wrapKey: unsupported key type 'RSA'")
+
+		_, err = c.WrapKey(cek, nil, nil, &cryptoapi.PublicKey{Type: okpKeyType, Curve: "Ed25519"})
+		require.EqualError(t, err, "wrapKey: unsupported OKP curve 'Ed25519'")
+
+		_, err = c.WrapKey(cek, nil, nil, &cryptoapi.PublicKey{Type: okpKeyType, Curve: x25519Curve, X: []byte("x")})
+		require.EqualError(t, err, "wrapKey: invalid X25519 public key size")
+
+		_, err = c.WrapKey(cek, nil, nil, &cryptoapi.PublicKey{Type: ecKeyType, Curve: "P-256", X: []byte("x")})
+		require.EqualError(t, err, "wrapKey: public key is not on curve P-256")
+
+		_, err = c.WrapKey(cek, nil, nil, &cryptoapi.PublicKey{Type: ecKeyType, Curve: "P-1"})
+		require.EqualError(t, err, "wrapKey: unsupported curve")
+	})
+
+	t.Run("sender key on another curve", func(t *testing.T) {
+		_, err := c.WrapKey(cek, nil, nil, p256PubKey, cryptoapi.WithSender(x25519KH))
+		require.EqualError(t, err,
+			"wrapKey: sender key: ecdh: private and public keys are not on the same curve")
+	})
+
+	t.Run("sender key is not a private key handle", func(t *testing.T) {
+		_, err := c.WrapKey(cek, nil, nil, x25519PubKey, cryptoapi.WithSender("kh"))
+		require.EqualError(t, err, "wrapKey: sender key: bad key handle format")
+
+		pubKH, err := x25519KH.Public()
+		require.NoError(t, err)
+
+		_, err = c.WrapKey(cek, nil, nil, x25519PubKey, cryptoapi.WithSender(pubKH))
+		require.EqualError(t, err, "wrapKey: sender key: private key handle is required")
+
+		sigKH, err := keyset.NewHandle(signature.ED25519KeyTemplate())
+		require.NoError(t, err)
+
+		_, err = c.WrapKey(cek, nil, nil, x25519PubKey, cryptoapi.WithSender(sigKH))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not supported for key wrapping")
+	})
+
+	t.Run("invalid cek size", func(t *testing.T) {
+		_, err := c.WrapKey([]byte("short"), nil, nil, x25519PubKey)
+		require.Error(t, err)
+	})
+}
+
+func TestCrypto_UnwrapKeyFailures(t *testing.T) {
+	c := &Crypto{}
+
+	recKH, recPubKey := newKey(t, ecdhes.ECDHES256KWAES256GCMKeyTemplate())
+
+	wrappedKey, err := c.WrapKey(random(t, 32), nil, nil, recPubKey)
+	require.NoError(t, err)
+
+	t.Run("missing wrapped key", func(t *testing.T) {
+		_, err := c.UnwrapKey(nil, recKH)
+		require.EqualError(t, err, "unwrapKey: RecipientWrappedKey is empty")
+	})
+
+	t.Run("bad key handle", func(t *testing.T) {
+		_, err := c.UnwrapKey(wrappedKey, "kh")
+		require.EqualError(t, err, "unwrapKey: bad key handle format")
+
+		aeadKH, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+		require.NoError(t, err)
+
+		_, err = c.UnwrapKey(wrappedKey, aeadKH)
+		require.Error(t, err)
+	})
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		wk := *wrappedKey
+		wk.Alg = "ECDH-ES+A128KW"
+
+		_, err := c.UnwrapKey(&wk, recKH)
+		require.EqualError(t, err, "unwrapKey: unsupported key wrapping algorithm 'ECDH-ES+A128KW'")
+	})
+
+	t.Run("invalid epk", func(t *testing.T) {
+		wk := *wrappedKey
+		wk.EPK = cryptoapi.PublicKey{Type: okpKeyType, Curve: x25519Curve, X: random(t, 32)}
+
+		_, err := c.UnwrapKey(&wk, recKH)
+		require.EqualError(t, err, "unwrapKey: ecdh: private and public keys are not on the same curve")
+
+		wk.EPK = cryptoapi.PublicKey{}
+
+		_, err = c.UnwrapKey(&wk, recKH)
+		require.EqualError(t, err, "unwrapKey: epk: unsupported key type ''")
+	})
+
+	t.Run("tampered apu", func(t *testing.T) {
+		wk := *wrappedKey
+		wk.APU = []byte("other")
+
+		_, err := c.UnwrapKey(&wk, recKH)
+		require.Error(t, err)
+	})
+}
+
+func TestDeriveKEK(t *testing.T) {
+	// KEK derived for ECDH-ES must be compatible with go-jose implementation.
+	priv, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	pub, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	z, err := ecdh(priv, &pub.PublicKey)
+	require.NoError(t, err)
+
+	kek, err := deriveKEK(ECDHESA256KWAlg, z, []byte("apu"), []byte("apv"))
+	require.NoError(t, err)
+
+	expected := josecipher.DeriveECDHES(ECDHESA256KWAlg, []byte("apu"), []byte("apv"), priv, &pub.PublicKey, kekSize)
+	require.Equal(t, expected, kek)
+}
+
+func newKey(t *testing.T, template *tinkpb.KeyTemplate) (*keyset.Handle, *cryptoapi.PublicKey) {
+	t.Helper()
+
+	kh, err := keyset.NewHandle(template)
+	require.NoError(t, err)
+
+	pubKH, err := kh.Public()
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+
+	err = pubKH.WriteWithNoSecrets(keyio.NewWriter(buf))
+	require.NoError(t, err)
+
+	pubKey := &cryptoapi.PublicKey{}
+
+	err = json.Unmarshal(buf.Bytes(), pubKey)
+	require.NoError(t, err)
+
+	return kh, pubKey
+}
+
+func random(t *testing.T, size int) []byte {
+	t.Helper()
+
+	r := make([]byte, size)
+
+	_, err := rand.Read(r)
+	require.NoError(t, err)
+
+	return r
+}
@@ -0,0 +1,224 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webcrypto
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/webkms"
+)
+
+var errBadKeyHandleFormat = errors.New("bad key handle format")
+
+// RemoteCrypto is a crypto.Crypto client of a remote KMS server exposing the web KMS HTTP API. Key handles passed to
+// its functions must be *webkms.KeyHandle instances returned by the webkms client.
+type RemoteCrypto struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Opt configures the RemoteCrypto client.
+type Opt func(r *RemoteCrypto)
+
+// WithHTTPClient option is for setting a custom HTTP client used to call the remote KMS server.
+func WithHTTPClient(client *http.Client) Opt {
+	return func(r *RemoteCrypto) {
+		r.httpClient = client
+	}
+}
+
+// WithTimeout option is for definition of HTTP(s) timeout value of the remote crypto calls.
+func WithTimeout(timeout time.Duration) Opt {
+	return func(r *RemoteCrypto) {
+		r.httpClient.Timeout = timeout
+	}
+}
+
+// WithTLSConfig option is for definition of secured HTTP transport using a tls.Config instance.
+func WithTLSConfig(tlsConfig *tls.Config) Opt {
+	return func(r *RemoteCrypto) {
+		r.httpClient.Transport = &http.Transport{
+			TLSClientConfig: tlsConfig,
+		}
+	}
+}
+
+// New creates a new RemoteCrypto client of the web KMS server located at baseURL.
+func New(baseURL string, opts ...Opt) (*RemoteCrypto, error) {
+	_, err := url.ParseRequestURI(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("base URL invalid: %w", err)
+	}
+
+	r := &RemoteCrypto{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: &http.Client{}}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+// Encrypt will remotely encrypt msg and aad using the remote key referenced by kh.
+// returns:
+// 		cipherText in []byte
+//		nonce in []byte
+//		error in case of errors during encryption
+func (r *RemoteCrypto) Encrypt(msg, aad []byte, kh interface{}) ([]byte, []byte, error) {
+	resp := &webkms.EncryptResp{}
+
+	err := r.post(kh, webkms.EncryptPath, &webkms.EncryptReq{Message: msg, AdditionalData: aad}, resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encrypt: %w", err)
+	}
+
+	return resp.CipherText, resp.Nonce, nil
+}
+
+// Decrypt will remotely decrypt cipher with aad and given nonce using the remote key referenced by kh.
+// Arguments are in the same order as tinkcrypto's Decrypt (nonce before aad).
+// returns:
+//		plainText in []byte
+//		error in case of errors
+func (r *RemoteCrypto) Decrypt(cipher, nonce, aad []byte, kh interface{}) ([]byte, error) {
+	resp := &webkms.DecryptResp{}
+
+	err := r.post(kh, webkms.DecryptPath,
+		&webkms.DecryptReq{CipherText: cipher, AdditionalData: aad, Nonce: nonce}, resp)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+
+	return resp.PlainText, nil
+}
+
+// Sign will remotely sign msg using the remote key referenced by kh.
+// returns:
+// 		signature in []byte
+//		error in case of errors
+func (r *RemoteCrypto) Sign(msg []byte, kh interface{}) ([]byte, error) {
+	resp := &webkms.SignResp{}
+
+	err := r.post(kh, webkms.SignPath, &webkms.SignReq{Message: msg}, resp)
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+
+	return resp.Signature, nil
+}
+
+// Verify will remotely verify a signature for the given msg using the remote key referenced by kh.
+// returns:
+// 		error in case of errors or nil if signature verification was successful
+func (r *RemoteCrypto) Verify(signature, msg []byte, kh interface{}) error {
+	err := r.post(kh, webkms.VerifyPath, &webkms.VerifyReq{Signature: signature, Message: msg}, nil)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+
+	return nil
+}
+
+// ComputeMAC remotely computes message authentication code (MAC) for data using the remote key referenced by kh.
+func (r *RemoteCrypto) ComputeMAC(data []byte, kh interface{}) ([]byte, error) {
+	resp := &webkms.ComputeMACResp{}
+
+	err := r.post(kh, webkms.ComputeMACPath, &webkms.ComputeMACReq{Data: data}, resp)
+	if err != nil {
+		return nil, fmt.Errorf("compute MAC: %w", err)
+	}
+
+	return resp.MAC, nil
+}
+
+// VerifyMAC remotely determines if mac is a correct authentication code (MAC) for data using the remote key
+// referenced by kh and returns nil if so, otherwise it returns an error.
+func (r *RemoteCrypto) VerifyMAC(mac, data []byte, kh interface{}) error {
+	err := r.post(kh, webkms.VerifyMACPath, &webkms.VerifyMACReq{MAC: mac, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("verify MAC: %w", err)
+	}
+
+	return nil
+}
+
+// WrapKey will remotely execute key wrapping of cek using apu, apv and recipient public key 'recPubKey'.
+// ECDH-1PU key wrapping is executed when the sender key handle (a *webkms.KeyHandle) is set via crypto.WithSender()
+// option, ECDH-ES key wrapping is executed otherwise.
+func (r *RemoteCrypto) WrapKey(cek, apu, apv []byte, recPubKey *cryptoapi.PublicKey,
+	opts ...cryptoapi.WrapKeyOpts) (*cryptoapi.RecipientWrappedKey, error) {
+	pOpts := cryptoapi.NewOpt()
+
+	for _, opt := range opts {
+		opt(pOpts)
+	}
+
+	req := &webkms.WrapKeyReq{CEK: cek, APU: apu, APV: apv, RecipientPubKey: recPubKey}
+	resp := &webkms.WrapKeyResp{}
+
+	var err error
+
+	if pOpts.SenderKey() != nil {
+		err = r.post(pOpts.SenderKey(), webkms.KeyWrapPath, req, resp)
+	} else {
+		err = webkms.DoRequest(r.httpClient, http.MethodPost, r.baseURL+webkms.WrapPath, req, resp)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("wrapKey: %w", err)
+	}
+
+	return resp.WrappedKey, nil
+}
+
+// UnwrapKey remotely unwraps a key in recWK using the remote recipient key referenced by kh. The sender public key
+// must be set as a *crypto.PublicKey via crypto.WithSender() option to unwrap ECDH-1PU wrapped keys.
+func (r *RemoteCrypto) UnwrapKey(recWK *cryptoapi.RecipientWrappedKey, kh interface{},
+	opts ...cryptoapi.WrapKeyOpts) ([]byte, error) {
+	pOpts := cryptoapi.NewOpt()
+
+	for _, opt := range opts {
+		opt(pOpts)
+	}
+
+	req := &webkms.UnwrapKeyReq{WrappedKey: recWK}
+
+	if pOpts.SenderKey() != nil {
+		senderPubKey, ok := pOpts.SenderKey().(*cryptoapi.PublicKey)
+		if !ok {
+			return nil, errors.New("unwrapKey: sender key must be a *crypto.PublicKey")
+		}
+
+		req.SenderPubKey = senderPubKey
+	}
+
+	resp := &webkms.UnwrapKeyResp{}
+
+	err := r.post(kh, webkms.UnwrapPath, req, resp)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapKey: %w", err)
+	}
+
+	return resp.Key, nil
+}
+
+// post sends req to the operation path of the remote key referenced by kh.
+func (r *RemoteCrypto) post(kh interface{}, path string, req, resp interface{}) error {
+	keyHandle, ok := kh.(*webkms.KeyHandle)
+	if !ok {
+		return errBadKeyHandleFormat
+	}
+
+	return webkms.DoRequest(r.httpClient, http.MethodPost, keyHandle.KeyURL+path, req, resp)
+}
@@ -0,0 +1,204 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webcrypto
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/keyio"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/webkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		client := &http.Client{}
+
+		r, err := New("https://kms.example.com/", WithHTTPClient(client), WithTimeout(time.Second),
+			WithTLSConfig(nil))
+		require.NoError(t, err)
+		require.Equal(t, "https://kms.example.com", r.baseURL)
+		require.Equal(t, client, r.httpClient)
+		require.Equal(t, time.Second, r.httpClient.Timeout)
+	})
+
+	t.Run("invalid base URL", func(t *testing.T) {
+		_, err := New("kms")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "base URL invalid")
+	})
+}
+
+func TestRemoteCrypto(t *testing.T) {
+	localKMS, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(storage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	tc, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(webkms.NewServer(localKMS, tc))
+	defer srv.Close()
+
+	remoteKMS, err := webkms.New(srv.URL)
+	require.NoError(t, err)
+
+	r, err := New(srv.URL)
+	require.NoError(t, err)
+
+	msg := []byte("lorem ipsum")
+
+	t.Run("sign and verify", func(t *testing.T) {
+		_, kh, err := remoteKMS.Create(kms.ECDSAP256TypeIEEEP1363)
+		require.NoError(t, err)
+
+		sig, err := r.Sign(msg, kh)
+		require.NoError(t, err)
+
+		require.NoError(t, r.Verify(sig, msg, kh))
+
+		err = r.Verify(sig, []byte("other message"), kh)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verify: remote KMS server responded with status 400")
+	})
+
+	t.Run("encrypt and decrypt", func(t *testing.T) {
+		_, kh, err := remoteKMS.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		aad := []byte("aad")
+
+		cipherText, nonce, err := r.Encrypt(msg, aad, kh)
+		require.NoError(t, err)
+		require.NotEqual(t, msg, cipherText)
+
+		plainText, err := r.Decrypt(cipherText, nonce, aad, kh)
+		require.NoError(t, err)
+		require.Equal(t, msg, plainText)
+
+		_, err = r.Decrypt(cipherText, nonce, []byte("other aad"), kh)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decrypt: remote KMS server responded with status 400")
+	})
+
+	t.Run("compute and verify MAC", func(t *testing.T) {
+		_, kh, err := remoteKMS.Create(kms.HMACSHA256Tag256Type)
+		require.NoError(t, err)
+
+		mac, err := r.ComputeMAC(msg, kh)
+		require.NoError(t, err)
+
+		require.NoError(t, r.VerifyMAC(mac, msg, kh))
+
+		err = r.VerifyMAC(mac, []byte("other data"), kh)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verify MAC: remote KMS server responded with status 400")
+	})
+
+	t.Run("wrap and unwrap key", func(t *testing.T) {
+		recKeyID, recKH, err := remoteKMS.Create(kms.ECDHES256AES256GCMType)
+		require.NoError(t, err)
+
+		senderKeyID, senderKH, err := remoteKMS.Create(kms.ECDHES256AES256GCMType)
+		require.NoError(t, err)
+
+		recPubKey := publicKey(t, localKMS, recKeyID)
+		senderPubKey := publicKey(t, localKMS, senderKeyID)
+
+		cek := []byte("01234567890123456789012345678901")
+		apu := []byte("sender")
+		apv := []byte("recipient")
+
+		// ECDH-ES
+		wrappedKey, err := r.WrapKey(cek, apu, apv, recPubKey)
+		require.NoError(t, err)
+		require.Equal(t, tinkcrypto.ECDHESA256KWAlg, wrappedKey.Alg)
+
+		key, err := r.UnwrapKey(wrappedKey, recKH)
+		require.NoError(t, err)
+		require.Equal(t, cek, key)
+
+		// ECDH-1PU
+		wrappedKey, err = r.WrapKey(cek, apu, apv, recPubKey, cryptoapi.WithSender(senderKH))
+		require.NoError(t, err)
+		require.Equal(t, tinkcrypto.ECDH1PUA256KWAlg, wrappedKey.Alg)
+
+		key, err = r.UnwrapKey(wrappedKey, recKH, cryptoapi.WithSender(senderPubKey))
+		require.NoError(t, err)
+		require.Equal(t, cek, key)
+
+		_, err = r.UnwrapKey(wrappedKey, recKH, cryptoapi.WithSender(senderKH))
+		require.EqualError(t, err, "unwrapKey: sender key must be a *crypto.PublicKey")
+
+		_, err = r.UnwrapKey(wrappedKey, recKH)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "sender public key is required for ECDH-1PU key unwrapping")
+
+		_, err = r.WrapKey(cek, apu, apv, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "recipient public key is required")
+	})
+
+	t.Run("bad key handle", func(t *testing.T) {
+		_, _, err := r.Encrypt(msg, nil, "kh")
+		require.EqualError(t, err, "encrypt: bad key handle format")
+
+		_, err = r.Decrypt(msg, nil, nil, "kh")
+		require.EqualError(t, err, "decrypt: bad key handle format")
+
+		_, err = r.Sign(msg, "kh")
+		require.EqualError(t, err, "sign: bad key handle format")
+
+		err = r.Verify(msg, msg, "kh")
+		require.EqualError(t, err, "verify: bad key handle format")
+
+		_, err = r.ComputeMAC(msg, "kh")
+		require.EqualError(t, err, "compute MAC: bad key handle format")
+
+		err = r.VerifyMAC(msg, msg, "kh")
+		require.EqualError(t, err, "verify MAC: bad key handle format")
+
+		_, err = r.WrapKey(msg, nil, nil, &cryptoapi.PublicKey{}, cryptoapi.WithSender("kh"))
+		require.EqualError(t, err, "wrapKey: bad key handle format")
+
+		_, err = r.UnwrapKey(&cryptoapi.RecipientWrappedKey{}, "kh")
+		require.EqualError(t, err, "unwrapKey: bad key handle format")
+	})
+}
+
+// publicKey reads the public key of the ECDH key keyID directly from the KMS used by the server.
+func publicKey(t *testing.T, k kms.KeyManager, keyID string) *cryptoapi.PublicKey {
+	t.Helper()
+
+	kh, err := k.Get(keyID)
+	require.NoError(t, err)
+
+	pubKH, err := kh.(*keyset.Handle).Public()
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, pubKH.WriteWithNoSecrets(keyio.NewWriter(buf)))
+
+	pubKey := &cryptoapi.PublicKey{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), pubKey))
+
+	return pubKey
+}
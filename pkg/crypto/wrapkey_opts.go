@@ -0,0 +1,37 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crypto
+
+// WrapKeyOptions holds the options of the crypto.Wrap key functions, set by the WrapKeyOpts.
+type WrapKeyOptions struct {
+	senderKey interface{}
+}
+
+// WrapKeyOpts are the crypto.Wrap key options.
+type WrapKeyOpts func(opts *WrapKeyOptions)
+
+// NewOpt creates a new empty wrap key option.
+// Not to be used directly. It's intended for implementations of Crypto interface
+// Use WithSender() option function below instead.
+func NewOpt() *WrapKeyOptions {
+	return &WrapKeyOptions{}
+}
+
+// SenderKey gets the Sender key to be used for key wrapping using a sender key (authcrypt).
+// Not to be used directly. It's intended for implementations of Crypto interface.
+func (pk *WrapKeyOptions) SenderKey() interface{} {
+	return pk.senderKey
+}
+
+// WithSender option is for setting a sender key with crypto wrapping (eg: AuthCrypt). For Key Wrapping, the sender
+// key is the private key handle of the sender, for key unwrapping it is either the sender public key handle or
+// a *PublicKey of the sender.
+func WithSender(senderKey interface{}) WrapKeyOpts {
+	return func(opts *WrapKeyOptions) {
+		opts.senderKey = senderKey
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -24,6 +25,10 @@ const (
 
 	metadataKey = "metadata_%s"
 
+	// recordTTL is the time to live of the records of the inbound messages and of the thread metadata,
+	// the messages older than that can't be replied to
+	recordTTL = 7 * 24 * time.Hour
+
 	jsonID             = "@id"
 	jsonThread         = "~thread"
 	jsonThreadID       = "thid"
@@ -240,5 +245,5 @@ func (m *Messenger) saveRecord(msgID string, rec record) error {
 		return fmt.Errorf("marshal record: %w", err)
 	}
 
-	return m.store.Put(msgID, src)
+	return storage.PutWithTTL(m.store, msgID, src, recordTTL)
 }
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -19,6 +20,7 @@ import (
 	dispatcherMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/dispatcher"
 	messengerMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/messenger"
 	storageMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/storage"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
@@ -85,6 +87,20 @@ func TestMessenger_HandleInbound(t *testing.T) {
 		require.NoError(t, msgr.HandleInbound(service.DIDCommMsgMap{jsonID: ID}, myDID, theirDID))
 	})
 
+	t.Run("records expire", func(t *testing.T) {
+		storageProvider := mockstorage.NewMockStoreProvider()
+
+		provider := messengerMocks.NewMockProvider(ctrl)
+		provider.EXPECT().StorageProvider().Return(storageProvider)
+		provider.EXPECT().OutboundDispatcher().Return(nil)
+
+		msgr, err := NewMessenger(provider)
+		require.NoError(t, err)
+
+		require.NoError(t, msgr.HandleInbound(service.DIDCommMsgMap{jsonID: ID}, myDID, theirDID))
+		require.Equal(t, map[string]time.Duration{ID: recordTTL}, storageProvider.Store.TTLs)
+	})
+
 	t.Run("success without metadata", func(t *testing.T) {
 		store := storageMocks.NewMockStore(ctrl)
 		store.EXPECT().Put(ID, gomock.Any()).Return(nil)
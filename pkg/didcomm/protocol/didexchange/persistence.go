@@ -42,35 +42,32 @@ type connectionStore struct {
 	*did.ConnectionStore
 }
 
-// saveConnectionRecord saves the connection record against the connection id  in the store
+// saveConnectionRecord saves the connection record against the connection id  in the store.
+// The DID mappings are kept in a separate store and can't be written in the batch of the connection record, they are
+// saved first as saving them again is harmless: a failure never leaves a completed connection without its DID mappings.
 func (c *connectionStore) saveConnectionRecord(record *connection.Record) error {
-	err := c.SaveConnectionRecord(record)
-	if err != nil {
-		return fmt.Errorf(" failed to save connection record : %w", err)
-	}
-
 	if record.State == StateIDCompleted {
 		if err := c.SaveDIDByResolving(record.TheirDID, record.RecipientKeys...); err != nil {
 			return fmt.Errorf(" failed to save DID by resolving : %w", err)
 		}
 	}
 
+	err := c.SaveConnectionRecord(record)
+	if err != nil {
+		return fmt.Errorf(" failed to save connection record : %w", err)
+	}
+
 	return nil
 }
 
 // saveConnectionRecordWithMapping saves newly created connection record against the connection id in the store
-// and it creates mapping from namespaced ThreadID to connection ID
+// and it creates mapping from namespaced ThreadID to connection ID, the DID mappings are saved first as above.
 func (c *connectionStore) saveConnectionRecordWithMapping(record *connection.Record) error {
-	err := c.SaveConnectionRecordWithMappings(record)
-	if err != nil {
-		return err
-	}
-
 	if record.MyDID != "" {
 		if err := c.SaveDIDByResolving(record.MyDID); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return c.SaveConnectionRecordWithMappings(record)
 }
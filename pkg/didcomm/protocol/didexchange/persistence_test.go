@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package didexchange
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/mock/didcomm/protocol"
 	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
 	"github.com/hyperledger/aries-framework-go/pkg/store/did"
 )
@@ -128,10 +130,9 @@ func TestConnectionRecorder_SaveConnectionRecord(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "resolve error")
 
-		// note: record is still stored, since error happens afterwards
-		storedRecord, err := record.GetConnectionRecord(connRec.ConnectionID)
-		require.NoError(t, err)
-		require.Equal(t, connRec, storedRecord)
+		// the record isn't stored without its DID mappings
+		_, err = record.GetConnectionRecord(connRec.ConnectionID)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
 	})
 	t.Run("error saving DID by resolving", func(t *testing.T) {
 		record, err := newConnectionStore(&protocol.MockProvider{})
@@ -151,10 +152,9 @@ func TestConnectionRecorder_SaveConnectionRecord(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "resolve error")
 
-		// note: record is still stored, since error happens afterwards
-		storedRecord, err := record.GetConnectionRecord(connRec.ConnectionID)
-		require.NoError(t, err)
-		require.Equal(t, connRec, storedRecord)
+		// the record isn't stored without its DID mappings
+		_, err = record.GetConnectionRecord(connRec.ConnectionID)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
 	})
 }
 
@@ -578,7 +578,7 @@ type mockStore struct {
 }
 
 // Put stores the key and the record
-func (m *mockStore) Put(k string, v []byte) error {
+func (m *mockStore) Put(k string, v []byte, _ ...storage.Tag) error {
 	return m.put(k, v)
 }
 
@@ -597,6 +597,30 @@ func (m *mockStore) Iterator(start, limit string) storage.StoreIterator {
 	return nil
 }
 
+// Query returns storage iterator
+func (m *mockStore) Query(expression string, _ ...storage.QueryOption) (storage.StoreIterator, error) {
+	return nil, nil
+}
+
+// Batch applies the operations with put and delete
+func (m *mockStore) Batch(operations []storage.Operation) error {
+	for _, op := range operations {
+		var err error
+
+		if op.IsDelete() {
+			err = m.delete(op.Key)
+		} else {
+			err = m.put(op.Key, op.Value)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func randomString() string {
 	u := uuid.New()
 	return u.String()
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mitchellh/mapstructure"
@@ -41,6 +42,8 @@ const (
 	callbackChannelSize = 10
 
 	transitionalPayloadKey = "transitional_payload_%s"
+	// transitionalPayloadTTL is the time to live of the transitional payloads of the exchanges not completed
+	transitionalPayloadTTL = 72 * time.Hour
 )
 
 var logger = log.New(fmt.Sprintf("aries-framework/%s/service", Name))
@@ -321,7 +324,7 @@ func (s *Service) saveTransitionalPayload(id string, data *transitionalPayload)
 		return fmt.Errorf("marshal transitional payload: %w", err)
 	}
 
-	return s.store.Put(fmt.Sprintf(transitionalPayloadKey, id), src)
+	return storage.PutWithTTL(s.store, fmt.Sprintf(transitionalPayloadKey, id), src, transitionalPayloadTTL)
 }
 
 func (s *Service) deleteTransitionalPayload(id string) error {
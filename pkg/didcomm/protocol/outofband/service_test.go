@@ -219,7 +219,8 @@ func TestHandleInbound(t *testing.T) {
 func TestService_ActionContinue(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		msg := service.NewDIDCommMsgMap(newRequest())
-		s, err := New(testProvider())
+		provider := testProvider()
+		s, err := New(provider)
 		require.NoError(t, err)
 
 		actions := make(chan service.DIDCommAction)
@@ -236,6 +237,9 @@ func TestService_ActionContinue(t *testing.T) {
 			remainingActions, err = s.Actions()
 			require.NoError(t, err)
 			require.Equal(t, 1, len(remainingActions))
+			// the transitional payload of the action expires
+			require.Equal(t, transitionalPayloadTTL, provider.TransientStoreProvider.Store.TTLs[fmt.Sprintf(
+				transitionalPayloadKey, remainingActions[0].PIID)])
 			require.NoError(t, s.ActionContinue(remainingActions[0].PIID, &userOptions{}))
 		case <-time.After(1 * time.Second):
 			t.Error("timeout")
@@ -1257,7 +1261,7 @@ type stubStore struct {
 	putFunc func(k string, v []byte) error
 }
 
-func (s *stubStore) Put(k string, v []byte) error {
+func (s *stubStore) Put(k string, v []byte, _ ...storage.Tag) error {
 	if s.putFunc != nil {
 		return s.putFunc(k, v)
 	}
@@ -1277,6 +1281,14 @@ func (s *stubStore) Delete(k string) error {
 	panic("implement me")
 }
 
+func (s *stubStore) Query(expression string, _ ...storage.QueryOption) (storage.StoreIterator, error) {
+	panic("implement me")
+}
+
+func (s *stubStore) Batch(operations []storage.Operation) error {
+	panic("implement me")
+}
+
 type outboundMsgHandlerStub struct {
 	handleFunc func(service.DIDCommMsg, string, string) error
 }
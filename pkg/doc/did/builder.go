@@ -0,0 +1,353 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DocBuilder builds DID document in a fluent way.
+// The first error met by the builder is kept and returned by Build, all subsequent calls are no-op.
+type DocBuilder struct {
+	doc *Doc
+	err error
+}
+
+// NewDocBuilder creates a new DocBuilder for DID document with the given ID.
+func NewDocBuilder(id string) *DocBuilder {
+	return &DocBuilder{doc: BuildDoc(func(opts *Doc) { opts.ID = id })}
+}
+
+// WithContext sets @context of DID document.
+func (b *DocBuilder) WithContext(context ...string) *DocBuilder {
+	return b.apply(func(doc *Doc) error {
+		if len(context) == 0 {
+			return errors.New("context is empty")
+		}
+
+		doc.Context = context
+
+		return nil
+	})
+}
+
+// AddController adds controllers of DID document.
+func (b *DocBuilder) AddController(controller ...string) *DocBuilder {
+	return b.apply(func(doc *Doc) error {
+		return doc.AddController(controller...)
+	})
+}
+
+// AddAlsoKnownAs adds alternative identifiers of the DID subject.
+func (b *DocBuilder) AddAlsoKnownAs(uri ...string) *DocBuilder {
+	return b.apply(func(doc *Doc) error {
+		return doc.AddAlsoKnownAs(uri...)
+	})
+}
+
+// AddPublicKey adds public key to DID document and refers to it from the given verification relationships.
+func (b *DocBuilder) AddPublicKey(pk *PublicKey, relationships ...VerificationRelationship) *DocBuilder {
+	return b.apply(func(doc *Doc) error {
+		return doc.AddPublicKey(pk, relationships...)
+	})
+}
+
+// AddVerificationMethod adds verification method (embedded or referenced) to DID document.
+func (b *DocBuilder) AddVerificationMethod(vm *VerificationMethod) *DocBuilder {
+	return b.apply(func(doc *Doc) error {
+		return doc.AddVerificationMethod(vm)
+	})
+}
+
+// AddService adds service to DID document.
+func (b *DocBuilder) AddService(svc *Service) *DocBuilder {
+	return b.apply(func(doc *Doc) error {
+		return doc.AddService(svc)
+	})
+}
+
+// WithCreatedTime sets DID document created time.
+func (b *DocBuilder) WithCreatedTime(t time.Time) *DocBuilder {
+	return b.apply(func(doc *Doc) error {
+		doc.Created = &t
+
+		return nil
+	})
+}
+
+// WithUpdatedTime sets DID document updated time.
+func (b *DocBuilder) WithUpdatedTime(t time.Time) *DocBuilder {
+	return b.apply(func(doc *Doc) error {
+		doc.Updated = &t
+
+		return nil
+	})
+}
+
+// Build validates and returns DID document.
+func (b *DocBuilder) Build() (*Doc, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	if err := b.doc.Validate(); err != nil {
+		return nil, err
+	}
+
+	return b.doc, nil
+}
+
+func (b *DocBuilder) apply(f func(doc *Doc) error) *DocBuilder {
+	if b.err == nil {
+		b.err = f(b.doc)
+	}
+
+	return b
+}
+
+// AddController adds controllers of DID document. Already defined controllers are skipped.
+func (doc *Doc) AddController(controller ...string) error {
+	for _, c := range controller {
+		if _, err := Parse(c); err != nil {
+			return fmt.Errorf("add controller: %w", err)
+		}
+
+		if !containsString(doc.Controller, c) {
+			doc.Controller = append(doc.Controller, c)
+		}
+	}
+
+	return nil
+}
+
+// AddAlsoKnownAs adds alternative identifiers of the DID subject. Already defined identifiers are skipped.
+func (doc *Doc) AddAlsoKnownAs(uri ...string) error {
+	for _, u := range uri {
+		if u == "" {
+			return errors.New("add alsoKnownAs: empty URI")
+		}
+
+		if !containsString(doc.AlsoKnownAs, u) {
+			doc.AlsoKnownAs = append(doc.AlsoKnownAs, u)
+		}
+	}
+
+	return nil
+}
+
+// AddPublicKey adds public key to DID document and refers to it from the given verification relationships.
+func (doc *Doc) AddPublicKey(pk *PublicKey, relationships ...VerificationRelationship) error {
+	if pk.ID == "" {
+		return errors.New("add public key: key ID is empty")
+	}
+
+	if _, ok := doc.lookupPublicKey(pk.ID); ok {
+		return fmt.Errorf("add public key: key %s already exists", pk.ID)
+	}
+
+	// the key and its verification methods are added to a copy of the document, swapped in once all were added so
+	// that the document is unchanged on failure
+	updated := *doc
+
+	updated.PublicKey = append(append([]PublicKey(nil), doc.PublicKey...), *pk)
+
+	for _, r := range relationships {
+		if vms := updated.verificationMethods(r); vms != nil {
+			*vms = append([]VerificationMethod(nil), *vms...)
+		}
+
+		err := updated.AddVerificationMethod(NewReferencedVerificationMethod(pk, r, false))
+		if err != nil {
+			return err
+		}
+	}
+
+	*doc = updated
+
+	return nil
+}
+
+// RemovePublicKey removes public key with the given ID from DID document, including all verification methods
+// (referenced or embedded) of the key. It returns false if no key was removed.
+func (doc *Doc) RemovePublicKey(id string) bool {
+	removed := false
+
+	for i := range doc.PublicKey {
+		if doc.matchID(doc.PublicKey[i].ID, id) {
+			doc.PublicKey = append(doc.PublicKey[:i], doc.PublicKey[i+1:]...)
+			removed = true
+
+			break
+		}
+	}
+
+	for _, r := range verificationRelationships() {
+		if doc.RemoveVerificationMethod(id, r) {
+			removed = true
+		}
+	}
+
+	return removed
+}
+
+// AddVerificationMethod adds verification method to the verification relationship defined by vm.Relationship.
+// Public key of referenced (i.e. not embedded) verification method must be defined in DID document.
+func (doc *Doc) AddVerificationMethod(vm *VerificationMethod) error {
+	vms := doc.verificationMethods(vm.Relationship)
+	if vms == nil {
+		return fmt.Errorf("add verification method: unsupported verification relationship %d", vm.Relationship)
+	}
+
+	if vm.PublicKey.ID == "" {
+		return errors.New("add verification method: key ID is empty")
+	}
+
+	if !vm.Embedded {
+		if _, ok := doc.lookupPublicKey(vm.PublicKey.ID); !ok {
+			return fmt.Errorf("add verification method: key %s not exist in did doc public key", vm.PublicKey.ID)
+		}
+	}
+
+	for _, existing := range *vms {
+		if doc.matchID(existing.PublicKey.ID, vm.PublicKey.ID) {
+			return fmt.Errorf("add verification method: key %s is already used in the verification relationship",
+				vm.PublicKey.ID)
+		}
+	}
+
+	*vms = append(*vms, *vm)
+
+	return nil
+}
+
+// RemoveVerificationMethod removes verification method of the key with the given ID from the verification
+// relationship. It returns false if no verification method was removed.
+func (doc *Doc) RemoveVerificationMethod(keyID string, relationship VerificationRelationship) bool {
+	vms := doc.verificationMethods(relationship)
+	if vms == nil {
+		return false
+	}
+
+	for i := range *vms {
+		if doc.matchID((*vms)[i].PublicKey.ID, keyID) {
+			*vms = append((*vms)[:i], (*vms)[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddService adds service to DID document.
+func (doc *Doc) AddService(svc *Service) error {
+	if svc.ID == "" || svc.Type == "" || svc.ServiceEndpoint == "" {
+		return errors.New("add service: service ID, type and endpoint are mandatory")
+	}
+
+	for i := range doc.Service {
+		if doc.matchID(doc.Service[i].ID, svc.ID) {
+			return fmt.Errorf("add service: service %s already exists", svc.ID)
+		}
+	}
+
+	if containsString(svc.RecipientKeys, "") || containsString(svc.RoutingKeys, "") {
+		return fmt.Errorf("add service: service %s has empty recipient or routing key", svc.ID)
+	}
+
+	doc.Service = append(doc.Service, *svc)
+
+	return nil
+}
+
+// RemoveService removes service with the given ID from DID document.
+// It returns false if no service was removed.
+func (doc *Doc) RemoveService(id string) bool {
+	for i := range doc.Service {
+		if doc.matchID(doc.Service[i].ID, id) {
+			doc.Service = append(doc.Service[:i], doc.Service[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate validates DID document against JSON schema of its context.
+// In addition, it checks that referenced verification methods point to the public keys of DID document.
+func (doc *Doc) Validate() error {
+	for r, vms := range doc.VerificationMethods() {
+		if r == VerificationRelationshipGeneral {
+			continue
+		}
+
+		for _, vm := range vms {
+			if _, ok := doc.lookupPublicKey(vm.PublicKey.ID); !vm.Embedded && !ok {
+				return fmt.Errorf("key %s not exist in did doc public key", vm.PublicKey.ID)
+			}
+		}
+	}
+
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return err
+	}
+
+	raw := &rawDoc{Context: doc.Context}
+
+	return validate(docBytes, raw.schemaLoader())
+}
+
+func (doc *Doc) verificationMethods(relationship VerificationRelationship) *[]VerificationMethod {
+	switch relationship {
+	case Authentication:
+		return &doc.Authentication
+	case AssertionMethod:
+		return &doc.AssertionMethod
+	case CapabilityDelegation:
+		return &doc.CapabilityDelegation
+	case CapabilityInvocation:
+		return &doc.CapabilityInvocation
+	case KeyAgreement:
+		return &doc.KeyAgreement
+	default:
+		return nil
+	}
+}
+
+func (doc *Doc) lookupPublicKey(id string) (*PublicKey, bool) {
+	for i := range doc.PublicKey {
+		if doc.matchID(doc.PublicKey[i].ID, id) {
+			return &doc.PublicKey[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// matchID checks if DID URL (e.g. public key or service ID) matches the given absolute or relative DID URL.
+func (doc *Doc) matchID(didURL, id string) bool {
+	return didURL == id || didURL == resolveRelativeDIDURL(doc.ID, id) || resolveRelativeDIDURL(doc.ID, didURL) == id
+}
+
+func verificationRelationships() []VerificationRelationship {
+	return []VerificationRelationship{
+		Authentication, AssertionMethod, CapabilityDelegation, CapabilityInvocation, KeyAgreement,
+	}
+}
+
+func containsString(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+
+	return false
+}
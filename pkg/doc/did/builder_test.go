@@ -0,0 +1,255 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	builderDID       = "did:example:123456789abcdefghi"
+	builderKeyID     = builderDID + "#key-1"
+	builderAgreeKey  = builderDID + "#key-2"
+	builderServiceID = builderDID + "#didcomm"
+)
+
+func TestDocBuilder(t *testing.T) {
+	t.Run("build DID doc with all parts", func(t *testing.T) {
+		ti := time.Now().UTC().Truncate(time.Second)
+
+		signingKey := NewPublicKeyFromBytes(builderKeyID, "Ed25519VerificationKey2018", builderDID, []byte("key1"))
+		agreementKey := NewPublicKeyFromBytes(builderAgreeKey, "X25519KeyAgreementKey2019", builderDID, []byte("key2"))
+
+		doc, err := NewDocBuilder(builderDID).
+			AddController("did:example:controller", builderDID).
+			AddAlsoKnownAs("https://example.com/alice").
+			AddPublicKey(signingKey, Authentication, AssertionMethod, CapabilityInvocation, CapabilityDelegation).
+			AddVerificationMethod(NewEmbeddedVerificationMethod(agreementKey, KeyAgreement)).
+			AddService(&Service{
+				ID:              builderServiceID,
+				Type:            "did-communication",
+				Priority:        1,
+				RecipientKeys:   []string{builderKeyID},
+				RoutingKeys:     []string{"did:example:mediator#key-1"},
+				ServiceEndpoint: "https://agent.example.com",
+			}).
+			WithCreatedTime(ti).
+			WithUpdatedTime(ti).
+			Build()
+		require.NoError(t, err)
+		require.NotNil(t, doc)
+
+		require.Equal(t, []string{Context}, doc.Context)
+		require.Equal(t, []string{"did:example:controller", builderDID}, doc.Controller)
+		require.Equal(t, []string{"https://example.com/alice"}, doc.AlsoKnownAs)
+		require.Len(t, doc.PublicKey, 1)
+		require.Len(t, doc.Authentication, 1)
+		require.Len(t, doc.AssertionMethod, 1)
+		require.Len(t, doc.CapabilityInvocation, 1)
+		require.Len(t, doc.CapabilityDelegation, 1)
+		require.Len(t, doc.KeyAgreement, 1)
+		require.True(t, doc.KeyAgreement[0].Embedded)
+
+		docBytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+
+		parsedDoc, err := ParseDocument(docBytes)
+		require.NoError(t, err)
+		require.Equal(t, doc.Controller, parsedDoc.Controller)
+		require.Equal(t, doc.AlsoKnownAs, parsedDoc.AlsoKnownAs)
+		require.Equal(t, builderKeyID, parsedDoc.AssertionMethod[0].PublicKey.ID)
+		require.Equal(t, builderAgreeKey, parsedDoc.KeyAgreement[0].PublicKey.ID)
+		require.Equal(t, uint(1), parsedDoc.Service[0].Priority)
+		require.Equal(t, []string{"did:example:mediator#key-1"}, parsedDoc.Service[0].RoutingKeys)
+	})
+
+	t.Run("single controller is serialized as string", func(t *testing.T) {
+		doc, err := NewDocBuilder(builderDID).AddController(builderDID).Build()
+		require.NoError(t, err)
+
+		docBytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+		require.Contains(t, string(docBytes), `"controller":"`+builderDID+`"`)
+
+		parsedDoc, err := ParseDocument(docBytes)
+		require.NoError(t, err)
+		require.Equal(t, []string{builderDID}, parsedDoc.Controller)
+	})
+
+	t.Run("first error is returned", func(t *testing.T) {
+		pk := NewPublicKeyFromBytes(builderKeyID, "Ed25519VerificationKey2018", builderDID, []byte("key1"))
+
+		doc, err := NewDocBuilder(builderDID).
+			AddPublicKey(pk).
+			AddPublicKey(pk).
+			AddController("invalid").
+			Build()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key "+builderKeyID+" already exists")
+		require.Nil(t, doc)
+	})
+
+	t.Run("invalid context", func(t *testing.T) {
+		_, err := NewDocBuilder(builderDID).WithContext().Build()
+		require.EqualError(t, err, "context is empty")
+
+		_, err = NewDocBuilder(builderDID).WithContext("https://example.com/context").Build()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "did document not valid")
+	})
+
+	t.Run("invalid doc is not built", func(t *testing.T) {
+		doc, err := NewDocBuilder("").Build()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "id is required")
+		require.Nil(t, doc)
+	})
+}
+
+func TestDoc_AddPublicKey(t *testing.T) {
+	t.Run("add key with relative URL", func(t *testing.T) {
+		doc := BuildDoc(WithPublicKey([]PublicKey{{ID: builderKeyID}}))
+		doc.ID = builderDID
+
+		err := doc.AddPublicKey(&PublicKey{ID: "#key-1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "already exists")
+
+		err = doc.AddVerificationMethod(&VerificationMethod{
+			PublicKey: PublicKey{ID: builderKeyID}, Relationship: AssertionMethod, RelativeURL: true,
+		})
+		require.NoError(t, err)
+
+		err = doc.AddVerificationMethod(&VerificationMethod{
+			PublicKey: PublicKey{ID: "#key-1"}, Relationship: AssertionMethod,
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is already used in the verification relationship")
+	})
+
+	t.Run("empty key ID", func(t *testing.T) {
+		doc := BuildDoc()
+
+		err := doc.AddPublicKey(&PublicKey{})
+		require.EqualError(t, err, "add public key: key ID is empty")
+
+		err = doc.AddVerificationMethod(&VerificationMethod{Relationship: KeyAgreement, Embedded: true})
+		require.EqualError(t, err, "add verification method: key ID is empty")
+	})
+
+	t.Run("unsupported relationship", func(t *testing.T) {
+		doc := BuildDoc()
+
+		err := doc.AddPublicKey(&PublicKey{ID: builderKeyID}, VerificationRelationshipGeneral)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported verification relationship")
+		require.Empty(t, doc.PublicKey)
+	})
+
+	t.Run("document unchanged on failure", func(t *testing.T) {
+		doc := BuildDoc(WithPublicKey([]PublicKey{{ID: "#key-2"}}))
+		doc.ID = builderDID
+
+		err := doc.AddPublicKey(&PublicKey{ID: builderKeyID}, Authentication, AssertionMethod, Authentication)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is already used in the verification relationship")
+		require.Equal(t, []PublicKey{{ID: "#key-2"}}, doc.PublicKey)
+		require.Empty(t, doc.Authentication)
+		require.Empty(t, doc.AssertionMethod)
+	})
+
+	t.Run("referenced key does not exist", func(t *testing.T) {
+		doc := BuildDoc()
+
+		err := doc.AddVerificationMethod(&VerificationMethod{
+			PublicKey: PublicKey{ID: builderKeyID}, Relationship: Authentication,
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not exist in did doc public key")
+	})
+}
+
+func TestDoc_RemovePublicKey(t *testing.T) {
+	pk := NewPublicKeyFromBytes(builderKeyID, "Ed25519VerificationKey2018", builderDID, []byte("key1"))
+	agreementKey := NewPublicKeyFromBytes(builderAgreeKey, "X25519KeyAgreementKey2019", builderDID, []byte("key2"))
+
+	doc, err := NewDocBuilder(builderDID).
+		AddPublicKey(pk, Authentication, AssertionMethod).
+		AddVerificationMethod(NewEmbeddedVerificationMethod(agreementKey, KeyAgreement)).
+		Build()
+	require.NoError(t, err)
+
+	require.True(t, doc.RemoveVerificationMethod("#key-1", Authentication))
+	require.False(t, doc.RemoveVerificationMethod(builderKeyID, Authentication))
+	require.False(t, doc.RemoveVerificationMethod(builderKeyID, VerificationRelationshipGeneral))
+	require.Empty(t, doc.Authentication)
+	require.Len(t, doc.AssertionMethod, 1)
+
+	require.True(t, doc.RemovePublicKey(builderKeyID))
+	require.Empty(t, doc.PublicKey)
+	require.Empty(t, doc.AssertionMethod)
+
+	require.True(t, doc.RemovePublicKey(builderAgreeKey))
+	require.Empty(t, doc.KeyAgreement)
+
+	require.False(t, doc.RemovePublicKey(builderKeyID))
+	require.NoError(t, doc.Validate())
+}
+
+func TestDoc_Service(t *testing.T) {
+	doc := BuildDoc()
+	doc.ID = builderDID
+
+	err := doc.AddService(&Service{ID: builderServiceID, Type: "did-communication"})
+	require.EqualError(t, err, "add service: service ID, type and endpoint are mandatory")
+
+	svc := &Service{ID: builderServiceID, Type: "did-communication", ServiceEndpoint: "https://example.com"}
+	require.NoError(t, doc.AddService(svc))
+
+	err = doc.AddService(&Service{ID: "#didcomm", Type: "did-communication", ServiceEndpoint: "https://example.com"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already exists")
+
+	err = doc.AddService(&Service{
+		ID: "#other", Type: "did-communication", ServiceEndpoint: "https://example.com", RoutingKeys: []string{""},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "has empty recipient or routing key")
+
+	require.True(t, doc.RemoveService("#didcomm"))
+	require.False(t, doc.RemoveService(builderServiceID))
+	require.Empty(t, doc.Service)
+}
+
+func TestDoc_AddControllerAndAlsoKnownAs(t *testing.T) {
+	doc := BuildDoc()
+
+	require.NoError(t, doc.AddController(builderDID, builderDID))
+	require.Equal(t, []string{builderDID}, doc.Controller)
+
+	err := doc.AddController("not-a-did")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "add controller: invalid did")
+
+	require.NoError(t, doc.AddAlsoKnownAs("https://example.com", "https://example.com"))
+	require.Equal(t, []string{"https://example.com"}, doc.AlsoKnownAs)
+	require.EqualError(t, doc.AddAlsoKnownAs(""), "add alsoKnownAs: empty URI")
+}
+
+func TestDoc_Validate(t *testing.T) {
+	doc := BuildDoc(
+		WithAssertionMethod([]VerificationMethod{{PublicKey: PublicKey{ID: builderKeyID}}}),
+		WithKeyAgreement(nil), WithCapabilityInvocation(nil), WithCapabilityDelegation(nil),
+		WithController(nil), WithAlsoKnownAs(nil))
+	doc.ID = builderDID
+
+	err := doc.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "key "+builderKeyID+" not exist in did doc public key")
+}
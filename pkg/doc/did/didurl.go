@@ -0,0 +1,181 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	// ServiceQuery is DID URL query parameter which selects a service of DID document.
+	ServiceQuery = "service"
+	// RelativeRefQuery is DID URL query parameter which holds a relative reference appended to the service endpoint.
+	RelativeRefQuery = "relativeRef"
+	// VersionIDQuery is DID URL query parameter which requests a specific version of DID document.
+	VersionIDQuery = "versionId"
+	// VersionTimeQuery is DID URL query parameter which requests a version of DID document valid at the given time.
+	VersionTimeQuery = "versionTime"
+)
+
+// ErrResourceNotFound is returned when DID URL does not point to any resource of DID document.
+var ErrResourceNotFound = errors.New("DID URL resource not found")
+
+// DIDURL holds parsed DID URL.
+// See https://w3c.github.io/did-core/#did-url-syntax.
+type DIDURL struct {
+	DID      DID
+	Path     string
+	Queries  map[string][]string
+	Fragment string
+}
+
+// ParseDIDURL parses the string according to the generic DID URL syntax.
+func ParseDIDURL(didURL string) (*DIDURL, error) {
+	didPart, rest := didURL, ""
+
+	if i := strings.IndexAny(didURL, "/?#"); i >= 0 {
+		didPart, rest = didURL[:i], didURL[i:]
+	}
+
+	did, err := Parse(didPart)
+	if err != nil {
+		return nil, fmt.Errorf("parse DID URL: %w", err)
+	}
+
+	u, err := url.Parse(rest)
+	if err != nil {
+		return nil, fmt.Errorf("parse DID URL: %w", err)
+	}
+
+	queries, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("parse DID URL query: %w", err)
+	}
+
+	return &DIDURL{
+		DID:      *did,
+		Path:     u.Path,
+		Queries:  queries,
+		Fragment: u.Fragment,
+	}, nil
+}
+
+// Query returns the first value of the DID URL query parameter or empty string if the parameter is not set.
+func (u *DIDURL) Query(name string) string {
+	if v := u.Queries[name]; len(v) > 0 {
+		return v[0]
+	}
+
+	return ""
+}
+
+// DereferenceResult holds a resource of DID document referenced by DID URL.
+// Only one of VerificationMethod and Service is set, when none of them is set DID URL refers to the whole Doc.
+type DereferenceResult struct {
+	Doc                *Doc
+	VerificationMethod *VerificationMethod
+	Service            *Service
+	// ServiceEndpoint is an endpoint of the selected service with the DID URL path,
+	// relativeRef query parameter and fragment applied to it.
+	ServiceEndpoint string
+}
+
+// Dereference returns a resource of DID document referenced by the given DID URL.
+// DID URL with "service" query parameter is dereferenced to the service endpoint, DID URL with fragment
+// is dereferenced to the verification method or service with the matching ID, otherwise DID document itself
+// is returned.
+func (doc *Doc) Dereference(didURL *DIDURL) (*DereferenceResult, error) {
+	if serviceID := didURL.Query(ServiceQuery); serviceID != "" {
+		return doc.dereferenceService(didURL, serviceID)
+	}
+
+	if didURL.Path != "" {
+		return nil, fmt.Errorf("dereference DID URL: path %s can be dereferenced through a service only",
+			didURL.Path)
+	}
+
+	if didURL.Fragment == "" {
+		return &DereferenceResult{Doc: doc}, nil
+	}
+
+	id := didURL.DID.String() + "#" + didURL.Fragment
+	relativeID := "#" + didURL.Fragment
+
+	if vm, ok := doc.lookupVerificationMethod(id, relativeID); ok {
+		return &DereferenceResult{Doc: doc, VerificationMethod: vm}, nil
+	}
+
+	if svc, ok := doc.lookupService(id, relativeID); ok {
+		return &DereferenceResult{Doc: doc, Service: svc, ServiceEndpoint: svc.ServiceEndpoint}, nil
+	}
+
+	return nil, fmt.Errorf("dereference DID URL: fragment %s: %w", didURL.Fragment, ErrResourceNotFound)
+}
+
+func (doc *Doc) dereferenceService(didURL *DIDURL, serviceID string) (*DereferenceResult, error) {
+	svc, ok := doc.lookupService(didURL.DID.String()+"#"+serviceID, "#"+serviceID)
+	if !ok {
+		return nil, fmt.Errorf("dereference DID URL: service %s: %w", serviceID, ErrResourceNotFound)
+	}
+
+	endpoint := strings.TrimSuffix(svc.ServiceEndpoint, "/") + didURL.Path
+
+	if relativeRef := didURL.Query(RelativeRefQuery); relativeRef != "" {
+		endpoint = strings.TrimSuffix(endpoint, "/") + "/" + strings.TrimPrefix(relativeRef, "/")
+	}
+
+	if didURL.Fragment != "" {
+		endpoint += "#" + didURL.Fragment
+	}
+
+	return &DereferenceResult{Doc: doc, Service: svc, ServiceEndpoint: endpoint}, nil
+}
+
+// lookupVerificationMethod looks for the public key or embedded verification method by absolute ID first
+// and falls back to the ID relative to DID document.
+func (doc *Doc) lookupVerificationMethod(id, relativeID string) (*VerificationMethod, bool) {
+	for _, match := range []func(string) bool{
+		func(vmID string) bool { return vmID == id },
+		func(vmID string) bool { return doc.matchID(vmID, relativeID) },
+	} {
+		for i := range doc.PublicKey {
+			if match(doc.PublicKey[i].ID) {
+				return NewReferencedVerificationMethod(&doc.PublicKey[i], VerificationRelationshipGeneral, false), true
+			}
+		}
+
+		for _, r := range verificationRelationships() {
+			for _, vm := range *doc.verificationMethods(r) {
+				if vm.Embedded && match(vm.PublicKey.ID) {
+					vm := vm
+
+					return &vm, true
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (doc *Doc) lookupService(id, relativeID string) (*Service, bool) {
+	for i := range doc.Service {
+		if doc.Service[i].ID == id {
+			return &doc.Service[i], true
+		}
+	}
+
+	for i := range doc.Service {
+		if doc.matchID(doc.Service[i].ID, relativeID) {
+			return &doc.Service[i], true
+		}
+	}
+
+	return nil, false
+}
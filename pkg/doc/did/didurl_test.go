@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDIDURL(t *testing.T) {
+	t.Run("DID URL with path, query and fragment", func(t *testing.T) {
+		u, err := ParseDIDURL("did:example:123456/path/to?service=agent&relativeRef=%2Ffile&versionId=1#frag")
+		require.NoError(t, err)
+		require.Equal(t, "did:example:123456", u.DID.String())
+		require.Equal(t, "/path/to", u.Path)
+		require.Equal(t, "agent", u.Query(ServiceQuery))
+		require.Equal(t, "/file", u.Query(RelativeRefQuery))
+		require.Equal(t, "1", u.Query(VersionIDQuery))
+		require.Empty(t, u.Query(VersionTimeQuery))
+		require.Equal(t, "frag", u.Fragment)
+	})
+
+	t.Run("DID URL with fragment only", func(t *testing.T) {
+		u, err := ParseDIDURL("did:example:123456#key-1")
+		require.NoError(t, err)
+		require.Equal(t, "example", u.DID.Method)
+		require.Equal(t, "123456", u.DID.MethodSpecificID)
+		require.Empty(t, u.Path)
+		require.Empty(t, u.Queries)
+		require.Equal(t, "key-1", u.Fragment)
+	})
+
+	t.Run("plain DID", func(t *testing.T) {
+		u, err := ParseDIDURL("did:example:123456")
+		require.NoError(t, err)
+		require.Equal(t, "did:example:123456", u.DID.String())
+		require.Empty(t, u.Fragment)
+	})
+
+	t.Run("invalid DID", func(t *testing.T) {
+		_, err := ParseDIDURL("did1#key1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse DID URL: invalid did")
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, err := ParseDIDURL("did:example:123456?service=%zz")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse DID URL")
+	})
+}
+
+func TestDoc_Dereference(t *testing.T) {
+	const didID = "did:example:123456789abcdefghi"
+
+	signingKey := NewPublicKeyFromBytes(didID+"#key-1", "Ed25519VerificationKey2018", didID, []byte("key1"))
+	agreementKey := NewPublicKeyFromBytes(didID+"#key-2", "X25519KeyAgreementKey2019", didID, []byte("key2"))
+
+	doc, err := NewDocBuilder(didID).
+		AddPublicKey(signingKey, Authentication).
+		AddVerificationMethod(NewEmbeddedVerificationMethod(agreementKey, KeyAgreement)).
+		AddService(&Service{ID: "#agent", Type: "did-communication", ServiceEndpoint: "https://agent.example.com/"}).
+		Build()
+	require.NoError(t, err)
+
+	dereference := func(didURL string) (*DereferenceResult, error) {
+		u, e := ParseDIDURL(didURL)
+		require.NoError(t, e)
+
+		return doc.Dereference(u)
+	}
+
+	t.Run("DID document", func(t *testing.T) {
+		res, err := dereference(didID)
+		require.NoError(t, err)
+		require.Equal(t, doc, res.Doc)
+		require.Nil(t, res.VerificationMethod)
+		require.Nil(t, res.Service)
+	})
+
+	t.Run("public key", func(t *testing.T) {
+		res, err := dereference(didID + "#key-1")
+		require.NoError(t, err)
+		require.Equal(t, signingKey.Value, res.VerificationMethod.PublicKey.Value)
+		require.Equal(t, VerificationRelationshipGeneral, res.VerificationMethod.Relationship)
+	})
+
+	t.Run("embedded verification method", func(t *testing.T) {
+		res, err := dereference(didID + "#key-2")
+		require.NoError(t, err)
+		require.Equal(t, agreementKey.Value, res.VerificationMethod.PublicKey.Value)
+		require.Equal(t, KeyAgreement, res.VerificationMethod.Relationship)
+		require.True(t, res.VerificationMethod.Embedded)
+	})
+
+	t.Run("service by fragment", func(t *testing.T) {
+		res, err := dereference(didID + "#agent")
+		require.NoError(t, err)
+		require.Equal(t, "#agent", res.Service.ID)
+		require.Equal(t, "https://agent.example.com/", res.ServiceEndpoint)
+	})
+
+	t.Run("service endpoint with path, relativeRef and fragment", func(t *testing.T) {
+		res, err := dereference(didID + "/files?service=agent&relativeRef=%2Fresume.pdf#page-1")
+		require.NoError(t, err)
+		require.Equal(t, "#agent", res.Service.ID)
+		require.Equal(t, "https://agent.example.com/files/resume.pdf#page-1", res.ServiceEndpoint)
+	})
+
+	t.Run("service is not found", func(t *testing.T) {
+		_, err := dereference(didID + "?service=unknown")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrResourceNotFound))
+	})
+
+	t.Run("fragment is not found", func(t *testing.T) {
+		_, err := dereference(didID + "#key-3")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrResourceNotFound))
+	})
+
+	t.Run("path without service", func(t *testing.T) {
+		_, err := dereference(didID + "/path")
+		require.EqualError(t, err, "dereference DID URL: path /path can be dereferenced through a service only")
+	})
+}
@@ -50,6 +50,8 @@ const (
 	jsonldNonce          = "nonce"
 	jsonldProofPurpose   = "proofPurpose"
 
+	jsonldVerificationMethod = "verificationMethod"
+
 	// various public key encodings
 	jsonldPublicKeyBase58 = "publicKeyBase58"
 	jsonldPublicKeyHex    = "publicKeyHex"
@@ -104,6 +106,8 @@ func Parse(did string) (*DID, error) {
 type Doc struct {
 	Context              []string
 	ID                   string
+	Controller           []string
+	AlsoKnownAs          []string
 	PublicKey            []PublicKey
 	Service              []Service
 	Authentication       []VerificationMethod
@@ -224,6 +228,8 @@ func NewReferencedVerificationMethod(pk *PublicKey, r VerificationRelationship,
 type rawDoc struct {
 	Context              interface{}              `json:"@context,omitempty"`
 	ID                   string                   `json:"id,omitempty"`
+	Controller           interface{}              `json:"controller,omitempty"`
+	AlsoKnownAs          []interface{}            `json:"alsoKnownAs,omitempty"`
 	PublicKey            []map[string]interface{} `json:"publicKey,omitempty"`
 	Service              []map[string]interface{} `json:"service,omitempty"`
 	Authentication       []interface{}            `json:"authentication,omitempty"`
@@ -238,13 +244,14 @@ type rawDoc struct {
 
 // Proof is cryptographic proof of the integrity of the DID Document
 type Proof struct {
-	Type         string
-	Created      *time.Time
-	Creator      string
-	ProofValue   []byte
-	Domain       string
-	Nonce        []byte
-	ProofPurpose string
+	Type               string
+	Created            *time.Time
+	Creator            string
+	VerificationMethod string
+	ProofValue         []byte
+	Domain             string
+	Nonce              []byte
+	ProofPurpose       string
 }
 
 // ParseDocument creates an instance of DIDDocument by reading a JSON document from bytes
@@ -264,9 +271,11 @@ func ParseDocument(data []byte) (*Doc, error) {
 	}
 
 	doc := &Doc{
-		ID:      raw.ID,
-		Created: raw.Created,
-		Updated: raw.Updated,
+		ID:          raw.ID,
+		Controller:  stringOrArray(raw.Controller),
+		AlsoKnownAs: stringArray(raw.AlsoKnownAs),
+		Created:     raw.Created,
+		Updated:     raw.Updated,
 	}
 
 	context := raw.ParseContext()
@@ -367,13 +376,14 @@ func populateProofs(context string, rawProofs []interface{}) ([]Proof, error) {
 		}
 
 		proof := Proof{
-			Type:         stringEntry(emap[jsonldType]),
-			Created:      &timeValue,
-			Creator:      stringEntry(emap[jsonldCreator]),
-			ProofValue:   proofValue,
-			ProofPurpose: stringEntry(emap[jsonldProofPurpose]),
-			Domain:       stringEntry(emap[jsonldDomain]),
-			Nonce:        nonce,
+			Type:               stringEntry(emap[jsonldType]),
+			Created:            &timeValue,
+			Creator:            stringEntry(emap[jsonldCreator]),
+			VerificationMethod: stringEntry(emap[jsonldVerificationMethod]),
+			ProofValue:         proofValue,
+			ProofPurpose:       stringEntry(emap[jsonldProofPurpose]),
+			Domain:             stringEntry(emap[jsonldDomain]),
+			Nonce:              nonce,
 		}
 
 		proofs = append(proofs, proof)
@@ -688,6 +698,15 @@ func stringArray(entry interface{}) []string {
 	return result
 }
 
+// stringOrArray returns the entry as a list of strings, the entry being either a single string or an array.
+func stringOrArray(entry interface{}) []string {
+	if s, ok := entry.(string); ok {
+		return []string{s}
+	}
+
+	return stringArray(entry)
+}
+
 func mapEntry(entry interface{}) map[string]interface{} {
 	if entry == nil {
 		return nil
@@ -742,6 +761,8 @@ func (doc *Doc) JSONBytes() ([]byte, error) {
 	raw := &rawDoc{
 		Context:              doc.Context,
 		ID:                   doc.ID,
+		Controller:           populateRawController(doc.Controller),
+		AlsoKnownAs:          populateRawStrings(doc.AlsoKnownAs),
 		PublicKey:            publicKeys,
 		Authentication:       auths,
 		AssertionMethod:      assertionMethods,
@@ -892,6 +913,27 @@ func populateRawServices(services []Service) []map[string]interface{} {
 	return rawServices
 }
 
+func populateRawController(controller []string) interface{} {
+	switch len(controller) {
+	case 0:
+		return nil
+	case 1:
+		return controller[0]
+	default:
+		return populateRawStrings(controller)
+	}
+}
+
+func populateRawStrings(values []string) []interface{} {
+	var rawValues []interface{}
+
+	for _, v := range values {
+		rawValues = append(rawValues, v)
+	}
+
+	return rawValues
+}
+
 func populateRawPublicKeys(context string, pks []PublicKey) ([]map[string]interface{}, error) {
 	var rawPKs []map[string]interface{}
 
@@ -965,7 +1007,7 @@ func populateRawProofs(context string, proofs []Proof) []interface{} {
 	}
 
 	for _, p := range proofs {
-		rawProofs = append(rawProofs, map[string]interface{}{
+		rawProof := map[string]interface{}{
 			jsonldType:         p.Type,
 			jsonldCreated:      p.Created,
 			jsonldCreator:      p.Creator,
@@ -973,7 +1015,13 @@ func populateRawProofs(context string, proofs []Proof) []interface{} {
 			jsonldDomain:       p.Domain,
 			jsonldNonce:        base64.RawURLEncoding.EncodeToString(p.Nonce),
 			jsonldProofPurpose: p.ProofPurpose,
-		})
+		}
+
+		if p.VerificationMethod != "" {
+			rawProof[jsonldVerificationMethod] = p.VerificationMethod
+		}
+
+		rawProofs = append(rawProofs, rawProof)
 	}
 
 	return rawProofs
@@ -996,6 +1044,48 @@ func WithAuthentication(auth []VerificationMethod) DocOption {
 	}
 }
 
+// WithAssertionMethod DID doc AssertionMethod.
+func WithAssertionMethod(assertionMethod []VerificationMethod) DocOption {
+	return func(opts *Doc) {
+		opts.AssertionMethod = assertionMethod
+	}
+}
+
+// WithCapabilityDelegation DID doc CapabilityDelegation.
+func WithCapabilityDelegation(capabilityDelegation []VerificationMethod) DocOption {
+	return func(opts *Doc) {
+		opts.CapabilityDelegation = capabilityDelegation
+	}
+}
+
+// WithCapabilityInvocation DID doc CapabilityInvocation.
+func WithCapabilityInvocation(capabilityInvocation []VerificationMethod) DocOption {
+	return func(opts *Doc) {
+		opts.CapabilityInvocation = capabilityInvocation
+	}
+}
+
+// WithKeyAgreement DID doc KeyAgreement.
+func WithKeyAgreement(keyAgreement []VerificationMethod) DocOption {
+	return func(opts *Doc) {
+		opts.KeyAgreement = keyAgreement
+	}
+}
+
+// WithController DID doc controllers.
+func WithController(controller []string) DocOption {
+	return func(opts *Doc) {
+		opts.Controller = controller
+	}
+}
+
+// WithAlsoKnownAs DID doc alsoKnownAs identifiers.
+func WithAlsoKnownAs(alsoKnownAs []string) DocOption {
+	return func(opts *Doc) {
+		opts.AlsoKnownAs = alsoKnownAs
+	}
+}
+
 // WithService DID doc services.
 func WithService(svc []Service) DocOption {
 	return func(opts *Doc) {
@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/signer"
+)
+
+// LinkedDataProofContext holds options needed to build a Linked Data Proof of DID document.
+// Suite can be built with suite.NewCryptoSigner to keep the signing key inside the KMS.
+type LinkedDataProofContext struct {
+	SignatureType      string                // required
+	Suite              signer.SignatureSuite // required
+	VerificationMethod string                // required
+	Created            *time.Time            // optional
+	Purpose            string                // optional
+	Domain             string                // optional
+	Nonce              []byte                // optional
+}
+
+// AddLinkedDataProof appends proof to the DID document.
+// The verification method is put both into "creator" and "verificationMethod" fields of the proof.
+func (doc *Doc) AddLinkedDataProof(context *LinkedDataProofContext, jsonldOpts ...jsonld.ProcessorOpts) error {
+	if context.VerificationMethod == "" {
+		return errors.New("add linked data proof to DID doc: verification method is missing")
+	}
+
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return fmt.Errorf("add linked data proof to DID doc: %w", err)
+	}
+
+	signerContext := &signer.Context{
+		SignatureType:      context.SignatureType,
+		Creator:            context.VerificationMethod,
+		VerificationMethod: context.VerificationMethod,
+		Created:            context.Created,
+		Purpose:            context.Purpose,
+		Domain:             context.Domain,
+		Nonce:              context.Nonce,
+	}
+
+	defaultDocumentLoaderOpt := []jsonld.ProcessorOpts{jsonld.WithDocumentLoader(CachingJSONLDLoader())}
+
+	signedDocBytes, err := signer.New(context.Suite).Sign(signerContext, docBytes,
+		append(defaultDocumentLoaderOpt, jsonldOpts...)...)
+	if err != nil {
+		return fmt.Errorf("add linked data proof to DID doc: %w", err)
+	}
+
+	raw := &rawDoc{}
+
+	err = json.Unmarshal(signedDocBytes, raw)
+	if err != nil {
+		return fmt.Errorf("add linked data proof to DID doc: %w", err)
+	}
+
+	if len(raw.Proof) == 0 {
+		return errors.New("add linked data proof to DID doc: proof not found in signed document")
+	}
+
+	// signer appends a new proof and always puts signature into "proofValue" field
+	proofs, err := populateProofs(Context, raw.Proof[len(raw.Proof)-1:])
+	if err != nil {
+		return fmt.Errorf("add linked data proof to DID doc: %w", err)
+	}
+
+	doc.Proof = append(doc.Proof, proofs...)
+
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package did
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+func TestDoc_AddLinkedDataProof(t *testing.T) {
+	k, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(storage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	kid, kh, err := k.Create(kmsapi.ED25519Type)
+	require.NoError(t, err)
+
+	pubKeyBytes, err := k.ExportPubKeyBytes(kid)
+	require.NoError(t, err)
+
+	cr, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	ldpSuite := ed25519signature2018.New(
+		suite.WithSigner(suite.NewCryptoSigner(cr, kh.(*keyset.Handle))),
+		suite.WithVerifier(ed25519signature2018.NewPublicKeyVerifier()))
+
+	const didID = "did:example:signed"
+
+	keyID := didID + "#key-1"
+	pubKey := NewPublicKeyFromBytes(keyID, "Ed25519VerificationKey2018", didID, pubKeyBytes)
+
+	t.Run("sign and verify DID doc", func(t *testing.T) {
+		doc, err := NewDocBuilder(didID).AddPublicKey(pubKey, Authentication, AssertionMethod).Build()
+		require.NoError(t, err)
+
+		created := time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC)
+
+		err = doc.AddLinkedDataProof(&LinkedDataProofContext{
+			SignatureType:      signatureType,
+			Suite:              ldpSuite,
+			VerificationMethod: keyID,
+			Created:            &created,
+			Purpose:            "authentication",
+			Domain:             "example.com",
+			Nonce:              []byte("nonce"),
+		})
+		require.NoError(t, err)
+		require.Len(t, doc.Proof, 1)
+		require.Equal(t, keyID, doc.Proof[0].Creator)
+		require.Equal(t, keyID, doc.Proof[0].VerificationMethod)
+		require.Equal(t, "authentication", doc.Proof[0].ProofPurpose)
+		require.Equal(t, created, doc.Proof[0].Created.UTC())
+
+		require.NoError(t, doc.VerifyProof([]verifier.SignatureSuite{ldpSuite}))
+
+		docBytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+
+		parsedDoc, err := ParseDocument(docBytes)
+		require.NoError(t, err)
+		require.Equal(t, doc.Proof, parsedDoc.Proof)
+		require.NoError(t, parsedDoc.VerifyProof([]verifier.SignatureSuite{ldpSuite}))
+
+		// add second proof with default purpose and created time
+		err = parsedDoc.AddLinkedDataProof(&LinkedDataProofContext{
+			SignatureType:      signatureType,
+			Suite:              ldpSuite,
+			VerificationMethod: keyID,
+		})
+		require.NoError(t, err)
+		require.Len(t, parsedDoc.Proof, 2)
+		require.Equal(t, "assertionMethod", parsedDoc.Proof[1].ProofPurpose)
+		require.NotNil(t, parsedDoc.Proof[1].Created)
+		require.NoError(t, parsedDoc.VerifyProof([]verifier.SignatureSuite{ldpSuite}))
+	})
+
+	t.Run("verification method is missing", func(t *testing.T) {
+		doc := BuildDoc()
+
+		err := doc.AddLinkedDataProof(&LinkedDataProofContext{
+			SignatureType: signatureType,
+			Suite:         ldpSuite,
+		})
+		require.EqualError(t, err, "add linked data proof to DID doc: verification method is missing")
+	})
+
+	t.Run("signature type is not supported by suite", func(t *testing.T) {
+		doc := BuildDoc()
+		doc.ID = didID
+
+		err := doc.AddLinkedDataProof(&LinkedDataProofContext{
+			SignatureType:      "UnknownSignature",
+			Suite:              ldpSuite,
+			VerificationMethod: keyID,
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signature type UnknownSignature not supported")
+		require.Empty(t, doc.Proof)
+	})
+}
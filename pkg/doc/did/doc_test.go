@@ -1007,6 +1007,18 @@ func TestDidKeyResolver_Resolve(t *testing.T) {
 	require.Equal(t, testKeyVal, key.Value)
 }
 
+func TestGoJSONEncoding(t *testing.T) {
+	doc := &Doc{ID: "did:example:123", Controller: []string{"did:example:controller"},
+		AlsoKnownAs: []string{"did:example:alias"}, Proof: []Proof{{VerificationMethod: "did:example:123#key-1"}}}
+
+	docBytes, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	decoded := &Doc{}
+	require.NoError(t, json.Unmarshal(docBytes, decoded))
+	require.Equal(t, doc, decoded)
+}
+
 func TestBuildDoc(t *testing.T) {
 	ti := time.Now()
 	doc := BuildDoc(WithPublicKey([]PublicKey{{}}), WithService([]Service{{}, {}}),
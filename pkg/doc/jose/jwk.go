@@ -29,6 +29,10 @@ const (
 	secp256k1Kty  = "EC"
 	secp256k1Size = 32
 	bitsPerByte   = 8
+
+	x25519Crv  = "X25519"
+	x25519Kty  = "OKP"
+	x25519Size = 32
 )
 
 // JWK (JSON Web Key) is a JSON data structure that represents a cryptographic key.
@@ -62,8 +66,28 @@ func JWKFromPublicKey(pubKey interface{}) (*JWK, error) {
 	return key, nil
 }
 
+// JWKFromX25519Key creates a JWK (kty "OKP", crv "X25519") from a raw X25519 public key.
+func JWKFromX25519Key(pubKey []byte) (*JWK, error) {
+	if len(pubKey) != x25519Size {
+		return nil, fmt.Errorf("create JWK: %w", ErrInvalidKey)
+	}
+
+	key := make([]byte, x25519Size)
+	copy(key, pubKey)
+
+	return &JWK{
+		JSONWebKey: jose.JSONWebKey{Key: key},
+		Kty:        x25519Kty,
+		Crv:        x25519Crv,
+	}, nil
+}
+
 // PublicKeyBytes converts a public key to bytes.
 func (j *JWK) PublicKeyBytes() ([]byte, error) {
+	if j.isX25519() {
+		return j.Key.([]byte), nil
+	}
+
 	if j.isSecp256k1() {
 		var ecPubKey *ecdsa.PublicKey
 
@@ -102,14 +126,22 @@ func (j *JWK) UnmarshalJSON(jwkBytes []byte) error {
 		return fmt.Errorf("unable to read JWK: %w", marshalErr)
 	}
 
-	if isSecp256k1(key.Alg, key.Kty, key.Crv) {
+	switch {
+	case isX25519(key.Kty, key.Crv):
+		jwk, err := unmarshalX25519(&key)
+		if err != nil {
+			return fmt.Errorf("unable to read JWK: %w", err)
+		}
+
+		*j = *jwk
+	case isSecp256k1(key.Alg, key.Kty, key.Crv):
 		jwk, err := unmarshalSecp256k1(&key)
 		if err != nil {
 			return fmt.Errorf("unable to read JWK: %w", err)
 		}
 
 		*j = *jwk
-	} else {
+	default:
 		var joseJWK jose.JSONWebKey
 
 		err := json.Unmarshal(jwkBytes, &joseJWK)
@@ -128,6 +160,10 @@ func (j *JWK) UnmarshalJSON(jwkBytes []byte) error {
 
 // MarshalJSON serializes the given key to its JSON representation.
 func (j *JWK) MarshalJSON() ([]byte, error) {
+	if j.isX25519() {
+		return marshalX25519(j)
+	}
+
 	if j.isSecp256k1() {
 		return marshalSecp256k1(j)
 	}
@@ -135,6 +171,42 @@ func (j *JWK) MarshalJSON() ([]byte, error) {
 	return (&j.JSONWebKey).MarshalJSON()
 }
 
+func (j *JWK) isX25519() bool {
+	_, ok := j.Key.([]byte)
+
+	return ok && isX25519(j.Kty, j.Crv)
+}
+
+func isX25519(kty, crv string) bool {
+	return strings.EqualFold(kty, x25519Kty) && strings.EqualFold(crv, x25519Crv)
+}
+
+// unmarshalX25519 reads an X25519 public key, X25519 private keys are not supported.
+func unmarshalX25519(jwk *jsonWebKey) (*JWK, error) {
+	if jwk.X == nil || len(jwk.X.data) != x25519Size || jwk.D != nil {
+		return nil, ErrInvalidKey
+	}
+
+	return &JWK{
+		JSONWebKey: jose.JSONWebKey{
+			Key: jwk.X.data, KeyID: jwk.Kid, Algorithm: jwk.Alg, Use: jwk.Use,
+		},
+	}, nil
+}
+
+func marshalX25519(jwk *JWK) ([]byte, error) {
+	raw := jsonWebKey{
+		Kty: x25519Kty,
+		Crv: x25519Crv,
+		X:   &byteBuffer{data: jwk.Key.([]byte)},
+		Kid: jwk.KeyID,
+		Alg: jwk.Algorithm,
+		Use: jwk.Use,
+	}
+
+	return json.Marshal(raw)
+}
+
 func (j *JWK) isSecp256k1() bool {
 	return isSecp256k1Key(j.Key) || isSecp256k1(j.Algorithm, j.Kty, j.Crv)
 }
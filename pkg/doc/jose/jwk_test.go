@@ -332,3 +332,37 @@ func TestJWK_PublicKeyBytesValidation(t *testing.T) {
 	require.Contains(t, err.Error(), "unsupported public key type in kid 'pubkey#123'")
 	require.Empty(t, pkBytes)
 }
+
+func TestJWKFromX25519Key(t *testing.T) {
+	pubKey := make([]byte, 32)
+	_, err := rand.Read(pubKey)
+	require.NoError(t, err)
+
+	jwk, err := JWKFromX25519Key(pubKey)
+	require.NoError(t, err)
+	require.Equal(t, "OKP", jwk.Kty)
+	require.Equal(t, "X25519", jwk.Crv)
+
+	jwk.KeyID = "key1"
+
+	jwkBytes, err := json.Marshal(jwk)
+	require.NoError(t, err)
+	require.Contains(t, string(jwkBytes), `"kty":"OKP"`)
+	require.Contains(t, string(jwkBytes), `"crv":"X25519"`)
+
+	parsed := &JWK{}
+	require.NoError(t, json.Unmarshal(jwkBytes, parsed))
+	require.Equal(t, "key1", parsed.KeyID)
+	require.Equal(t, "OKP", parsed.Kty)
+	require.Equal(t, "X25519", parsed.Crv)
+
+	pkBytes, err := parsed.PublicKeyBytes()
+	require.NoError(t, err)
+	require.Equal(t, pubKey, pkBytes)
+
+	_, err = JWKFromX25519Key([]byte("short"))
+	require.EqualError(t, err, "create JWK: invalid JWK")
+
+	err = json.Unmarshal([]byte(`{"kty":"OKP","crv":"X25519","x":"c2hvcnQ"}`), parsed)
+	require.EqualError(t, err, "unable to read JWK: invalid JWK")
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/piprate/json-gold/ld"
 	"github.com/xeipuuv/gojsonschema"
 
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 )
@@ -85,20 +86,42 @@ func NewDIDKeyResolver(vdriRegistry vdri.Registry) *DIDKeyResolver {
 }
 
 func (r *DIDKeyResolver) resolvePublicKey(issuerDID, keyID string) (*verifier.PublicKey, error) {
+	// key ID is either an absolute DID URL or a fragment relative to the issuer DID
+	didURL := keyID
+	if !strings.HasPrefix(keyID, "did:") {
+		didURL = issuerDID + "#" + strings.TrimPrefix(keyID, "#")
+	}
+
+	// the registry resolves DIDs the generic DID syntax parser rejects (e.g. percent-encoded method specific IDs),
+	// the key of those is looked up by its fragment
+	if _, err := did.ParseDIDURL(didURL); err != nil {
+		return r.resolvePublicKeyByFragment(issuerDID, keyID)
+	}
+
+	res, err := vdri.Dereference(r.vdriRegistry, didURL)
+	if err != nil && !errors.Is(err, did.ErrResourceNotFound) {
+		return nil, err
+	}
+
+	if err != nil || res.VerificationMethod == nil {
+		return nil, fmt.Errorf("public key with KID %s is not found for DID %s", keyID, issuerDID)
+	}
+
+	return verifierPublicKey(res.VerificationMethod), nil
+}
+
+func (r *DIDKeyResolver) resolvePublicKeyByFragment(issuerDID, keyID string) (*verifier.PublicKey, error) {
 	doc, err := r.vdriRegistry.Resolve(issuerDID)
 	if err != nil {
 		return nil, fmt.Errorf("resolve DID %s: %w", issuerDID, err)
 	}
 
-	methods := doc.VerificationMethods()
-	for _, verificationMethods := range methods {
-		for _, vm := range verificationMethods {
-			if strings.Contains(vm.PublicKey.ID, keyID) {
-				return &verifier.PublicKey{
-					Type:  vm.PublicKey.Type,
-					Value: vm.PublicKey.Value,
-					JWK:   vm.PublicKey.JSONWebKey(),
-				}, nil
+	fragment := "#" + keyID[strings.LastIndex(keyID, "#")+1:]
+
+	for _, verificationMethods := range doc.VerificationMethods() {
+		for i := range verificationMethods {
+			if id := verificationMethods[i].PublicKey.ID; id == issuerDID+fragment || id == fragment {
+				return verifierPublicKey(&verificationMethods[i]), nil
 			}
 		}
 	}
@@ -106,6 +129,14 @@ func (r *DIDKeyResolver) resolvePublicKey(issuerDID, keyID string) (*verifier.Pu
 	return nil, fmt.Errorf("public key with KID %s is not found for DID %s", keyID, issuerDID)
 }
 
+func verifierPublicKey(vm *did.VerificationMethod) *verifier.PublicKey {
+	return &verifier.PublicKey{
+		Type:  vm.PublicKey.Type,
+		Value: vm.PublicKey.Value,
+		JWK:   vm.PublicKey.JSONWebKey(),
+	}
+}
+
 // PublicKeyFetcher returns Public Key Fetcher via DID resolution mechanism.
 func (r *DIDKeyResolver) PublicKeyFetcher() PublicKeyFetcher {
 	return r.resolvePublicKey
@@ -266,16 +266,50 @@ func TestDIDKeyResolver_Resolve(t *testing.T) {
 	r.Equal(assertionMethod.PublicKey.Value, assertMethPubKey.Value)
 	r.Equal("Ed25519VerificationKey2018", assertMethPubKey.Type)
 
+	relativePubKey, err := resolver.PublicKeyFetcher()(didDoc.ID, "#keys-1")
+	r.NoError(err)
+	r.Equal(authentication.PublicKey.Value, relativePubKey.Value)
+
+	pubKey, err = resolver.PublicKeyFetcher()(didDoc.ID, didDoc.ID+"?service=endpoint-1")
+	r.Error(err)
+	r.EqualError(err, fmt.Sprintf("public key with KID %s?service=endpoint-1 is not found for DID %s",
+		didDoc.ID, didDoc.ID))
+	r.Nil(pubKey)
+
 	pubKey, err = resolver.PublicKeyFetcher()(didDoc.ID, "invalid key")
 	r.Error(err)
 	r.EqualError(err, fmt.Sprintf("public key with KID invalid key is not found for DID %s", didDoc.ID))
 	r.Nil(pubKey)
 
+	// DID not conforming to the generic DID syntax, resolved by the registry
+	const lenientDID = "did:test:2WxUJa8nVjXr5yS69JWoKZ%3D"
+
+	v.ResolveValue = &did.Doc{ID: lenientDID, Authentication: []did.VerificationMethod{
+		{PublicKey: did.PublicKey{ID: lenientDID + "#keys-1", Value: publicKey.Value}},
+		{PublicKey: did.PublicKey{ID: "#keys-2", Value: authentication.PublicKey.Value}},
+	}}
+
+	pubKey, err = resolver.PublicKeyFetcher()(lenientDID, "#keys-1")
+	r.NoError(err)
+	r.Equal(publicKey.Value, pubKey.Value)
+
+	pubKey, err = resolver.PublicKeyFetcher()(lenientDID, lenientDID+"#keys-2")
+	r.NoError(err)
+	r.Equal(authentication.PublicKey.Value, pubKey.Value)
+
+	pubKey, err = resolver.PublicKeyFetcher()(lenientDID, "#unknown")
+	r.EqualError(err, fmt.Sprintf("public key with KID #unknown is not found for DID %s", lenientDID))
+	r.Nil(pubKey)
+
 	v.ResolveErr = errors.New("resolver error")
 	pubKey, err = resolver.PublicKeyFetcher()(didDoc.ID, "")
 	r.Error(err)
 	r.EqualError(err, fmt.Sprintf("resolve DID %s: resolver error", didDoc.ID))
 	r.Nil(pubKey)
+
+	pubKey, err = resolver.PublicKeyFetcher()(lenientDID, "#keys-1")
+	r.EqualError(err, fmt.Sprintf("resolve DID %s: resolver error", lenientDID))
+	r.Nil(pubKey)
 }
 
 //nolint:lll
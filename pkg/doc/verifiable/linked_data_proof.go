@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/proof"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/signer"
@@ -26,19 +27,18 @@ type keyResolverAdapter struct {
 }
 
 func (k *keyResolverAdapter) Resolve(id string) (*verifier.PublicKey, error) {
-	// id will contain didID#keyID
+	// id is DID URL (didID#keyID) in most cases
+	if didURL, err := did.ParseDIDURL(id); err == nil && didURL.Fragment != "" {
+		return k.pubKeyFetcher(didURL.DID.String(), "#"+didURL.Fragment)
+	}
+
+	// fallback for non-DID key IDs: id will contain issuerID#keyID
 	idSplit := strings.Split(id, "#")
 	if len(idSplit) != resolveIDParts {
 		return nil, fmt.Errorf("wrong id %s to resolve", idSplit)
 	}
-	// idSplit[0] is didID
-	// idSplit[1] is keyID
-	pubKey, err := k.pubKeyFetcher(idSplit[0], fmt.Sprintf("#%s", idSplit[1]))
-	if err != nil {
-		return nil, err
-	}
 
-	return pubKey, nil
+	return k.pubKeyFetcher(idSplit[0], fmt.Sprintf("#%s", idSplit[1]))
 }
 
 // SignatureRepresentation is a signature value holder type (e.g. "proofValue" or "jws").
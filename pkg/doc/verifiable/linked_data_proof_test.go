@@ -33,6 +33,18 @@ func Test_keyResolverAdapter_Resolve(t *testing.T) {
 		require.Equal(t, []byte(pubKey), resolvedPubKey.Value)
 	})
 
+	t.Run("successful public key resolving by DID URL", func(t *testing.T) {
+		kra := &keyResolverAdapter{pubKeyFetcher: func(issuerID, keyID string) (*verifier.PublicKey, error) {
+			require.Equal(t, "did:example:123456", issuerID)
+			require.Equal(t, "#key1", keyID)
+
+			return &verifier.PublicKey{}, nil
+		}}
+		resolvedPubKey, err := kra.Resolve("did:example:123456?versionId=1#key1")
+		require.NoError(t, err)
+		require.NotNil(t, resolvedPubKey)
+	})
+
 	t.Run("error wrong key format", func(t *testing.T) {
 		kra := &keyResolverAdapter{pubKeyFetcher: func(issuerID, keyID string) (*verifier.PublicKey, error) {
 			return nil, nil
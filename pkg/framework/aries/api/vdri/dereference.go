@@ -0,0 +1,46 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vdri
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+)
+
+// Dereference resolves DID of the given DID URL using the registry and returns the resource DID URL refers to
+// (DID document, verification method or service endpoint).
+// "versionId" and "versionTime" query parameters of DID URL are passed to the DID resolution.
+func Dereference(registry Registry, didURL string, opts ...ResolveOpts) (*did.DereferenceResult, error) {
+	u, err := did.ParseDIDURL(didURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if versionID := u.Query(did.VersionIDQuery); versionID != "" {
+		opts = append(opts, WithVersionID(versionID))
+	}
+
+	if versionTime := u.Query(did.VersionTimeQuery); versionTime != "" {
+		t, e := time.Parse(time.RFC3339, versionTime)
+		if e != nil {
+			return nil, fmt.Errorf("parse %s query parameter: %w", did.VersionTimeQuery, e)
+		}
+
+		opts = append(opts, WithVersionTime(t))
+	}
+
+	didID := u.DID.String()
+
+	doc, err := registry.Resolve(didID, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("resolve DID %s: %w", didID, err)
+	}
+
+	return doc.Dereference(u)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vdri_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+)
+
+func TestDereference(t *testing.T) {
+	const didID = "did:example:123456789abcdefghi"
+
+	pk := did.NewPublicKeyFromBytes(didID+"#key-1", "Ed25519VerificationKey2018", didID, []byte("key1"))
+
+	doc, err := did.NewDocBuilder(didID).
+		AddPublicKey(pk, did.Authentication).
+		AddService(&did.Service{ID: didID + "#hub", Type: "IdentityHub", ServiceEndpoint: "https://hub.example.com"}).
+		Build()
+	require.NoError(t, err)
+
+	t.Run("dereference verification method", func(t *testing.T) {
+		res, err := vdriapi.Dereference(&mockvdri.MockVDRIRegistry{ResolveValue: doc}, didID+"#key-1")
+		require.NoError(t, err)
+		require.Equal(t, pk.Value, res.VerificationMethod.PublicKey.Value)
+	})
+
+	t.Run("dereference service endpoint", func(t *testing.T) {
+		res, err := vdriapi.Dereference(&mockvdri.MockVDRIRegistry{ResolveValue: doc},
+			didID+"?service=hub&relativeRef=/profile")
+		require.NoError(t, err)
+		require.Equal(t, "https://hub.example.com/profile", res.ServiceEndpoint)
+	})
+
+	t.Run("version query parameters are passed to resolution", func(t *testing.T) {
+		var resolveOpts vdriapi.ResolveDIDOpts
+
+		registry := &mockvdri.MockVDRIRegistry{
+			ResolveFunc: func(id string, opts ...vdriapi.ResolveOpts) (*did.Doc, error) {
+				require.Equal(t, didID, id)
+
+				for _, opt := range opts {
+					opt(&resolveOpts)
+				}
+
+				return doc, nil
+			},
+		}
+
+		res, err := vdriapi.Dereference(registry, didID+"?versionId=2&versionTime=2020-06-01T10:00:00Z")
+		require.NoError(t, err)
+		require.Equal(t, doc, res.Doc)
+		require.Equal(t, "2", resolveOpts.VersionID)
+		require.Equal(t, time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC).Format(time.RFC3339),
+			resolveOpts.VersionTime)
+	})
+
+	t.Run("invalid version time", func(t *testing.T) {
+		_, err := vdriapi.Dereference(&mockvdri.MockVDRIRegistry{ResolveValue: doc}, didID+"?versionTime=yesterday")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse versionTime query parameter")
+	})
+
+	t.Run("invalid DID URL", func(t *testing.T) {
+		_, err := vdriapi.Dereference(&mockvdri.MockVDRIRegistry{ResolveValue: doc}, "key-1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse DID URL")
+	})
+
+	t.Run("resolve error", func(t *testing.T) {
+		_, err := vdriapi.Dereference(&mockvdri.MockVDRIRegistry{ResolveErr: errors.New("resolve error")},
+			didID+"#key-1")
+		require.EqualError(t, err, "resolve DID "+didID+": resolve error")
+	})
+}
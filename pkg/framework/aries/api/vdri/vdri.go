@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
 )
 
 // ErrNotFound is returned when a DID resolver does not find the DID.
@@ -88,11 +90,13 @@ func WithNoCache(noCache bool) ResolveOpts {
 
 // CreateDIDOpts holds the options for creating DID
 type CreateDIDOpts struct {
-	ServiceType     string
-	KeyType         string
-	ServiceEndpoint string
-	RoutingKeys     []string
-	RequestBuilder  func([]byte) (io.Reader, error)
+	ServiceType      string
+	KeyType          string
+	ServiceEndpoint  string
+	RoutingKeys      []string
+	RequestBuilder   func([]byte) (io.Reader, error)
+	KeyAgreementType kms.KeyType
+	KeyAgreement     *PubKey
 }
 
 // DocOpts is a create DID option
@@ -134,11 +138,28 @@ func WithRequestBuilder(builder func(payload []byte) (io.Reader, error)) DocOpts
 	}
 }
 
+// WithKeyAgreementType allows for setting the KMS key type (kms.X25519ECDHKWType, kms.NISTP256ECDHKWType or
+// kms.NISTP384ECDHKWType) of the key agreement key created by the registry for the DID document.
+func WithKeyAgreementType(keyType kms.KeyType) DocOpts {
+	return func(opts *CreateDIDOpts) {
+		opts.KeyAgreementType = keyType
+	}
+}
+
+// WithKeyAgreement allows for setting the key agreement public key of the DID document to build.
+func WithKeyAgreement(pubKey *PubKey) DocOpts {
+	return func(opts *CreateDIDOpts) {
+		opts.KeyAgreement = pubKey
+	}
+}
+
 // PubKey contains public key type and value
 type PubKey struct {
 	ID    string
 	Value string // base58 encoded
 	Type  string
+	// JWK is the optional JSON Web Key of the public key
+	JWK *jose.JWK
 }
 
 // ModifiedBy key/signature used to update the DID Document
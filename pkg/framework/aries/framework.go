@@ -25,8 +25,11 @@ import (
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/context"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+	"github.com/hyperledger/aries-framework-go/pkg/store/migration"
 	"github.com/hyperledger/aries-framework-go/pkg/store/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/vdri/peer"
@@ -64,6 +67,9 @@ type Aries struct {
 	vdriRegistry           vdriapi.Registry
 	vdri                   []vdriapi.VDRI
 	verifiableStore        verifiable.Store
+	migrations             []migration.Step
+	migrationOpts          []migration.Option
+	migrationReport        *migration.Report
 	transportReturnRoute   string
 	id                     string
 }
@@ -105,12 +111,12 @@ func New(opts ...Option) (*Aries, error) {
 
 func initializeServices(frameworkOpts *Aries) (*Aries, error) {
 	// Order of initializing service is important
-	// Create legacyKMS
-	if e := createLegacyKMS(frameworkOpts); e != nil {
+	if e := createKMS(frameworkOpts); e != nil {
 		return nil, e
 	}
 
-	if e := createKMS(frameworkOpts); e != nil {
+	// Create legacyKMS (must be done after KMS)
+	if e := createLegacyKMS(frameworkOpts); e != nil {
 		return nil, e
 	}
 
@@ -134,6 +140,11 @@ func initializeServices(frameworkOpts *Aries) (*Aries, error) {
 		return nil, err
 	}
 
+	// Migrate the records saved by previous versions (must be done before loading services)
+	if err := migrateStores(frameworkOpts); err != nil {
+		return nil, err
+	}
+
 	// Load services
 	if err := loadServices(frameworkOpts); err != nil {
 		return nil, err
@@ -220,6 +231,17 @@ func WithLegacyKMS(k api.KMSCreator) Option {
 	}
 }
 
+// WithoutLegacyKMS runs the Aries framework without the LegacyKMS store: the LegacyKMS interfaces used by the
+// legacy packers, didexchange and the Signer are implemented by a legacykms.LocalKMSAdapter on top of the framework
+// KMS and Crypto (localkms and tinkcrypto by default). The key pairs of an existing LegacyKMS store are imported into
+// the KMS the first time the framework is started with this option.
+func WithoutLegacyKMS() Option {
+	return func(opts *Aries) error {
+		opts.legacyKMSCreator = createLocalKMSAdapter
+		return nil
+	}
+}
+
 // WithSecretLock injects a SecretLock service to the Aries framework
 func WithSecretLock(s secretlock.Service) Option {
 	return func(opts *Aries) error {
@@ -282,6 +304,38 @@ func WithVerifiableStore(store verifiable.Store) Option {
 	}
 }
 
+// WithMigrations registers the migration steps of the stores of the protocol services, they are run on startup with
+// the migration steps of the framework stores.
+func WithMigrations(steps ...migration.Step) Option {
+	return func(opts *Aries) error {
+		opts.migrations = append(opts.migrations, steps...)
+		return nil
+	}
+}
+
+// WithMigrationDryRun runs the migration steps of the stores on startup without applying their changes, the changes
+// which would be made are reported by MigrationReport. The framework is started on the records as they are, the
+// connection records which aren't tagged yet are looked up by key prefix.
+func WithMigrationDryRun() Option {
+	return func(opts *Aries) error {
+		opts.migrationOpts = append(opts.migrationOpts, migration.WithDryRun())
+		return nil
+	}
+}
+
+// WithMigrationBackup copies the records of the stores to backup stores before migrating them on startup.
+func WithMigrationBackup() Option {
+	return func(opts *Aries) error {
+		opts.migrationOpts = append(opts.migrationOpts, migration.WithBackup())
+		return nil
+	}
+}
+
+// MigrationReport returns the report of the migration of the stores run on startup.
+func (a *Aries) MigrationReport() *migration.Report {
+	return a.migrationReport
+}
+
 // Context provides a handle to the framework context.
 func (a *Aries) Context() (*context.Provider, error) {
 	return context.New(
@@ -358,6 +412,8 @@ func (a *Aries) closeVDRI() error {
 func createLegacyKMS(frameworkOpts *Aries) error {
 	ctx, err := context.New(
 		context.WithStorageProvider(frameworkOpts.storeProvider),
+		context.WithKMS(frameworkOpts.kms),
+		context.WithCrypto(frameworkOpts.crypto),
 	)
 	if err != nil {
 		return fmt.Errorf("create context failed: %w", err)
@@ -371,6 +427,20 @@ func createLegacyKMS(frameworkOpts *Aries) error {
 	return nil
 }
 
+func createLocalKMSAdapter(ctx api.Provider) (api.CloseableKMS, error) {
+	adapter, err := legacykms.NewLocalKMSAdapter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = adapter.ImportLegacyKeysOnce()
+	if err != nil {
+		return nil, err
+	}
+
+	return adapter, nil
+}
+
 func createKMS(frameworkOpts *Aries) error {
 	ctx, err := context.New(
 		context.WithStorageProvider(frameworkOpts.storeProvider),
@@ -388,6 +458,32 @@ func createKMS(frameworkOpts *Aries) error {
 	return nil
 }
 
+func migrateStores(frameworkOpts *Aries) error {
+	ctx, err := context.New(
+		context.WithStorageProvider(frameworkOpts.storeProvider),
+		context.WithTransientStorageProvider(frameworkOpts.transientStoreProvider),
+	)
+	if err != nil {
+		return fmt.Errorf("create context failed: %w", err)
+	}
+
+	migrator := migration.New(ctx, frameworkOpts.migrationOpts...)
+
+	steps := append(connection.Migrations(), verifiable.Migrations()...)
+
+	err = migrator.Register(append(steps, frameworkOpts.migrations...)...)
+	if err != nil {
+		return fmt.Errorf("register migrations failed: %w", err)
+	}
+
+	frameworkOpts.migrationReport, err = migrator.Run()
+	if err != nil {
+		return fmt.Errorf("migrate stores failed: %w", err)
+	}
+
+	return nil
+}
+
 func createVDRI(frameworkOpts *Aries) error {
 	ctx, err := context.New(
 		// TODO add a better way to use either LegacyKMS or KMS in the registry, for now LegacyKMS will be used by
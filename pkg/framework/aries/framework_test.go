@@ -36,6 +36,7 @@ import (
 	mocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/common/service"
 	verifiableStoreMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/store/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
 	mockcrypto "github.com/hyperledger/aries-framework-go/pkg/mock/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/mock/didcomm"
@@ -49,7 +50,12 @@ import (
 	locallock "github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/hkdf"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/passphrase"
+	storageapi "github.com/hyperledger/aries-framework-go/pkg/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/storage/leveldb"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+	"github.com/hyperledger/aries-framework-go/pkg/store/migration"
 	"github.com/hyperledger/aries-framework-go/pkg/vdri/peer"
 )
 
@@ -157,6 +163,21 @@ func TestFramework(t *testing.T) {
 		require.Contains(t, err.Error(), "create new vdri peer failed")
 	})
 
+	t.Run("test error migrate stores", func(t *testing.T) {
+		path, cleanup := generateTempDir(t)
+		defer cleanup()
+		dbPath = path
+
+		transientStoreProvider := storage.NewMockStoreProvider()
+		transientStoreProvider.FailNamespace = connection.Namespace
+
+		_, err := New(
+			WithTransientStoreProvider(transientStoreProvider),
+			WithInboundTransport(&mockInboundTransport{}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "migrate stores failed")
+	})
+
 	t.Run("test vdri - close error", func(t *testing.T) {
 		path, cleanup := generateTempDir(t)
 		defer cleanup()
@@ -402,6 +423,45 @@ func TestFramework(t *testing.T) {
 		require.Contains(t, err.Error(), "error from legacyKMS")
 	})
 
+	t.Run("test new without legacy kms", func(t *testing.T) {
+		path, cleanup := generateTempDir(t)
+		defer cleanup()
+		dbPath = path
+
+		// create a key pair with the default legacy KMS
+		a, err := New(WithInboundTransport(&mockInboundTransport{}))
+		require.NoError(t, err)
+
+		ctx, err := a.Context()
+		require.NoError(t, err)
+
+		_, verKey, err := ctx.LegacyKMS().CreateKeySet()
+		require.NoError(t, err)
+
+		legacySig, err := ctx.Signer().SignMessage([]byte("msg"), verKey)
+		require.NoError(t, err)
+		require.NoError(t, a.Close())
+
+		// the legacy key pair is imported into the KMS
+		a, err = New(WithInboundTransport(&mockInboundTransport{}), WithoutLegacyKMS())
+		require.NoError(t, err)
+
+		ctx, err = a.Context()
+		require.NoError(t, err)
+		require.IsType(t, &legacykms.LocalKMSAdapter{}, ctx.LegacyKMS())
+
+		sig, err := ctx.Signer().SignMessage([]byte("msg"), verKey)
+		require.NoError(t, err)
+		require.Equal(t, legacySig, sig)
+
+		_, verKey, err = ctx.LegacyKMS().CreateKeySet()
+		require.NoError(t, err)
+
+		_, err = ctx.Signer().SignMessage([]byte("msg"), verKey)
+		require.NoError(t, err)
+		require.NoError(t, a.Close())
+	})
+
 	t.Run("test new with explicitly passing noop secret lock svc as an option", func(t *testing.T) {
 		// create noop secret lock service
 		s := &noop.NoLock{}
@@ -416,6 +476,27 @@ func TestFramework(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("test new with passphrase secret lock svc as an option", func(t *testing.T) {
+		storeProvider := storage.NewMockStoreProvider()
+
+		s, err := passphrase.New("passphrase", storeProvider, passphrase.WithKDFParams(1, 64, 1))
+		require.NoError(t, err)
+
+		a, err := New(WithSecretLock(s), WithStoreProvider(storeProvider))
+		require.NoError(t, err)
+		require.NotEmpty(t, a)
+		require.Equal(t, s, a.secretLock)
+
+		ctx, err := a.Context()
+		require.NoError(t, err)
+
+		_, _, err = ctx.KMS().Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		err = a.Close()
+		require.NoError(t, err)
+	})
+
 	t.Run("test new with custom (unprotected master key) secret lock svc and with custom KMS", func(t *testing.T) {
 		masterKeyFilePath := "masterKey_aries.txt"
 		tmpfile, err := ioutil.TempFile("", masterKeyFilePath)
@@ -652,6 +733,86 @@ func TestFramework(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, mockStore, aries.verifiableStore)
 	})
+
+	t.Run("test migrations", func(t *testing.T) {
+		path, cleanup := generateTempDir(t)
+		defer cleanup()
+		dbPath = path
+
+		storeProvider, transientStoreProvider := mem.NewProvider(), mem.NewProvider()
+		protocolStore, err := storeProvider.OpenStore("protocol")
+		require.NoError(t, err)
+		require.NoError(t, protocolStore.Put("key", []byte("v0")))
+
+		// connection record saved without tags
+		connectionStore, err := storeProvider.OpenStore(connection.Namespace)
+		require.NoError(t, err)
+		require.NoError(t, connectionStore.Put("conn_legacy", []byte(`{"ConnectionID":"legacy"}`)))
+
+		step := migration.Step{
+			Store:       "protocol",
+			Version:     1,
+			Description: "update the records",
+			Migrate: func(store storageapi.Store) error {
+				return store.Put("key", []byte("v1"))
+			},
+		}
+
+		aries, err := New(WithStoreProvider(storeProvider), WithTransientStoreProvider(transientStoreProvider),
+			WithMigrations(step), WithMigrationDryRun())
+		require.NoError(t, err)
+		require.True(t, aries.MigrationReport().DryRun)
+		require.Len(t, aries.MigrationReport().Stores, 4)
+
+		value, err := protocolStore.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v0"), value)
+
+		// the connection records are found before they are tagged
+		ctx, err := aries.Context()
+		require.NoError(t, err)
+
+		lookup, err := connection.NewLookup(ctx)
+		require.NoError(t, err)
+
+		records, err := lookup.QueryConnectionRecords()
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+
+		aries, err = New(WithStoreProvider(storeProvider), WithTransientStoreProvider(transientStoreProvider),
+			WithMigrations(step), WithMigrationBackup())
+		require.NoError(t, err)
+		require.False(t, aries.MigrationReport().DryRun)
+		require.Len(t, aries.MigrationReport().Stores, 4)
+
+		value, err = protocolStore.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), value)
+
+		backupStore, err := storeProvider.OpenStore("protocol_backup_v0")
+		require.NoError(t, err)
+
+		value, err = backupStore.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v0"), value)
+
+		// the stores are up to date
+		aries, err = New(WithStoreProvider(storeProvider), WithTransientStoreProvider(transientStoreProvider),
+			WithMigrations(step))
+		require.NoError(t, err)
+		require.Empty(t, aries.MigrationReport().Stores)
+	})
+
+	t.Run("test error register migrations", func(t *testing.T) {
+		path, cleanup := generateTempDir(t)
+		defer cleanup()
+		dbPath = path
+
+		_, err := New(WithMigrations(migration.Step{Store: connection.Namespace, Version: 1,
+			Migrate: func(storageapi.Store) error { return nil }}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "register migrations failed")
+	})
 }
 
 func Test_Packager(t *testing.T) {
@@ -0,0 +1,161 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptoutil
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	archiveKDFAlg = "scrypt"
+
+	// scrypt parameters recommended for interactive logins (2017), see https://godoc.org/golang.org/x/crypto/scrypt
+	scryptN        = 32768
+	scryptR        = 8
+	scryptP        = 1
+	archiveKeySize = 32
+	archiveSaltLen = 16
+)
+
+// Archive is an archive encrypted with AES-GCM with a key derived from a passphrase. Its header (version and kdf) is
+// authenticated as AEAD additional data.
+type Archive struct {
+	ArchiveHeader
+	Nonce      []byte `json:"nonce"`
+	CipherText []byte `json:"ciphertext"`
+}
+
+// ArchiveHeader is the header of an Archive.
+type ArchiveHeader struct {
+	Version int        `json:"version"`
+	KDF     ArchiveKDF `json:"kdf"`
+}
+
+// ArchiveKDF holds the parameters of the derivation of the Archive key from the passphrase.
+type ArchiveKDF struct {
+	Alg  string `json:"alg"`
+	Salt []byte `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+// SealArchive encrypts payload into an Archive of the given version with a key derived from passphrase and returns it
+// marshalled to JSON.
+func SealArchive(payload, passphrase []byte, version int) ([]byte, error) {
+	salt := make([]byte, archiveSaltLen)
+
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	header := ArchiveHeader{
+		Version: version,
+		KDF:     ArchiveKDF{Alg: archiveKDFAlg, Salt: salt, N: scryptN, R: scryptR, P: scryptP},
+	}
+
+	aad, key, err := deriveArchiveKey(&header, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	aesGCM, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aesGCM.NonceSize())
+
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&Archive{
+		ArchiveHeader: header,
+		Nonce:         nonce,
+		CipherText:    aesGCM.Seal(nil, nonce, payload, aad),
+	})
+}
+
+// OpenArchive decrypts the JSON Archive with passphrase and returns its payload, the Archive must have the given
+// version.
+func OpenArchive(archiveBytes, passphrase []byte, version int) ([]byte, error) {
+	archive := &Archive{}
+
+	err := json.Unmarshal(archiveBytes, archive)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	if archive.Version != version {
+		return nil, fmt.Errorf("unsupported archive version %d", archive.Version)
+	}
+
+	if archive.KDF.Alg != archiveKDFAlg {
+		return nil, fmt.Errorf("unsupported archive key derivation '%s'", archive.KDF.Alg)
+	}
+
+	// the cost parameters are those of SealArchive, the parameters of an untrusted archive could exhaust the memory
+	if archive.KDF.N != scryptN || archive.KDF.R != scryptR || archive.KDF.P != scryptP {
+		return nil, fmt.Errorf("unsupported archive key derivation parameters n=%d r=%d p=%d",
+			archive.KDF.N, archive.KDF.R, archive.KDF.P)
+	}
+
+	aad, key, err := deriveArchiveKey(&archive.ArchiveHeader, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	aesGCM, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(archive.Nonce) != aesGCM.NonceSize() {
+		return nil, errors.New("invalid archive nonce")
+	}
+
+	payload, err := aesGCM.Open(nil, archive.Nonce, archive.CipherText, aad)
+	if err != nil {
+		return nil, errors.New("invalid passphrase or corrupted archive")
+	}
+
+	return payload, nil
+}
+
+// deriveArchiveKey returns the AEAD additional data of the archive header and the key derived from passphrase.
+func deriveArchiveKey(header *ArchiveHeader, passphrase []byte) ([]byte, []byte, error) {
+	aad, err := json.Marshal(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := scrypt.Key(passphrase, header.KDF.Salt, header.KDF.N, header.KDF.R, header.KDF.P, archiveKeySize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to derive archive key: %w", err)
+	}
+
+	return aad, key, nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
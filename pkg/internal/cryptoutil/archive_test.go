@@ -0,0 +1,88 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptoutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive(t *testing.T) {
+	passphrase := []byte("passphrase")
+	payload := []byte("payload")
+
+	archive, err := SealArchive(payload, passphrase, 3)
+	require.NoError(t, err)
+
+	t.Run("open archive", func(t *testing.T) {
+		opened, err := OpenArchive(archive, passphrase, 3)
+		require.NoError(t, err)
+		require.Equal(t, payload, opened)
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		_, err := OpenArchive(archive, []byte("wrong passphrase"), 3)
+		require.EqualError(t, err, "invalid passphrase or corrupted archive")
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := OpenArchive(archive, passphrase, 1)
+		require.EqualError(t, err, "unsupported archive version 3")
+	})
+
+	t.Run("tampered archive", func(t *testing.T) {
+		a := &Archive{}
+		require.NoError(t, json.Unmarshal(archive, a))
+
+		// the header is authenticated
+		a.KDF.Salt[0] ^= 0xff
+		tampered, err := json.Marshal(a)
+		require.NoError(t, err)
+
+		_, err = OpenArchive(tampered, passphrase, 3)
+		require.EqualError(t, err, "invalid passphrase or corrupted archive")
+
+		require.NoError(t, json.Unmarshal(archive, a))
+		a.KDF.Alg = "pbkdf2"
+		tampered, err = json.Marshal(a)
+		require.NoError(t, err)
+
+		_, err = OpenArchive(tampered, passphrase, 3)
+		require.EqualError(t, err, "unsupported archive key derivation 'pbkdf2'")
+
+		require.NoError(t, json.Unmarshal(archive, a))
+		a.KDF.N = 3
+		tampered, err = json.Marshal(a)
+		require.NoError(t, err)
+
+		_, err = OpenArchive(tampered, passphrase, 3)
+		require.EqualError(t, err, "unsupported archive key derivation parameters n=3 r=8 p=1")
+
+		// costly parameters are rejected before the key derivation
+		require.NoError(t, json.Unmarshal(archive, a))
+		a.KDF.N, a.KDF.R, a.KDF.P = 1<<30, 1<<20, 1<<20
+		tampered, err = json.Marshal(a)
+		require.NoError(t, err)
+
+		_, err = OpenArchive(tampered, passphrase, 3)
+		require.EqualError(t, err, "unsupported archive key derivation parameters n=1073741824 r=1048576 p=1048576")
+
+		require.NoError(t, json.Unmarshal(archive, a))
+		a.Nonce = []byte("nonce")
+		tampered, err = json.Marshal(a)
+		require.NoError(t, err)
+
+		_, err = OpenArchive(tampered, passphrase, 3)
+		require.EqualError(t, err, "invalid archive nonce")
+
+		_, err = OpenArchive([]byte("{"), passphrase, 3)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid archive")
+	})
+}
@@ -7,6 +7,8 @@
 package kms
 
 import (
+	"time"
+
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
@@ -59,6 +61,44 @@ type KeyManager interface {
 	ImportPrivateKey(privKey interface{}, kt KeyType, opts ...PrivateKeyOpts) (string, interface{}, error)
 }
 
+// KeyInventory is implemented by key managers able to enumerate, describe and delete the keys they manage.
+type KeyInventory interface {
+	// List returns the metadata of all keys managed by the KMS (including disabled keys)
+	// Returns:
+	//  - list of key metadata sorted by creation time
+	//  - error if failure
+	List() ([]*KeyMetadata, error)
+	// Describe returns the metadata of the key referenced by keyID
+	// Returns:
+	//  - key metadata
+	//  - error if the key is not found or failure
+	Describe(keyID string) (*KeyMetadata, error)
+	// Delete removes the key referenced by keyID and its metadata from the KMS
+	// Returns:
+	//  - error if the key is not found or failure
+	Delete(keyID string) error
+}
+
+// KeyStatus is the status of a key managed by the KMS
+type KeyStatus string
+
+const (
+	// KeyEnabled is the status of keys usable for crypto operations
+	KeyEnabled = KeyStatus("enabled")
+	// KeyDisabled is the status of keys rotated out (or otherwise disabled) that must not be used anymore
+	KeyDisabled = KeyStatus("disabled")
+)
+
+// KeyMetadata holds the information about a key managed by the KMS
+type KeyMetadata struct {
+	KeyID   string    `json:"keyID"`
+	KeyType KeyType   `json:"keyType,omitempty"`
+	Created time.Time `json:"created"`
+	Status  KeyStatus `json:"status"`
+	// RotatedTo is set to the new keyID when the key is rotated
+	RotatedTo string `json:"rotatedTo,omitempty"`
+}
+
 // Provider for KeyManager builder/constructor
 type Provider interface {
 	StorageProvider() storage.Provider
@@ -103,6 +143,12 @@ const (
 	HMACSHA256Tag256 = "HMACSHA256Tag256"
 	// ECDHES256AES256GCM key type value
 	ECDHES256AES256GCM = "ECDHES256AES256GCM"
+	// NISTP256ECDHKW key type value
+	NISTP256ECDHKW = "NISTP256ECDHKW"
+	// NISTP384ECDHKW key type value
+	NISTP384ECDHKW = "NISTP384ECDHKW"
+	// X25519ECDHKW key type value
+	X25519ECDHKW = "X25519ECDHKW"
 )
 
 // KeyType represents a key type supported by the KMS
@@ -143,4 +189,10 @@ const (
 	HMACSHA256Tag256Type = KeyType(HMACSHA256Tag256)
 	// ECDHES256AES256GCMType key type value
 	ECDHES256AES256GCMType = KeyType(ECDHES256AES256GCM)
+	// NISTP256ECDHKWType key type value, a P-256 key agreement key used for ECDH-ES/ECDH-1PU key wrapping
+	NISTP256ECDHKWType = KeyType(NISTP256ECDHKW)
+	// NISTP384ECDHKWType key type value, a P-384 key agreement key used for ECDH-ES/ECDH-1PU key wrapping
+	NISTP384ECDHKWType = KeyType(NISTP384ECDHKW)
+	// X25519ECDHKWType key type value, an X25519 key agreement key used for ECDH-ES/ECDH-1PU key wrapping
+	X25519ECDHKWType = KeyType(X25519ECDHKW)
 )
@@ -0,0 +1,154 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+)
+
+// PubKeyBytesToJWK converts pubKey, as returned by KeyManager.ExportPubKeyBytes() for a key of type kt, into a JWK.
+// Supported key types are:
+//  - key agreement keys: NISTP256ECDHKWType, NISTP384ECDHKWType and X25519ECDHKWType (pubKey is a marshaled
+//    crypto.PublicKey)
+//  - signing keys: ED25519Type, ECDSA P-256/P-384/P-521 DER and IEEE P1363 types and ECDSASecp256k1TypeIEEEP1363
+func PubKeyBytesToJWK(pubKey []byte, kt KeyType) (*jose.JWK, error) {
+	switch kt {
+	case NISTP256ECDHKWType, NISTP384ECDHKWType, X25519ECDHKWType:
+		return ecdhPubKeyToJWK(pubKey, kt)
+	case ED25519Type:
+		if len(pubKey) != ed25519.PublicKeySize {
+			return nil, errors.New("pubKeyBytesToJWK: invalid Ed25519 public key")
+		}
+
+		return jose.JWKFromPublicKey(ed25519.PublicKey(pubKey))
+	case ECDSAP256TypeDER, ECDSAP384TypeDER, ECDSAP521TypeDER:
+		key, err := x509.ParsePKIXPublicKey(pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("pubKeyBytesToJWK: %w", err)
+		}
+
+		return jose.JWKFromPublicKey(key)
+	case ECDSAP256TypeIEEEP1363, ECDSAP384TypeIEEEP1363, ECDSAP521TypeIEEEP1363:
+		c := map[KeyType]elliptic.Curve{
+			ECDSAP256TypeIEEEP1363: elliptic.P256(),
+			ECDSAP384TypeIEEEP1363: elliptic.P384(),
+			ECDSAP521TypeIEEEP1363: elliptic.P521(),
+		}[kt]
+
+		x, y := elliptic.Unmarshal(c, pubKey)
+		if x == nil {
+			return nil, errors.New("pubKeyBytesToJWK: invalid EC public key")
+		}
+
+		return jose.JWKFromPublicKey(&ecdsa.PublicKey{Curve: c, X: x, Y: y})
+	case ECDSASecp256k1TypeIEEEP1363:
+		key, err := btcec.ParsePubKey(pubKey, btcec.S256())
+		if err != nil {
+			return nil, fmt.Errorf("pubKeyBytesToJWK: invalid secp256k1 public key: %w", err)
+		}
+
+		return jose.JWKFromPublicKey(key.ToECDSA())
+	default:
+		return nil, fmt.Errorf("pubKeyBytesToJWK: unsupported key type '%s'", kt)
+	}
+}
+
+// ExportPubKeyJWK exports the public key of the key keyID managed by km as a JWK with its kid set to keyID.
+// kt is the type of the key, it can be left empty if km is a KeyInventory: the key type is then read from the key
+// metadata.
+func ExportPubKeyJWK(km KeyManager, keyID string, kt KeyType) (*jose.JWK, error) {
+	if kt == "" {
+		inventory, ok := km.(KeyInventory)
+		if !ok {
+			return nil, errors.New("exportPubKeyJWK: key type is mandatory")
+		}
+
+		md, err := inventory.Describe(keyID)
+		if err != nil {
+			return nil, fmt.Errorf("exportPubKeyJWK: %w", err)
+		}
+
+		if md.KeyType == "" {
+			return nil, fmt.Errorf("exportPubKeyJWK: type of key '%s' is unknown", keyID)
+		}
+
+		kt = md.KeyType
+	}
+
+	pubKey, err := km.ExportPubKeyBytes(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("exportPubKeyJWK: %w", err)
+	}
+
+	jwk, err := PubKeyBytesToJWK(pubKey, kt)
+	if err != nil {
+		return nil, err
+	}
+
+	jwk.KeyID = keyID
+
+	return jwk, nil
+}
+
+// JWKKeyType returns the signing key type matching the curve of jwk: ED25519Type for Ed25519 keys and the IEEE P1363
+// ECDSA key types (used by JWS) for P-256, P-384 and secp256k1 keys.
+func JWKKeyType(jwk *jose.JWK) (KeyType, error) {
+	switch jwk.Crv {
+	case "Ed25519":
+		return ED25519Type, nil
+	case "P-256":
+		return ECDSAP256TypeIEEEP1363, nil
+	case "P-384":
+		return ECDSAP384TypeIEEEP1363, nil
+	case "secp256k1":
+		return ECDSASecp256k1TypeIEEEP1363, nil
+	default:
+		return "", fmt.Errorf("unsupported curve '%s'", jwk.Crv)
+	}
+}
+
+func ecdhPubKeyToJWK(pubKey []byte, kt KeyType) (*jose.JWK, error) {
+	key := &crypto.PublicKey{}
+
+	err := json.Unmarshal(pubKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("pubKeyBytesToJWK: failed to unmarshal ECDH public key: %w", err)
+	}
+
+	if kt == X25519ECDHKWType {
+		return jose.JWKFromX25519Key(key.X)
+	}
+
+	c := elliptic.P256()
+	if kt == NISTP384ECDHKWType {
+		c = elliptic.P384()
+	}
+
+	ecKey := &ecdsa.PublicKey{
+		Curve: c,
+		X:     new(big.Int).SetBytes(key.X),
+		Y:     new(big.Int).SetBytes(key.Y),
+	}
+
+	if !c.IsOnCurve(ecKey.X, ecKey.Y) {
+		return nil, errors.New("pubKeyBytesToJWK: invalid ECDH public key")
+	}
+
+	return jose.JWKFromPublicKey(ecKey)
+}
@@ -0,0 +1,267 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	gojose "github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+)
+
+func TestPubKeyBytesToJWK(t *testing.T) {
+	t.Run("ECDH key types", func(t *testing.T) {
+		for kt, c := range map[KeyType]elliptic.Curve{
+			NISTP256ECDHKWType: elliptic.P256(),
+			NISTP384ECDHKWType: elliptic.P384(),
+		} {
+			privKey, err := ecdsa.GenerateKey(c, rand.Reader)
+			require.NoError(t, err)
+
+			pubKey, err := json.Marshal(&crypto.PublicKey{
+				X:     privKey.X.Bytes(),
+				Y:     privKey.Y.Bytes(),
+				Curve: c.Params().Name,
+				Type:  "EC",
+			})
+			require.NoError(t, err)
+
+			jwk, err := PubKeyBytesToJWK(pubKey, kt)
+			require.NoError(t, err)
+			require.Equal(t, "EC", jwk.Kty)
+			require.Equal(t, c.Params().Name, jwk.Crv)
+			require.Equal(t, &privKey.PublicKey, jwk.Key)
+
+			invalidKey, err := json.Marshal(&crypto.PublicKey{X: privKey.X.Bytes(), Y: privKey.X.Bytes()})
+			require.NoError(t, err)
+
+			_, err = PubKeyBytesToJWK(invalidKey, kt)
+			require.EqualError(t, err, "pubKeyBytesToJWK: invalid ECDH public key")
+		}
+
+		x25519Key := make([]byte, 32)
+		_, err := rand.Read(x25519Key)
+		require.NoError(t, err)
+
+		pubKey, err := json.Marshal(&crypto.PublicKey{X: x25519Key, Curve: "X25519", Type: "OKP"})
+		require.NoError(t, err)
+
+		jwk, err := PubKeyBytesToJWK(pubKey, X25519ECDHKWType)
+		require.NoError(t, err)
+		require.Equal(t, "OKP", jwk.Kty)
+		require.Equal(t, "X25519", jwk.Crv)
+
+		pkBytes, err := jwk.PublicKeyBytes()
+		require.NoError(t, err)
+		require.Equal(t, x25519Key, pkBytes)
+
+		_, err = PubKeyBytesToJWK([]byte("{"), X25519ECDHKWType)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "pubKeyBytesToJWK: failed to unmarshal ECDH public key")
+	})
+
+	t.Run("signing key types", func(t *testing.T) {
+		edPubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err := PubKeyBytesToJWK(edPubKey, ED25519Type)
+		require.NoError(t, err)
+		require.Equal(t, "OKP", jwk.Kty)
+		require.Equal(t, "Ed25519", jwk.Crv)
+
+		_, err = PubKeyBytesToJWK([]byte("key"), ED25519Type)
+		require.EqualError(t, err, "pubKeyBytesToJWK: invalid Ed25519 public key")
+
+		ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err = PubKeyBytesToJWK(elliptic.Marshal(elliptic.P384(), ecKey.X, ecKey.Y), ECDSAP384TypeIEEEP1363)
+		require.NoError(t, err)
+		require.Equal(t, &ecKey.PublicKey, jwk.Key)
+
+		_, err = PubKeyBytesToJWK([]byte("key"), ECDSAP384TypeIEEEP1363)
+		require.EqualError(t, err, "pubKeyBytesToJWK: invalid EC public key")
+
+		derKey, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+		require.NoError(t, err)
+
+		jwk, err = PubKeyBytesToJWK(derKey, ECDSAP384TypeDER)
+		require.NoError(t, err)
+		require.Equal(t, "P-384", jwk.Crv)
+
+		_, err = PubKeyBytesToJWK([]byte("key"), ECDSAP384TypeDER)
+		require.Error(t, err)
+
+		secp256k1Key, err := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err = PubKeyBytesToJWK((*btcec.PublicKey)(&secp256k1Key.PublicKey).SerializeCompressed(),
+			ECDSASecp256k1TypeIEEEP1363)
+		require.NoError(t, err)
+		require.Equal(t, "secp256k1", jwk.Crv)
+		require.Equal(t, secp256k1Key.X, jwk.Key.(*ecdsa.PublicKey).X)
+
+		_, err = PubKeyBytesToJWK([]byte("key"), ECDSASecp256k1TypeIEEEP1363)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "pubKeyBytesToJWK: invalid secp256k1 public key")
+	})
+
+	t.Run("unsupported key type", func(t *testing.T) {
+		_, err := PubKeyBytesToJWK([]byte("key"), AES256GCMType)
+		require.EqualError(t, err, "pubKeyBytesToJWK: unsupported key type 'AES256GCM'")
+	})
+}
+
+// keyManager is a KeyManager and KeyInventory storing the imported keys in memory.
+type keyManager struct {
+	KeyManager
+	keys    map[string]interface{}
+	keyType map[string]KeyType
+}
+
+func newKeyManager() *keyManager {
+	return &keyManager{keys: map[string]interface{}{}, keyType: map[string]KeyType{}}
+}
+
+func (k *keyManager) ImportPrivateKey(privKey interface{}, kt KeyType,
+	opts ...PrivateKeyOpts) (string, interface{}, error) {
+	pksOpts := NewOpt()
+
+	for _, opt := range opts {
+		opt(pksOpts)
+	}
+
+	keyID := pksOpts.KsID()
+	if keyID == "" {
+		keyID = "generated"
+	}
+
+	k.keys[keyID] = privKey
+	k.keyType[keyID] = kt
+
+	return keyID, privKey, nil
+}
+
+func (k *keyManager) ExportPubKeyBytes(keyID string) ([]byte, error) {
+	switch key := k.keys[keyID].(type) {
+	case ed25519.PrivateKey:
+		return key.Public().(ed25519.PublicKey), nil
+	case *ecdsa.PrivateKey:
+		return elliptic.Marshal(key.Curve, key.X, key.Y), nil
+	default:
+		return nil, errors.New("key not found")
+	}
+}
+
+func (k *keyManager) Describe(keyID string) (*KeyMetadata, error) {
+	kt, ok := k.keyType[keyID]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+
+	return &KeyMetadata{KeyID: keyID, KeyType: kt}, nil
+}
+
+func (k *keyManager) List() ([]*KeyMetadata, error) {
+	return nil, nil
+}
+
+func (k *keyManager) Delete(string) error {
+	return nil
+}
+
+func TestJWKKeyType(t *testing.T) {
+	t.Run("import and export keys", func(t *testing.T) {
+		_, edPrivKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		secp256k1Key, err := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+		require.NoError(t, err)
+
+		for _, tc := range []struct {
+			key interface{}
+			kt  KeyType
+		}{
+			{key: edPrivKey, kt: ED25519Type},
+			{key: p256Key, kt: ECDSAP256TypeIEEEP1363},
+			{key: p384Key, kt: ECDSAP384TypeIEEEP1363},
+			{key: secp256k1Key, kt: ECDSASecp256k1TypeIEEEP1363},
+		} {
+			jwkBytes, err := (&jose.JWK{JSONWebKey: gojose.JSONWebKey{Key: tc.key}}).MarshalJSON()
+			require.NoError(t, err)
+
+			jwk := &jose.JWK{}
+			require.NoError(t, jwk.UnmarshalJSON(jwkBytes))
+
+			kt, err := JWKKeyType(jwk)
+			require.NoError(t, err)
+			require.Equal(t, tc.kt, kt)
+
+			km := newKeyManager()
+
+			keyID, _, err := km.ImportPrivateKey(jwk.Key, kt, WithKeyID("kid"))
+			require.NoError(t, err)
+
+			pubJWK, err := ExportPubKeyJWK(km, keyID, "")
+			require.NoError(t, err)
+			require.Equal(t, keyID, pubJWK.KeyID)
+			require.Equal(t, jwk.Crv, pubJWK.Crv)
+			require.True(t, pubJWK.IsPublic())
+		}
+	})
+
+	t.Run("unsupported curve", func(t *testing.T) {
+		p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+		require.NoError(t, err)
+
+		_, err = JWKKeyType(&jose.JWK{JSONWebKey: gojose.JSONWebKey{Key: p521Key}, Crv: "P-521"})
+		require.EqualError(t, err, "unsupported curve 'P-521'")
+	})
+}
+
+func TestExportPubKeyJWK(t *testing.T) {
+	km := newKeyManager()
+
+	_, err := ExportPubKeyJWK(km, "unknown", "")
+	require.EqualError(t, err, "exportPubKeyJWK: key not found")
+
+	_, err = ExportPubKeyJWK(km, "unknown", ED25519Type)
+	require.EqualError(t, err, "exportPubKeyJWK: key not found")
+
+	km.keyType["untyped"] = ""
+
+	_, err = ExportPubKeyJWK(km, "untyped", "")
+	require.EqualError(t, err, "exportPubKeyJWK: type of key 'untyped' is unknown")
+
+	_, err = ExportPubKeyJWK(km.KeyManager, "key", "")
+	require.EqualError(t, err, "exportPubKeyJWK: key type is mandatory")
+
+	_, edPrivKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	km.keys["key"] = edPrivKey
+
+	_, err = ExportPubKeyJWK(km, "key", ECDSAP256TypeIEEEP1363)
+	require.EqualError(t, err, "pubKeyBytesToJWK: invalid EC public key")
+}
@@ -30,12 +30,17 @@ import (
 //   for encryption/decryption, so clients do not need to see
 //   the secrets themselves.
 type CryptoBox struct {
-	km *BaseKMS
+	km keyPairGetter
+}
+
+// keyPairGetter reads the key pairs of a LegacyKMS, it is implemented by BaseKMS and LocalKMSAdapter.
+type keyPairGetter interface {
+	getKeyPairSet(verKey string) (*cryptoutil.MessagingKeys, error)
 }
 
 // NewCryptoBox creates a CryptoBox which provides crypto box encryption using the given LegacyKMS's keypairs
 func NewCryptoBox(w KeyManager) (*CryptoBox, error) {
-	wa, ok := w.(*BaseKMS)
+	wa, ok := w.(keyPairGetter)
 	if !ok {
 		return nil, fmt.Errorf("cannot use parameter as LegacyKMS")
 	}
@@ -0,0 +1,379 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package legacykms
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	ed25519pb "github.com/google/tink/go/proto/ed25519_go_proto"
+	chacha "golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// AdapterStoreNamespace is the namespace of the store mapping the legacy (base58 encoded) public keys to the KMS
+	// key IDs of the LocalKMSAdapter.
+	AdapterStoreNamespace = "legacykeyindex"
+
+	// legacyKeysImportedKey marks the legacy KMS keystore as imported, it can't collide with a base58 public key.
+	legacyKeysImportedKey = "_legacykeysimported"
+)
+
+var logger = log.New("aries-framework/kms/legacykms")
+
+// adapterProvider contains the dependencies of the LocalKMSAdapter and is typically created by using aries.Context().
+type adapterProvider interface {
+	KMS() kms.KeyManager
+	Crypto() crypto.Crypto
+	StorageProvider() storage.Provider
+}
+
+// LocalKMSAdapter implements the legacy KMS interfaces on top of a kms.KeyManager creating Tink keysets (localkms) and
+// a crypto.Crypto (tinkcrypto), so that an agent keeps all its keys in a single KMS store encrypted by the secret
+// lock. Signature key pairs are Ed25519 keys of the KMS, the legacy X25519 encryption key pairs are converted from them
+// on demand. The base58 encoded signature and encryption public keys used by the legacy interfaces are mapped to the
+// KMS key IDs in the AdapterStoreNamespace store.
+//
+// The legacy packers need the private keys in memory to derive their encryption keys: the adapter reads them from the
+// KMS keysets, they are never stored in clear.
+type LocalKMSAdapter struct {
+	km    kms.KeyManager
+	cr    crypto.Crypto
+	index storage.Store
+	// legacyStoreProvider is the store provider of the legacy keystore to import
+	legacyStoreProvider storage.Provider
+}
+
+// NewLocalKMSAdapter returns a new LocalKMSAdapter using the KMS, Crypto and storage provider of ctx.
+func NewLocalKMSAdapter(ctx adapterProvider) (*LocalKMSAdapter, error) {
+	if ctx.KMS() == nil || ctx.Crypto() == nil {
+		return nil, errors.New("legacy KMS adapter requires a KMS and a Crypto")
+	}
+
+	index, err := ctx.StorageProvider().OpenStore(AdapterStoreNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to OpenStore for '%s', cause: %w", AdapterStoreNamespace, err)
+	}
+
+	return &LocalKMSAdapter{
+		km:                  ctx.KMS(),
+		cr:                  ctx.Crypto(),
+		index:               index,
+		legacyStoreProvider: ctx.StorageProvider(),
+	}, nil
+}
+
+// CreateKeySet creates a new Ed25519 key in the KMS.
+// returns:
+// 		string: base58 encoded X25519 public key converted from the Ed25519 public key
+// 		string: base58 encoded Ed25519 public key
+//		error: in case of errors
+func (a *LocalKMSAdapter) CreateKeySet() (string, string, error) {
+	keyID, _, err := a.km.Create(kms.ED25519Type)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create key: %w", err)
+	}
+
+	sigPub, err := a.km.ExportPubKeyBytes(keyID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to export public key: %w", err)
+	}
+
+	encPub, err := a.indexKey(keyID, sigPub)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base58.Encode(encPub), base58.Encode(sigPub), nil
+}
+
+// ConvertToEncryptionKey returns the X25519 public key converted from the Ed25519 public key verKey of the KMS.
+func (a *LocalKMSAdapter) ConvertToEncryptionKey(verKey []byte) ([]byte, error) {
+	encPub, err := cryptoutil.PublicEd25519toCurve25519(verKey)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = a.keyID(base58.Encode(verKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return encPub, nil
+}
+
+// GetEncryptionKey will return the public encryption key corresponding to the public verKey argument.
+func (a *LocalKMSAdapter) GetEncryptionKey(verKey []byte) ([]byte, error) {
+	return a.ConvertToEncryptionKey(verKey)
+}
+
+// SignMessage signs message with the Crypto using the KMS key of the base58 encoded public key fromVerKey.
+func (a *LocalKMSAdapter) SignMessage(message []byte, fromVerKey string) ([]byte, error) {
+	keyID, err := a.keyID(fromVerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+
+	kh, err := a.km.Get(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+
+	return a.cr.Sign(message, kh)
+}
+
+// DeriveKEK will derive an ephemeral symmetric key (kek) using the private key of the X25519 public key fromPubKey
+// and derived with toPubKey.
+func (a *LocalKMSAdapter) DeriveKEK(alg, apu, fromPubKey, toPubKey []byte) ([]byte, error) {
+	if fromPubKey == nil || toPubKey == nil {
+		return nil, cryptoutil.ErrInvalidKey
+	}
+
+	kpc, err := a.getKeyPairSet(base58.Encode(fromPubKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed from getKeyPairSet: %w", err)
+	}
+
+	fromPrivKey := new([chacha.KeySize]byte)
+	copy(fromPrivKey[:], kpc.EncKeyPair.Priv)
+
+	toKey := new([chacha.KeySize]byte)
+	copy(toKey[:], toPubKey)
+
+	return cryptoutil.Derive25519KEK(alg, apu, fromPrivKey, toKey)
+}
+
+// FindVerKey returns the index of the first key of candidateKeys found in the KMS.
+func (a *LocalKMSAdapter) FindVerKey(candidateKeys []string) (int, error) {
+	for i, key := range candidateKeys {
+		_, err := a.keyID(key)
+		if err != nil {
+			if errors.Is(err, cryptoutil.ErrKeyNotFound) {
+				continue
+			}
+
+			return -1, fmt.Errorf("failed to find key: %w", err)
+		}
+
+		return i, nil
+	}
+
+	return -1, cryptoutil.ErrKeyNotFound
+}
+
+// Close the adapter.
+func (a *LocalKMSAdapter) Close() error {
+	return nil
+}
+
+// ImportLegacyKeys imports the key pairs of the legacy KMS keystore (KeyStoreNamespace) into the KMS as Ed25519 keys.
+// Keys already imported are skipped, the legacy keystore is not modified.
+// Returns:
+//  - the number of imported key pairs
+//  - error if failed to read the legacy keystore or to import a key
+func (a *LocalKMSAdapter) ImportLegacyKeys() (int, error) {
+	legacyStore, err := a.legacyStoreProvider.OpenStore(KeyStoreNamespace)
+	if err != nil {
+		return 0, fmt.Errorf("import legacy keys: failed to OpenStore for '%s', cause: %w", KeyStoreNamespace, err)
+	}
+
+	itr := legacyStore.Iterator("", storage.EndKeySuffix)
+	defer itr.Release()
+
+	imported := 0
+
+	for itr.Next() {
+		keys := &cryptoutil.MessagingKeys{}
+
+		err = json.Unmarshal(itr.Value(), keys)
+		if err != nil {
+			return imported, fmt.Errorf("import legacy keys: failed to unmarshal key '%s': %w", itr.Key(), err)
+		}
+
+		if keys.SigKeyPair == nil || len(keys.SigKeyPair.Priv) != ed25519.PrivateKeySize {
+			logger.Warnf("import legacy keys: skipping key '%s' without Ed25519 signature key pair", itr.Key())
+
+			continue
+		}
+
+		ok, err := a.importKeyPair(keys.SigKeyPair)
+		if err != nil {
+			return imported, fmt.Errorf("import legacy keys: key '%s': %w", itr.Key(), err)
+		}
+
+		if ok {
+			imported++
+		}
+	}
+
+	if itr.Error() != nil {
+		return imported, fmt.Errorf("import legacy keys: %w", itr.Error())
+	}
+
+	return imported, nil
+}
+
+// ImportLegacyKeysOnce calls ImportLegacyKeys the first time it is called with the index store, following calls do
+// nothing and return 0.
+func (a *LocalKMSAdapter) ImportLegacyKeysOnce() (int, error) {
+	_, err := a.index.Get(legacyKeysImportedKey)
+	if err == nil {
+		return 0, nil
+	}
+
+	if !errors.Is(err, storage.ErrDataNotFound) {
+		return 0, fmt.Errorf("import legacy keys: %w", err)
+	}
+
+	imported, err := a.ImportLegacyKeys()
+	if err != nil {
+		return imported, err
+	}
+
+	err = a.index.Put(legacyKeysImportedKey, []byte("true"))
+	if err != nil {
+		return imported, fmt.Errorf("import legacy keys: %w", err)
+	}
+
+	logger.Infof("imported %d legacy KMS key pairs into the KMS", imported)
+
+	return imported, nil
+}
+
+// importKeyPair imports sigKP into the KMS unless its public key is already indexed.
+func (a *LocalKMSAdapter) importKeyPair(sigKP *cryptoutil.SigKeyPair) (bool, error) {
+	_, err := a.keyID(base58.Encode(sigKP.Pub))
+	if err == nil {
+		return false, nil
+	}
+
+	if !errors.Is(err, cryptoutil.ErrKeyNotFound) {
+		return false, err
+	}
+
+	keyID, _, err := a.km.ImportPrivateKey(ed25519.PrivateKey(sigKP.Priv), kms.ED25519Type)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = a.indexKey(keyID, sigKP.Pub)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// indexKey maps the base58 encoded Ed25519 public key sigPub and its X25519 public key to keyID. It returns the X25519
+// public key.
+func (a *LocalKMSAdapter) indexKey(keyID string, sigPub []byte) ([]byte, error) {
+	encPub, err := cryptoutil.PublicEd25519toCurve25519(sigPub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert public key: %w", err)
+	}
+
+	for _, pub := range [][]byte{sigPub, encPub} {
+		err = a.index.Put(base58.Encode(pub), []byte(keyID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to index key: %w", err)
+		}
+	}
+
+	return encPub, nil
+}
+
+// keyID returns the KMS key ID of the base58 encoded (signature or encryption) public key pubKey.
+func (a *LocalKMSAdapter) keyID(pubKey string) (string, error) {
+	keyID, err := a.index.Get(pubKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return "", cryptoutil.ErrKeyNotFound
+		}
+
+		return "", err
+	}
+
+	return string(keyID), nil
+}
+
+// getKeyPairSet reads the Ed25519 private key of the base58 encoded public key pubKey from the KMS and returns it with
+// its converted X25519 key pair. It is used by DeriveKEK and CryptoBox.
+func (a *LocalKMSAdapter) getKeyPairSet(pubKey string) (*cryptoutil.MessagingKeys, error) {
+	keyID, err := a.keyID(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	kh, err := a.km.Get(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	sigPriv, err := ed25519PrivateKey(kh)
+	if err != nil {
+		return nil, fmt.Errorf("key '%s': %w", keyID, err)
+	}
+
+	sigPub := sigPriv.Public().(ed25519.PublicKey)
+
+	encKP, err := createEncKeyPair(&cryptoutil.SigKeyPair{KeyPair: cryptoutil.KeyPair{Pub: sigPub, Priv: sigPriv}})
+	if err != nil {
+		return nil, err
+	}
+
+	return &cryptoutil.MessagingKeys{
+		EncKeyPair: encKP,
+		SigKeyPair: &cryptoutil.SigKeyPair{
+			KeyPair: cryptoutil.KeyPair{Pub: sigPub, Priv: sigPriv},
+			Alg:     cryptoutil.EdDSA,
+		},
+	}, nil
+}
+
+// ed25519PrivateKey reads the private key of the primary key of the Tink keyset handle kh.
+func ed25519PrivateKey(kh interface{}) (ed25519.PrivateKey, error) {
+	handle, ok := kh.(*keyset.Handle)
+	if !ok {
+		return nil, errors.New("key handle is not a Tink keyset handle")
+	}
+
+	mem := &keyset.MemReaderWriter{}
+
+	err := insecurecleartextkeyset.Write(handle, mem)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range mem.Keyset.Key {
+		if key.KeyId != mem.Keyset.PrimaryKeyId || key.KeyData == nil {
+			continue
+		}
+
+		privKey := &ed25519pb.Ed25519PrivateKey{}
+
+		err = proto.Unmarshal(key.KeyData.Value, privKey)
+		if err != nil || len(privKey.KeyValue) != ed25519.SeedSize {
+			return nil, errors.New("key is not an Ed25519 private key")
+		}
+
+		return ed25519.NewKeyFromSeed(privKey.KeyValue), nil
+	}
+
+	return nil, errors.New("keyset has no primary key")
+}
@@ -0,0 +1,269 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package legacykms
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+type adapterTestProvider struct {
+	km            kms.KeyManager
+	cr            crypto.Crypto
+	storeProvider storage.Provider
+}
+
+func (p *adapterTestProvider) KMS() kms.KeyManager {
+	return p.km
+}
+
+func (p *adapterTestProvider) Crypto() crypto.Crypto {
+	return p.cr
+}
+
+func (p *adapterTestProvider) StorageProvider() storage.Provider {
+	return p.storeProvider
+}
+
+func newAdapterTestProvider(t *testing.T, storeProvider storage.Provider) *adapterTestProvider {
+	t.Helper()
+
+	km, err := localkms.New("local-lock://test/master/key/", mockkms.NewProviderForKMS(storeProvider, &noop.NoLock{}))
+	require.NoError(t, err)
+
+	cr, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	return &adapterTestProvider{km: km, cr: cr, storeProvider: storeProvider}
+}
+
+func newAdapter(t *testing.T) *LocalKMSAdapter {
+	t.Helper()
+
+	a, err := NewLocalKMSAdapter(newAdapterTestProvider(t, mem.NewProvider()))
+	require.NoError(t, err)
+
+	return a
+}
+
+func TestNewLocalKMSAdapter(t *testing.T) {
+	t.Run("missing KMS", func(t *testing.T) {
+		_, err := NewLocalKMSAdapter(&adapterTestProvider{storeProvider: mem.NewProvider()})
+		require.EqualError(t, err, "legacy KMS adapter requires a KMS and a Crypto")
+	})
+
+	t.Run("open store error", func(t *testing.T) {
+		p := newAdapterTestProvider(t, mem.NewProvider())
+		p.storeProvider = &mockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("open error")}
+
+		_, err := NewLocalKMSAdapter(p)
+		require.EqualError(t, err, "failed to OpenStore for 'legacykeyindex', cause: open error")
+	})
+}
+
+func TestLocalKMSAdapter_CreateKeySet(t *testing.T) {
+	var _ KMS = (*LocalKMSAdapter)(nil)
+
+	a := newAdapter(t)
+
+	encKey, verKey, err := a.CreateKeySet()
+	require.NoError(t, err)
+
+	encPub, err := a.ConvertToEncryptionKey(base58.Decode(verKey))
+	require.NoError(t, err)
+	require.Equal(t, encKey, base58.Encode(encPub))
+
+	encPub, err = a.GetEncryptionKey(base58.Decode(verKey))
+	require.NoError(t, err)
+	require.Equal(t, encKey, base58.Encode(encPub))
+
+	i, err := a.FindVerKey([]string{"unknown", verKey})
+	require.NoError(t, err)
+	require.Equal(t, 1, i)
+
+	i, err = a.FindVerKey([]string{"unknown", encKey})
+	require.NoError(t, err)
+	require.Equal(t, 1, i)
+
+	_, err = a.FindVerKey([]string{"unknown"})
+	require.EqualError(t, err, cryptoutil.ErrKeyNotFound.Error())
+
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, err = a.ConvertToEncryptionKey(otherPub)
+	require.EqualError(t, err, cryptoutil.ErrKeyNotFound.Error())
+
+	_, err = a.ConvertToEncryptionKey([]byte("key"))
+	require.Error(t, err)
+
+	require.NoError(t, a.Close())
+}
+
+func TestLocalKMSAdapter_SignMessage(t *testing.T) {
+	a := newAdapter(t)
+
+	_, verKey, err := a.CreateKeySet()
+	require.NoError(t, err)
+
+	msg := []byte("test message")
+
+	sig, err := a.SignMessage(msg, verKey)
+	require.NoError(t, err)
+	require.True(t, ed25519.Verify(base58.Decode(verKey), msg, sig))
+
+	_, err = a.SignMessage(msg, "unknown")
+	require.EqualError(t, err, "failed to get key: "+cryptoutil.ErrKeyNotFound.Error())
+}
+
+func TestLocalKMSAdapter_CryptoBox(t *testing.T) {
+	a := newAdapter(t)
+	legacyKMS, _ := newKMS(t)
+
+	aEncKey, _, err := a.CreateKeySet()
+	require.NoError(t, err)
+
+	legacyEncKey, _, err := legacyKMS.CreateKeySet()
+	require.NoError(t, err)
+
+	aBox, err := NewCryptoBox(a)
+	require.NoError(t, err)
+
+	legacyBox, err := NewCryptoBox(legacyKMS)
+	require.NoError(t, err)
+
+	msg := []byte("test message")
+	nonce := make([]byte, cryptoutil.NonceSize)
+
+	t.Run("easy", func(t *testing.T) {
+		enc, err := legacyBox.Easy(msg, nonce, base58.Decode(aEncKey), base58.Decode(legacyEncKey))
+		require.NoError(t, err)
+
+		dec, err := aBox.EasyOpen(enc, nonce, base58.Decode(legacyEncKey), base58.Decode(aEncKey))
+		require.NoError(t, err)
+		require.Equal(t, msg, dec)
+	})
+
+	t.Run("seal", func(t *testing.T) {
+		enc, err := aBox.Seal(msg, base58.Decode(aEncKey), rand.Reader)
+		require.NoError(t, err)
+
+		dec, err := aBox.SealOpen(enc, base58.Decode(aEncKey))
+		require.NoError(t, err)
+		require.Equal(t, msg, dec)
+	})
+}
+
+func TestLocalKMSAdapter_ImportLegacyKeys(t *testing.T) {
+	storeProvider := mem.NewProvider()
+
+	legacyKMS, err := New(&testProvider{storeProvider: storeProvider})
+	require.NoError(t, err)
+
+	legacyEncKey, legacyVerKey, err := legacyKMS.CreateKeySet()
+	require.NoError(t, err)
+
+	_, _, err = legacyKMS.CreateKeySet()
+	require.NoError(t, err)
+
+	a, err := NewLocalKMSAdapter(newAdapterTestProvider(t, storeProvider))
+	require.NoError(t, err)
+
+	imported, err := a.ImportLegacyKeysOnce()
+	require.NoError(t, err)
+	require.Equal(t, 2, imported)
+
+	imported, err = a.ImportLegacyKeysOnce()
+	require.NoError(t, err)
+	require.Zero(t, imported)
+
+	// already imported keys are skipped
+	imported, err = a.ImportLegacyKeys()
+	require.NoError(t, err)
+	require.Zero(t, imported)
+
+	t.Run("imported keys sign", func(t *testing.T) {
+		msg := []byte("test message")
+
+		sig, err := a.SignMessage(msg, legacyVerKey)
+		require.NoError(t, err)
+
+		legacySig, err := legacyKMS.SignMessage(msg, legacyVerKey)
+		require.NoError(t, err)
+		require.Equal(t, legacySig, sig)
+	})
+
+	t.Run("imported keys derive the same KEK", func(t *testing.T) {
+		toKey, err := randCurveKeyPair(rand.Reader)
+		require.NoError(t, err)
+
+		alg, apu := []byte("ECDH-SS+XC20PKW"), []byte("sender")
+
+		kek, err := a.DeriveKEK(alg, apu, base58.Decode(legacyEncKey), toKey.EncKeyPair.Pub)
+		require.NoError(t, err)
+
+		legacyKEK, err := legacyKMS.DeriveKEK(alg, apu, base58.Decode(legacyEncKey), toKey.EncKeyPair.Pub)
+		require.NoError(t, err)
+		require.Equal(t, legacyKEK, kek)
+
+		_, err = a.DeriveKEK(alg, apu, nil, toKey.EncKeyPair.Pub)
+		require.EqualError(t, err, cryptoutil.ErrInvalidKey.Error())
+
+		_, err = a.DeriveKEK(alg, apu, toKey.EncKeyPair.Pub, toKey.EncKeyPair.Pub)
+		require.EqualError(t, err, "failed from getKeyPairSet: "+cryptoutil.ErrKeyNotFound.Error())
+	})
+
+	t.Run("invalid legacy key", func(t *testing.T) {
+		storeProvider := mem.NewProvider()
+
+		store, err := storeProvider.OpenStore(KeyStoreNamespace)
+		require.NoError(t, err)
+		require.NoError(t, store.Put("key", []byte("{")))
+
+		a, err := NewLocalKMSAdapter(newAdapterTestProvider(t, storeProvider))
+		require.NoError(t, err)
+
+		_, err = a.ImportLegacyKeysOnce()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "import legacy keys: failed to unmarshal key 'key'")
+
+		require.NoError(t, store.Put("key", []byte("{}")))
+
+		imported, err := a.ImportLegacyKeysOnce()
+		require.NoError(t, err)
+		require.Zero(t, imported)
+	})
+}
+
+func TestEd25519PrivateKey(t *testing.T) {
+	_, err := ed25519PrivateKey("kh")
+	require.EqualError(t, err, "key handle is not a Tink keyset handle")
+
+	kh, err := keyset.NewHandle(aead.AES128GCMKeyTemplate())
+	require.NoError(t, err)
+
+	_, err = ed25519PrivateKey(kh)
+	require.EqualError(t, err, "key is not an Ed25519 private key")
+}
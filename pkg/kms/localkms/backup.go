@@ -0,0 +1,388 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const backupVersion = 1
+
+// BackupOpt is an option of BackupKeys() and RestoreKeys() calls.
+type BackupOpt func(opts *backupOpts)
+
+type backupOpts struct {
+	legacyStore storage.Store
+	dryRun      bool
+}
+
+// WithLegacyKeyStore option adds the legacy KMS key pairs found in legacyStore to the backup, or restores the legacy
+// key pairs of the archive into legacyStore.
+func WithLegacyKeyStore(legacyStore storage.Store) BackupOpt {
+	return func(opts *backupOpts) {
+		opts.legacyStore = legacyStore
+	}
+}
+
+// WithDryRun option executes all the checks of RestoreKeys() without writing any key in the stores.
+func WithDryRun() BackupOpt {
+	return func(opts *backupOpts) {
+		opts.dryRun = true
+	}
+}
+
+// RestoreResult is the result of a RestoreKeys() call.
+type RestoreResult struct {
+	// KeyIDs of the restored keys (or keys that would be restored in dry-run mode)
+	KeyIDs []string `json:"keyIDs"`
+	// SkippedKeyIDs are the IDs of the archived keys that already exist in the KMS
+	SkippedKeyIDs []string `json:"skippedKeyIDs,omitempty"`
+	// LegacyKeys is the number of restored legacy KMS key pair entries
+	LegacyKeys int `json:"legacyKeys"`
+	// DryRun is set when nothing was written to the stores
+	DryRun bool `json:"dryRun"`
+}
+
+// backupArchive is the encrypted archive envelope.
+type backupArchive = cryptoutil.Archive
+
+type backupPayload struct {
+	Created    time.Time     `json:"created"`
+	Keys       []backupKey   `json:"keys"`
+	LegacyKeys []backupEntry `json:"legacyKeys,omitempty"`
+}
+
+// backupKey is an archived key. Keyset is empty for rotated out keys which only have metadata.
+type backupKey struct {
+	KeyID    string           `json:"keyID"`
+	Keyset   []byte           `json:"keyset,omitempty"`
+	Metadata *kms.KeyMetadata `json:"metadata,omitempty"`
+	Digest   []byte           `json:"digest"`
+}
+
+type backupEntry struct {
+	Key    string `json:"key"`
+	Value  []byte `json:"value"`
+	Digest []byte `json:"digest"`
+}
+
+// BackupKeys exports all keysets (and their metadata) of the KMS into a single archive encrypted with a key derived
+// from passphrase. Legacy KMS key pairs are added to the archive with the WithLegacyKeyStore() option.
+// Returns:
+//  - the encrypted archive
+//  - error if failure
+func (l *LocalKMS) BackupKeys(passphrase []byte, opts ...BackupOpt) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("backup keys: passphrase is mandatory")
+	}
+
+	bOpts := &backupOpts{}
+
+	for _, opt := range opts {
+		opt(bOpts)
+	}
+
+	payload, err := l.backupPayload(bOpts)
+	if err != nil {
+		return nil, fmt.Errorf("backup keys: %w", err)
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("backup keys: failed to marshal payload: %w", err)
+	}
+
+	archive, err := sealArchive(payloadBytes, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("backup keys: %w", err)
+	}
+
+	return archive, nil
+}
+
+// RestoreKeys decrypts archive with passphrase, verifies its integrity and restores the archived keysets under their
+// original key IDs. Keys already present in the KMS are skipped. Legacy KMS key pairs are restored with the
+// WithLegacyKeyStore() option. Nothing is written with the WithDryRun() option.
+// Returns:
+//  - result listing the restored and skipped key IDs
+//  - error if the passphrase is wrong, the archive is corrupted or failed to write the keys
+func (l *LocalKMS) RestoreKeys(archive, passphrase []byte, opts ...BackupOpt) (*RestoreResult, error) {
+	bOpts := &backupOpts{}
+
+	for _, opt := range opts {
+		opt(bOpts)
+	}
+
+	payloadBytes, err := openArchive(archive, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("restore keys: %w", err)
+	}
+
+	payload := &backupPayload{}
+
+	err = json.Unmarshal(payloadBytes, payload)
+	if err != nil {
+		return nil, fmt.Errorf("restore keys: failed to unmarshal payload: %w", err)
+	}
+
+	handles, err := verifyPayload(payload)
+	if err != nil {
+		return nil, fmt.Errorf("restore keys: %w", err)
+	}
+
+	result := &RestoreResult{DryRun: bOpts.dryRun, KeyIDs: []string{}}
+
+	for i := range payload.Keys {
+		key := &payload.Keys[i]
+
+		restored, e := l.restoreKey(key, handles[key.KeyID], bOpts.dryRun)
+		if e != nil {
+			return nil, fmt.Errorf("restore keys: key %s: %w", key.KeyID, e)
+		}
+
+		if restored {
+			result.KeyIDs = append(result.KeyIDs, key.KeyID)
+		} else {
+			result.SkippedKeyIDs = append(result.SkippedKeyIDs, key.KeyID)
+		}
+	}
+
+	if bOpts.legacyStore != nil {
+		for _, entry := range payload.LegacyKeys {
+			if !bOpts.dryRun {
+				if e := bOpts.legacyStore.Put(entry.Key, entry.Value); e != nil {
+					return nil, fmt.Errorf("restore keys: legacy key %s: %w", entry.Key, e)
+				}
+			}
+
+			result.LegacyKeys++
+		}
+	}
+
+	return result, nil
+}
+
+func (l *LocalKMS) backupPayload(opts *backupOpts) (*backupPayload, error) {
+	payload := &backupPayload{Created: time.Now().UTC()}
+	keys := map[string]*backupKey{}
+
+	var keyIDs []string
+
+	getKey := func(keyID string) *backupKey {
+		if _, ok := keys[keyID]; !ok {
+			keys[keyID] = &backupKey{KeyID: keyID}
+			keyIDs = append(keyIDs, keyID)
+		}
+
+		return keys[keyID]
+	}
+
+	entries, err := readAll(l.store)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Key, keyIndexPrefix) {
+			md := &kms.KeyMetadata{}
+
+			if e := json.Unmarshal(entry.Value, md); e != nil {
+				return nil, fmt.Errorf("failed to unmarshal key metadata: %w", e)
+			}
+
+			getKey(md.KeyID).Metadata = md
+
+			continue
+		}
+
+		ks, e := l.cleartextKeyset(entry.Key)
+		if e != nil {
+			return nil, fmt.Errorf("failed to read keyset %s: %w", entry.Key, e)
+		}
+
+		getKey(entry.Key).Keyset = ks
+	}
+
+	for _, keyID := range keyIDs {
+		key := keys[keyID]
+		key.Digest = digest(key.KeyID, key.Keyset)
+
+		payload.Keys = append(payload.Keys, *key)
+	}
+
+	if opts.legacyStore != nil {
+		legacyEntries, e := readAll(opts.legacyStore)
+		if e != nil {
+			return nil, fmt.Errorf("failed to read legacy keys: %w", e)
+		}
+
+		for _, entry := range legacyEntries {
+			entry.Digest = digest(entry.Key, entry.Value)
+			payload.LegacyKeys = append(payload.LegacyKeys, entry)
+		}
+	}
+
+	return payload, nil
+}
+
+// cleartextKeyset reads the keyset keyID decrypted with the master key and returns it as a cleartext JSON keyset.
+func (l *LocalKMS) cleartextKeyset(keyID string) ([]byte, error) {
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = insecurecleartextkeyset.Write(kh, keyset.NewJSONWriter(buf))
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// restoreKey writes key in the store (encrypted with the master key) unless it already exists. It returns false if
+// the key was skipped.
+func (l *LocalKMS) restoreKey(key *backupKey, kh *keyset.Handle, dryRun bool) (bool, error) {
+	_, err := l.store.Get(key.KeyID)
+	if err == nil {
+		return false, nil
+	}
+
+	if !errors.Is(err, storage.ErrDataNotFound) {
+		return false, err
+	}
+
+	if key.Metadata != nil {
+		_, err = l.store.Get(keyIndexPrefix + key.KeyID)
+		if err == nil {
+			return false, nil
+		}
+
+		if !errors.Is(err, storage.ErrDataNotFound) {
+			return false, err
+		}
+	}
+
+	if dryRun {
+		return true, nil
+	}
+
+	if kh != nil {
+		buf := new(bytes.Buffer)
+
+		err = kh.Write(keyset.NewJSONWriter(buf), l.masterKeyEnvAEAD)
+		if err != nil {
+			return false, err
+		}
+
+		_, err = writeToStore(l.store, buf, kms.WithKeyID(key.KeyID))
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if key.Metadata != nil {
+		err = l.putKeyMetadata(key.Metadata)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// verifyPayload checks the digests of all archived entries and parses the archived keysets.
+func verifyPayload(payload *backupPayload) (map[string]*keyset.Handle, error) {
+	handles := map[string]*keyset.Handle{}
+
+	for _, key := range payload.Keys {
+		if key.KeyID == "" {
+			return nil, errors.New("integrity check failed: key without ID")
+		}
+
+		if !bytes.Equal(digest(key.KeyID, key.Keyset), key.Digest) {
+			return nil, fmt.Errorf("integrity check failed for key %s", key.KeyID)
+		}
+
+		if len(key.Keyset) == 0 {
+			if key.Metadata == nil {
+				return nil, fmt.Errorf("integrity check failed: key %s has no keyset", key.KeyID)
+			}
+
+			continue
+		}
+
+		kh, err := insecurecleartextkeyset.Read(keyset.NewJSONReader(bytes.NewReader(key.Keyset)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid keyset for key %s: %w", key.KeyID, err)
+		}
+
+		handles[key.KeyID] = kh
+	}
+
+	for _, entry := range payload.LegacyKeys {
+		if !bytes.Equal(digest(entry.Key, entry.Value), entry.Digest) {
+			return nil, fmt.Errorf("integrity check failed for legacy key %s", entry.Key)
+		}
+	}
+
+	return handles, nil
+}
+
+func sealArchive(payload, passphrase []byte) ([]byte, error) {
+	return cryptoutil.SealArchive(payload, passphrase, backupVersion)
+}
+
+func openArchive(archiveBytes, passphrase []byte) ([]byte, error) {
+	return cryptoutil.OpenArchive(archiveBytes, passphrase, backupVersion)
+}
+
+func digest(key string, value []byte) []byte {
+	h := sha256.New()
+
+	// hash.Hash never returns an error
+	_, _ = h.Write(cryptoutil.LengthPrefix([]byte(key))) // nolint: errcheck
+	_, _ = h.Write(value)                                // nolint: errcheck
+
+	return h.Sum(nil)
+}
+
+// readAll returns all entries of store.
+func readAll(store storage.Store) ([]backupEntry, error) {
+	itr := store.Iterator("", storage.EndKeySuffix)
+	defer itr.Release()
+
+	var entries []backupEntry
+
+	for itr.Next() {
+		entries = append(entries, backupEntry{
+			Key:   string(itr.Key()),
+			Value: append([]byte{}, itr.Value()...),
+		})
+	}
+
+	if itr.Error() != nil {
+		return nil, itr.Error()
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,320 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+func TestLocalKMS_BackupRestoreKeys(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+
+	srcKMS, srcDB := newBackupTestKMS(t)
+
+	signKeyID, _, err := srcKMS.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	rotatedKeyID, _, err := srcKMS.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	aeadKeyID, aeadKH, err := srcKMS.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	newKeyID, _, err := srcKMS.Rotate(kms.AES256GCMType, rotatedKeyID)
+	require.NoError(t, err)
+
+	c, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	msg := []byte("lorem ipsum")
+
+	cipherText, nonce, err := c.Encrypt(msg, nil, aeadKH)
+	require.NoError(t, err)
+
+	legacyDB := map[string][]byte{"verKey": []byte(`{"keys":"pair"}`)}
+
+	archive, err := srcKMS.BackupKeys(passphrase, WithLegacyKeyStore(&mockstorage.MockStore{Store: legacyDB}))
+	require.NoError(t, err)
+
+	// the archive does not contain cleartext key material
+	for k := range srcDB {
+		require.NotContains(t, string(archive), k)
+	}
+
+	t.Run("restore in a new KMS with another master key", func(t *testing.T) {
+		dstKMS, _ := newBackupTestKMS(t)
+		dstLegacyDB := map[string][]byte{}
+
+		result, err := dstKMS.RestoreKeys(archive, passphrase,
+			WithLegacyKeyStore(&mockstorage.MockStore{Store: dstLegacyDB}))
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{signKeyID, rotatedKeyID, aeadKeyID, newKeyID}, result.KeyIDs)
+		require.Empty(t, result.SkippedKeyIDs)
+		require.Equal(t, 1, result.LegacyKeys)
+		require.False(t, result.DryRun)
+		require.Equal(t, legacyDB, dstLegacyDB)
+
+		// restored keys work under their original IDs
+		kh, err := dstKMS.Get(aeadKeyID)
+		require.NoError(t, err)
+
+		plainText, err := c.Decrypt(cipherText, nonce, nil, kh.(*keyset.Handle))
+		require.NoError(t, err)
+		require.Equal(t, msg, plainText)
+
+		srcPubKey, err := srcKMS.ExportPubKeyBytes(signKeyID)
+		require.NoError(t, err)
+
+		dstPubKey, err := dstKMS.ExportPubKeyBytes(signKeyID)
+		require.NoError(t, err)
+		require.Equal(t, srcPubKey, dstPubKey)
+
+		// metadata is restored
+		srcKeys, err := srcKMS.List()
+		require.NoError(t, err)
+
+		dstKeys, err := dstKMS.List()
+		require.NoError(t, err)
+		require.Equal(t, srcKeys, dstKeys)
+
+		md, err := dstKMS.Describe(rotatedKeyID)
+		require.NoError(t, err)
+		require.Equal(t, kms.KeyDisabled, md.Status)
+		require.Equal(t, newKeyID, md.RotatedTo)
+
+		// restoring again skips existing keys
+		result, err = dstKMS.RestoreKeys(archive, passphrase)
+		require.NoError(t, err)
+		require.Empty(t, result.KeyIDs)
+		require.Len(t, result.SkippedKeyIDs, 4)
+		require.Equal(t, 0, result.LegacyKeys)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		dstKMS, dstDB := newBackupTestKMS(t)
+		dstLegacyDB := map[string][]byte{}
+
+		result, err := dstKMS.RestoreKeys(archive, passphrase, WithDryRun(),
+			WithLegacyKeyStore(&mockstorage.MockStore{Store: dstLegacyDB}))
+		require.NoError(t, err)
+		require.True(t, result.DryRun)
+		require.Len(t, result.KeyIDs, 4)
+		require.Equal(t, 1, result.LegacyKeys)
+		require.Empty(t, dstDB)
+		require.Empty(t, dstLegacyDB)
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		dstKMS, _ := newBackupTestKMS(t)
+
+		_, err := dstKMS.RestoreKeys(archive, []byte("wrong passphrase"))
+		require.EqualError(t, err, "restore keys: invalid passphrase or corrupted archive")
+	})
+
+	t.Run("tampered archive", func(t *testing.T) {
+		dstKMS, _ := newBackupTestKMS(t)
+
+		a := &backupArchive{}
+		require.NoError(t, json.Unmarshal(archive, a))
+
+		// header is authenticated
+		a.KDF.Salt[0] ^= 0xff
+		tampered, err := json.Marshal(a)
+		require.NoError(t, err)
+
+		_, err = dstKMS.RestoreKeys(tampered, passphrase)
+		require.EqualError(t, err, "restore keys: invalid passphrase or corrupted archive")
+
+		// key derivation parameters other than the sealing ones are rejected
+		require.NoError(t, json.Unmarshal(archive, a))
+		a.KDF.N = 16384
+		tampered, err = json.Marshal(a)
+		require.NoError(t, err)
+
+		_, err = dstKMS.RestoreKeys(tampered, passphrase)
+		require.EqualError(t, err, "restore keys: unsupported archive key derivation parameters n=16384 r=8 p=1")
+
+		require.NoError(t, json.Unmarshal(archive, a))
+		a.CipherText[0] ^= 0xff
+		tampered, err = json.Marshal(a)
+		require.NoError(t, err)
+
+		_, err = dstKMS.RestoreKeys(tampered, passphrase)
+		require.EqualError(t, err, "restore keys: invalid passphrase or corrupted archive")
+
+		require.NoError(t, json.Unmarshal(archive, a))
+		a.Version = 2
+		tampered, err = json.Marshal(a)
+		require.NoError(t, err)
+
+		_, err = dstKMS.RestoreKeys(tampered, passphrase)
+		require.EqualError(t, err, "restore keys: unsupported archive version 2")
+
+		require.NoError(t, json.Unmarshal(archive, a))
+		a.KDF.Alg = "pbkdf2"
+		tampered, err = json.Marshal(a)
+		require.NoError(t, err)
+
+		_, err = dstKMS.RestoreKeys(tampered, passphrase)
+		require.EqualError(t, err, "restore keys: unsupported archive key derivation 'pbkdf2'")
+
+		require.NoError(t, json.Unmarshal(archive, a))
+		a.Nonce = []byte("nonce")
+		tampered, err = json.Marshal(a)
+		require.NoError(t, err)
+
+		_, err = dstKMS.RestoreKeys(tampered, passphrase)
+		require.EqualError(t, err, "restore keys: invalid archive nonce")
+
+		_, err = dstKMS.RestoreKeys([]byte("{"), passphrase)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "restore keys: invalid archive")
+	})
+
+	t.Run("integrity check failures", func(t *testing.T) {
+		dstKMS, _ := newBackupTestKMS(t)
+
+		tests := []struct {
+			name    string
+			payload *backupPayload
+			err     string
+		}{
+			{
+				name:    "key without ID",
+				payload: &backupPayload{Keys: []backupKey{{}}},
+				err:     "restore keys: integrity check failed: key without ID",
+			},
+			{
+				name:    "bad key digest",
+				payload: &backupPayload{Keys: []backupKey{{KeyID: "k1", Keyset: []byte("{}")}}},
+				err:     "restore keys: integrity check failed for key k1",
+			},
+			{
+				name:    "key without keyset and metadata",
+				payload: &backupPayload{Keys: []backupKey{{KeyID: "k1", Digest: digest("k1", nil)}}},
+				err:     "restore keys: integrity check failed: key k1 has no keyset",
+			},
+			{
+				name: "invalid keyset",
+				payload: &backupPayload{Keys: []backupKey{
+					{KeyID: "k1", Keyset: []byte("{"), Digest: digest("k1", []byte("{"))},
+				}},
+				err: "restore keys: invalid keyset for key k1",
+			},
+			{
+				name:    "bad legacy key digest",
+				payload: &backupPayload{LegacyKeys: []backupEntry{{Key: "verKey", Value: []byte("{}")}}},
+				err:     "restore keys: integrity check failed for legacy key verKey",
+			},
+		}
+
+		for _, tc := range tests {
+			payload, err := json.Marshal(tc.payload)
+			require.NoError(t, err)
+
+			a, err := sealArchive(payload, passphrase)
+			require.NoError(t, err)
+
+			_, err = dstKMS.RestoreKeys(a, passphrase)
+			require.Error(t, err, tc.name)
+			require.Contains(t, err.Error(), tc.err, tc.name)
+		}
+
+		a, err := sealArchive([]byte("not JSON"), passphrase)
+		require.NoError(t, err)
+
+		_, err = dstKMS.RestoreKeys(a, passphrase)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "restore keys: failed to unmarshal payload")
+	})
+
+	t.Run("backup failures", func(t *testing.T) {
+		_, err := srcKMS.BackupKeys(nil)
+		require.EqualError(t, err, "backup keys: passphrase is mandatory")
+
+		_, err = srcKMS.BackupKeys(passphrase,
+			WithLegacyKeyStore(&mockstorage.MockStore{ErrItr: errors.New("iterator error")}))
+		require.EqualError(t, err, "backup keys: failed to read legacy keys: iterator error")
+
+		k, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{ErrItr: errors.New("iterator error")}),
+			secretLock: &noop.NoLock{},
+		})
+		require.NoError(t, err)
+
+		_, err = k.BackupKeys(passphrase)
+		require.EqualError(t, err, "backup keys: iterator error")
+
+		k, db := newBackupTestKMS(t)
+		db["bad keyset"] = []byte("{")
+
+		_, err = k.BackupKeys(passphrase)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "backup keys: failed to read keyset bad keyset")
+
+		delete(db, "bad keyset")
+		db[keyIndexPrefix+"bad"] = []byte("{")
+
+		_, err = k.BackupKeys(passphrase)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "backup keys: failed to unmarshal key metadata")
+	})
+
+	t.Run("restore store failures", func(t *testing.T) {
+		k, err := New(testMasterKeyURI, &mockProvider{
+			storage: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+				Store: map[string][]byte{}, ErrGet: errors.New("get error")}),
+			secretLock: &noop.NoLock{},
+		})
+		require.NoError(t, err)
+
+		_, err = k.RestoreKeys(archive, passphrase)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get error")
+
+		k, err = New(testMasterKeyURI, &mockProvider{
+			storage: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+				Store: map[string][]byte{}, ErrPut: errors.New("put error")}),
+			secretLock: &noop.NoLock{},
+		})
+		require.NoError(t, err)
+
+		_, err = k.RestoreKeys(archive, passphrase)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "put error")
+
+		dstKMS, _ := newBackupTestKMS(t)
+
+		_, err = dstKMS.RestoreKeys(archive, passphrase,
+			WithLegacyKeyStore(&mockstorage.MockStore{Store: map[string][]byte{}, ErrPut: errors.New("put error")}))
+		require.EqualError(t, err, "restore keys: legacy key verKey: put error")
+	})
+}
+
+func newBackupTestKMS(t *testing.T) (*LocalKMS, map[string][]byte) {
+	t.Helper()
+
+	db := map[string][]byte{}
+
+	k, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{Store: db}),
+		secretLock: &noop.NoLock{},
+	})
+	require.NoError(t, err)
+
+	return k, db
+}
@@ -0,0 +1,181 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// keyIndexPrefix is the prefix of key metadata entries in the KMS store. ':' is not part of the base64 URL alphabet
+// used for generated keyset IDs.
+const keyIndexPrefix = "keyindex:"
+
+// List returns the metadata of all indexed keys (including disabled keys) sorted by creation time.
+// Note: keys created before key indexing was introduced are not listed, but they can still be described by keyID.
+func (l *LocalKMS) List() ([]*kms.KeyMetadata, error) {
+	itr := l.store.Iterator(keyIndexPrefix, keyIndexPrefix+storage.EndKeySuffix)
+	defer itr.Release()
+
+	var keys []*kms.KeyMetadata
+
+	for itr.Next() {
+		if !strings.HasPrefix(string(itr.Key()), keyIndexPrefix) {
+			continue
+		}
+
+		md := &kms.KeyMetadata{}
+
+		err := json.Unmarshal(itr.Value(), md)
+		if err != nil {
+			return nil, fmt.Errorf("list keys: failed to unmarshal key metadata: %w", err)
+		}
+
+		keys = append(keys, md)
+	}
+
+	if itr.Error() != nil {
+		return nil, fmt.Errorf("list keys: %w", itr.Error())
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].Created.Before(keys[j].Created)
+	})
+
+	return keys, nil
+}
+
+// Describe returns the metadata of the key referenced by keyID. Keys stored without metadata are reported as enabled
+// keys of unknown type and creation time.
+func (l *LocalKMS) Describe(keyID string) (*kms.KeyMetadata, error) {
+	md, err := l.getKeyMetadata(keyID)
+	if err == nil {
+		return md, nil
+	}
+
+	if !errors.Is(err, storage.ErrDataNotFound) {
+		return nil, fmt.Errorf("describe key: %w", err)
+	}
+
+	_, err = l.store.Get(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("describe key: %w", err)
+	}
+
+	return &kms.KeyMetadata{KeyID: keyID, Status: kms.KeyEnabled}, nil
+}
+
+// Delete removes the keyset referenced by keyID and its metadata from the store.
+func (l *LocalKMS) Delete(keyID string) error {
+	_, mdErr := l.store.Get(keyIndexPrefix + keyID)
+	if mdErr != nil && !errors.Is(mdErr, storage.ErrDataNotFound) {
+		return fmt.Errorf("delete key: %w", mdErr)
+	}
+
+	_, ksErr := l.store.Get(keyID)
+	if ksErr != nil && !errors.Is(ksErr, storage.ErrDataNotFound) {
+		return fmt.Errorf("delete key: %w", ksErr)
+	}
+
+	if mdErr != nil && ksErr != nil {
+		return fmt.Errorf("delete key: %w", storage.ErrDataNotFound)
+	}
+
+	if ksErr == nil {
+		if err := l.store.Delete(keyID); err != nil {
+			return fmt.Errorf("delete key: %w", err)
+		}
+	}
+
+	if mdErr == nil {
+		if err := l.store.Delete(keyIndexPrefix + keyID); err != nil {
+			return fmt.Errorf("delete key metadata: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// indexKey stores the metadata of a new enabled key.
+func (l *LocalKMS) indexKey(keyID string, kt kms.KeyType) error {
+	return l.putKeyMetadata(&kms.KeyMetadata{
+		KeyID:   keyID,
+		KeyType: kt,
+		Created: time.Now().UTC(),
+		Status:  kms.KeyEnabled,
+	})
+}
+
+// disableKey marks the key keyID as disabled and rotated to newKeyID. Keys without metadata are indexed as disabled.
+func (l *LocalKMS) disableKey(keyID, newKeyID string) error {
+	md, err := l.getKeyMetadata(keyID)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		md, err = &kms.KeyMetadata{KeyID: keyID}, nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	md.Status = kms.KeyDisabled
+	md.RotatedTo = newKeyID
+
+	return l.putKeyMetadata(md)
+}
+
+func (l *LocalKMS) putKeyMetadata(md *kms.KeyMetadata) error {
+	mdBytes, err := json.Marshal(md)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key metadata: %w", err)
+	}
+
+	return l.store.Put(keyIndexPrefix+md.KeyID, mdBytes)
+}
+
+func (l *LocalKMS) getKeyMetadata(keyID string) (*kms.KeyMetadata, error) {
+	mdBytes, err := l.store.Get(keyIndexPrefix + keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	md := &kms.KeyMetadata{}
+
+	err = json.Unmarshal(mdBytes, md)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal key metadata: %w", err)
+	}
+
+	return md, nil
+}
+
+// disableNonPrimaryKeys returns a copy of kh where all keys but the primary key are disabled.
+func disableNonPrimaryKeys(kh *keyset.Handle) (*keyset.Handle, error) {
+	ks, ok := proto.Clone(insecurecleartextkeyset.KeysetMaterial(kh)).(*tinkpb.Keyset)
+	if !ok {
+		return nil, errors.New("failed to copy keyset")
+	}
+
+	for _, k := range ks.Key {
+		if k.KeyId != ks.PrimaryKeyId {
+			k.Status = tinkpb.KeyStatusType_DISABLED
+		}
+	}
+
+	return insecurecleartextkeyset.Read(&keyset.MemReaderWriter{Keyset: ks})
+}
@@ -0,0 +1,203 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestLocalKMS_KeyInventory(t *testing.T) {
+	storeDB := make(map[string][]byte)
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{Store: storeDB}),
+		secretLock: &noop.NoLock{},
+	})
+	require.NoError(t, err)
+
+	var _ kms.KeyInventory = kmsService
+
+	t.Run("create, list and describe keys", func(t *testing.T) {
+		keyID1, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		keyID2, _, err := kmsService.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		keys, err := kmsService.List()
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		require.Equal(t, keyID1, keys[0].KeyID)
+		require.Equal(t, kms.ED25519Type, keys[0].KeyType)
+		require.Equal(t, kms.KeyEnabled, keys[0].Status)
+		require.False(t, keys[0].Created.IsZero())
+		require.Equal(t, keyID2, keys[1].KeyID)
+
+		md, err := kmsService.Describe(keyID2)
+		require.NoError(t, err)
+		require.Equal(t, kms.AES256GCMType, md.KeyType)
+		require.Equal(t, kms.KeyEnabled, md.Status)
+
+		require.NoError(t, kmsService.Delete(keyID1))
+		require.NoError(t, kmsService.Delete(keyID2))
+
+		keys, err = kmsService.List()
+		require.NoError(t, err)
+		require.Empty(t, keys)
+		require.Empty(t, storeDB)
+	})
+
+	t.Run("rotated key is disabled", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		newKeyID, kh, err := kmsService.Rotate(kms.ED25519Type, keyID)
+		require.NoError(t, err)
+
+		md, err := kmsService.Describe(keyID)
+		require.NoError(t, err)
+		require.Equal(t, kms.KeyDisabled, md.Status)
+		require.Equal(t, newKeyID, md.RotatedTo)
+		require.Equal(t, kms.ED25519Type, md.KeyType)
+
+		md, err = kmsService.Describe(newKeyID)
+		require.NoError(t, err)
+		require.Equal(t, kms.KeyEnabled, md.Status)
+
+		// old key is disabled in the rotated keyset
+		ks := insecurecleartextkeyset.KeysetMaterial(kh.(*keyset.Handle))
+		require.Len(t, ks.Key, 2)
+
+		for _, k := range ks.Key {
+			if k.KeyId == ks.PrimaryKeyId {
+				require.Equal(t, tinkpb.KeyStatusType_ENABLED, k.Status)
+			} else {
+				require.Equal(t, tinkpb.KeyStatusType_DISABLED, k.Status)
+			}
+		}
+
+		keys, err := kmsService.List()
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+
+		// deleting a rotated out key removes its metadata
+		require.NoError(t, kmsService.Delete(keyID))
+		require.NoError(t, kmsService.Delete(newKeyID))
+		require.Empty(t, storeDB)
+	})
+
+	t.Run("imported key is indexed", func(t *testing.T) {
+		_, privKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		keyID, _, err := kmsService.ImportPrivateKey(privKey, kms.ED25519Type, kms.WithKeyID("imported"))
+		require.NoError(t, err)
+		require.Equal(t, "imported", keyID)
+
+		md, err := kmsService.Describe(keyID)
+		require.NoError(t, err)
+		require.Equal(t, kms.ED25519Type, md.KeyType)
+		require.Equal(t, kms.KeyEnabled, md.Status)
+
+		require.NoError(t, kmsService.Delete(keyID))
+	})
+
+	t.Run("key without metadata", func(t *testing.T) {
+		keyID, _, err := kmsService.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		// remove the metadata to emulate a key created without an index
+		delete(storeDB, keyIndexPrefix+keyID)
+
+		md, err := kmsService.Describe(keyID)
+		require.NoError(t, err)
+		require.Equal(t, &kms.KeyMetadata{KeyID: keyID, Status: kms.KeyEnabled}, md)
+
+		keys, err := kmsService.List()
+		require.NoError(t, err)
+		require.Empty(t, keys)
+
+		require.NoError(t, kmsService.Delete(keyID))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := kmsService.Describe("unknown")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		err = kmsService.Delete("unknown")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		storeDB[keyIndexPrefix+"invalid"] = []byte("{")
+
+		_, err := kmsService.List()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "list keys: failed to unmarshal key metadata")
+
+		_, err = kmsService.Describe("invalid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "describe key: failed to unmarshal key metadata")
+
+		require.NoError(t, kmsService.Delete("invalid"))
+	})
+}
+
+func TestLocalKMS_KeyInventoryStoreErrors(t *testing.T) {
+	storeErr := errors.New("store error")
+
+	t.Run("iterator error", func(t *testing.T) {
+		kmsService, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{ErrItr: storeErr}),
+			secretLock: &noop.NoLock{},
+		})
+		require.NoError(t, err)
+
+		_, err = kmsService.List()
+		require.EqualError(t, err, "list keys: store error")
+	})
+
+	t.Run("get error", func(t *testing.T) {
+		kmsService, err := New(testMasterKeyURI, &mockProvider{
+			storage: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+				Store: map[string][]byte{}, ErrGet: storeErr}),
+			secretLock: &noop.NoLock{},
+		})
+		require.NoError(t, err)
+
+		_, err = kmsService.Describe("key")
+		require.EqualError(t, err, "describe key: store error")
+
+		err = kmsService.Delete("key")
+		require.EqualError(t, err, "delete key: store error")
+	})
+
+	t.Run("delete error", func(t *testing.T) {
+		kmsService, err := New(testMasterKeyURI, &mockProvider{
+			storage: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+				Store: map[string][]byte{"key": []byte("keyset")}, ErrDelete: storeErr}),
+			secretLock: &noop.NoLock{},
+		})
+		require.NoError(t, err)
+
+		err = kmsService.Delete("key")
+		require.EqualError(t, err, "delete key: store error")
+	})
+}
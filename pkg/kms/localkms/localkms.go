@@ -22,6 +22,7 @@ import (
 	"github.com/google/tink/go/signature"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdhes"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/secp256k1"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/keywrapper"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
@@ -99,6 +100,11 @@ func (l *LocalKMS) Create(kt kms.KeyType) (string, interface{}, error) {
 		return "", nil, err
 	}
 
+	err = l.indexKey(kID, kt)
+	if err != nil {
+		return "", nil, err
+	}
+
 	return kID, kh, nil
 }
 
@@ -111,7 +117,8 @@ func (l *LocalKMS) Get(keyID string) (interface{}, error) {
 }
 
 // Rotate a key referenced by keyID and return a new handle of a keyset including old key and
-// new key with type kt. It also returns the updated keyID as the first return value
+// new key with type kt. It also returns the updated keyID as the first return value.
+// The old keys are disabled in the new keyset and keyID is marked as disabled in the key index.
 // Returns:
 //  - new KeyID // TODO remove this return from Rotate() - #1837
 //  - handle instance (to private key)
@@ -135,8 +142,12 @@ func (l *LocalKMS) Rotate(kt kms.KeyType, keyID string) (string, interface{}, er
 		return "", nil, err
 	}
 
-	updatedKH, err := km.Handle()
+	rotatedKH, err := km.Handle()
+	if err != nil {
+		return "", nil, err
+	}
 
+	updatedKH, err := disableNonPrimaryKeys(rotatedKH)
 	if err != nil {
 		return "", nil, err
 	}
@@ -151,6 +162,16 @@ func (l *LocalKMS) Rotate(kt kms.KeyType, keyID string) (string, interface{}, er
 		return "", nil, err
 	}
 
+	err = l.indexKey(newID, kt)
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = l.disableKey(keyID, newID)
+	if err != nil {
+		return "", nil, err
+	}
+
 	return newID, updatedKH, nil
 }
 
@@ -181,12 +202,18 @@ func getKeyTemplate(keyType kms.KeyType) (*tinkpb.KeyTemplate, error) {
 		return createECDSAIEEE1363KeyTemplate(commonpb.HashType_SHA384, commonpb.EllipticCurveType_NIST_P384), nil
 	case kms.ECDSAP521TypeIEEEP1363:
 		return createECDSAIEEE1363KeyTemplate(commonpb.HashType_SHA512, commonpb.EllipticCurveType_NIST_P521), nil
+	case kms.ECDSASecp256k1TypeIEEEP1363:
+		return secp256k1.KeyTemplate(), nil
 	case kms.ED25519Type:
 		return signature.ED25519KeyWithoutPrefixTemplate(), nil
 	case kms.HMACSHA256Tag256Type:
 		return mac.HMACSHA256Tag256KeyTemplate(), nil
-	case kms.ECDHES256AES256GCMType:
+	case kms.ECDHES256AES256GCMType, kms.NISTP256ECDHKWType:
 		return ecdhes.ECDHES256KWAES256GCMKeyTemplate(), nil
+	case kms.NISTP384ECDHKWType:
+		return ecdhes.ECDHES384KWAES256GCMKeyTemplate(), nil
+	case kms.X25519ECDHKWType:
+		return ecdhes.ECDHESX25519KWAES256GCMKeyTemplate(), nil
 	default:
 		return nil, fmt.Errorf("key type unrecognized")
 	}
@@ -285,7 +312,7 @@ func (l *LocalKMS) PubKeyBytesToHandle(pubKey []byte, kt kms.KeyType) (interface
 // ImportPrivateKey will import privKey into the KMS storage for the given keyType then returns the new key id and
 // the newly persisted Handle.
 // 'privKey' possible types are: *ecdsa.PrivateKey and ed25519.PrivateKey
-// 'keyType' possible types are signing key types only (ECDSA keys or Ed25519)
+// 'keyType' possible types are signing key types only (ECDSA keys, including ECDSASecp256k1TypeIEEEP1363, or Ed25519)
 // 'opts' allows setting the keysetID of the imported key using WithKeyID() option. If the ID is already used,
 // then an error is returned.
 // Returns:
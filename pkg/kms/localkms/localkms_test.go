@@ -14,16 +14,21 @@ import (
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/google/tink/go/keyset"
 	"github.com/google/tink/go/subtle/random"
 	"github.com/stretchr/testify/require"
 
+	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/keywrapper"
 	mocksecretlock "github.com/hyperledger/aries-framework-go/pkg/mock/secretlock"
@@ -31,6 +36,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/hkdf"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
@@ -210,8 +216,11 @@ func TestLocalKMS_Success(t *testing.T) {
 		kms.ECDSAP256TypeIEEEP1363,
 		kms.ECDSAP384TypeIEEEP1363,
 		kms.ECDSAP521TypeIEEEP1363,
+		kms.ECDSASecp256k1TypeIEEEP1363,
 		kms.ED25519Type,
 		kms.ECDHES256AES256GCMType,
+		kms.NISTP256ECDHKWType,
+		kms.NISTP384ECDHKWType,
 	}
 
 	for _, v := range keyTemplates {
@@ -332,6 +341,11 @@ func TestLocalKMS_ImportPrivateKey(t *testing.T) {
 			keyType: kms.ECDSAP521TypeIEEEP1363,
 			curve:   elliptic.P521(),
 		},
+		{
+			tcName:  "import private key using ECDSASecp256k1TypeIEEEP1363 type",
+			keyType: kms.ECDSASecp256k1TypeIEEEP1363,
+			curve:   btcec.S256(),
+		},
 		{
 			tcName:  "import private key using ED25519Type type",
 			keyType: kms.ED25519Type,
@@ -406,12 +420,115 @@ func TestLocalKMS_ImportPrivateKey(t *testing.T) {
 				pubKey, err := x509.MarshalPKIXPublicKey(privKey.Public())
 				require.NoError(t, err)
 				require.EqualValues(t, pubKey, pubKeyBytes)
-			case kms.ECDSAP256TypeIEEEP1363, kms.ECDSAP384TypeIEEEP1363, kms.ECDSAP521TypeIEEEP1363:
+			case kms.ECDSAP256TypeIEEEP1363, kms.ECDSAP384TypeIEEEP1363, kms.ECDSAP521TypeIEEEP1363,
+				kms.ECDSASecp256k1TypeIEEEP1363:
 				pubKey := elliptic.Marshal(tt.curve, privKey.X, privKey.Y)
 				require.EqualValues(t, pubKey, pubKeyBytes)
 			}
 		})
 	}
+
+	t.Run("import secp256k1 private key with another curve", func(t *testing.T) {
+		privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		_, _, err = kmsService.ImportPrivateKey(privKey, kms.ECDSASecp256k1TypeIEEEP1363)
+		require.EqualError(t, err, "import private EC key failed: key is not a secp256k1 key")
+	})
+}
+
+func TestLocalKMS_Secp256k1SignVerify(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: createMasterKeyAndSecretLock(t),
+	})
+	require.NoError(t, err)
+
+	privKey, err := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+	require.NoError(t, err)
+
+	keyID, kh, err := kmsService.ImportPrivateKey(privKey, kms.ECDSASecp256k1TypeIEEEP1363)
+	require.NoError(t, err)
+
+	c, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	msg := []byte("test message")
+
+	sig, err := c.Sign(msg, kh)
+	require.NoError(t, err)
+	require.Len(t, sig, 64)
+
+	// the signature is a IEEE P1363 ECDSA signature of the SHA-256 digest of msg
+	digest := sha256.Sum256(msg)
+	require.True(t, ecdsa.Verify(&privKey.PublicKey, digest[:],
+		new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])))
+
+	pubKeyBytes, err := kmsService.ExportPubKeyBytes(keyID)
+	require.NoError(t, err)
+
+	pubKH, err := kmsService.PubKeyBytesToHandle(pubKeyBytes, kms.ECDSASecp256k1TypeIEEEP1363)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Verify(sig, msg, pubKH))
+	require.Error(t, c.Verify(sig, []byte("other message"), pubKH))
+
+	// keys are still usable once reloaded from the store
+	kh, err = kmsService.Get(keyID)
+	require.NoError(t, err)
+
+	sig, err = c.Sign(msg, kh)
+	require.NoError(t, err)
+	require.NoError(t, c.Verify(sig, msg, pubKH))
+}
+
+func TestLocalKMS_ECDHKeyTypes(t *testing.T) {
+	kmsService, err := New(testMasterKeyURI, &mockProvider{
+		storage:    mockstorage.NewMockStoreProvider(),
+		secretLock: &noop.NoLock{},
+	})
+	require.NoError(t, err)
+
+	c, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		kt    kms.KeyType
+		kty   string
+		curve string
+	}{
+		{kt: kms.NISTP256ECDHKWType, kty: "EC", curve: "P-256"},
+		{kt: kms.NISTP384ECDHKWType, kty: "EC", curve: "P-384"},
+		{kt: kms.X25519ECDHKWType, kty: "OKP", curve: "X25519"},
+	} {
+		tc := tc
+
+		t.Run(string(tc.kt), func(t *testing.T) {
+			keyID, kh, err := kmsService.Create(tc.kt)
+			require.NoError(t, err)
+
+			pubKeyBytes, err := kmsService.ExportPubKeyBytes(keyID)
+			require.NoError(t, err)
+
+			jwk, err := kms.PubKeyBytesToJWK(pubKeyBytes, tc.kt)
+			require.NoError(t, err)
+			require.Equal(t, tc.kty, jwk.Kty)
+			require.Equal(t, tc.curve, jwk.Crv)
+
+			// the exported public key is a recipient key for key wrapping
+			recPubKey := &cryptoapi.PublicKey{}
+			require.NoError(t, json.Unmarshal(pubKeyBytes, recPubKey))
+
+			cek := random.GetRandomBytes(uint32(32))
+
+			wk, err := c.WrapKey(cek, []byte("apu"), []byte("apv"), recPubKey)
+			require.NoError(t, err)
+
+			unwrapped, err := c.UnwrapKey(wk, kh)
+			require.NoError(t, err)
+			require.Equal(t, cek, unwrapped)
+		})
+	}
 }
 
 func TestLocalKMS_getKeyTemplate(t *testing.T) {
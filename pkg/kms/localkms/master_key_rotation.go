@@ -0,0 +1,131 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/google/tink/go/keyset"
+)
+
+// MasterKeyRotator is implemented by secret lock services supporting the rotation of their master key, such as
+// secretlock/local.Lock.
+type MasterKeyRotator interface {
+	// RotateMasterKey creates a new master key, persists it with persist then uses it to encrypt keys while still
+	// decrypting keys with the previous master keys.
+	RotateMasterKey(persist func(protectedMasterKey []byte) error) error
+	// CompleteMasterKeyRotation drops the previous master keys.
+	CompleteMasterKeyRotation()
+}
+
+// MasterKeyRotationProgress reports the progress of the re-encryption of keysets during a master key rotation.
+type MasterKeyRotationProgress struct {
+	// KeyID of the last re-encrypted keyset
+	KeyID string `json:"keyID"`
+	// Done is the number of keysets re-encrypted so far
+	Done int `json:"done"`
+	// Total is the number of keysets to re-encrypt
+	Total int `json:"total"`
+}
+
+// ReEncryptOpt is an option of the keysets re-encryption.
+type ReEncryptOpt func(opts *reEncryptOpts)
+
+type reEncryptOpts struct {
+	progress func(p *MasterKeyRotationProgress)
+}
+
+// WithProgress sets a function called after each keyset is re-encrypted.
+func WithProgress(progress func(p *MasterKeyRotationProgress)) ReEncryptOpt {
+	return func(opts *reEncryptOpts) {
+		opts.progress = progress
+	}
+}
+
+// RotateMasterKey rotates the master key of the KMS secret lock, which must be a MasterKeyRotator, then
+// re-encrypts all keysets with the new master key using ReEncryptKeys(). persist is called with the protected new
+// master key before any keyset is re-encrypted, it must store it for the next start of the secret lock.
+// Returns:
+//  - the number of re-encrypted keysets
+//  - error if the secret lock does not support rotation, failed to persist the new master key or failed to
+//    re-encrypt a keyset (in which case the rotation can be resumed with ReEncryptKeys())
+func (l *LocalKMS) RotateMasterKey(persist func(protectedMasterKey []byte) error, opts ...ReEncryptOpt) (int, error) {
+	rotator, ok := l.secretLock.(MasterKeyRotator)
+	if !ok {
+		return 0, fmt.Errorf("rotate master key: secret lock does not support master key rotation")
+	}
+
+	err := rotator.RotateMasterKey(persist)
+	if err != nil {
+		return 0, fmt.Errorf("rotate master key: %w", err)
+	}
+
+	return l.ReEncryptKeys(opts...)
+}
+
+// ReEncryptKeys re-encrypts all keysets of the KMS store with the current master key of the secret lock, then
+// completes the master key rotation if the secret lock is a MasterKeyRotator. Each keyset is replaced in a single
+// store write, it is safe to call ReEncryptKeys again to resume an interrupted rotation as long as the secret lock can
+// still decrypt keysets with the previous master key.
+// Returns:
+//  - the number of re-encrypted keysets
+//  - error if failed to read, re-encrypt or store a keyset
+func (l *LocalKMS) ReEncryptKeys(opts ...ReEncryptOpt) (int, error) {
+	rOpts := &reEncryptOpts{}
+
+	for _, opt := range opts {
+		opt(rOpts)
+	}
+
+	entries, err := readAll(l.store)
+	if err != nil {
+		return 0, fmt.Errorf("re-encrypt keys: %w", err)
+	}
+
+	var keyIDs []string
+
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Key, keyIndexPrefix) {
+			keyIDs = append(keyIDs, entry.Key)
+		}
+	}
+
+	for i, keyID := range keyIDs {
+		err = l.reEncryptKeySet(keyID)
+		if err != nil {
+			return i, fmt.Errorf("re-encrypt keys: key %s: %w", keyID, err)
+		}
+
+		if rOpts.progress != nil {
+			rOpts.progress(&MasterKeyRotationProgress{KeyID: keyID, Done: i + 1, Total: len(keyIDs)})
+		}
+	}
+
+	if rotator, ok := l.secretLock.(MasterKeyRotator); ok {
+		rotator.CompleteMasterKeyRotation()
+	}
+
+	return len(keyIDs), nil
+}
+
+func (l *LocalKMS) reEncryptKeySet(keyID string) error {
+	kh, err := l.getKeySet(keyID)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = kh.Write(keyset.NewJSONWriter(buf), l.masterKeyEnvAEAD)
+	if err != nil {
+		return err
+	}
+
+	return l.store.Put(keyID, buf.Bytes())
+}
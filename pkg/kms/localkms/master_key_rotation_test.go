@@ -0,0 +1,176 @@
+/*
+ Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+ SPDX-License-Identifier: Apache-2.0
+*/
+
+package localkms
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+func TestLocalKMS_RotateMasterKey(t *testing.T) {
+	masterKey := []byte(base64.URLEncoding.EncodeToString(random.GetRandomBytes(32)))
+
+	newLock := func(t *testing.T, mk []byte, opts ...local.Opt) secretlock.Service {
+		t.Helper()
+
+		s, err := local.NewService(bytes.NewReader(mk), nil, opts...)
+		require.NoError(t, err)
+
+		return s
+	}
+
+	t.Run("success", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		storeProvider := mockstorage.NewCustomMockStoreProvider(store)
+
+		k, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: newLock(t, masterKey)})
+		require.NoError(t, err)
+
+		signKeyID, _, err := k.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		pubKey, err := k.ExportPubKeyBytes(signKeyID)
+		require.NoError(t, err)
+
+		aeadKeyID, _, err := k.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		var (
+			newMasterKey []byte
+			progress     []MasterKeyRotationProgress
+		)
+
+		n, err := k.RotateMasterKey(func(protectedMasterKey []byte) error {
+			newMasterKey = protectedMasterKey
+			return nil
+		}, WithProgress(func(p *MasterKeyRotationProgress) {
+			progress = append(progress, *p)
+		}))
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Len(t, progress, 2)
+		require.Equal(t, 2, progress[1].Done)
+		require.Equal(t, 2, progress[1].Total)
+		require.ElementsMatch(t, []string{signKeyID, aeadKeyID}, []string{progress[0].KeyID, progress[1].KeyID})
+
+		// keys are readable with the new master key only
+		k, err = New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: newLock(t, newMasterKey)})
+		require.NoError(t, err)
+
+		rotatedPubKey, err := k.ExportPubKeyBytes(signKeyID)
+		require.NoError(t, err)
+		require.Equal(t, pubKey, rotatedPubKey)
+
+		_, err = k.Get(aeadKeyID)
+		require.NoError(t, err)
+
+		k, err = New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: newLock(t, masterKey)})
+		require.NoError(t, err)
+
+		_, err = k.Get(aeadKeyID)
+		require.Error(t, err)
+
+		// key metadata is untouched
+		md, err := k.Describe(aeadKeyID)
+		require.NoError(t, err)
+		require.Equal(t, kms.AES256GCMType, md.KeyType)
+	})
+
+	t.Run("resume interrupted rotation", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+		storeProvider := mockstorage.NewCustomMockStoreProvider(store)
+
+		k, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: newLock(t, masterKey)})
+		require.NoError(t, err)
+
+		keyID, _, err := k.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		var newMasterKey []byte
+
+		store.ErrPut = errors.New("put error")
+
+		_, err = k.RotateMasterKey(func(protectedMasterKey []byte) error {
+			newMasterKey = protectedMasterKey
+			return nil
+		})
+		require.EqualError(t, err, "re-encrypt keys: key "+keyID+": put error")
+
+		store.ErrPut = nil
+
+		// restart with the new master key and the previous one
+		k, err = New(testMasterKeyURI, &mockProvider{
+			storage:    storeProvider,
+			secretLock: newLock(t, newMasterKey, local.WithPreviousMasterKey(bytes.NewReader(masterKey))),
+		})
+		require.NoError(t, err)
+
+		n, err := k.ReEncryptKeys()
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+
+		k, err = New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: newLock(t, newMasterKey)})
+		require.NoError(t, err)
+
+		_, err = k.Get(keyID)
+		require.NoError(t, err)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		k, err := New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: &noop.NoLock{},
+		})
+		require.NoError(t, err)
+
+		_, err = k.RotateMasterKey(func([]byte) error { return nil })
+		require.EqualError(t, err, "rotate master key: secret lock does not support master key rotation")
+
+		k, err = New(testMasterKeyURI, &mockProvider{
+			storage:    mockstorage.NewMockStoreProvider(),
+			secretLock: newLock(t, masterKey),
+		})
+		require.NoError(t, err)
+
+		_, err = k.RotateMasterKey(func([]byte) error { return errors.New("write error") })
+		require.EqualError(t, err, "rotate master key: failed to persist new master key: write error")
+
+		k, err = New(testMasterKeyURI, &mockProvider{
+			storage: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+				ErrItr: errors.New("iterator error"),
+			}),
+			secretLock: newLock(t, masterKey),
+		})
+		require.NoError(t, err)
+
+		_, err = k.ReEncryptKeys()
+		require.EqualError(t, err, "re-encrypt keys: iterator error")
+
+		k, err = New(testMasterKeyURI, &mockProvider{
+			storage: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+				Store: map[string][]byte{"bad": []byte("{")},
+			}),
+			secretLock: newLock(t, masterKey),
+		})
+		require.NoError(t, err)
+
+		_, err = k.ReEncryptKeys()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "re-encrypt keys: key bad")
+	})
+}
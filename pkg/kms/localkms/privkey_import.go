@@ -12,6 +12,7 @@ import (
 	"crypto/ed25519"
 	"fmt"
 
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/golang/protobuf/proto"
 	"github.com/google/tink/go/keyset"
 	commonpb "github.com/google/tink/go/proto/common_go_proto"
@@ -19,18 +20,22 @@ import (
 	ed25519pb "github.com/google/tink/go/proto/ed25519_go_proto"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/secp256k1"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 )
 
 const (
-	ecdsaSignerTypeURL   = "type.googleapis.com/google.crypto.tink.EcdsaPrivateKey"
-	ed25519SignerTypeURL = "type.googleapis.com/google.crypto.tink.Ed25519PrivateKey"
+	ecdsaSignerTypeURL     = "type.googleapis.com/google.crypto.tink.EcdsaPrivateKey"
+	ed25519SignerTypeURL   = "type.googleapis.com/google.crypto.tink.Ed25519PrivateKey"
+	secp256k1SignerTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.Secp256k1EcdsaPrivateKey"
 )
 
 func (l *LocalKMS) importECDSAKey(privKey *ecdsa.PrivateKey, kt kms.KeyType,
 	opts ...kms.PrivateKeyOpts) (string, *keyset.Handle, error) {
 	var params *ecdsapb.EcdsaParams
 
+	typeURL := ecdsaSignerTypeURL
+
 	err := validECPrivateKey(privKey)
 	if err != nil {
 		return "", nil, fmt.Errorf("import private EC key failed: %w", err)
@@ -73,6 +78,14 @@ func (l *LocalKMS) importECDSAKey(privKey *ecdsa.PrivateKey, kt kms.KeyType,
 			Encoding: ecdsapb.EcdsaSignatureEncoding_IEEE_P1363,
 			HashType: commonpb.HashType_SHA512,
 		}
+	case kms.ECDSASecp256k1TypeIEEEP1363:
+		// Tink has no secp256k1 curve, these keys are managed by the secp256k1 primitive key managers
+		if privKey.Curve != btcec.S256() {
+			return "", nil, fmt.Errorf("import private EC key failed: key is not a secp256k1 key")
+		}
+
+		params = secp256k1.Params()
+		typeURL = secp256k1SignerTypeURL
 	default:
 		return "", nil, fmt.Errorf("import private EC key failed: invalid ECDSA key type")
 	}
@@ -82,12 +95,13 @@ func (l *LocalKMS) importECDSAKey(privKey *ecdsa.PrivateKey, kt kms.KeyType,
 		return "", nil, fmt.Errorf("import private EC key failed: %w", err)
 	}
 
-	ks := newKeySet(ecdsaSignerTypeURL, mKeyValue, tinkpb.KeyData_ASYMMETRIC_PRIVATE)
+	ks := newKeySet(typeURL, mKeyValue, tinkpb.KeyData_ASYMMETRIC_PRIVATE)
 
-	return l.importKeySet(ks, opts...)
+	return l.importKeySet(ks, kt, opts...)
 }
 
-func (l *LocalKMS) importKeySet(ks *tinkpb.Keyset, opts ...kms.PrivateKeyOpts) (string, *keyset.Handle, error) {
+func (l *LocalKMS) importKeySet(ks *tinkpb.Keyset, kt kms.KeyType,
+	opts ...kms.PrivateKeyOpts) (string, *keyset.Handle, error) {
 	ksID, err := l.writeImportedKey(ks, opts...)
 	if err != nil {
 		return "", nil, fmt.Errorf("import private EC key failed: %w", err)
@@ -98,6 +112,11 @@ func (l *LocalKMS) importKeySet(ks *tinkpb.Keyset, opts ...kms.PrivateKeyOpts) (
 		return ksID, nil, fmt.Errorf("import private EC key successful but failed to get key from store: %w", err)
 	}
 
+	err = l.indexKey(ksID, kt)
+	if err != nil {
+		return ksID, nil, fmt.Errorf("import private key successful but failed to index key: %w", err)
+	}
+
 	return ksID, kh, nil
 }
 
@@ -128,7 +147,7 @@ func (l *LocalKMS) importEd25519Key(privKey ed25519.PrivateKey, kt kms.KeyType,
 
 	ks := newKeySet(ed25519SignerTypeURL, mKeyValue, tinkpb.KeyData_ASYMMETRIC_PRIVATE)
 
-	return l.importKeySet(ks, opts...)
+	return l.importKeySet(ks, kt, opts...)
 }
 
 func validECPrivateKey(privateKey *ecdsa.PrivateKey) error {
@@ -137,7 +137,7 @@ func TestImportKeySetInvalid(t *testing.T) {
 			k, err := New(testMasterKeyURI, tc.kmsProvider)
 			require.NoError(t, err)
 
-			_, _, err = k.importKeySet(tc.ks)
+			_, _, err = k.importKeySet(tc.ks, kms.ECDSAP256TypeDER)
 			if tc.tcName == "call importKeySet with bad storage getKeySet call" {
 				require.Contains(t, err.Error(), tc.expectedError)
 				return
@@ -12,6 +12,7 @@ import (
 	"crypto/x509"
 	"fmt"
 
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/golang/protobuf/proto"
 	"github.com/google/tink/go/insecurecleartextkeyset"
 	"github.com/google/tink/go/keyset"
@@ -21,6 +22,7 @@ import (
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"github.com/google/tink/go/subtle"
 
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/secp256k1"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 )
 
@@ -140,6 +142,18 @@ func getMarshalledProtoKeyAndKeyURL(pubKey []byte, kt kms.KeyType) ([]byte, stri
 		if err != nil {
 			return nil, "", err
 		}
+	case kms.ECDSASecp256k1TypeIEEEP1363:
+		tURL = secp256k1VerifierTypeURL
+
+		key, e := btcec.ParsePubKey(pubKey, btcec.S256())
+		if e != nil {
+			return nil, "", e
+		}
+
+		keyValue, err = getMarshalledECDSAKey(key.ToECDSA(), secp256k1.Params())
+		if err != nil {
+			return nil, "", err
+		}
 	case kms.ED25519Type:
 		tURL = ed25519VerifierTypeURL
 		pubKeyProto := new(ed25519pb.Ed25519PublicKey)
@@ -14,23 +14,30 @@ import (
 	"io"
 	"math/big"
 
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/golang/protobuf/proto"
 	commonpb "github.com/google/tink/go/proto/common_go_proto"
 	ecdsapb "github.com/google/tink/go/proto/ecdsa_go_proto"
 	ed25519pb "github.com/google/tink/go/proto/ed25519_go_proto"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"github.com/google/tink/go/subtle"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/keyio"
 )
 
 const (
-	ecdsaVerifierTypeURL   = "type.googleapis.com/google.crypto.tink.EcdsaPublicKey"
-	ed25519VerifierTypeURL = "type.googleapis.com/google.crypto.tink.Ed25519PublicKey"
+	ecdsaVerifierTypeURL         = "type.googleapis.com/google.crypto.tink.EcdsaPublicKey"
+	ed25519VerifierTypeURL       = "type.googleapis.com/google.crypto.tink.Ed25519PublicKey"
+	secp256k1VerifierTypeURL     = "type.hyperledger.org/hyperledger.aries.crypto.tink.Secp256k1EcdsaPublicKey"
+	ecdhesAESPublicKeyTypeURL    = "type.hyperledger.org/hyperledger.aries.crypto.tink.EcdhesAesAeadPublicKey"
+	ecdhesX25519PublicKeyTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.EcdhesX25519AeadPublicKey"
 )
 
 // PubKeyWriter will write the raw bytes of a Tink KeySet's primary public key
 // The keyset must be one of the keyURLs defined above
-// Note: Only signing public keys can be exported through this PubKeyWriter.
-// ECHDES has its own Writer to export its public keys due to cyclic dependency.
+// Note: Signing public keys are written as raw bytes. ECDH-ES key agreement public keys (NISTP256ECDHKW,
+// NISTP384ECDHKW, X25519ECDHKW and ECDHES256AES256GCM key types) are written by keyio.PubKeyWriter as a marshaled
+// crypto.PublicKey.
 type PubKeyWriter struct {
 	w io.Writer
 }
@@ -62,11 +69,13 @@ func write(w io.Writer, msg *tinkpb.Keyset) error {
 	for _, key := range ks {
 		if key.KeyId == primaryKID && key.Status == tinkpb.KeyStatusType_ENABLED {
 			switch key.KeyData.TypeUrl {
-			case ecdsaVerifierTypeURL, ed25519VerifierTypeURL:
+			case ecdsaVerifierTypeURL, ed25519VerifierTypeURL, secp256k1VerifierTypeURL:
 				created, err = writePubKey(w, key)
 				if err != nil {
 					return err
 				}
+			case ecdhesAESPublicKeyTypeURL, ecdhesX25519PublicKeyTypeURL:
+				return keyio.NewWriter(w).Write(msg)
 			default:
 				return fmt.Errorf("key type not supported for writing raw key bytes: %s", key.KeyData.TypeUrl)
 			}
@@ -98,6 +107,16 @@ func writePubKey(w io.Writer, key *tinkpb.Keyset_Key) (bool, error) {
 		if err != nil {
 			return false, err
 		}
+	case secp256k1VerifierTypeURL:
+		pubKeyProto := new(ecdsapb.EcdsaPublicKey)
+
+		err := proto.Unmarshal(key.KeyData.Value, pubKeyProto)
+		if err != nil {
+			return false, err
+		}
+
+		marshaledRawPubKey = elliptic.Marshal(btcec.S256(),
+			new(big.Int).SetBytes(pubKeyProto.X), new(big.Int).SetBytes(pubKeyProto.Y))
 	case ed25519VerifierTypeURL:
 		pubKeyProto := new(ed25519pb.Ed25519PublicKey)
 
@@ -0,0 +1,321 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11kms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// PKCS#11 v3.0 Edwards curves constants, not defined by the pkcs11 package.
+const (
+	ckkECEdwards           = 0x00000040
+	ckmECEdwardsKeyPairGen = 0x00001055
+	ckmEdDSA               = 0x00001057
+
+	rsParts = 2
+)
+
+var (
+	oidP256    = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidP384    = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	oidEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+
+	errInvalidSignature = errors.New("invalid signature")
+)
+
+// keyParams are the PKCS#11 parameters of a key type. The hash functions of the ECDSA key types are the same as the
+// localkms ones so that signatures can be verified by any crypto.Crypto implementation.
+type keyParams struct {
+	keyType  uint
+	genMech  uint
+	signMech uint
+	curveOID asn1.ObjectIdentifier
+	// curve and hash are not set for Ed25519 keys
+	curve elliptic.Curve
+	hash  crypto.Hash
+	// der is set for ECDSA key types with ASN.1 DER encoded signatures (IEEE P1363 encoding otherwise)
+	der bool
+}
+
+func getKeyParams(kt kms.KeyType) (*keyParams, error) {
+	switch kt {
+	case kms.ECDSAP256TypeDER:
+		return ecdsaKeyParams(oidP256, elliptic.P256(), crypto.SHA256, true), nil
+	case kms.ECDSAP384TypeDER:
+		return ecdsaKeyParams(oidP384, elliptic.P384(), crypto.SHA512, true), nil
+	case kms.ECDSAP256TypeIEEEP1363:
+		return ecdsaKeyParams(oidP256, elliptic.P256(), crypto.SHA256, false), nil
+	case kms.ECDSAP384TypeIEEEP1363:
+		return ecdsaKeyParams(oidP384, elliptic.P384(), crypto.SHA384, false), nil
+	case kms.ED25519Type:
+		return &keyParams{
+			keyType:  ckkECEdwards,
+			genMech:  ckmECEdwardsKeyPairGen,
+			signMech: ckmEdDSA,
+			curveOID: oidEd25519,
+		}, nil
+	default:
+		return nil, fmt.Errorf("key type '%s' is not supported by PKCS#11 KMS", kt)
+	}
+}
+
+func ecdsaKeyParams(oid asn1.ObjectIdentifier, curve elliptic.Curve, hash crypto.Hash, der bool) *keyParams {
+	return &keyParams{
+		keyType:  pkcs11.CKK_EC,
+		genMech:  pkcs11.CKM_EC_KEY_PAIR_GEN,
+		signMech: pkcs11.CKM_ECDSA,
+		curveOID: oid,
+		curve:    curve,
+		hash:     hash,
+		der:      der,
+	}
+}
+
+// KeyHandle is the opaque handle of a private key stored in a PKCS#11 token. The private key never leaves the token,
+// it is used by pkcs11crypto to sign messages in the token.
+type KeyHandle struct {
+	KeyID   string
+	KeyType kms.KeyType
+	token   *Token
+}
+
+// Sign signs msg with the private key in the token. ECDSA messages are hashed before being sent to the token.
+func (h *KeyHandle) Sign(msg []byte) ([]byte, error) {
+	params, err := getKeyParams(h.KeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	data := msg
+
+	if params.curve != nil {
+		digest := params.hash.New()
+		digest.Write(msg) // nolint: errcheck,gosec // hash.Hash.Write never returns an error
+
+		data = digest.Sum(nil)
+	}
+
+	sig, err := h.token.sign(h.KeyID, params.signMech, data)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs11 sign: %w", err)
+	}
+
+	if params.der {
+		sig, err = ieeeP1363ToDER(sig)
+		if err != nil {
+			return nil, fmt.Errorf("pkcs11 sign: %w", err)
+		}
+	}
+
+	return sig, nil
+}
+
+// PublicKey returns a handle of the public key read from the token.
+func (h *KeyHandle) PublicKey() (*PublicKeyHandle, error) {
+	values, err := h.token.getAttributes(pkcs11.CKO_PUBLIC_KEY, h.KeyID, pkcs11.CKA_EC_POINT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	params, err := getKeyParams(h.KeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.curve == nil {
+		point := unwrapECPoint(values[0], ed25519.PublicKeySize)
+		if len(point) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key in token")
+		}
+
+		return &PublicKeyHandle{KeyType: h.KeyType, PublicKey: ed25519.PublicKey(point)}, nil
+	}
+
+	x, y := elliptic.Unmarshal(params.curve, unwrapECPoint(values[0], 1+rsParts*curveSize(params.curve)))
+	if x == nil {
+		return nil, fmt.Errorf("invalid EC point in token")
+	}
+
+	return &PublicKeyHandle{KeyType: h.KeyType, PublicKey: &ecdsa.PublicKey{Curve: params.curve, X: x, Y: y}}, nil
+}
+
+// PublicKeyHandle is a handle of a public key of a key pair created by the PKCS#11 KMS. Public keys are not bound to
+// the token, signatures are verified in software.
+type PublicKeyHandle struct {
+	KeyType kms.KeyType
+	// PublicKey is either *ecdsa.PublicKey or ed25519.PublicKey
+	PublicKey crypto.PublicKey
+}
+
+// Verify verifies signature of msg with the public key.
+func (h *PublicKeyHandle) Verify(signature, msg []byte) error {
+	params, err := getKeyParams(h.KeyType)
+	if err != nil {
+		return err
+	}
+
+	switch pubKey := h.PublicKey.(type) {
+	case ed25519.PublicKey:
+		if params.curve != nil || !ed25519.Verify(pubKey, msg, signature) {
+			return errInvalidSignature
+		}
+
+		return nil
+	case *ecdsa.PublicKey:
+		if params.curve == nil || pubKey.Curve != params.curve {
+			return fmt.Errorf("public key does not match key type '%s'", h.KeyType)
+		}
+
+		return verifyECDSA(pubKey, params, signature, msg)
+	default:
+		return fmt.Errorf("unsupported public key type %T", h.PublicKey)
+	}
+}
+
+func verifyECDSA(pubKey *ecdsa.PublicKey, params *keyParams, signature, msg []byte) error {
+	var r, s *big.Int
+
+	if params.der {
+		sig := &ecdsaSignature{}
+
+		rest, err := asn1.Unmarshal(signature, sig)
+		if err != nil || len(rest) != 0 {
+			return errInvalidSignature
+		}
+
+		r, s = sig.R, sig.S
+	} else {
+		size := curveSize(params.curve)
+		if len(signature) != rsParts*size {
+			return errInvalidSignature
+		}
+
+		r, s = new(big.Int).SetBytes(signature[:size]), new(big.Int).SetBytes(signature[size:])
+	}
+
+	digest := params.hash.New()
+	digest.Write(msg) // nolint: errcheck,gosec // hash.Hash.Write never returns an error
+
+	if !ecdsa.Verify(pubKey, digest.Sum(nil), r, s) {
+		return errInvalidSignature
+	}
+
+	return nil
+}
+
+// exportPubKeyBytes marshals pubKey with the same format as localkms: PKIX DER for ECDSA DER key types, uncompressed
+// EC point for ECDSA IEEE P1363 key types and raw public key for Ed25519.
+func (h *PublicKeyHandle) exportPubKeyBytes() ([]byte, error) {
+	switch pubKey := h.PublicKey.(type) {
+	case ed25519.PublicKey:
+		return pubKey, nil
+	case *ecdsa.PublicKey:
+		params, err := getKeyParams(h.KeyType)
+		if err != nil {
+			return nil, err
+		}
+
+		if params.der {
+			return x509.MarshalPKIXPublicKey(pubKey)
+		}
+
+		return elliptic.Marshal(pubKey.Curve, pubKey.X, pubKey.Y), nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", h.PublicKey)
+	}
+}
+
+// pubKeyBytesToHandle parses pubKey exported with the format of kt.
+func pubKeyBytesToHandle(pubKey []byte, kt kms.KeyType) (*PublicKeyHandle, error) {
+	if len(pubKey) == 0 {
+		return nil, fmt.Errorf("pubKey is empty")
+	}
+
+	params, err := getKeyParams(kt)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case params.curve == nil:
+		if len(pubKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key size")
+		}
+
+		return &PublicKeyHandle{KeyType: kt, PublicKey: ed25519.PublicKey(pubKey)}, nil
+	case params.der:
+		key, err := x509.ParsePKIXPublicKey(pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecKey.Curve != params.curve {
+			return nil, fmt.Errorf("public key does not match key type '%s'", kt)
+		}
+
+		return &PublicKeyHandle{KeyType: kt, PublicKey: ecKey}, nil
+	default:
+		x, y := elliptic.Unmarshal(params.curve, pubKey)
+		if x == nil {
+			return nil, fmt.Errorf("public key does not match key type '%s'", kt)
+		}
+
+		return &PublicKeyHandle{KeyType: kt, PublicKey: &ecdsa.PublicKey{Curve: params.curve, X: x, Y: y}}, nil
+	}
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// ieeeP1363ToDER converts the r||s signature returned by CKM_ECDSA into an ASN.1 DER signature.
+func ieeeP1363ToDER(sig []byte) ([]byte, error) {
+	if len(sig) == 0 || len(sig)%rsParts != 0 {
+		return nil, fmt.Errorf("invalid ECDSA signature size %d", len(sig))
+	}
+
+	size := len(sig) / rsParts
+
+	return asn1.Marshal(ecdsaSignature{
+		R: new(big.Int).SetBytes(sig[:size]),
+		S: new(big.Int).SetBytes(sig[size:]),
+	})
+}
+
+// unwrapECPoint returns the EC point of a CKA_EC_POINT value. The value should be a DER octet string but some tokens
+// return the raw point of pointSize bytes.
+func unwrapECPoint(value []byte, pointSize int) []byte {
+	if len(value) == pointSize {
+		return value
+	}
+
+	var point []byte
+
+	rest, err := asn1.Unmarshal(value, &point)
+	if err != nil || len(rest) != 0 {
+		return value
+	}
+
+	return point
+}
+
+func curveSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8 // nolint: gomnd
+}
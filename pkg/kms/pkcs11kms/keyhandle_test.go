@@ -0,0 +1,128 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+func TestKeyHandle_Sign(t *testing.T) {
+	k, ctx := newTestKMS(t)
+
+	_, kh, err := k.Create(kms.ECDSAP256TypeDER)
+	require.NoError(t, err)
+
+	t.Run("DER signature", func(t *testing.T) {
+		sig, err := kh.(*KeyHandle).Sign([]byte("msg"))
+		require.NoError(t, err)
+
+		rest, err := asn1.Unmarshal(sig, &ecdsaSignature{})
+		require.NoError(t, err)
+		require.Empty(t, rest)
+	})
+
+	t.Run("token error", func(t *testing.T) {
+		ctx.ErrSign = errors.New("sign error")
+		defer func() { ctx.ErrSign = nil }()
+
+		_, err := kh.(*KeyHandle).Sign([]byte("msg"))
+		require.EqualError(t, err, "pkcs11 sign: sign error")
+	})
+
+	t.Run("unsupported key type", func(t *testing.T) {
+		_, err := (&KeyHandle{KeyType: kms.AES256GCMType}).Sign([]byte("msg"))
+		require.EqualError(t, err, "key type 'AES256GCM' is not supported by PKCS#11 KMS")
+	})
+
+	t.Run("key not found", func(t *testing.T) {
+		_, err := (&KeyHandle{KeyID: "unknown", KeyType: kms.ED25519Type, token: k.token}).Sign([]byte("msg"))
+		require.EqualError(t, err, "pkcs11 sign: key not found")
+	})
+}
+
+func TestPublicKeyHandle_Verify(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	edPubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		handle *PublicKeyHandle
+		sig    []byte
+		err    string
+	}{
+		{
+			name:   "unsupported key type",
+			handle: &PublicKeyHandle{KeyType: kms.AES256GCMType, PublicKey: edPubKey},
+			err:    "key type 'AES256GCM' is not supported by PKCS#11 KMS",
+		},
+		{
+			name:   "Ed25519 key with ECDSA key type",
+			handle: &PublicKeyHandle{KeyType: kms.ECDSAP256TypeDER, PublicKey: edPubKey},
+			err:    "invalid signature",
+		},
+		{
+			name:   "ECDSA key with Ed25519 key type",
+			handle: &PublicKeyHandle{KeyType: kms.ED25519Type, PublicKey: &ecKey.PublicKey},
+			err:    "public key does not match key type 'ED25519'",
+		},
+		{
+			name:   "ECDSA key with another curve",
+			handle: &PublicKeyHandle{KeyType: kms.ECDSAP384TypeDER, PublicKey: &ecKey.PublicKey},
+			err:    "public key does not match key type 'ECDSAP384DER'",
+		},
+		{
+			name:   "invalid DER signature",
+			handle: &PublicKeyHandle{KeyType: kms.ECDSAP256TypeDER, PublicKey: &ecKey.PublicKey},
+			sig:    []byte("sig"),
+			err:    "invalid signature",
+		},
+		{
+			name:   "invalid IEEE P1363 signature size",
+			handle: &PublicKeyHandle{KeyType: kms.ECDSAP256TypeIEEEP1363, PublicKey: &ecKey.PublicKey},
+			sig:    []byte("sig"),
+			err:    "invalid signature",
+		},
+		{
+			name:   "unsupported public key",
+			handle: &PublicKeyHandle{KeyType: kms.ED25519Type, PublicKey: "key"},
+			err:    "unsupported public key type string",
+		},
+	}
+
+	for _, tc := range tests {
+		require.EqualError(t, tc.handle.Verify(tc.sig, []byte("msg")), tc.err, tc.name)
+	}
+}
+
+func TestUnwrapECPoint(t *testing.T) {
+	point := elliptic.Marshal(elliptic.P256(), elliptic.P256().Params().Gx, elliptic.P256().Params().Gy)
+
+	wrapped, err := asn1.Marshal(point)
+	require.NoError(t, err)
+
+	require.Equal(t, point, unwrapECPoint(wrapped, len(point)))
+	require.Equal(t, point, unwrapECPoint(point, len(point)))
+	require.Equal(t, []byte("abc"), unwrapECPoint([]byte("abc"), len(point)))
+}
+
+func TestIEEEP1363ToDER(t *testing.T) {
+	_, err := ieeeP1363ToDER([]byte("abc"))
+	require.EqualError(t, err, "invalid ECDSA signature size 3")
+}
@@ -0,0 +1,255 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/miekg/pkcs11"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// package pkcs11kms provides a kms.KeyManager keeping signing keys inside a PKCS#11 token (HSM, SoftHSM, ...).
+//
+// Keys are created as token objects: a private key object, sensitive and not extractable, and a public key object,
+// both having the key ID as CKA_ID and the KMS key type as CKA_LABEL. Key handles returned by the KMS are opaque
+// *KeyHandle instances, they must be used with the pkcs11crypto package which signs messages inside the token.
+//
+// Supported key types are kms.ECDSAP256TypeDER, kms.ECDSAP384TypeDER, kms.ECDSAP256TypeIEEEP1363,
+// kms.ECDSAP384TypeIEEEP1363 and kms.ED25519Type (requires a token supporting PKCS#11 v3.0 EdDSA mechanisms).
+
+var logger = log.New("aries-framework/kms/pkcs11kms")
+
+const keyIDSize = 16
+
+// KMS is a kms.KeyManager storing keys in a PKCS#11 token.
+type KMS struct {
+	token *Token
+}
+
+// New creates a new PKCS#11 KMS using the keys of token.
+func New(token *Token) *KMS {
+	return &KMS{token: token}
+}
+
+// Create a new key pair of type kt in the token.
+// Returns:
+//  - keyID of the key
+//  - *KeyHandle of the private key
+//  - error if failure
+func (k *KMS) Create(kt kms.KeyType) (string, interface{}, error) {
+	params, err := getKeyParams(kt)
+	if err != nil {
+		return "", nil, fmt.Errorf("create: %w", err)
+	}
+
+	ecParams, err := asn1.Marshal(params.curveOID)
+	if err != nil {
+		return "", nil, fmt.Errorf("create: %w", err)
+	}
+
+	keyID := newKeyID()
+
+	err = k.token.generateKeyPair(params.genMech,
+		append(publicKeyTemplate(keyID, kt, params), pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams)),
+		privateKeyTemplate(keyID, kt, params))
+	if err != nil {
+		return "", nil, fmt.Errorf("create: failed to generate key pair: %w", err)
+	}
+
+	return keyID, &KeyHandle{KeyID: keyID, KeyType: kt, token: k.token}, nil
+}
+
+// Get the handle of the private key keyID.
+// Returns:
+//  - *KeyHandle of the private key
+//  - error if the key is not found or failure
+func (k *KMS) Get(keyID string) (interface{}, error) {
+	values, err := k.token.getAttributes(pkcs11.CKO_PRIVATE_KEY, keyID, pkcs11.CKA_LABEL)
+	if err != nil {
+		return nil, fmt.Errorf("get key '%s': %w", keyID, err)
+	}
+
+	kt := kms.KeyType(values[0])
+
+	_, err = getKeyParams(kt)
+	if err != nil {
+		return nil, fmt.Errorf("get key '%s': %w", keyID, err)
+	}
+
+	return &KeyHandle{KeyID: keyID, KeyType: kt, token: k.token}, nil
+}
+
+// Rotate creates a new key pair of type kt replacing keyID. Keys of a PKCS#11 token can't be grouped in a keyset:
+// the key keyID is not modified, it remains in the token until deleted with the token tools.
+// Returns:
+//  - keyID of the new key
+//  - *KeyHandle of the new private key
+//  - error if keyID is not found or failure
+func (k *KMS) Rotate(kt kms.KeyType, keyID string) (string, interface{}, error) {
+	_, err := k.token.findObject(pkcs11.CKO_PRIVATE_KEY, keyID)
+	if err != nil {
+		return "", nil, fmt.Errorf("rotate key '%s': %w", keyID, err)
+	}
+
+	return k.Create(kt)
+}
+
+// ExportPubKeyBytes reads the public key keyID from the token and returns it with the same format as localkms.
+// Returns:
+//  - marshalled public key []byte
+//  - error if the key is not found or failure
+func (k *KMS) ExportPubKeyBytes(keyID string) ([]byte, error) {
+	kh, err := k.Get(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKH, err := kh.(*KeyHandle).PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("export public key '%s': %w", keyID, err)
+	}
+
+	return pubKH.exportPubKeyBytes()
+}
+
+// PubKeyBytesToHandle transforms pubKey raw bytes into a *PublicKeyHandle of type kt. The handle is not stored in the
+// token, signatures are verified in software.
+// Returns:
+//  - *PublicKeyHandle of type kt
+//  - error if kt is not supported, the key does not match kt or unmarshal fails
+func (k *KMS) PubKeyBytesToHandle(pubKey []byte, kt kms.KeyType) (interface{}, error) {
+	return pubKeyBytesToHandle(pubKey, kt)
+}
+
+// ImportPrivateKey imports privKey into the token as a sensitive and not extractable key.
+// 'privKey' possible types are: *ecdsa.PrivateKey and ed25519.PrivateKey
+// 'kt' possible types are the key types supported by the KMS
+// 'opts' allows setting the key ID of the imported key using kms.WithKeyID() option. If the ID is already used,
+// then an error is returned.
+// Returns:
+//  - keyID of the key
+//  - *KeyHandle of the private key
+//  - error if import failure (key empty, invalid, doesn't match kt, unsupported kt or storing key failed)
+func (k *KMS) ImportPrivateKey(privKey interface{}, kt kms.KeyType,
+	opts ...kms.PrivateKeyOpts) (string, interface{}, error) {
+	params, err := getKeyParams(kt)
+	if err != nil {
+		return "", nil, fmt.Errorf("import private key: %w", err)
+	}
+
+	value, point, err := privateKeyValues(privKey, params)
+	if err != nil {
+		return "", nil, fmt.Errorf("import private key: %w", err)
+	}
+
+	pksOpts := kms.NewOpt()
+
+	for _, opt := range opts {
+		opt(pksOpts)
+	}
+
+	keyID := pksOpts.KsID()
+	if keyID == "" {
+		keyID = newKeyID()
+	} else {
+		_, err = k.token.findObject(pkcs11.CKO_PRIVATE_KEY, keyID)
+		if err == nil {
+			return "", nil, fmt.Errorf("import private key: key '%s' already exists", keyID)
+		}
+
+		if !errors.Is(err, errKeyNotFound) {
+			return "", nil, fmt.Errorf("import private key: %w", err)
+		}
+	}
+
+	ecParams, err := asn1.Marshal(params.curveOID)
+	if err != nil {
+		return "", nil, fmt.Errorf("import private key: %w", err)
+	}
+
+	ecPoint, err := asn1.Marshal(point)
+	if err != nil {
+		return "", nil, fmt.Errorf("import private key: %w", err)
+	}
+
+	err = k.token.createObjects(
+		append(privateKeyTemplate(keyID, kt, params),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
+			pkcs11.NewAttribute(pkcs11.CKA_VALUE, value)),
+		append(publicKeyTemplate(keyID, kt, params),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, ecPoint)))
+	if err != nil {
+		return "", nil, fmt.Errorf("import private key: failed to create key objects: %w", err)
+	}
+
+	return keyID, &KeyHandle{KeyID: keyID, KeyType: kt, token: k.token}, nil
+}
+
+// privateKeyValues returns the CKA_VALUE of privKey and its public EC point.
+func privateKeyValues(privKey interface{}, params *keyParams) ([]byte, []byte, error) {
+	switch pk := privKey.(type) {
+	case *ecdsa.PrivateKey:
+		if pk == nil || pk.D == nil || params.curve == nil || pk.Curve != params.curve {
+			return nil, nil, fmt.Errorf("private key does not match key type")
+		}
+
+		size := curveSize(params.curve)
+		value := make([]byte, size)
+		d := pk.D.Bytes()
+		copy(value[size-len(d):], d)
+
+		return value, elliptic.Marshal(pk.Curve, pk.X, pk.Y), nil
+	case ed25519.PrivateKey:
+		if len(pk) != ed25519.PrivateKeySize || params.curve != nil {
+			return nil, nil, fmt.Errorf("private key does not match key type")
+		}
+
+		return pk.Seed(), pk.Public().(ed25519.PublicKey), nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported private key type %T", privKey)
+	}
+}
+
+func publicKeyTemplate(keyID string, kt kms.KeyType, params *keyParams) []*pkcs11.Attribute {
+	return []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, params.keyType),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(keyID)),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, string(kt)),
+	}
+}
+
+func privateKeyTemplate(keyID string, kt kms.KeyType, params *keyParams) []*pkcs11.Attribute {
+	return []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, params.keyType),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(keyID)),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, string(kt)),
+	}
+}
+
+func newKeyID() string {
+	return base64.RawURLEncoding.EncodeToString(random.GetRandomBytes(keyIDSize))
+}
@@ -0,0 +1,239 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockpkcs11 "github.com/hyperledger/aries-framework-go/pkg/mock/kms/pkcs11kms"
+)
+
+var supportedKeyTypes = []kms.KeyType{
+	kms.ECDSAP256TypeDER,
+	kms.ECDSAP384TypeDER,
+	kms.ECDSAP256TypeIEEEP1363,
+	kms.ECDSAP384TypeIEEEP1363,
+	kms.ED25519Type,
+}
+
+func newTestKMS(t *testing.T) (*KMS, *mockpkcs11.Context) {
+	t.Helper()
+
+	ctx := mockpkcs11.NewContext()
+
+	token, err := NewToken(ctx, "aries", "1234")
+	require.NoError(t, err)
+
+	return New(token), ctx
+}
+
+func TestKMS_Create(t *testing.T) {
+	var _ kms.KeyManager = (*KMS)(nil)
+
+	k, _ := newTestKMS(t)
+
+	for _, kt := range supportedKeyTypes {
+		kt := kt
+
+		t.Run(string(kt), func(t *testing.T) {
+			keyID, kh, err := k.Create(kt)
+			require.NoError(t, err)
+			require.NotEmpty(t, keyID)
+
+			keyHandle, ok := kh.(*KeyHandle)
+			require.True(t, ok)
+			require.Equal(t, keyID, keyHandle.KeyID)
+			require.Equal(t, kt, keyHandle.KeyType)
+
+			kh, err = k.Get(keyID)
+			require.NoError(t, err)
+			require.Equal(t, keyHandle, kh)
+
+			msg := []byte("test message")
+
+			sig, err := keyHandle.Sign(msg)
+			require.NoError(t, err)
+
+			pubKeyBytes, err := k.ExportPubKeyBytes(keyID)
+			require.NoError(t, err)
+
+			pubKH, err := k.PubKeyBytesToHandle(pubKeyBytes, kt)
+			require.NoError(t, err)
+
+			require.NoError(t, pubKH.(*PublicKeyHandle).Verify(sig, msg))
+			require.EqualError(t, pubKH.(*PublicKeyHandle).Verify(sig, []byte("other message")), "invalid signature")
+		})
+	}
+
+	t.Run("unsupported key type", func(t *testing.T) {
+		_, _, err := k.Create(kms.AES256GCMType)
+		require.EqualError(t, err, "create: key type 'AES256GCM' is not supported by PKCS#11 KMS")
+	})
+
+	t.Run("generate key pair error", func(t *testing.T) {
+		k, ctx := newTestKMS(t)
+		ctx.ErrGenerateKeyPair = errors.New("token error")
+
+		_, _, err := k.Create(kms.ED25519Type)
+		require.EqualError(t, err, "create: failed to generate key pair: token error")
+	})
+}
+
+func TestKMS_Get(t *testing.T) {
+	k, ctx := newTestKMS(t)
+
+	t.Run("key not found", func(t *testing.T) {
+		_, err := k.Get("unknown")
+		require.EqualError(t, err, "get key 'unknown': key not found")
+
+		_, err = k.ExportPubKeyBytes("unknown")
+		require.EqualError(t, err, "get key 'unknown': key not found")
+	})
+
+	t.Run("find objects error", func(t *testing.T) {
+		ctx.ErrFindObjects = errors.New("find error")
+		defer func() { ctx.ErrFindObjects = nil }()
+
+		_, err := k.Get("unknown")
+		require.EqualError(t, err, "get key 'unknown': find error")
+	})
+
+	t.Run("read attribute error", func(t *testing.T) {
+		keyID, _, err := k.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		ctx.ErrGetAttributeValue = errors.New("attribute error")
+		defer func() { ctx.ErrGetAttributeValue = nil }()
+
+		_, err = k.Get(keyID)
+		require.EqualError(t, err, "get key '"+keyID+"': attribute error")
+	})
+}
+
+func TestKMS_Rotate(t *testing.T) {
+	k, ctx := newTestKMS(t)
+
+	keyID, _, err := k.Create(kms.ECDSAP256TypeDER)
+	require.NoError(t, err)
+
+	newKeyID, kh, err := k.Rotate(kms.ED25519Type, keyID)
+	require.NoError(t, err)
+	require.NotEqual(t, keyID, newKeyID)
+	require.Equal(t, kms.ED25519Type, kh.(*KeyHandle).KeyType)
+
+	// the rotated key is kept in the token
+	_, err = k.Get(keyID)
+	require.NoError(t, err)
+	require.Equal(t, 4, ctx.Objects())
+
+	_, _, err = k.Rotate(kms.ED25519Type, "unknown")
+	require.EqualError(t, err, "rotate key 'unknown': key not found")
+}
+
+func TestKMS_ImportPrivateKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	edPubKey, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		k, _ := newTestKMS(t)
+
+		keyID, kh, err := k.ImportPrivateKey(ecKey, kms.ECDSAP384TypeIEEEP1363)
+		require.NoError(t, err)
+
+		msg := []byte("test message")
+
+		sig, err := kh.(*KeyHandle).Sign(msg)
+		require.NoError(t, err)
+
+		pubKeyBytes, err := k.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+		require.Equal(t, elliptic.Marshal(elliptic.P384(), ecKey.X, ecKey.Y), pubKeyBytes)
+
+		pubKH := &PublicKeyHandle{KeyType: kms.ECDSAP384TypeIEEEP1363, PublicKey: &ecKey.PublicKey}
+		require.NoError(t, pubKH.Verify(sig, msg))
+
+		keyID, kh, err = k.ImportPrivateKey(edKey, kms.ED25519Type, kms.WithKeyID("ed25519key"))
+		require.NoError(t, err)
+		require.Equal(t, "ed25519key", keyID)
+
+		sig, err = kh.(*KeyHandle).Sign(msg)
+		require.NoError(t, err)
+		require.True(t, ed25519.Verify(edPubKey, msg, sig))
+
+		pubKeyBytes, err = k.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+		require.EqualValues(t, edPubKey, pubKeyBytes)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		k, ctx := newTestKMS(t)
+
+		_, _, err := k.ImportPrivateKey(edKey, kms.ED25519Type, kms.WithKeyID("key1"))
+		require.NoError(t, err)
+
+		_, _, err = k.ImportPrivateKey(edKey, kms.ED25519Type, kms.WithKeyID("key1"))
+		require.EqualError(t, err, "import private key: key 'key1' already exists")
+
+		_, _, err = k.ImportPrivateKey(edKey, kms.AES256GCMType)
+		require.EqualError(t, err, "import private key: key type 'AES256GCM' is not supported by PKCS#11 KMS")
+
+		_, _, err = k.ImportPrivateKey(ecKey, kms.ECDSAP256TypeDER)
+		require.EqualError(t, err, "import private key: private key does not match key type")
+
+		_, _, err = k.ImportPrivateKey(edKey, kms.ECDSAP256TypeDER)
+		require.EqualError(t, err, "import private key: private key does not match key type")
+
+		_, _, err = k.ImportPrivateKey("key", kms.ED25519Type)
+		require.EqualError(t, err, "import private key: unsupported private key type string")
+
+		ctx.ErrCreateObject = errors.New("create error")
+
+		_, _, err = k.ImportPrivateKey(edKey, kms.ED25519Type)
+		require.EqualError(t, err, "import private key: failed to create key objects: create error")
+		require.Equal(t, 2, ctx.Objects())
+	})
+}
+
+func TestKMS_PubKeyBytesToHandle(t *testing.T) {
+	k, _ := newTestKMS(t)
+
+	_, err := k.PubKeyBytesToHandle(nil, kms.ED25519Type)
+	require.EqualError(t, err, "pubKey is empty")
+
+	_, err = k.PubKeyBytesToHandle([]byte("key"), kms.AES256GCMType)
+	require.EqualError(t, err, "key type 'AES256GCM' is not supported by PKCS#11 KMS")
+
+	_, err = k.PubKeyBytesToHandle([]byte("key"), kms.ED25519Type)
+	require.EqualError(t, err, "invalid Ed25519 public key size")
+
+	_, err = k.PubKeyBytesToHandle([]byte("key"), kms.ECDSAP256TypeDER)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to parse public key")
+
+	_, err = k.PubKeyBytesToHandle([]byte("key"), kms.ECDSAP256TypeIEEEP1363)
+	require.EqualError(t, err, "public key does not match key type 'ECDSAP256IEEEP1363'")
+
+	keyID, _, err := k.Create(kms.ECDSAP384TypeDER)
+	require.NoError(t, err)
+
+	pubKeyBytes, err := k.ExportPubKeyBytes(keyID)
+	require.NoError(t, err)
+
+	_, err = k.PubKeyBytesToHandle(pubKeyBytes, kms.ECDSAP256TypeDER)
+	require.EqualError(t, err, "public key does not match key type 'ECDSAP256DER'")
+}
@@ -0,0 +1,88 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// TestSoftHSM runs the KMS against a real PKCS#11 library, it is skipped unless PKCS11_LIB is set, eg:
+//   softhsm2-util --init-token --free --label aries --pin 1234 --so-pin 1234
+//   PKCS11_LIB=/usr/lib/softhsm/libsofthsm2.so go test ./pkg/kms/pkcs11kms/...
+// PKCS11_TOKEN (default "aries") and PKCS11_PIN (default "1234") set the token label and user PIN.
+func TestSoftHSM(t *testing.T) {
+	libPath := os.Getenv("PKCS11_LIB")
+	if libPath == "" {
+		t.Skip("PKCS11_LIB is not set")
+	}
+
+	token, err := OpenToken(libPath, envOrDefault("PKCS11_TOKEN", "aries"), envOrDefault("PKCS11_PIN", "1234"))
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, token.Close())
+	}()
+
+	k := New(token)
+
+	for _, kt := range supportedKeyTypes {
+		kt := kt
+
+		t.Run(string(kt), func(t *testing.T) {
+			keyID, kh, err := k.Create(kt)
+			if kt == kms.ED25519Type && errors.Is(err, pkcs11.Error(pkcs11.CKR_MECHANISM_INVALID)) {
+				t.Skip("EdDSA is not supported by the token")
+			}
+
+			require.NoError(t, err)
+
+			msg := []byte("test message")
+
+			sig, err := kh.(*KeyHandle).Sign(msg)
+			require.NoError(t, err)
+
+			pubKeyBytes, err := k.ExportPubKeyBytes(keyID)
+			require.NoError(t, err)
+
+			pubKH, err := k.PubKeyBytesToHandle(pubKeyBytes, kt)
+			require.NoError(t, err)
+			require.NoError(t, pubKH.(*PublicKeyHandle).Verify(sig, msg))
+		})
+	}
+
+	t.Run("import private key", func(t *testing.T) {
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		_, kh, err := k.ImportPrivateKey(ecKey, kms.ECDSAP256TypeIEEEP1363)
+		require.NoError(t, err)
+
+		sig, err := kh.(*KeyHandle).Sign([]byte("test message"))
+		require.NoError(t, err)
+
+		pubKH := &PublicKeyHandle{KeyType: kms.ECDSAP256TypeIEEEP1363, PublicKey: &ecKey.PublicKey}
+		require.NoError(t, pubKH.Verify(sig, []byte("test message")))
+	})
+}
+
+func envOrDefault(name, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	return defaultValue
+}
@@ -0,0 +1,275 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11kms
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+// Context is the subset of the PKCS#11 library functions used by the PKCS#11 KMS. It is implemented by *pkcs11.Ctx.
+type Context interface {
+	GetSlotList(tokenPresent bool) ([]uint, error)
+	GetTokenInfo(slotID uint) (pkcs11.TokenInfo, error)
+	OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error)
+	CloseSession(sh pkcs11.SessionHandle) error
+	Login(sh pkcs11.SessionHandle, userType uint, pin string) error
+	Logout(sh pkcs11.SessionHandle) error
+	GenerateKeyPair(sh pkcs11.SessionHandle, m []*pkcs11.Mechanism,
+		public, private []*pkcs11.Attribute) (pkcs11.ObjectHandle, pkcs11.ObjectHandle, error)
+	CreateObject(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) (pkcs11.ObjectHandle, error)
+	DestroyObject(sh pkcs11.SessionHandle, oh pkcs11.ObjectHandle) error
+	GetAttributeValue(sh pkcs11.SessionHandle, o pkcs11.ObjectHandle,
+		a []*pkcs11.Attribute) ([]*pkcs11.Attribute, error)
+	FindObjectsInit(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) error
+	FindObjects(sh pkcs11.SessionHandle, max int) ([]pkcs11.ObjectHandle, bool, error)
+	FindObjectsFinal(sh pkcs11.SessionHandle) error
+	SignInit(sh pkcs11.SessionHandle, m []*pkcs11.Mechanism, o pkcs11.ObjectHandle) error
+	Sign(sh pkcs11.SessionHandle, message []byte) ([]byte, error)
+}
+
+// errKeyNotFound is returned when no key object has the requested key ID.
+var errKeyNotFound = errors.New("key not found")
+
+// Token is a logged in session on a PKCS#11 token. Token functions are safe for concurrent use, calls to the token
+// are serialized on the session.
+type Token struct {
+	ctx     Context
+	session pkcs11.SessionHandle
+	closer  func()
+	mutex   sync.Mutex
+}
+
+// OpenToken loads the PKCS#11 library found at libPath (eg: /usr/lib/softhsm/libsofthsm2.so) then opens a session
+// on the token labelled tokenLabel and logs in as user with pin.
+func OpenToken(libPath, tokenLabel, pin string) (*Token, error) {
+	ctx := pkcs11.New(libPath)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 library '%s'", libPath)
+	}
+
+	err := ctx.Initialize()
+	if err != nil {
+		ctx.Destroy()
+
+		return nil, fmt.Errorf("failed to initialize PKCS#11 library: %w", err)
+	}
+
+	closer := func() {
+		err = ctx.Finalize()
+		if err != nil {
+			logger.Warnf("failed to finalize PKCS#11 library: %s", err)
+		}
+
+		ctx.Destroy()
+	}
+
+	t, err := NewToken(ctx, tokenLabel, pin)
+	if err != nil {
+		closer()
+
+		return nil, err
+	}
+
+	t.closer = closer
+
+	return t, nil
+}
+
+// NewToken opens a session on the token labelled tokenLabel of the initialized PKCS#11 context ctx and logs in as
+// user with pin.
+func NewToken(ctx Context, tokenLabel, pin string) (*Token, error) {
+	slot, err := findSlot(ctx, tokenLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session on token '%s': %w", tokenLabel, err)
+	}
+
+	err = ctx.Login(session, pkcs11.CKU_USER, pin)
+	if err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+		closeErr := ctx.CloseSession(session)
+		if closeErr != nil {
+			logger.Warnf("failed to close session: %s", closeErr)
+		}
+
+		return nil, fmt.Errorf("failed to login to token '%s': %w", tokenLabel, err)
+	}
+
+	return &Token{ctx: ctx, session: session}, nil
+}
+
+// Close logs out and closes the token session. The PKCS#11 library is finalized if the token was opened with
+// OpenToken.
+func (t *Token) Close() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	err := t.ctx.Logout(t.session)
+	if err != nil {
+		logger.Warnf("failed to logout: %s", err)
+	}
+
+	err = t.ctx.CloseSession(t.session)
+
+	if t.closer != nil {
+		t.closer()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to close session: %w", err)
+	}
+
+	return nil
+}
+
+func findSlot(ctx Context, tokenLabel string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get PKCS#11 slots: %w", err)
+	}
+
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get PKCS#11 token info: %w", err)
+		}
+
+		if info.Label == tokenLabel {
+			return slot, nil
+		}
+	}
+
+	return 0, fmt.Errorf("token '%s' not found", tokenLabel)
+}
+
+func (t *Token) generateKeyPair(mech uint, public, private []*pkcs11.Attribute) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	_, _, err := t.ctx.GenerateKeyPair(t.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mech, nil)}, public, private)
+
+	return err
+}
+
+// createObjects creates all objects or none of them.
+func (t *Token) createObjects(templates ...[]*pkcs11.Attribute) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var created []pkcs11.ObjectHandle
+
+	for _, template := range templates {
+		h, err := t.ctx.CreateObject(t.session, template)
+		if err != nil {
+			for _, o := range created {
+				destroyErr := t.ctx.DestroyObject(t.session, o)
+				if destroyErr != nil {
+					logger.Warnf("failed to destroy object: %s", destroyErr)
+				}
+			}
+
+			return err
+		}
+
+		created = append(created, h)
+	}
+
+	return nil
+}
+
+// findObject returns the object of class with the key ID keyID.
+func (t *Token) findObject(class uint, keyID string) (pkcs11.ObjectHandle, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.findObjectUnsafe(class, keyID)
+}
+
+func (t *Token) findObjectUnsafe(class uint, keyID string) (pkcs11.ObjectHandle, error) {
+	err := t.ctx.FindObjectsInit(t.session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(keyID)),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	objects, _, err := t.ctx.FindObjects(t.session, 1)
+
+	finalErr := t.ctx.FindObjectsFinal(t.session)
+	if finalErr != nil {
+		logger.Warnf("failed to end objects search: %s", finalErr)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(objects) == 0 {
+		return 0, errKeyNotFound
+	}
+
+	return objects[0], nil
+}
+
+// getAttributes reads the attributes of types attrTypes of the object of class with the key ID keyID.
+func (t *Token) getAttributes(class uint, keyID string, attrTypes ...uint) ([][]byte, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	o, err := t.findObjectUnsafe(class, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	template := make([]*pkcs11.Attribute, len(attrTypes))
+	for i, attrType := range attrTypes {
+		template[i] = pkcs11.NewAttribute(attrType, nil)
+	}
+
+	attrs, err := t.ctx.GetAttributeValue(t.session, o, template)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([][]byte, len(attrTypes))
+
+	for i, attrType := range attrTypes {
+		for _, attr := range attrs {
+			if attr.Type == attrType {
+				values[i] = attr.Value
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// sign signs data with the private key keyID and the signature mechanism mech.
+func (t *Token) sign(keyID string, mech uint, data []byte) ([]byte, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	o, err := t.findObjectUnsafe(pkcs11.CKO_PRIVATE_KEY, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = t.ctx.SignInit(t.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mech, nil)}, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.ctx.Sign(t.session, data)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11kms
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/require"
+
+	mockpkcs11 "github.com/hyperledger/aries-framework-go/pkg/mock/kms/pkcs11kms"
+)
+
+func TestNewToken(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		token, err := NewToken(mockpkcs11.NewContext(), "aries", "1234")
+		require.NoError(t, err)
+		require.NoError(t, token.Close())
+	})
+
+	t.Run("token not found", func(t *testing.T) {
+		_, err := NewToken(mockpkcs11.NewContext(), "other", "1234")
+		require.EqualError(t, err, "token 'other' not found")
+	})
+
+	t.Run("wrong PIN", func(t *testing.T) {
+		_, err := NewToken(mockpkcs11.NewContext(), "aries", "4321")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, pkcs11.Error(pkcs11.CKR_PIN_INCORRECT)))
+	})
+
+	t.Run("user already logged in", func(t *testing.T) {
+		ctx := mockpkcs11.NewContext()
+		ctx.ErrLogin = pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)
+
+		_, err := NewToken(ctx, "aries", "1234")
+		require.NoError(t, err)
+	})
+
+	t.Run("PKCS#11 errors", func(t *testing.T) {
+		ctx := mockpkcs11.NewContext()
+		ctx.ErrGetSlotList = errors.New("slot error")
+
+		_, err := NewToken(ctx, "aries", "1234")
+		require.EqualError(t, err, "failed to get PKCS#11 slots: slot error")
+
+		ctx = mockpkcs11.NewContext()
+		ctx.ErrOpenSession = errors.New("session error")
+
+		_, err = NewToken(ctx, "aries", "1234")
+		require.EqualError(t, err, "failed to open session on token 'aries': session error")
+	})
+}
+
+func TestOpenToken(t *testing.T) {
+	_, err := OpenToken("/invalid/libpkcs11.so", "aries", "1234")
+	require.EqualError(t, err, "failed to load PKCS#11 library '/invalid/libpkcs11.so'")
+}
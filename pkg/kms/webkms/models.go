@@ -0,0 +1,111 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webkms
+
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+)
+
+// CreateKeyReq is the request body of create key and rotate key calls.
+type CreateKeyReq struct {
+	KeyType string `json:"keyType"`
+}
+
+// KeyResp is the response body of create key, get key and rotate key calls.
+type KeyResp struct {
+	KeyID string `json:"keyID"`
+}
+
+// ExportKeyResp is the response body of export public key calls.
+type ExportKeyResp struct {
+	PublicKey []byte `json:"publicKey"`
+}
+
+// SignReq is the request body of sign calls.
+type SignReq struct {
+	Message []byte `json:"message"`
+}
+
+// SignResp is the response body of sign calls.
+type SignResp struct {
+	Signature []byte `json:"signature"`
+}
+
+// VerifyReq is the request body of verify calls.
+type VerifyReq struct {
+	Signature []byte `json:"signature"`
+	Message   []byte `json:"message"`
+}
+
+// EncryptReq is the request body of encrypt calls.
+type EncryptReq struct {
+	Message        []byte `json:"message"`
+	AdditionalData []byte `json:"aad,omitempty"`
+}
+
+// EncryptResp is the response body of encrypt calls.
+type EncryptResp struct {
+	CipherText []byte `json:"cipherText"`
+	Nonce      []byte `json:"nonce"`
+}
+
+// DecryptReq is the request body of decrypt calls.
+type DecryptReq struct {
+	CipherText     []byte `json:"cipherText"`
+	AdditionalData []byte `json:"aad,omitempty"`
+	Nonce          []byte `json:"nonce"`
+}
+
+// DecryptResp is the response body of decrypt calls.
+type DecryptResp struct {
+	PlainText []byte `json:"plainText"`
+}
+
+// ComputeMACReq is the request body of compute MAC calls.
+type ComputeMACReq struct {
+	Data []byte `json:"data"`
+}
+
+// ComputeMACResp is the response body of compute MAC calls.
+type ComputeMACResp struct {
+	MAC []byte `json:"mac"`
+}
+
+// VerifyMACReq is the request body of verify MAC calls.
+type VerifyMACReq struct {
+	MAC  []byte `json:"mac"`
+	Data []byte `json:"data"`
+}
+
+// WrapKeyReq is the request body of wrap key calls.
+type WrapKeyReq struct {
+	CEK             []byte            `json:"cek"`
+	APU             []byte            `json:"apu,omitempty"`
+	APV             []byte            `json:"apv,omitempty"`
+	RecipientPubKey *crypto.PublicKey `json:"recipientPublicKey"`
+}
+
+// WrapKeyResp is the response body of wrap key calls.
+type WrapKeyResp struct {
+	WrappedKey *crypto.RecipientWrappedKey `json:"wrappedKey"`
+}
+
+// UnwrapKeyReq is the request body of unwrap key calls.
+type UnwrapKeyReq struct {
+	WrappedKey   *crypto.RecipientWrappedKey `json:"wrappedKey"`
+	SenderPubKey *crypto.PublicKey           `json:"senderPublicKey,omitempty"`
+}
+
+// UnwrapKeyResp is the response body of unwrap key calls.
+type UnwrapKeyResp struct {
+	Key []byte `json:"key"`
+}
+
+// ErrorResp is the response body sent by the server when a call fails.
+type ErrorResp struct {
+	Message string `json:"errMessage"`
+}
@@ -0,0 +1,337 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webkms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/gorilla/mux"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+const keyIDVar = "keyID"
+
+// Server is the reference web KMS server. It exposes the web KMS HTTP API on top of a kms.KeyManager (eg. localkms)
+// and a crypto.Crypto (eg. tinkcrypto) instances. Server is an http.Handler and can be run in-process with
+// httptest.NewServer().
+type Server struct {
+	router *mux.Router
+	km     kms.KeyManager
+	crypto crypto.Crypto
+}
+
+// NewServer creates a new web KMS server executing key operations with km and crypto operations with c.
+func NewServer(km kms.KeyManager, c crypto.Crypto) *Server {
+	s := &Server{router: mux.NewRouter(), km: km, crypto: c}
+
+	keyPath := KeysPath + "/{" + keyIDVar + "}"
+
+	s.router.HandleFunc(KeysPath, s.createKey).Methods(http.MethodPost)
+	s.router.HandleFunc(WrapPath, s.wrapKey).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath, s.getKey).Methods(http.MethodGet)
+	s.router.HandleFunc(keyPath+ExportPath, s.exportPubKey).Methods(http.MethodGet)
+	s.router.HandleFunc(keyPath+RotatePath, s.rotateKey).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath+SignPath, s.sign).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath+VerifyPath, s.verify).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath+EncryptPath, s.encrypt).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath+DecryptPath, s.decrypt).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath+ComputeMACPath, s.computeMAC).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath+VerifyMACPath, s.verifyMAC).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath+KeyWrapPath, s.wrapKey).Methods(http.MethodPost)
+	s.router.HandleFunc(keyPath+UnwrapPath, s.unwrapKey).Methods(http.MethodPost)
+
+	return s
+}
+
+// ServeHTTP dispatches the request to the web KMS API handlers.
+func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.router.ServeHTTP(rw, req)
+}
+
+func (s *Server) createKey(rw http.ResponseWriter, req *http.Request) {
+	var request CreateKeyReq
+
+	if !readRequest(rw, req, &request) {
+		return
+	}
+
+	keyID, _, err := s.km.Create(kms.KeyType(request.KeyType))
+	if err != nil {
+		sendError(rw, http.StatusInternalServerError, fmt.Errorf("create key: %w", err))
+		return
+	}
+
+	sendResponse(rw, &KeyResp{KeyID: keyID})
+}
+
+func (s *Server) getKey(rw http.ResponseWriter, req *http.Request) {
+	keyID := mux.Vars(req)[keyIDVar]
+
+	if _, ok := s.getKeyHandle(rw, req); !ok {
+		return
+	}
+
+	sendResponse(rw, &KeyResp{KeyID: keyID})
+}
+
+func (s *Server) rotateKey(rw http.ResponseWriter, req *http.Request) {
+	var request CreateKeyReq
+
+	if !readRequest(rw, req, &request) {
+		return
+	}
+
+	keyID, _, err := s.km.Rotate(kms.KeyType(request.KeyType), mux.Vars(req)[keyIDVar])
+	if err != nil {
+		sendError(rw, http.StatusInternalServerError, fmt.Errorf("rotate key: %w", err))
+		return
+	}
+
+	sendResponse(rw, &KeyResp{KeyID: keyID})
+}
+
+func (s *Server) exportPubKey(rw http.ResponseWriter, req *http.Request) {
+	pubKey, err := s.km.ExportPubKeyBytes(mux.Vars(req)[keyIDVar])
+	if err != nil {
+		sendError(rw, http.StatusInternalServerError, fmt.Errorf("export public key: %w", err))
+		return
+	}
+
+	sendResponse(rw, &ExportKeyResp{PublicKey: pubKey})
+}
+
+func (s *Server) sign(rw http.ResponseWriter, req *http.Request) {
+	var request SignReq
+
+	kh, ok := s.readKeyRequest(rw, req, &request)
+	if !ok {
+		return
+	}
+
+	sig, err := s.crypto.Sign(request.Message, kh)
+	if err != nil {
+		sendError(rw, http.StatusInternalServerError, fmt.Errorf("sign: %w", err))
+		return
+	}
+
+	sendResponse(rw, &SignResp{Signature: sig})
+}
+
+func (s *Server) verify(rw http.ResponseWriter, req *http.Request) {
+	var request VerifyReq
+
+	kh, ok := s.readKeyRequest(rw, req, &request)
+	if !ok {
+		return
+	}
+
+	err := s.crypto.Verify(request.Signature, request.Message, publicKeyHandle(kh))
+	if err != nil {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("verify: %w", err))
+		return
+	}
+
+	sendResponse(rw, nil)
+}
+
+func (s *Server) encrypt(rw http.ResponseWriter, req *http.Request) {
+	var request EncryptReq
+
+	kh, ok := s.readKeyRequest(rw, req, &request)
+	if !ok {
+		return
+	}
+
+	cipherText, nonce, err := s.crypto.Encrypt(request.Message, request.AdditionalData, kh)
+	if err != nil {
+		sendError(rw, http.StatusInternalServerError, fmt.Errorf("encrypt: %w", err))
+		return
+	}
+
+	sendResponse(rw, &EncryptResp{CipherText: cipherText, Nonce: nonce})
+}
+
+func (s *Server) decrypt(rw http.ResponseWriter, req *http.Request) {
+	var request DecryptReq
+
+	kh, ok := s.readKeyRequest(rw, req, &request)
+	if !ok {
+		return
+	}
+
+	// nonce is passed before aad as done by tinkcrypto and the framework callers
+	plainText, err := s.crypto.Decrypt(request.CipherText, request.Nonce, request.AdditionalData, kh)
+	if err != nil {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("decrypt: %w", err))
+		return
+	}
+
+	sendResponse(rw, &DecryptResp{PlainText: plainText})
+}
+
+func (s *Server) computeMAC(rw http.ResponseWriter, req *http.Request) {
+	var request ComputeMACReq
+
+	kh, ok := s.readKeyRequest(rw, req, &request)
+	if !ok {
+		return
+	}
+
+	mac, err := s.crypto.ComputeMAC(request.Data, kh)
+	if err != nil {
+		sendError(rw, http.StatusInternalServerError, fmt.Errorf("compute MAC: %w", err))
+		return
+	}
+
+	sendResponse(rw, &ComputeMACResp{MAC: mac})
+}
+
+func (s *Server) verifyMAC(rw http.ResponseWriter, req *http.Request) {
+	var request VerifyMACReq
+
+	kh, ok := s.readKeyRequest(rw, req, &request)
+	if !ok {
+		return
+	}
+
+	err := s.crypto.VerifyMAC(request.MAC, request.Data, kh)
+	if err != nil {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("verify MAC: %w", err))
+		return
+	}
+
+	sendResponse(rw, nil)
+}
+
+// wrapKey serves both ECDH-ES key wrapping (no sender key in the path) and ECDH-1PU key wrapping (sender key in the
+// path).
+func (s *Server) wrapKey(rw http.ResponseWriter, req *http.Request) {
+	var request WrapKeyReq
+
+	if !readRequest(rw, req, &request) {
+		return
+	}
+
+	var opts []crypto.WrapKeyOpts
+
+	if _, ok := mux.Vars(req)[keyIDVar]; ok {
+		senderKH, ok := s.getKeyHandle(rw, req)
+		if !ok {
+			return
+		}
+
+		opts = append(opts, crypto.WithSender(senderKH))
+	}
+
+	wrappedKey, err := s.crypto.WrapKey(request.CEK, request.APU, request.APV, request.RecipientPubKey, opts...)
+	if err != nil {
+		sendError(rw, http.StatusInternalServerError, fmt.Errorf("wrap key: %w", err))
+		return
+	}
+
+	sendResponse(rw, &WrapKeyResp{WrappedKey: wrappedKey})
+}
+
+func (s *Server) unwrapKey(rw http.ResponseWriter, req *http.Request) {
+	var request UnwrapKeyReq
+
+	kh, ok := s.readKeyRequest(rw, req, &request)
+	if !ok {
+		return
+	}
+
+	var opts []crypto.WrapKeyOpts
+
+	if request.SenderPubKey != nil {
+		opts = append(opts, crypto.WithSender(request.SenderPubKey))
+	}
+
+	key, err := s.crypto.UnwrapKey(request.WrappedKey, kh, opts...)
+	if err != nil {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("unwrap key: %w", err))
+		return
+	}
+
+	sendResponse(rw, &UnwrapKeyResp{Key: key})
+}
+
+// publicKeyHandle returns the public key handle of kh if it is a Tink keyset handle. Tink verifiers require public
+// key handles.
+func publicKeyHandle(kh interface{}) interface{} {
+	if keyHandle, ok := kh.(*keyset.Handle); ok {
+		pubKH, err := keyHandle.Public()
+		if err == nil {
+			return pubKH
+		}
+	}
+
+	return kh
+}
+
+// readKeyRequest decodes the request body and fetches the handle of the key referenced in the request path.
+func (s *Server) readKeyRequest(rw http.ResponseWriter, req *http.Request, request interface{}) (interface{}, bool) {
+	if !readRequest(rw, req, request) {
+		return nil, false
+	}
+
+	return s.getKeyHandle(rw, req)
+}
+
+func (s *Server) getKeyHandle(rw http.ResponseWriter, req *http.Request) (interface{}, bool) {
+	kh, err := s.km.Get(mux.Vars(req)[keyIDVar])
+	if err != nil {
+		sendError(rw, http.StatusNotFound, fmt.Errorf("get key: %w", err))
+		return nil, false
+	}
+
+	return kh, true
+}
+
+func readRequest(rw http.ResponseWriter, req *http.Request, request interface{}) bool {
+	if req.Body == nil {
+		sendError(rw, http.StatusBadRequest, errors.New("missing request body"))
+		return false
+	}
+
+	err := json.NewDecoder(req.Body).Decode(request)
+	if err != nil {
+		sendError(rw, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return false
+	}
+
+	return true
+}
+
+func sendResponse(rw http.ResponseWriter, resp interface{}) {
+	rw.Header().Set("Content-Type", contentType)
+
+	if resp == nil {
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	err := json.NewEncoder(rw).Encode(resp)
+	if err != nil {
+		logger.Errorf("Unable to send response, %s", err)
+	}
+}
+
+func sendError(rw http.ResponseWriter, status int, err error) {
+	rw.Header().Set("Content-Type", contentType)
+	rw.WriteHeader(status)
+
+	e := json.NewEncoder(rw).Encode(&ErrorResp{Message: err.Error()})
+	if e != nil {
+		logger.Errorf("Unable to send error response, %s", e)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webkms
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+func TestServer(t *testing.T) {
+	localKMS := newLocalKMS(t)
+	srv := NewServer(localKMS, newCrypto(t))
+
+	signKeyID, _, err := localKMS.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	aeadKeyID, _, err := localKMS.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	t.Run("invalid request body", func(t *testing.T) {
+		for _, path := range []string{
+			KeysPath, WrapPath, KeysPath + "/" + signKeyID + RotatePath, KeysPath + "/" + signKeyID + SignPath,
+		} {
+			rr := serve(t, srv, http.MethodPost, path, []byte("{"))
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.Contains(t, errMessage(t, rr), "invalid request")
+		}
+
+		req := httptest.NewRequest(http.MethodPost, KeysPath, nil)
+		req.Body = nil
+
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, "missing request body", errMessage(t, rr))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		for _, path := range []string{SignPath, VerifyPath, EncryptPath, DecryptPath, ComputeMACPath,
+			VerifyMACPath, KeyWrapPath, UnwrapPath} {
+			rr := serve(t, srv, http.MethodPost, KeysPath+"/unknown"+path, []byte("{}"))
+			require.Equal(t, http.StatusNotFound, rr.Code, path)
+			require.Contains(t, errMessage(t, rr), "get key")
+		}
+	})
+
+	t.Run("crypto operation failures", func(t *testing.T) {
+		tests := []struct {
+			keyID  string
+			path   string
+			status int
+			err    string
+		}{
+			{keyID: aeadKeyID, path: SignPath, status: http.StatusInternalServerError, err: "sign"},
+			{keyID: aeadKeyID, path: VerifyPath, status: http.StatusBadRequest, err: "verify"},
+			{keyID: signKeyID, path: EncryptPath, status: http.StatusInternalServerError, err: "encrypt"},
+			{keyID: aeadKeyID, path: DecryptPath, status: http.StatusBadRequest, err: "decrypt"},
+			{keyID: signKeyID, path: ComputeMACPath, status: http.StatusInternalServerError, err: "compute MAC"},
+			{keyID: signKeyID, path: VerifyMACPath, status: http.StatusBadRequest, err: "verify MAC"},
+			{keyID: signKeyID, path: KeyWrapPath, status: http.StatusInternalServerError, err: "wrap key"},
+			{keyID: signKeyID, path: UnwrapPath, status: http.StatusBadRequest, err: "unwrap key"},
+		}
+
+		for _, tc := range tests {
+			rr := serve(t, srv, http.MethodPost, KeysPath+"/"+tc.keyID+tc.path, []byte("{}"))
+			require.Equal(t, tc.status, rr.Code, tc.path)
+			require.Contains(t, errMessage(t, rr), tc.err+":")
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rr := serve(t, srv, http.MethodDelete, KeysPath+"/"+signKeyID, nil)
+		require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	})
+}
+
+func serve(t *testing.T, srv *Server, method, path string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, httptest.NewRequest(method, path, bytes.NewReader(body)))
+
+	return rr
+}
+
+func errMessage(t *testing.T, rr *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	errResp := &ErrorResp{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), errResp))
+
+	return errResp.Message
+}
@@ -0,0 +1,258 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webkms
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+var logger = log.New("aries-framework/kms/webkms")
+
+const (
+	// KeysPath is the path of the keys collection relative to the server base URL.
+	KeysPath = "/kms/keys"
+	// WrapPath is the path of the ECDH-ES key wrapping endpoint relative to the server base URL.
+	WrapPath = "/kms/wrap"
+
+	// ExportPath is the export public key path relative to a key URL.
+	ExportPath = "/export"
+	// RotatePath is the rotate key path relative to a key URL.
+	RotatePath = "/rotate"
+	// SignPath is the sign path relative to a key URL.
+	SignPath = "/sign"
+	// VerifyPath is the verify path relative to a key URL.
+	VerifyPath = "/verify"
+	// EncryptPath is the encrypt path relative to a key URL.
+	EncryptPath = "/encrypt"
+	// DecryptPath is the decrypt path relative to a key URL.
+	DecryptPath = "/decrypt"
+	// ComputeMACPath is the compute MAC path relative to a key URL.
+	ComputeMACPath = "/computemac"
+	// VerifyMACPath is the verify MAC path relative to a key URL.
+	VerifyMACPath = "/verifymac"
+	// KeyWrapPath is the ECDH-1PU key wrapping path relative to the sender key URL.
+	KeyWrapPath = "/wrap"
+	// UnwrapPath is the unwrap key path relative to a key URL.
+	UnwrapPath = "/unwrap"
+
+	contentType = "application/json"
+)
+
+// KeyHandle is the key handle of a key managed by a remote KMS. It holds the URL of the key which is used by
+// webcrypto to execute crypto operations remotely.
+type KeyHandle struct {
+	KeyID  string
+	KeyURL string
+}
+
+// RemoteKMS is a kms.KeyManager client of a remote KMS server exposing the web KMS HTTP API.
+type RemoteKMS struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Opt configures the RemoteKMS client.
+type Opt func(r *RemoteKMS)
+
+// WithHTTPClient option is for setting a custom HTTP client used to call the remote KMS server.
+func WithHTTPClient(client *http.Client) Opt {
+	return func(r *RemoteKMS) {
+		r.httpClient = client
+	}
+}
+
+// WithTimeout option is for definition of HTTP(s) timeout value of the remote KMS calls.
+func WithTimeout(timeout time.Duration) Opt {
+	return func(r *RemoteKMS) {
+		r.httpClient.Timeout = timeout
+	}
+}
+
+// WithTLSConfig option is for definition of secured HTTP transport using a tls.Config instance.
+func WithTLSConfig(tlsConfig *tls.Config) Opt {
+	return func(r *RemoteKMS) {
+		r.httpClient.Transport = &http.Transport{
+			TLSClientConfig: tlsConfig,
+		}
+	}
+}
+
+// New creates a new RemoteKMS client of the web KMS server located at baseURL.
+func New(baseURL string, opts ...Opt) (*RemoteKMS, error) {
+	_, err := url.ParseRequestURI(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("base URL invalid: %w", err)
+	}
+
+	r := &RemoteKMS{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: &http.Client{}}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+// Create a new key of type kt in the remote KMS.
+// Returns:
+//  - keyID of the new key
+//  - *KeyHandle referencing the remote key
+//  - error if failure
+func (r *RemoteKMS) Create(kt kms.KeyType) (string, interface{}, error) {
+	if kt == "" {
+		return "", nil, errors.New("failed to create new key, missing key type")
+	}
+
+	resp := &KeyResp{}
+
+	err := DoRequest(r.httpClient, http.MethodPost, r.baseURL+KeysPath, &CreateKeyReq{KeyType: string(kt)}, resp)
+	if err != nil {
+		return "", nil, fmt.Errorf("create key: %w", err)
+	}
+
+	return resp.KeyID, r.keyHandle(resp.KeyID), nil
+}
+
+// Get the key handle of the remote key referenced by keyID. An error is returned if the key does not exist.
+func (r *RemoteKMS) Get(keyID string) (interface{}, error) {
+	resp := &KeyResp{}
+
+	err := DoRequest(r.httpClient, http.MethodGet, r.keyURL(keyID), nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("get key: %w", err)
+	}
+
+	return r.keyHandle(resp.KeyID), nil
+}
+
+// Rotate the remote key referenced by keyID with a new key of type kt.
+// Returns:
+//  - new keyID
+//  - *KeyHandle referencing the rotated remote key
+//  - error if failure
+func (r *RemoteKMS) Rotate(kt kms.KeyType, keyID string) (string, interface{}, error) {
+	resp := &KeyResp{}
+
+	err := DoRequest(r.httpClient, http.MethodPost, r.keyURL(keyID)+RotatePath, &CreateKeyReq{KeyType: string(kt)},
+		resp)
+	if err != nil {
+		return "", nil, fmt.Errorf("rotate key: %w", err)
+	}
+
+	return resp.KeyID, r.keyHandle(resp.KeyID), nil
+}
+
+// ExportPubKeyBytes will fetch the public key bytes of the remote key referenced by keyID.
+func (r *RemoteKMS) ExportPubKeyBytes(keyID string) ([]byte, error) {
+	resp := &ExportKeyResp{}
+
+	err := DoRequest(r.httpClient, http.MethodGet, r.keyURL(keyID)+ExportPath, nil, resp)
+	if err != nil {
+		return nil, fmt.Errorf("export public key: %w", err)
+	}
+
+	return resp.PublicKey, nil
+}
+
+// PubKeyBytesToHandle is not supported by the remote KMS, public key handles are local objects and should be
+// created with a local KMS.
+func (r *RemoteKMS) PubKeyBytesToHandle(pubKey []byte, kt kms.KeyType) (interface{}, error) {
+	return nil, errors.New("pubKeyBytesToHandle is not supported by remote KMS")
+}
+
+// ImportPrivateKey is not supported by the remote KMS, private keys must never leave the remote KMS server.
+func (r *RemoteKMS) ImportPrivateKey(privKey interface{}, kt kms.KeyType,
+	opts ...kms.PrivateKeyOpts) (string, interface{}, error) {
+	return "", nil, errors.New("importPrivateKey is not supported by remote KMS")
+}
+
+func (r *RemoteKMS) keyURL(keyID string) string {
+	return r.baseURL + KeysPath + "/" + url.PathEscape(keyID)
+}
+
+func (r *RemoteKMS) keyHandle(keyID string) *KeyHandle {
+	return &KeyHandle{KeyID: keyID, KeyURL: r.keyURL(keyID)}
+}
+
+// DoRequest sends an HTTP request with the JSON encoded body req (if not nil) to the web KMS server and decodes
+// the JSON response into resp (if not nil). Error responses of the server are returned as errors.
+func DoRequest(client *http.Client, method, reqURL string, req, resp interface{}) error {
+	var body io.Reader
+
+	if req != nil {
+		reqBytes, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+
+		body = bytes.NewReader(reqBytes)
+	}
+
+	httpReq, err := http.NewRequest(method, reqURL, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	httpReq.Header.Set("Accept", contentType)
+
+	if body != nil {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+
+	defer closeResponseBody(httpResp.Body)
+
+	respBytes, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		errResp := &ErrorResp{}
+
+		if e := json.Unmarshal(respBytes, errResp); e != nil || errResp.Message == "" {
+			return fmt.Errorf("remote KMS server responded with status %d: %s", httpResp.StatusCode, respBytes)
+		}
+
+		return fmt.Errorf("remote KMS server responded with status %d: %s", httpResp.StatusCode, errResp.Message)
+	}
+
+	if resp == nil {
+		return nil
+	}
+
+	err = json.Unmarshal(respBytes, resp)
+	if err != nil {
+		return fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+func closeResponseBody(respBody io.Closer) {
+	e := respBody.Close()
+	if e != nil {
+		logger.Errorf("Failed to close response body: %v", e)
+	}
+}
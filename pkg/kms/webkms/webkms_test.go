@@ -0,0 +1,161 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webkms
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		client := &http.Client{}
+
+		r, err := New("https://kms.example.com/", WithHTTPClient(client), WithTimeout(time.Second),
+			WithTLSConfig(nil))
+		require.NoError(t, err)
+		require.Equal(t, "https://kms.example.com", r.baseURL)
+		require.Equal(t, client, r.httpClient)
+		require.Equal(t, time.Second, r.httpClient.Timeout)
+	})
+
+	t.Run("invalid base URL", func(t *testing.T) {
+		_, err := New("kms")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "base URL invalid")
+	})
+}
+
+func TestRemoteKMS(t *testing.T) {
+	srv := httptest.NewServer(NewServer(newLocalKMS(t), newCrypto(t)))
+	defer srv.Close()
+
+	r, err := New(srv.URL)
+	require.NoError(t, err)
+
+	t.Run("create, get, export and rotate key", func(t *testing.T) {
+		keyID, kh, err := r.Create(kms.ED25519Type)
+		require.NoError(t, err)
+		require.NotEmpty(t, keyID)
+		require.Equal(t, &KeyHandle{KeyID: keyID, KeyURL: srv.URL + KeysPath + "/" + keyID}, kh)
+
+		kh2, err := r.Get(keyID)
+		require.NoError(t, err)
+		require.Equal(t, kh, kh2)
+
+		pubKey, err := r.ExportPubKeyBytes(keyID)
+		require.NoError(t, err)
+		require.Len(t, pubKey, 32)
+
+		newKeyID, newKH, err := r.Rotate(kms.ED25519Type, keyID)
+		require.NoError(t, err)
+		require.NotEqual(t, keyID, newKeyID)
+		require.Equal(t, newKeyID, newKH.(*KeyHandle).KeyID)
+	})
+
+	t.Run("create key failures", func(t *testing.T) {
+		_, _, err := r.Create("")
+		require.EqualError(t, err, "failed to create new key, missing key type")
+
+		_, _, err = r.Create("unknown")
+		require.EqualError(t, err,
+			"create key: remote KMS server responded with status 500: create key: key type unrecognized")
+	})
+
+	t.Run("key not found", func(t *testing.T) {
+		_, err := r.Get("unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get key: remote KMS server responded with status 404")
+
+		_, _, err = r.Rotate(kms.ED25519Type, "unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "rotate key: remote KMS server responded with status 500")
+
+		_, err = r.ExportPubKeyBytes("unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "export public key: remote KMS server responded with status 500")
+	})
+
+	t.Run("unsupported functions", func(t *testing.T) {
+		_, err := r.PubKeyBytesToHandle([]byte("key"), kms.ED25519Type)
+		require.EqualError(t, err, "pubKeyBytesToHandle is not supported by remote KMS")
+
+		_, _, err = r.ImportPrivateKey([]byte("key"), kms.ED25519Type)
+		require.EqualError(t, err, "importPrivateKey is not supported by remote KMS")
+	})
+}
+
+func TestDoRequest(t *testing.T) {
+	t.Run("server unreachable", func(t *testing.T) {
+		err := DoRequest(&http.Client{}, http.MethodGet, "http://127.0.0.1:0/kms/keys", nil, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "send request")
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		err := DoRequest(&http.Client{}, http.MethodPost, "http://localhost", make(chan int), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "marshal request")
+
+		err = DoRequest(&http.Client{}, "bad method", "http://localhost", nil, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create request")
+	})
+
+	t.Run("non JSON responses", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodGet {
+				_, err := rw.Write([]byte("not JSON"))
+				require.NoError(t, err)
+
+				return
+			}
+
+			rw.WriteHeader(http.StatusBadGateway)
+			_, err := rw.Write([]byte("bad gateway"))
+			require.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		err := DoRequest(&http.Client{}, http.MethodGet, srv.URL, nil, &KeyResp{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal response")
+
+		err = DoRequest(&http.Client{}, http.MethodPost, srv.URL, nil, &KeyResp{})
+		require.EqualError(t, err, "remote KMS server responded with status 502: bad gateway")
+	})
+}
+
+func newLocalKMS(t *testing.T) *localkms.LocalKMS {
+	t.Helper()
+
+	k, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(storage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	return k
+}
+
+func newCrypto(t *testing.T) *tinkcrypto.Crypto {
+	t.Helper()
+
+	c, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	return c
+}
@@ -0,0 +1,606 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package couchdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+)
+
+var logger = log.New("aries-framework/mock/couchdb")
+
+const (
+	dbPath         = "/{db}"
+	allDocsPath    = dbPath + "/_all_docs"
+	bulkDocsPath   = dbPath + "/_bulk_docs"
+	findPath       = dbPath + "/_find"
+	docPath        = dbPath + "/{docID}"
+	attachmentPath = docPath + "/{attachment}"
+)
+
+// Server is a minimal in-memory CouchDB server implementing the database and document operations used by the
+// CouchDB storage provider, to be used with httptest in tests: database creation, document CRUD with revisions and
+// inline attachments, _all_docs key ranges, _bulk_docs and _find with equality and $exists selectors on (nested)
+// fields, sorted by _id and paginated with bookmarks.
+type Server struct {
+	router *mux.Router
+	dbs    map[string]map[string]*document
+	lock   sync.RWMutex
+}
+
+// document is a stored document, without its reserved fields.
+type document struct {
+	rev         int
+	fields      map[string]json.RawMessage
+	attachments map[string]*attachment
+}
+
+type attachment struct {
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+}
+
+// errorResponse is the body of the error responses, kivik reports the reason.
+type errorResponse struct {
+	Code   string `json:"error"`
+	Reason string `json:"reason"`
+}
+
+// updateResult is the result of the update of a document.
+type updateResult struct {
+	OK     bool   `json:"ok,omitempty"`
+	ID     string `json:"id"`
+	Rev    string `json:"rev,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// statusError is an error response of the server.
+type statusError struct {
+	status int
+	errorResponse
+}
+
+var (
+	errDBNotFound  = &statusError{http.StatusNotFound, errorResponse{"not_found", "Database does not exist."}}
+	errDocNotFound = &statusError{http.StatusNotFound, errorResponse{"not_found", "missing"}}
+	errConflict    = &statusError{http.StatusConflict, errorResponse{"conflict", "Document update conflict."}}
+)
+
+// NewServer returns a new CouchDB server without databases.
+func NewServer() *Server {
+	s := &Server{router: mux.NewRouter().UseEncodedPath(), dbs: make(map[string]map[string]*document)}
+
+	s.router.HandleFunc(dbPath, s.createDB).Methods(http.MethodPut)
+	s.router.HandleFunc(allDocsPath, s.allDocs).Methods(http.MethodGet)
+	s.router.HandleFunc(bulkDocsPath, s.bulkDocs).Methods(http.MethodPost)
+	s.router.HandleFunc(findPath, s.find).Methods(http.MethodPost)
+	s.router.HandleFunc(docPath, s.getDoc).Methods(http.MethodGet)
+	s.router.HandleFunc(docPath, s.putDoc).Methods(http.MethodPut)
+	s.router.HandleFunc(docPath, s.deleteDoc).Methods(http.MethodDelete)
+	s.router.HandleFunc(attachmentPath, s.getAttachment).Methods(http.MethodGet)
+
+	return s
+}
+
+// ServeHTTP serves the CouchDB REST API.
+func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.router.ServeHTTP(rw, req)
+}
+
+func (s *Server) createDB(rw http.ResponseWriter, req *http.Request) {
+	name := mux.Vars(req)["db"]
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.dbs[name]; ok {
+		writeError(rw, &statusError{http.StatusPreconditionFailed,
+			errorResponse{"file_exists", "The database could not be created, the file already exists."}})
+
+		return
+	}
+
+	s.dbs[name] = make(map[string]*document)
+
+	writeResponse(rw, http.StatusCreated, map[string]bool{"ok": true})
+}
+
+func (s *Server) getDoc(rw http.ResponseWriter, req *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	db, id, err := s.docVars(req)
+	if err != nil {
+		writeError(rw, err)
+
+		return
+	}
+
+	doc, ok := db[id]
+	if !ok {
+		writeError(rw, errDocNotFound)
+
+		return
+	}
+
+	rw.Header().Set("ETag", fmt.Sprintf("%q", doc.revision()))
+	writeResponse(rw, http.StatusOK, doc.json(id))
+}
+
+func (s *Server) putDoc(rw http.ResponseWriter, req *http.Request) {
+	var fields map[string]json.RawMessage
+
+	if !readRequest(rw, req, &fields) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	db, id, err := s.docVars(req)
+	if err != nil {
+		writeError(rw, err)
+
+		return
+	}
+
+	rev, err := update(db, id, fields)
+	if err != nil {
+		writeError(rw, err)
+
+		return
+	}
+
+	writeResponse(rw, http.StatusCreated, &updateResult{OK: true, ID: id, Rev: rev})
+}
+
+func (s *Server) deleteDoc(rw http.ResponseWriter, req *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	db, id, err := s.docVars(req)
+	if err != nil {
+		writeError(rw, err)
+
+		return
+	}
+
+	doc, ok := db[id]
+	if !ok {
+		writeError(rw, errDocNotFound)
+
+		return
+	}
+
+	if req.URL.Query().Get("rev") != doc.revision() {
+		writeError(rw, errConflict)
+
+		return
+	}
+
+	delete(db, id)
+
+	rev := fmt.Sprintf("%d-deleted", doc.rev+1)
+
+	rw.Header().Set("ETag", fmt.Sprintf("%q", rev))
+	writeResponse(rw, http.StatusOK, &updateResult{OK: true, ID: id, Rev: rev})
+}
+
+func (s *Server) getAttachment(rw http.ResponseWriter, req *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	db, id, err := s.docVars(req)
+	if err != nil {
+		writeError(rw, err)
+
+		return
+	}
+
+	doc, ok := db[id]
+	if !ok {
+		writeError(rw, errDocNotFound)
+
+		return
+	}
+
+	att, ok := doc.attachments[mux.Vars(req)["attachment"]]
+	if !ok {
+		writeError(rw, errDocNotFound)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", att.ContentType)
+	rw.Header().Set("ETag", fmt.Sprintf("%q", doc.revision()))
+
+	if _, err := rw.Write(att.Data); err != nil {
+		logger.Errorf("failed to write response: %v", err)
+	}
+}
+
+func (s *Server) allDocs(rw http.ResponseWriter, req *http.Request) {
+	var startKey, endKey *string
+
+	for name, key := range map[string]**string{"startkey": &startKey, "endkey": &endKey} {
+		if value := req.URL.Query().Get(name); value != "" {
+			if err := json.Unmarshal([]byte(value), key); err != nil {
+				writeError(rw, &statusError{http.StatusBadRequest, errorResponse{"bad_request", err.Error()}})
+
+				return
+			}
+		}
+	}
+
+	inclusiveEnd := req.URL.Query().Get("inclusive_end") != "false"
+	includeDocs := req.URL.Query().Get("include_docs") == "true"
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	db, ok := s.dbs[mux.Vars(req)["db"]]
+	if !ok {
+		writeError(rw, errDBNotFound)
+
+		return
+	}
+
+	type row struct {
+		ID    string            `json:"id"`
+		Key   string            `json:"key"`
+		Value map[string]string `json:"value"`
+		Doc   interface{}       `json:"doc,omitempty"`
+	}
+
+	rows := []row{}
+
+	for _, id := range sortedIDs(db) {
+		if startKey != nil && id < *startKey {
+			continue
+		}
+
+		if endKey != nil && (id > *endKey || id == *endKey && !inclusiveEnd) {
+			continue
+		}
+
+		r := row{ID: id, Key: id, Value: map[string]string{"rev": db[id].revision()}}
+
+		if includeDocs {
+			r.Doc = db[id].json(id)
+		}
+
+		rows = append(rows, r)
+	}
+
+	writeResponse(rw, http.StatusOK, map[string]interface{}{"total_rows": len(db), "offset": 0, "rows": rows})
+}
+
+func (s *Server) bulkDocs(rw http.ResponseWriter, req *http.Request) {
+	var request struct {
+		Docs []map[string]json.RawMessage `json:"docs"`
+	}
+
+	if !readRequest(rw, req, &request) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	db, ok := s.dbs[mux.Vars(req)["db"]]
+	if !ok {
+		writeError(rw, errDBNotFound)
+
+		return
+	}
+
+	results := make([]*updateResult, len(request.Docs))
+
+	for i, fields := range request.Docs {
+		var id string
+
+		if err := json.Unmarshal(fields["_id"], &id); err != nil || id == "" {
+			results[i] = &updateResult{Error: "bad_request", Reason: "Document id must be a string."}
+
+			continue
+		}
+
+		rev, err := update(db, id, fields)
+		if err != nil {
+			e := err.(*statusError) //nolint:errorlint // update returns status errors
+
+			results[i] = &updateResult{ID: id, Error: e.Code, Reason: e.Reason}
+
+			continue
+		}
+
+		results[i] = &updateResult{OK: true, ID: id, Rev: rev}
+	}
+
+	writeResponse(rw, http.StatusCreated, results)
+}
+
+func (s *Server) find(rw http.ResponseWriter, req *http.Request) {
+	var query struct {
+		Selector map[string]json.RawMessage `json:"selector"`
+		Limit    int                        `json:"limit"`
+		Bookmark string                     `json:"bookmark"`
+	}
+
+	if !readRequest(rw, req, &query) {
+		return
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	db, ok := s.dbs[mux.Vars(req)["db"]]
+	if !ok {
+		writeError(rw, errDBNotFound)
+
+		return
+	}
+
+	docs := []interface{}{}
+	bookmark := query.Bookmark
+
+	for _, id := range sortedIDs(db) {
+		if query.Bookmark != "" && id <= query.Bookmark || !db[id].matches(query.Selector) {
+			continue
+		}
+
+		if query.Limit > 0 && len(docs) == query.Limit {
+			break
+		}
+
+		docs = append(docs, db[id].json(id))
+		bookmark = id
+	}
+
+	writeResponse(rw, http.StatusOK, map[string]interface{}{"docs": docs, "bookmark": bookmark})
+}
+
+// docVars returns the database and the document ID of the request, the lock must be held.
+func (s *Server) docVars(req *http.Request) (map[string]*document, string, error) {
+	db, ok := s.dbs[mux.Vars(req)["db"]]
+	if !ok {
+		return nil, "", errDBNotFound
+	}
+
+	id, err := url.QueryUnescape(mux.Vars(req)["docID"])
+	if err != nil {
+		return nil, "", &statusError{http.StatusBadRequest, errorResponse{"bad_request", err.Error()}}
+	}
+
+	return db, id, nil
+}
+
+// update creates, updates or deletes (if _deleted is set) the document with the given fields, the revision of the
+// fields must be the current one. It returns the new revision.
+func update(db map[string]*document, id string, fields map[string]json.RawMessage) (string, error) {
+	var rev string
+
+	if raw, ok := fields["_rev"]; ok {
+		if err := json.Unmarshal(raw, &rev); err != nil {
+			return "", &statusError{http.StatusBadRequest, errorResponse{"bad_request", "Invalid rev format"}}
+		}
+	}
+
+	current, exists := db[id]
+	if exists && rev != current.revision() || !exists && rev != "" {
+		return "", errConflict
+	}
+
+	doc := &document{rev: 1, fields: make(map[string]json.RawMessage), attachments: make(map[string]*attachment)}
+	if exists {
+		doc.rev = current.rev + 1
+	}
+
+	var deleted bool
+
+	for name, value := range fields {
+		switch name {
+		case "_id", "_rev":
+		case "_deleted":
+			deleted = string(value) == "true"
+		case "_attachments":
+			if err := json.Unmarshal(value, &doc.attachments); err != nil {
+				return "", &statusError{http.StatusBadRequest, errorResponse{"bad_request", err.Error()}}
+			}
+		default:
+			doc.fields[name] = value
+		}
+	}
+
+	if deleted {
+		delete(db, id)
+
+		return fmt.Sprintf("%d-deleted", doc.rev), nil
+	}
+
+	db[id] = doc
+
+	return doc.revision(), nil
+}
+
+func (d *document) revision() string {
+	return fmt.Sprintf("%d-mock", d.rev)
+}
+
+// json returns the document with its reserved fields, the attachments are stubs.
+func (d *document) json(id string) map[string]interface{} {
+	doc := map[string]interface{}{"_id": id, "_rev": d.revision()}
+
+	for name, value := range d.fields {
+		doc[name] = value
+	}
+
+	if len(d.attachments) > 0 {
+		stubs := make(map[string]interface{}, len(d.attachments))
+
+		for name, att := range d.attachments {
+			stubs[name] = map[string]interface{}{"content_type": att.ContentType, "length": len(att.Data), "stub": true}
+		}
+
+		doc["_attachments"] = stubs
+	}
+
+	return doc
+}
+
+// matches reports whether the document matches all the conditions of the selector, the conditions are either values
+// or {"$exists": true}. The field names are paths of nested fields separated by unescaped dots.
+func (d *document) matches(selector map[string]json.RawMessage) bool {
+	for field, condition := range selector {
+		var operator struct {
+			Exists *bool `json:"$exists"`
+		}
+
+		_ = json.Unmarshal(condition, &operator) //nolint:errcheck // the condition is a value if it's not an operator
+
+		value, exists := d.field(field)
+
+		if operator.Exists != nil {
+			if exists != *operator.Exists {
+				return false
+			}
+
+			continue
+		}
+
+		if !exists || !equalJSON(value, condition) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (d *document) field(path string) (json.RawMessage, bool) {
+	names := splitFieldPath(path)
+
+	value, ok := d.fields[names[0]]
+
+	for _, name := range names[1:] {
+		if !ok {
+			break
+		}
+
+		var object map[string]json.RawMessage
+
+		if json.Unmarshal(value, &object) != nil {
+			return nil, false
+		}
+
+		value, ok = object[name]
+	}
+
+	return value, ok
+}
+
+// splitFieldPath splits the field path on the dots not escaped with a backslash.
+func splitFieldPath(path string) []string {
+	var (
+		names   []string
+		current strings.Builder
+	)
+
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			current.WriteByte('.')
+			i++
+		case path[i] == '.':
+			names = append(names, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(path[i])
+		}
+	}
+
+	return append(names, current.String())
+}
+
+func equalJSON(a, b json.RawMessage) bool {
+	var valueA, valueB interface{}
+
+	if json.Unmarshal(a, &valueA) != nil || json.Unmarshal(b, &valueB) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(valueA, valueB)
+}
+
+func sortedIDs(db map[string]*document) []string {
+	ids := make([]string, 0, len(db))
+
+	for id := range db {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// readRequest unmarshals the body of the request, the error response is written if it fails.
+func readRequest(rw http.ResponseWriter, req *http.Request, request interface{}) bool {
+	body, err := ioutil.ReadAll(req.Body)
+	if err == nil {
+		err = json.Unmarshal(body, request)
+	}
+
+	if err != nil {
+		writeError(rw, &statusError{http.StatusBadRequest, errorResponse{"bad_request", err.Error()}})
+
+		return false
+	}
+
+	return true
+}
+
+func writeError(rw http.ResponseWriter, err error) {
+	e, ok := err.(*statusError)
+	if !ok {
+		e = &statusError{http.StatusInternalServerError, errorResponse{"unknown_error", err.Error()}}
+	}
+
+	writeResponse(rw, e.status, &e.errorResponse)
+}
+
+func writeResponse(rw http.ResponseWriter, status int, response interface{}) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		logger.Errorf("failed to marshal response: %v", err)
+
+		status, body = http.StatusInternalServerError, []byte(`{"error":"unknown_error"}`)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+
+	if _, err := rw.Write(body); err != nil {
+		logger.Errorf("failed to write response: %v", err)
+	}
+}
+
+// Error returns the reason of the error response.
+func (e *statusError) Error() string {
+	return e.Reason
+}
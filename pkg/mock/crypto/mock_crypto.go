@@ -6,6 +6,10 @@ SPDX-License-Identifier: Apache-2.0
 
 package crypto
 
+import (
+	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
+)
+
 // Crypto mock
 type Crypto struct {
 	EncryptValue      []byte
@@ -19,6 +23,10 @@ type Crypto struct {
 	ComputeMACValue   []byte
 	ComputeMACErr     error
 	VerifyMACErr      error
+	WrapValue         *cryptoapi.RecipientWrappedKey
+	WrapErr           error
+	UnwrapValue       []byte
+	UnwrapErr         error
 }
 
 // Encrypt returns mocked values and a mocked error
@@ -50,3 +58,15 @@ func (c *Crypto) ComputeMAC(data []byte, kh interface{}) ([]byte, error) {
 func (c *Crypto) VerifyMAC(mac, data []byte, kh interface{}) error {
 	return c.VerifyMACErr
 }
+
+// WrapKey returns a mocked value
+func (c *Crypto) WrapKey(cek, apu, apv []byte, recPubKey *cryptoapi.PublicKey,
+	wrapKeyOpts ...cryptoapi.WrapKeyOpts) (*cryptoapi.RecipientWrappedKey, error) {
+	return c.WrapValue, c.WrapErr
+}
+
+// UnwrapKey returns a mocked value
+func (c *Crypto) UnwrapKey(recWK *cryptoapi.RecipientWrappedKey, kh interface{},
+	wrapKeyOpts ...cryptoapi.WrapKeyOpts) ([]byte, error) {
+	return c.UnwrapValue, c.UnwrapErr
+}
@@ -0,0 +1,312 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package edv
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sync"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/gorilla/mux"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+)
+
+var logger = log.New("aries-framework/mock/edv")
+
+const (
+	vaultsPath    = "/encrypted-data-vaults"
+	vaultPath     = vaultsPath + "/{vaultID}"
+	documentsPath = vaultPath + "/documents"
+	documentPath  = documentsPath + "/{docID}"
+	queriesPath   = vaultPath + "/queries"
+	idSize        = 16
+)
+
+// Server is a minimal in-memory Encrypted Data Vault server implementing the vault and document operations of the
+// EDV REST API (https://identity.foundation/secure-data-store/#http-api), to be used with httptest in tests.
+// The documents are kept as sent, the uniqueness of indexed attributes isn't enforced.
+type Server struct {
+	router *mux.Router
+	vaults map[string]map[string]*document
+	lock   sync.RWMutex
+}
+
+// document is a stored encrypted document and its indexed attributes.
+type document struct {
+	raw        json.RawMessage
+	attributes map[string]string
+}
+
+// encryptedDocument holds the fields of the encrypted documents read by the server.
+type encryptedDocument struct {
+	ID      string `json:"id"`
+	Indexed []struct {
+		Attributes []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"attributes"`
+	} `json:"indexed"`
+	JWE json.RawMessage `json:"jwe"`
+}
+
+// query is a query of the documents by indexed attribute, the documents having the attribute Name are matched if its
+// value equals Value, or whatever its value if Has is set.
+type query struct {
+	Name  string `json:"index"`
+	Value string `json:"equals"`
+	Has   bool   `json:"has"`
+}
+
+// NewServer returns a new EDV server without vaults.
+func NewServer() *Server {
+	s := &Server{router: mux.NewRouter(), vaults: make(map[string]map[string]*document)}
+
+	s.router.HandleFunc(vaultsPath, s.createDataVault).Methods(http.MethodPost)
+	s.router.HandleFunc(documentsPath, s.createDocument).Methods(http.MethodPost)
+	s.router.HandleFunc(documentPath, s.readDocument).Methods(http.MethodGet)
+	s.router.HandleFunc(documentPath, s.updateDocument).Methods(http.MethodPut)
+	s.router.HandleFunc(documentPath, s.deleteDocument).Methods(http.MethodDelete)
+	s.router.HandleFunc(queriesPath, s.queryVault).Methods(http.MethodPost)
+
+	return s
+}
+
+// ServeHTTP serves the EDV REST API.
+func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.router.ServeHTTP(rw, req)
+}
+
+func (s *Server) createDataVault(rw http.ResponseWriter, req *http.Request) {
+	var config struct {
+		Controller string `json:"controller"`
+	}
+
+	if _, ok := readRequest(rw, req, &config); !ok {
+		return
+	}
+
+	if config.Controller == "" {
+		http.Error(rw, "controller is mandatory", http.StatusBadRequest)
+
+		return
+	}
+
+	vaultID, err := newID()
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	s.lock.Lock()
+	s.vaults[vaultID] = make(map[string]*document)
+	s.lock.Unlock()
+
+	rw.Header().Set("Location", path.Join(vaultsPath, vaultID))
+	rw.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) createDocument(rw http.ResponseWriter, req *http.Request) {
+	doc, id, ok := readDocument(rw, req)
+	if !ok {
+		return
+	}
+
+	vaultID := mux.Vars(req)["vaultID"]
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	vault, ok := s.vaults[vaultID]
+	if !ok {
+		http.Error(rw, "vault not found", http.StatusNotFound)
+
+		return
+	}
+
+	if _, ok := vault[id]; ok {
+		http.Error(rw, "duplicate document", http.StatusConflict)
+
+		return
+	}
+
+	vault[id] = doc
+
+	rw.Header().Set("Location", path.Join(vaultsPath, vaultID, "documents", id))
+	rw.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) readDocument(rw http.ResponseWriter, req *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	doc, ok := s.vaults[mux.Vars(req)["vaultID"]][mux.Vars(req)["docID"]]
+	if !ok {
+		http.Error(rw, "document not found", http.StatusNotFound)
+
+		return
+	}
+
+	writeResponse(rw, doc.raw)
+}
+
+func (s *Server) updateDocument(rw http.ResponseWriter, req *http.Request) {
+	doc, id, ok := readDocument(rw, req)
+	if !ok {
+		return
+	}
+
+	if id != mux.Vars(req)["docID"] {
+		http.Error(rw, "document ID mismatch", http.StatusBadRequest)
+
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	vault := s.vaults[mux.Vars(req)["vaultID"]]
+
+	if _, ok := vault[id]; !ok {
+		http.Error(rw, "document not found", http.StatusNotFound)
+
+		return
+	}
+
+	vault[id] = doc
+}
+
+func (s *Server) deleteDocument(rw http.ResponseWriter, req *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	vault := s.vaults[mux.Vars(req)["vaultID"]]
+	docID := mux.Vars(req)["docID"]
+
+	if _, ok := vault[docID]; !ok {
+		http.Error(rw, "document not found", http.StatusNotFound)
+
+		return
+	}
+
+	delete(vault, docID)
+}
+
+func (s *Server) queryVault(rw http.ResponseWriter, req *http.Request) {
+	var q query
+
+	if _, ok := readRequest(rw, req, &q); !ok {
+		return
+	}
+
+	if q.Value == "" && !q.Has {
+		http.Error(rw, "query must have an equals or a has condition", http.StatusBadRequest)
+
+		return
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	vault, ok := s.vaults[mux.Vars(req)["vaultID"]]
+	if !ok {
+		http.Error(rw, "vault not found", http.StatusNotFound)
+
+		return
+	}
+
+	documents := []json.RawMessage{}
+
+	for _, doc := range vault {
+		if value, ok := doc.attributes[q.Name]; ok && (q.Has || q.Value == value) {
+			documents = append(documents, doc.raw)
+		}
+	}
+
+	response, err := json.Marshal(documents)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	writeResponse(rw, response)
+}
+
+// readDocument reads the encrypted document of the request, the error response is written if it's invalid.
+func readDocument(rw http.ResponseWriter, req *http.Request) (*document, string, bool) {
+	var encDoc encryptedDocument
+
+	raw, ok := readRequest(rw, req, &encDoc)
+	if !ok {
+		return nil, "", false
+	}
+
+	if len(base58.Decode(encDoc.ID)) != idSize {
+		http.Error(rw, "document ID must be a base58-encoded 128-bit value", http.StatusBadRequest)
+
+		return nil, "", false
+	}
+
+	if len(encDoc.JWE) == 0 || string(encDoc.JWE) == "null" {
+		http.Error(rw, "jwe is mandatory", http.StatusBadRequest)
+
+		return nil, "", false
+	}
+
+	doc := &document{raw: raw, attributes: make(map[string]string)}
+
+	for _, collection := range encDoc.Indexed {
+		for _, attribute := range collection.Attributes {
+			doc.attributes[attribute.Name] = attribute.Value
+		}
+	}
+
+	return doc, encDoc.ID, true
+}
+
+// readRequest unmarshals the body of the request and returns it, the error response is written if it fails.
+func readRequest(rw http.ResponseWriter, req *http.Request, request interface{}) ([]byte, bool) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err == nil {
+		err = json.Unmarshal(body, request)
+	}
+
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+
+		return nil, false
+	}
+
+	return body, true
+}
+
+func writeResponse(rw http.ResponseWriter, response []byte) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	_, err := rw.Write(response)
+	if err != nil {
+		logger.Errorf("failed to write response: %v", err)
+	}
+}
+
+func newID() (string, error) {
+	id := make([]byte, idSize)
+
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+
+	return base58.Encode(id), nil
+}
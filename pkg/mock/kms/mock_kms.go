@@ -36,6 +36,11 @@ type KeyManager struct {
 	ImportPrivateKeyErr      error
 	ImportPrivateKeyID       string
 	ImportPrivateKeyValue    *keyset.Handle
+	ListValue                []*kmsservice.KeyMetadata
+	ListErr                  error
+	DescribeValue            *kmsservice.KeyMetadata
+	DescribeErr              error
+	DeleteErr                error
 }
 
 // Create a new mock ey/keyset/key handle for the type kt
@@ -93,6 +98,29 @@ func (k *KeyManager) ImportPrivateKey(privKey interface{}, keyType kmsservice.Ke
 	return k.ImportPrivateKeyID, k.ImportPrivateKeyValue, nil
 }
 
+// List returns mocked key metadata list
+func (k *KeyManager) List() ([]*kmsservice.KeyMetadata, error) {
+	if k.ListErr != nil {
+		return nil, k.ListErr
+	}
+
+	return k.ListValue, nil
+}
+
+// Describe returns mocked key metadata
+func (k *KeyManager) Describe(keyID string) (*kmsservice.KeyMetadata, error) {
+	if k.DescribeErr != nil {
+		return nil, k.DescribeErr
+	}
+
+	return k.DescribeValue, nil
+}
+
+// Delete returns mocked delete key error
+func (k *KeyManager) Delete(keyID string) error {
+	return k.DeleteErr
+}
+
 // CreateMockKeyHandle is a utility function that returns a mock key (for tests only. ie: not registered in Tink)
 func CreateMockKeyHandle() (*keyset.Handle, error) {
 	ks := testutil.NewTestAESGCMKeyset(tinkpb.OutputPrefixType_TINK)
@@ -0,0 +1,477 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pkcs11kms
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+const (
+	ckmEdDSA               = 0x1057
+	ckmECEdwardsKeyPairGen = 0x1055
+	defaultTokenLabel      = "aries"
+	defaultPIN             = "1234"
+	firstObjectHandle      = 1
+	firstSessionHandle     = 1
+	rsParts                = 2
+)
+
+var (
+	oidP256    = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidP384    = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	oidEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+)
+
+// Context mocks a PKCS#11 library context exposing a single in-memory token. Keys are generated and used with the
+// Go crypto packages, private keys never leave the mock token. It supports the subset of PKCS#11 functions used by
+// pkcs11kms: EC (P-256, P-384) and EC Edwards (Ed25519) key pairs, CKM_ECDSA and CKM_EDDSA signatures.
+type Context struct {
+	TokenLabel string
+	PIN        string
+
+	ErrGetSlotList       error
+	ErrOpenSession       error
+	ErrLogin             error
+	ErrGenerateKeyPair   error
+	ErrCreateObject      error
+	ErrFindObjects       error
+	ErrGetAttributeValue error
+	ErrSign              error
+
+	objects    map[pkcs11.ObjectHandle][]*pkcs11.Attribute
+	signers    map[pkcs11.ObjectHandle]crypto.Signer
+	nextHandle pkcs11.ObjectHandle
+	found      []pkcs11.ObjectHandle
+	signObject pkcs11.ObjectHandle
+	signMech   uint
+	mutex      sync.Mutex
+}
+
+// NewContext creates a mock PKCS#11 context with an empty token labelled "aries" and the user PIN "1234".
+func NewContext() *Context {
+	return &Context{
+		TokenLabel: defaultTokenLabel,
+		PIN:        defaultPIN,
+		objects:    map[pkcs11.ObjectHandle][]*pkcs11.Attribute{},
+		signers:    map[pkcs11.ObjectHandle]crypto.Signer{},
+		nextHandle: firstObjectHandle,
+	}
+}
+
+// Objects returns the number of objects stored in the mock token.
+func (c *Context) Objects() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.objects)
+}
+
+// GetSlotList returns the slot of the mock token.
+func (c *Context) GetSlotList(tokenPresent bool) ([]uint, error) {
+	if c.ErrGetSlotList != nil {
+		return nil, c.ErrGetSlotList
+	}
+
+	return []uint{0}, nil
+}
+
+// GetTokenInfo returns the info of the mock token.
+func (c *Context) GetTokenInfo(slotID uint) (pkcs11.TokenInfo, error) {
+	if slotID != 0 {
+		return pkcs11.TokenInfo{}, pkcs11.Error(pkcs11.CKR_SLOT_ID_INVALID)
+	}
+
+	return pkcs11.TokenInfo{Label: c.TokenLabel}, nil
+}
+
+// OpenSession opens a session on the mock token.
+func (c *Context) OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error) {
+	if c.ErrOpenSession != nil {
+		return 0, c.ErrOpenSession
+	}
+
+	return firstSessionHandle, nil
+}
+
+// CloseSession closes the session on the mock token.
+func (c *Context) CloseSession(sh pkcs11.SessionHandle) error {
+	return nil
+}
+
+// Login logs the user in the mock token if pin matches the token PIN.
+func (c *Context) Login(sh pkcs11.SessionHandle, userType uint, pin string) error {
+	if c.ErrLogin != nil {
+		return c.ErrLogin
+	}
+
+	if pin != c.PIN {
+		return pkcs11.Error(pkcs11.CKR_PIN_INCORRECT)
+	}
+
+	return nil
+}
+
+// Logout logs the user out of the mock token.
+func (c *Context) Logout(sh pkcs11.SessionHandle) error {
+	return nil
+}
+
+// GenerateKeyPair generates an EC or EC Edwards key pair from the CKA_EC_PARAMS of the public key template.
+func (c *Context) GenerateKeyPair(sh pkcs11.SessionHandle, m []*pkcs11.Mechanism,
+	public, private []*pkcs11.Attribute) (pkcs11.ObjectHandle, pkcs11.ObjectHandle, error) {
+	if c.ErrGenerateKeyPair != nil {
+		return 0, 0, c.ErrGenerateKeyPair
+	}
+
+	if len(m) != 1 || (m[0].Mechanism != pkcs11.CKM_EC_KEY_PAIR_GEN && m[0].Mechanism != ckmECEdwardsKeyPairGen) {
+		return 0, 0, pkcs11.Error(pkcs11.CKR_MECHANISM_INVALID)
+	}
+
+	var (
+		signer  crypto.Signer
+		ecPoint []byte
+		err     error
+	)
+
+	oid, err := curveOID(public)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	switch {
+	case oid.Equal(oidEd25519):
+		var pub ed25519.PublicKey
+
+		pub, signer, err = ed25519.GenerateKey(rand.Reader)
+		ecPoint = pub
+	default:
+		var (
+			curve elliptic.Curve
+			key   *ecdsa.PrivateKey
+		)
+
+		curve, err = ecCurve(oid)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		key, err = ecdsa.GenerateKey(curve, rand.Reader)
+		if key != nil {
+			signer = key
+			ecPoint = elliptic.Marshal(curve, key.X, key.Y)
+		}
+	}
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ecPointValue, err := asn1.Marshal(ecPoint)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	pubHandle := c.store(append(copyAttributes(public), pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, ecPointValue)))
+	privHandle := c.store(copyAttributes(private))
+	c.signers[privHandle] = signer
+
+	return pubHandle, privHandle, nil
+}
+
+// CreateObject stores an object in the mock token. Private key objects must have CKA_EC_PARAMS and CKA_VALUE
+// attributes, their CKA_VALUE is not readable.
+func (c *Context) CreateObject(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) (pkcs11.ObjectHandle, error) {
+	if c.ErrCreateObject != nil {
+		return 0, c.ErrCreateObject
+	}
+
+	class := findAttribute(temp, pkcs11.CKA_CLASS)
+	if class == nil || !bytes.Equal(class.Value, pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY).Value) {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		return c.store(copyAttributes(temp)), nil
+	}
+
+	signer, err := privateKey(temp)
+	if err != nil {
+		return 0, err
+	}
+
+	var attrs []*pkcs11.Attribute
+
+	for _, a := range temp {
+		if a.Type != pkcs11.CKA_VALUE {
+			attrs = append(attrs, a)
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	h := c.store(copyAttributes(attrs))
+	c.signers[h] = signer
+
+	return h, nil
+}
+
+// DestroyObject removes an object from the mock token.
+func (c *Context) DestroyObject(sh pkcs11.SessionHandle, oh pkcs11.ObjectHandle) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.objects[oh]; !ok {
+		return pkcs11.Error(pkcs11.CKR_OBJECT_HANDLE_INVALID)
+	}
+
+	delete(c.objects, oh)
+	delete(c.signers, oh)
+
+	return nil
+}
+
+// GetAttributeValue reads the attributes a of object o.
+func (c *Context) GetAttributeValue(sh pkcs11.SessionHandle, o pkcs11.ObjectHandle,
+	a []*pkcs11.Attribute) ([]*pkcs11.Attribute, error) {
+	if c.ErrGetAttributeValue != nil {
+		return nil, c.ErrGetAttributeValue
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	attrs, ok := c.objects[o]
+	if !ok {
+		return nil, pkcs11.Error(pkcs11.CKR_OBJECT_HANDLE_INVALID)
+	}
+
+	var result []*pkcs11.Attribute
+
+	for _, req := range a {
+		attr := findAttribute(attrs, req.Type)
+		if attr == nil {
+			return nil, pkcs11.Error(pkcs11.CKR_ATTRIBUTE_TYPE_INVALID)
+		}
+
+		result = append(result, pkcs11.NewAttribute(attr.Type, append([]byte(nil), attr.Value...)))
+	}
+
+	return result, nil
+}
+
+// FindObjectsInit starts a search of the objects matching all attributes of temp.
+func (c *Context) FindObjectsInit(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.found = nil
+
+	for h, attrs := range c.objects {
+		if matches(attrs, temp) {
+			c.found = append(c.found, h)
+		}
+	}
+
+	return nil
+}
+
+// FindObjects returns at most max objects found by the search started with FindObjectsInit.
+func (c *Context) FindObjects(sh pkcs11.SessionHandle, max int) ([]pkcs11.ObjectHandle, bool, error) {
+	if c.ErrFindObjects != nil {
+		return nil, false, c.ErrFindObjects
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if max > len(c.found) {
+		max = len(c.found)
+	}
+
+	result := c.found[:max]
+	c.found = c.found[max:]
+
+	return result, false, nil
+}
+
+// FindObjectsFinal ends the search started with FindObjectsInit.
+func (c *Context) FindObjectsFinal(sh pkcs11.SessionHandle) error {
+	c.found = nil
+
+	return nil
+}
+
+// SignInit starts a signature with the private key o.
+func (c *Context) SignInit(sh pkcs11.SessionHandle, m []*pkcs11.Mechanism, o pkcs11.ObjectHandle) error {
+	if len(m) != 1 || (m[0].Mechanism != pkcs11.CKM_ECDSA && m[0].Mechanism != ckmEdDSA) {
+		return pkcs11.Error(pkcs11.CKR_MECHANISM_INVALID)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.signers[o]; !ok {
+		return pkcs11.Error(pkcs11.CKR_KEY_HANDLE_INVALID)
+	}
+
+	c.signObject = o
+	c.signMech = m[0].Mechanism
+
+	return nil
+}
+
+// Sign signs message with the key and mechanism set by SignInit. CKM_ECDSA signatures are the raw concatenation of
+// r and s, message is expected to be a digest.
+func (c *Context) Sign(sh pkcs11.SessionHandle, message []byte) ([]byte, error) {
+	if c.ErrSign != nil {
+		return nil, c.ErrSign
+	}
+
+	c.mutex.Lock()
+	signer := c.signers[c.signObject]
+	mech := c.signMech
+	c.mutex.Unlock()
+
+	switch key := signer.(type) {
+	case ed25519.PrivateKey:
+		if mech != ckmEdDSA {
+			return nil, pkcs11.Error(pkcs11.CKR_KEY_TYPE_INCONSISTENT)
+		}
+
+		return ed25519.Sign(key, message), nil
+	case *ecdsa.PrivateKey:
+		if mech != pkcs11.CKM_ECDSA {
+			return nil, pkcs11.Error(pkcs11.CKR_KEY_TYPE_INCONSISTENT)
+		}
+
+		r, s, err := ecdsa.Sign(rand.Reader, key, message)
+		if err != nil {
+			return nil, err
+		}
+
+		size := (key.Curve.Params().BitSize + 7) / 8
+		sig := make([]byte, rsParts*size)
+		rBytes, sBytes := r.Bytes(), s.Bytes()
+		copy(sig[size-len(rBytes):size], rBytes)
+		copy(sig[rsParts*size-len(sBytes):], sBytes)
+
+		return sig, nil
+	default:
+		return nil, pkcs11.Error(pkcs11.CKR_OPERATION_NOT_INITIALIZED)
+	}
+}
+
+func (c *Context) store(attrs []*pkcs11.Attribute) pkcs11.ObjectHandle {
+	h := c.nextHandle
+	c.nextHandle++
+	c.objects[h] = attrs
+
+	return h
+}
+
+func privateKey(temp []*pkcs11.Attribute) (crypto.Signer, error) {
+	value := findAttribute(temp, pkcs11.CKA_VALUE)
+	if value == nil {
+		return nil, pkcs11.Error(pkcs11.CKR_TEMPLATE_INCOMPLETE)
+	}
+
+	oid, err := curveOID(temp)
+	if err != nil {
+		return nil, err
+	}
+
+	if oid.Equal(oidEd25519) {
+		if len(value.Value) != ed25519.SeedSize {
+			return nil, pkcs11.Error(pkcs11.CKR_ATTRIBUTE_VALUE_INVALID)
+		}
+
+		return ed25519.NewKeyFromSeed(value.Value), nil
+	}
+
+	curve, err := ecCurve(oid)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(value.Value)}
+	key.Curve = curve
+	key.X, key.Y = curve.ScalarBaseMult(value.Value)
+
+	return key, nil
+}
+
+func curveOID(attrs []*pkcs11.Attribute) (asn1.ObjectIdentifier, error) {
+	params := findAttribute(attrs, pkcs11.CKA_EC_PARAMS)
+	if params == nil {
+		return nil, pkcs11.Error(pkcs11.CKR_TEMPLATE_INCOMPLETE)
+	}
+
+	var oid asn1.ObjectIdentifier
+
+	_, err := asn1.Unmarshal(params.Value, &oid)
+	if err != nil {
+		return nil, pkcs11.Error(pkcs11.CKR_ATTRIBUTE_VALUE_INVALID)
+	}
+
+	return oid, nil
+}
+
+func ecCurve(oid asn1.ObjectIdentifier) (elliptic.Curve, error) {
+	switch {
+	case oid.Equal(oidP256):
+		return elliptic.P256(), nil
+	case oid.Equal(oidP384):
+		return elliptic.P384(), nil
+	default:
+		return nil, errors.New("mock pkcs11: unsupported curve")
+	}
+}
+
+func findAttribute(attrs []*pkcs11.Attribute, typ uint) *pkcs11.Attribute {
+	for _, a := range attrs {
+		if a.Type == typ {
+			return a
+		}
+	}
+
+	return nil
+}
+
+func matches(attrs, temp []*pkcs11.Attribute) bool {
+	for _, t := range temp {
+		a := findAttribute(attrs, t.Type)
+		if a == nil || !bytes.Equal(a.Value, t.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func copyAttributes(attrs []*pkcs11.Attribute) []*pkcs11.Attribute {
+	result := make([]*pkcs11.Attribute, len(attrs))
+
+	for i, a := range attrs {
+		result[i] = pkcs11.NewAttribute(a.Type, append([]byte(nil), a.Value...))
+	}
+
+	return result
+}
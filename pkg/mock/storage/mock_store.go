@@ -8,8 +8,10 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
@@ -61,15 +63,19 @@ func (s *MockStoreProvider) CloseStore(name string) error {
 // MockStore mock store.
 type MockStore struct {
 	Store     map[string][]byte
+	Tags      map[string][]storage.Tag
+	TTLs      map[string]time.Duration
 	lock      sync.RWMutex
 	ErrPut    error
 	ErrGet    error
 	ErrItr    error
 	ErrDelete error
+	ErrQuery  error
+	ErrBatch  error
 }
 
-// Put stores the key and the record
-func (s *MockStore) Put(k string, v []byte) error {
+// Put stores the key and the record with its tags
+func (s *MockStore) Put(k string, v []byte, tags ...storage.Tag) error {
 	if k == "" {
 		return errors.New("key is mandatory")
 	}
@@ -80,11 +86,43 @@ func (s *MockStore) Put(k string, v []byte) error {
 
 	s.lock.Lock()
 	s.Store[k] = v
+
+	if s.Tags == nil {
+		s.Tags = make(map[string][]storage.Tag)
+	}
+
+	if len(tags) > 0 {
+		s.Tags[k] = tags
+	} else {
+		delete(s.Tags, k)
+	}
+
+	delete(s.TTLs, k)
+
 	s.lock.Unlock()
 
 	return s.ErrPut
 }
 
+// PutWithTTL stores the key and the record with its tags like Put, the ttl of the record is kept in TTLs
+func (s *MockStore) PutWithTTL(k string, v []byte, ttl time.Duration, tags ...storage.Tag) error {
+	err := s.Put(k, v, tags...)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.TTLs == nil {
+		s.TTLs = make(map[string]time.Duration)
+	}
+
+	s.TTLs[k] = ttl
+
+	return nil
+}
+
 // Get fetches the record based on key
 func (s *MockStore) Get(k string) ([]byte, error) {
 	if s.ErrGet != nil {
@@ -126,11 +164,92 @@ func (s *MockStore) Iterator(start, limit string) storage.StoreIterator {
 func (s *MockStore) Delete(k string) error {
 	s.lock.Lock()
 	delete(s.Store, k)
+	delete(s.Tags, k)
+	delete(s.TTLs, k)
 	s.lock.Unlock()
 
 	return s.ErrDelete
 }
 
+// Query returns an iterator over the records having a tag matching the expression
+func (s *MockStore) Query(expression string, _ ...storage.QueryOption) (storage.StoreIterator, error) {
+	if s.ErrQuery != nil {
+		return nil, s.ErrQuery
+	}
+
+	query, err := storage.ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var keys []string
+
+	for k, tags := range s.Tags {
+		if query.Match(tags) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	batch := make([][]string, len(keys))
+
+	for i, k := range keys {
+		batch[i] = []string{k, string(s.Store[k])}
+	}
+
+	return NewMockIterator(batch), nil
+}
+
+// Batch applies the operations with Put and Delete, it fails with the errors of the store
+func (s *MockStore) Batch(operations []storage.Operation) error {
+	if s.ErrBatch != nil {
+		return s.ErrBatch
+	}
+
+	for _, op := range operations {
+		var err error
+
+		if op.IsDelete() {
+			err = s.Delete(op.Key)
+		} else {
+			err = s.Put(op.Key, op.Value, op.Tags...)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BatchWithTTL applies the operations like Batch, the ttl of the records stored is kept in TTLs
+func (s *MockStore) BatchWithTTL(operations []storage.Operation, ttl time.Duration) error {
+	err := s.Batch(operations)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.TTLs == nil {
+		s.TTLs = make(map[string]time.Duration)
+	}
+
+	for _, op := range operations {
+		if !op.IsDelete() {
+			s.TTLs[op.Key] = ttl
+		}
+	}
+
+	return nil
+}
+
 // NewMockIterator returns new mock iterator for given batch
 func NewMockIterator(batch [][]string) *MockIterator {
 	if len(batch) == 0 {
@@ -10,6 +10,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/google/tink/go/subtle/random"
 
@@ -46,17 +47,67 @@ import (
 
 var logger = log.New("aries-framework/lock")
 
-const masterKeyLen = 512
+const (
+	masterKeyLen = 512
+
+	// size of master keys created by RotateMasterKey()
+	newMasterKeySize = 32
+)
 
 // Lock is a secret lock service responsible for encrypting keys using a master key
 type Lock struct {
-	aead cipher.AEAD
+	aead    cipher.AEAD
+	secLock secretlock.Service
+	// previous master keys ciphers, used for decryption only while a master key rotation is in progress
+	previous []cipher.AEAD
+	mutex    sync.RWMutex
+}
+
+// Opt is a local secret lock service option.
+type Opt func(opts *lockOpts)
+
+type lockOpts struct {
+	previousMasterKeyReaders []io.Reader
+}
+
+// WithPreviousMasterKey adds a master key read from masterKeyReader (protected with the same secLock as the current
+// master key) to decrypt keys that were not re-encrypted yet by an interrupted master key rotation.
+func WithPreviousMasterKey(masterKeyReader io.Reader) Opt {
+	return func(opts *lockOpts) {
+		opts.previousMasterKeyReaders = append(opts.previousMasterKeyReaders, masterKeyReader)
+	}
 }
 
 // NewService creates a new instance of local secret lock service using a master key in masterKeyReader.
 // If the masterKey is not protected (secLock=nil) this function will attempt to base64 URL Decode the
 // content of masterKeyReader and if it fails, then will attempt to create a secret lock cipher with the raw key as is.
-func NewService(masterKeyReader io.Reader, secLock secretlock.Service) (secretlock.Service, error) {
+func NewService(masterKeyReader io.Reader, secLock secretlock.Service, opts ...Opt) (secretlock.Service, error) {
+	lOpts := &lockOpts{}
+
+	for _, opt := range opts {
+		opt(lOpts)
+	}
+
+	aead, err := newMasterKeyCipher(masterKeyReader, secLock)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Lock{aead: aead, secLock: secLock}
+
+	for _, r := range lOpts.previousMasterKeyReaders {
+		previous, e := newMasterKeyCipher(r, secLock)
+		if e != nil {
+			return nil, fmt.Errorf("previous master key: %w", e)
+		}
+
+		l.previous = append(l.previous, previous)
+	}
+
+	return l, nil
+}
+
+func newMasterKeyCipher(masterKeyReader io.Reader, secLock secretlock.Service) (cipher.AEAD, error) {
 	masterKeyData := make([]byte, masterKeyLen)
 
 	if masterKeyReader == nil {
@@ -100,19 +151,67 @@ func NewService(masterKeyReader io.Reader, secLock secretlock.Service) (secretlo
 	}
 
 	// finally create the cipher to be used by the lock service
+	return cipherutil.CreateAESCipher(masterKey)
+}
+
+// RotateMasterKey creates a new random master key and calls persist with its protected content (encrypted with the
+// secLock passed to NewService, or base64URL encoded if secLock is nil), the content expected by NewService.
+// If persist succeeds, the new master key is used to encrypt keys from now on while the previous master keys are
+// still used to decrypt keys until CompleteMasterKeyRotation() is called. Keys encrypted with the previous master keys
+// must be re-encrypted before completing the rotation, for KMS keysets this is done by localkms.ReEncryptKeys().
+//
+// persist should keep the previous master key until the rotation is completed in order to restart the service with
+// the WithPreviousMasterKey() option if the rotation is interrupted.
+func (s *Lock) RotateMasterKey(persist func(protectedMasterKey []byte) error) error {
+	masterKey := random.GetRandomBytes(newMasterKeySize)
+
 	aead, err := cipherutil.CreateAESCipher(masterKey)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return &Lock{aead: aead}, nil
+	protectedMasterKey := []byte(base64.URLEncoding.EncodeToString(masterKey))
+
+	if s.secLock != nil {
+		encResponse, e := s.secLock.Encrypt("", &secretlock.EncryptRequest{Plaintext: string(masterKey)})
+		if e != nil {
+			return fmt.Errorf("failed to protect new master key: %w", e)
+		}
+
+		protectedMasterKey = []byte(encResponse.Ciphertext)
+	}
+
+	err = persist(protectedMasterKey)
+	if err != nil {
+		return fmt.Errorf("failed to persist new master key: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.previous = append([]cipher.AEAD{s.aead}, s.previous...)
+	s.aead = aead
+
+	return nil
+}
+
+// CompleteMasterKeyRotation drops the previous master keys, keys encrypted with them can no longer be decrypted.
+func (s *Lock) CompleteMasterKeyRotation() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.previous = nil
 }
 
 // Encrypt a key in req using master key in the local secret lock service
 // (keyURI is used for remote locks, it is ignored by this implementation)
 func (s *Lock) Encrypt(keyURI string, req *secretlock.EncryptRequest) (*secretlock.EncryptResponse, error) {
-	nonce := random.GetRandomBytes(uint32(s.aead.NonceSize()))
-	ct := s.aead.Seal(nil, nonce, []byte(req.Plaintext), []byte(req.AdditionalAuthenticatedData))
+	s.mutex.RLock()
+	aead := s.aead
+	s.mutex.RUnlock()
+
+	nonce := random.GetRandomBytes(uint32(aead.NonceSize()))
+	ct := aead.Seal(nil, nonce, []byte(req.Plaintext), []byte(req.AdditionalAuthenticatedData))
 	ct = append(nonce, ct...)
 
 	return &secretlock.EncryptResponse{
@@ -128,20 +227,34 @@ func (s *Lock) Decrypt(keyURI string, req *secretlock.DecryptRequest) (*secretlo
 		return nil, err
 	}
 
-	nonceSize := uint32(s.aead.NonceSize())
+	s.mutex.RLock()
+	aeads := append([]cipher.AEAD{s.aead}, s.previous...)
+	s.mutex.RUnlock()
 
-	// ensure ciphertext contains more than nonce+ciphertext (result from Encrypt())
-	if len(ct) <= int(nonceSize) {
-		return nil, fmt.Errorf("invalid request")
-	}
+	var pt []byte
 
-	nonce := ct[0:nonceSize]
-	ct = ct[nonceSize:]
+	// keys not re-encrypted yet during a master key rotation are decrypted with the previous master keys
+	for _, aead := range aeads {
+		pt, err = open(aead, ct, []byte(req.AdditionalAuthenticatedData))
+		if err == nil {
+			break
+		}
+	}
 
-	pt, err := s.aead.Open(nil, nonce, ct, []byte(req.AdditionalAuthenticatedData))
 	if err != nil {
 		return nil, err
 	}
 
 	return &secretlock.DecryptResponse{Plaintext: string(pt)}, nil
 }
+
+func open(aead cipher.AEAD, ct, aad []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+
+	// ensure ciphertext contains more than nonce+ciphertext (result from Encrypt())
+	if len(ct) <= nonceSize {
+		return nil, fmt.Errorf("invalid request")
+	}
+
+	return aead.Open(nil, ct[0:nonceSize], ct[nonceSize:], aad)
+}
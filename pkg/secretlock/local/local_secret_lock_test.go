@@ -10,6 +10,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -18,8 +19,11 @@ import (
 	"github.com/google/tink/go/subtle/random"
 	"github.com/stretchr/testify/require"
 
+	mocksecretlock "github.com/hyperledger/aries-framework-go/pkg/mock/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local/masterlock/hkdf"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
 )
 
 const (
@@ -382,3 +386,181 @@ func TestCreateServiceFromEnvWithMasterLock(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, someKey, []byte(someKeyDec.Plaintext))
 }
+
+func TestCreateServiceFromStoreWithMasterLock(t *testing.T) {
+	masterKeyContent := random.GetRandomBytes(uint32(32))
+
+	masterLocker, err := hkdf.NewMasterLock("secretPassphrase", sha256.New, nil)
+	require.NoError(t, err)
+
+	masterLockEnc, err := masterLocker.Encrypt("", &secretlock.EncryptRequest{
+		Plaintext: string(masterKeyContent)})
+	require.NoError(t, err)
+
+	store, err := mem.NewProvider().OpenStore("masterkey")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("current", []byte(masterLockEnc.Ciphertext)))
+
+	// get a reader from a missing key
+	badReader, err := MasterKeyFromStore(store, "missing")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	require.Empty(t, badReader)
+
+	r, err := MasterKeyFromStore(store, "current")
+	require.NoError(t, err)
+
+	s, err := NewService(r, masterLocker)
+	require.NoError(t, err)
+
+	someKey := random.GetRandomBytes(uint32(32))
+	someKeyEnc, err := s.Encrypt("", &secretlock.EncryptRequest{
+		Plaintext: string(someKey)})
+	require.NoError(t, err)
+
+	someKeyDec, err := s.Decrypt("", &secretlock.DecryptRequest{
+		Ciphertext: someKeyEnc.Ciphertext})
+	require.NoError(t, err)
+	require.Equal(t, someKey, []byte(someKeyDec.Plaintext))
+}
+
+func TestRotateMasterKey(t *testing.T) {
+	masterLocker, err := hkdf.NewMasterLock("secretPassphrase", sha256.New, nil)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name    string
+		secLock secretlock.Service
+	}{
+		{name: "unprotected master key"},
+		{name: "protected master key", secLock: masterLocker},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			masterKey := random.GetRandomBytes(uint32(32))
+			masterKeyContent := []byte(base64.URLEncoding.EncodeToString(masterKey))
+
+			if tc.secLock != nil {
+				encResp, e := tc.secLock.Encrypt("", &secretlock.EncryptRequest{Plaintext: string(masterKey)})
+				require.NoError(t, e)
+
+				masterKeyContent = []byte(encResp.Ciphertext)
+			}
+
+			s, err := NewService(bytes.NewReader(masterKeyContent), tc.secLock)
+			require.NoError(t, err)
+
+			oldEnc, err := s.Encrypt(testKeyURI, &secretlock.EncryptRequest{
+				Plaintext:                   "old key",
+				AdditionalAuthenticatedData: "aad",
+			})
+			require.NoError(t, err)
+
+			var newMasterKeyContent []byte
+
+			l, ok := s.(*Lock)
+			require.True(t, ok)
+
+			err = l.RotateMasterKey(func(protectedMasterKey []byte) error {
+				newMasterKeyContent = protectedMasterKey
+				return nil
+			})
+			require.NoError(t, err)
+			require.NotEmpty(t, newMasterKeyContent)
+
+			newEnc, err := s.Encrypt(testKeyURI, &secretlock.EncryptRequest{
+				Plaintext:                   "new key",
+				AdditionalAuthenticatedData: "aad",
+			})
+			require.NoError(t, err)
+
+			// while the rotation is in progress, both old and new keys are decrypted
+			decResp, err := s.Decrypt(testKeyURI, &secretlock.DecryptRequest{
+				Ciphertext:                  oldEnc.Ciphertext,
+				AdditionalAuthenticatedData: "aad",
+			})
+			require.NoError(t, err)
+			require.Equal(t, "old key", decResp.Plaintext)
+
+			// an interrupted rotation is resumed with the previous master key
+			resumed, err := NewService(bytes.NewReader(newMasterKeyContent), tc.secLock,
+				WithPreviousMasterKey(bytes.NewReader(masterKeyContent)))
+			require.NoError(t, err)
+
+			for pt, ct := range map[string]string{"old key": oldEnc.Ciphertext, "new key": newEnc.Ciphertext} {
+				decResp, err = resumed.Decrypt(testKeyURI, &secretlock.DecryptRequest{
+					Ciphertext:                  ct,
+					AdditionalAuthenticatedData: "aad",
+				})
+				require.NoError(t, err)
+				require.Equal(t, pt, decResp.Plaintext)
+			}
+
+			l.CompleteMasterKeyRotation()
+
+			_, err = s.Decrypt(testKeyURI, &secretlock.DecryptRequest{
+				Ciphertext:                  oldEnc.Ciphertext,
+				AdditionalAuthenticatedData: "aad",
+			})
+			require.Error(t, err)
+
+			// the new master key content is the one expected by NewService
+			s, err = NewService(bytes.NewReader(newMasterKeyContent), tc.secLock)
+			require.NoError(t, err)
+
+			decResp, err = s.Decrypt(testKeyURI, &secretlock.DecryptRequest{
+				Ciphertext:                  newEnc.Ciphertext,
+				AdditionalAuthenticatedData: "aad",
+			})
+			require.NoError(t, err)
+			require.Equal(t, "new key", decResp.Plaintext)
+		})
+	}
+
+	t.Run("persist error", func(t *testing.T) {
+		s, err := NewService(bytes.NewReader([]byte(base64.URLEncoding.EncodeToString(
+			random.GetRandomBytes(uint32(32))))), nil)
+		require.NoError(t, err)
+
+		l, ok := s.(*Lock)
+		require.True(t, ok)
+
+		enc, err := s.Encrypt(testKeyURI, &secretlock.EncryptRequest{Plaintext: "key"})
+		require.NoError(t, err)
+
+		err = l.RotateMasterKey(func([]byte) error {
+			return errors.New("write error")
+		})
+		require.EqualError(t, err, "failed to persist new master key: write error")
+
+		// the current master key is unchanged
+		l.CompleteMasterKeyRotation()
+
+		decResp, err := s.Decrypt(testKeyURI, &secretlock.DecryptRequest{Ciphertext: enc.Ciphertext})
+		require.NoError(t, err)
+		require.Equal(t, "key", decResp.Plaintext)
+	})
+
+	t.Run("protect error", func(t *testing.T) {
+		s, err := NewService(bytes.NewReader([]byte("masterKey")), &mocksecretlock.MockSecretLock{
+			ValDecrypt: string(random.GetRandomBytes(uint32(32))),
+			ErrEncrypt: errors.New("encrypt error"),
+		})
+		require.NoError(t, err)
+
+		l, ok := s.(*Lock)
+		require.True(t, ok)
+
+		err = l.RotateMasterKey(func([]byte) error {
+			return nil
+		})
+		require.EqualError(t, err, "failed to protect new master key: encrypt error")
+	})
+
+	t.Run("invalid previous master key", func(t *testing.T) {
+		_, err := NewService(bytes.NewReader([]byte(base64.URLEncoding.EncodeToString(
+			random.GetRandomBytes(uint32(32))))), nil, WithPreviousMasterKey(nil))
+		require.EqualError(t, err, "previous master key: masterKeyReader is nil")
+	})
+}
@@ -11,6 +11,8 @@ import (
 	"io"
 	"os"
 	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
 // MasterKeyFromPath creates a new instance of a local secret lock Reader to read a master key stored in `path`.
@@ -51,3 +53,14 @@ func MasterKeyFromEnv(envPrefix, keyURI string) (io.Reader, error) {
 
 	return bytes.NewReader([]byte(mk)), nil
 }
+
+// MasterKeyFromStore creates a new instance of a local secret lock Reader to read a master key stored in `store` under
+// `key`, such as the master keys persisted by the kms controller command RotateMasterKey.
+func MasterKeyFromStore(store storage.Store, key string) (io.Reader, error) {
+	mk, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(mk), nil
+}
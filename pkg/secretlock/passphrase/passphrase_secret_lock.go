@@ -0,0 +1,260 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package passphrase provides a secret lock service protected by a user passphrase.
+//
+// A random master key is created the first time the service is started on a store. It is wrapped (encrypted) with a
+// key derived from the passphrase using Argon2id and stored along with the salt and the KDF parameters in the
+// `Namespace` store. The following starts unwrap the master key with the same passphrase.
+//
+// Keys are encrypted with the master key exactly like the local secret lock service does. Since the master key never
+// changes, ChangePassphrase() only re-wraps it and keys already encrypted by the lock remain readable.
+package passphrase
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/tink/go/subtle/random"
+	"golang.org/x/crypto/argon2"
+
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/local"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// Namespace is the store namespace of the passphrase secret lock state.
+	Namespace = "passphraselock"
+
+	stateKey     = "masterkey"
+	stateVersion = 1
+
+	kdfAlg        = "argon2id"
+	masterKeySize = 32
+	saltSize      = 16
+
+	// DefaultTime is the default Argon2id number of passes.
+	DefaultTime = 3
+	// DefaultMemory is the default Argon2id memory size in KiB.
+	DefaultMemory = 64 * 1024
+	// DefaultThreads is the default Argon2id parallelism.
+	DefaultThreads = 4
+)
+
+// ErrInvalidPassphrase is returned when the passphrase does not unwrap the stored master key.
+var ErrInvalidPassphrase = errors.New("invalid passphrase")
+
+// Opt is a passphrase secret lock option.
+type Opt func(p *kdfParams)
+
+// WithKDFParams sets the Argon2id parameters used to derive the passphrase key: number of passes `time`, memory size
+// in KiB `memory` and parallelism `threads`. The parameters are stored with the master key, they are used when the
+// master key is created or re-wrapped by ChangePassphrase() only.
+func WithKDFParams(time, memory uint32, threads uint8) Opt {
+	return func(p *kdfParams) {
+		p.Time = time
+		p.Memory = memory
+		p.Threads = threads
+	}
+}
+
+type kdfParams struct {
+	Alg     string `json:"alg"`
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+type lockState struct {
+	Version    int       `json:"version"`
+	KDF        kdfParams `json:"kdf"`
+	WrappedKey []byte    `json:"wrappedKey"`
+}
+
+// Lock is a secret lock service encrypting keys with a master key protected by a passphrase.
+type Lock struct {
+	secretlock.Service
+	store     storage.Store
+	params    kdfParams
+	masterKey []byte
+	mutex     sync.Mutex
+}
+
+// New creates a passphrase secret lock service using `passphrase` and the lock state found in the `Namespace` store
+// of storeProvider. If the store has no state yet, a new master key is created, wrapped with `passphrase` and stored.
+// It returns ErrInvalidPassphrase if `passphrase` does not match the stored state.
+func New(passphrase string, storeProvider storage.Provider, opts ...Opt) (*Lock, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is empty")
+	}
+
+	store, err := storeProvider.OpenStore(Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open passphrase lock store: %w", err)
+	}
+
+	params := kdfParams{Alg: kdfAlg, Time: DefaultTime, Memory: DefaultMemory, Threads: DefaultThreads}
+
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	l := &Lock{store: store, params: params}
+
+	state, err := l.readState()
+
+	switch {
+	case errors.Is(err, storage.ErrDataNotFound):
+		l.masterKey = random.GetRandomBytes(masterKeySize)
+
+		err = l.writeState(passphrase)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		l.masterKey, err = unwrapMasterKey(state, passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l.Service, err = local.NewService(
+		bytes.NewReader([]byte(base64.URLEncoding.EncodeToString(l.masterKey))), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// ChangePassphrase re-wraps the master key with newPassphrase using a new salt. currentPassphrase must match the
+// stored state. Keys encrypted by the lock are not affected.
+func (l *Lock) ChangePassphrase(currentPassphrase, newPassphrase string) error {
+	if newPassphrase == "" {
+		return fmt.Errorf("change passphrase: new passphrase is empty")
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	state, err := l.readState()
+	if err != nil {
+		return fmt.Errorf("change passphrase: %w", err)
+	}
+
+	_, err = unwrapMasterKey(state, currentPassphrase)
+	if err != nil {
+		return fmt.Errorf("change passphrase: %w", err)
+	}
+
+	err = l.writeState(newPassphrase)
+	if err != nil {
+		return fmt.Errorf("change passphrase: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Lock) readState() (*lockState, error) {
+	stateBytes, err := l.store.Get(stateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &lockState{}
+
+	err = json.Unmarshal(stateBytes, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal passphrase lock state: %w", err)
+	}
+
+	if state.Version != stateVersion {
+		return nil, fmt.Errorf("unsupported passphrase lock state version %d", state.Version)
+	}
+
+	if state.KDF.Alg != kdfAlg {
+		return nil, fmt.Errorf("unsupported passphrase lock key derivation '%s'", state.KDF.Alg)
+	}
+
+	return state, nil
+}
+
+func (l *Lock) writeState(passphrase string) error {
+	state := &lockState{Version: stateVersion, KDF: l.params}
+	state.KDF.Salt = random.GetRandomBytes(saltSize)
+
+	aead, aad, err := newKEKCipher(state, passphrase)
+	if err != nil {
+		return err
+	}
+
+	nonce := random.GetRandomBytes(uint32(aead.NonceSize()))
+	state.WrappedKey = append(nonce, aead.Seal(nil, nonce, l.masterKey, aad)...)
+
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal passphrase lock state: %w", err)
+	}
+
+	return l.store.Put(stateKey, stateBytes)
+}
+
+func unwrapMasterKey(state *lockState, passphrase string) ([]byte, error) {
+	aead, aad, err := newKEKCipher(state, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(state.WrappedKey) <= aead.NonceSize() {
+		return nil, fmt.Errorf("invalid wrapped master key")
+	}
+
+	nonce := state.WrappedKey[:aead.NonceSize()]
+
+	masterKey, err := aead.Open(nil, nonce, state.WrappedKey[aead.NonceSize():], aad)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	return masterKey, nil
+}
+
+// newKEKCipher derives the key encryption key from passphrase and the state KDF parameters. The KDF parameters are
+// returned as additional authenticated data to bind them to the wrapped master key.
+func newKEKCipher(state *lockState, passphrase string) (cipher.AEAD, []byte, error) {
+	if state.KDF.Time == 0 || state.KDF.Memory == 0 || state.KDF.Threads == 0 {
+		return nil, nil, fmt.Errorf("invalid passphrase lock key derivation parameters")
+	}
+
+	aad, err := json.Marshal(state.KDF)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	kek := argon2.IDKey([]byte(passphrase), state.KDF.Salt, state.KDF.Time, state.KDF.Memory, state.KDF.Threads,
+		masterKeySize)
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return aead, aad, nil
+}
@@ -0,0 +1,200 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package passphrase
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+// small Argon2id parameters to keep tests fast.
+var testKDFParams = WithKDFParams(1, 64, 1)
+
+func TestNew(t *testing.T) {
+	t.Run("create then reopen with the same passphrase", func(t *testing.T) {
+		storeProvider := mem.NewProvider()
+
+		l, err := New("passphrase", storeProvider, testKDFParams)
+		require.NoError(t, err)
+
+		encResp, err := l.Encrypt("", &secretlock.EncryptRequest{
+			Plaintext:                   "key",
+			AdditionalAuthenticatedData: "aad",
+		})
+		require.NoError(t, err)
+
+		l2, err := New("passphrase", storeProvider)
+		require.NoError(t, err)
+		require.Equal(t, l.masterKey, l2.masterKey)
+
+		decResp, err := l2.Decrypt("", &secretlock.DecryptRequest{
+			Ciphertext:                  encResp.Ciphertext,
+			AdditionalAuthenticatedData: "aad",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "key", decResp.Plaintext)
+
+		// stored parameters are used, not the options
+		state, err := l2.readState()
+		require.NoError(t, err)
+		require.EqualValues(t, 1, state.KDF.Time)
+		require.EqualValues(t, 64, state.KDF.Memory)
+		require.EqualValues(t, 1, state.KDF.Threads)
+		require.Len(t, state.KDF.Salt, saltSize)
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		storeProvider := mem.NewProvider()
+
+		_, err := New("passphrase", storeProvider, testKDFParams)
+		require.NoError(t, err)
+
+		_, err = New("other passphrase", storeProvider)
+		require.True(t, errors.Is(err, ErrInvalidPassphrase))
+	})
+
+	t.Run("empty passphrase", func(t *testing.T) {
+		_, err := New("", mem.NewProvider())
+		require.EqualError(t, err, "passphrase is empty")
+	})
+
+	t.Run("store errors", func(t *testing.T) {
+		_, err := New("passphrase", &mockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")})
+		require.EqualError(t, err, "failed to open passphrase lock store: open error")
+
+		_, err = New("passphrase", mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+			Store:  map[string][]byte{},
+			ErrGet: errors.New("get error"),
+		}))
+		require.EqualError(t, err, "get error")
+
+		_, err = New("passphrase", mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+			Store:  map[string][]byte{},
+			ErrPut: errors.New("put error"),
+		}), testKDFParams)
+		require.EqualError(t, err, "put error")
+	})
+
+	t.Run("invalid stored state", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			state string
+			err   string
+		}{
+			{
+				name:  "not JSON",
+				state: "{",
+				err:   "failed to unmarshal passphrase lock state",
+			},
+			{
+				name:  "unsupported version",
+				state: `{"version":2}`,
+				err:   "unsupported passphrase lock state version 2",
+			},
+			{
+				name:  "unsupported KDF",
+				state: `{"version":1,"kdf":{"alg":"scrypt"}}`,
+				err:   "unsupported passphrase lock key derivation 'scrypt'",
+			},
+			{
+				name:  "invalid KDF parameters",
+				state: `{"version":1,"kdf":{"alg":"argon2id"}}`,
+				err:   "invalid passphrase lock key derivation parameters",
+			},
+			{
+				name:  "invalid wrapped key",
+				state: `{"version":1,"kdf":{"alg":"argon2id","time":1,"memory":64,"threads":1}}`,
+				err:   "invalid wrapped master key",
+			},
+		}
+
+		for _, tc := range tests {
+			_, err := New("passphrase", mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+				Store: map[string][]byte{stateKey: []byte(tc.state)},
+			}))
+			require.Error(t, err, tc.name)
+			require.Contains(t, err.Error(), tc.err, tc.name)
+		}
+	})
+
+	t.Run("tampered KDF parameters", func(t *testing.T) {
+		db := map[string][]byte{}
+		storeProvider := mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{Store: db})
+
+		_, err := New("passphrase", storeProvider, testKDFParams)
+		require.NoError(t, err)
+
+		state := &lockState{}
+		require.NoError(t, json.Unmarshal(db[stateKey], state))
+
+		state.KDF.Memory = 32
+		db[stateKey], err = json.Marshal(state)
+		require.NoError(t, err)
+
+		_, err = New("passphrase", storeProvider)
+		require.True(t, errors.Is(err, ErrInvalidPassphrase))
+	})
+}
+
+func TestLock_ChangePassphrase(t *testing.T) {
+	storeProvider := mem.NewProvider()
+
+	l, err := New("passphrase", storeProvider, testKDFParams)
+	require.NoError(t, err)
+
+	encResp, err := l.Encrypt("", &secretlock.EncryptRequest{Plaintext: "key"})
+	require.NoError(t, err)
+
+	t.Run("wrong current passphrase", func(t *testing.T) {
+		err = l.ChangePassphrase("wrong", "new passphrase")
+		require.True(t, errors.Is(err, ErrInvalidPassphrase))
+	})
+
+	t.Run("empty new passphrase", func(t *testing.T) {
+		err = l.ChangePassphrase("passphrase", "")
+		require.EqualError(t, err, "change passphrase: new passphrase is empty")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		err = l.ChangePassphrase("passphrase", "new passphrase")
+		require.NoError(t, err)
+
+		_, err = New("passphrase", storeProvider)
+		require.True(t, errors.Is(err, ErrInvalidPassphrase))
+
+		l2, err := New("new passphrase", storeProvider)
+		require.NoError(t, err)
+
+		// keys encrypted before the change are still readable
+		decResp, err := l2.Decrypt("", &secretlock.DecryptRequest{Ciphertext: encResp.Ciphertext})
+		require.NoError(t, err)
+		require.Equal(t, "key", decResp.Plaintext)
+	})
+
+	t.Run("store errors", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{}}
+
+		l, err := New("passphrase", mockstorage.NewCustomMockStoreProvider(store), testKDFParams)
+		require.NoError(t, err)
+
+		store.ErrPut = errors.New("put error")
+
+		err = l.ChangePassphrase("passphrase", "new passphrase")
+		require.EqualError(t, err, "change passphrase: put error")
+
+		store.ErrGet = errors.New("get error")
+
+		err = l.ChangePassphrase("passphrase", "new passphrase")
+		require.EqualError(t, err, "change passphrase: get error")
+	})
+}
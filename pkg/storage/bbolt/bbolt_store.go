@@ -0,0 +1,621 @@
+// +build !js,!wasm
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbolt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	fileMode      = 0600
+	compactSuffix = ".compact"
+	backupSuffix  = ".backup"
+	// defaultTimeout is how long opening the database waits for the file lock held by another process by default
+	defaultTimeout = 10 * time.Second
+	// compactTxMaxSize is the number of bytes copied in a transaction of the compacted database
+	compactTxMaxSize = 64 * 1024 * 1024
+	tagSeparator     = "\x00"
+)
+
+var (
+	// the bucket of a store holds the records bucket and the tags buckets
+	recordsBucket  = []byte("records")
+	tagsBucket     = []byte("tags")
+	tagIndexBucket = []byte("tagindex")
+)
+
+// the file operations replacing the database file by its compacted copy, replaced in the tests to make them fail
+var (
+	renameFile = os.Rename
+	openDB     = bolt.Open
+)
+
+// Provider bbolt implementation of storage.Provider interface. The stores are kept in one bbolt database file,
+// one bucket per store. The file is locked while the provider is open.
+type Provider struct {
+	path    string
+	options *bolt.Options
+	db      *bolt.DB
+	dbs     map[string]*bboltStore
+	lock    sync.RWMutex
+}
+
+// Option configures the provider.
+type Option func(opts *bolt.Options)
+
+// WithTimeout sets how long opening the database waits for the file lock held by another process, 10 seconds by
+// default. It waits indefinitely with a zero timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(opts *bolt.Options) {
+		opts.Timeout = timeout
+	}
+}
+
+// WithNoSync skips the fsync of the database file after each commit, trading durability for speed (eg. in tests).
+func WithNoSync() Option {
+	return func(opts *bolt.Options) {
+		opts.NoSync = true
+	}
+}
+
+// NewProvider instantiates Provider, opening (and creating if needed) the bbolt database file at path.
+func NewProvider(path string, opts ...Option) (*Provider, error) {
+	options := &bolt.Options{Timeout: defaultTimeout}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	db, err := bolt.Open(path, fileMode, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bbolt database %s: %w", path, err)
+	}
+
+	return &Provider{path: path, options: options, db: db, dbs: make(map[string]*bboltStore)}, nil
+}
+
+// OpenStore opens and returns a store for given name space, its bucket is created if it doesn't exist.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	name = strings.ToLower(name)
+
+	if store, ok := p.dbs[name]; ok {
+		return store, nil
+	}
+
+	err := p.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(name))
+		if err != nil {
+			return err
+		}
+
+		for _, b := range [][]byte{recordsBucket, tagsBucket, tagIndexBucket} {
+			if _, err = bucket.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bucket %s: %w", name, err)
+	}
+
+	store := &bboltStore{provider: p, name: []byte(name)}
+	p.dbs[name] = store
+
+	return store, nil
+}
+
+// Close closes the database file, and the stores created under this store provider.
+func (p *Provider) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.dbs = make(map[string]*bboltStore)
+
+	return p.db.Close()
+}
+
+// CloseStore closes the store of given name, the database file stays open for the other stores.
+func (p *Provider) CloseStore(name string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.dbs, strings.ToLower(name))
+
+	return nil
+}
+
+// Compact rewrites the database file without its free pages, shrinking the file after records were deleted.
+// The stores are unavailable while the database is compacted.
+func (p *Provider) Compact() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	compactPath := p.path + compactSuffix
+
+	// remove the leftover of a failed compaction
+	if err := os.Remove(compactPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", compactPath, err)
+	}
+
+	dst, err := bolt.Open(compactPath, fileMode, p.options)
+	if err != nil {
+		return fmt.Errorf("failed to open compacted database: %w", err)
+	}
+
+	err = compact(dst, p.db)
+
+	if errClose := dst.Close(); errClose != nil && err == nil {
+		err = errClose
+	}
+
+	if err != nil {
+		_ = os.Remove(compactPath) //nolint:errcheck
+
+		return fmt.Errorf("failed to compact database: %w", err)
+	}
+
+	if err = p.db.Close(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+
+	return p.replace(compactPath)
+}
+
+// replace replaces the closed database file by the compacted database and opens it. The original file is kept as a
+// backup until the compacted database is opened, it is restored and opened again if the replacement fails.
+func (p *Provider) replace(compactPath string) error {
+	backupPath := p.path + backupSuffix
+
+	if err := renameFile(p.path, backupPath); err != nil {
+		_ = os.Remove(compactPath) //nolint:errcheck
+
+		return p.reopen("", fmt.Errorf("failed to back up database: %w", err))
+	}
+
+	if err := renameFile(compactPath, p.path); err != nil {
+		_ = os.Remove(compactPath) //nolint:errcheck
+
+		return p.reopen(backupPath, fmt.Errorf("failed to replace database: %w", err))
+	}
+
+	db, err := openDB(p.path, fileMode, p.options)
+	if err != nil {
+		return p.reopen(backupPath, fmt.Errorf("failed to open compacted database: %w", err))
+	}
+
+	p.db = db
+
+	// the compaction is done, the backup is only left behind on failure
+	_ = os.Remove(backupPath) //nolint:errcheck
+
+	return nil
+}
+
+// reopen restores the original database file from backupPath (unless empty) and opens it after the failure err of
+// its replacement, err is returned with the failure of the restoration if any.
+func (p *Provider) reopen(backupPath string, err error) error {
+	if backupPath != "" {
+		if errRename := renameFile(backupPath, p.path); errRename != nil {
+			return fmt.Errorf("%w (failed to restore database from %s: %v)", err, backupPath, errRename)
+		}
+	}
+
+	db, errOpen := openDB(p.path, fileMode, p.options)
+	if errOpen != nil {
+		return fmt.Errorf("%w (failed to reopen database: %v)", err, errOpen)
+	}
+
+	p.db = db
+
+	return err
+}
+
+// compact copies the buckets of src to dst, in transactions of at most compactTxMaxSize bytes.
+func compact(dst, src *bolt.DB) error {
+	var size int64
+
+	tx, err := dst.Begin(true)
+	if err != nil {
+		return err
+	}
+
+	err = src.View(func(srcTx *bolt.Tx) error {
+		return walk(srcTx, func(path [][]byte, k, v []byte, seq uint64) error {
+			// commit the transaction when it is too big, and start a new one
+			if size+int64(len(k)+len(v)) > compactTxMaxSize {
+				if errCommit := tx.Commit(); errCommit != nil {
+					return errCommit
+				}
+
+				next, errBegin := dst.Begin(true)
+				if errBegin != nil {
+					return errBegin
+				}
+
+				tx, size = next, 0
+			}
+
+			size += int64(len(k) + len(v))
+
+			return copyEntry(tx, path, k, v, seq)
+		})
+	})
+	if err != nil {
+		// the transaction may have been committed already
+		_ = tx.Rollback() //nolint:errcheck
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// copyEntry copies the bucket (if v is nil) or the record k of the bucket path into tx.
+func copyEntry(tx *bolt.Tx, path [][]byte, k, v []byte, seq uint64) error {
+	if len(path) == 0 {
+		// root bucket
+		bucket, err := tx.CreateBucket(k)
+		if err != nil {
+			return err
+		}
+
+		return bucket.SetSequence(seq)
+	}
+
+	bucket := tx.Bucket(path[0])
+	for _, name := range path[1:] {
+		bucket = bucket.Bucket(name)
+	}
+
+	// the records are copied in order, pages are filled completely
+	bucket.FillPercent = 1.0
+
+	if v != nil {
+		return bucket.Put(k, v)
+	}
+
+	nested, err := bucket.CreateBucket(k)
+	if err != nil {
+		return err
+	}
+
+	return nested.SetSequence(seq)
+}
+
+type walkFunc func(path [][]byte, k, v []byte, seq uint64) error
+
+// walk calls fn for the buckets and the records of tx, recursively.
+func walk(tx *bolt.Tx, fn walkFunc) error {
+	return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+		return walkBucket(b, nil, name, nil, b.Sequence(), fn)
+	})
+}
+
+func walkBucket(b *bolt.Bucket, path [][]byte, k, v []byte, seq uint64, fn walkFunc) error {
+	if err := fn(path, k, v, seq); err != nil {
+		return err
+	}
+
+	// records have no nested records
+	if v != nil {
+		return nil
+	}
+
+	path = append(append([][]byte{}, path...), k)
+
+	return b.ForEach(func(k, v []byte) error {
+		if v == nil {
+			nested := b.Bucket(k)
+
+			return walkBucket(nested, path, k, nil, nested.Sequence(), fn)
+		}
+
+		return walkBucket(b, path, k, v, b.Sequence(), fn)
+	})
+}
+
+type bboltStore struct {
+	provider *Provider
+	name     []byte
+}
+
+// update runs fn in a read-write transaction with the buckets of the store.
+func (s *bboltStore) update(fn func(records, tags, index *bolt.Bucket) error) error {
+	s.provider.lock.RLock()
+	defer s.provider.lock.RUnlock()
+
+	return s.provider.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(s.name)
+
+		return fn(bucket.Bucket(recordsBucket), bucket.Bucket(tagsBucket), bucket.Bucket(tagIndexBucket))
+	})
+}
+
+// view runs fn in a read-only transaction with the buckets of the store.
+func (s *bboltStore) view(fn func(records, tags, index *bolt.Bucket) error) error {
+	s.provider.lock.RLock()
+	defer s.provider.lock.RUnlock()
+
+	return s.provider.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(s.name)
+
+		return fn(bucket.Bucket(recordsBucket), bucket.Bucket(tagsBucket), bucket.Bucket(tagIndexBucket))
+	})
+}
+
+// Put stores the key and the record with its tags.
+func (s *bboltStore) Put(k string, v []byte, tags ...storage.Tag) error {
+	if k == "" || v == nil {
+		return errors.New("key and value are mandatory")
+	}
+
+	return s.Batch([]storage.Operation{storage.PutOperation(k, v, tags...)})
+}
+
+// Batch applies the operations atomically in a bbolt read-write transaction.
+func (s *bboltStore) Batch(operations []storage.Operation) error {
+	for _, op := range operations {
+		if op.Key == "" {
+			return storage.ErrKeyRequired
+		}
+
+		if !op.IsDelete() && op.Value == nil {
+			return errors.New("key and value are mandatory")
+		}
+	}
+
+	return s.update(func(records, tags, index *bolt.Bucket) error {
+		for _, op := range operations {
+			err := deleteTags(tags, index, op.Key)
+			if err != nil {
+				return err
+			}
+
+			if op.IsDelete() {
+				if err = records.Delete([]byte(op.Key)); err != nil {
+					return fmt.Errorf("failed to delete record: %w", err)
+				}
+
+				continue
+			}
+
+			if err = records.Put([]byte(op.Key), op.Value); err != nil {
+				return fmt.Errorf("failed to put record: %w", err)
+			}
+
+			if err = putTags(tags, index, op.Key, op.Tags); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func putTags(tags, index *bolt.Bucket, k string, recordTags []storage.Tag) error {
+	if len(recordTags) == 0 {
+		return nil
+	}
+
+	tagsBytes, err := json.Marshal(recordTags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	if err = tags.Put([]byte(k), tagsBytes); err != nil {
+		return fmt.Errorf("failed to put tags: %w", err)
+	}
+
+	for _, tag := range recordTags {
+		if err = index.Put(tagIndexKey(tag.Name, tag.Value, k), []byte{}); err != nil {
+			return fmt.Errorf("failed to put tag index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func deleteTags(tags, index *bolt.Bucket, k string) error {
+	tagsBytes := tags.Get([]byte(k))
+	if tagsBytes == nil {
+		return nil
+	}
+
+	var recordTags []storage.Tag
+
+	err := json.Unmarshal(tagsBytes, &recordTags)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal tags: %w", err)
+	}
+
+	for _, tag := range recordTags {
+		if err = index.Delete(tagIndexKey(tag.Name, tag.Value, k)); err != nil {
+			return fmt.Errorf("failed to delete tag index: %w", err)
+		}
+	}
+
+	if err = tags.Delete([]byte(k)); err != nil {
+		return fmt.Errorf("failed to delete tags: %w", err)
+	}
+
+	return nil
+}
+
+func tagIndexKey(name, value, k string) []byte {
+	return []byte(name + tagSeparator + value + tagSeparator + k)
+}
+
+// Get fetches the record based on key
+func (s *bboltStore) Get(k string) ([]byte, error) {
+	if k == "" {
+		return nil, storage.ErrKeyRequired
+	}
+
+	var data []byte
+
+	err := s.view(func(records, _, _ *bolt.Bucket) error {
+		v := records.Get([]byte(k))
+		if v == nil {
+			return storage.ErrDataNotFound
+		}
+
+		// the value is only valid during the transaction
+		data = append([]byte{}, v...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Delete will delete record with k key
+func (s *bboltStore) Delete(k string) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	return s.Batch([]storage.Operation{storage.DeleteOperation(k)})
+}
+
+// Iterator returns iterator over the records in the range [start, limit) ordered by key, read from a snapshot
+// of the store.
+func (s *bboltStore) Iterator(start, limit string) storage.StoreIterator {
+	limitKey := []byte(strings.ReplaceAll(limit, storage.EndKeySuffix, "~"))
+
+	var items []item
+
+	err := s.view(func(records, _, _ *bolt.Bucket) error {
+		c := records.Cursor()
+
+		for k, v := c.Seek([]byte(start)); k != nil && bytes.Compare(k, limitKey) < 0; k, v = c.Next() {
+			items = append(items, item{key: append([]byte{}, k...), value: append([]byte{}, v...)})
+		}
+
+		return nil
+	})
+
+	return &iterator{items: items, err: err}
+}
+
+// Query returns iterator over the records having a tag matching the expression, ordered by tag value and key, read
+// from a snapshot of the store.
+func (s *bboltStore) Query(expression string, _ ...storage.QueryOption) (storage.StoreIterator, error) {
+	query, err := storage.ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := []byte(query.Name + tagSeparator)
+	if !query.AnyValue {
+		prefix = append(prefix, query.Value+tagSeparator...)
+	}
+
+	var items []item
+
+	err = s.view(func(records, _, index *bolt.Bucket) error {
+		c := index.Cursor()
+
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			key := k[len(prefix):]
+
+			if query.AnyValue {
+				// the index key suffix is value + separator + key
+				key = key[bytes.Index(key, []byte(tagSeparator))+1:]
+			}
+
+			v := records.Get(key)
+			if v == nil {
+				return fmt.Errorf("failed to get record '%s': %w", key, storage.ErrDataNotFound)
+			}
+
+			items = append(items, item{key: append([]byte{}, key...), value: append([]byte{}, v...)})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &iterator{items: items}, nil
+}
+
+type item struct {
+	key   []byte
+	value []byte
+}
+
+// iterator iterates over the records copied from a read-only transaction, the transaction is not kept open
+// while iterating so that the records can be updated meanwhile.
+type iterator struct {
+	items   []item
+	current int
+	err     error
+}
+
+// Next moves the iterator to the next record.
+func (i *iterator) Next() bool {
+	if i.current >= len(i.items) {
+		i.items = nil
+
+		return false
+	}
+
+	i.current++
+
+	return true
+}
+
+// Release releases the records of the iterator.
+func (i *iterator) Release() {
+	i.items = nil
+	i.current = 0
+}
+
+// Error returns the error of the iterator.
+func (i *iterator) Error() error {
+	return i.err
+}
+
+// Key returns the key of the current record.
+func (i *iterator) Key() []byte {
+	if i.current == 0 || i.current > len(i.items) {
+		return nil
+	}
+
+	return i.items[i.current-1].key
+}
+
+// Value returns the value of the current record.
+func (i *iterator) Value() []byte {
+	if i.current == 0 || i.current > len(i.items) {
+		return nil
+	}
+
+	return i.items[i.current-1].value
+}
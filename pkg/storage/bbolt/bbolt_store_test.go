@@ -0,0 +1,596 @@
+// +build !js,!wasm
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbolt
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/storagetest"
+)
+
+func setupBBolt(t testing.TB) (string, func()) {
+	dbPath, err := ioutil.TempDir("", "bbolt")
+	if err != nil {
+		t.Fatalf("Failed to create bbolt directory: %s", err)
+	}
+
+	return filepath.Join(dbPath, "aries.db"), func() {
+		err := os.RemoveAll(dbPath)
+		if err != nil {
+			t.Fatalf("Failed to clear bbolt directory: %s", err)
+		}
+	}
+}
+
+func TestBBoltStore(t *testing.T) {
+	path, cleanup := setupBBolt(t)
+	defer cleanup()
+
+	t.Run("Test bbolt store put and get", func(t *testing.T) {
+		prov, err := NewProvider(path, WithNoSync())
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		const key = "did:example:123"
+		data := []byte("value")
+
+		err = store.Put(key, data)
+		require.NoError(t, err)
+
+		doc, err := store.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, data, doc)
+
+		// empty value
+		err = store.Put("empty", []byte{})
+		require.NoError(t, err)
+
+		doc, err = store.Get("empty")
+		require.NoError(t, err)
+		require.Empty(t, doc)
+
+		_, err = store.Get("did:example:789")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		// nil key
+		_, err = store.Get("")
+		require.True(t, errors.Is(err, storage.ErrKeyRequired))
+
+		// nil value
+		err = store.Put(key, nil)
+		require.Error(t, err)
+
+		// nil key
+		err = store.Put("", data)
+		require.Error(t, err)
+
+		err = prov.Close()
+		require.NoError(t, err)
+
+		// try to get after provider is closed
+		_, err = store.Get(key)
+		require.Error(t, err)
+	})
+
+	t.Run("Test bbolt stores are persisted and isolated", func(t *testing.T) {
+		prov, err := NewProvider(path)
+		require.NoError(t, err)
+
+		store1, err := prov.OpenStore("store1")
+		require.NoError(t, err)
+
+		// store names are case insensitive
+		store1Upper, err := prov.OpenStore("STORE1")
+		require.NoError(t, err)
+		require.Equal(t, store1, store1Upper)
+
+		store2, err := prov.OpenStore("store2")
+		require.NoError(t, err)
+
+		require.NoError(t, store1.Put("key", []byte("value1")))
+		require.NoError(t, store2.Put("key", []byte("value2")))
+
+		require.NoError(t, prov.CloseStore("store1"))
+		require.NoError(t, prov.CloseStore("unknown"))
+		require.NoError(t, prov.Close())
+
+		prov, err = NewProvider(path)
+		require.NoError(t, err)
+
+		store1, err = prov.OpenStore("store1")
+		require.NoError(t, err)
+
+		value, err := store1.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value1"), value)
+
+		store2, err = prov.OpenStore("store2")
+		require.NoError(t, err)
+
+		value, err = store2.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), value)
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test bbolt database file is locked", func(t *testing.T) {
+		prov, err := NewProvider(path)
+		require.NoError(t, err)
+
+		_, err = NewProvider(path, WithTimeout(50*time.Millisecond))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open bbolt database")
+		require.True(t, errors.Is(err, bolt.ErrTimeout))
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test bbolt open store failure", func(t *testing.T) {
+		prov, err := NewProvider(path)
+		require.NoError(t, err)
+
+		_, err = prov.OpenStore("")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create bucket")
+
+		require.NoError(t, prov.Close())
+	})
+}
+
+func TestBBoltStoreIterator(t *testing.T) {
+	path, cleanup := setupBBolt(t)
+	defer cleanup()
+
+	prov, err := NewProvider(path)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	store, err := prov.OpenStore("test-iterator")
+	require.NoError(t, err)
+
+	const valPrefix = "val-for-%s"
+	keys := []string{"abc_123", "abc_124", "abc_125", "abc_126", "jkl_123", "mno_123", "dab_123"}
+
+	for _, key := range keys {
+		err = store.Put(key, []byte(fmt.Sprintf(valPrefix, key)), storage.Tag{Name: "tag"})
+		require.NoError(t, err)
+	}
+
+	verifyItr := func(itr storage.StoreIterator, expected ...string) {
+		var found []string
+
+		for itr.Next() {
+			require.Equal(t, fmt.Sprintf(valPrefix, itr.Key()), string(itr.Value()))
+
+			found = append(found, string(itr.Key()))
+		}
+
+		require.NoError(t, itr.Error())
+		require.Equal(t, expected, found)
+
+		itr.Release()
+		require.False(t, itr.Next())
+		require.Nil(t, itr.Key())
+		require.Nil(t, itr.Value())
+	}
+
+	verifyItr(store.Iterator("abc_", "abc_"+storage.EndKeySuffix), "abc_123", "abc_124", "abc_125", "abc_126")
+	verifyItr(store.Iterator("abc_", "mno_"+storage.EndKeySuffix),
+		"abc_123", "abc_124", "abc_125", "abc_126", "dab_123", "jkl_123", "mno_123")
+	verifyItr(store.Iterator("abc_", "mno_123"),
+		"abc_123", "abc_124", "abc_125", "abc_126", "dab_123", "jkl_123")
+	verifyItr(store.Iterator("", ""))
+
+	t.Run("records can be updated while iterating", func(t *testing.T) {
+		itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+
+		for itr.Next() {
+			require.NoError(t, store.Delete(string(itr.Key())))
+		}
+
+		itr.Release()
+
+		verifyItr(store.Iterator("abc_", "abc_"+storage.EndKeySuffix))
+	})
+
+	t.Run("iterator failure", func(t *testing.T) {
+		closedProv, err := NewProvider(path + "-closed")
+		require.NoError(t, err)
+
+		closedStore, err := closedProv.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, closedProv.Close())
+
+		itr := closedStore.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+		require.False(t, itr.Next())
+		require.Error(t, itr.Error())
+	})
+}
+
+func TestBBoltStoreDelete(t *testing.T) {
+	path, cleanup := setupBBolt(t)
+	defer cleanup()
+
+	prov, err := NewProvider(path)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	const commonKey = "did:example:1234"
+
+	err = store.Put(commonKey, []byte("value"), storage.Tag{Name: "type", Value: "a"})
+	require.NoError(t, err)
+
+	// delete the record and its tags
+	err = store.Delete(commonKey)
+	require.NoError(t, err)
+
+	_, err = store.Get(commonKey)
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+	itr, err := store.Query("type")
+	require.NoError(t, err)
+	require.False(t, itr.Next())
+
+	// delete a record that doesn't exist
+	err = store.Delete(commonKey)
+	require.NoError(t, err)
+
+	// try to delete with empty key
+	err = store.Delete("")
+	require.True(t, errors.Is(err, storage.ErrKeyRequired))
+}
+
+func TestBBoltStoreQuery(t *testing.T) {
+	path, cleanup := setupBBolt(t)
+	defer cleanup()
+
+	prov, err := NewProvider(path)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	store, err := prov.OpenStore("test-query")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1"), storage.Tag{Name: "type", Value: "a"}))
+	require.NoError(t, store.Put("key2", []byte("value2"), storage.Tag{Name: "type", Value: "b"},
+		storage.Tag{Name: "other"}))
+	require.NoError(t, store.Put("key3", []byte("value3"), storage.Tag{Name: "type", Value: "a"}))
+	require.NoError(t, store.Put("key4", []byte("value4")))
+
+	query := func(expression string) []string {
+		itr, err := store.Query(expression)
+		require.NoError(t, err)
+
+		defer itr.Release()
+
+		var keys []string
+
+		for itr.Next() {
+			require.Equal(t, "value"+string(itr.Key())[3:], string(itr.Value()))
+
+			keys = append(keys, string(itr.Key()))
+		}
+
+		require.NoError(t, itr.Error())
+
+		return keys
+	}
+
+	require.Equal(t, []string{"key1", "key3"}, query("type:a"))
+	require.Equal(t, []string{"key1", "key3", "key2"}, query("type"))
+	require.Equal(t, []string{"key2"}, query("other"))
+	require.Empty(t, query("type:c"))
+
+	// the tags of an updated record are replaced
+	require.NoError(t, store.Put("key1", []byte("value1"), storage.Tag{Name: "type", Value: "b"}))
+	require.Equal(t, []string{"key3"}, query("type:a"))
+	require.Equal(t, []string{"key1", "key2"}, query("type:b"))
+
+	_, err = store.Query("")
+	require.True(t, errors.Is(err, storage.ErrInvalidQuery))
+}
+
+func TestBBoltStoreBatch(t *testing.T) {
+	path, cleanup := setupBBolt(t)
+	defer cleanup()
+
+	prov, err := NewProvider(path)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	store, err := prov.OpenStore("test-batch")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key0", []byte("value0"), storage.Tag{Name: "type", Value: "a"}))
+
+	err = store.Batch([]storage.Operation{
+		storage.PutOperation("key1", []byte("value1"), storage.Tag{Name: "type", Value: "a"}),
+		storage.PutOperation("key2", []byte("value2")),
+		storage.PutOperation("key2", []byte("value2"), storage.Tag{Name: "type", Value: "b"}),
+		storage.DeleteOperation("key0"),
+	})
+	require.NoError(t, err)
+
+	_, err = store.Get("key0")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+	value, err := store.Get("key2")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), value)
+
+	itr, err := store.Query("type")
+	require.NoError(t, err)
+
+	var keys []string
+	for itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+
+	require.Equal(t, []string{"key1", "key2"}, keys)
+
+	t.Run("batch with empty key is not applied", func(t *testing.T) {
+		err = store.Batch([]storage.Operation{
+			storage.DeleteOperation("key1"),
+			storage.PutOperation("", []byte("value")),
+		})
+		require.True(t, errors.Is(err, storage.ErrKeyRequired))
+
+		_, err = store.Get("key1")
+		require.NoError(t, err)
+	})
+
+	t.Run("batch with nil value is not applied", func(t *testing.T) {
+		err = store.Batch([]storage.Operation{
+			storage.DeleteOperation("key1"),
+			storage.PutOperation("key3", nil),
+		})
+		require.EqualError(t, err, "key and value are mandatory")
+
+		_, err = store.Get("key1")
+		require.NoError(t, err)
+	})
+
+	t.Run("batch failure is rolled back", func(t *testing.T) {
+		err = store.Batch([]storage.Operation{
+			storage.DeleteOperation("key1"),
+			storage.PutOperation(string(make([]byte, bolt.MaxKeySize+1)), []byte("value")),
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to put record")
+
+		_, err = store.Get("key1")
+		require.NoError(t, err)
+	})
+}
+
+func TestProviderCompact(t *testing.T) {
+	path, cleanup := setupBBolt(t)
+	defer cleanup()
+
+	prov, err := NewProvider(path)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("test-compact")
+	require.NoError(t, err)
+
+	value := make([]byte, 1024)
+
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, store.Put(fmt.Sprintf("key%d", i), value, storage.Tag{Name: "index", Value: fmt.Sprint(i)}))
+	}
+
+	for i := 10; i < 1000; i++ {
+		require.NoError(t, store.Delete(fmt.Sprintf("key%d", i)))
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	sizeBefore := info.Size()
+
+	require.NoError(t, prov.Compact())
+
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	require.Less(t, info.Size(), sizeBefore)
+
+	// the store is usable after the compaction
+	for i := 0; i < 10; i++ {
+		v, err := store.Get(fmt.Sprintf("key%d", i))
+		require.NoError(t, err)
+		require.Equal(t, value, v)
+	}
+
+	itr, err := store.Query("index:5")
+	require.NoError(t, err)
+	require.True(t, itr.Next())
+	require.Equal(t, "key5", string(itr.Key()))
+
+	require.NoError(t, store.Put("key1000", value))
+
+	t.Run("compact failure", func(t *testing.T) {
+		// a directory in place of the compacted database file can't be removed
+		require.NoError(t, os.MkdirAll(filepath.Join(path+compactSuffix, "dir"), 0700))
+
+		err = prov.Compact()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to remove")
+
+		require.NoError(t, os.RemoveAll(path+compactSuffix))
+	})
+
+	// the original database is reopened when it can't be replaced by the compacted database
+	requireReopened := func(t *testing.T) {
+		t.Helper()
+
+		_, err = store.Get("key1000")
+		require.NoError(t, err)
+
+		_, err = os.Stat(path + compactSuffix)
+		require.True(t, os.IsNotExist(err))
+	}
+
+	t.Run("backup failure", func(t *testing.T) {
+		// the database file can't be renamed to a directory which isn't empty
+		require.NoError(t, os.MkdirAll(filepath.Join(path+backupSuffix, "dir"), 0700))
+
+		err = prov.Compact()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to back up database")
+
+		requireReopened(t)
+		require.NoError(t, os.RemoveAll(path+backupSuffix))
+	})
+
+	t.Run("replace failure", func(t *testing.T) {
+		defer func() { renameFile = os.Rename }()
+
+		renameFile = func(oldPath, newPath string) error {
+			if oldPath == path+compactSuffix {
+				return errors.New("rename error")
+			}
+
+			return os.Rename(oldPath, newPath)
+		}
+
+		err = prov.Compact()
+		require.EqualError(t, err, "failed to replace database: rename error")
+
+		requireReopened(t)
+	})
+
+	t.Run("open compacted database failure", func(t *testing.T) {
+		defer func() { openDB = bolt.Open }()
+
+		openDB = func(string, os.FileMode, *bolt.Options) (*bolt.DB, error) {
+			openDB = bolt.Open
+
+			return nil, errors.New("open error")
+		}
+
+		err = prov.Compact()
+		require.EqualError(t, err, "failed to open compacted database: open error")
+
+		requireReopened(t)
+
+		_, err = os.Stat(path + backupSuffix)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	require.NoError(t, prov.Close())
+
+	// the compacted database is persisted
+	prov, err = NewProvider(path)
+	require.NoError(t, err)
+
+	store, err = prov.OpenStore("test-compact")
+	require.NoError(t, err)
+
+	_, err = store.Get("key1000")
+	require.NoError(t, err)
+
+	require.NoError(t, prov.Close())
+}
+
+func TestProviderCompactReopenFailure(t *testing.T) {
+	path, cleanup := setupBBolt(t)
+	defer cleanup()
+
+	prov, err := NewProvider(path)
+	require.NoError(t, err)
+
+	defer func() {
+		renameFile = os.Rename
+		openDB = bolt.Open
+	}()
+
+	openDB = func(string, os.FileMode, *bolt.Options) (*bolt.DB, error) {
+		return nil, errors.New("open error")
+	}
+
+	renameFile = func(oldPath, newPath string) error {
+		if oldPath == path+backupSuffix {
+			return errors.New("rename error")
+		}
+
+		return os.Rename(oldPath, newPath)
+	}
+
+	err = prov.Compact()
+	require.EqualError(t, err, "failed to open compacted database: open error (failed to restore database from "+
+		path+backupSuffix+": rename error)")
+
+	renameFile = func(string, string) error {
+		return errors.New("rename error")
+	}
+
+	err = prov.replace(path + compactSuffix)
+	require.EqualError(t, err, "failed to back up database: rename error (failed to reopen database: open error)")
+}
+
+func TestProviderTimeout(t *testing.T) {
+	path, cleanup := setupBBolt(t)
+	defer cleanup()
+
+	prov, err := NewProvider(path)
+	require.NoError(t, err)
+	require.Equal(t, defaultTimeout, prov.options.Timeout)
+
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	// the file is locked by the open provider
+	_, err = NewProvider(path, WithTimeout(10*time.Millisecond))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timeout")
+}
+
+func TestBBoltStoreConformance(t *testing.T) {
+	path, cleanup := setupBBolt(t)
+	defer cleanup()
+
+	prov, err := NewProvider(path, WithNoSync())
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	storagetest.TestAll(t, prov)
+}
@@ -37,6 +37,8 @@ const (
 	blankHostErrMsg           = "hostURL for new CouchDB provider can't be blank"
 	failToCloseProviderErrMsg = "failed to close provider"
 	couchDBNotFoundErr        = "Not Found:"
+	// tagsField is the reserved document field holding the tags of a record, as a name to value map
+	tagsField = "ariesStorageTags"
 )
 
 // Option configures the couchdb provider
@@ -149,12 +151,27 @@ type CouchDBStore struct {
 	db *kivik.DB
 }
 
-// Put stores the given key-value pair in the store.
-func (c *CouchDBStore) Put(k string, v []byte) error {
+// Put stores the given key-value pair in the store, the tags are saved in a reserved field of the document.
+func (c *CouchDBStore) Put(k string, v []byte, tags ...storage.Tag) error {
 	if k == "" || v == nil {
 		return errors.New("key and value are mandatory")
 	}
 
+	valueToPut, err := c.newDoc(k, v, tags)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.db.Put(context.Background(), k, valueToPut)
+	if err != nil {
+		return fmt.Errorf("failed to store data: %w", err)
+	}
+
+	return nil
+}
+
+// newDoc returns the document storing value v with its tags, with the revision of the stored document if any.
+func (c *CouchDBStore) newDoc(k string, v []byte, tags []storage.Tag) ([]byte, error) {
 	var valueToPut []byte
 	if isJSON(v) {
 		valueToPut = v
@@ -164,22 +181,212 @@ func (c *CouchDBStore) Put(k string, v []byte) error {
 
 	revID, err := c.getRevID(k)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if revID != "" {
-		valueToPut, err = c.addRevID(valueToPut, revID)
+		valueToPut, err = addField(valueToPut, "_rev", revID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(tags) > 0 {
+		tagsMap := make(map[string]string, len(tags))
+
+		for _, tag := range tags {
+			tagsMap[tag.Name] = tag.Value
+		}
+
+		valueToPut, err = addField(valueToPut, tagsField, tagsMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return valueToPut, nil
+}
+
+// Batch writes the documents of the operations in one bulk docs request. Only the last operation of a key is applied
+// as a document can be updated once per request. CouchDB has no transactions: the documents written by the request are
+// kept when the update of another document fails. Batch then makes a best-effort compensating rollback, writing back
+// the previous records of the written documents, and returns the rollback error with the batch error if it fails.
+// The batch is not isolated, concurrent readers can see the documents written before the rollback and concurrent
+// writers of the same keys can be overwritten by it, and it isn't crash-safe, a failure of the process during the
+// batch leaves the documents partially written.
+func (c *CouchDBStore) Batch(operations []storage.Operation) error {
+	lastOperations := make(map[string]storage.Operation)
+
+	var keys []string
+
+	for _, op := range operations {
+		if op.Key == "" {
+			return storage.ErrKeyRequired
+		}
+
+		if !op.IsDelete() && op.Value == nil {
+			return errors.New("key and value are mandatory")
+		}
+
+		if _, ok := lastOperations[op.Key]; !ok {
+			keys = append(keys, op.Key)
+		}
+
+		lastOperations[op.Key] = op
+	}
+
+	docs := make([]interface{}, 0, len(keys))
+	previous := make(map[string]storage.Operation, len(keys))
+
+	for _, k := range keys {
+		prev, err := c.currentRecord(k)
+		if err != nil {
+			return err
+		}
+
+		doc, err := c.batchDoc(lastOperations[k])
 		if err != nil {
 			return err
 		}
+
+		if doc != nil {
+			docs = append(docs, doc)
+			previous[k] = prev
+		}
 	}
 
-	_, err = c.db.Put(context.Background(), k, valueToPut)
+	if len(docs) == 0 {
+		return nil
+	}
+
+	revs, err := c.bulkDocs(docs)
 	if err != nil {
-		return fmt.Errorf("failed to store data: %w", err)
+		if errRollback := c.rollback(previous, revs); errRollback != nil {
+			return fmt.Errorf("%w (failed to roll back the batch: %v)", err, errRollback)
+		}
 	}
 
-	return nil
+	return err
+}
+
+// batchDoc returns the document written by a Batch operation, nil for the deletion of a document not stored.
+func (c *CouchDBStore) batchDoc(op storage.Operation) (json.RawMessage, error) {
+	if op.IsDelete() {
+		revID, err := c.getRevID(op.Key)
+		if err != nil || revID == "" {
+			return nil, err
+		}
+
+		return json.Marshal(map[string]interface{}{"_id": op.Key, "_rev": revID, "_deleted": true})
+	}
+
+	doc, err := c.newDoc(op.Key, op.Value, op.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return addField(doc, "_id", op.Key)
+}
+
+// currentRecord returns the operation restoring the record stored with key k: a put of its value and tags, a delete
+// if the record isn't stored.
+func (c *CouchDBStore) currentRecord(k string) (storage.Operation, error) {
+	rawDoc := make(map[string]interface{})
+
+	err := c.db.Get(context.Background(), k).ScanDoc(&rawDoc)
+	if err != nil {
+		if strings.Contains(err.Error(), couchDBNotFoundErr) {
+			return storage.DeleteOperation(k), nil
+		}
+
+		return storage.Operation{}, err
+	}
+
+	var tags []storage.Tag
+
+	if tagsMap, ok := rawDoc[tagsField].(map[string]interface{}); ok {
+		for name, value := range tagsMap {
+			tags = append(tags, storage.Tag{Name: name, Value: fmt.Sprint(value)})
+		}
+	}
+
+	value, err := c.getStoredValueFromRawDoc(rawDoc, k)
+	if err != nil {
+		return storage.Operation{}, err
+	}
+
+	return storage.PutOperation(k, value, tags...), nil
+}
+
+// bulkDocs writes the documents in one request, it returns the new revisions of the documents written (the revision
+// of a deleted document included) along with the first update error.
+func (c *CouchDBStore) bulkDocs(docs []interface{}) (map[string]string, error) {
+	results, err := c.db.BulkDocs(context.Background(), docs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store documents: %w", err)
+	}
+
+	revs := make(map[string]string)
+
+	for results.Next() {
+		if errUpdate := results.UpdateErr(); errUpdate != nil {
+			if err == nil {
+				err = fmt.Errorf("failed to store document %s: %w", results.ID(), errUpdate)
+			}
+
+			continue
+		}
+
+		revs[results.ID()] = results.Rev()
+	}
+
+	if err == nil {
+		err = results.Err()
+	}
+
+	if errClose := results.Close(); errClose != nil && err == nil {
+		err = errClose
+	}
+
+	return revs, err
+}
+
+// rollback restores the previous records of the documents written by a failed Batch, revs holding their revisions.
+func (c *CouchDBStore) rollback(previous map[string]storage.Operation, revs map[string]string) error {
+	docs := make([]interface{}, 0, len(revs))
+
+	for k, rev := range revs {
+		prev := previous[k]
+
+		var (
+			doc []byte
+			err error
+		)
+
+		if prev.IsDelete() {
+			doc, err = json.Marshal(map[string]interface{}{"_id": k, "_rev": rev, "_deleted": true})
+		} else {
+			// a document deleted by the batch is created again, without the revision of its deletion
+			doc, err = c.newDoc(k, prev.Value, prev.Tags)
+			if err == nil {
+				doc, err = addField(doc, "_id", k)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		docs = append(docs, json.RawMessage(doc))
+	}
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	_, err := c.bulkDocs(docs)
+
+	return err
 }
 
 func isJSON(textToCheck []byte) bool {
@@ -197,7 +404,7 @@ func wrapTextAsCouchDBAttachment(textToWrap []byte) []byte {
 // Get retrieves the value in the store associated with the given key.
 func (c *CouchDBStore) Get(k string) ([]byte, error) {
 	if k == "" {
-		return nil, errors.New("key is mandatory")
+		return nil, storage.ErrKeyRequired
 	}
 
 	rawDoc := make(map[string]interface{})
@@ -216,13 +423,13 @@ func (c *CouchDBStore) Get(k string) ([]byte, error) {
 	return c.getStoredValueFromRawDoc(rawDoc, k)
 }
 
-func (c *CouchDBStore) addRevID(valueToPut []byte, revID string) ([]byte, error) {
+func addField(valueToPut []byte, name string, value interface{}) ([]byte, error) {
 	var m map[string]interface{}
 	if err := json.Unmarshal(valueToPut, &m); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal put value: %w", err)
 	}
 
-	m["_rev"] = revID
+	m[name] = value
 
 	newValue, err := json.Marshal(m)
 	if err != nil {
@@ -250,14 +457,14 @@ func (c *CouchDBStore) getRevID(k string) (string, error) {
 	return rawDoc["_rev"].(string), nil
 }
 
-// Delete will delete record with k key
+// Delete will delete record with k key, deleting a missing record isn't an error.
 func (c *CouchDBStore) Delete(k string) error {
 	if k == "" {
-		return errors.New("key is mandatory")
+		return storage.ErrKeyRequired
 	}
 
 	revID, err := c.getRevID(k)
-	if err != nil {
+	if err != nil || revID == "" {
 		return err
 	}
 
@@ -285,6 +492,146 @@ func (c *CouchDBStore) Iterator(startKey, endKey string) storage.StoreIterator {
 	return &couchDBResultsIterator{store: c, resultRows: resultRows}
 }
 
+// Query returns iterator over the records having a tag matching the expression, the records are found with a Mango
+// query and fetched by pages of the page size of the query options.
+func (c *CouchDBStore) Query(expression string, opts ...storage.QueryOption) (storage.StoreIterator, error) {
+	query, err := storage.ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	// dots are escaped as they separate the names of nested fields
+	field := tagsField + "." + strings.ReplaceAll(query.Name, ".", "\\.")
+
+	var condition interface{} = query.Value
+	if query.AnyValue {
+		condition = map[string]interface{}{"$exists": true}
+	}
+
+	i := &couchDBQueryIterator{
+		store:    c,
+		selector: map[string]interface{}{field: condition},
+		pageSize: storage.NewQueryOptions(opts...).PageSize,
+	}
+
+	err = i.fetchPage("")
+	if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// couchDBQueryIterator iterates over the results of a Mango query, page by page.
+type couchDBQueryIterator struct {
+	store      *CouchDBStore
+	selector   map[string]interface{}
+	pageSize   int
+	count      int
+	resultRows *kivik.Rows
+	key        []byte
+	value      []byte
+	err        error
+}
+
+func (i *couchDBQueryIterator) fetchPage(bookmark string) error {
+	query := map[string]interface{}{
+		"selector": i.selector,
+		"sort":     []map[string]string{{"_id": "asc"}},
+		"limit":    i.pageSize,
+	}
+
+	if bookmark != "" {
+		query["bookmark"] = bookmark
+	}
+
+	resultRows, err := i.store.db.Find(context.Background(), query)
+	if err != nil {
+		return fmt.Errorf("failed to query docs: %w", err)
+	}
+
+	i.resultRows = resultRows
+	i.count = 0
+
+	return nil
+}
+
+func (i *couchDBQueryIterator) Next() bool {
+	i.key, i.value = nil, nil
+
+	for i.err == nil {
+		if i.resultRows.Next() {
+			i.count++
+
+			return i.readCurrent()
+		}
+
+		i.err = i.resultRows.Err()
+
+		// a partial page is the last one
+		if i.err != nil || i.count < i.pageSize {
+			return false
+		}
+
+		bookmark := i.resultRows.Bookmark()
+
+		i.Release()
+
+		i.err = i.fetchPage(bookmark)
+	}
+
+	return false
+}
+
+func (i *couchDBQueryIterator) readCurrent() bool {
+	rawDoc := make(map[string]interface{})
+
+	err := i.resultRows.ScanDoc(&rawDoc)
+	if err != nil {
+		i.err = err
+
+		return false
+	}
+
+	key, ok := rawDoc["_id"].(string)
+	if !ok {
+		i.err = errors.New("document without id")
+
+		return false
+	}
+
+	value, err := i.store.getStoredValueFromRawDoc(rawDoc, key)
+	if err != nil {
+		i.err = err
+
+		return false
+	}
+
+	i.key, i.value = []byte(key), value
+
+	return true
+}
+
+func (i *couchDBQueryIterator) Release() {
+	if err := i.resultRows.Close(); err != nil && i.err == nil {
+		i.err = err
+	}
+}
+
+func (i *couchDBQueryIterator) Error() error {
+	return i.err
+}
+
+// Key returns the key of the current key-value pair.
+func (i *couchDBQueryIterator) Key() []byte {
+	return i.key
+}
+
+// Value returns the value of the current key-value pair.
+func (i *couchDBQueryIterator) Value() []byte {
+	return i.value
+}
+
 type couchDBResultsIterator struct {
 	store      *CouchDBStore
 	resultRows *kivik.Rows
@@ -358,6 +705,7 @@ func (c *CouchDBStore) getStoredValueFromRawDoc(rawDoc map[string]interface{}, k
 	// Strip out the CouchDB-specific fields
 	delete(rawDoc, "_id")
 	delete(rawDoc, "_rev")
+	delete(rawDoc, tagsField)
 
 	strippedJSON, err := json.Marshal(rawDoc)
 	if err != nil {
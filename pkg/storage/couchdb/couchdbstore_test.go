@@ -10,7 +10,10 @@ package couchdbstore
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
@@ -19,7 +22,9 @@ import (
 	"github.com/go-kivik/kivik"
 	"github.com/stretchr/testify/require"
 
+	mockcouchdb "github.com/hyperledger/aries-framework-go/pkg/mock/couchdb"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/storagetest"
 )
 
 const (
@@ -30,18 +35,30 @@ const (
 // 'make unit-test' from the terminal will take care of this for you.
 // To run the tests manually, start an instance by running docker run -p 5984:5984 couchdb:2.3.1 from a terminal.
 
+// couchDBAvailable is set if the CouchDB instance is running, the tests using it are skipped otherwise.
+var couchDBAvailable bool
+
 func TestMain(m *testing.M) {
 	err := waitForCouchDBToStart()
 	if err != nil {
 		fmt.Printf(err.Error() +
 			". Make sure you start a couchDB instance using" +
 			" 'docker run -p 5984:5984 couchdb:2.3.1' before running the unit tests")
-		os.Exit(0)
 	}
 
+	couchDBAvailable = err == nil
+
 	os.Exit(m.Run())
 }
 
+func skipWithoutCouchDB(t *testing.T) {
+	t.Helper()
+
+	if !couchDBAvailable {
+		t.Skip("CouchDB instance not available at " + couchDBURL)
+	}
+}
+
 func waitForCouchDBToStart() error {
 	client, err := kivik.New("couch", couchDBURL)
 	if err != nil {
@@ -72,6 +89,8 @@ func waitForCouchDBToStart() error {
 }
 
 func TestCouchDBStore(t *testing.T) {
+	skipWithoutCouchDB(t)
+
 	t.Run("Test couchdb store put and get", func(t *testing.T) {
 		prov, err := NewProvider(couchDBURL, WithDBPrefix("dbprefix"))
 		require.NoError(t, err)
@@ -305,6 +324,8 @@ func verifyItr(t *testing.T, itr storage.StoreIterator, count int, prefix string
 }
 
 func TestCouchDBStoreDelete(t *testing.T) {
+	skipWithoutCouchDB(t)
+
 	const commonKey = "did:example:1234"
 
 	prov, err := NewProvider(couchDBURL)
@@ -330,9 +351,9 @@ func TestCouchDBStoreDelete(t *testing.T) {
 	err = store1.Delete("")
 	require.EqualError(t, err, "key is mandatory")
 
+	// deleting a missing key isn't an error
 	err = store1.Delete("k1")
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "failed to delete doc")
+	require.NoError(t, err)
 
 	// finally test Delete an existing key
 	err = store1.Delete(commonKey)
@@ -342,3 +363,196 @@ func TestCouchDBStoreDelete(t *testing.T) {
 	require.EqualError(t, err, storage.ErrDataNotFound.Error())
 	require.Empty(t, doc)
 }
+
+func TestCouchDBStoreQuery(t *testing.T) {
+	skipWithoutCouchDB(t)
+
+	prov, err := NewProvider(couchDBURL)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("storequery")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key3", []byte(`{"field":"value3"}`), storage.Tag{Name: "type", Value: "b"}))
+	require.NoError(t, store.Put("key1", []byte("value1"), storage.Tag{Name: "type", Value: "a"},
+		storage.Tag{Name: "flag"}))
+	require.NoError(t, store.Put("key2", []byte(`{"field":"value2"}`), storage.Tag{Name: "type", Value: "a"}))
+	require.NoError(t, store.Put("key4", []byte("value4")))
+
+	queryKeys := func(expression string) []string {
+		itr, err := store.Query(expression, storage.WithPageSize(1))
+		require.NoError(t, err)
+
+		defer itr.Release()
+
+		var keys []string
+
+		for itr.Next() {
+			require.Contains(t, string(itr.Value()), "value"+string(itr.Key())[3:])
+			require.NotContains(t, string(itr.Value()), tagsField)
+
+			keys = append(keys, string(itr.Key()))
+		}
+
+		require.NoError(t, itr.Error())
+
+		return keys
+	}
+
+	require.Equal(t, []string{"key1", "key2", "key3"}, queryKeys("type"))
+	require.Equal(t, []string{"key1", "key2"}, queryKeys("type:a"))
+	require.Equal(t, []string{"key1"}, queryKeys("flag"))
+	require.Empty(t, queryKeys("unknown"))
+
+	// tags are not part of the stored value
+	value, err := store.Get("key2")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"field":"value2"}`, string(value))
+
+	// put without tags removes the tags of the record
+	require.NoError(t, store.Put("key1", []byte("value1")))
+	require.Equal(t, []string{"key2"}, queryKeys("type:a"))
+
+	require.NoError(t, store.Delete("key2"))
+	require.Empty(t, queryKeys("type:a"))
+
+	_, err = store.Query(":a")
+	require.True(t, errors.Is(err, storage.ErrInvalidQuery))
+}
+
+func TestCouchDBStoreBatch(t *testing.T) {
+	skipWithoutCouchDB(t)
+
+	prov, err := NewProvider(couchDBURL)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("storebatch")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+
+	err = store.Batch([]storage.Operation{
+		storage.PutOperation("key2", []byte(`{"field":"value2"}`), storage.Tag{Name: "tag"}),
+		storage.DeleteOperation("key1"),
+		storage.PutOperation("key3", []byte("value3")),
+		storage.PutOperation("key3", []byte("value3-2"), storage.Tag{Name: "tag"}),
+		storage.DeleteOperation("unknown"),
+	})
+	require.NoError(t, err)
+
+	_, err = store.Get("key1")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+	value, err := store.Get("key2")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"field":"value2"}`, string(value))
+
+	value, err = store.Get("key3")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value3-2"), value)
+
+	itr, err := store.Query("tag")
+	require.NoError(t, err)
+
+	var keys []string
+
+	for itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+
+	itr.Release()
+	require.Equal(t, []string{"key2", "key3"}, keys)
+
+	// update of stored documents
+	err = store.Batch([]storage.Operation{storage.PutOperation("key2", []byte(`{"field":"value2-2"}`))})
+	require.NoError(t, err)
+
+	value, err = store.Get("key2")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"field":"value2-2"}`, string(value))
+
+	err = store.Batch([]storage.Operation{storage.PutOperation("", []byte("value"))})
+	require.Error(t, err)
+
+	err = store.Batch([]storage.Operation{storage.PutOperation("key4", nil)})
+	require.EqualError(t, err, "key and value are mandatory")
+}
+
+func TestCouchDBStoreBatchRollback(t *testing.T) {
+	server := mockcouchdb.NewServer()
+
+	var store storage.Store
+
+	// a concurrent update of key3 makes its write in the bulk docs request fail
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasSuffix(req.URL.Path, "/_bulk_docs") {
+			if _, err := store.Get("key3"); err == nil {
+				require.NoError(t, store.Put("key3", []byte("concurrent")))
+			}
+		}
+
+		server.ServeHTTP(rw, req)
+	}))
+	defer srv.Close()
+
+	prov, err := NewProvider(srv.URL)
+	require.NoError(t, err)
+
+	store, err = prov.OpenStore("storebatchrollback")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1"), storage.Tag{Name: "tag", Value: "a"}))
+	require.NoError(t, store.Put("key2", []byte(`{"field":"value2"}`)))
+	require.NoError(t, store.Put("key3", []byte("value3")))
+
+	err = store.Batch([]storage.Operation{
+		storage.PutOperation("key1", []byte("value1-2")),
+		storage.DeleteOperation("key2"),
+		storage.PutOperation("key3", []byte("value3-2")),
+		storage.PutOperation("key4", []byte("value4")),
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to store document key3")
+
+	value, err := store.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1"), value)
+
+	itr, err := store.Query("tag:a")
+	require.NoError(t, err)
+	require.True(t, itr.Next())
+	require.Equal(t, "key1", string(itr.Key()))
+	itr.Release()
+
+	value, err = store.Get("key2")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"field":"value2"}`, string(value))
+
+	value, err = store.Get("key3")
+	require.NoError(t, err)
+	require.Equal(t, []byte("concurrent"), value)
+
+	_, err = store.Get("key4")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+}
+
+func TestCouchDBStoreConformance(t *testing.T) {
+	t.Run("stub server", func(t *testing.T) {
+		srv := httptest.NewServer(mockcouchdb.NewServer())
+		defer srv.Close()
+
+		prov, err := NewProvider(srv.URL)
+		require.NoError(t, err)
+
+		storagetest.TestAll(t, prov)
+	})
+
+	t.Run("CouchDB instance", func(t *testing.T) {
+		skipWithoutCouchDB(t)
+
+		prov, err := NewProvider(couchDBURL, WithDBPrefix("conformance"))
+		require.NoError(t, err)
+
+		storagetest.TestAll(t, prov)
+	})
+}
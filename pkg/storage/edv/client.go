@@ -0,0 +1,211 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package edv
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+)
+
+var logger = log.New("aries-framework/storage/edv")
+
+const vaultsPath = "/encrypted-data-vaults"
+
+var (
+	// ErrNotFound is returned when the vault or the document doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicateDocument is returned when a document is created with the ID of an existing document.
+	ErrDuplicateDocument = errors.New("duplicate document")
+)
+
+// Client is a client of the REST API of Encrypted Data Vault servers.
+// (https://identity.foundation/secure-data-store/#http-api)
+type Client struct {
+	serverURL  string
+	httpClient *http.Client
+}
+
+// ClientOption configures the EDV client.
+type ClientOption func(c *Client)
+
+// WithTimeout option sets the timeout of the HTTP(s) requests.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithTLSConfig option is for definition of secured HTTP transport using a tls.Config instance.
+func WithTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Transport = &http.Transport{
+			TLSClientConfig: tlsConfig,
+		}
+	}
+}
+
+// NewClient returns a client of the EDV server at serverURL.
+func NewClient(serverURL string, opts ...ClientOption) (*Client, error) {
+	_, err := url.ParseRequestURI(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("server URL invalid: %w", err)
+	}
+
+	c := &Client{serverURL: serverURL, httpClient: &http.Client{}}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// CreateDataVault creates a vault with the given configuration and returns its ID.
+func (c *Client) CreateDataVault(config *DataVaultConfiguration) (string, error) {
+	location, err := c.send(http.MethodPost, vaultsPath, config, nil, http.StatusCreated)
+	if err != nil {
+		return "", fmt.Errorf("failed to create data vault: %w", err)
+	}
+
+	return path.Base(location), nil
+}
+
+// CreateDocument creates the document in the vault vaultID.
+func (c *Client) CreateDocument(vaultID string, document *EncryptedDocument) error {
+	_, err := c.send(http.MethodPost, documentsPath(vaultID), document, nil, http.StatusCreated)
+	if err != nil {
+		return fmt.Errorf("failed to create document %s: %w", document.ID, err)
+	}
+
+	return nil
+}
+
+// ReadDocument reads the document docID from the vault vaultID.
+func (c *Client) ReadDocument(vaultID, docID string) (*EncryptedDocument, error) {
+	var document EncryptedDocument
+
+	_, err := c.send(http.MethodGet, documentPath(vaultID, docID), nil, &document, http.StatusOK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document %s: %w", docID, err)
+	}
+
+	return &document, nil
+}
+
+// UpdateDocument replaces the document in the vault vaultID.
+func (c *Client) UpdateDocument(vaultID string, document *EncryptedDocument) error {
+	_, err := c.send(http.MethodPut, documentPath(vaultID, document.ID), document, nil, http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("failed to update document %s: %w", document.ID, err)
+	}
+
+	return nil
+}
+
+// DeleteDocument deletes the document docID from the vault vaultID.
+func (c *Client) DeleteDocument(vaultID, docID string) error {
+	_, err := c.send(http.MethodDelete, documentPath(vaultID, docID), nil, nil, http.StatusOK)
+	if err != nil {
+		return fmt.Errorf("failed to delete document %s: %w", docID, err)
+	}
+
+	return nil
+}
+
+// QueryVault returns the documents of the vault vaultID matching the query.
+func (c *Client) QueryVault(vaultID string, query *Query) ([]EncryptedDocument, error) {
+	var documents []EncryptedDocument
+
+	_, err := c.send(http.MethodPost, path.Join(vaultsPath, url.PathEscape(vaultID), "queries"), query,
+		&documents, http.StatusOK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vault %s: %w", vaultID, err)
+	}
+
+	return documents, nil
+}
+
+func documentsPath(vaultID string) string {
+	return path.Join(vaultsPath, url.PathEscape(vaultID), "documents")
+}
+
+func documentPath(vaultID, docID string) string {
+	return path.Join(documentsPath(vaultID), url.PathEscape(docID))
+}
+
+// send sends the request and unmarshals the response body into response if not nil, the Location header of the
+// response is returned.
+func (c *Client) send(method, endpoint string, request, response interface{}, expectedStatus int) (string, error) {
+	var body io.Reader
+
+	if request != nil {
+		requestBytes, err := json.Marshal(request)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		body = bytes.NewReader(requestBytes)
+	}
+
+	req, err := http.NewRequest(method, c.serverURL+endpoint, body)
+	if err != nil {
+		return "", fmt.Errorf("HTTP create request failed: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("HTTP request failed: %w", err)
+	}
+
+	defer closeResponseBody(resp.Body)
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response body failed: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case expectedStatus:
+	case http.StatusNotFound:
+		return "", ErrNotFound
+	case http.StatusConflict:
+		return "", ErrDuplicateDocument
+	default:
+		return "", fmt.Errorf("unexpected response from EDV server [%d] body [%s]", resp.StatusCode, respBody)
+	}
+
+	if response != nil {
+		err = json.Unmarshal(respBody, response)
+		if err != nil {
+			return "", fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+
+	return resp.Header.Get("Location"), nil
+}
+
+func closeResponseBody(respBody io.Closer) {
+	e := respBody.Close()
+	if e != nil {
+		logger.Errorf("Failed to close response body: %v", e)
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package edv
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+
+	mockedv "github.com/hyperledger/aries-framework-go/pkg/mock/edv"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		c, err := NewClient("https://edv.example.com", WithTimeout(time.Second),
+			WithTLSConfig(&tls.Config{ServerName: "edv.example.com"}))
+		require.NoError(t, err)
+		require.Equal(t, time.Second, c.httpClient.Timeout)
+		require.NotNil(t, c.httpClient.Transport)
+	})
+
+	t.Run("invalid server URL", func(t *testing.T) {
+		_, err := NewClient("invalid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "server URL invalid")
+	})
+}
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(mockedv.NewServer())
+	defer srv.Close()
+
+	c, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	vaultID, err := c.CreateDataVault(&DataVaultConfiguration{
+		Controller: "did:example:123",
+		KEK:        IDTypePair{ID: "https://example.com/kms/12345", Type: "AesKeyWrappingKey2019"},
+		HMAC:       IDTypePair{ID: "https://example.com/kms/67891", Type: macKeyType},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, vaultID)
+
+	docID := base58.Encode([]byte("0123456789abcdef"))
+	doc := &EncryptedDocument{
+		ID: docID,
+		IndexedAttributeCollections: []IndexedAttributeCollection{{
+			HMAC:              IDTypePair{Type: macKeyType},
+			IndexedAttributes: []IndexedAttribute{{Name: "name", Value: "value"}},
+		}},
+		JWE: json.RawMessage(`{"protected":"1"}`),
+	}
+
+	t.Run("create and read document", func(t *testing.T) {
+		require.NoError(t, c.CreateDocument(vaultID, doc))
+
+		err = c.CreateDocument(vaultID, doc)
+		require.True(t, errors.Is(err, ErrDuplicateDocument))
+
+		read, err := c.ReadDocument(vaultID, docID)
+		require.NoError(t, err)
+		require.Equal(t, doc, read)
+
+		_, err = c.ReadDocument(vaultID, base58.Encode([]byte("fedcba9876543210")))
+		require.True(t, errors.Is(err, ErrNotFound))
+
+		err = c.CreateDocument("unknown", doc)
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("update document", func(t *testing.T) {
+		doc.JWE = json.RawMessage(`{"protected":"2"}`)
+
+		require.NoError(t, c.UpdateDocument(vaultID, doc))
+
+		read, err := c.ReadDocument(vaultID, docID)
+		require.NoError(t, err)
+		require.Equal(t, doc, read)
+
+		err = c.UpdateDocument(vaultID, &EncryptedDocument{
+			ID:  base58.Encode([]byte("fedcba9876543210")),
+			JWE: json.RawMessage(`{}`),
+		})
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("query vault", func(t *testing.T) {
+		docs, err := c.QueryVault(vaultID, &Query{Name: "name", Value: "value"})
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+		require.Equal(t, *doc, docs[0])
+
+		// a query of any value of the attribute has no equals condition
+		raw, err := json.Marshal(&Query{Name: "name", Has: true})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"index":"name","has":true}`, string(raw))
+
+		docs, err = c.QueryVault(vaultID, &Query{Name: "name", Has: true})
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+
+		docs, err = c.QueryVault(vaultID, &Query{Name: "name", Value: "other"})
+		require.NoError(t, err)
+		require.Empty(t, docs)
+
+		_, err = c.QueryVault(vaultID, &Query{Name: "name"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query must have an equals or a has condition")
+
+		_, err = c.QueryVault("unknown", &Query{Name: "name", Has: true})
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("delete document", func(t *testing.T) {
+		require.NoError(t, c.DeleteDocument(vaultID, docID))
+
+		err = c.DeleteDocument(vaultID, docID)
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err := c.CreateDataVault(&DataVaultConfiguration{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unexpected response from EDV server [400]")
+
+		err = c.CreateDocument(vaultID, &EncryptedDocument{ID: "invalid", JWE: json.RawMessage(`{}`)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unexpected response from EDV server [400]")
+
+		err = c.CreateDocument(vaultID, &EncryptedDocument{ID: docID})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unexpected response from EDV server [400]")
+
+		err = c.CreateDocument(vaultID, &EncryptedDocument{ID: docID, JWE: json.RawMessage(`invalid`)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to marshal request")
+	})
+}
+
+func TestClientFailures(t *testing.T) {
+	t.Run("HTTP request failure", func(t *testing.T) {
+		c, err := NewClient("http://localhost:1")
+		require.NoError(t, err)
+
+		_, err = c.ReadDocument("vault", "doc")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "HTTP request failed")
+	})
+
+	t.Run("create request failure", func(t *testing.T) {
+		c, err := NewClient("http://localhost:1")
+		require.NoError(t, err)
+
+		c.serverURL = "http://local host"
+
+		_, err = c.ReadDocument("vault", "doc")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "HTTP create request failed")
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			_, err := fmt.Fprint(rw, "invalid")
+			require.NoError(t, err)
+		}))
+		defer srv.Close()
+
+		c, err := NewClient(srv.URL)
+		require.NoError(t, err)
+
+		_, err = c.ReadDocument("vault", "doc")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal response")
+	})
+
+	t.Run("invalid response body", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("Content-Length", "10")
+		}))
+		defer srv.Close()
+
+		c, err := NewClient(srv.URL)
+		require.NoError(t, err)
+
+		_, err = c.ReadDocument("vault", "doc")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "reading response body failed")
+	})
+}
@@ -0,0 +1,456 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package edv
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/btcsuite/btcutil/base58"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// docIDSize is the size of the document IDs before their base58 encoding (128 bits).
+	docIDSize = 16
+	// storeAttributeName is the name of the indexed attribute holding the name of the store of a document.
+	storeAttributeName = "store"
+	// macKeyType is the type of the MAC key of the indexed attributes.
+	macKeyType = "Sha256HmacKey2019"
+)
+
+// Provider is a storage.Provider keeping the records of its stores in an Encrypted Data Vault as encrypted
+// documents (https://identity.foundation/secure-data-store), the EDV server can't read them.
+//
+// The documents are JWEs built with the given jose encrypter and decrypter. Their IDs and the names and values of
+// their indexed attributes (the store name and the record tags) are MACs computed with macKH so that they don't
+// leak either. Query and Iterator query the vault by indexed attribute and decrypt the matching documents.
+// The operations of a Batch are sent one by one, the EDV REST API having no transactions.
+type Provider struct {
+	client    *Client
+	vaultID   string
+	encrypter jose.Encrypter
+	decrypter jose.Decrypter
+	crypto    crypto.Crypto
+	macKH     interface{}
+	stores    map[string]*edvStore
+	lock      sync.RWMutex
+}
+
+// NewProvider returns a provider storing the records in the vault vaultID of the EDV server of client.
+func NewProvider(client *Client, vaultID string, encrypter jose.Encrypter, decrypter jose.Decrypter,
+	c crypto.Crypto, macKH interface{}) *Provider {
+	return &Provider{
+		client:    client,
+		vaultID:   vaultID,
+		encrypter: encrypter,
+		decrypter: decrypter,
+		crypto:    c,
+		macKH:     macKH,
+		stores:    make(map[string]*edvStore),
+	}
+}
+
+// OpenStore opens the store with the given name, the stores share the vault of the provider.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	name = strings.ToLower(name)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	store, ok := p.stores[name]
+	if !ok {
+		store = &edvStore{name: name, provider: p}
+		p.stores[name] = store
+	}
+
+	return store, nil
+}
+
+// CloseStore closes the store with the given name.
+func (p *Provider) CloseStore(name string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.stores, strings.ToLower(name))
+
+	return nil
+}
+
+// Close closes all the stores of the provider.
+func (p *Provider) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.stores = make(map[string]*edvStore)
+
+	return nil
+}
+
+// content is the content of the structured documents of the records.
+type content struct {
+	Store string `json:"store"`
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type edvStore struct {
+	name     string
+	provider *Provider
+}
+
+func (s *edvStore) mac(data string) ([]byte, error) {
+	mac, err := s.provider.crypto.ComputeMAC([]byte(data), s.provider.macKH)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute MAC: %w", err)
+	}
+
+	return mac, nil
+}
+
+// documentID returns the ID of the document of the record k, derived from its MAC.
+func (s *edvStore) documentID(k string) (string, error) {
+	mac, err := s.mac(s.name + "\x00" + k)
+	if err != nil {
+		return "", err
+	}
+
+	if len(mac) < docIDSize {
+		return "", fmt.Errorf("MAC too short for a document ID: %d bytes", len(mac))
+	}
+
+	return base58.Encode(mac[:docIDSize]), nil
+}
+
+// attribute returns the indexed attribute of the given name and value, the value is omitted if anyValue is set.
+func (s *edvStore) attribute(name, value string, anyValue bool) (IndexedAttribute, error) {
+	nameMAC, err := s.mac(s.name + "\x00" + name)
+	if err != nil {
+		return IndexedAttribute{}, err
+	}
+
+	attribute := IndexedAttribute{Name: base64.RawURLEncoding.EncodeToString(nameMAC)}
+
+	if !anyValue {
+		valueMAC, err := s.mac(value)
+		if err != nil {
+			return IndexedAttribute{}, err
+		}
+
+		attribute.Value = base64.RawURLEncoding.EncodeToString(valueMAC)
+	}
+
+	return attribute, nil
+}
+
+// encryptedDocument returns the encrypted document of the record.
+func (s *edvStore) encryptedDocument(k string, v []byte, tags []storage.Tag) (*EncryptedDocument, error) {
+	docID, err := s.documentID(k)
+	if err != nil {
+		return nil, err
+	}
+
+	// the store attribute name has the prefix \x00 so that it can't be the name of a tag
+	attributes := make([]IndexedAttribute, 0, len(tags)+1)
+
+	storeAttribute, err := s.attribute("\x00"+storeAttributeName, s.name, false)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes = append(attributes, storeAttribute)
+
+	for _, tag := range tags {
+		attribute, err := s.attribute(tag.Name, tag.Value, false)
+		if err != nil {
+			return nil, err
+		}
+
+		attributes = append(attributes, attribute)
+	}
+
+	structuredDoc, err := json.Marshal(&struct {
+		ID      string   `json:"id"`
+		Content *content `json:"content"`
+	}{ID: docID, Content: &content{Store: s.name, Key: k, Value: v}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal structured document: %w", err)
+	}
+
+	jwe, err := s.provider.encrypter.Encrypt(structuredDoc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt structured document: %w", err)
+	}
+
+	serializedJWE, err := jwe.FullSerialize(json.Marshal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize JWE: %w", err)
+	}
+
+	return &EncryptedDocument{
+		ID: docID,
+		IndexedAttributeCollections: []IndexedAttributeCollection{{
+			HMAC:              IDTypePair{Type: macKeyType},
+			IndexedAttributes: attributes,
+		}},
+		JWE: json.RawMessage(serializedJWE),
+	}, nil
+}
+
+// decrypt returns the content of the encrypted document.
+func (s *edvStore) decrypt(document *EncryptedDocument) (*content, error) {
+	jwe, err := jose.Deserialize(string(document.JWE))
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize JWE of document %s: %w", document.ID, err)
+	}
+
+	structuredDoc, err := s.provider.decrypter.Decrypt(jwe)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt document %s: %w", document.ID, err)
+	}
+
+	var doc struct {
+		Content content `json:"content"`
+	}
+
+	err = json.Unmarshal(structuredDoc, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal structured document %s: %w", document.ID, err)
+	}
+
+	if doc.Content.Store != s.name {
+		return nil, fmt.Errorf("document %s doesn't belong to store %s", document.ID, s.name)
+	}
+
+	return &doc.Content, nil
+}
+
+// Put encrypts the record and creates or updates its document in the vault.
+func (s *edvStore) Put(k string, v []byte, tags ...storage.Tag) error {
+	if k == "" || v == nil {
+		return errors.New("key and value are mandatory")
+	}
+
+	document, err := s.encryptedDocument(k, v, tags)
+	if err != nil {
+		return err
+	}
+
+	err = s.provider.client.CreateDocument(s.provider.vaultID, document)
+	if errors.Is(err, ErrDuplicateDocument) {
+		return s.provider.client.UpdateDocument(s.provider.vaultID, document)
+	}
+
+	return err
+}
+
+// Get reads the document of the record from the vault and decrypts it.
+func (s *edvStore) Get(k string) ([]byte, error) {
+	if k == "" {
+		return nil, storage.ErrKeyRequired
+	}
+
+	docID, err := s.documentID(k)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := s.provider.client.ReadDocument(s.provider.vaultID, docID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, storage.ErrDataNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := s.decrypt(document)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Key != k {
+		return nil, fmt.Errorf("document %s isn't the document of key %s", docID, k)
+	}
+
+	return c.Value, nil
+}
+
+// Iterator returns an iterator over the records of the key range, ordered by key. All the documents of the store are
+// read from the vault and decrypted to find them.
+func (s *edvStore) Iterator(start, limit string) storage.StoreIterator {
+	storeAttribute, err := s.attribute("\x00"+storeAttributeName, s.name, false)
+	if err != nil {
+		return &iterator{err: err}
+	}
+
+	records, err := s.query(&Query{Name: storeAttribute.Name, Value: storeAttribute.Value})
+	if err != nil {
+		return &iterator{err: err}
+	}
+
+	limit = strings.ReplaceAll(limit, storage.EndKeySuffix, "~")
+
+	var inRange []*content
+
+	for _, r := range records {
+		if r.Key >= start && r.Key < limit {
+			inRange = append(inRange, r)
+		}
+	}
+
+	sort.Slice(inRange, func(i, j int) bool {
+		return inRange[i].Key < inRange[j].Key
+	})
+
+	return &iterator{records: inRange}
+}
+
+// Delete deletes the document of the record from the vault.
+func (s *edvStore) Delete(k string) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	docID, err := s.documentID(k)
+	if err != nil {
+		return err
+	}
+
+	err = s.provider.client.DeleteDocument(s.provider.vaultID, docID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// Query queries the vault by the indexed attribute of the tag and decrypts the matching documents. The query options
+// are ignored, all the matching documents are returned by the vault at once.
+func (s *edvStore) Query(expression string, _ ...storage.QueryOption) (storage.StoreIterator, error) {
+	query, err := storage.ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	attribute, err := s.attribute(query.Name, query.Value, query.AnyValue)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.query(&Query{Name: attribute.Name, Value: attribute.Value, Has: query.AnyValue})
+	if err != nil {
+		return nil, err
+	}
+
+	return &iterator{records: records}, nil
+}
+
+// query returns the decrypted documents of the vault matching the query.
+func (s *edvStore) query(query *Query) ([]*content, error) {
+	documents, err := s.provider.client.QueryVault(s.provider.vaultID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*content, len(documents))
+
+	for i := range documents {
+		records[i], err = s.decrypt(&documents[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return records, nil
+}
+
+// Batch applies the operations one by one, it stops at the first failure.
+func (s *edvStore) Batch(operations []storage.Operation) error {
+	for _, op := range operations {
+		if op.Key == "" {
+			return storage.ErrKeyRequired
+		}
+
+		if !op.IsDelete() && op.Value == nil {
+			return errors.New("key and value are mandatory")
+		}
+	}
+
+	for _, op := range operations {
+		var err error
+
+		if op.IsDelete() {
+			err = s.Delete(op.Key)
+		} else {
+			err = s.Put(op.Key, op.Value, op.Tags...)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to apply operation on key %s: %w", op.Key, err)
+		}
+	}
+
+	return nil
+}
+
+// iterator iterates over decrypted records.
+type iterator struct {
+	records []*content
+	current int
+	err     error
+}
+
+// Next moves the iterator to the next record.
+func (i *iterator) Next() bool {
+	if i.current >= len(i.records) {
+		i.records = nil
+
+		return false
+	}
+
+	i.current++
+
+	return true
+}
+
+// Release releases the records.
+func (i *iterator) Release() {
+	i.records = nil
+	i.current = 0
+}
+
+// Error returns the error of the iterator.
+func (i *iterator) Error() error {
+	return i.err
+}
+
+// Key returns the key of the current record.
+func (i *iterator) Key() []byte {
+	if i.current == 0 || i.current > len(i.records) {
+		return nil
+	}
+
+	return []byte(i.records[i.current-1].Key)
+}
+
+// Value returns the value of the current record.
+func (i *iterator) Value() []byte {
+	if i.current == 0 || i.current > len(i.records) {
+		return nil
+	}
+
+	return i.records[i.current-1].Value
+}
@@ -0,0 +1,470 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package edv
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/mac"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdhes"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/keyio"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	mockcrypto "github.com/hyperledger/aries-framework-go/pkg/mock/crypto"
+	mockedv "github.com/hyperledger/aries-framework-go/pkg/mock/edv"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/storagetest"
+)
+
+type testVault struct {
+	client    *Client
+	vaultID   string
+	encrypter jose.Encrypter
+	decrypter jose.Decrypter
+	crypto    *tinkcrypto.Crypto
+	macKH     *keyset.Handle
+}
+
+func newTestVault(t *testing.T) *testVault {
+	t.Helper()
+
+	srv := httptest.NewServer(mockedv.NewServer())
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(srv.URL)
+	require.NoError(t, err)
+
+	vaultID, err := client.CreateDataVault(&DataVaultConfiguration{Controller: "did:example:123"})
+	require.NoError(t, err)
+
+	recKH, err := keyset.NewHandle(ecdhes.ECDHES256KWAES256GCMKeyTemplate())
+	require.NoError(t, err)
+
+	pubKH, err := recKH.Public()
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, pubKH.WriteWithNoSecrets(keyio.NewWriter(buf)))
+
+	var recPubKey composite.PublicKey
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &recPubKey))
+
+	encrypter, err := jose.NewJWEEncrypt(jose.A256GCM, []composite.PublicKey{recPubKey})
+	require.NoError(t, err)
+
+	c, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	macKH, err := keyset.NewHandle(mac.HMACSHA256Tag256KeyTemplate())
+	require.NoError(t, err)
+
+	return &testVault{
+		client:    client,
+		vaultID:   vaultID,
+		encrypter: encrypter,
+		decrypter: jose.NewJWEDecrypt(recKH),
+		crypto:    c,
+		macKH:     macKH,
+	}
+}
+
+func (v *testVault) provider() *Provider {
+	return NewProvider(v.client, v.vaultID, v.encrypter, v.decrypter, v.crypto, v.macKH)
+}
+
+func TestProvider(t *testing.T) {
+	v := newTestVault(t)
+	p := v.provider()
+
+	store1, err := p.OpenStore("Test")
+	require.NoError(t, err)
+
+	store2, err := p.OpenStore("test")
+	require.NoError(t, err)
+	require.Equal(t, store1, store2)
+
+	require.NoError(t, p.CloseStore("test"))
+	require.Empty(t, p.stores)
+
+	_, err = p.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, p.Close())
+	require.Empty(t, p.stores)
+}
+
+func TestEDVStore(t *testing.T) {
+	v := newTestVault(t)
+	p := v.provider()
+
+	store, err := p.OpenStore("store1")
+	require.NoError(t, err)
+
+	otherStore, err := p.OpenStore("store2")
+	require.NoError(t, err)
+
+	t.Run("put and get", func(t *testing.T) {
+		data := []byte(`{"secret":"value"}`)
+
+		require.NoError(t, store.Put("key1", data, storage.Tag{Name: "type", Value: "credential"}))
+
+		value, err := store.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, data, value)
+
+		// the vault can't read the documents
+		docs, err := v.client.QueryVault(v.vaultID, &Query{Name: mustAttribute(t, store, "type", "", true).Name, Has: true})
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+		require.NotContains(t, string(docs[0].JWE), "secret")
+		require.NotContains(t, string(docs[0].JWE), "key1")
+
+		for _, attribute := range docs[0].IndexedAttributeCollections[0].IndexedAttributes {
+			require.NotEqual(t, "type", attribute.Name)
+			require.NotEqual(t, "credential", attribute.Value)
+		}
+
+		// update
+		require.NoError(t, store.Put("key1", []byte("value1"), storage.Tag{Name: "type", Value: "credential"}))
+
+		value, err = store.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value1"), value)
+
+		// the stores don't share records
+		_, err = otherStore.Get("key1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		_, err = store.Get("")
+		require.True(t, errors.Is(err, storage.ErrKeyRequired))
+
+		require.Error(t, store.Put("", data))
+		require.Error(t, store.Put("key", nil))
+	})
+
+	t.Run("iterator", func(t *testing.T) {
+		require.NoError(t, store.Put("abc_2", []byte("value_abc_2")))
+		require.NoError(t, store.Put("abc_1", []byte("value_abc_1")))
+		require.NoError(t, store.Put("abd_1", []byte("value_abd_1")))
+		require.NoError(t, otherStore.Put("abc_3", []byte("value_abc_3")))
+
+		itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+
+		var keys []string
+
+		for itr.Next() {
+			require.Equal(t, "value_"+string(itr.Key()), string(itr.Value()))
+
+			keys = append(keys, string(itr.Key()))
+		}
+
+		require.NoError(t, itr.Error())
+		require.Equal(t, []string{"abc_1", "abc_2"}, keys)
+
+		itr.Release()
+		require.False(t, itr.Next())
+		require.Nil(t, itr.Key())
+		require.Nil(t, itr.Value())
+	})
+
+	t.Run("query", func(t *testing.T) {
+		require.NoError(t, store.Put("key3", []byte("value3"), storage.Tag{Name: "type", Value: "presentation"}))
+		require.NoError(t, otherStore.Put("key4", []byte("value4"), storage.Tag{Name: "type", Value: "credential"}))
+
+		for expression, expected := range map[string][]string{
+			"type":              {"key1", "key3"},
+			"type:credential":   {"key1"},
+			"type:presentation": {"key3"},
+			"other":             nil,
+		} {
+			itr, err := store.Query(expression)
+			require.NoError(t, err)
+
+			var keys []string
+
+			for itr.Next() {
+				keys = append(keys, string(itr.Key()))
+			}
+
+			require.NoError(t, itr.Error())
+			require.ElementsMatch(t, expected, keys, expression)
+		}
+
+		_, err := store.Query("")
+		require.True(t, errors.Is(err, storage.ErrInvalidQuery))
+	})
+
+	t.Run("batch and delete", func(t *testing.T) {
+		err := store.Batch([]storage.Operation{
+			storage.PutOperation("key5", []byte("value5"), storage.Tag{Name: "type", Value: "credential"}),
+			storage.DeleteOperation("key1"),
+			storage.DeleteOperation("unknown"),
+		})
+		require.NoError(t, err)
+
+		_, err = store.Get("key1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		value, err := store.Get("key5")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value5"), value)
+
+		require.NoError(t, store.Delete("key5"))
+
+		_, err = store.Get("key5")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		require.True(t, errors.Is(store.Delete(""), storage.ErrKeyRequired))
+
+		err = store.Batch([]storage.Operation{
+			storage.PutOperation("key6", []byte("value6")),
+			storage.PutOperation("", []byte("value")),
+		})
+		require.True(t, errors.Is(err, storage.ErrKeyRequired))
+
+		_, err = store.Get("key6")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		err = store.Batch([]storage.Operation{storage.DeleteOperation("key6")})
+		require.NoError(t, err)
+
+		err = store.Batch([]storage.Operation{{Key: "key7", Value: []byte{}}})
+		require.NoError(t, err)
+
+		err = store.Batch([]storage.Operation{storage.PutOperation("key8", nil)})
+		require.EqualError(t, err, "key and value are mandatory")
+	})
+}
+
+func TestEDVStoreFailures(t *testing.T) {
+	v := newTestVault(t)
+
+	t.Run("MAC failure", func(t *testing.T) {
+		p := NewProvider(v.client, v.vaultID, v.encrypter, v.decrypter,
+			&mockcrypto.Crypto{ComputeMACErr: fmt.Errorf("mac error")}, v.macKH)
+
+		store, err := p.OpenStore("test")
+		require.NoError(t, err)
+
+		err = store.Put("key", []byte("value"))
+		require.EqualError(t, err, "failed to compute MAC: mac error")
+
+		_, err = store.Get("key")
+		require.EqualError(t, err, "failed to compute MAC: mac error")
+
+		err = store.Delete("key")
+		require.EqualError(t, err, "failed to compute MAC: mac error")
+
+		_, err = store.Query("tag")
+		require.EqualError(t, err, "failed to compute MAC: mac error")
+
+		itr := store.Iterator("", storage.EndKeySuffix)
+		require.False(t, itr.Next())
+		require.EqualError(t, itr.Error(), "failed to compute MAC: mac error")
+
+		err = store.Batch([]storage.Operation{storage.PutOperation("key", []byte("value"))})
+		require.EqualError(t, err, "failed to apply operation on key key: failed to compute MAC: mac error")
+	})
+
+	t.Run("MAC too short", func(t *testing.T) {
+		p := NewProvider(v.client, v.vaultID, v.encrypter, v.decrypter,
+			&mockcrypto.Crypto{ComputeMACValue: []byte("short")}, v.macKH)
+
+		store, err := p.OpenStore("test")
+		require.NoError(t, err)
+
+		_, err = store.Get("key")
+		require.EqualError(t, err, "MAC too short for a document ID: 5 bytes")
+	})
+
+	t.Run("MAC of attributes failure", func(t *testing.T) {
+		p := NewProvider(v.client, v.vaultID, v.encrypter, v.decrypter,
+			&macFailingCrypto{Crypto: v.crypto, failOn: "tag"}, v.macKH)
+
+		store, err := p.OpenStore("test")
+		require.NoError(t, err)
+
+		err = store.Put("key", []byte("value"), storage.Tag{Name: "tag", Value: "value"})
+		require.EqualError(t, err, "failed to compute MAC: mac error")
+
+		err = store.Put("key", []byte("value"), storage.Tag{Name: "name", Value: "tag"})
+		require.EqualError(t, err, "failed to compute MAC: mac error")
+
+		p = NewProvider(v.client, v.vaultID, v.encrypter, v.decrypter,
+			&macFailingCrypto{Crypto: v.crypto, failOn: "\x00store"}, v.macKH)
+
+		store, err = p.OpenStore("test")
+		require.NoError(t, err)
+
+		err = store.Put("key", []byte("value"))
+		require.EqualError(t, err, "failed to compute MAC: mac error")
+	})
+
+	t.Run("encrypt failure", func(t *testing.T) {
+		p := NewProvider(v.client, v.vaultID, &failingEncrypter{}, v.decrypter, v.crypto, v.macKH)
+
+		store, err := p.OpenStore("test")
+		require.NoError(t, err)
+
+		err = store.Put("key", []byte("value"))
+		require.EqualError(t, err, "failed to encrypt structured document: encrypt error")
+	})
+
+	t.Run("decrypt failure", func(t *testing.T) {
+		p := NewProvider(v.client, v.vaultID, v.encrypter, &failingDecrypter{}, v.crypto, v.macKH)
+
+		store, err := p.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key", []byte("value"), storage.Tag{Name: "tag"}))
+
+		_, err = store.Get("key")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decrypt error")
+
+		_, err = store.Query("tag")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decrypt error")
+
+		itr := store.Iterator("", storage.EndKeySuffix)
+		require.False(t, itr.Next())
+		require.Error(t, itr.Error())
+		require.Contains(t, itr.Error().Error(), "decrypt error")
+	})
+
+	t.Run("invalid documents", func(t *testing.T) {
+		store, err := v.provider().OpenStore("invalid")
+		require.NoError(t, err)
+
+		s, ok := store.(*edvStore)
+		require.True(t, ok)
+
+		docID, err := s.documentID("key1")
+		require.NoError(t, err)
+
+		require.NoError(t, v.client.CreateDocument(v.vaultID, &EncryptedDocument{
+			ID: docID, JWE: json.RawMessage(`"invalid"`),
+		}))
+
+		_, err = store.Get("key1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to deserialize JWE")
+
+		// a document of another key
+		require.NoError(t, store.Put("key2", []byte("value2")))
+
+		docID2, err := s.documentID("key2")
+		require.NoError(t, err)
+
+		doc2, err := v.client.ReadDocument(v.vaultID, docID2)
+		require.NoError(t, err)
+
+		doc2.ID = docID
+		require.NoError(t, v.client.UpdateDocument(v.vaultID, doc2))
+
+		_, err = store.Get("key1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "isn't the document of key key1")
+
+		// a document of another store
+		otherStore, err := v.provider().OpenStore("other")
+		require.NoError(t, err)
+
+		_, err = otherStore.(*edvStore).decrypt(doc2)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "doesn't belong to store other")
+
+		// a JWE of invalid content
+		jwe, err := v.encrypter.Encrypt([]byte("invalid"))
+		require.NoError(t, err)
+
+		serializedJWE, err := jwe.FullSerialize(json.Marshal)
+		require.NoError(t, err)
+
+		_, err = s.decrypt(&EncryptedDocument{ID: docID, JWE: json.RawMessage(serializedJWE)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal structured document")
+	})
+
+	t.Run("vault not found", func(t *testing.T) {
+		p := NewProvider(v.client, "unknown", v.encrypter, v.decrypter, v.crypto, v.macKH)
+
+		store, err := p.OpenStore("test")
+		require.NoError(t, err)
+
+		err = store.Put("key", []byte("value"))
+		require.True(t, errors.Is(err, ErrNotFound))
+
+		_, err = store.Query("tag")
+		require.True(t, errors.Is(err, ErrNotFound))
+
+		p = NewProvider(&Client{serverURL: "http://local host", httpClient: v.client.httpClient}, v.vaultID,
+			v.encrypter, v.decrypter, v.crypto, v.macKH)
+
+		store, err = p.OpenStore("test")
+		require.NoError(t, err)
+
+		_, err = store.Get("key")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "HTTP create request failed")
+	})
+}
+
+func mustAttribute(t *testing.T, store storage.Store, name, value string, anyValue bool) IndexedAttribute {
+	t.Helper()
+
+	s, ok := store.(*edvStore)
+	require.True(t, ok)
+
+	attribute, err := s.attribute(name, value, anyValue)
+	require.NoError(t, err)
+
+	return attribute
+}
+
+// macFailingCrypto fails to compute the MAC of the data containing failOn.
+type macFailingCrypto struct {
+	*tinkcrypto.Crypto
+	failOn string
+}
+
+func (c *macFailingCrypto) ComputeMAC(data []byte, kh interface{}) ([]byte, error) {
+	if bytes.Contains(data, []byte(c.failOn)) {
+		return nil, fmt.Errorf("mac error")
+	}
+
+	return c.Crypto.ComputeMAC(data, kh)
+}
+
+type failingEncrypter struct{}
+
+func (e *failingEncrypter) EncryptWithAuthData(_, _ []byte) (*jose.JSONWebEncryption, error) {
+	return nil, fmt.Errorf("encrypt error")
+}
+
+func (e *failingEncrypter) Encrypt(_ []byte) (*jose.JSONWebEncryption, error) {
+	return nil, fmt.Errorf("encrypt error")
+}
+
+type failingDecrypter struct{}
+
+func (d *failingDecrypter) Decrypt(_ *jose.JSONWebEncryption) ([]byte, error) {
+	return nil, fmt.Errorf("decrypt error")
+}
+
+func TestEDVStoreConformance(t *testing.T) {
+	storagetest.TestAll(t, newTestVault(t).provider())
+}
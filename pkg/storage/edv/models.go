@@ -0,0 +1,58 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package edv
+
+import "encoding/json"
+
+// DataVaultConfiguration represents the configuration of an Encrypted Data Vault.
+// (https://identity.foundation/secure-data-store/#datavaultconfiguration)
+type DataVaultConfiguration struct {
+	Sequence    int        `json:"sequence"`
+	Controller  string     `json:"controller"`
+	Invoker     string     `json:"invoker,omitempty"`
+	Delegator   string     `json:"delegator,omitempty"`
+	ReferenceID string     `json:"referenceId,omitempty"`
+	KEK         IDTypePair `json:"kek"`
+	HMAC        IDTypePair `json:"hmac"`
+}
+
+// IDTypePair represents an ID+Type pair.
+type IDTypePair struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// EncryptedDocument represents an Encrypted Document stored in a vault.
+// (https://identity.foundation/secure-data-store/#encrypteddocument)
+type EncryptedDocument struct {
+	ID                          string                       `json:"id"`
+	Sequence                    int                          `json:"sequence"`
+	IndexedAttributeCollections []IndexedAttributeCollection `json:"indexed,omitempty"`
+	JWE                         json.RawMessage              `json:"jwe"`
+}
+
+// IndexedAttributeCollection represents a collection of indexed attributes, all of which share a common MAC key.
+type IndexedAttributeCollection struct {
+	Sequence          int                `json:"sequence"`
+	HMAC              IDTypePair         `json:"hmac"`
+	IndexedAttributes []IndexedAttribute `json:"attributes"`
+}
+
+// IndexedAttribute represents a single indexed attribute, its name and value are MACs.
+type IndexedAttribute struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Unique bool   `json:"unique"`
+}
+
+// Query represents a query of the documents of a vault by indexed attribute: the documents having the attribute Name
+// with the value Value are matched, or with any value if Has is set.
+type Query struct {
+	Name  string `json:"index"`
+	Value string `json:"equals,omitempty"`
+	Has   bool   `json:"has,omitempty"`
+}
@@ -0,0 +1,499 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encrypted
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// Provider is a storage.Provider wrapping another provider, the records of its stores are encrypted before being
+// written to the stores of the wrapped provider (e.g. couchdb or mysql) and decrypted when read back.
+//
+// The values are encrypted with an AEAD key (e.g. kms.AES256GCMType) of a kms.KeyManager, bound to the key of
+// the record. Optionally (see WithMACKeyID), the keys of the records and their tags are replaced by their MAC
+// computed with a MAC key (kms.HMACSHA256Tag256Type) so that they don't leak either: Get and Query still work but
+// Iterator then reads and decrypts all the records of the store to find the records of the key range.
+//
+// The keys must not be kept in a KMS using the wrapped provider through this provider.
+type Provider struct {
+	provider storage.Provider
+	crypto   crypto.Crypto
+	encKH    interface{}
+	macKH    interface{}
+}
+
+type options struct {
+	macKeyID string
+}
+
+// Option configures the encrypted provider.
+type Option func(opts *options)
+
+// WithMACKeyID replaces the keys of the records and the tags by their MAC computed with the key macKeyID.
+func WithMACKeyID(macKeyID string) Option {
+	return func(opts *options) {
+		opts.macKeyID = macKeyID
+	}
+}
+
+// NewProvider returns a provider encrypting the records of the stores of provider with the key encKeyID of km.
+func NewProvider(provider storage.Provider, km kms.KeyManager, c crypto.Crypto, encKeyID string,
+	opts ...Option) (*Provider, error) {
+	o := &options{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	encKH, err := km.Get(encKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key %s: %w", encKeyID, err)
+	}
+
+	p := &Provider{provider: provider, crypto: c, encKH: encKH}
+
+	if o.macKeyID != "" {
+		p.macKH, err = km.Get(o.macKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get MAC key %s: %w", o.macKeyID, err)
+		}
+	}
+
+	return p, nil
+}
+
+// OpenStore opens the store of the wrapped provider and returns it wrapped in an encrypted store.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	store, err := p.provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptedStore{store: store, provider: p}, nil
+}
+
+// CloseStore closes the store of the wrapped provider.
+func (p *Provider) CloseStore(name string) error {
+	return p.provider.CloseStore(name)
+}
+
+// Close closes the wrapped provider.
+func (p *Provider) Close() error {
+	return p.provider.Close()
+}
+
+// encryptedRecord is the value stored in the wrapped store.
+type encryptedRecord struct {
+	Ciphertext []byte `json:"ciphertext"`
+	Nonce      []byte `json:"nonce"`
+}
+
+// record is the encrypted content of an encryptedRecord, the key is kept for the iterators when keys are MACed.
+type record struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type encryptedStore struct {
+	store    storage.Store
+	provider *Provider
+}
+
+// storedKey returns the key of the record in the wrapped store.
+func (s *encryptedStore) storedKey(k string) (string, error) {
+	if s.provider.macKH == nil {
+		return k, nil
+	}
+
+	return s.mac(k)
+}
+
+func (s *encryptedStore) mac(data string) (string, error) {
+	mac, err := s.provider.crypto.ComputeMAC([]byte(data), s.provider.macKH)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute MAC: %w", err)
+	}
+
+	// hex encoded, the MACs are used as keys and tag names of the wrapped store which may reserve some characters
+	// (e.g. CouchDB rejects the document IDs starting with an underscore)
+	return hex.EncodeToString(mac), nil
+}
+
+// storedTags returns the tags of the record in the wrapped store.
+func (s *encryptedStore) storedTags(tags []storage.Tag) ([]storage.Tag, error) {
+	if s.provider.macKH == nil || len(tags) == 0 {
+		return tags, nil
+	}
+
+	storedTags := make([]storage.Tag, len(tags))
+
+	for i, tag := range tags {
+		name, err := s.mac(tag.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := s.mac(tag.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		storedTags[i] = storage.Tag{Name: name, Value: value}
+	}
+
+	return storedTags, nil
+}
+
+// encrypt returns the encrypted record stored under the key storedKey.
+func (s *encryptedStore) encrypt(storedKey, k string, v []byte) ([]byte, error) {
+	recordBytes, err := json.Marshal(&record{Key: k, Value: v})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	// the stored key is authenticated so that records can't be swapped
+	ciphertext, nonce, err := s.provider.crypto.Encrypt(recordBytes, []byte(storedKey), s.provider.encKH)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt record: %w", err)
+	}
+
+	return json.Marshal(&encryptedRecord{Ciphertext: ciphertext, Nonce: nonce})
+}
+
+// decrypt returns the record stored under the key storedKey.
+func (s *encryptedStore) decrypt(storedKey string, v []byte) (*record, error) {
+	var encRecord encryptedRecord
+
+	err := json.Unmarshal(v, &encRecord)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal encrypted record: %w", err)
+	}
+
+	recordBytes, err := s.provider.crypto.Decrypt(encRecord.Ciphertext, encRecord.Nonce, []byte(storedKey),
+		s.provider.encKH)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt record: %w", err)
+	}
+
+	var r record
+
+	err = json.Unmarshal(recordBytes, &r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal record: %w", err)
+	}
+
+	return &r, nil
+}
+
+// operation returns the operation of the wrapped store.
+func (s *encryptedStore) operation(op *storage.Operation) (storage.Operation, error) {
+	if op.Key == "" {
+		return storage.Operation{}, storage.ErrKeyRequired
+	}
+
+	storedKey, err := s.storedKey(op.Key)
+	if err != nil {
+		return storage.Operation{}, err
+	}
+
+	if op.IsDelete() {
+		return storage.DeleteOperation(storedKey), nil
+	}
+
+	value, err := s.encrypt(storedKey, op.Key, op.Value)
+	if err != nil {
+		return storage.Operation{}, err
+	}
+
+	tags, err := s.storedTags(op.Tags)
+	if err != nil {
+		return storage.Operation{}, err
+	}
+
+	return storage.PutOperation(storedKey, value, tags...), nil
+}
+
+// Put encrypts the record and stores it in the wrapped store.
+func (s *encryptedStore) Put(k string, v []byte, tags ...storage.Tag) error {
+	if v == nil {
+		return errors.New("key and value are mandatory")
+	}
+
+	op, err := s.operation(&storage.Operation{Key: k, Value: v, Tags: tags})
+	if err != nil {
+		return err
+	}
+
+	return s.store.Put(op.Key, op.Value, op.Tags...)
+}
+
+// PutWithTTL encrypts the record and stores it in the wrapped store, with a time to live if the wrapped store
+// supports it.
+func (s *encryptedStore) PutWithTTL(k string, v []byte, ttl time.Duration, tags ...storage.Tag) error {
+	if v == nil {
+		return errors.New("key and value are mandatory")
+	}
+
+	op, err := s.operation(&storage.Operation{Key: k, Value: v, Tags: tags})
+	if err != nil {
+		return err
+	}
+
+	return storage.PutWithTTL(s.store, op.Key, op.Value, ttl, op.Tags...)
+}
+
+// Get fetches the record from the wrapped store and decrypts it.
+func (s *encryptedStore) Get(k string) ([]byte, error) {
+	if k == "" {
+		return nil, storage.ErrKeyRequired
+	}
+
+	storedKey, err := s.storedKey(k)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := s.store.Get(storedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.decrypt(storedKey, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Value, nil
+}
+
+// Iterator returns an iterator decrypting the records of the wrapped store in the key range. If the keys are MACed,
+// all the records of the wrapped store are decrypted, the records of the key range are returned ordered by key.
+func (s *encryptedStore) Iterator(start, limit string) storage.StoreIterator {
+	if s.provider.macKH == nil {
+		return &iterator{store: s, iterator: s.store.Iterator(start, limit)}
+	}
+
+	itr := &iterator{store: s, iterator: s.store.Iterator("", storage.EndKeySuffix)}
+	defer itr.Release()
+
+	limit = strings.ReplaceAll(limit, storage.EndKeySuffix, "~")
+
+	var records []*record
+
+	for itr.Next() {
+		if k := itr.current.Key; k >= start && k < limit {
+			records = append(records, itr.current)
+		}
+	}
+
+	if itr.Error() != nil {
+		return &recordsIterator{err: itr.Error()}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Key < records[j].Key
+	})
+
+	return &recordsIterator{records: records}
+}
+
+// Delete deletes the record from the wrapped store.
+func (s *encryptedStore) Delete(k string) error {
+	deleteOp := storage.DeleteOperation(k)
+
+	op, err := s.operation(&deleteOp)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Delete(op.Key)
+}
+
+// Query queries the wrapped store, with the MACed tags if the keys are MACed, and decrypts the records.
+func (s *encryptedStore) Query(expression string, opts ...storage.QueryOption) (storage.StoreIterator, error) {
+	query, err := storage.ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.provider.macKH != nil {
+		expression, err = s.mac(query.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if !query.AnyValue {
+			value, errMAC := s.mac(query.Value)
+			if errMAC != nil {
+				return nil, errMAC
+			}
+
+			expression += ":" + value
+		}
+	}
+
+	itr, err := s.store.Query(expression, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &iterator{store: s, iterator: itr}, nil
+}
+
+// Batch encrypts the records of the operations and applies them in a batch of the wrapped store.
+func (s *encryptedStore) Batch(operations []storage.Operation) error {
+	storedOperations, err := s.storedOperations(operations)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Batch(storedOperations)
+}
+
+// BatchWithTTL encrypts the records of the operations and applies them in a batch of the wrapped store, with a time
+// to live if the wrapped store supports it.
+func (s *encryptedStore) BatchWithTTL(operations []storage.Operation, ttl time.Duration) error {
+	storedOperations, err := s.storedOperations(operations)
+	if err != nil {
+		return err
+	}
+
+	return storage.BatchWithTTL(s.store, storedOperations, ttl)
+}
+
+// storedOperations returns the operations of the wrapped store applying the operations.
+func (s *encryptedStore) storedOperations(operations []storage.Operation) ([]storage.Operation, error) {
+	storedOperations := make([]storage.Operation, len(operations))
+
+	for i := range operations {
+		if !operations[i].IsDelete() && operations[i].Value == nil {
+			return nil, errors.New("key and value are mandatory")
+		}
+
+		op, err := s.operation(&operations[i])
+		if err != nil {
+			return nil, err
+		}
+
+		storedOperations[i] = op
+	}
+
+	return storedOperations, nil
+}
+
+// iterator decrypts the records of an iterator of the wrapped store.
+type iterator struct {
+	store    *encryptedStore
+	iterator storage.StoreIterator
+	current  *record
+	err      error
+}
+
+// Next moves the iterator to the next record and decrypts it.
+func (i *iterator) Next() bool {
+	i.current = nil
+
+	if i.err != nil || !i.iterator.Next() {
+		return false
+	}
+
+	i.current, i.err = i.store.decrypt(string(i.iterator.Key()), i.iterator.Value())
+
+	return i.err == nil
+}
+
+// Release releases the iterator of the wrapped store.
+func (i *iterator) Release() {
+	i.current = nil
+	i.iterator.Release()
+}
+
+// Error returns the error of the iterator.
+func (i *iterator) Error() error {
+	if i.err != nil {
+		return i.err
+	}
+
+	return i.iterator.Error()
+}
+
+// Key returns the key of the current record.
+func (i *iterator) Key() []byte {
+	if i.current == nil {
+		return nil
+	}
+
+	return []byte(i.current.Key)
+}
+
+// Value returns the value of the current record.
+func (i *iterator) Value() []byte {
+	if i.current == nil {
+		return nil
+	}
+
+	return i.current.Value
+}
+
+// recordsIterator iterates over decrypted records.
+type recordsIterator struct {
+	records []*record
+	current int
+	err     error
+}
+
+// Next moves the iterator to the next record.
+func (i *recordsIterator) Next() bool {
+	if i.current >= len(i.records) {
+		i.records = nil
+
+		return false
+	}
+
+	i.current++
+
+	return true
+}
+
+// Release releases the records.
+func (i *recordsIterator) Release() {
+	i.records = nil
+	i.current = 0
+}
+
+// Error returns the error of the iterator.
+func (i *recordsIterator) Error() error {
+	return i.err
+}
+
+// Key returns the key of the current record.
+func (i *recordsIterator) Key() []byte {
+	if i.current == 0 || i.current > len(i.records) {
+		return nil
+	}
+
+	return []byte(i.records[i.current-1].Key)
+}
+
+// Value returns the value of the current record.
+func (i *recordsIterator) Value() []byte {
+	if i.current == 0 || i.current > len(i.records) {
+		return nil
+	}
+
+	return i.records[i.current-1].Value
+}
@@ -0,0 +1,500 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encrypted
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockcrypto "github.com/hyperledger/aries-framework-go/pkg/mock/crypto"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/storagetest"
+)
+
+type testContext struct {
+	km       kms.KeyManager
+	crypto   *tinkcrypto.Crypto
+	encKeyID string
+	macKeyID string
+}
+
+func newTestContext(t *testing.T) *testContext {
+	km, err := localkms.New("local-lock://test/key/uri",
+		mockkms.NewProviderForKMS(mockstorage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	c, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	encKeyID, _, err := km.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	macKeyID, _, err := km.Create(kms.HMACSHA256Tag256Type)
+	require.NoError(t, err)
+
+	return &testContext{km: km, crypto: c, encKeyID: encKeyID, macKeyID: macKeyID}
+}
+
+func TestNewProvider(t *testing.T) {
+	ctx := newTestContext(t)
+
+	t.Run("success", func(t *testing.T) {
+		p, err := NewProvider(mem.NewProvider(), ctx.km, ctx.crypto, ctx.encKeyID, WithMACKeyID(ctx.macKeyID))
+		require.NoError(t, err)
+		require.NotNil(t, p.encKH)
+		require.NotNil(t, p.macKH)
+	})
+
+	t.Run("encryption key not found", func(t *testing.T) {
+		_, err := NewProvider(mem.NewProvider(), ctx.km, ctx.crypto, "unknown")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get encryption key unknown")
+	})
+
+	t.Run("MAC key not found", func(t *testing.T) {
+		_, err := NewProvider(mem.NewProvider(), ctx.km, ctx.crypto, ctx.encKeyID, WithMACKeyID("unknown"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get MAC key unknown")
+	})
+}
+
+func TestProvider(t *testing.T) {
+	ctx := newTestContext(t)
+
+	t.Run("open and close stores", func(t *testing.T) {
+		p, err := NewProvider(mem.NewProvider(), ctx.km, ctx.crypto, ctx.encKeyID)
+		require.NoError(t, err)
+
+		_, err = p.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, p.CloseStore("test"))
+		require.NoError(t, p.Close())
+	})
+
+	t.Run("open store failure", func(t *testing.T) {
+		p, err := NewProvider(&mockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("open error")},
+			ctx.km, ctx.crypto, ctx.encKeyID)
+		require.NoError(t, err)
+
+		_, err = p.OpenStore("test")
+		require.EqualError(t, err, "open error")
+	})
+}
+
+func TestEncryptedStore(t *testing.T) {
+	ctx := newTestContext(t)
+
+	for _, macKeyID := range []string{"", ctx.macKeyID} {
+		var opts []Option
+
+		name := "values encrypted"
+
+		if macKeyID != "" {
+			opts = append(opts, WithMACKeyID(macKeyID))
+			name = "values encrypted and keys MACed"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			underlying := mockstorage.NewMockStoreProvider()
+
+			p, err := NewProvider(underlying, ctx.km, ctx.crypto, ctx.encKeyID, opts...)
+			require.NoError(t, err)
+
+			store, err := p.OpenStore("test")
+			require.NoError(t, err)
+
+			rawStore := underlying.Store
+
+			t.Run("put and get", func(t *testing.T) {
+				data := []byte(`{"secret":"value"}`)
+
+				require.NoError(t, store.Put("key1", data, storage.Tag{Name: "type", Value: "credential"}))
+
+				value, err := store.Get("key1")
+				require.NoError(t, err)
+				require.Equal(t, data, value)
+
+				// nothing is stored in plaintext
+				for k, v := range rawStore.Store {
+					require.NotContains(t, string(v), "secret")
+
+					if macKeyID != "" {
+						require.NotEqual(t, "key1", k)
+
+						for _, tag := range rawStore.Tags[k] {
+							require.NotEqual(t, "type", tag.Name)
+							require.NotEqual(t, "credential", tag.Value)
+						}
+					}
+				}
+
+				_, err = store.Get("key2")
+				require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+				_, err = store.Get("")
+				require.True(t, errors.Is(err, storage.ErrKeyRequired))
+
+				require.True(t, errors.Is(store.Put("", data), storage.ErrKeyRequired))
+				require.Error(t, store.Put("key", nil))
+			})
+
+			t.Run("records can't be swapped", func(t *testing.T) {
+				require.NoError(t, store.Put("key2", []byte("value2")))
+
+				storedKey1, storedKey2 := "key1", "key2"
+
+				if macKeyID != "" {
+					encStore, ok := store.(*encryptedStore)
+					require.True(t, ok)
+
+					storedKey1, err = encStore.mac("key1")
+					require.NoError(t, err)
+
+					storedKey2, err = encStore.mac("key2")
+					require.NoError(t, err)
+				}
+
+				swapped := rawStore.Store[storedKey2]
+				rawStore.Store[storedKey2] = rawStore.Store[storedKey1]
+
+				_, err = store.Get("key2")
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "failed to decrypt record")
+
+				rawStore.Store[storedKey2] = swapped
+			})
+
+			t.Run("iterator", func(t *testing.T) {
+				require.NoError(t, store.Put("abc_2", []byte("value_abc_2")))
+				require.NoError(t, store.Put("abc_1", []byte("value_abc_1")))
+				require.NoError(t, store.Put("abd_1", []byte("value_abd_1")))
+
+				itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+
+				var keys []string
+
+				for itr.Next() {
+					require.Equal(t, "value_"+string(itr.Key()), string(itr.Value()))
+
+					keys = append(keys, string(itr.Key()))
+				}
+
+				require.NoError(t, itr.Error())
+				require.ElementsMatch(t, []string{"abc_1", "abc_2"}, keys)
+
+				itr.Release()
+				require.False(t, itr.Next())
+				require.Nil(t, itr.Key())
+				require.Nil(t, itr.Value())
+			})
+
+			t.Run("query", func(t *testing.T) {
+				require.NoError(t, store.Put("key3", []byte("value3"), storage.Tag{Name: "type", Value: "presentation"}))
+
+				for expression, expected := range map[string][]string{
+					"type":              {"key1", "key3"},
+					"type:credential":   {"key1"},
+					"type:presentation": {"key3"},
+					"other":             nil,
+				} {
+					itr, err := store.Query(expression)
+					require.NoError(t, err)
+
+					var keys []string
+
+					for itr.Next() {
+						keys = append(keys, string(itr.Key()))
+					}
+
+					require.NoError(t, itr.Error())
+					require.ElementsMatch(t, expected, keys, expression)
+
+					itr.Release()
+				}
+
+				_, err := store.Query("")
+				require.True(t, errors.Is(err, storage.ErrInvalidQuery))
+			})
+
+			t.Run("put with TTL", func(t *testing.T) {
+				require.NoError(t, storage.PutWithTTL(store, "expiring", []byte("value"), time.Hour))
+
+				value, err := store.Get("expiring")
+				require.NoError(t, err)
+				require.Equal(t, []byte("value"), value)
+
+				storedKey := "expiring"
+
+				if macKeyID != "" {
+					storedKey, err = store.(*encryptedStore).mac("expiring")
+					require.NoError(t, err)
+				}
+
+				require.Equal(t, time.Hour, rawStore.TTLs[storedKey])
+
+				require.Error(t, storage.PutWithTTL(store, "expiring", nil, time.Hour))
+				require.True(t, errors.Is(storage.PutWithTTL(store, "", []byte("value"), time.Hour),
+					storage.ErrKeyRequired))
+			})
+
+			t.Run("batch with TTL", func(t *testing.T) {
+				err := storage.BatchWithTTL(store, []storage.Operation{
+					storage.PutOperation("batch-expiring", []byte("value")),
+				}, time.Hour)
+				require.NoError(t, err)
+
+				value, err := store.Get("batch-expiring")
+				require.NoError(t, err)
+				require.Equal(t, []byte("value"), value)
+
+				storedKey := "batch-expiring"
+
+				if macKeyID != "" {
+					storedKey, err = store.(*encryptedStore).mac("batch-expiring")
+					require.NoError(t, err)
+				}
+
+				require.Equal(t, time.Hour, rawStore.TTLs[storedKey])
+
+				err = storage.BatchWithTTL(store, []storage.Operation{storage.PutOperation("batch-expiring", nil)},
+					time.Hour)
+				require.EqualError(t, err, "key and value are mandatory")
+
+				require.NoError(t, store.Delete("batch-expiring"))
+			})
+
+			t.Run("batch and delete", func(t *testing.T) {
+				err := store.Batch([]storage.Operation{
+					storage.PutOperation("key4", []byte("value4"), storage.Tag{Name: "type", Value: "credential"}),
+					storage.DeleteOperation("key1"),
+				})
+				require.NoError(t, err)
+
+				_, err = store.Get("key1")
+				require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+				value, err := store.Get("key4")
+				require.NoError(t, err)
+				require.Equal(t, []byte("value4"), value)
+
+				require.NoError(t, store.Delete("key4"))
+
+				_, err = store.Get("key4")
+				require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+				require.True(t, errors.Is(store.Delete(""), storage.ErrKeyRequired))
+
+				err = store.Batch([]storage.Operation{storage.PutOperation("", []byte("value"))})
+				require.True(t, errors.Is(err, storage.ErrKeyRequired))
+
+				err = store.Batch([]storage.Operation{storage.PutOperation("key5", nil)})
+				require.EqualError(t, err, "key and value are mandatory")
+			})
+		})
+	}
+}
+
+func TestEncryptedStoreFailures(t *testing.T) {
+	ctx := newTestContext(t)
+
+	newStore := func(t *testing.T, underlying storage.Provider, c *mockcrypto.Crypto, macKeyID string) storage.Store {
+		var opts []Option
+		if macKeyID != "" {
+			opts = append(opts, WithMACKeyID(macKeyID))
+		}
+
+		p, err := NewProvider(underlying, ctx.km, c, ctx.encKeyID, opts...)
+		require.NoError(t, err)
+
+		store, err := p.OpenStore("test")
+		require.NoError(t, err)
+
+		return store
+	}
+
+	t.Run("encrypt failure", func(t *testing.T) {
+		store := newStore(t, mem.NewProvider(), &mockcrypto.Crypto{EncryptErr: fmt.Errorf("encrypt error")}, "")
+
+		err := store.Put("key", []byte("value"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to encrypt record: encrypt error")
+
+		err = store.Batch([]storage.Operation{storage.PutOperation("key", []byte("value"))})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "encrypt error")
+	})
+
+	t.Run("decrypt failure", func(t *testing.T) {
+		underlying := mem.NewProvider()
+		store := newStore(t, underlying, &mockcrypto.Crypto{DecryptErr: fmt.Errorf("decrypt error")}, "")
+
+		require.NoError(t, store.Put("key", []byte("value"), storage.Tag{Name: "tag"}))
+
+		_, err := store.Get("key")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decrypt record: decrypt error")
+
+		itr := store.Iterator("", storage.EndKeySuffix)
+		require.False(t, itr.Next())
+		require.Error(t, itr.Error())
+
+		itr, err = store.Query("tag")
+		require.NoError(t, err)
+		require.False(t, itr.Next())
+		require.Error(t, itr.Error())
+	})
+
+	t.Run("invalid stored records", func(t *testing.T) {
+		underlying := mem.NewProvider()
+		store := newStore(t, underlying, &mockcrypto.Crypto{DecryptValue: []byte("not json")}, "")
+
+		rawStore, err := underlying.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, rawStore.Put("key1", []byte("not json")))
+		require.NoError(t, store.Put("key2", []byte("value")))
+
+		_, err = store.Get("key1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal encrypted record")
+
+		_, err = store.Get("key2")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal record")
+	})
+
+	t.Run("MAC failure", func(t *testing.T) {
+		store := newStore(t, mem.NewProvider(), &mockcrypto.Crypto{ComputeMACErr: fmt.Errorf("mac error")},
+			ctx.macKeyID)
+
+		err := store.Put("key", []byte("value"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to compute MAC: mac error")
+
+		_, err = store.Get("key")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mac error")
+
+		err = store.Delete("key")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mac error")
+
+		_, err = store.Query("tag")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mac error")
+	})
+
+	t.Run("MAC of tags failure", func(t *testing.T) {
+		macCrypto := &macFailingCrypto{Crypto: ctx.crypto, failOn: "tag"}
+
+		p, err := NewProvider(mem.NewProvider(), ctx.km, macCrypto, ctx.encKeyID, WithMACKeyID(ctx.macKeyID))
+		require.NoError(t, err)
+
+		store, err := p.OpenStore("test")
+		require.NoError(t, err)
+
+		err = store.Put("key", []byte("value"), storage.Tag{Name: "tag", Value: "value"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mac error")
+
+		err = store.Put("key", []byte("value"), storage.Tag{Name: "name", Value: "tag"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mac error")
+
+		_, err = store.Query("name:tag")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mac error")
+	})
+
+	t.Run("wrapped store failures", func(t *testing.T) {
+		store := newStore(t, mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+			Store:    map[string][]byte{},
+			ErrQuery: fmt.Errorf("query error"),
+			ErrItr:   fmt.Errorf("iterator error"),
+		}), &mockcrypto.Crypto{}, ctx.macKeyID)
+
+		_, err := store.Query("tag")
+		require.EqualError(t, err, "query error")
+
+		itr := store.Iterator("", storage.EndKeySuffix)
+		require.False(t, itr.Next())
+		require.EqualError(t, itr.Error(), "iterator error")
+	})
+}
+
+func TestEncryptedStoreMACEncoding(t *testing.T) {
+	ctx := newTestContext(t)
+
+	// the base64 encoding of this MAC starts with an underscore, reserved in CouchDB document IDs
+	mac := []byte{0xfc, 0x01}
+	require.True(t, strings.HasPrefix(base64.RawURLEncoding.EncodeToString(mac), "_"))
+
+	underlying := mockstorage.NewMockStoreProvider()
+
+	p, err := NewProvider(underlying, ctx.km, &mockcrypto.Crypto{ComputeMACValue: mac}, ctx.encKeyID,
+		WithMACKeyID(ctx.macKeyID))
+	require.NoError(t, err)
+
+	store, err := p.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key", []byte("value"), storage.Tag{Name: "tag", Value: "value"}))
+
+	_, ok := underlying.Store.Store["fc01"]
+	require.True(t, ok)
+
+	itr, err := underlying.Store.Query("fc01:fc01")
+	require.NoError(t, err)
+	require.True(t, itr.Next())
+	require.Equal(t, "fc01", string(itr.Key()))
+}
+
+// macFailingCrypto fails to compute the MAC of the data containing failOn.
+type macFailingCrypto struct {
+	*tinkcrypto.Crypto
+	failOn string
+}
+
+func (c *macFailingCrypto) ComputeMAC(data []byte, kh interface{}) ([]byte, error) {
+	if strings.Contains(string(data), c.failOn) {
+		return nil, fmt.Errorf("mac error")
+	}
+
+	return c.Crypto.ComputeMAC(data, kh)
+}
+
+func TestEncryptedStoreConformance(t *testing.T) {
+	ctx := newTestContext(t)
+
+	t.Run("values encrypted", func(t *testing.T) {
+		p, err := NewProvider(mem.NewProvider(), ctx.km, ctx.crypto, ctx.encKeyID)
+		require.NoError(t, err)
+
+		storagetest.TestAll(t, p)
+	})
+
+	t.Run("values encrypted and keys MACed", func(t *testing.T) {
+		p, err := NewProvider(mem.NewProvider(), ctx.km, ctx.crypto, ctx.encKeyID, WithMACKeyID(ctx.macKeyID))
+		require.NoError(t, err)
+
+		storagetest.TestAll(t, p)
+	})
+}
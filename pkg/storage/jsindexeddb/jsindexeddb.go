@@ -32,9 +32,12 @@ import (
 const (
 	dbName    = "aries-%s"
 	defDbName = "aries"
+	// tagsIndex is the multi entry index of the record tags, each tag is indexed as "name" and "name:value"
+	tagsIndex = "tags"
 )
 
-var dbVersion = 1 //nolint:gochecknoglobals
+// dbVersion 2 adds the tags index to the object stores
+var dbVersion = 2 //nolint:gochecknoglobals
 
 // Provider jsindexeddb implementation of storage.Provider interface
 type Provider struct {
@@ -93,8 +96,16 @@ func (p *Provider) openDB(db string, names ...string) error {
 		m := make(map[string]interface{})
 		m["keyPath"] = "key"
 		for _, name := range names {
-			fmt.Printf("indexedDB create object store %s\n", name)
-			this.Get("result").Call("createObjectStore", name, m)
+			var objectStore js.Value
+			if this.Get("result").Get("objectStoreNames").Call("contains", name).Bool() {
+				objectStore = this.Get("transaction").Call("objectStore", name)
+			} else {
+				fmt.Printf("indexedDB create object store %s\n", name)
+				objectStore = this.Get("result").Call("createObjectStore", name, m)
+			}
+			if !objectStore.Get("indexNames").Call("contains", tagsIndex).Bool() {
+				objectStore.Call("createIndex", tagsIndex, tagsIndex, map[string]interface{}{"multiEntry": true})
+			}
 		}
 		return nil
 	}))
@@ -121,21 +132,64 @@ type store struct {
 	db   *js.Value
 }
 
-// Put stores the key and the record
-func (s *store) Put(k string, v []byte) error {
+// Put stores the key and the record with its tags
+func (s *store) Put(k string, v []byte, tags ...storage.Tag) error {
 	if k == "" || v == nil {
 		return errors.New("key and value are mandatory")
 	}
 
+	req := s.db.Call("transaction", s.name, "readwrite").Call("objectStore", s.name).Call("put", record(k, v, tags))
+
+	_, err := getResult(req)
+	if err != nil {
+		return fmt.Errorf("failed to store data: %w", err)
+	}
+
+	return nil
+}
+
+// record returns the object stored for the key and the value with its tags.
+func record(k string, v []byte, tags []storage.Tag) map[string]interface{} {
+	tagsEntries := make([]interface{}, 0, 2*len(tags))
+
+	for _, tag := range tags {
+		tagsEntries = append(tagsEntries, tag.Name, tag.Name+":"+tag.Value)
+	}
+
 	m := make(map[string]interface{})
 	m["key"] = k
 	m["value"] = string(v)
+	m["tags"] = tagsEntries
+
+	return m
+}
 
-	req := s.db.Call("transaction", s.name, "readwrite").Call("objectStore", s.name).Call("put", m)
+// Batch applies the operations in one readwrite transaction, which is aborted if one of the operations fails.
+func (s *store) Batch(operations []storage.Operation) error {
+	for _, op := range operations {
+		if op.Key == "" {
+			return storage.ErrKeyRequired
+		}
 
-	_, err := getResult(req)
+		if !op.IsDelete() && op.Value == nil {
+			return errors.New("key and value are mandatory")
+		}
+	}
+
+	tx := s.db.Call("transaction", s.name, "readwrite")
+	objectStore := tx.Call("objectStore", s.name)
+
+	for _, op := range operations {
+		if op.IsDelete() {
+			objectStore.Call("delete", op.Key)
+		} else {
+			objectStore.Call("put", record(op.Key, op.Value, op.Tags))
+		}
+	}
+
+	err := getTransactionResult(tx)
 	if err != nil {
-		return fmt.Errorf("failed to store data: %w", err)
+		return fmt.Errorf("failed to apply batch: %w", err)
 	}
 
 	return nil
@@ -144,7 +198,7 @@ func (s *store) Put(k string, v []byte) error {
 // Get fetches the record based on key
 func (s *store) Get(k string) ([]byte, error) {
 	if k == "" {
-		return nil, errors.New("key is mandatory")
+		return nil, storage.ErrKeyRequired
 	}
 
 	req := s.db.Call("transaction", s.name).Call("objectStore", s.name).Call("get", k)
@@ -178,7 +232,7 @@ func (s *store) Iterator(start, limit string) storage.StoreIterator {
 // Delete will delete record with k key
 func (s *store) Delete(k string) error {
 	if k == "" {
-		return errors.New("key is mandatory")
+		return storage.ErrKeyRequired
 	}
 
 	req := s.db.Call("transaction", s.name, "readwrite").Call("objectStore", s.name).Call("delete", k)
@@ -191,6 +245,30 @@ func (s *store) Delete(k string) error {
 	return nil
 }
 
+// Query returns iterator over the records having a tag matching the expression, found using the tags index.
+func (s *store) Query(expression string, _ ...storage.QueryOption) (storage.StoreIterator, error) {
+	query, err := storage.ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	indexKey := query.Name
+	if !query.AnyValue {
+		indexKey += ":" + query.Value
+	}
+
+	keyRange := js.Global().Get("IDBKeyRange").Call("only", indexKey)
+	req := s.db.Call("transaction", s.name).Call("objectStore", s.name).Call("index", tagsIndex).
+		Call("getAll", keyRange)
+
+	batch, err := getResult(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data: %w", err)
+	}
+
+	return newIterator(batch, nil), nil
+}
+
 type iterator struct {
 	batch *js.Value
 	err   error
@@ -266,6 +344,37 @@ func getResult(req js.Value) (*js.Value, error) {
 	}
 }
 
+func getTransactionResult(tx js.Value) error {
+	oncomplete := make(chan struct{})
+	// a failed transaction triggers both onerror and onabort
+	onerror := make(chan js.Value, 2) //nolint:gomnd
+
+	const timeout = 10
+
+	tx.Set("oncomplete", js.FuncOf(func(this js.Value, inputs []js.Value) interface{} {
+		oncomplete <- struct{}{}
+		return nil
+	}))
+	onFailure := js.FuncOf(func(this js.Value, inputs []js.Value) interface{} {
+		onerror <- this.Get("error")
+		return nil
+	})
+	tx.Set("onerror", onFailure)
+	tx.Set("onabort", onFailure)
+	select {
+	case <-oncomplete:
+		return nil
+	case value := <-onerror:
+		if !value.Truthy() {
+			return errors.New("transaction aborted")
+		}
+
+		return fmt.Errorf("%s %s", value.Get("name").String(), value.Get("message").String())
+	case <-time.After(timeout * time.Second):
+		return errors.New("timeout waiting for transaction")
+	}
+}
+
 // since jsindexdb doesn't support adding object stores on fly, using predefined object store names to
 //  create object store in advance instead of creating a database per store.
 // TODO pass store names from higher level packages during initialization [Issue #1347]
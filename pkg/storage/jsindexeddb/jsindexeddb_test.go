@@ -175,3 +175,90 @@ func TestIndexDBStoreDelete(t *testing.T) {
 	require.EqualError(t, err, storage.ErrDataNotFound.Error())
 	require.Empty(t, doc)
 }
+
+func TestStoreQuery(t *testing.T) {
+	prov, err := NewProvider(sampleDBName)
+	require.NoError(t, err)
+	store, err := prov.OpenStore("testquery")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key3", []byte("value3"), storage.Tag{Name: "type", Value: "b"}))
+	require.NoError(t, store.Put("key1", []byte("value1"), storage.Tag{Name: "type", Value: "a"},
+		storage.Tag{Name: "flag"}))
+	require.NoError(t, store.Put("key2", []byte("value2"), storage.Tag{Name: "type", Value: "a"}))
+	require.NoError(t, store.Put("key4", []byte("value4")))
+
+	queryKeys := func(expression string) []string {
+		itr, err := store.Query(expression)
+		require.NoError(t, err)
+
+		var keys []string
+
+		for itr.Next() {
+			require.Equal(t, "value"+string(itr.Key())[3:], string(itr.Value()))
+
+			keys = append(keys, string(itr.Key()))
+		}
+
+		require.NoError(t, itr.Error())
+
+		return keys
+	}
+
+	require.Equal(t, []string{"key1", "key2", "key3"}, queryKeys("type"))
+	require.Equal(t, []string{"key1", "key2"}, queryKeys("type:a"))
+	require.Equal(t, []string{"key1"}, queryKeys("flag"))
+	require.Empty(t, queryKeys("unknown"))
+
+	// put without tags removes the tags of the record
+	require.NoError(t, store.Put("key1", []byte("value1")))
+	require.Equal(t, []string{"key2"}, queryKeys("type:a"))
+
+	require.NoError(t, store.Delete("key2"))
+	require.Empty(t, queryKeys("type:a"))
+
+	_, err = store.Query(":a")
+	require.Error(t, err)
+}
+
+func TestStoreBatch(t *testing.T) {
+	prov, err := NewProvider(sampleDBName)
+	require.NoError(t, err)
+	store, err := prov.OpenStore("testbatch")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+
+	err = store.Batch([]storage.Operation{
+		storage.PutOperation("key2", []byte("value2"), storage.Tag{Name: "tag"}),
+		storage.DeleteOperation("key1"),
+		storage.PutOperation("key3", []byte("value3")),
+		storage.PutOperation("key3", []byte("value3-2"), storage.Tag{Name: "tag"}),
+	})
+	require.NoError(t, err)
+
+	_, err = store.Get("key1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), storage.ErrDataNotFound.Error())
+
+	value, err := store.Get("key3")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value3-2"), value)
+
+	itr, err := store.Query("tag")
+	require.NoError(t, err)
+
+	var keys []string
+
+	for itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+
+	require.Equal(t, []string{"key2", "key3"}, keys)
+
+	err = store.Batch([]storage.Operation{storage.PutOperation("", []byte("value"))})
+	require.Error(t, err)
+
+	err = store.Batch([]storage.Operation{storage.PutOperation("key4", nil)})
+	require.EqualError(t, err, "key and value are mandatory")
+}
@@ -9,30 +9,68 @@ SPDX-License-Identifier: Apache-2.0
 package leveldb
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/util"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
-const pathPattern = "%s-%s"
+var logger = log.New("aries-framework/storage/leveldb")
+
+const (
+	pathPattern = "%s-%s"
+
+	// tag index keys are prefixed with 0xff bytes to sort after the keys of the records (iterators are limited with '~')
+	tagsKeyPrefix     = "\xff\xfftags\x00"
+	tagIndexKeyPrefix = "\xff\xfftag\x00"
+	tagIndexSeparator = "\x00"
+
+	// the expiry of a record is kept under its key and in the expiry index, ordered by expiry time
+	expiryKeyPrefix      = "\xff\xffexpiry\x00"
+	expiryIndexKeyPrefix = "\xff\xffexpiries\x00"
+
+	defaultSweepInterval = time.Minute
+)
 
 // Provider leveldb implementation of storage.Provider interface
 type Provider struct {
-	dbPath string
-	dbs    map[string]*leveldbStore
-	lock   sync.RWMutex
+	dbPath        string
+	dbs           map[string]*leveldbStore
+	lock          sync.RWMutex
+	sweepInterval time.Duration
+	stopSweeper   chan struct{}
+}
+
+// Option configures the leveldb provider.
+type Option func(p *Provider)
+
+// WithSweepInterval sets the interval of the sweeps deleting the expired records, one minute by default.
+func WithSweepInterval(interval time.Duration) Option {
+	return func(p *Provider) {
+		p.sweepInterval = interval
+	}
 }
 
 // NewProvider instantiates Provider
-func NewProvider(dbPath string) *Provider {
-	return &Provider{dbs: make(map[string]*leveldbStore), dbPath: dbPath}
+func NewProvider(dbPath string, opts ...Option) *Provider {
+	p := &Provider{dbs: make(map[string]*leveldbStore), dbPath: dbPath, sweepInterval: defaultSweepInterval}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 // OpenStore opens and returns a store for given name space.
@@ -65,7 +103,7 @@ func (p *Provider) newLeveldbStore(name string) (*leveldbStore, error) {
 		return nil, err
 	}
 
-	store := &leveldbStore{db}
+	store := &leveldbStore{db: db, provider: p}
 	p.dbs[strings.ToLower(name)] = store
 
 	return store, nil
@@ -91,6 +129,11 @@ func (p *Provider) Close() error {
 
 	p.dbs = make(map[string]*leveldbStore)
 
+	if p.stopSweeper != nil {
+		close(p.stopSweeper)
+		p.stopSweeper = nil
+	}
+
 	return nil
 }
 
@@ -110,23 +153,303 @@ func (p *Provider) CloseStore(name string) error {
 	return nil
 }
 
+// startSweeper starts the background sweep of the expired records of the stores, if not started yet.
+func (p *Provider) startSweeper() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.stopSweeper != nil {
+		return
+	}
+
+	p.stopSweeper = make(chan struct{})
+
+	go p.sweep(p.stopSweeper)
+}
+
+// sweep deletes the expired records of the stores every sweep interval, until stop is closed.
+func (p *Provider) sweep(stop chan struct{}) {
+	ticker := time.NewTicker(p.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			p.lock.RLock()
+
+			stores := make([]*leveldbStore, 0, len(p.dbs))
+			for _, store := range p.dbs {
+				stores = append(stores, store)
+			}
+
+			p.lock.RUnlock()
+
+			for _, store := range stores {
+				err := store.deleteExpired(now)
+				if err != nil && !errors.Is(err, leveldb.ErrClosed) {
+					logger.Warnf("failed to delete expired records: %s", err)
+				}
+			}
+		}
+	}
+}
+
 type leveldbStore struct {
-	db *leveldb.DB
+	db       *leveldb.DB
+	provider *Provider
+	// tagsLock serializes the updates of the tag and expiry indexes of the records
+	tagsLock sync.Mutex
 }
 
-// Put stores the key and the record
-func (s *leveldbStore) Put(k string, v []byte) error {
+// Put stores the key and the record with its tags. The record and its tag index entries are written in one batch.
+func (s *leveldbStore) Put(k string, v []byte, tags ...storage.Tag) error {
 	if k == "" || v == nil {
 		return errors.New("key and value are mandatory")
 	}
 
-	return s.db.Put([]byte(k), v, nil)
+	return s.Batch([]storage.Operation{storage.PutOperation(k, v, tags...)})
+}
+
+// PutWithTTL stores the key and the record with its tags and its expiry in the expiry index, the record is deleted by
+// the sweep following its expiry.
+func (s *leveldbStore) PutWithTTL(k string, v []byte, ttl time.Duration, tags ...storage.Tag) error {
+	if k == "" || v == nil {
+		return errors.New("key and value are mandatory")
+	}
+
+	return s.BatchWithTTL([]storage.Operation{storage.PutOperation(k, v, tags...)}, ttl)
+}
+
+// BatchWithTTL applies the operations atomically like Batch, with the expiry of the records stored in the expiry index
+// in the same write batch. The records are deleted by the sweep following their expiry.
+func (s *leveldbStore) BatchWithTTL(operations []storage.Operation, ttl time.Duration) error {
+	err := validateOperations(operations)
+	if err != nil {
+		return err
+	}
+
+	expiry := make([]byte, 8) //nolint:gomnd
+	binary.BigEndian.PutUint64(expiry, uint64(time.Now().Add(ttl).UnixNano()))
+
+	s.tagsLock.Lock()
+
+	batch := new(leveldb.Batch)
+
+	err = s.addOperations(batch, operations)
+	if err != nil {
+		s.tagsLock.Unlock()
+
+		return err
+	}
+
+	for _, op := range operations {
+		if !op.IsDelete() {
+			batch.Put([]byte(expiryKeyPrefix+op.Key), expiry)
+			batch.Put(expiryIndexKey(expiry, op.Key), nil)
+		}
+	}
+
+	err = s.db.Write(batch, nil)
+
+	s.tagsLock.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	s.provider.startSweeper()
+
+	return nil
+}
+
+// deleteExpired deletes the records expired at now and their expiry index entries. The index entries of the records
+// written again since are deleted only.
+func (s *leveldbStore) deleteExpired(now time.Time) error {
+	limit := make([]byte, 8) //nolint:gomnd
+	binary.BigEndian.PutUint64(limit, uint64(now.UnixNano()))
+
+	s.tagsLock.Lock()
+	defer s.tagsLock.Unlock()
+
+	batch := new(leveldb.Batch)
+
+	var operations []storage.Operation
+
+	itr := s.db.NewIterator(&util.Range{
+		Start: []byte(expiryIndexKeyPrefix),
+		Limit: expiryIndexKey(limit, ""),
+	}, nil)
+	defer itr.Release()
+
+	for itr.Next() {
+		indexKey := itr.Key()
+		expiry := indexKey[len(expiryIndexKeyPrefix) : len(expiryIndexKeyPrefix)+len(limit)]
+		k := string(indexKey[len(expiryIndexKeyPrefix)+len(limit):])
+
+		recordExpiry, err := s.db.Get([]byte(expiryKeyPrefix+k), nil)
+		if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+			return fmt.Errorf("failed to get expiry: %w", err)
+		}
+
+		if bytes.Equal(recordExpiry, expiry) {
+			operations = append(operations, storage.DeleteOperation(k))
+		}
+
+		batch.Delete(append([]byte(nil), indexKey...))
+	}
+
+	if itr.Error() != nil {
+		return itr.Error()
+	}
+
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	err := s.addOperations(batch, operations)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Write(batch, nil)
+}
+
+func expiryIndexKey(expiry []byte, k string) []byte {
+	return append([]byte(expiryIndexKeyPrefix+string(expiry)), k...)
+}
+
+// Batch applies the operations atomically with a leveldb write batch.
+func (s *leveldbStore) Batch(operations []storage.Operation) error {
+	err := validateOperations(operations)
+	if err != nil {
+		return err
+	}
+
+	s.tagsLock.Lock()
+	defer s.tagsLock.Unlock()
+
+	batch := new(leveldb.Batch)
+
+	err = s.addOperations(batch, operations)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Write(batch, nil)
+}
+
+func validateOperations(operations []storage.Operation) error {
+	for _, op := range operations {
+		if op.Key == "" {
+			return storage.ErrKeyRequired
+		}
+
+		if !op.IsDelete() && op.Value == nil {
+			return errors.New("key and value are mandatory")
+		}
+	}
+
+	return nil
+}
+
+// addOperations adds the operations to batch with the updates of the tag index, the expiry of the records is removed.
+// The caller must hold tagsLock.
+func (s *leveldbStore) addOperations(batch *leveldb.Batch, operations []storage.Operation) error {
+	// tags of the records written by previous operations of the batch
+	batchTags := make(map[string][]storage.Tag)
+
+	for _, op := range operations {
+		err := s.deleteTags(batch, batchTags, op.Key)
+		if err != nil {
+			return err
+		}
+
+		// the expiry index entry is left to the sweep
+		batch.Delete([]byte(expiryKeyPrefix + op.Key))
+
+		if op.IsDelete() {
+			batch.Delete([]byte(op.Key))
+
+			batchTags[op.Key] = nil
+
+			continue
+		}
+
+		batch.Put([]byte(op.Key), op.Value)
+
+		if len(op.Tags) > 0 {
+			tagsBytes, err := json.Marshal(op.Tags)
+			if err != nil {
+				return fmt.Errorf("failed to marshal tags: %w", err)
+			}
+
+			batch.Put([]byte(tagsKeyPrefix+op.Key), tagsBytes)
+
+			for _, tag := range op.Tags {
+				batch.Put(tagIndexKey(tag.Name, tag.Value, op.Key), nil)
+			}
+		}
+
+		batchTags[op.Key] = op.Tags
+	}
+
+	return nil
+}
+
+// deleteTags adds the deletion of the tags of record k to batch, the tags are read from batchTags if the record is
+// written by the batch.
+func (s *leveldbStore) deleteTags(batch *leveldb.Batch, batchTags map[string][]storage.Tag, k string) error {
+	tags, ok := batchTags[k]
+	if !ok {
+		var err error
+
+		tags, err = s.getTags(k)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, tag := range tags {
+		batch.Delete(tagIndexKey(tag.Name, tag.Value, k))
+	}
+
+	if len(tags) > 0 {
+		batch.Delete([]byte(tagsKeyPrefix + k))
+	}
+
+	return nil
+}
+
+func (s *leveldbStore) getTags(k string) ([]storage.Tag, error) {
+	tagsBytes, err := s.db.Get([]byte(tagsKeyPrefix+k), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	var tags []storage.Tag
+
+	err = json.Unmarshal(tagsBytes, &tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func tagIndexKey(name, value, k string) []byte {
+	return []byte(tagIndexKeyPrefix + name + tagIndexSeparator + value + tagIndexSeparator + k)
 }
 
 // Get fetches the record based on key
 func (s *leveldbStore) Get(k string) ([]byte, error) {
 	if k == "" {
-		return nil, errors.New("key is mandatory")
+		return nil, storage.ErrKeyRequired
 	}
 
 	data, err := s.db.Get([]byte(k), nil)
@@ -154,8 +477,97 @@ func (s *leveldbStore) Iterator(start, limit string) storage.StoreIterator {
 // Delete will delete record with k key
 func (s *leveldbStore) Delete(k string) error {
 	if k == "" {
-		return errors.New("key is mandatory")
+		return storage.ErrKeyRequired
+	}
+
+	return s.Batch([]storage.Operation{storage.DeleteOperation(k)})
+}
+
+// Query returns iterator over the records having a tag matching the expression, the records are read from the tag
+// index of a snapshot of the db.
+func (s *leveldbStore) Query(expression string, _ ...storage.QueryOption) (storage.StoreIterator, error) {
+	query, err := storage.ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := tagIndexKeyPrefix + query.Name + tagIndexSeparator
+	if !query.AnyValue {
+		prefix += query.Value + tagIndexSeparator
 	}
 
-	return s.db.Delete([]byte(k), nil)
+	snapshot, err := s.db.GetSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	return &queryIterator{
+		snapshot: snapshot,
+		index:    snapshot.NewIterator(util.BytesPrefix([]byte(prefix)), nil),
+		prefix:   len(prefix),
+		anyValue: query.AnyValue,
+	}, nil
+}
+
+// queryIterator iterates over the tag index entries of a query and reads the matching records.
+type queryIterator struct {
+	snapshot *leveldb.Snapshot
+	index    iterator.Iterator
+	prefix   int
+	anyValue bool
+	key      []byte
+	value    []byte
+	err      error
+}
+
+// Next moves the iterator to the next record.
+func (i *queryIterator) Next() bool {
+	i.key, i.value = nil, nil
+
+	if i.err != nil || !i.index.Next() {
+		return false
+	}
+
+	key := i.index.Key()[i.prefix:]
+
+	if i.anyValue {
+		// the index key suffix is value + separator + key
+		key = key[bytes.Index(key, []byte(tagIndexSeparator))+1:]
+	}
+
+	value, err := i.snapshot.Get(key, nil)
+	if err != nil {
+		i.err = fmt.Errorf("failed to get record '%s': %w", key, err)
+
+		return false
+	}
+
+	i.key, i.value = append([]byte(nil), key...), value
+
+	return true
+}
+
+// Release releases the iterator and the snapshot.
+func (i *queryIterator) Release() {
+	i.index.Release()
+	i.snapshot.Release()
+}
+
+// Error returns the error of the iterator.
+func (i *queryIterator) Error() error {
+	if i.err != nil {
+		return i.err
+	}
+
+	return i.index.Error()
+}
+
+// Key returns the key of the current record.
+func (i *queryIterator) Key() []byte {
+	return i.key
+}
+
+// Value returns the value of the current record.
+func (i *queryIterator) Value() []byte {
+	return i.value
 }
@@ -9,15 +9,19 @@ SPDX-License-Identifier: Apache-2.0
 package leveldb
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/storagetest"
 )
 
 func setupLevelDB(t testing.TB) (string, func()) {
@@ -294,3 +298,231 @@ func TestLevelDBStoreDelete(t *testing.T) {
 	require.EqualError(t, err, storage.ErrDataNotFound.Error())
 	require.Empty(t, doc)
 }
+
+func TestLevelDBStoreQuery(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path)
+	defer func() { require.NoError(t, prov.Close()) }()
+
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key3", []byte("value3"), storage.Tag{Name: "type", Value: "b"}))
+	require.NoError(t, store.Put("key1", []byte("value1"), storage.Tag{Name: "type", Value: "a"},
+		storage.Tag{Name: "flag"}))
+	require.NoError(t, store.Put("key2", []byte("value2"), storage.Tag{Name: "type", Value: "a"}))
+	require.NoError(t, store.Put("key4", []byte("value4")))
+
+	queryKeys := func(expression string) []string {
+		itr, err := store.Query(expression)
+		require.NoError(t, err)
+
+		defer itr.Release()
+
+		var keys []string
+
+		for itr.Next() {
+			require.Equal(t, "value"+string(itr.Key())[3:], string(itr.Value()))
+
+			keys = append(keys, string(itr.Key()))
+		}
+
+		require.NoError(t, itr.Error())
+
+		return keys
+	}
+
+	require.Equal(t, []string{"key1", "key2", "key3"}, queryKeys("type"))
+	require.Equal(t, []string{"key1", "key2"}, queryKeys("type:a"))
+	require.Equal(t, []string{"key1"}, queryKeys("flag"))
+	require.Empty(t, queryKeys("flag:a"))
+	require.Empty(t, queryKeys("unknown"))
+
+	// tag index entries are not returned by the iterator
+	itr := store.Iterator("key", "key"+storage.EndKeySuffix)
+	count := 0
+
+	for itr.Next() {
+		count++
+	}
+
+	itr.Release()
+	require.Equal(t, 4, count)
+
+	// put without tags removes the tags of the record
+	require.NoError(t, store.Put("key1", []byte("value1")))
+	require.Equal(t, []string{"key2"}, queryKeys("type:a"))
+	require.Empty(t, queryKeys("flag"))
+
+	require.NoError(t, store.Delete("key2"))
+	require.Empty(t, queryKeys("type:a"))
+
+	_, err = store.Query(":a")
+	require.True(t, errors.Is(err, storage.ErrInvalidQuery))
+}
+
+func TestLevelDBStoreBatch(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path)
+	defer func() { require.NoError(t, prov.Close()) }()
+
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1"), storage.Tag{Name: "tag"}))
+
+	err = store.Batch([]storage.Operation{
+		storage.PutOperation("key2", []byte("value2"), storage.Tag{Name: "tag", Value: "a"}),
+		storage.DeleteOperation("key1"),
+		storage.PutOperation("key3", []byte("value3"), storage.Tag{Name: "tag", Value: "a"}),
+		storage.PutOperation("key3", []byte("value3-2"), storage.Tag{Name: "tag", Value: "b"}),
+	})
+	require.NoError(t, err)
+
+	_, err = store.Get("key1")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+	value, err := store.Get("key3")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value3-2"), value)
+
+	queryKeys := func(expression string) []string {
+		itr, err := store.Query(expression)
+		require.NoError(t, err)
+
+		defer itr.Release()
+
+		var keys []string
+
+		for itr.Next() {
+			keys = append(keys, string(itr.Key()))
+		}
+
+		require.NoError(t, itr.Error())
+
+		return keys
+	}
+
+	require.Equal(t, []string{"key2", "key3"}, queryKeys("tag"))
+	require.Equal(t, []string{"key2"}, queryKeys("tag:a"))
+	require.Equal(t, []string{"key3"}, queryKeys("tag:b"))
+
+	// no operation is applied if one of them is not valid
+	err = store.Batch([]storage.Operation{
+		storage.PutOperation("key4", []byte("value4")),
+		storage.PutOperation("", []byte("value")),
+	})
+	require.Error(t, err)
+
+	_, err = store.Get("key4")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+	err = store.Batch([]storage.Operation{
+		storage.PutOperation("key4", []byte("value4")),
+		storage.PutOperation("key5", nil),
+	})
+	require.EqualError(t, err, "key and value are mandatory")
+
+	_, err = store.Get("key4")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+}
+
+func TestLevelDBStorePutWithTTL(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path, WithSweepInterval(10*time.Millisecond))
+
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	expiringStore, ok := store.(storage.ExpiringStore)
+	require.True(t, ok)
+
+	require.NoError(t, expiringStore.PutWithTTL("expiring", []byte("value"), 20*time.Millisecond,
+		storage.Tag{Name: "tag"}))
+	require.NoError(t, expiringStore.PutWithTTL("persisted", []byte("value"), 20*time.Millisecond))
+	require.NoError(t, expiringStore.PutWithTTL("deleted", []byte("value"), 20*time.Millisecond))
+	require.NoError(t, expiringStore.PutWithTTL("batch", []byte("value"), 20*time.Millisecond))
+	require.NoError(t, expiringStore.PutWithTTL("renewed", []byte("value"), 20*time.Millisecond))
+	require.NoError(t, expiringStore.PutWithTTL("long", []byte("value"), time.Hour))
+	require.NoError(t, expiringStore.BatchWithTTL([]storage.Operation{
+		storage.PutOperation("batch-expiring", []byte("value"), storage.Tag{Name: "tag"}),
+		storage.DeleteOperation("unknown"),
+	}, 20*time.Millisecond))
+
+	// Put, Delete and Batch remove the expiry of the records, PutWithTTL replaces it
+	require.NoError(t, store.Put("persisted", []byte("new value")))
+	require.NoError(t, store.Delete("deleted"))
+	require.NoError(t, store.Batch([]storage.Operation{storage.PutOperation("batch", []byte("new value"))}))
+	require.NoError(t, expiringStore.PutWithTTL("renewed", []byte("new value"), time.Hour))
+
+	value, err := store.Get("expiring")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), value)
+
+	require.Eventually(t, func() bool {
+		_, err = store.Get("expiring")
+		_, errBatch := store.Get("batch-expiring")
+
+		return errors.Is(err, storage.ErrDataNotFound) && errors.Is(errBatch, storage.ErrDataNotFound)
+	}, time.Second, 10*time.Millisecond)
+
+	itr, err := store.Query("tag")
+	require.NoError(t, err)
+	require.False(t, itr.Next())
+	itr.Release()
+
+	for _, k := range []string{"persisted", "batch", "renewed", "long"} {
+		_, err = store.Get(k)
+		require.NoError(t, err, k)
+	}
+
+	// the index entries of the swept records are deleted, only the one of "long" and "renewed" remain
+	itr = store.Iterator(expiryIndexKeyPrefix, expiryIndexKeyPrefix+"\xff")
+	verifyItr(t, itr, 2, "")
+
+	require.Error(t, expiringStore.PutWithTTL("", []byte("value"), time.Hour))
+	require.Error(t, expiringStore.PutWithTTL("key", nil, time.Hour))
+	require.Error(t, expiringStore.BatchWithTTL([]storage.Operation{storage.PutOperation("", []byte("value"))},
+		time.Hour))
+	require.Error(t, expiringStore.BatchWithTTL([]storage.Operation{storage.PutOperation("key", nil)}, time.Hour))
+
+	// the sweeper is stopped on close and restarted by the next record with a TTL
+	require.NoError(t, prov.Close())
+	require.Nil(t, prov.stopSweeper)
+
+	err = expiringStore.PutWithTTL("closed", []byte("value"), time.Hour)
+	require.True(t, errors.Is(err, leveldb.ErrClosed))
+	require.Error(t, expiringStore.(*leveldbStore).deleteExpired(time.Now()))
+
+	store, err = prov.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, storage.PutWithTTL(store, "expiring", []byte("value"), 20*time.Millisecond))
+	require.NotNil(t, prov.stopSweeper)
+
+	require.Eventually(t, func() bool {
+		_, err = store.Get("expiring")
+
+		return errors.Is(err, storage.ErrDataNotFound)
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, prov.Close())
+}
+
+func TestLevelDBStoreConformance(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path)
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	storagetest.TestAll(t, prov)
+}
@@ -8,24 +8,43 @@ package mem
 
 import (
 	"errors"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
-// TODO https://github.com/hyperledger/aries-framework-go/issues/750 - we will need to consider
-//  automatic eviction based on TTL.
+const defaultSweepInterval = time.Minute
 
 // Provider leveldb implementation of storage.Provider interface
 type Provider struct {
-	dbs  map[string]*memStore
-	lock sync.RWMutex
+	dbs           map[string]*memStore
+	lock          sync.RWMutex
+	sweepInterval time.Duration
+	stopSweeper   chan struct{}
+}
+
+// Option configures the mem provider.
+type Option func(p *Provider)
+
+// WithSweepInterval sets the interval of the sweeps deleting the expired records, one minute by default.
+func WithSweepInterval(interval time.Duration) Option {
+	return func(p *Provider) {
+		p.sweepInterval = interval
+	}
 }
 
 // NewProvider instantiates Provider
-func NewProvider() *Provider {
-	return &Provider{dbs: make(map[string]*memStore)}
+func NewProvider(opts ...Option) *Provider {
+	p := &Provider{dbs: make(map[string]*memStore), sweepInterval: defaultSweepInterval}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 // OpenStore opens and returns a store for given name space.
@@ -53,7 +72,12 @@ func (p *Provider) newMemStore(name string) *memStore {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
-	store := &memStore{db: make(map[string][]byte)}
+	store := &memStore{
+		db:       make(map[string][]byte),
+		tags:     make(map[string][]storage.Tag),
+		expiry:   make(map[string]time.Time),
+		provider: p,
+	}
 	p.dbs[strings.ToLower(name)] = store
 
 	return store
@@ -65,11 +89,16 @@ func (p *Provider) Close() error {
 	defer p.lock.Unlock()
 
 	for _, memStore := range p.dbs {
-		memStore.db = make(map[string][]byte)
+		memStore.clear()
 	}
 
 	p.dbs = make(map[string]*memStore)
 
+	if p.stopSweeper != nil {
+		close(p.stopSweeper)
+		p.stopSweeper = nil
+	}
+
 	return nil
 }
 
@@ -84,34 +113,121 @@ func (p *Provider) CloseStore(name string) error {
 	if ok {
 		delete(p.dbs, k)
 
-		memStore.db = make(map[string][]byte)
+		memStore.clear()
 	}
 
 	return nil
 }
 
+// startSweeper starts the background sweep of the expired records of the stores, if not started yet.
+func (p *Provider) startSweeper() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.stopSweeper != nil {
+		return
+	}
+
+	p.stopSweeper = make(chan struct{})
+
+	go p.sweep(p.stopSweeper)
+}
+
+// sweep deletes the expired records of the stores every sweep interval, until stop is closed.
+func (p *Provider) sweep(stop chan struct{}) {
+	ticker := time.NewTicker(p.sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			p.lock.RLock()
+
+			stores := make([]*memStore, 0, len(p.dbs))
+			for _, store := range p.dbs {
+				stores = append(stores, store)
+			}
+
+			p.lock.RUnlock()
+
+			for _, store := range stores {
+				store.deleteExpired(now)
+			}
+		}
+	}
+}
+
 type memStore struct {
-	db map[string][]byte
+	db       map[string][]byte
+	tags     map[string][]storage.Tag
+	expiry   map[string]time.Time
+	provider *Provider
 	sync.RWMutex
 }
 
-// Put stores the key and the record
-func (s *memStore) Put(k string, v []byte) error {
+// Put stores the key and the record with its tags
+func (s *memStore) Put(k string, v []byte, tags ...storage.Tag) error {
 	if k == "" || v == nil {
 		return errors.New("key and value are mandatory")
 	}
 
+	s.put(k, v, tags, time.Time{})
+
+	return nil
+}
+
+// PutWithTTL stores the key and the record with its tags, the record is deleted by the sweep following its expiry.
+func (s *memStore) PutWithTTL(k string, v []byte, ttl time.Duration, tags ...storage.Tag) error {
+	if k == "" || v == nil {
+		return errors.New("key and value are mandatory")
+	}
+
+	s.put(k, v, tags, time.Now().Add(ttl))
+	s.provider.startSweeper()
+
+	return nil
+}
+
+// put stores the record, it never expires if expiry is zero.
+func (s *memStore) put(k string, v []byte, tags []storage.Tag, expiry time.Time) {
 	s.Lock()
+	defer s.Unlock()
+
 	s.db[k] = v
-	s.Unlock()
 
-	return nil
+	if len(tags) > 0 {
+		s.tags[k] = tags
+	} else {
+		delete(s.tags, k)
+	}
+
+	if expiry.IsZero() {
+		delete(s.expiry, k)
+	} else {
+		s.expiry[k] = expiry
+	}
+}
+
+// deleteExpired deletes the records expired at now.
+func (s *memStore) deleteExpired(now time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	for k, expiry := range s.expiry {
+		if !expiry.After(now) {
+			delete(s.db, k)
+			delete(s.tags, k)
+			delete(s.expiry, k)
+		}
+	}
 }
 
 // Get fetches the record based on key
 func (s *memStore) Get(k string) ([]byte, error) {
 	if k == "" {
-		return nil, errors.New("key is mandatory")
+		return nil, storage.ErrKeyRequired
 	}
 
 	s.RLock()
@@ -125,37 +241,144 @@ func (s *memStore) Get(k string) ([]byte, error) {
 	return data, nil
 }
 
-// Iterator returns iterator for the latest snapshot of the underlying db.
+// Iterator returns iterator over the records in the range [start, limit) sorted by key, for the latest snapshot of
+// the underlying db.
 func (s *memStore) Iterator(start, limit string) storage.StoreIterator {
-	// TODO Change Store Iterator https://github.com/hyperledger/aries-framework-go/issues/852
+	limit = strings.ReplaceAll(limit, storage.EndKeySuffix, "~")
+
 	s.RLock()
-	data := s.db
 	defer s.RUnlock()
 
-	var batch [][]string
+	var keys []string
 
-	for k, v := range data {
-		if strings.HasPrefix(k, start) {
-			batch = append(batch, []string{k, string(v)})
+	for k := range s.db {
+		if k >= start && k < limit {
+			keys = append(keys, k)
 		}
 	}
 
+	sort.Strings(keys)
+
+	batch := make([][]string, len(keys))
+
+	for i, k := range keys {
+		batch[i] = []string{k, string(s.db[k])}
+	}
+
 	return newMemIterator(batch)
 }
 
 // Delete will delete record with k key
 func (s *memStore) Delete(k string) error {
 	if k == "" {
-		return errors.New("key is mandatory")
+		return storage.ErrKeyRequired
 	}
 
 	s.Lock()
 	delete(s.db, k)
+	delete(s.tags, k)
+	delete(s.expiry, k)
 	s.Unlock()
 
 	return nil
 }
 
+// Query returns iterator over the records having a tag matching the expression, sorted by key.
+func (s *memStore) Query(expression string, _ ...storage.QueryOption) (storage.StoreIterator, error) {
+	query, err := storage.ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	var keys []string
+
+	for k, tags := range s.tags {
+		if query.Match(tags) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	batch := make([][]string, len(keys))
+
+	for i, k := range keys {
+		batch[i] = []string{k, string(s.db[k])}
+	}
+
+	return newMemIterator(batch), nil
+}
+
+// Batch applies the operations atomically, under the lock of the store.
+func (s *memStore) Batch(operations []storage.Operation) error {
+	return s.batch(operations, time.Time{})
+}
+
+// BatchWithTTL applies the operations atomically like Batch, the records stored are deleted by the sweep following
+// their expiry.
+func (s *memStore) BatchWithTTL(operations []storage.Operation, ttl time.Duration) error {
+	err := s.batch(operations, time.Now().Add(ttl))
+	if err != nil {
+		return err
+	}
+
+	s.provider.startSweeper()
+
+	return nil
+}
+
+// batch applies the operations, the records stored never expire if expiry is zero.
+func (s *memStore) batch(operations []storage.Operation, expiry time.Time) error {
+	for _, op := range operations {
+		if op.Key == "" {
+			return storage.ErrKeyRequired
+		}
+
+		if !op.IsDelete() && op.Value == nil {
+			return errors.New("key and value are mandatory")
+		}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	for _, op := range operations {
+		delete(s.expiry, op.Key)
+
+		if op.IsDelete() {
+			delete(s.db, op.Key)
+			delete(s.tags, op.Key)
+
+			continue
+		}
+
+		s.db[op.Key] = op.Value
+
+		if len(op.Tags) > 0 {
+			s.tags[op.Key] = op.Tags
+		} else {
+			delete(s.tags, op.Key)
+		}
+
+		if !expiry.IsZero() {
+			s.expiry[op.Key] = expiry
+		}
+	}
+
+	return nil
+}
+
+func (s *memStore) clear() {
+	s.Lock()
+	s.db = make(map[string][]byte)
+	s.tags = make(map[string][]storage.Tag)
+	s.expiry = make(map[string]time.Time)
+	s.Unlock()
+}
+
 type memIterator struct {
 	currentIndex int
 	currentItem  []string
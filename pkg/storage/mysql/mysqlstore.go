@@ -27,8 +27,9 @@ type Provider struct {
 }
 
 type sqlDBStore struct {
-	db        *sql.DB
-	tableName string
+	db            *sql.DB
+	tableName     string
+	tagsTableName string
 }
 
 type result struct {
@@ -40,6 +41,7 @@ const (
 	blankDBPathErrMsg         = "DB URL for new mySQL DB provider can't be blank"
 	failToCloseProviderErrMsg = "failed to close provider"
 	tablePrefix               = "t_"
+	tagsTableSuffix           = "_tags"
 	sqlDBNotFound             = "no rows"
 	createDBQuery             = "CREATE DATABASE IF NOT EXISTS "
 	useDBQuery                = "USE "
@@ -78,35 +80,82 @@ func (p *Provider) OpenStore(name string) (storage.Store, error) {
 	// creating the database
 	_, err = tx.Exec(createDBQuery + name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create db %s: %w", name, err)
+		return nil, rollback(tx, fmt.Errorf("failed to create db %s: %w", name, err))
 	}
 
 	// Use is used to select the created database without this DDL operations are not permitted
 	_, err = tx.Exec(useDBQuery + name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to use db %s: %w", name, err)
+		return nil, rollback(tx, fmt.Errorf("failed to use db %s: %w", name, err))
 	}
 
 	tableName := tablePrefix + name
 	// TODO: Issue-1940 Store the hashed key to control the width of the key varchar column
 	createTableStmt := "CREATE Table IF NOT EXISTS " + tableName +
-		"(`key` varchar(255) NOT NULL ,`value` BLOB, PRIMARY KEY (`key`));"
+		"(`key` varchar(255) NOT NULL ,`value` MEDIUMBLOB, PRIMARY KEY (`key`));"
 
 	// creating key-value table inside the database
 	_, err = tx.Exec(createTableStmt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create table %s: %w", tableName, err)
+		return nil, rollback(tx, fmt.Errorf("failed to create table %s: %w", tableName, err))
+	}
+
+	err = migrateValueColumn(tx, name, tableName)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+
+	tagsTableName := tableName + tagsTableSuffix
+	createTagsTableStmt := "CREATE Table IF NOT EXISTS " + tagsTableName +
+		"(`key` varchar(255) NOT NULL ,`name` varchar(255) NOT NULL ,`value` varchar(255) NOT NULL DEFAULT '', " +
+		"PRIMARY KEY (`key`, `name`, `value`), INDEX (`name`, `value`));"
+
+	// creating tags table of the key-value table, used by the queries
+	_, err = tx.Exec(createTagsTableStmt)
+	if err != nil {
+		return nil, rollback(tx, fmt.Errorf("failed to create table %s: %w", tagsTableName, err))
+	}
+
+	// the transaction holds a connection of the pool until it ends
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	store := &sqlDBStore{
-		db:        p.db,
-		tableName: tableName}
+		db:            p.db,
+		tableName:     tableName,
+		tagsTableName: tagsTableName}
 
 	p.dbs[name] = store
 
 	return store, nil
 }
 
+// migrateValueColumn widens the value column of the tables created with a BLOB value column, limited to 64KiB, to
+// MEDIUMBLOB.
+func migrateValueColumn(tx *sql.Tx, dbName, tableName string) error {
+	var dataType string
+
+	err := tx.QueryRow("SELECT DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? "+
+		"AND COLUMN_NAME = 'value'", dbName, tableName).Scan(&dataType)
+	if err != nil {
+		return fmt.Errorf("failed to get value column type of table %s: %w", tableName, err)
+	}
+
+	if !strings.EqualFold(dataType, "blob") {
+		return nil
+	}
+
+	//nolint: gosec
+	_, err = tx.Exec("ALTER TABLE " + tableName + " MODIFY `value` MEDIUMBLOB")
+	if err != nil {
+		return fmt.Errorf("failed to migrate value column of table %s: %w", tableName, err)
+	}
+
+	return nil
+}
+
 // Close closes the provider.
 func (p *Provider) Close() error {
 	p.Lock()
@@ -143,24 +192,104 @@ func (p *Provider) CloseStore(name string) error {
 	return store.db.Close()
 }
 
-// Put stores the key and the record
-func (s *sqlDBStore) Put(k string, v []byte) error {
+// Put stores the key and the record with its tags, the previous tags of the record are replaced in the same
+// transaction.
+func (s *sqlDBStore) Put(k string, v []byte, tags ...storage.Tag) error {
 	if k == "" {
 		return storage.ErrKeyRequired
 	}
 
+	return s.Batch([]storage.Operation{storage.PutOperation(k, v, tags...)})
+}
+
+// Batch applies the operations in a SQL transaction.
+func (s *sqlDBStore) Batch(operations []storage.Operation) error {
+	for _, op := range operations {
+		if op.Key == "" {
+			return storage.ErrKeyRequired
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	for _, op := range operations {
+		if op.IsDelete() {
+			err = s.deleteTx(tx, op.Key)
+		} else {
+			err = s.putTx(tx, op.Key, op.Value, op.Tags)
+		}
+
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqlDBStore) putTx(tx *sql.Tx, k string, v []byte, tags []storage.Tag) error {
 	//nolint: gosec
 	// create upsert query to insert the record, checking whether the key is already mapped to a value in the store.
 	createStmt := "INSERT INTO " + s.tableName + " VALUES (?, ?) ON DUPLICATE KEY UPDATE value=?"
 	// executing the prepared insert statement
-	_, err := s.db.Exec(createStmt, k, v, v)
+	_, err := tx.Exec(createStmt, k, v, v)
 	if err != nil {
 		return fmt.Errorf("failed to insert key and value record into %s %w ", s.tableName, err)
 	}
 
+	//nolint: gosec
+	_, err = tx.Exec("DELETE FROM "+s.tagsTableName+" WHERE `key`= ?", k)
+	if err != nil {
+		return fmt.Errorf("failed to delete tags from %s: %w", s.tagsTableName, err)
+	}
+
+	for _, tag := range tags {
+		//nolint: gosec
+		// a tag given twice is stored once
+		_, err = tx.Exec("INSERT INTO "+s.tagsTableName+" VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `key` = `key`",
+			k, tag.Name, tag.Value)
+		if err != nil {
+			return fmt.Errorf("failed to insert tag into %s: %w", s.tagsTableName, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *sqlDBStore) deleteTx(tx *sql.Tx, k string) error {
+	//nolint: gosec
+	_, err := tx.Exec("DELETE FROM "+s.tagsTableName+" WHERE `key`= ?", k)
+	if err != nil {
+		return fmt.Errorf("failed to delete tags %w", err)
+	}
+
+	//nolint: gosec
+	// delete query to delete the record by key
+	_, err = tx.Exec("DELETE FROM "+s.tableName+" WHERE `key`= ?", k)
+	if err != nil {
+		return fmt.Errorf("failed to delete row %w", err)
+	}
+
 	return nil
 }
 
+// rollback rolls back tx and returns err.
+func rollback(tx *sql.Tx, err error) error {
+	if errRollback := tx.Rollback(); errRollback != nil {
+		return fmt.Errorf("%w (rollback failed: %s)", err, errRollback.Error())
+	}
+
+	return err
+}
+
 // Get fetches the record based on key
 func (s *sqlDBStore) Get(k string) ([]byte, error) {
 	if k == "" {
@@ -188,17 +317,124 @@ func (s *sqlDBStore) Delete(k string) error {
 	if k == "" {
 		return storage.ErrKeyRequired
 	}
+
+	return s.Batch([]storage.Operation{storage.DeleteOperation(k)})
+}
+
+// Query returns iterator over the records having a tag matching the expression, the records are fetched by pages
+// of the page size of the query options.
+func (s *sqlDBStore) Query(expression string, opts ...storage.QueryOption) (storage.StoreIterator, error) {
+	query, err := storage.ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+
 	//nolint: gosec
-	// delete query to delete the record by key
-	_, err := s.db.Exec("DELETE FROM "+s.tableName+" WHERE `key`= ?", k)
+	queryStmt := "SELECT DISTINCT t.`key`, t.`value` FROM " + s.tableName + " t INNER JOIN " + s.tagsTableName +
+		" g ON t.`key` = g.`key` WHERE g.`name` = ?"
+	args := []interface{}{query.Name}
+
+	if !query.AnyValue {
+		queryStmt += " AND g.`value` = ?"
 
+		args = append(args, query.Value)
+	}
+
+	queryStmt += " ORDER BY t.`key` LIMIT ? OFFSET ?"
+
+	i := &sqlDBQueryIterator{
+		store:    s,
+		stmt:     queryStmt,
+		args:     args,
+		pageSize: storage.NewQueryOptions(opts...).PageSize,
+	}
+
+	err = i.fetchPage()
 	if err != nil {
-		return fmt.Errorf("failed to delete row %w", err)
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// sqlDBQueryIterator iterates over the results of a query, page by page.
+type sqlDBQueryIterator struct {
+	store      *sqlDBStore
+	stmt       string
+	args       []interface{}
+	pageSize   int
+	offset     int
+	count      int
+	resultRows *sql.Rows
+	current    result
+	err        error
+}
+
+func (i *sqlDBQueryIterator) fetchPage() error {
+	args := append(append([]interface{}{}, i.args...), i.pageSize, i.offset)
+
+	resultRows, err := i.store.db.Query(i.stmt, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query rows %w", err)
 	}
 
+	i.resultRows = resultRows
+	i.count = 0
+
 	return nil
 }
 
+func (i *sqlDBQueryIterator) Next() bool {
+	i.current = result{}
+
+	for i.err == nil {
+		if i.resultRows.Next() {
+			i.count++
+			i.err = i.resultRows.Scan(&i.current.key, &i.current.value)
+
+			return i.err == nil
+		}
+
+		i.err = i.resultRows.Err()
+
+		// a partial page is the last one
+		if i.err != nil || i.count < i.pageSize {
+			return false
+		}
+
+		i.Release()
+
+		i.offset += i.pageSize
+		i.err = i.fetchPage()
+	}
+
+	return false
+}
+
+func (i *sqlDBQueryIterator) Release() {
+	if err := i.resultRows.Close(); err != nil && i.err == nil {
+		i.err = err
+	}
+}
+
+func (i *sqlDBQueryIterator) Error() error {
+	return i.err
+}
+
+// Key returns the key of the current key-value pair.
+func (i *sqlDBQueryIterator) Key() []byte {
+	if i.current.key == "" {
+		return nil
+	}
+
+	return []byte(i.current.key)
+}
+
+// Value returns the value of the current key-value pair.
+func (i *sqlDBQueryIterator) Value() []byte {
+	return i.current.value
+}
+
 type sqlDBResultsIterator struct {
 	store      *sqlDBStore
 	resultRows *sql.Rows
@@ -6,15 +6,20 @@ SPDX-License-Identifier: Apache-2.0
 package mysql
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/storagetest"
 )
 
 const (
@@ -45,13 +50,24 @@ func TestSQLDBStore(t *testing.T) {
 			sqlmock.NewResult(1, 1))
 		mock.ExpectExec("CREATE Table IF NOT EXISTS t_test ").WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		expectValueColumnType(mock, "mediumblob")
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_test_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO t_test (.+)").WithArgs(key, data, data).WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_test_tags (.+)").WithArgs(key).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 		mock.ExpectQuery("SELECT (.+) FROM t_test (.+)").WithArgs(key).WillReturnRows(
 			sqlmock.NewRows(columns).AddRow(data))
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO t_test (.+)").WithArgs(key, data2, data2).WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_test_tags (.+)").WithArgs(key).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO t_test (.+)").WithArgs(key, data2, data2).WillReturnError(err)
+		mock.ExpectRollback()
 		mock.ExpectQuery("SELECT (.+) FROM t_test (.+)").WithArgs(key).WillReturnRows(
 			sqlmock.NewRows(columns).AddRow(data2))
 		mock.ExpectQuery("SELECT (.+) FROM t_test (.+)").WithArgs(did2).WillReturnError(
@@ -136,6 +152,9 @@ func TestSQLStoreMultiGetAndPut(t *testing.T) {
 		mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store1 ").WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		expectValueColumnType(mock, "mediumblob")
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store1_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		// store 2
 		mock.ExpectBegin()
@@ -144,14 +163,23 @@ func TestSQLStoreMultiGetAndPut(t *testing.T) {
 		mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store2 ").WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		expectValueColumnType(mock, "mediumblob")
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store2_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO t_store1 (.+)").WithArgs(commonKey, data, data).WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_store1_tags (.+)").WithArgs(commonKey).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 		mock.ExpectQuery("SELECT (.+) FROM t_store1 (.+)").WithArgs(commonKey).WillReturnRows(
 			sqlmock.NewRows(columns).AddRow(data))
 		mock.ExpectQuery("SELECT (.+) FROM t_store2 (.+)").WithArgs(commonKey).WillReturnError(
 			storage.ErrDataNotFound)
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO t_store2 (.+)").WithArgs(commonKey, data, data).WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_store2_tags (.+)").WithArgs(commonKey).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 		mock.ExpectQuery("SELECT (.+) FROM t_store2 (.+)").WithArgs(commonKey).WillReturnRows(
 			sqlmock.NewRows(columns).AddRow(data))
 		// recreate store1
@@ -161,6 +189,9 @@ func TestSQLStoreMultiGetAndPut(t *testing.T) {
 		mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store1 ").WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		expectValueColumnType(mock, "mediumblob")
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store1_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 		mock.ExpectQuery("SELECT (.+) FROM t_store1 (.+)").WithArgs(commonKey).WillReturnRows(
 			sqlmock.NewRows(columns).AddRow(data))
 		mock.ExpectQuery("SELECT (.+) FROM t_store1 (.+)").WithArgs(commonKey).WillReturnError(err)
@@ -247,6 +278,7 @@ func TestSQLStoreFailure(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectExec("CREATE DATABASE IF NOT EXISTS").WillReturnError(
 			fmt.Errorf("failed to create db %s: %w", "sample", err))
+		mock.ExpectRollback()
 
 		// sample 2
 		mock.ExpectBegin()
@@ -254,6 +286,7 @@ func TestSQLStoreFailure(t *testing.T) {
 			sqlmock.NewResult(1, 1))
 		mock.ExpectExec("USE ").WillReturnError(
 			fmt.Errorf("failed to use db %s: %w", "sample2", err))
+		mock.ExpectRollback()
 
 		prov, err = NewProvider(sqlStoreDBURL)
 		require.NoError(t, err)
@@ -298,8 +331,14 @@ func TestSQLStoreMultiStore(t *testing.T) {
 		mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store_1 ").WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		expectValueColumnType(mock, "mediumblob")
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store_1_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO t_store_1 (.+)").WithArgs(commonKey, data, data).WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_store_1_tags (.+)").WithArgs(commonKey).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 		mock.ExpectQuery("SELECT (.+) FROM t_store_1 (.+)").WithArgs(commonKey).WillReturnRows(
 			sqlmock.NewRows(columns).AddRow(data))
 
@@ -310,8 +349,14 @@ func TestSQLStoreMultiStore(t *testing.T) {
 		mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store_2 ").WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		expectValueColumnType(mock, "mediumblob")
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store_2_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO t_store_2 (.+)").WithArgs(commonKey, data, data).WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_store_2_tags (.+)").WithArgs(commonKey).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 		mock.ExpectQuery("SELECT (.+) FROM t_store_2 (.+)").WithArgs(commonKey).WillReturnRows(
 			sqlmock.NewRows(columns).AddRow(data))
 
@@ -322,8 +367,14 @@ func TestSQLStoreMultiStore(t *testing.T) {
 		mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store_3 ").WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		expectValueColumnType(mock, "mediumblob")
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store_3_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO t_store_3 (.+)").WithArgs(commonKey, data, data).WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_store_3_tags (.+)").WithArgs(commonKey).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 		mock.ExpectQuery("SELECT (.+) FROM t_store_3 (.+)").WithArgs(commonKey).WillReturnRows(
 			sqlmock.NewRows(columns).AddRow(data))
 
@@ -334,8 +385,14 @@ func TestSQLStoreMultiStore(t *testing.T) {
 		mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store_4 ").WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		expectValueColumnType(mock, "mediumblob")
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store_4_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO t_store_4 (.+)").WithArgs(commonKey, data, data).WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_store_4_tags (.+)").WithArgs(commonKey).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 		mock.ExpectQuery("SELECT (.+) FROM t_store_4 (.+)").WithArgs(commonKey).WillReturnRows(
 			sqlmock.NewRows(columns).AddRow(data))
 
@@ -346,8 +403,14 @@ func TestSQLStoreMultiStore(t *testing.T) {
 		mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store_5 ").WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		expectValueColumnType(mock, "mediumblob")
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_store_5_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO t_store_5 (.+)").WithArgs(commonKey, data, data).WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_store_5_tags (.+)").WithArgs(commonKey).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 		mock.ExpectQuery("SELECT (.+) FROM t_store_5 (.+)").WithArgs(commonKey).WillReturnRows(
 			sqlmock.NewRows(columns).AddRow(data))
 		mock.ExpectClose()
@@ -424,15 +487,27 @@ func TestSQLDBStoreDelete(t *testing.T) {
 	mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectExec("CREATE Table IF NOT EXISTS t_store1").WillReturnResult(
 		sqlmock.NewResult(1, 1))
+	expectValueColumnType(mock, "mediumblob")
+	mock.ExpectExec("CREATE Table IF NOT EXISTS t_store1_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO t_store1 (.+)").WithArgs(commonKey, data, data).WillReturnResult(
 		sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM t_store1_tags (.+)").WithArgs(commonKey).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 	mock.ExpectQuery("SELECT (.+) FROM t_store1 (.+)").WithArgs(commonKey).WillReturnRows(
 		sqlmock.NewRows(columns).AddRow(data))
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM t_store1_tags (.+)").WithArgs(commonKey).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectExec("DELETE FROM t_store1 (.+)").WithArgs(commonKey).WillReturnResult(
 		sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 	mock.ExpectQuery("SELECT (.+) FROM t_store1 (.+)").WithArgs(commonKey).WillReturnError(
 		storage.ErrDataNotFound)
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM t_store1_tags (.+)").WithArgs(commonKey).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectExec("DELETE FROM t_store1 (.+)").WithArgs(commonKey).WillReturnError(err)
+	mock.ExpectRollback()
 
 	prov, err := NewProvider(sqlStoreDBURL)
 	require.NoError(t, err)
@@ -490,6 +565,9 @@ func TestSQLDBStoreIterator(t *testing.T) {
 		mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectExec("CREATE Table IF NOT EXISTS t_testIterator").WillReturnResult(
 			sqlmock.NewResult(1, 1))
+		expectValueColumnType(mock, "mediumblob")
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_testIterator_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		prov, err := NewProvider(sqlStoreDBURL)
 		require.NoError(t, err)
@@ -501,9 +579,12 @@ func TestSQLDBStoreIterator(t *testing.T) {
 		keys := []string{"abc_123", "abc_124", "abc_125", "abc_126", "jkl_123", "mno_123"}
 
 		for _, key := range keys {
+			mock.ExpectBegin()
 			mock.ExpectExec("INSERT INTO t_testIterator (.+)").WithArgs(key,
 				[]byte(fmt.Sprintf(valPrefix, key)),
 				[]byte(fmt.Sprintf(valPrefix, key))).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec("DELETE FROM t_testIterator_tags (.+)").WithArgs(key).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
 			err = store.Put(key, []byte(fmt.Sprintf(valPrefix, key)))
 			require.NoError(t, err)
 		}
@@ -585,3 +666,306 @@ func verifyItr(t *testing.T, itr storage.StoreIterator, count int, prefix string
 	require.Error(t, itr.Error())
 	require.Contains(t, itr.Error().Error(), "sql: Rows are closed")
 }
+
+func TestSQLDBStoreQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	columns := []string{"`key`", "`value`"}
+	data := []byte("value")
+	tag := storage.Tag{Name: "type", Value: "a"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE DATABASE IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE Table IF NOT EXISTS t_testQuery").WillReturnResult(sqlmock.NewResult(1, 1))
+	expectValueColumnType(mock, "mediumblob")
+	mock.ExpectExec("CREATE Table IF NOT EXISTS t_testQuery_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	prov, err := NewProvider(sqlStoreDBURL)
+	require.NoError(t, err)
+
+	prov.db = db
+	store, err := prov.OpenStore("testQuery")
+	require.NoError(t, err)
+
+	t.Run("put with tags", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO t_testQuery (.+)").WithArgs("key1", data, data).WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_testQuery_tags (.+)").WithArgs("key1").WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO t_testQuery_tags (.+)").WithArgs("key1", "type", "a").WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, store.Put("key1", data, tag))
+	})
+
+	t.Run("put tag failure", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO t_testQuery (.+)").WithArgs("key1", data, data).WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_testQuery_tags (.+)").WithArgs("key1").WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO t_testQuery_tags (.+)").WithArgs("key1", "type", "a").WillReturnError(
+			fmt.Errorf("insert error"))
+		mock.ExpectRollback()
+
+		err := store.Put("key1", data, tag)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to insert tag")
+	})
+
+	t.Run("query by pages", func(t *testing.T) {
+		mock.ExpectQuery("SELECT DISTINCT (.+) INNER JOIN t_testQuery_tags (.+)").WithArgs("type", "a", 2, 0).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("key1", data).AddRow("key2", data))
+		mock.ExpectQuery("SELECT DISTINCT (.+) INNER JOIN t_testQuery_tags (.+)").WithArgs("type", "a", 2, 2).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("key3", data))
+
+		itr, err := store.Query("type:a", storage.WithPageSize(2))
+		require.NoError(t, err)
+
+		var keys []string
+
+		for itr.Next() {
+			require.Equal(t, data, itr.Value())
+
+			keys = append(keys, string(itr.Key()))
+		}
+
+		require.NoError(t, itr.Error())
+		require.Equal(t, []string{"key1", "key2", "key3"}, keys)
+
+		itr.Release()
+	})
+
+	t.Run("query any value", func(t *testing.T) {
+		mock.ExpectQuery("SELECT DISTINCT (.+) INNER JOIN t_testQuery_tags (.+)").
+			WithArgs("type", storage.DefaultPageSize, 0).WillReturnRows(sqlmock.NewRows(columns))
+
+		itr, err := store.Query("type")
+		require.NoError(t, err)
+		require.False(t, itr.Next())
+		require.NoError(t, itr.Error())
+
+		itr.Release()
+	})
+
+	t.Run("query failures", func(t *testing.T) {
+		_, err := store.Query("")
+		require.True(t, errors.Is(err, storage.ErrInvalidQuery))
+
+		mock.ExpectQuery("SELECT DISTINCT (.+)").WithArgs("type", storage.DefaultPageSize, 0).
+			WillReturnError(fmt.Errorf("query error"))
+
+		_, err = store.Query("type")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to query rows")
+	})
+
+	// we make sure that all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSQLDBStoreBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := []byte("value")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE DATABASE IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE Table IF NOT EXISTS t_testBatch").WillReturnResult(sqlmock.NewResult(1, 1))
+	expectValueColumnType(mock, "mediumblob")
+	mock.ExpectExec("CREATE Table IF NOT EXISTS t_testBatch_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	prov, err := NewProvider(sqlStoreDBURL)
+	require.NoError(t, err)
+
+	prov.db = db
+	store, err := prov.OpenStore("testBatch")
+	require.NoError(t, err)
+
+	t.Run("batch success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO t_testBatch (.+)").WithArgs("key1", data, data).WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_testBatch_tags (.+)").WithArgs("key1").WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO t_testBatch_tags (.+) ON DUPLICATE KEY UPDATE").
+			WithArgs("key1", "type", "a").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_testBatch_tags (.+)").WithArgs("key2").WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_testBatch (.+)").WithArgs("key2").WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err := store.Batch([]storage.Operation{
+			storage.PutOperation("key1", data, storage.Tag{Name: "type", Value: "a"}),
+			storage.DeleteOperation("key2"),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("batch failure is rolled back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO t_testBatch (.+)").WithArgs("key1", data, data).WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_testBatch_tags (.+)").WithArgs("key1").WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_testBatch_tags (.+)").WithArgs("key2").WillReturnError(
+			fmt.Errorf("delete error"))
+		mock.ExpectRollback()
+
+		err := store.Batch([]storage.Operation{
+			storage.PutOperation("key1", data),
+			storage.DeleteOperation("key2"),
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "delete error")
+	})
+
+	t.Run("batch with empty key", func(t *testing.T) {
+		err := store.Batch([]storage.Operation{storage.PutOperation("", data)})
+		require.True(t, errors.Is(err, storage.ErrKeyRequired))
+	})
+
+	t.Run("batch begin failure", func(t *testing.T) {
+		mock.ExpectBegin().WillReturnError(fmt.Errorf("begin error"))
+
+		err := store.Batch([]storage.Operation{storage.PutOperation("key1", data)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to begin transaction")
+	})
+
+	t.Run("batch commit failure", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM t_testBatch_tags (.+)").WithArgs("key1").WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectExec("DELETE FROM t_testBatch (.+)").WithArgs("key1").WillReturnResult(
+			sqlmock.NewResult(1, 1))
+		mock.ExpectCommit().WillReturnError(fmt.Errorf("commit error"))
+
+		err := store.Batch([]storage.Operation{storage.DeleteOperation("key1")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to commit transaction")
+	})
+
+	// we make sure that all expectations were met
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSQLDBStoreConformance(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := prov.db.PingContext(ctx); err != nil {
+		t.Skipf("MySQL instance not available at %s: %s", sqlStoreDBURL, err)
+	}
+
+	storagetest.TestAll(t, prov)
+}
+
+func TestSQLDBStoreValueColumnMigration(t *testing.T) {
+	openStore := func(t *testing.T, db *sql.DB) (storage.Store, error) {
+		t.Helper()
+
+		prov, err := NewProvider(sqlStoreDBURL)
+		require.NoError(t, err)
+
+		prov.db = db
+
+		return prov.OpenStore("migrated")
+	}
+
+	expectCreateTable := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE DATABASE IF NOT EXISTS").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("USE ").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_migrated ").WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	t.Run("BLOB value column is widened to MEDIUMBLOB", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+
+		expectCreateTable(mock)
+		mock.ExpectQuery("SELECT DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS").WithArgs("migrated", "t_migrated").
+			WillReturnRows(sqlmock.NewRows([]string{"DATA_TYPE"}).AddRow("blob"))
+		mock.ExpectExec("ALTER TABLE t_migrated MODIFY `value` MEDIUMBLOB").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_migrated_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		store, err := openStore(t, db)
+		require.NoError(t, err)
+		require.NotNil(t, store)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("MEDIUMBLOB value column is left as is", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+
+		expectCreateTable(mock)
+		expectValueColumnType(mock, "mediumblob")
+		mock.ExpectExec("CREATE Table IF NOT EXISTS t_migrated_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		_, err = openStore(t, db)
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("value column type query failure", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+
+		expectCreateTable(mock)
+		mock.ExpectQuery("SELECT DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS").WillReturnError(fmt.Errorf("query error"))
+		mock.ExpectRollback()
+
+		_, err = openStore(t, db)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get value column type of table t_migrated")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("alter table failure", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+
+		expectCreateTable(mock)
+		expectValueColumnType(mock, "blob")
+		mock.ExpectExec("ALTER TABLE t_migrated").WillReturnError(fmt.Errorf("alter error"))
+		mock.ExpectRollback()
+
+		_, err = openStore(t, db)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to migrate value column of table t_migrated")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func expectValueColumnType(mock sqlmock.Sqlmock, dataType string) {
+	mock.ExpectQuery("SELECT DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS").
+		WillReturnRows(sqlmock.NewRows([]string{"DATA_TYPE"}).AddRow(dataType))
+}
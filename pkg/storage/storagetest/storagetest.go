@@ -0,0 +1,585 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package storagetest is a conformance test suite of the storage.Provider contract, to be run by the tests of the
+// storage providers:
+//
+//	func TestProvider(t *testing.T) {
+//		storagetest.TestAll(t, NewProvider())
+//	}
+//
+// The tests open stores with names prefixed by "storagetest" and don't close the provider. Keys and store names are
+// lowercase ASCII, JSON values are compact with sorted fields, so that providers storing them as documents pass.
+package storagetest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// LargeValueSize is the size of the values stored by TestLargeValues.
+	LargeValueSize = 1 << 20
+	// concurrency is the number of goroutines run by the concurrency tests.
+	concurrency = 10
+	// iterations is the number of operations of each goroutine of the concurrency tests.
+	iterations = 20
+)
+
+// TestAll runs all the tests of the suite against the provider.
+func TestAll(t *testing.T, provider storage.Provider) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, provider storage.Provider)
+	}{
+		{"put and get", TestPutGet},
+		{"empty keys", TestEmptyKeys},
+		{"delete", TestDelete},
+		{"stores isolation", TestStoresIsolation},
+		{"iterator", TestIterator},
+		{"query", TestQuery},
+		{"batch", TestBatch},
+		{"large values", TestLargeValues},
+		{"concurrent open store", TestConcurrentOpenStore},
+		{"concurrent operations", TestConcurrentOperations},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			tc.test(t, provider)
+		})
+	}
+}
+
+// TestPutGet tests storing and overwriting records, and reading missing ones.
+func TestPutGet(t *testing.T, provider storage.Provider) {
+	store, err := provider.OpenStore("storagetest_putget")
+	require.NoError(t, err)
+
+	values := map[string][]byte{
+		"did:example:123": []byte("value"),
+		"json":            []byte(`{"field":"value"}`),
+		"empty":           {},
+		"binary":          {0, 1, 2, 0xfe, 0xff},
+	}
+
+	for k, v := range values {
+		require.NoError(t, store.Put(k, v))
+
+		stored, err := store.Get(k)
+		require.NoError(t, err, k)
+		require.Equal(t, string(v), string(stored), k)
+	}
+
+	require.NoError(t, store.Put("did:example:123", []byte("new value")))
+
+	stored, err := store.Get("did:example:123")
+	require.NoError(t, err)
+	require.Equal(t, []byte("new value"), stored)
+
+	_, err = store.Get("did:example:missing")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound), "got %v", err)
+
+	reopened, err := provider.OpenStore("storagetest_putget")
+	require.NoError(t, err)
+
+	stored, err = reopened.Get("json")
+	require.NoError(t, err)
+	require.Equal(t, values["json"], stored)
+}
+
+// TestEmptyKeys tests that the operations of the stores require a key.
+func TestEmptyKeys(t *testing.T, provider storage.Provider) {
+	store, err := provider.OpenStore("storagetest_emptykeys")
+	require.NoError(t, err)
+
+	require.Error(t, store.Put("", []byte("value")))
+
+	_, err = store.Get("")
+	require.True(t, errors.Is(err, storage.ErrKeyRequired), "got %v", err)
+
+	err = store.Delete("")
+	require.True(t, errors.Is(err, storage.ErrKeyRequired), "got %v", err)
+
+	err = store.Batch([]storage.Operation{
+		storage.PutOperation("key", []byte("value")),
+		storage.PutOperation("", []byte("value")),
+	})
+	require.Error(t, err)
+
+	// the operations of a batch are validated before any is applied
+	_, err = store.Get("key")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound), "got %v", err)
+}
+
+// TestDelete tests deleting records, deleting a missing record isn't an error.
+func TestDelete(t *testing.T, provider storage.Provider) {
+	store, err := provider.OpenStore("storagetest_delete")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+	require.NoError(t, store.Put("key2", []byte("value2")))
+
+	require.NoError(t, store.Delete("key1"))
+
+	_, err = store.Get("key1")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound), "got %v", err)
+
+	value, err := store.Get("key2")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), value)
+
+	require.NoError(t, store.Delete("key1"))
+	require.NoError(t, store.Delete("missing"))
+
+	requireRecords(t, store.Iterator("key", "key"+storage.EndKeySuffix), []record{{"key2", "value2"}})
+
+	// a deleted record can be stored again
+	require.NoError(t, store.Put("key1", []byte("value3")))
+
+	value, err = store.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value3"), value)
+}
+
+// TestStoresIsolation tests that the stores of a provider don't share their records.
+func TestStoresIsolation(t *testing.T, provider storage.Provider) {
+	store1, err := provider.OpenStore("storagetest_isolation1")
+	require.NoError(t, err)
+
+	store2, err := provider.OpenStore("storagetest_isolation2")
+	require.NoError(t, err)
+
+	require.NoError(t, store1.Put("key", []byte("value1"), storage.Tag{Name: "tag", Value: "value"}))
+	require.NoError(t, store2.Put("key", []byte("value2"), storage.Tag{Name: "tag", Value: "value"}))
+	require.NoError(t, store1.Put("key1", []byte("value1")))
+
+	value, err := store1.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1"), value)
+
+	value, err = store2.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), value)
+
+	_, err = store2.Get("key1")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound), "got %v", err)
+
+	requireRecords(t, store2.Iterator("key", "key"+storage.EndKeySuffix), []record{{"key", "value2"}})
+
+	itr, err := store2.Query("tag:value")
+	require.NoError(t, err)
+	requireRecords(t, itr, []record{{"key", "value2"}})
+
+	require.NoError(t, store1.Delete("key"))
+
+	value, err = store2.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), value)
+}
+
+// TestIterator tests the key ranges of the iterators, with and without storage.EndKeySuffix.
+func TestIterator(t *testing.T, provider storage.Provider) {
+	store, err := provider.OpenStore("storagetest_iterator")
+	require.NoError(t, err)
+
+	keys := []string{"abc_3", "abc_1", "abd_1", "ab", "abc_2", "b", "a"}
+	for _, k := range keys {
+		require.NoError(t, store.Put(k, []byte("value_"+k)))
+	}
+
+	tests := []struct {
+		name       string
+		start, end string
+		expected   []string
+	}{
+		{"prefix", "abc_", "abc_" + storage.EndKeySuffix, []string{"abc_1", "abc_2", "abc_3"}},
+		{"prefix including the start key", "ab", "ab" + storage.EndKeySuffix,
+			[]string{"ab", "abc_1", "abc_2", "abc_3", "abd_1"}},
+		{"exclusive end key", "abc_1", "abc_3", []string{"abc_1", "abc_2"}},
+		{"start key not stored", "abc_0", "abc_9", []string{"abc_1", "abc_2", "abc_3"}},
+		{"all keys", "a", "c", []string{"a", "ab", "abc_1", "abc_2", "abc_3", "abd_1", "b"}},
+		{"single key", "b", "b" + storage.EndKeySuffix, []string{"b"}},
+		{"no key in range", "abc_4", "abd", nil},
+		{"no key with prefix", "x", "x" + storage.EndKeySuffix, nil},
+		{"empty range", "abc_2", "abc_2", nil},
+	}
+
+	for _, tc := range tests {
+		expected := make([]record, len(tc.expected))
+		for i, k := range tc.expected {
+			expected[i] = record{k, "value_" + k}
+		}
+
+		requireRecords(t, store.Iterator(tc.start, tc.end), expected, tc.name)
+	}
+
+	t.Run("releasing an iterator before its end", func(t *testing.T) {
+		itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("abc_1"), itr.Key())
+		itr.Release()
+		require.NoError(t, itr.Error())
+	})
+
+	t.Run("records updated after the end of an iteration", func(t *testing.T) {
+		require.NoError(t, store.Delete("abc_2"))
+		require.NoError(t, store.Put("abc_4", []byte("value_abc_4")))
+
+		requireRecords(t, store.Iterator("abc_", "abc_"+storage.EndKeySuffix), []record{
+			{"abc_1", "value_abc_1"}, {"abc_3", "value_abc_3"}, {"abc_4", "value_abc_4"},
+		})
+	})
+}
+
+// TestQuery tests querying the records by tag, with and without value, by pages of different sizes.
+func TestQuery(t *testing.T, provider storage.Provider) {
+	store, err := provider.OpenStore("storagetest_query")
+	require.NoError(t, err)
+
+	for i := 0; i < 7; i++ {
+		tags := []storage.Tag{{Name: "type", Value: "even"}}
+		if i%2 == 1 {
+			tags[0].Value = "odd"
+		}
+
+		if i%3 == 0 {
+			tags = append(tags, storage.Tag{Name: "triple"})
+		}
+
+		require.NoError(t, store.Put(fmt.Sprintf("record_%d", i), []byte(fmt.Sprintf("value_%d", i)), tags...))
+	}
+
+	require.NoError(t, store.Put("untagged", []byte("value")))
+
+	records := func(indices ...int) []record {
+		r := make([]record, len(indices))
+		for i, index := range indices {
+			r[i] = record{fmt.Sprintf("record_%d", index), fmt.Sprintf("value_%d", index)}
+		}
+
+		return r
+	}
+
+	tests := []struct {
+		expression string
+		expected   []record
+	}{
+		{"type:even", records(0, 2, 4, 6)},
+		{"type:odd", records(1, 3, 5)},
+		{"type", records(0, 1, 2, 3, 4, 5, 6)},
+		{"triple", records(0, 3, 6)},
+		{"triple:", records(0, 3, 6)},
+		{"type:none", nil},
+		{"missing", nil},
+	}
+
+	for _, pageSize := range []int{1, 2, 3, storage.DefaultPageSize} {
+		for _, tc := range tests {
+			itr, err := store.Query(tc.expression, storage.WithPageSize(pageSize))
+			require.NoError(t, err, tc.expression)
+			requireRecordsInAnyOrder(t, itr, tc.expected, "%s with page size %d", tc.expression, pageSize)
+		}
+	}
+
+	_, err = store.Query("")
+	require.True(t, errors.Is(err, storage.ErrInvalidQuery), "got %v", err)
+
+	_, err = store.Query(":value")
+	require.True(t, errors.Is(err, storage.ErrInvalidQuery), "got %v", err)
+
+	t.Run("tags of updated and deleted records", func(t *testing.T) {
+		require.NoError(t, store.Put("record_0", []byte("new_value_0"), storage.Tag{Name: "type", Value: "odd"}))
+		require.NoError(t, store.Delete("record_1"))
+
+		itr, err := store.Query("type:odd")
+		require.NoError(t, err)
+		requireRecordsInAnyOrder(t, itr, []record{
+			{"record_0", "new_value_0"}, {"record_3", "value_3"}, {"record_5", "value_5"},
+		})
+
+		itr, err = store.Query("triple")
+		require.NoError(t, err)
+		requireRecordsInAnyOrder(t, itr, records(3, 6))
+	})
+}
+
+// TestBatch tests applying operations in batches.
+func TestBatch(t *testing.T, provider storage.Provider) {
+	store, err := provider.OpenStore("storagetest_batch")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1"), storage.Tag{Name: "tag", Value: "1"}))
+	require.NoError(t, store.Put("key2", []byte("value2")))
+
+	require.NoError(t, store.Batch(nil))
+
+	err = store.Batch([]storage.Operation{
+		storage.PutOperation("key3", []byte("value3"), storage.Tag{Name: "tag", Value: "3"}),
+		storage.DeleteOperation("key1"),
+		storage.PutOperation("key2", []byte("new value2"), storage.Tag{Name: "tag", Value: "2"}),
+		storage.PutOperation("key4", []byte("value4")),
+		storage.DeleteOperation("key4"),
+		storage.DeleteOperation("missing"),
+		storage.PutOperation("key5", []byte("value5")),
+		storage.PutOperation("key5", []byte(`{"field":"value5"}`), storage.Tag{Name: "tag", Value: "5"}),
+	})
+	require.NoError(t, err)
+
+	requireRecords(t, store.Iterator("key", "key"+storage.EndKeySuffix), []record{
+		{"key2", "new value2"}, {"key3", "value3"}, {"key5", `{"field":"value5"}`},
+	})
+
+	for _, k := range []string{"key1", "key4"} {
+		_, err = store.Get(k)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound), "got %v for %s", err, k)
+	}
+
+	itr, err := store.Query("tag")
+	require.NoError(t, err)
+	requireRecordsInAnyOrder(t, itr, []record{
+		{"key2", "new value2"}, {"key3", "value3"}, {"key5", `{"field":"value5"}`},
+	})
+}
+
+// TestLargeValues tests storing values of LargeValueSize bytes.
+func TestLargeValues(t *testing.T, provider storage.Provider) {
+	store, err := provider.OpenStore("storagetest_largevalues")
+	require.NoError(t, err)
+
+	value1 := randomBytes(t, LargeValueSize)
+	value2 := randomBytes(t, LargeValueSize)
+
+	require.NoError(t, store.Put("key1", value1, storage.Tag{Name: "tag"}))
+	require.NoError(t, store.Batch([]storage.Operation{storage.PutOperation("key2", value2)}))
+
+	stored, err := store.Get("key1")
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(value1, stored), "large value of key1 not read back")
+
+	stored, err = store.Get("key2")
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(value2, stored), "large value of key2 not read back")
+
+	itr := store.Iterator("key", "key"+storage.EndKeySuffix)
+	defer itr.Release()
+
+	for _, expected := range [][]byte{value1, value2} {
+		require.True(t, itr.Next())
+		require.True(t, bytes.Equal(expected, itr.Value()), "large value of %s not iterated", itr.Key())
+	}
+
+	require.False(t, itr.Next())
+	require.NoError(t, itr.Error())
+
+	queryItr, err := store.Query("tag")
+	require.NoError(t, err)
+
+	defer queryItr.Release()
+
+	require.True(t, queryItr.Next())
+	require.True(t, bytes.Equal(value1, queryItr.Value()), "large value of key1 not queried")
+}
+
+// TestConcurrentOpenStore tests opening the same store from concurrent goroutines.
+func TestConcurrentOpenStore(t *testing.T, provider storage.Provider) {
+	errs := runConcurrently(func(i int) error {
+		store, err := provider.OpenStore("storagetest_concurrentopen")
+		if err != nil {
+			return fmt.Errorf("open store: %w", err)
+		}
+
+		return store.Put(fmt.Sprintf("key_%d", i), []byte(fmt.Sprintf("value_%d", i)))
+	})
+	require.Empty(t, errs)
+
+	store, err := provider.OpenStore("storagetest_concurrentopen")
+	require.NoError(t, err)
+
+	for i := 0; i < concurrency; i++ {
+		value, err := store.Get(fmt.Sprintf("key_%d", i))
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("value_%d", i)), value)
+	}
+}
+
+// TestConcurrentOperations tests concurrent goroutines putting, reading, iterating over, querying and deleting
+// records of the same store.
+func TestConcurrentOperations(t *testing.T, provider storage.Provider) {
+	store, err := provider.OpenStore("storagetest_concurrentoperations")
+	require.NoError(t, err)
+
+	errs := runConcurrently(func(i int) error {
+		prefix := fmt.Sprintf("goroutine_%d_", i)
+		tag := storage.Tag{Name: "goroutine", Value: fmt.Sprint(i)}
+
+		for j := 0; j < iterations; j++ {
+			k, v := fmt.Sprintf("%skey_%02d", prefix, j), []byte(fmt.Sprintf("value_%d", j))
+
+			if err := store.Put(k, v, tag); err != nil {
+				return fmt.Errorf("put %s: %w", k, err)
+			}
+
+			stored, err := store.Get(k)
+			if err != nil {
+				return fmt.Errorf("get %s: %w", k, err)
+			}
+
+			if !bytes.Equal(v, stored) {
+				return fmt.Errorf("get %s: unexpected value %s", k, stored)
+			}
+
+			if err := expectCount(store.Iterator(prefix, prefix+storage.EndKeySuffix), j+1); err != nil {
+				return fmt.Errorf("iterator of %s: %w", prefix, err)
+			}
+		}
+
+		itr, err := store.Query(fmt.Sprintf("%s:%s", tag.Name, tag.Value))
+		if err != nil {
+			return fmt.Errorf("query of %s: %w", prefix, err)
+		}
+
+		if err := expectCount(itr, iterations); err != nil {
+			return fmt.Errorf("query of %s: %w", prefix, err)
+		}
+
+		for j := 0; j < iterations; j += 2 {
+			if err := store.Delete(fmt.Sprintf("%skey_%02d", prefix, j)); err != nil {
+				return fmt.Errorf("delete: %w", err)
+			}
+		}
+
+		return expectCount(store.Iterator(prefix, prefix+storage.EndKeySuffix), iterations/2)
+	})
+	require.Empty(t, errs)
+
+	requireCount(t, store.Iterator("goroutine_", "goroutine_"+storage.EndKeySuffix), concurrency*iterations/2)
+}
+
+// record is a key-value pair read from an iterator.
+type record struct {
+	key, value string
+}
+
+// readRecords reads and releases the iterator.
+func readRecords(itr storage.StoreIterator) ([]record, error) {
+	defer itr.Release()
+
+	var records []record
+
+	for itr.Next() {
+		records = append(records, record{string(itr.Key()), string(itr.Value())})
+	}
+
+	return records, itr.Error()
+}
+
+// requireRecords requires the iterator to return the expected records, in order.
+func requireRecords(t *testing.T, itr storage.StoreIterator, expected []record, msgAndArgs ...interface{}) {
+	t.Helper()
+
+	records, err := readRecords(itr)
+	require.NoError(t, err, msgAndArgs...)
+
+	if len(expected) == 0 {
+		require.Empty(t, records, msgAndArgs...)
+
+		return
+	}
+
+	require.Equal(t, expected, records, msgAndArgs...)
+}
+
+// requireRecordsInAnyOrder requires the iterator to return the expected records, in any order.
+func requireRecordsInAnyOrder(t *testing.T, itr storage.StoreIterator, expected []record,
+	msgAndArgs ...interface{}) {
+	t.Helper()
+
+	records, err := readRecords(itr)
+	require.NoError(t, err, msgAndArgs...)
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].key < records[j].key
+	})
+
+	if len(expected) == 0 {
+		require.Empty(t, records, msgAndArgs...)
+
+		return
+	}
+
+	require.Equal(t, expected, records, msgAndArgs...)
+}
+
+// requireCount requires the iterator to return count records.
+func requireCount(t *testing.T, itr storage.StoreIterator, count int) {
+	t.Helper()
+
+	require.NoError(t, expectCount(itr, count))
+}
+
+// expectCount returns an error if the iterator fails or doesn't return count records.
+func expectCount(itr storage.StoreIterator, count int) error {
+	records, err := readRecords(itr)
+	if err != nil {
+		return err
+	}
+
+	if len(records) != count {
+		return fmt.Errorf("%d records instead of %d", len(records), count)
+	}
+
+	return nil
+}
+
+// runConcurrently runs f in concurrent goroutines and returns their errors.
+func runConcurrently(f func(i int) error) []error {
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		errs []error
+	)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			if err := f(i); err != nil {
+				lock.Lock()
+				errs = append(errs, err)
+				lock.Unlock()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+func randomBytes(t *testing.T, size int) []byte {
+	t.Helper()
+
+	b := make([]byte, size)
+
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+
+	return b
+}
@@ -6,17 +6,28 @@ SPDX-License-Identifier: Apache-2.0
 
 package storage
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 // EndKeySuffix end key suffix
 const EndKeySuffix = "!!"
 
+// DefaultPageSize is the default number of records fetched at once by Store.Query
+const DefaultPageSize = 25
+
 // ErrDataNotFound is returned when data not found
 var ErrDataNotFound = errors.New("data not found")
 
 // ErrKeyRequired is returned when key is mandatory
 var ErrKeyRequired = errors.New("key is mandatory")
 
+// ErrInvalidQuery is returned when a query expression is not valid
+var ErrInvalidQuery = errors.New("invalid query expression")
+
 // Provider storage provider interface
 type Provider interface {
 	// OpenStore opens a store with given name space and returns the handle
@@ -31,8 +42,9 @@ type Provider interface {
 
 // Store is the storage interface
 type Store interface {
-	// Put stores the key and the record
-	Put(k string, v []byte) error
+	// Put stores the key and the record. The optional tags replace the tags of a previously stored record,
+	// they allow finding the record with Query.
+	Put(k string, v []byte, tags ...Tag) error
 
 	// Get fetches the record based on key
 	Get(k string) ([]byte, error)
@@ -52,6 +64,155 @@ type Store interface {
 
 	// Delete will delete a record with k key
 	Delete(k string) error
+
+	// Query returns an iterator over the records having a tag matching the expression.
+	//
+	// Args:
+	//
+	// expression: "TagName" matches the records having the tag TagName whatever its value,
+	// "TagName:TagValue" matches the records having the tag TagName with value TagValue.
+	// opts: query options, like the number of records fetched at once from the underlying database.
+	//
+	// Returns:
+	//
+	// StoreIterator: iterator for the matching records
+	// error: ErrInvalidQuery if the expression is not valid or failure
+	Query(expression string, opts ...QueryOption) (StoreIterator, error)
+
+	// Batch applies the operations in order, atomically: either all the operations are applied or none of them.
+	Batch(operations []Operation) error
+}
+
+// ExpiringStore is a Store supporting records with a time to live. Expired records are deleted by a background sweep
+// of the store, they can still be read until then.
+type ExpiringStore interface {
+	Store
+
+	// PutWithTTL stores the key and the record with its tags like Put, the record expires after ttl.
+	// A Put or a Batch operation on the key removes the expiry of the record.
+	PutWithTTL(k string, v []byte, ttl time.Duration, tags ...Tag) error
+
+	// BatchWithTTL applies the operations like Batch, the records stored by the operations expire after ttl.
+	BatchWithTTL(operations []Operation, ttl time.Duration) error
+}
+
+// PutWithTTL stores the record with a time to live if the store is an ExpiringStore, otherwise the record is stored
+// with Put and kept until it is deleted.
+func PutWithTTL(store Store, k string, v []byte, ttl time.Duration, tags ...Tag) error {
+	if expiringStore, ok := store.(ExpiringStore); ok {
+		return expiringStore.PutWithTTL(k, v, ttl, tags...)
+	}
+
+	return store.Put(k, v, tags...)
+}
+
+// BatchWithTTL applies the operations with a time to live of the records stored if the store is an ExpiringStore,
+// otherwise they are applied with Batch and the records are kept until they are deleted.
+func BatchWithTTL(store Store, operations []Operation, ttl time.Duration) error {
+	if expiringStore, ok := store.(ExpiringStore); ok {
+		return expiringStore.BatchWithTTL(operations, ttl)
+	}
+
+	return store.Batch(operations)
+}
+
+// Operation is a write operation of Store.Batch. An operation with Delete set deletes the record with the key,
+// otherwise the record is stored with its tags like Store.Put does.
+type Operation struct {
+	Key    string
+	Value  []byte
+	Tags   []Tag
+	Delete bool
+}
+
+// PutOperation returns an Operation storing the key and the record with its tags.
+func PutOperation(k string, v []byte, tags ...Tag) Operation {
+	return Operation{Key: k, Value: v, Tags: tags}
+}
+
+// DeleteOperation returns an Operation deleting the record with the key.
+func DeleteOperation(k string) Operation {
+	return Operation{Key: k, Delete: true}
+}
+
+// IsDelete reports whether the operation deletes the record.
+func (o *Operation) IsDelete() bool {
+	return o.Delete
+}
+
+// Tag is a name/value pair attached to a record by Store.Put. The value is optional and tag names are expected to be
+// unique among the tags of a record.
+type Tag struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+// QueryOptions holds the options of Store.Query.
+type QueryOptions struct {
+	// PageSize is the number of records fetched at once from the underlying database
+	PageSize int
+}
+
+// QueryOption is an option of Store.Query.
+type QueryOption func(opts *QueryOptions)
+
+// WithPageSize sets the number of records fetched at once from the underlying database by Store.Query.
+// Stores keeping their data in memory ignore it.
+func WithPageSize(size int) QueryOption {
+	return func(opts *QueryOptions) {
+		opts.PageSize = size
+	}
+}
+
+// NewQueryOptions returns the query options set by opts, with DefaultPageSize if no (or an invalid) page size is set.
+// It is intended for implementations of the Store interface.
+func NewQueryOptions(opts ...QueryOption) *QueryOptions {
+	options := &QueryOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.PageSize <= 0 {
+		options.PageSize = DefaultPageSize
+	}
+
+	return options
+}
+
+// TagQuery is a parsed Store.Query expression.
+type TagQuery struct {
+	// Name of the tag
+	Name string
+	// Value of the tag, it is ignored if AnyValue is true
+	Value string
+	// AnyValue is true if the expression has no tag value
+	AnyValue bool
+}
+
+// ParseQuery parses a Store.Query expression. It is intended for implementations of the Store interface.
+func ParseQuery(expression string) (*TagQuery, error) {
+	parts := strings.SplitN(expression, ":", 2) //nolint:gomnd
+	if parts[0] == "" {
+		return nil, fmt.Errorf("%w: '%s'", ErrInvalidQuery, expression)
+	}
+
+	if len(parts) == 1 {
+		return &TagQuery{Name: parts[0], AnyValue: true}, nil
+	}
+
+	return &TagQuery{Name: parts[0], Value: parts[1]}, nil
+}
+
+// Match reports whether one of tags matches the query.
+func (q *TagQuery) Match(tags []Tag) bool {
+	for _, tag := range tags {
+		if tag.Name == q.Name && (q.AnyValue || tag.Value == q.Value) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // StoreIterator is the iterator for the latest snapshot of the underlying store.
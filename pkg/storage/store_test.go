@@ -0,0 +1,138 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	t.Run("tag name", func(t *testing.T) {
+		query, err := ParseQuery("name")
+		require.NoError(t, err)
+		require.Equal(t, &TagQuery{Name: "name", AnyValue: true}, query)
+
+		require.True(t, query.Match([]Tag{{Name: "other"}, {Name: "name", Value: "value"}}))
+		require.False(t, query.Match([]Tag{{Name: "other", Value: "name"}}))
+	})
+
+	t.Run("tag name and value", func(t *testing.T) {
+		query, err := ParseQuery("name:value:1")
+		require.NoError(t, err)
+		require.Equal(t, &TagQuery{Name: "name", Value: "value:1"}, query)
+
+		require.True(t, query.Match([]Tag{{Name: "name", Value: "value:1"}}))
+		require.False(t, query.Match([]Tag{{Name: "name", Value: "value"}}))
+	})
+
+	t.Run("empty tag value", func(t *testing.T) {
+		query, err := ParseQuery("name:")
+		require.NoError(t, err)
+
+		require.True(t, query.Match([]Tag{{Name: "name"}}))
+		require.False(t, query.Match([]Tag{{Name: "name", Value: "value"}}))
+	})
+
+	t.Run("invalid expression", func(t *testing.T) {
+		_, err := ParseQuery("")
+		require.True(t, errors.Is(err, ErrInvalidQuery))
+
+		_, err = ParseQuery(":value")
+		require.True(t, errors.Is(err, ErrInvalidQuery))
+	})
+}
+
+func TestNewQueryOptions(t *testing.T) {
+	require.Equal(t, DefaultPageSize, NewQueryOptions().PageSize)
+	require.Equal(t, DefaultPageSize, NewQueryOptions(WithPageSize(0)).PageSize)
+	require.Equal(t, 10, NewQueryOptions(WithPageSize(10)).PageSize)
+}
+
+func TestOperation(t *testing.T) {
+	put := PutOperation("key", nil, Tag{Name: "tag"})
+	require.False(t, put.IsDelete())
+	require.Equal(t, []Tag{{Name: "tag"}}, put.Tags)
+
+	del := DeleteOperation("key")
+	require.True(t, del.IsDelete())
+	require.Equal(t, "key", del.Key)
+}
+
+func TestPutWithTTL(t *testing.T) {
+	t.Run("expiring store", func(t *testing.T) {
+		store := &stubStore{}
+
+		require.NoError(t, PutWithTTL(&stubExpiringStore{stubStore: store}, "key", []byte("value"), time.Hour,
+			Tag{Name: "tag"}))
+		require.Equal(t, []string{"PutWithTTL key 1h0m0s [{tag }]"}, store.calls)
+	})
+
+	t.Run("store without expiry", func(t *testing.T) {
+		store := &stubStore{}
+
+		require.NoError(t, PutWithTTL(store, "key", []byte("value"), time.Hour, Tag{Name: "tag"}))
+		require.Equal(t, []string{"Put key [{tag }]"}, store.calls)
+	})
+}
+
+func TestBatchWithTTL(t *testing.T) {
+	operations := []Operation{PutOperation("key", []byte("value")), DeleteOperation("other")}
+
+	t.Run("expiring store", func(t *testing.T) {
+		store := &stubStore{}
+
+		require.NoError(t, BatchWithTTL(&stubExpiringStore{stubStore: store}, operations, time.Hour))
+		require.Equal(t, []string{"BatchWithTTL 2 1h0m0s"}, store.calls)
+	})
+
+	t.Run("store without expiry", func(t *testing.T) {
+		store := &stubStore{}
+
+		require.NoError(t, BatchWithTTL(store, operations, time.Hour))
+		require.Equal(t, []string{"Batch 2"}, store.calls)
+	})
+}
+
+// stubStore records the calls of Put and Batch.
+type stubStore struct {
+	Store
+	calls []string
+}
+
+func (s *stubStore) Put(k string, _ []byte, tags ...Tag) error {
+	s.calls = append(s.calls, fmt.Sprintf("Put %s %v", k, tags))
+
+	return nil
+}
+
+func (s *stubStore) Batch(operations []Operation) error {
+	s.calls = append(s.calls, fmt.Sprintf("Batch %d", len(operations)))
+
+	return nil
+}
+
+// stubExpiringStore records the calls of PutWithTTL and BatchWithTTL.
+type stubExpiringStore struct {
+	*stubStore
+}
+
+func (s *stubExpiringStore) PutWithTTL(k string, _ []byte, ttl time.Duration, tags ...Tag) error {
+	s.calls = append(s.calls, fmt.Sprintf("PutWithTTL %s %s %v", k, ttl, tags))
+
+	return nil
+}
+
+func (s *stubExpiringStore) BatchWithTTL(operations []Operation, ttl time.Duration) error {
+	s.calls = append(s.calls, fmt.Sprintf("BatchWithTTL %d %s", len(operations), ttl))
+
+	return nil
+}
@@ -16,6 +16,7 @@ import (
 	"strings"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/migration"
 )
 
 const (
@@ -31,6 +32,8 @@ const (
 	limitPattern    = "%s" + storage.EndKeySuffix
 	keySeparator    = "_"
 	stateIDEmptyErr = "stateID can't be empty"
+	// tagsMigrationKey marks the stores whose connection records saved without tags have been tagged
+	tagsMigrationKey = "conntagsmigrated"
 )
 
 // KeyPrefix is prefix builder for storage keys
@@ -75,6 +78,89 @@ func NewLookup(p provider) (*Lookup, error) {
 	return &Lookup{transientStore: transientStore, store: store}, nil
 }
 
+// Migrations returns the migration steps of the connection records of the permanent and transient stores.
+func Migrations() []migration.Step {
+	var steps []migration.Step
+
+	for _, transient := range []bool{false, true} {
+		steps = append(steps, migration.Step{
+			Store:       Namespace,
+			Transient:   transient,
+			Version:     1,
+			Description: "tag the connection records saved without tags",
+			Migrate:     tagLegacyRecords,
+			Tags:        RecordTags,
+		})
+	}
+
+	return steps
+}
+
+func tagLegacyRecords(store storage.Store) error {
+	_, err := store.Get(tagsMigrationKey)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, storage.ErrDataNotFound) {
+		return err
+	}
+
+	records := make(map[string][]byte)
+
+	for _, keyPrefix := range []string{getConnectionKeyPrefix()(""), getConnectionStateKeyPrefix()("")} {
+		itr := store.Iterator(keyPrefix, fmt.Sprintf(limitPattern, keyPrefix))
+
+		for itr.Next() {
+			records[string(itr.Key())] = itr.Value()
+		}
+
+		itr.Release()
+
+		if err = itr.Error(); err != nil {
+			return err
+		}
+	}
+
+	for k, v := range records {
+		var tags []storage.Tag
+
+		tags, err = RecordTags(k, v)
+		if err != nil {
+			return err
+		}
+
+		err = store.Put(k, v, tags...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return store.Put(tagsMigrationKey, []byte("true"))
+}
+
+// RecordTags returns the tags of the record saved with key k in the permanent or transient connection store: the
+// connection records are tagged with their connection ID, the other records have no tags.
+func RecordTags(k string, v []byte) ([]storage.Tag, error) {
+	if !strings.HasPrefix(k, getConnectionKeyPrefix()("")) && !strings.HasPrefix(k, getConnectionStateKeyPrefix()("")) {
+		return nil, nil
+	}
+
+	var record Record
+
+	err := json.Unmarshal(v, &record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal connection record: %w", err)
+	}
+
+	return []storage.Tag{recordTag(k, record.ConnectionID)}, nil
+}
+
+// recordTag returns the tag of the connection record saved with key k, the tag name is the key prefix.
+func recordTag(k, connectionID string) storage.Tag {
+	return storage.Tag{Name: strings.SplitN(k, keySeparator, 2)[0], Value: connectionID} //nolint:gomnd
+}
+
 // Lookup takes care of connection related persistence features
 type Lookup struct {
 	transientStore storage.Store
@@ -104,9 +190,11 @@ func (c *Lookup) GetConnectionRecord(connectionID string) (*Record, error) {
 // for given query criteria
 func (c *Lookup) QueryConnectionRecords() ([]*Record, error) {
 	// TODO https://github.com/hyperledger/aries-framework-go/issues/655 query criteria to be added as part of issue
-	searchKey := getConnectionKeyPrefix()("")
+	itr, err := queryConnectionRecords(c.store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connection records, %w", err)
+	}
 
-	itr := c.store.Iterator(searchKey, fmt.Sprintf(limitPattern, searchKey))
 	defer itr.Release()
 
 	var records []*Record
@@ -126,7 +214,15 @@ func (c *Lookup) QueryConnectionRecords() ([]*Record, error) {
 		records = append(records, &record)
 	}
 
-	transientItr := c.transientStore.Iterator(searchKey, fmt.Sprintf(limitPattern, searchKey))
+	if err = itr.Error(); err != nil {
+		return nil, fmt.Errorf("failed to query connection records, %w", err)
+	}
+
+	transientItr, err := queryConnectionRecords(c.transientStore)
+	if err != nil {
+		return nil, fmt.Errorf("query connection records from transient store : %w", err)
+	}
+
 	defer transientItr.Release()
 
 	for transientItr.Next() {
@@ -144,9 +240,62 @@ func (c *Lookup) QueryConnectionRecords() ([]*Record, error) {
 		records = append(records, &record)
 	}
 
+	if err = transientItr.Error(); err != nil {
+		return nil, fmt.Errorf("query connection records from transient store : %w", err)
+	}
+
 	return records, nil
 }
 
+// queryConnectionRecords returns an iterator on the connection records of the store. The records are queried by tag
+// once the store is tagged, until then (e.g. the migrations didn't run yet) they are iterated by key prefix as the
+// records saved without tags aren't returned by the query.
+func queryConnectionRecords(store storage.Store) (storage.StoreIterator, error) {
+	tagged, err := isTagged(store)
+	if err != nil {
+		return nil, err
+	}
+
+	if tagged {
+		return store.Query(connIDKeyPrefix)
+	}
+
+	searchKey := getConnectionKeyPrefix()("")
+
+	return store.Iterator(searchKey, fmt.Sprintf(limitPattern, searchKey)), nil
+}
+
+// queryConnectionStateRecords returns an iterator on the connection state records of connectionID in the store,
+// queried by tag or iterated by key prefix like queryConnectionRecords.
+func queryConnectionStateRecords(store storage.Store, connectionID string) (storage.StoreIterator, error) {
+	tagged, err := isTagged(store)
+	if err != nil {
+		return nil, err
+	}
+
+	if tagged {
+		return store.Query(connStateKeyPrefix + ":" + connectionID)
+	}
+
+	searchKey := getConnectionStateKeyPrefix()(connectionID, "")
+
+	return store.Iterator(searchKey, fmt.Sprintf(limitPattern, searchKey)), nil
+}
+
+// isTagged returns true if the connection records saved without tags in the store have been tagged.
+func isTagged(store storage.Store) (bool, error) {
+	_, err := store.Get(tagsMigrationKey)
+	if err == nil {
+		return true, nil
+	}
+
+	if !errors.Is(err, storage.ErrDataNotFound) {
+		return false, err
+	}
+
+	return false, nil
+}
+
 // GetConnectionRecordAtState return connection record based on the connection ID and state.
 func (c *Lookup) GetConnectionRecordAtState(connectionID, stateID string) (*Record, error) {
 	if stateID == "" {
@@ -11,6 +11,7 @@ package connection
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -20,6 +21,7 @@ import (
 	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/store/migration"
 )
 
 const (
@@ -65,7 +67,8 @@ func TestConnectionReader_GetAndQueryConnectionRecord(t *testing.T) {
 			connRecBytes, err := json.Marshal(&Record{ConnectionID: id,
 				ThreadID: fmt.Sprintf(threadIDFmt, id)})
 			require.NoError(t, err)
-			err = store.Put(getConnectionKeyPrefix()(id), connRecBytes)
+			err = store.Put(getConnectionKeyPrefix()(id), connRecBytes,
+				storage.Tag{Name: connIDKeyPrefix, Value: id})
 			require.NoError(t, err)
 		}
 	}
@@ -309,7 +312,7 @@ func TestConnectionRecorder_QueryConnectionRecord(t *testing.T) {
 			})
 			require.NoError(t, jsonErr)
 
-			err = store.Put(fmt.Sprintf("%s_abc%d", connIDKeyPrefix, i), val)
+			err = store.Put(fmt.Sprintf("%s_abc%d", connIDKeyPrefix, i), val, storage.Tag{Name: connIDKeyPrefix})
 			require.NoError(t, err)
 		}
 		for i := overlap; i < transientStoreCount+storeCount; i++ {
@@ -318,7 +321,8 @@ func TestConnectionRecorder_QueryConnectionRecord(t *testing.T) {
 			})
 			require.NoError(t, jsonErr)
 
-			err = transientStore.Put(fmt.Sprintf("%s_abc%d", connIDKeyPrefix, i), val)
+			err = transientStore.Put(fmt.Sprintf("%s_abc%d", connIDKeyPrefix, i), val,
+				storage.Tag{Name: connIDKeyPrefix})
 			require.NoError(t, err)
 		}
 
@@ -332,7 +336,7 @@ func TestConnectionRecorder_QueryConnectionRecord(t *testing.T) {
 
 	t.Run("test query connection record failure", func(t *testing.T) {
 		store := &mockstorage.MockStore{Store: make(map[string][]byte)}
-		err := store.Put(fmt.Sprintf("%s_abc123", connIDKeyPrefix), []byte("-----"))
+		err := store.Put(fmt.Sprintf("%s_abc123", connIDKeyPrefix), []byte("-----"), storage.Tag{Name: connIDKeyPrefix})
 		require.NoError(t, err)
 
 		recorder, err := NewLookup(&mockProvider{store: store})
@@ -344,6 +348,192 @@ func TestConnectionRecorder_QueryConnectionRecord(t *testing.T) {
 	})
 }
 
+func TestConnectionRecorder_QueryConnectionRecordError(t *testing.T) {
+	t.Run("query store failure", func(t *testing.T) {
+		recorder, err := NewLookup(&mockProvider{store: &mockstorage.MockStore{
+			Store:    map[string][]byte{tagsMigrationKey: []byte("true")},
+			ErrQuery: fmt.Errorf(sampleErrMsg),
+		}})
+		require.NoError(t, err)
+
+		_, err = recorder.QueryConnectionRecords()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), sampleErrMsg)
+	})
+
+	t.Run("get migration key failure", func(t *testing.T) {
+		recorder, err := NewLookup(&mockProvider{store: &mockstorage.MockStore{
+			Store:  make(map[string][]byte),
+			ErrGet: fmt.Errorf(sampleErrMsg),
+		}})
+		require.NoError(t, err)
+
+		_, err = recorder.QueryConnectionRecords()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), sampleErrMsg)
+	})
+
+	t.Run("query transient store failure", func(t *testing.T) {
+		recorder, err := NewLookup(&mockProvider{transientStore: &mockstorage.MockStore{
+			Store:    map[string][]byte{tagsMigrationKey: []byte("true")},
+			ErrQuery: fmt.Errorf(sampleErrMsg),
+		}})
+		require.NoError(t, err)
+
+		_, err = recorder.QueryConnectionRecords()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), sampleErrMsg)
+	})
+}
+
+func TestTagLegacyRecords(t *testing.T) {
+	t.Run("tag connection records saved without tags", func(t *testing.T) {
+		store, err := mem.NewProvider().OpenStore(Namespace)
+		require.NoError(t, err)
+
+		transientStore, err := mem.NewProvider().OpenStore(Namespace)
+		require.NoError(t, err)
+
+		record := &Record{ConnectionID: "conn-1", State: stateNameCompleted}
+		stateKey := getConnectionStateKeyPrefix()(record.ConnectionID, record.State)
+
+		require.NoError(t, marshalAndSave(getConnectionKeyPrefix()(record.ConnectionID), record, store))
+		require.NoError(t, marshalAndSave(getConnectionKeyPrefix()(record.ConnectionID), record, transientStore))
+		require.NoError(t, marshalAndSave(stateKey, record, transientStore))
+
+		recorder, err := NewRecorder(&mockProvider{store: store, transientStore: transientStore})
+		require.NoError(t, err)
+
+		// the records saved without tags are found by key prefix until the store is tagged
+		records, err := recorder.QueryConnectionRecords()
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+
+		require.NoError(t, removeConnectionsForStates(recorder, record.ConnectionID))
+
+		_, err = recorder.GetConnectionRecordAtState(record.ConnectionID, record.State)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		require.NoError(t, marshalAndSave(stateKey, record, transientStore))
+
+		require.NoError(t, tagLegacyRecords(store))
+		require.NoError(t, tagLegacyRecords(transientStore))
+
+		records, err = recorder.QueryConnectionRecords()
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		require.Equal(t, record.ConnectionID, records[0].ConnectionID)
+
+		require.NoError(t, removeConnectionsForStates(recorder, record.ConnectionID))
+
+		_, err = recorder.GetConnectionRecordAtState(record.ConnectionID, record.State)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		// records saved without tags after the migration are not tagged again
+		require.NoError(t, marshalAndSave(getConnectionKeyPrefix()("conn-2"), &Record{ConnectionID: "conn-2"}, store))
+		require.NoError(t, tagLegacyRecords(store))
+
+		records, err = recorder.QueryConnectionRecords()
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+	})
+
+	t.Run("get migration key failure", func(t *testing.T) {
+		err := tagLegacyRecords(&mockstorage.MockStore{
+			Store:  make(map[string][]byte),
+			ErrGet: fmt.Errorf(sampleErrMsg),
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), sampleErrMsg)
+	})
+
+	t.Run("iterate records failure", func(t *testing.T) {
+		err := tagLegacyRecords(&mockstorage.MockStore{
+			Store:  make(map[string][]byte),
+			ErrItr: fmt.Errorf(sampleErrMsg),
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), sampleErrMsg)
+	})
+
+	t.Run("invalid record", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: make(map[string][]byte)}
+		require.NoError(t, store.Put(getConnectionKeyPrefix()("conn-1"), []byte("-----")))
+
+		err := tagLegacyRecords(store)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal connection record")
+	})
+
+	t.Run("put record failure", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: make(map[string][]byte)}
+		require.NoError(t, marshalAndSave(getConnectionKeyPrefix()("conn-1"), &Record{ConnectionID: "conn-1"}, store))
+
+		store.ErrPut = fmt.Errorf(sampleErrMsg)
+
+		err := tagLegacyRecords(store)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), sampleErrMsg)
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	store, err := mem.NewProvider().OpenStore(Namespace)
+	require.NoError(t, err)
+
+	transientStore, err := mem.NewProvider().OpenStore(Namespace)
+	require.NoError(t, err)
+
+	record := &Record{ConnectionID: "conn-1", State: stateNameCompleted}
+
+	require.NoError(t, marshalAndSave(getConnectionKeyPrefix()(record.ConnectionID), record, store))
+	require.NoError(t, marshalAndSave(getConnectionStateKeyPrefix()(record.ConnectionID, record.State), record,
+		transientStore))
+
+	provider := &mockProvider{store: store, transientStore: transientStore}
+
+	migrator := migration.New(provider)
+	require.NoError(t, migrator.Register(Migrations()...))
+
+	report, err := migrator.Run()
+	require.NoError(t, err)
+	require.Len(t, report.Stores, 2)
+	require.Equal(t, []string{getConnectionKeyPrefix()(record.ConnectionID), tagsMigrationKey},
+		report.Stores[0].Steps[0].Updated)
+	require.True(t, report.Stores[1].Transient)
+
+	lookup, err := NewLookup(provider)
+	require.NoError(t, err)
+
+	records, err := lookup.QueryConnectionRecords()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	_, err = lookup.GetConnectionRecordAtState(record.ConnectionID, record.State)
+	require.NoError(t, err)
+}
+
+func TestRecordTags(t *testing.T) {
+	record, err := json.Marshal(&Record{ConnectionID: "conn-1"})
+	require.NoError(t, err)
+
+	tags, err := RecordTags(getConnectionKeyPrefix()("conn-1"), record)
+	require.NoError(t, err)
+	require.Equal(t, []storage.Tag{{Name: "conn", Value: "conn-1"}}, tags)
+
+	tags, err = RecordTags(getConnectionStateKeyPrefix()("conn-1", stateNameCompleted), record)
+	require.NoError(t, err)
+	require.Equal(t, []storage.Tag{{Name: "connstate", Value: "conn-1"}}, tags)
+
+	tags, err = RecordTags("didconn_did", []byte("conn-1"))
+	require.NoError(t, err)
+	require.Empty(t, tags)
+
+	_, err = RecordTags(getConnectionKeyPrefix()("conn-1"), []byte("invalid"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to unmarshal connection record")
+}
+
 func TestGetConnectionIDByDIDs(t *testing.T) {
 	myDID := "did:mydid:123"
 	theirDID := "did:theirdid:789"
@@ -14,6 +14,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
@@ -25,6 +26,9 @@ const (
 	//  will need to be figured with verification key
 	theirNSPrefix    = "their"
 	errMsgInvalidKey = "invalid key"
+	// transientDataTTL is the time to live of the data of the exchanges in the transient store: the connection records,
+	// their namespace mappings and event data. The data of abandoned exchanges is deleted after it.
+	transientDataTTL = 72 * time.Hour
 )
 
 // NewRecorder returns new connection recorder.
@@ -56,33 +60,7 @@ func (c *Recorder) SaveInvitation(id string, invitation interface{}) error {
 
 // SaveConnectionRecord saves given connection records in underlying store
 func (c *Recorder) SaveConnectionRecord(record *Record) error {
-	if err := marshalAndSave(getConnectionKeyPrefix()(record.ConnectionID),
-		record, c.transientStore); err != nil {
-		return fmt.Errorf("save connection record in transient store: %w", err)
-	}
-
-	if record.State != "" {
-		err := marshalAndSave(getConnectionStateKeyPrefix()(record.ConnectionID, record.State),
-			record, c.transientStore)
-		if err != nil {
-			return fmt.Errorf("save connection record with state in transient store: %w", err)
-		}
-	}
-
-	if record.State == stateNameCompleted {
-		if err := marshalAndSave(getConnectionKeyPrefix()(record.ConnectionID),
-			record, c.store); err != nil {
-			return fmt.Errorf("save connection record in permanent store: %w", err)
-		}
-
-		// create map between DIDs and ConnectionID
-		if err := c.store.Put(getDIDConnMapKeyPrefix()(record.MyDID, record.TheirDID),
-			[]byte(record.ConnectionID)); err != nil {
-			return fmt.Errorf("save did and connection map in store: %w", err)
-		}
-	}
-
-	return nil
+	return c.saveConnectionRecord(record)
 }
 
 // SaveConnectionRecordWithMappings saves newly created connection record against the connection id in the store
@@ -93,29 +71,79 @@ func (c *Recorder) SaveConnectionRecordWithMappings(record *Record) error {
 		return fmt.Errorf("validation failed while saving connection record with mapping: %w", err)
 	}
 
-	err = c.SaveConnectionRecord(record)
+	mapping, err := namespaceThreadIDOperation(record.ThreadID, record.Namespace, record.ConnectionID)
+	if err != nil {
+		return fmt.Errorf("failed to save connection record with namespace mappings: %w", err)
+	}
+
+	err = c.saveConnectionRecord(record, mapping)
 	if err != nil {
 		return fmt.Errorf("failed to save connection record with mappings: %w", err)
 	}
 
-	err = c.SaveNamespaceThreadID(record.ThreadID, record.Namespace, record.ConnectionID)
+	return nil
+}
+
+// saveConnectionRecord saves the connection record in the transient store in one batch with the additional
+// transient operations, expiring after transientDataTTL, then in the permanent store with the DIDs mapping once the
+// connection is completed.
+func (c *Recorder) saveConnectionRecord(record *Record, transientOperations ...storage.Operation) error {
+	bytes, err := json.Marshal(record)
 	if err != nil {
-		return fmt.Errorf("failed to save connection record with namespace mappings: %w", err)
+		return fmt.Errorf("save connection record: %w", err)
+	}
+
+	connTag := storage.Tag{Name: connIDKeyPrefix, Value: record.ConnectionID}
+
+	operations := []storage.Operation{storage.PutOperation(getConnectionKeyPrefix()(record.ConnectionID), bytes, connTag)}
+
+	if record.State != "" {
+		operations = append(operations, storage.PutOperation(
+			getConnectionStateKeyPrefix()(record.ConnectionID, record.State), bytes,
+			storage.Tag{Name: connStateKeyPrefix, Value: record.ConnectionID}))
+	}
+
+	err = storage.BatchWithTTL(c.transientStore, append(operations, transientOperations...), transientDataTTL)
+	if err != nil {
+		return fmt.Errorf("save connection record in transient store: %w", err)
+	}
+
+	if record.State == stateNameCompleted {
+		err = c.store.Batch([]storage.Operation{
+			storage.PutOperation(getConnectionKeyPrefix()(record.ConnectionID), bytes, connTag),
+			// create map between DIDs and ConnectionID
+			storage.PutOperation(getDIDConnMapKeyPrefix()(record.MyDID, record.TheirDID), []byte(record.ConnectionID)),
+		})
+		if err != nil {
+			return fmt.Errorf("save connection record and did map in permanent store: %w", err)
+		}
 	}
 
 	return nil
 }
 
-// SaveEvent saves event related data for given connection ID
+// SaveEvent saves event related data for given connection ID, the event data of abandoned exchanges expire
+// after transientDataTTL.
 // TODO connection event data shouldn't be transient [Issues #1029]
 func (c *Recorder) SaveEvent(connectionID string, data []byte) error {
-	return c.transientStore.Put(getEventDataKeyPrefix()(connectionID), data)
+	return storage.PutWithTTL(c.transientStore, getEventDataKeyPrefix()(connectionID), data, transientDataTTL)
 }
 
-// SaveNamespaceThreadID saves given namespace, threadID and connection ID mapping in transient store
+// SaveNamespaceThreadID saves given namespace, threadID and connection ID mapping in transient store, the mapping
+// expires after transientDataTTL.
 func (c *Recorder) SaveNamespaceThreadID(threadID, namespace, connectionID string) error {
+	mapping, err := namespaceThreadIDOperation(threadID, namespace, connectionID)
+	if err != nil {
+		return err
+	}
+
+	return storage.PutWithTTL(c.transientStore, mapping.Key, mapping.Value, transientDataTTL)
+}
+
+// namespaceThreadIDOperation returns the operation saving the namespace, threadID and connection ID mapping.
+func namespaceThreadIDOperation(threadID, namespace, connectionID string) (storage.Operation, error) {
 	if namespace != myNSPrefix && namespace != theirNSPrefix {
-		return fmt.Errorf("namespace not supported")
+		return storage.Operation{}, fmt.Errorf("namespace not supported")
 	}
 
 	prefix := myNSPrefix
@@ -125,10 +153,10 @@ func (c *Recorder) SaveNamespaceThreadID(threadID, namespace, connectionID strin
 
 	key, err := computeHash([]byte(threadID))
 	if err != nil {
-		return err
+		return storage.Operation{}, err
 	}
 
-	return c.transientStore.Put(getNamespaceKeyPrefix(prefix)(key), []byte(connectionID))
+	return storage.PutOperation(getNamespaceKeyPrefix(prefix)(key), []byte(connectionID)), nil
 }
 
 // RemoveConnection removes connection record from the store for given id
@@ -169,13 +197,13 @@ func (c *Recorder) RemoveConnection(connectionID string) error {
 	return nil
 }
 
-func marshalAndSave(k string, v interface{}, store storage.Store) error {
+func marshalAndSave(k string, v interface{}, store storage.Store, tags ...storage.Tag) error {
 	bytes, err := json.Marshal(v)
 	if err != nil {
 		return fmt.Errorf("save connection record: %w", err)
 	}
 
-	return store.Put(k, bytes)
+	return store.Put(k, bytes, tags...)
 }
 
 // isValidConnection validates connection record
@@ -201,24 +229,36 @@ func computeHash(bytes []byte) (string, error) {
 }
 
 func removeConnectionsForStates(c *Recorder, connectionID string) error {
-	itr := c.transientStore.Iterator(getConnectionStateKeyPrefix()(
-		connectionID),
-		getConnectionStateKeyPrefix()(connectionID)+storage.EndKeySuffix,
-	)
-	defer itr.Release()
+	itr, err := queryConnectionStateRecords(c.transientStore, connectionID)
+	if err != nil {
+		return fmt.Errorf("unable to query connection state records: connectionid=%s err=%w", connectionID, err)
+	}
+
+	var keys []string
 
 	for itr.Next() {
-		key := string(itr.Key())
-		err := c.transientStore.Delete(key)
+		keys = append(keys, string(itr.Key()))
+	}
 
-		if err != nil {
-			return fmt.Errorf(
-				"unable to delete connection state record from the transient store: key=%s connectionid=%s err=%w",
-				key, connectionID, err)
-		}
+	itr.Release()
+
+	if err = itr.Error(); err != nil {
+		return err
 	}
 
-	return itr.Error()
+	operations := make([]storage.Operation, len(keys))
+
+	for i, key := range keys {
+		operations[i] = storage.DeleteOperation(key)
+	}
+
+	err = c.transientStore.Batch(operations)
+	if err != nil {
+		return fmt.Errorf("unable to delete connection state records from the transient store: connectionid=%s err=%w",
+			connectionID, err)
+	}
+
+	return nil
 }
 
 func removeMappings(c *Recorder, record *Record) error {
@@ -12,6 +12,7 @@ package connection
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -134,7 +135,7 @@ func Test_RemoveConnectionRecordsForStates(t *testing.T) {
 		}
 
 		err := marshalAndSave(getConnectionStateKeyPrefix()(record.ConnectionID, record.State),
-			record, store)
+			record, store, storage.Tag{Name: connStateKeyPrefix, Value: record.ConnectionID})
 		require.NoError(t, err)
 
 		recorder, err := NewRecorder(&protocol.MockProvider{
@@ -163,6 +164,20 @@ func Test_RemoveConnectionRecordsForStates(t *testing.T) {
 		err = removeConnectionsForStates(recorder, "anyID")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), errMsg)
+
+		// tagged store
+		store.Store[tagsMigrationKey] = []byte("true")
+		store.ErrQuery = fmt.Errorf(errMsg)
+
+		err = removeConnectionsForStates(recorder, "anyID")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errMsg)
+
+		store.ErrGet = fmt.Errorf(errMsg)
+
+		err = removeConnectionsForStates(recorder, "anyID")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errMsg)
 	})
 	t.Run("test failed to delete connection state record from the store", func(t *testing.T) {
 		const errMsg = "get error"
@@ -181,7 +196,7 @@ func Test_RemoveConnectionRecordsForStates(t *testing.T) {
 		}
 
 		err := marshalAndSave(getConnectionStateKeyPrefix()(record.ConnectionID, record.State),
-			record, store)
+			record, store, storage.Tag{Name: connStateKeyPrefix, Value: record.ConnectionID})
 		require.NoError(t, err)
 
 		recorder, err := NewRecorder(&protocol.MockProvider{
@@ -297,7 +312,9 @@ func TestConnectionStore_GetInvitation(t *testing.T) {
 
 func TestConnectionStore_SaveAndGetEventData(t *testing.T) {
 	t.Run("test save and get event data - success", func(t *testing.T) {
-		recorder, err := NewRecorder(&protocol.MockProvider{})
+		transientStore := mockstorage.NewMockStoreProvider()
+
+		recorder, err := NewRecorder(&protocol.MockProvider{TransientStoreProvider: transientStore})
 		require.NoError(t, err)
 		require.NotNil(t, recorder)
 
@@ -309,6 +326,10 @@ func TestConnectionStore_SaveAndGetEventData(t *testing.T) {
 		valueFound, err := recorder.GetEvent(sampleConnID)
 		require.NoError(t, err)
 		require.Equal(t, valueStored, valueFound)
+
+		// the event data expires
+		require.Equal(t, map[string]time.Duration{getEventDataKeyPrefix()(sampleConnID): transientDataTTL},
+			transientStore.Store.TTLs)
 	})
 
 	t.Run("test get invitation - not found scenario", func(t *testing.T) {
@@ -396,6 +417,26 @@ func TestConnectionRecorder_SaveConnectionRecord(t *testing.T) {
 		require.Equal(t, record, &r2)
 	})
 
+	t.Run("save connection record with mappings - the transient records expire", func(t *testing.T) {
+		transientStore := mockstorage.NewMockStoreProvider()
+
+		recorder, err := NewRecorder(&protocol.MockProvider{TransientStoreProvider: transientStore})
+		require.NoError(t, err)
+
+		record := &Record{ThreadID: threadIDValue,
+			ConnectionID: sampleConnID, State: stateNameInvited, Namespace: theirNSPrefix}
+		require.NoError(t, recorder.SaveConnectionRecordWithMappings(record))
+
+		nsKey, err := CreateNamespaceKey(theirNSPrefix, threadIDValue)
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]time.Duration{
+			getConnectionKeyPrefix()(sampleConnID):                        transientDataTTL,
+			getConnectionStateKeyPrefix()(sampleConnID, stateNameInvited): transientDataTTL,
+			nsKey: transientDataTTL,
+		}, transientStore.Store.TTLs)
+	})
+
 	t.Run("save connection record with invited state - completed", func(t *testing.T) {
 		recorder, err := NewRecorder(&protocol.MockProvider{})
 		require.NoError(t, err)
@@ -458,6 +499,24 @@ func TestConnectionRecorder_SaveConnectionRecord(t *testing.T) {
 		err = record.SaveConnectionRecord(connRec)
 		require.Contains(t, err.Error(), errMsg)
 	})
+
+	t.Run("save connection record batch error", func(t *testing.T) {
+		const errMsg = "batch error"
+		transientStore := &mockstorage.MockStore{
+			Store:    make(map[string][]byte),
+			ErrBatch: fmt.Errorf(errMsg),
+		}
+		recorder, err := NewRecorder(&protocol.MockProvider{
+			TransientStoreProvider: mockstorage.NewCustomMockStoreProvider(transientStore),
+		})
+		require.NoError(t, err)
+		connRec := &Record{ThreadID: threadIDValue,
+			ConnectionID: sampleConnID, State: stateNameInvited, Namespace: theirNSPrefix}
+		err = recorder.SaveConnectionRecordWithMappings(connRec)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errMsg)
+		require.Empty(t, transientStore.Store)
+	})
 }
 
 func TestConnectionRecorder_RemoveConnection(t *testing.T) {
@@ -726,6 +785,22 @@ func TestConnectionRecorder_CreateNSKeys(t *testing.T) {
 }
 
 func TestConnectionRecorder_SaveNamespaceThreadID(t *testing.T) {
+	t.Run("save namespace thread ID mapping", func(t *testing.T) {
+		transientStore := mockstorage.NewMockStoreProvider()
+
+		recorder, err := NewRecorder(&protocol.MockProvider{TransientStoreProvider: transientStore})
+		require.NoError(t, err)
+
+		require.NoError(t, recorder.SaveNamespaceThreadID(threadIDValue, theirNSPrefix, sampleConnID))
+
+		key, err := CreateNamespaceKey(theirNSPrefix, threadIDValue)
+		require.NoError(t, err)
+		require.Equal(t, []byte(sampleConnID), transientStore.Store.Store[key])
+
+		// the mapping expires
+		require.Equal(t, map[string]time.Duration{key: transientDataTTL}, transientStore.Store.TTLs)
+	})
+
 	t.Run("missing required parameters", func(t *testing.T) {
 		recorder, err := NewRecorder(&protocol.MockProvider{})
 		require.NoError(t, err)
@@ -51,27 +51,22 @@ func NewConnectionStore(ctx connectionProvider) (*ConnectionStore, error) {
 	return &ConnectionStore{store: store, vdr: ctx.VDRIRegistry()}, nil
 }
 
-// saveDID saves a DID, indexed using the given public key
-func (c *ConnectionStore) saveDID(did, key string) error {
-	data := didRecord{
-		DID: did,
-	}
-
-	bytes, err := json.Marshal(data)
+// SaveDID saves a DID, indexed using the given public keys
+func (c *ConnectionStore) SaveDID(did string, keys ...string) error {
+	bytes, err := json.Marshal(didRecord{DID: did})
 	if err != nil {
-		return err
+		return fmt.Errorf("saving DID in did map: %w", err)
 	}
 
-	return c.store.Put(key, bytes)
-}
+	operations := make([]storage.Operation, len(keys))
 
-// SaveDID saves a DID, indexed using the given public keys
-func (c *ConnectionStore) SaveDID(did string, keys ...string) error {
-	for _, key := range keys {
-		err := c.saveDID(did, key)
-		if err != nil {
-			return fmt.Errorf("saving DID in did map: %w", err)
-		}
+	for i, key := range keys {
+		operations[i] = storage.PutOperation(key, bytes)
+	}
+
+	err = c.store.Batch(operations)
+	if err != nil {
+		return fmt.Errorf("saving DID in did map: %w", err)
 	}
 
 	return nil
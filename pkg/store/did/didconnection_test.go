@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package did
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -74,6 +75,40 @@ func TestBaseConnectionStore(t *testing.T) {
 		require.Contains(t, err.Error(), "put error")
 	})
 
+	t.Run("SaveDID batch error", func(t *testing.T) {
+		cs, err := NewConnectionStore(&ctx{
+			store: &mockstorage.MockStoreProvider{
+				Store: &mockstorage.MockStore{
+					Store:    map[string][]byte{},
+					ErrBatch: fmt.Errorf("batch error"),
+				},
+			},
+			vdr: &mockvdri.MockVDRIRegistry{},
+		})
+		require.NoError(t, err)
+
+		err = cs.SaveDID("did", "key1", "key2")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "batch error")
+
+		_, err = cs.GetDID("key1")
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("SaveDID with several keys", func(t *testing.T) {
+		connStore, err := NewConnectionStore(&prov)
+		require.NoError(t, err)
+
+		err = connStore.SaveDID("did:several", "key1", "key2")
+		require.NoError(t, err)
+
+		for _, key := range []string{"key1", "key2"} {
+			didVal, err := connStore.GetDID(key)
+			require.NoError(t, err)
+			require.Equal(t, "did:several", didVal)
+		}
+	})
+
 	t.Run("SaveDID + GetDID", func(t *testing.T) {
 		connStore, err := NewConnectionStore(&prov)
 		require.NoError(t, err)
@@ -0,0 +1,397 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/messenger"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/introduce"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/issuecredential"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/mediator"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/outofband"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/presentproof"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+	"github.com/hyperledger/aries-framework-go/pkg/store/did"
+	"github.com/hyperledger/aries-framework-go/pkg/store/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/peer"
+)
+
+const archiveVersion = 1
+
+// Store is a store of the agent whose records are exported.
+type Store struct {
+	// Name is the name of the store.
+	Name string
+	// Transient selects the store of the transient storage provider instead of the permanent one.
+	Transient bool
+	// Tags returns the tags of a record on import, as the tags of the records can't be read from the stores to be
+	// exported. The records are imported without tags if it is nil.
+	Tags func(k string, v []byte) ([]storage.Tag, error)
+}
+
+// DefaultStores returns the stores exported by default: the connections, the DIDs of store/did and of the peer VDRI,
+// the credentials and presentations, the states of the protocol services and the legacy KMS key pairs. The keysets
+// of the KMS aren't kept in the archive as stored, they are exported with the backup of the KMS. The legacy KMS key
+// pairs are left out with WithoutKeys.
+func DefaultStores() []Store {
+	return []Store{
+		{Name: connection.Namespace, Tags: connection.RecordTags},
+		{Name: connection.Namespace, Transient: true, Tags: connection.RecordTags},
+		{Name: did.NameSpace},
+		{Name: did.StoreName},
+		{Name: peer.StoreNamespace},
+		{Name: verifiable.NameSpace},
+		{Name: issuecredential.Name},
+		{Name: presentproof.Name},
+		{Name: introduce.Introduce},
+		{Name: mediator.Coordination},
+		{Name: outofband.Name, Transient: true},
+		{Name: messenger.MessengerStore},
+		{Name: legacykms.KeyStoreNamespace},
+		{Name: legacykms.AdapterStoreNamespace},
+	}
+}
+
+// ImportResult is the result of an Import call.
+type ImportResult struct {
+	// Stores are the imported stores with their number of records
+	Stores []*StoreResult `json:"stores"`
+	// Keys is the result of the restore of the KMS keys, if the archive has them
+	Keys *localkms.RestoreResult `json:"keys,omitempty"`
+	// DryRun is set when nothing was written to the stores
+	DryRun bool `json:"dryRun"`
+}
+
+// StoreResult is the result of the import of a store.
+type StoreResult struct {
+	Name      string `json:"name"`
+	Transient bool   `json:"transient,omitempty"`
+	Records   int    `json:"records"`
+}
+
+type provider interface {
+	StorageProvider() storage.Provider
+	TransientStorageProvider() storage.Provider
+	KMS() kms.KeyManager
+}
+
+// keyBackup is implemented by KMS supporting passphrase protected backup and restore of their keys.
+type keyBackup interface {
+	BackupKeys(passphrase []byte, opts ...localkms.BackupOpt) ([]byte, error)
+	RestoreKeys(archive, passphrase []byte, opts ...localkms.BackupOpt) (*localkms.RestoreResult, error)
+}
+
+type options struct {
+	stores      []Store
+	withoutKeys bool
+	dryRun      bool
+}
+
+// Option is an option of Export and Import.
+type Option func(opts *options)
+
+// WithStores adds stores to the default stores, such as the stores of custom protocol services. On import, it sets
+// how the records of the stores of the archive are tagged.
+func WithStores(stores ...Store) Option {
+	return func(opts *options) {
+		opts.stores = append(opts.stores, stores...)
+	}
+}
+
+// WithoutKeys leaves the keys of the KMS and the stores of key material, such as the legacy KMS key pairs, out of the
+// export, or ignores them in the archive on import. It is required to export the data of an agent whose KMS doesn't
+// support the backup of its keys.
+func WithoutKeys() Option {
+	return func(opts *options) {
+		opts.withoutKeys = true
+	}
+}
+
+// WithDryRun executes all the checks of Import without writing anything, the result tells what would be imported.
+func WithDryRun() Option {
+	return func(opts *options) {
+		opts.dryRun = true
+	}
+}
+
+// payload is the content of the archive.
+type payload struct {
+	Created time.Time       `json:"created"`
+	Stores  []*storeRecords `json:"stores"`
+	// Keys is the backup archive of the KMS keys, encrypted with the same passphrase
+	Keys json.RawMessage `json:"keys,omitempty"`
+}
+
+type storeRecords struct {
+	Name      string   `json:"name"`
+	Transient bool     `json:"transient,omitempty"`
+	Records   []record `json:"records"`
+}
+
+type record struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// Export exports the records of the stores of the agent and the keys of its KMS into a single archive encrypted with
+// a key derived from passphrase. The archive can be imported into an agent using any storage provider.
+// Returns:
+//  - the encrypted archive
+//  - error if failure
+func Export(p provider, passphrase []byte, opts ...Option) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("export: passphrase is mandatory")
+	}
+
+	eOpts := newOptions(opts)
+
+	data := &payload{Created: time.Now().UTC()}
+
+	for _, s := range eOpts.stores {
+		if eOpts.excluded(s.Name, s.Transient) {
+			continue
+		}
+
+		records, err := readRecords(storageProvider(p, s.Transient), s.Name)
+		if err != nil {
+			return nil, fmt.Errorf("export: store '%s': %w", s.Name, err)
+		}
+
+		data.Stores = append(data.Stores, &storeRecords{Name: s.Name, Transient: s.Transient, Records: records})
+	}
+
+	if !eOpts.withoutKeys {
+		backup, err := kmsBackup(p)
+		if err != nil {
+			return nil, fmt.Errorf("export: %w", err)
+		}
+
+		data.Keys, err = backup.BackupKeys(passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("export: %w", err)
+		}
+	}
+
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("export: failed to marshal payload: %w", err)
+	}
+
+	archive, err := cryptoutil.SealArchive(dataBytes, passphrase, archiveVersion)
+	if err != nil {
+		return nil, fmt.Errorf("export: %w", err)
+	}
+
+	return archive, nil
+}
+
+// Import decrypts archive with passphrase and imports its records into the stores of the agent, replacing the records
+// with the same keys, and its keys into the KMS, skipping the keys already present. The records keep the schema
+// version of the agent which exported them, they are migrated the next time the framework starts.
+//
+// The whole archive, keys included, is decrypted and validated before anything is written. The import isn't atomic
+// though: the keys and each store are written one after the other, a failure to write leaves the keys and the stores
+// written before it imported. Importing the archive again once the failure is fixed completes the import.
+// Returns:
+//  - result listing the imported stores and keys
+//  - error if the passphrase is wrong, the archive is corrupted or failed to write the records
+func Import(p provider, archive, passphrase []byte, opts ...Option) (*ImportResult, error) {
+	iOpts := newOptions(opts)
+
+	dataBytes, err := cryptoutil.OpenArchive(archive, passphrase, archiveVersion)
+	if err != nil {
+		return nil, fmt.Errorf("import: %w", err)
+	}
+
+	data := &payload{}
+
+	err = json.Unmarshal(dataBytes, data)
+	if err != nil {
+		return nil, fmt.Errorf("import: failed to unmarshal payload: %w", err)
+	}
+
+	var stores []*storeRecords
+
+	for _, s := range data.Stores {
+		if !iOpts.excluded(s.Name, s.Transient) {
+			stores = append(stores, s)
+		}
+	}
+
+	// the operations of all the stores are prepared before importing anything
+	operations := make([][]storage.Operation, len(stores))
+
+	for i, s := range stores {
+		operations[i], err = iOpts.operations(s)
+		if err != nil {
+			return nil, fmt.Errorf("import: store '%s': %w", s.Name, err)
+		}
+	}
+
+	result := &ImportResult{Stores: []*StoreResult{}, DryRun: iOpts.dryRun}
+
+	if len(data.Keys) > 0 && !iOpts.withoutKeys {
+		// a dry run of the restore decrypts and validates the keys before anything is written
+		result.Keys, err = restoreKeys(p, data.Keys, passphrase, true)
+		if err != nil {
+			return nil, fmt.Errorf("import: %w", err)
+		}
+
+		if !iOpts.dryRun {
+			result.Keys, err = restoreKeys(p, data.Keys, passphrase, false)
+			if err != nil {
+				return nil, fmt.Errorf("import: %w", err)
+			}
+		}
+	}
+
+	for i, s := range stores {
+		if !iOpts.dryRun && len(operations[i]) > 0 {
+			err = writeRecords(storageProvider(p, s.Transient), s.Name, operations[i])
+			if err != nil {
+				return nil, fmt.Errorf("import: store '%s': %w", s.Name, err)
+			}
+		}
+
+		result.Stores = append(result.Stores, &StoreResult{Name: s.Name, Transient: s.Transient, Records: len(s.Records)})
+	}
+
+	return result, nil
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{stores: DefaultStores()}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// excluded reports whether the store is left out of the export and import, the stores of key material are left out
+// without the keys.
+func (o *options) excluded(name string, transient bool) bool {
+	if !o.withoutKeys || transient {
+		return false
+	}
+
+	return name == legacykms.KeyStoreNamespace || name == localkms.Namespace
+}
+
+// operations returns the operations importing the records of the store, tagged by the store with the same name.
+func (o *options) operations(s *storeRecords) ([]storage.Operation, error) {
+	var tags func(k string, v []byte) ([]storage.Tag, error)
+
+	for _, store := range o.stores {
+		if store.Name == s.Name && store.Transient == s.Transient {
+			tags = store.Tags
+		}
+	}
+
+	operations := make([]storage.Operation, len(s.Records))
+
+	for i, r := range s.Records {
+		if r.Key == "" || r.Value == nil {
+			return nil, errors.New("invalid record: key and value are mandatory")
+		}
+
+		var recordTags []storage.Tag
+
+		if tags != nil {
+			var err error
+
+			recordTags, err = tags(r.Key, r.Value)
+			if err != nil {
+				return nil, fmt.Errorf("record %s: %w", r.Key, err)
+			}
+		}
+
+		operations[i] = storage.PutOperation(r.Key, r.Value, recordTags...)
+	}
+
+	return operations, nil
+}
+
+func storageProvider(p provider, transient bool) storage.Provider {
+	if transient {
+		return p.TransientStorageProvider()
+	}
+
+	return p.StorageProvider()
+}
+
+// readRecords returns all the records of the store.
+func readRecords(storageProvider storage.Provider, name string) ([]record, error) {
+	store, err := storageProvider.OpenStore(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+
+	itr := store.Iterator("", storage.EndKeySuffix)
+	defer itr.Release()
+
+	records := []record{}
+
+	for itr.Next() {
+		records = append(records, record{Key: string(itr.Key()), Value: append([]byte{}, itr.Value()...)})
+	}
+
+	if err = itr.Error(); err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+
+	return records, nil
+}
+
+// writeRecords applies the operations to the store in a batch.
+func writeRecords(storageProvider storage.Provider, name string, operations []storage.Operation) error {
+	store, err := storageProvider.OpenStore(name)
+	if err != nil {
+		return fmt.Errorf("failed to open store: %w", err)
+	}
+
+	err = store.Batch(operations)
+	if err != nil {
+		return fmt.Errorf("failed to write records: %w", err)
+	}
+
+	return nil
+}
+
+func restoreKeys(p provider, archive, passphrase []byte, dryRun bool) (*localkms.RestoreResult, error) {
+	backup, err := kmsBackup(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []localkms.BackupOpt
+
+	if dryRun {
+		opts = append(opts, localkms.WithDryRun())
+	}
+
+	return backup.RestoreKeys(archive, passphrase, opts...)
+}
+
+func kmsBackup(p provider) (keyBackup, error) {
+	backup, ok := p.KMS().(keyBackup)
+	if !ok {
+		return nil, errors.New("kms does not support key backup and restore")
+	}
+
+	return backup, nil
+}
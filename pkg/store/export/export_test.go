@@ -0,0 +1,309 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/legacykms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+	"github.com/hyperledger/aries-framework-go/pkg/store/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/peer"
+)
+
+const masterKeyURI = "local-lock://test/master/key/"
+
+//nolint:gochecknoglobals
+var passphrase = []byte("passphrase")
+
+func TestExportImport(t *testing.T) {
+	src := newAgent(t)
+
+	recorder, err := connection.NewRecorder(src)
+	require.NoError(t, err)
+
+	completed := &connection.Record{ConnectionID: "conn1", State: "completed", MyDID: "did:peer:me",
+		TheirDID: "did:peer:them"}
+	require.NoError(t, recorder.SaveConnectionRecord(completed))
+	require.NoError(t, recorder.SaveConnectionRecord(&connection.Record{ConnectionID: "conn2", State: "requested"}))
+
+	putRecord(t, src.storageProvider, peer.StoreNamespace, "did:peer:me", "{}")
+	putRecord(t, src.storageProvider, verifiable.NameSpace, "vcname_vc", `{"id":"vc"}`)
+	putRecord(t, src.storageProvider, "custom", "k", "v")
+	putRecord(t, src.storageProvider, legacykms.KeyStoreNamespace, "legacykey", "secret")
+
+	keyID, _, err := src.kms.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	archive, err := Export(src, passphrase, WithStores(Store{Name: "custom"}))
+	require.NoError(t, err)
+
+	t.Run("test import", func(t *testing.T) {
+		dst := newAgent(t)
+
+		result, err := Import(dst, archive, passphrase, WithStores(Store{Name: "custom"}))
+		require.NoError(t, err)
+		require.False(t, result.DryRun)
+		require.Equal(t, []string{keyID}, result.Keys.KeyIDs)
+		require.Len(t, result.Stores, len(DefaultStores())+1)
+		require.Contains(t, result.Stores, &StoreResult{Name: connection.Namespace, Transient: true, Records: 4})
+		require.Contains(t, result.Stores, &StoreResult{Name: "custom", Records: 1})
+
+		for _, s := range append(DefaultStores(), Store{Name: "custom"}) {
+			srcRecords, err := readRecords(storageProvider(src, s.Transient), s.Name)
+			require.NoError(t, err)
+
+			dstRecords, err := readRecords(storageProvider(dst, s.Transient), s.Name)
+			require.NoError(t, err)
+
+			require.Equal(t, srcRecords, dstRecords, s.Name)
+		}
+
+		// the connection records are tagged
+		lookup, err := connection.NewLookup(dst)
+		require.NoError(t, err)
+
+		records, err := lookup.QueryConnectionRecords()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+
+		_, err = dst.kms.Get(keyID)
+		require.NoError(t, err)
+
+		// the keys already in the KMS are skipped
+		result, err = Import(dst, archive, passphrase)
+		require.NoError(t, err)
+		require.Empty(t, result.Keys.KeyIDs)
+		require.Equal(t, []string{keyID}, result.Keys.SkippedKeyIDs)
+	})
+
+	t.Run("test import dry run", func(t *testing.T) {
+		dst := newAgent(t)
+
+		result, err := Import(dst, archive, passphrase, WithDryRun())
+		require.NoError(t, err)
+		require.True(t, result.DryRun)
+		require.True(t, result.Keys.DryRun)
+		require.Equal(t, []string{keyID}, result.Keys.KeyIDs)
+		require.Contains(t, result.Stores, &StoreResult{Name: connection.Namespace, Records: 2})
+
+		records, err := readRecords(dst.storageProvider, connection.Namespace)
+		require.NoError(t, err)
+		require.Empty(t, records)
+
+		_, err = dst.kms.Get(keyID)
+		require.Error(t, err)
+	})
+
+	t.Run("test import without keys", func(t *testing.T) {
+		dst := newAgent(t)
+		dst.kms = &mockkms.KeyManager{}
+
+		result, err := Import(dst, archive, passphrase, WithoutKeys())
+		require.NoError(t, err)
+		require.Nil(t, result.Keys)
+		require.NotContains(t, result.Stores, &StoreResult{Name: legacykms.KeyStoreNamespace, Records: 1})
+
+		records, err := readRecords(dst.storageProvider, legacykms.KeyStoreNamespace)
+		require.NoError(t, err)
+		require.Empty(t, records)
+
+		_, err = Import(dst, archive, passphrase)
+		require.EqualError(t, err, "import: kms does not support key backup and restore")
+	})
+
+	t.Run("test import errors", func(t *testing.T) {
+		dst := newAgent(t)
+
+		_, err := Import(dst, archive, []byte("wrong passphrase"))
+		require.EqualError(t, err, "import: invalid passphrase or corrupted archive")
+
+		invalid, err := cryptoutil.SealArchive([]byte("{"), passphrase, archiveVersion)
+		require.NoError(t, err)
+
+		_, err = Import(dst, invalid, passphrase)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "import: failed to unmarshal payload")
+
+		invalid = sealPayload(t, &payload{Stores: []*storeRecords{{Name: "custom", Records: []record{{Key: "k"}}}}})
+
+		_, err = Import(dst, invalid, passphrase)
+		require.EqualError(t, err, "import: store 'custom': invalid record: key and value are mandatory")
+
+		invalid = sealPayload(t, &payload{Stores: []*storeRecords{{
+			Name:    connection.Namespace,
+			Records: []record{{Key: "conn_conn1", Value: []byte("invalid")}},
+		}}})
+
+		_, err = Import(dst, invalid, passphrase)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "import: store 'didexchange': record conn_conn1: "+
+			"failed to unmarshal connection record")
+
+		invalid = sealPayload(t, &payload{
+			Stores: []*storeRecords{{Name: "custom", Records: []record{{Key: "k", Value: []byte("v")}}}},
+			Keys:   json.RawMessage(`{}`),
+		})
+
+		_, err = Import(dst, invalid, passphrase)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "import: restore keys")
+
+		// nothing is written when the keys are invalid
+		records, err := readRecords(dst.storageProvider, "custom")
+		require.NoError(t, err)
+		require.Empty(t, records)
+
+		valid := sealPayload(t, &payload{Stores: []*storeRecords{{
+			Name:    "custom",
+			Records: []record{{Key: "k", Value: []byte("v")}},
+		}}})
+
+		dst.storageProvider = &mockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")}
+
+		_, err = Import(dst, valid, passphrase)
+		require.EqualError(t, err, "import: store 'custom': failed to open store: open error")
+
+		dst.storageProvider = mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+			Store:    make(map[string][]byte),
+			ErrBatch: errors.New("batch error"),
+		})
+
+		_, err = Import(dst, valid, passphrase)
+		require.EqualError(t, err, "import: store 'custom': failed to write records: batch error")
+	})
+}
+
+func TestExport(t *testing.T) {
+	t.Run("test export without keys", func(t *testing.T) {
+		agent := newAgent(t)
+		agent.kms = &mockkms.KeyManager{}
+
+		_, err := Export(agent, passphrase)
+		require.EqualError(t, err, "export: kms does not support key backup and restore")
+
+		putRecord(t, agent.storageProvider, legacykms.KeyStoreNamespace, "legacykey", "legacy private key")
+		putRecord(t, agent.storageProvider, localkms.Namespace, "keyset", "keyset")
+
+		archive, err := Export(agent, passphrase, WithoutKeys(), WithStores(Store{Name: localkms.Namespace}))
+		require.NoError(t, err)
+
+		data := openPayload(t, archive)
+		require.Empty(t, data.Keys)
+		require.Len(t, data.Stores, len(DefaultStores())-1)
+
+		for _, s := range data.Stores {
+			require.NotEqual(t, legacykms.KeyStoreNamespace, s.Name)
+			require.NotEqual(t, localkms.Namespace, s.Name)
+		}
+
+		dataBytes, err := cryptoutil.OpenArchive(archive, passphrase, archiveVersion)
+		require.NoError(t, err)
+		require.NotContains(t, string(dataBytes), "legacy private key")
+		require.NotContains(t, string(dataBytes), "keyset")
+	})
+
+	t.Run("test export errors", func(t *testing.T) {
+		agent := newAgent(t)
+
+		_, err := Export(agent, nil)
+		require.EqualError(t, err, "export: passphrase is mandatory")
+
+		agent.storageProvider = &mockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")}
+
+		_, err = Export(agent, passphrase)
+		require.EqualError(t, err, "export: store 'didexchange': failed to open store: open error")
+
+		agent.storageProvider = mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+			Store:  make(map[string][]byte),
+			ErrItr: errors.New("iterator error"),
+		})
+
+		_, err = Export(agent, passphrase)
+		require.EqualError(t, err, "export: store 'didexchange': failed to read records: iterator error")
+	})
+}
+
+type mockAgent struct {
+	storageProvider          storage.Provider
+	transientStorageProvider storage.Provider
+	kms                      kms.KeyManager
+}
+
+func newAgent(t *testing.T) *mockAgent {
+	t.Helper()
+
+	agent := &mockAgent{storageProvider: mem.NewProvider(), transientStorageProvider: mem.NewProvider()}
+
+	k, err := localkms.New(masterKeyURI, agent)
+	require.NoError(t, err)
+
+	agent.kms = k
+
+	return agent
+}
+
+func (a *mockAgent) StorageProvider() storage.Provider {
+	return a.storageProvider
+}
+
+func (a *mockAgent) TransientStorageProvider() storage.Provider {
+	return a.transientStorageProvider
+}
+
+func (a *mockAgent) KMS() kms.KeyManager {
+	return a.kms
+}
+
+func (a *mockAgent) SecretLock() secretlock.Service {
+	return &noop.NoLock{}
+}
+
+func putRecord(t *testing.T, p storage.Provider, name, k, v string) {
+	t.Helper()
+
+	store, err := p.OpenStore(name)
+	require.NoError(t, err)
+	require.NoError(t, store.Put(k, []byte(v)))
+}
+
+func sealPayload(t *testing.T, data *payload) []byte {
+	t.Helper()
+
+	dataBytes, err := json.Marshal(data)
+	require.NoError(t, err)
+
+	archive, err := cryptoutil.SealArchive(dataBytes, passphrase, archiveVersion)
+	require.NoError(t, err)
+
+	return archive
+}
+
+func openPayload(t *testing.T, archive []byte) *payload {
+	t.Helper()
+
+	dataBytes, err := cryptoutil.OpenArchive(archive, passphrase, archiveVersion)
+	require.NoError(t, err)
+
+	data := &payload{}
+	require.NoError(t, json.Unmarshal(dataBytes, data))
+
+	return data
+}
@@ -0,0 +1,415 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+var logger = log.New("aries-framework/store/migration")
+
+const (
+	// SchemaVersionKey is the key of the schema version of the records of a store, saved in the store itself.
+	// A store without it has the version 0, it was created before its records were versioned.
+	SchemaVersionKey = "schemaversion"
+
+	backupStorePattern = "%s_backup_v%d"
+)
+
+// Step migrates the records of a store from the schema version Version-1 to Version.
+type Step struct {
+	// Store is the name of the store.
+	Store string
+	// Transient selects the store of the transient storage provider instead of the permanent one.
+	Transient bool
+	// Version is the schema version of the records once migrated, the versions of a store start at 1.
+	Version int
+	// Description tells what the step changes, it is copied to the report.
+	Description string
+	// Migrate reads and writes the records of the store. The writes are staged and applied atomically with the new
+	// schema version once Migrate returns without error, they are discarded otherwise or in a dry run. The records
+	// which can't be migrated are reported with Skip rather than failing the step.
+	Migrate func(store storage.Store) error
+	// Tags returns the tags of a record of the schema version Version-1, as the tags of the records can't be read from
+	// the stores. It tags the records copied to a backup of that version and restored from it, the records are copied
+	// without tags if it is nil.
+	Tags func(k string, v []byte) ([]storage.Tag, error)
+}
+
+// Report is the report of the migration of the stores.
+type Report struct {
+	DryRun bool           `json:"dryRun,omitempty"`
+	Stores []*StoreReport `json:"stores,omitempty"`
+}
+
+// StoreReport is the report of the migration of a store.
+type StoreReport struct {
+	Store       string `json:"store"`
+	Transient   bool   `json:"transient,omitempty"`
+	FromVersion int    `json:"fromVersion"`
+	ToVersion   int    `json:"toVersion"`
+	// Backup is the name of the store the records were copied to before the migration, if any
+	Backup string        `json:"backup,omitempty"`
+	Steps  []*StepReport `json:"steps"`
+}
+
+// StepReport is the report of a migration step, with the keys of the records it saved and deleted, and the reasons
+// the records it skipped couldn't be migrated, by key.
+type StepReport struct {
+	Version     int               `json:"version"`
+	Description string            `json:"description,omitempty"`
+	Updated     []string          `json:"updated,omitempty"`
+	Deleted     []string          `json:"deleted,omitempty"`
+	Skipped     map[string]string `json:"skipped,omitempty"`
+}
+
+type provider interface {
+	StorageProvider() storage.Provider
+	TransientStorageProvider() storage.Provider
+}
+
+// storeID identifies a store of the permanent or transient storage provider.
+type storeID struct {
+	name      string
+	transient bool
+}
+
+// Migrator migrates the stores of the permanent and transient storage providers to the schema version of the latest
+// registered steps.
+type Migrator struct {
+	storageProvider          storage.Provider
+	transientStorageProvider storage.Provider
+	steps                    map[storeID][]*Step
+	dryRun                   bool
+	backup                   bool
+}
+
+// Option configures the Migrator.
+type Option func(m *Migrator)
+
+// WithDryRun runs the migration steps without applying their changes, the report tells what would be changed.
+func WithDryRun() Option {
+	return func(m *Migrator) {
+		m.dryRun = true
+	}
+}
+
+// WithBackup copies the records of a store to the store <name>_backup_v<version> of the same storage provider before
+// migrating it, tagged by the Tags of the first step run. The store can be restored from the backup with Restore.
+func WithBackup() Option {
+	return func(m *Migrator) {
+		m.backup = true
+	}
+}
+
+// New returns a new Migrator of the stores of the provider.
+func New(p provider, opts ...Option) *Migrator {
+	m := &Migrator{
+		storageProvider:          p.StorageProvider(),
+		transientStorageProvider: p.TransientStorageProvider(),
+		steps:                    make(map[storeID][]*Step),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Register registers migration steps, the steps of a store are run in the order of their versions.
+func (m *Migrator) Register(steps ...Step) error {
+	for i := range steps {
+		step := steps[i]
+
+		if step.Store == "" || step.Version < 1 || step.Migrate == nil {
+			return fmt.Errorf("invalid migration step %d of store '%s': store, version and migrate are mandatory",
+				step.Version, step.Store)
+		}
+
+		id := storeID{name: step.Store, transient: step.Transient}
+
+		for _, s := range m.steps[id] {
+			if s.Version == step.Version {
+				return fmt.Errorf("duplicate migration step %d of store '%s'", step.Version, step.Store)
+			}
+		}
+
+		m.steps[id] = append(m.steps[id], &step)
+
+		sort.Slice(m.steps[id], func(i, j int) bool {
+			return m.steps[id][i].Version < m.steps[id][j].Version
+		})
+	}
+
+	return nil
+}
+
+// Run migrates the stores having registered steps to the version of their latest step. The steps of a store are
+// applied one by one, a failed step leaves the store at the version of the previous step. The stores are migrated
+// in the order of their names, the permanent stores first.
+func (m *Migrator) Run() (*Report, error) {
+	ids := make([]storeID, 0, len(m.steps))
+
+	for id := range m.steps {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].transient != ids[j].transient {
+			return ids[j].transient
+		}
+
+		return ids[i].name < ids[j].name
+	})
+
+	report := &Report{DryRun: m.dryRun}
+
+	for _, id := range ids {
+		storeReport, err := m.migrate(id, m.steps[id])
+		if err != nil {
+			return nil, fmt.Errorf("migrate store '%s': %w", id.name, err)
+		}
+
+		if storeReport != nil {
+			report.Stores = append(report.Stores, storeReport)
+		}
+	}
+
+	return report, nil
+}
+
+// migrate runs the pending steps of the store, it returns a nil report if the store is up to date.
+func (m *Migrator) migrate(id storeID, steps []*Step) (*StoreReport, error) {
+	for i, step := range steps {
+		if step.Version != i+1 {
+			return nil, fmt.Errorf("missing migration step %d", i+1)
+		}
+	}
+
+	storageProvider := m.storageProvider
+	if id.transient {
+		storageProvider = m.transientStorageProvider
+	}
+
+	store, err := storageProvider.OpenStore(id.name)
+	if err != nil {
+		return nil, fmt.Errorf("open store: %w", err)
+	}
+
+	version, err := SchemaVersion(store)
+	if err != nil {
+		return nil, err
+	}
+
+	if version > len(steps) {
+		return nil, fmt.Errorf("schema version %d is newer than the latest known version %d", version, len(steps))
+	}
+
+	if version == len(steps) {
+		return nil, nil
+	}
+
+	report := &StoreReport{Store: id.name, Transient: id.transient, FromVersion: version, ToVersion: version}
+
+	if m.backup && !m.dryRun {
+		report.Backup = fmt.Sprintf(backupStorePattern, id.name, version)
+
+		err = backupStore(store, storageProvider, report.Backup, steps[version].Tags)
+		if err != nil {
+			return nil, fmt.Errorf("backup: %w", err)
+		}
+	}
+
+	// in a dry run, the staged changes of a step are seen by the next steps
+	var current storage.Store = store
+
+	for _, step := range steps[version:] {
+		staged := newStagedStore(current)
+
+		err = step.Migrate(staged)
+		if err != nil {
+			return nil, fmt.Errorf("migration step %d: %w", step.Version, err)
+		}
+
+		stepReport := &StepReport{Version: step.Version, Description: step.Description}
+		stepReport.Updated, stepReport.Deleted = staged.changes()
+		stepReport.Skipped = staged.skipped
+
+		err = staged.Put(SchemaVersionKey, []byte(strconv.Itoa(step.Version)))
+		if err != nil {
+			return nil, err
+		}
+
+		if m.dryRun {
+			current = staged
+		} else if err = store.Batch(staged.operations()); err != nil {
+			return nil, fmt.Errorf("apply migration step %d: %w", step.Version, err)
+		}
+
+		report.ToVersion = step.Version
+		report.Steps = append(report.Steps, stepReport)
+	}
+
+	return report, nil
+}
+
+// Skip reports that the record k of the store given to a migration step couldn't be migrated for the given reason.
+// The record is logged and listed in the report of the step, which goes on with the other records.
+func Skip(store storage.Store, k string, reason error) {
+	logger.Warnf("migration skipped record %s: %s", k, reason)
+
+	if staged, ok := store.(*stagedStore); ok {
+		if staged.skipped == nil {
+			staged.skipped = make(map[string]string)
+		}
+
+		staged.skipped[k] = reason.Error()
+	}
+}
+
+// SchemaVersion returns the schema version of the records of the store.
+func SchemaVersion(store storage.Store) (int, error) {
+	v, err := store.Get(SchemaVersionKey)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("get schema version: %w", err)
+	}
+
+	version, err := strconv.Atoi(string(v))
+	if err != nil {
+		return 0, fmt.Errorf("invalid schema version '%s': %w", v, err)
+	}
+
+	return version, nil
+}
+
+// Restore replaces the records of the store with the records of its backup of the schema version, made by a run with
+// WithBackup, tagged by the Tags of the registered step migrating the store from that version. The records which
+// aren't in the backup are deleted.
+func (m *Migrator) Restore(name string, transient bool, version int) error {
+	id := storeID{name: name, transient: transient}
+
+	var tags func(k string, v []byte) ([]storage.Tag, error)
+
+	for _, step := range m.steps[id] {
+		if step.Version == version+1 {
+			tags = step.Tags
+		}
+	}
+
+	storageProvider := m.storageProvider
+	if transient {
+		storageProvider = m.transientStorageProvider
+	}
+
+	store, err := storageProvider.OpenStore(name)
+	if err != nil {
+		return fmt.Errorf("restore store '%s': open store: %w", name, err)
+	}
+
+	backup, err := storageProvider.OpenStore(fmt.Sprintf(backupStorePattern, name, version))
+	if err != nil {
+		return fmt.Errorf("restore store '%s': open backup store: %w", name, err)
+	}
+
+	operations, err := copyOperations(backup, tags)
+	if err != nil {
+		return fmt.Errorf("restore store '%s': %w", name, err)
+	}
+
+	// a store isn't backed up when it has no records, the backup can't be told apart from a missing one
+	if len(operations) == 0 {
+		return fmt.Errorf("restore store '%s': no records in backup of version %d", name, version)
+	}
+
+	restored := make(map[string]bool, len(operations))
+
+	for _, op := range operations {
+		restored[op.Key] = true
+	}
+
+	itr := store.Iterator("", storage.EndKeySuffix)
+	defer itr.Release()
+
+	for itr.Next() {
+		if !restored[string(itr.Key())] {
+			operations = append(operations, storage.DeleteOperation(string(itr.Key())))
+		}
+	}
+
+	if err = itr.Error(); err != nil {
+		return fmt.Errorf("restore store '%s': read records: %w", name, err)
+	}
+
+	if err = store.Batch(operations); err != nil {
+		return fmt.Errorf("restore store '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// backupStore copies the records of the store with their tags to the backup store in a batch.
+func backupStore(store storage.Store, storageProvider storage.Provider, backup string,
+	tags func(k string, v []byte) ([]storage.Tag, error)) error {
+	backupStore, err := storageProvider.OpenStore(backup)
+	if err != nil {
+		return fmt.Errorf("open backup store: %w", err)
+	}
+
+	operations, err := copyOperations(store, tags)
+	if err != nil {
+		return err
+	}
+
+	if len(operations) == 0 {
+		return nil
+	}
+
+	return backupStore.Batch(operations)
+}
+
+// copyOperations returns the operations copying the records of the store, tagged by tags if not nil.
+func copyOperations(store storage.Store, tags func(k string, v []byte) ([]storage.Tag, error)) ([]storage.Operation,
+	error) {
+	itr := store.Iterator("", storage.EndKeySuffix)
+	defer itr.Release()
+
+	var operations []storage.Operation
+
+	for itr.Next() {
+		k, v := string(itr.Key()), append([]byte{}, itr.Value()...)
+
+		var recordTags []storage.Tag
+
+		if tags != nil && k != SchemaVersionKey {
+			var err error
+
+			recordTags, err = tags(k, v)
+			if err != nil {
+				return nil, fmt.Errorf("tags of record %s: %w", k, err)
+			}
+		}
+
+		operations = append(operations, storage.PutOperation(k, v, recordTags...))
+	}
+
+	if err := itr.Error(); err != nil {
+		return nil, fmt.Errorf("read records: %w", err)
+	}
+
+	return operations, nil
+}
@@ -0,0 +1,468 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestMigrator_Register(t *testing.T) {
+	m := New(newProvider())
+
+	t.Run("test success", func(t *testing.T) {
+		require.NoError(t, m.Register(putStep("store", 2, "k", "v2"), putStep("store", 1, "k", "v1")))
+		require.NoError(t, m.Register(Step{Store: "store", Transient: true, Version: 1, Migrate: noop}))
+		require.Equal(t, 1, m.steps[storeID{name: "store"}][0].Version)
+		require.Equal(t, 2, m.steps[storeID{name: "store"}][1].Version)
+	})
+
+	t.Run("test invalid step", func(t *testing.T) {
+		err := m.Register(Step{Version: 1, Migrate: noop})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "store, version and migrate are mandatory")
+
+		err = m.Register(Step{Store: "store", Migrate: noop})
+		require.Error(t, err)
+
+		err = m.Register(Step{Store: "store", Version: 1})
+		require.Error(t, err)
+	})
+
+	t.Run("test duplicate step", func(t *testing.T) {
+		err := m.Register(putStep("store", 1, "k", "v"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "duplicate migration step 1 of store 'store'")
+	})
+}
+
+func TestMigrator_Run(t *testing.T) {
+	t.Run("test success", func(t *testing.T) {
+		p := newProvider()
+		store := openStore(t, p.storageProvider, "store")
+		require.NoError(t, store.Put("old", []byte("v0")))
+
+		m := New(p)
+		require.NoError(t, m.Register(
+			putStep("store", 1, "k", "v1"),
+			Step{Store: "store", Version: 2, Description: "delete the old record", Migrate: func(s storage.Store) error {
+				return s.Delete("old")
+			}},
+			Step{Store: "store", Transient: true, Version: 1, Migrate: noop},
+			putStep("another", 1, "k", "v1"),
+		))
+
+		report, err := m.Run()
+		require.NoError(t, err)
+		require.False(t, report.DryRun)
+		require.Equal(t, []*StoreReport{
+			{Store: "another", FromVersion: 0, ToVersion: 1, Steps: []*StepReport{
+				{Version: 1, Description: "put k", Updated: []string{"k"}},
+			}},
+			{Store: "store", FromVersion: 0, ToVersion: 2, Steps: []*StepReport{
+				{Version: 1, Description: "put k", Updated: []string{"k"}},
+				{Version: 2, Description: "delete the old record", Deleted: []string{"old"}},
+			}},
+			{Store: "store", Transient: true, FromVersion: 0, ToVersion: 1, Steps: []*StepReport{{Version: 1}}},
+		}, report.Stores)
+
+		requireRecord(t, store, "k", "v1")
+		requireRecord(t, store, SchemaVersionKey, "2")
+
+		_, err = store.Get("old")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		requireRecord(t, openStore(t, p.transientStorageProvider, "store"), SchemaVersionKey, "1")
+
+		// the stores are up to date
+		report, err = m.Run()
+		require.NoError(t, err)
+		require.Empty(t, report.Stores)
+
+		// the new steps are run
+		require.NoError(t, m.Register(putStep("store", 3, "k", "v3")))
+
+		report, err = m.Run()
+		require.NoError(t, err)
+		require.Len(t, report.Stores, 1)
+		require.Equal(t, 2, report.Stores[0].FromVersion)
+		require.Equal(t, 3, report.Stores[0].ToVersion)
+		requireRecord(t, store, "k", "v3")
+	})
+
+	t.Run("test skipped records", func(t *testing.T) {
+		p := newProvider()
+		store := openStore(t, p.storageProvider, "store")
+		require.NoError(t, store.Put("bad", []byte("v0")))
+
+		m := New(p)
+		require.NoError(t, m.Register(Step{Store: "store", Version: 1, Migrate: func(s storage.Store) error {
+			Skip(s, "bad", errors.New("invalid record"))
+
+			return s.Put("good", []byte("v1"))
+		}}))
+
+		report, err := m.Run()
+		require.NoError(t, err)
+		require.Len(t, report.Stores, 1)
+		require.Equal(t, 1, report.Stores[0].ToVersion)
+		require.Equal(t, map[string]string{"bad": "invalid record"}, report.Stores[0].Steps[0].Skipped)
+		require.Equal(t, []string{"good"}, report.Stores[0].Steps[0].Updated)
+
+		requireRecord(t, store, "bad", "v0")
+		requireRecord(t, store, "good", "v1")
+	})
+
+	t.Run("test dry run", func(t *testing.T) {
+		p := newProvider()
+		store := openStore(t, p.storageProvider, "store")
+		require.NoError(t, store.Put("k", []byte("v0")))
+
+		m := New(p, WithDryRun(), WithBackup())
+		require.NoError(t, m.Register(
+			putStep("store", 1, "k", "v1"),
+			Step{Store: "store", Version: 2, Migrate: func(s storage.Store) error {
+				// the changes of the previous step are seen
+				v, err := s.Get("k")
+				if err != nil {
+					return err
+				}
+
+				return s.Put("k2", append(v, '+'))
+			}},
+		))
+
+		report, err := m.Run()
+		require.NoError(t, err)
+		require.True(t, report.DryRun)
+		require.Len(t, report.Stores, 1)
+		require.Equal(t, 2, report.Stores[0].ToVersion)
+		require.Empty(t, report.Stores[0].Backup)
+		require.Equal(t, []string{"k2"}, report.Stores[0].Steps[1].Updated)
+
+		requireRecord(t, store, "k", "v0")
+
+		_, err = store.Get("k2")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		version, err := SchemaVersion(store)
+		require.NoError(t, err)
+		require.Equal(t, 0, version)
+
+		_, err = openStore(t, p.storageProvider, "store_backup_v0").Get("k")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test backup", func(t *testing.T) {
+		p := newProvider()
+		store := openStore(t, p.storageProvider, "store")
+		require.NoError(t, store.Put("k", []byte("v1")))
+		require.NoError(t, store.Put(SchemaVersionKey, []byte("1")))
+
+		m := New(p, WithBackup())
+		require.NoError(t, m.Register(putStep("store", 1, "k", "v1"), putStep("store", 2, "k", "v2")))
+		require.NoError(t, m.Register(putStep("empty", 1, "k", "v1")))
+
+		report, err := m.Run()
+		require.NoError(t, err)
+		require.Len(t, report.Stores, 2)
+		require.Equal(t, "empty_backup_v0", report.Stores[0].Backup)
+		require.Equal(t, "store_backup_v1", report.Stores[1].Backup)
+
+		requireRecord(t, store, "k", "v2")
+
+		backup := openStore(t, p.storageProvider, "store_backup_v1")
+		requireRecord(t, backup, "k", "v1")
+		requireRecord(t, backup, SchemaVersionKey, "1")
+	})
+
+	t.Run("test backup and restore tags", func(t *testing.T) {
+		p := newProvider()
+		store := openStore(t, p.storageProvider, "store")
+		require.NoError(t, store.Put("k", []byte("v0"), storage.Tag{Name: "tag", Value: "k"}))
+
+		step := putStep("store", 1, "k2", "v1")
+		step.Tags = func(k string, v []byte) ([]storage.Tag, error) {
+			return []storage.Tag{{Name: "tag", Value: k}}, nil
+		}
+
+		m := New(p, WithBackup())
+		require.NoError(t, m.Register(step))
+
+		_, err := m.Run()
+		require.NoError(t, err)
+		requireRecord(t, store, "k2", "v1")
+		requireQuery(t, openStore(t, p.storageProvider, "store_backup_v0"), "tag:k", "k")
+
+		require.NoError(t, m.Restore("store", false, 0))
+		requireRecord(t, store, "k", "v0")
+		requireQuery(t, store, "tag:k", "k")
+
+		_, err = store.Get("k2")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		version, err := SchemaVersion(store)
+		require.NoError(t, err)
+		require.Equal(t, 0, version)
+	})
+
+	t.Run("test backup tags error", func(t *testing.T) {
+		p := newProvider()
+		require.NoError(t, openStore(t, p.storageProvider, "store").Put("k", []byte("v0")))
+
+		step := putStep("store", 1, "k", "v1")
+		step.Tags = func(string, []byte) ([]storage.Tag, error) {
+			return nil, errors.New("tags error")
+		}
+
+		m := New(p, WithBackup())
+		require.NoError(t, m.Register(step))
+
+		_, err := m.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "backup: tags of record k: tags error")
+	})
+
+	t.Run("test missing step", func(t *testing.T) {
+		m := New(newProvider())
+		require.NoError(t, m.Register(putStep("store", 1, "k", "v1"), putStep("store", 3, "k", "v3")))
+
+		_, err := m.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "migrate store 'store': missing migration step 2")
+	})
+
+	t.Run("test newer schema version", func(t *testing.T) {
+		p := newProvider()
+		require.NoError(t, openStore(t, p.storageProvider, "store").Put(SchemaVersionKey, []byte("2")))
+
+		m := New(p)
+		require.NoError(t, m.Register(putStep("store", 1, "k", "v1")))
+
+		_, err := m.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "schema version 2 is newer than the latest known version 1")
+	})
+
+	t.Run("test step error", func(t *testing.T) {
+		p := newProvider()
+		store := openStore(t, p.storageProvider, "store")
+
+		m := New(p)
+		require.NoError(t, m.Register(
+			putStep("store", 1, "k", "v1"),
+			Step{Store: "store", Version: 2, Migrate: func(s storage.Store) error {
+				if err := s.Put("k", []byte("v2")); err != nil {
+					return err
+				}
+
+				return errors.New("step error")
+			}},
+		))
+
+		_, err := m.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "migration step 2: step error")
+
+		// the store is left at the version of the previous step
+		requireRecord(t, store, "k", "v1")
+		requireRecord(t, store, SchemaVersionKey, "1")
+	})
+
+	t.Run("test store errors", func(t *testing.T) {
+		m := New(&mockProvider{
+			storageProvider: &mockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")},
+		})
+		require.NoError(t, m.Register(putStep("store", 1, "k", "v1")))
+
+		_, err := m.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open store: open error")
+
+		m = New(&mockProvider{storageProvider: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+			Store: make(map[string][]byte), ErrGet: errors.New("get error"),
+		})})
+		require.NoError(t, m.Register(putStep("store", 1, "k", "v1")))
+
+		_, err = m.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get schema version: get error")
+
+		m = New(&mockProvider{storageProvider: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+			Store: make(map[string][]byte), ErrBatch: errors.New("batch error"),
+		})})
+		require.NoError(t, m.Register(putStep("store", 1, "k", "v1")))
+
+		_, err = m.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "apply migration step 1: batch error")
+
+		m = New(&mockProvider{storageProvider: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+			Store: map[string][]byte{"k": []byte("v0")}, ErrItr: errors.New("iterator error"),
+		})}, WithBackup())
+		require.NoError(t, m.Register(putStep("store", 1, "k", "v1")))
+
+		_, err = m.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "backup: read records: iterator error")
+	})
+}
+
+func TestMigrator_Restore(t *testing.T) {
+	t.Run("test restore transient store", func(t *testing.T) {
+		p := newProvider()
+		require.NoError(t, openStore(t, p.transientStorageProvider, "store_backup_v1").Batch([]storage.Operation{
+			storage.PutOperation("k", []byte("v1")),
+			storage.PutOperation(SchemaVersionKey, []byte("1")),
+		}))
+
+		store := openStore(t, p.transientStorageProvider, "store")
+		require.NoError(t, store.Put("k", []byte("v2")))
+		require.NoError(t, store.Put(SchemaVersionKey, []byte("2")))
+
+		require.NoError(t, New(p).Restore("store", true, 1))
+		requireRecord(t, store, "k", "v1")
+		requireRecord(t, store, SchemaVersionKey, "1")
+	})
+
+	t.Run("test no backup", func(t *testing.T) {
+		p := newProvider()
+		require.NoError(t, openStore(t, p.storageProvider, "store").Put("k", []byte("v1")))
+
+		err := New(p).Restore("store", false, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "restore store 'store': no records in backup of version 0")
+
+		requireRecord(t, openStore(t, p.storageProvider, "store"), "k", "v1")
+	})
+
+	t.Run("test errors", func(t *testing.T) {
+		m := New(&mockProvider{
+			storageProvider: &mockstorage.MockStoreProvider{ErrOpenStoreHandle: errors.New("open error")},
+		})
+
+		err := m.Restore("store", false, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "restore store 'store': open store: open error")
+
+		m = New(&mockProvider{storageProvider: &mockstorage.MockStoreProvider{
+			Store:         mockstorage.NewMockStoreProvider().Store,
+			FailNamespace: "store_backup_v0",
+		}})
+
+		err = m.Restore("store", false, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "restore store 'store': open backup store")
+
+		m = New(&mockProvider{storageProvider: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+			Store:  map[string][]byte{"k": []byte("v")},
+			ErrItr: errors.New("iterator error"),
+		})})
+
+		err = m.Restore("store", false, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "restore store 'store': read records: iterator error")
+
+		m = New(&mockProvider{storageProvider: mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{
+			Store:    map[string][]byte{"k": []byte("v")},
+			ErrBatch: errors.New("batch error"),
+		})})
+
+		err = m.Restore("store", false, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "restore store 'store': batch error")
+	})
+}
+
+func TestSchemaVersion(t *testing.T) {
+	store, err := mem.NewProvider().OpenStore("store")
+	require.NoError(t, err)
+
+	version, err := SchemaVersion(store)
+	require.NoError(t, err)
+	require.Equal(t, 0, version)
+
+	require.NoError(t, store.Put(SchemaVersionKey, []byte("12")))
+
+	version, err = SchemaVersion(store)
+	require.NoError(t, err)
+	require.Equal(t, 12, version)
+
+	require.NoError(t, store.Put(SchemaVersionKey, []byte("invalid")))
+
+	_, err = SchemaVersion(store)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid schema version 'invalid'")
+}
+
+type mockProvider struct {
+	storageProvider          storage.Provider
+	transientStorageProvider storage.Provider
+}
+
+func newProvider() *mockProvider {
+	return &mockProvider{storageProvider: mem.NewProvider(), transientStorageProvider: mem.NewProvider()}
+}
+
+func (p *mockProvider) StorageProvider() storage.Provider {
+	return p.storageProvider
+}
+
+func (p *mockProvider) TransientStorageProvider() storage.Provider {
+	return p.transientStorageProvider
+}
+
+func putStep(store string, version int, k, v string) Step {
+	return Step{Store: store, Version: version, Description: "put " + k, Migrate: func(s storage.Store) error {
+		return s.Put(k, []byte(v))
+	}}
+}
+
+func noop(storage.Store) error {
+	return nil
+}
+
+func openStore(t *testing.T, p storage.Provider, name string) storage.Store {
+	t.Helper()
+
+	store, err := p.OpenStore(name)
+	require.NoError(t, err)
+
+	return store
+}
+
+func requireQuery(t *testing.T, store storage.Store, expression string, keys ...string) {
+	t.Helper()
+
+	itr, err := store.Query(expression)
+	require.NoError(t, err)
+
+	defer itr.Release()
+
+	var found []string
+
+	for itr.Next() {
+		found = append(found, string(itr.Key()))
+	}
+
+	require.NoError(t, itr.Error())
+	require.Equal(t, keys, found)
+}
+
+func requireRecord(t *testing.T, store storage.Store, k, v string) {
+	t.Helper()
+
+	value, err := store.Get(k)
+	require.NoError(t, err)
+	require.Equal(t, v, string(value))
+}
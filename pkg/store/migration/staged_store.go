@@ -0,0 +1,253 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migration
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// stagedStore stages the writes to a store: the reads see the staged records on top of the records of the store,
+// which is only written by applying the staged operations.
+type stagedStore struct {
+	store   storage.Store
+	records map[string]*stagedRecord
+	skipped map[string]string
+}
+
+// stagedRecord is a record saved or, if deleted is true, deleted.
+type stagedRecord struct {
+	value   []byte
+	tags    []storage.Tag
+	deleted bool
+}
+
+type record struct {
+	key   string
+	value []byte
+}
+
+func newStagedStore(store storage.Store) *stagedStore {
+	return &stagedStore{store: store, records: make(map[string]*stagedRecord)}
+}
+
+// Put stages the record with its tags.
+func (s *stagedStore) Put(k string, v []byte, tags ...storage.Tag) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	s.records[k] = &stagedRecord{value: append([]byte{}, v...), tags: tags}
+
+	return nil
+}
+
+// Get returns the staged record if any, the record of the store otherwise.
+func (s *stagedStore) Get(k string) ([]byte, error) {
+	if k == "" {
+		return nil, storage.ErrKeyRequired
+	}
+
+	if r, ok := s.records[k]; ok {
+		if r.deleted {
+			return nil, storage.ErrDataNotFound
+		}
+
+		return r.value, nil
+	}
+
+	return s.store.Get(k)
+}
+
+// Iterator returns an iterator over the records of the store in the key range merged with the staged records.
+func (s *stagedStore) Iterator(start, limit string) storage.StoreIterator {
+	itr := s.store.Iterator(start, limit)
+	defer itr.Release()
+
+	limit = strings.ReplaceAll(limit, storage.EndKeySuffix, "~")
+
+	var records []*record
+
+	for itr.Next() {
+		if _, ok := s.records[string(itr.Key())]; !ok {
+			records = append(records, &record{key: string(itr.Key()), value: append([]byte{}, itr.Value()...)})
+		}
+	}
+
+	if itr.Error() != nil {
+		return &recordsIterator{err: itr.Error()}
+	}
+
+	for k, r := range s.records {
+		if !r.deleted && k >= start && k < limit {
+			records = append(records, &record{key: k, value: r.value})
+		}
+	}
+
+	return newRecordsIterator(records)
+}
+
+// Delete stages the deletion of the record.
+func (s *stagedStore) Delete(k string) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	s.records[k] = &stagedRecord{deleted: true}
+
+	return nil
+}
+
+// Query returns an iterator over the records of the store matching the expression merged with the staged records.
+func (s *stagedStore) Query(expression string, opts ...storage.QueryOption) (storage.StoreIterator, error) {
+	query, err := storage.ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	itr, err := s.store.Query(expression, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer itr.Release()
+
+	var records []*record
+
+	for itr.Next() {
+		if _, ok := s.records[string(itr.Key())]; !ok {
+			records = append(records, &record{key: string(itr.Key()), value: append([]byte{}, itr.Value()...)})
+		}
+	}
+
+	if itr.Error() != nil {
+		return nil, itr.Error()
+	}
+
+	for k, r := range s.records {
+		if !r.deleted && query.Match(r.tags) {
+			records = append(records, &record{key: k, value: r.value})
+		}
+	}
+
+	return newRecordsIterator(records), nil
+}
+
+// Batch stages the operations.
+func (s *stagedStore) Batch(operations []storage.Operation) error {
+	for _, op := range operations {
+		if op.Key == "" {
+			return storage.ErrKeyRequired
+		}
+	}
+
+	for i := range operations {
+		if operations[i].IsDelete() {
+			s.records[operations[i].Key] = &stagedRecord{deleted: true}
+		} else {
+			s.records[operations[i].Key] = &stagedRecord{value: operations[i].Value, tags: operations[i].Tags}
+		}
+	}
+
+	return nil
+}
+
+// changes returns the keys of the staged records saved and deleted, ordered.
+func (s *stagedStore) changes() ([]string, []string) {
+	var updated, deleted []string
+
+	for k, r := range s.records {
+		if r.deleted {
+			deleted = append(deleted, k)
+		} else {
+			updated = append(updated, k)
+		}
+	}
+
+	sort.Strings(updated)
+	sort.Strings(deleted)
+
+	return updated, deleted
+}
+
+// operations returns the operations applying the staged records to the store.
+func (s *stagedStore) operations() []storage.Operation {
+	operations := make([]storage.Operation, 0, len(s.records))
+
+	for k, r := range s.records {
+		if r.deleted {
+			operations = append(operations, storage.DeleteOperation(k))
+		} else {
+			operations = append(operations, storage.PutOperation(k, r.value, r.tags...))
+		}
+	}
+
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].Key < operations[j].Key
+	})
+
+	return operations
+}
+
+// recordsIterator iterates over records ordered by key.
+type recordsIterator struct {
+	records []*record
+	current int
+	err     error
+}
+
+func newRecordsIterator(records []*record) *recordsIterator {
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].key < records[j].key
+	})
+
+	return &recordsIterator{records: records}
+}
+
+// Next moves the iterator to the next record.
+func (i *recordsIterator) Next() bool {
+	if i.current >= len(i.records) {
+		i.records = nil
+
+		return false
+	}
+
+	i.current++
+
+	return true
+}
+
+// Release releases the records.
+func (i *recordsIterator) Release() {
+	i.records = nil
+	i.current = 0
+}
+
+// Error returns the error of the iterator.
+func (i *recordsIterator) Error() error {
+	return i.err
+}
+
+// Key returns the key of the current record.
+func (i *recordsIterator) Key() []byte {
+	if i.current == 0 || i.current > len(i.records) {
+		return nil
+	}
+
+	return []byte(i.records[i.current-1].key)
+}
+
+// Value returns the value of the current record.
+func (i *recordsIterator) Value() []byte {
+	if i.current == 0 || i.current > len(i.records) {
+		return nil
+	}
+
+	return i.records[i.current-1].value
+}
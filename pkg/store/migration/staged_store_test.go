@@ -0,0 +1,140 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestStagedStore(t *testing.T) {
+	newStores := func(t *testing.T) (storage.Store, *stagedStore) {
+		store, err := mem.NewProvider().OpenStore("store")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("a1", []byte("a1"), storage.Tag{Name: "a", Value: "1"}))
+		require.NoError(t, store.Put("a2", []byte("a2"), storage.Tag{Name: "a", Value: "2"}))
+		require.NoError(t, store.Put("b1", []byte("b1"), storage.Tag{Name: "b"}))
+
+		return store, newStagedStore(store)
+	}
+
+	t.Run("test put, get and delete", func(t *testing.T) {
+		store, staged := newStores(t)
+
+		require.NoError(t, staged.Put("a1", []byte("new a1")))
+		require.NoError(t, staged.Put("c1", []byte("c1")))
+		require.NoError(t, staged.Delete("b1"))
+
+		requireRecord(t, staged, "a1", "new a1")
+		requireRecord(t, staged, "a2", "a2")
+		requireRecord(t, staged, "c1", "c1")
+
+		_, err := staged.Get("b1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		// the store is unchanged
+		requireRecord(t, store, "a1", "a1")
+		requireRecord(t, store, "b1", "b1")
+
+		_, err = store.Get("c1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		updated, deleted := staged.changes()
+		require.Equal(t, []string{"a1", "c1"}, updated)
+		require.Equal(t, []string{"b1"}, deleted)
+
+		require.NoError(t, store.Batch(staged.operations()))
+		requireRecord(t, store, "a1", "new a1")
+		requireRecord(t, store, "c1", "c1")
+
+		_, err = store.Get("b1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test empty key", func(t *testing.T) {
+		_, staged := newStores(t)
+
+		require.True(t, errors.Is(staged.Put("", []byte("v")), storage.ErrKeyRequired))
+		require.True(t, errors.Is(staged.Delete(""), storage.ErrKeyRequired))
+
+		_, err := staged.Get("")
+		require.True(t, errors.Is(err, storage.ErrKeyRequired))
+
+		err = staged.Batch([]storage.Operation{storage.PutOperation("k", []byte("v")), storage.DeleteOperation("")})
+		require.True(t, errors.Is(err, storage.ErrKeyRequired))
+
+		// the batch isn't applied
+		_, err = staged.Get("k")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test iterator", func(t *testing.T) {
+		_, staged := newStores(t)
+
+		require.NoError(t, staged.Batch([]storage.Operation{
+			storage.PutOperation("a0", []byte("a0")),
+			storage.DeleteOperation("a1"),
+			storage.PutOperation("a2", []byte("new a2")),
+			storage.PutOperation("b0", []byte("b0")),
+		}))
+
+		require.Equal(t, []string{"a0=a0", "a2=new a2"}, readAll(t, staged.Iterator("a", "a"+storage.EndKeySuffix)))
+		require.Equal(t, []string{"a0=a0", "a2=new a2", "b0=b0"}, readAll(t, staged.Iterator("a", "b1")))
+
+		itr := newStagedStore(&mockstorage.MockStore{ErrItr: errors.New("iterator error")}).Iterator("", "")
+		require.False(t, itr.Next())
+		require.EqualError(t, itr.Error(), "iterator error")
+		require.Nil(t, itr.Key())
+		require.Nil(t, itr.Value())
+		itr.Release()
+	})
+
+	t.Run("test query", func(t *testing.T) {
+		_, staged := newStores(t)
+
+		require.NoError(t, staged.Put("a0", []byte("a0"), storage.Tag{Name: "a", Value: "1"}))
+		require.NoError(t, staged.Put("a2", []byte("new a2")))
+		require.NoError(t, staged.Put("b0", []byte("b0"), storage.Tag{Name: "b"}))
+
+		itr, err := staged.Query("a")
+		require.NoError(t, err)
+		require.Equal(t, []string{"a0=a0", "a1=a1"}, readAll(t, itr))
+
+		itr, err = staged.Query("b")
+		require.NoError(t, err)
+		require.Equal(t, []string{"b0=b0", "b1=b1"}, readAll(t, itr))
+
+		_, err = staged.Query("")
+		require.True(t, errors.Is(err, storage.ErrInvalidQuery))
+
+		_, err = newStagedStore(&mockstorage.MockStore{ErrQuery: errors.New("query error")}).Query("a")
+		require.EqualError(t, err, "query error")
+	})
+}
+
+func readAll(t *testing.T, itr storage.StoreIterator) []string {
+	t.Helper()
+
+	defer itr.Release()
+
+	var records []string
+
+	for itr.Next() {
+		records = append(records, string(itr.Key())+"="+string(itr.Value()))
+	}
+
+	require.NoError(t, itr.Error())
+
+	return records
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/migration"
 )
 
 const (
@@ -257,6 +258,93 @@ func (s *StoreImplementation) getAllRecords(searchKey string, keyPrefix func(str
 	return records, nil
 }
 
+// Migrations returns the migration steps of the vc store.
+func Migrations() []migration.Step {
+	return []migration.Step{{
+		Store:       NameSpace,
+		Version:     1,
+		Description: "save the fields of interest of the credentials and presentations in their name records",
+		Migrate:     migrateNameRecords,
+	}}
+}
+
+// migrateNameRecords replaces the name records holding only the ID of the credential or presentation, saved by
+// previous versions, with the records of the fields of interest read from the credential or presentation. The
+// records of the credentials and presentations which can't be parsed are skipped and keep only the ID.
+func migrateNameRecords(store storage.Store) error {
+	err := migrateLegacyNameRecords(store, credentialNameKey, func(vcBytes []byte) (string, []string, []string, error) {
+		vc, err := verifiable.ParseUnverifiedCredential(vcBytes)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("new credential failed: %w", err)
+		}
+
+		return getVCSubjectID(vc), vc.Context, vc.Types, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return migrateLegacyNameRecords(store, presentationNameKey, func(vpBytes []byte) (string, []string, []string, error) {
+		vp, err := verifiable.ParsePresentation(vpBytes, verifiable.WithDisabledPresentationProofCheck())
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("new presentation failed: %w", err)
+		}
+
+		return vp.Holder, vp.Context, vp.Type, nil
+	})
+}
+
+func migrateLegacyNameRecords(store storage.Store, keyPrefix string,
+	fields func([]byte) (string, []string, []string, error)) error {
+	itr := store.Iterator(keyPrefix, fmt.Sprintf(limitPattern, keyPrefix))
+	defer itr.Release()
+
+	// the legacy records hold the ID as is, it isn't a JSON object
+	legacyRecords := make(map[string]string)
+
+	for itr.Next() {
+		var r record
+
+		if json.Unmarshal(itr.Value(), &r) != nil {
+			legacyRecords[string(itr.Key())] = string(itr.Value())
+		}
+	}
+
+	if err := itr.Error(); err != nil {
+		return fmt.Errorf("failed to read name records: %w", err)
+	}
+
+	for k, id := range legacyRecords {
+		var subjectID string
+
+		var contexts, types []string
+
+		data, err := store.Get(id)
+		if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+			return fmt.Errorf("failed to get %s: %w", id, err)
+		}
+
+		if err == nil {
+			subjectID, contexts, types, err = fields(data)
+			if err != nil {
+				// the name record keeps only the ID, like the records of the IDs not found
+				migration.Skip(store, k, fmt.Errorf("failed to read %s: %w", id, err))
+			}
+		}
+
+		recordBytes, err := getRecord(id, subjectID, contexts, types)
+		if err != nil {
+			return err
+		}
+
+		if err := store.Put(k, recordBytes); err != nil {
+			return fmt.Errorf("failed to put name record: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func getVCSubjectID(vc *verifiable.Credential) string {
 	if subject, ok := vc.Subject.(map[string]interface{}); ok {
 		if s, ok := subject["id"].(string); ok {
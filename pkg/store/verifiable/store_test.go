@@ -16,6 +16,8 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
 	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/store/migration"
 )
 
 const sampleCredentialName = "sampleVCName"
@@ -669,3 +671,96 @@ func TestGetPresentations(t *testing.T) {
 		require.Equal(t, records[0].SubjectID, udVP.Holder)
 	})
 }
+
+func TestMigrations(t *testing.T) {
+	t.Run("test migrate name records saved with the ID", func(t *testing.T) {
+		storeProvider := mem.NewProvider()
+		store, err := storeProvider.OpenStore(NameSpace)
+		require.NoError(t, err)
+
+		vc, err := verifiable.ParseUnverifiedCredential([]byte(udCredential))
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put(vc.ID, []byte(udCredential)))
+		require.NoError(t, store.Put(credentialNameDataKey(sampleCredentialName), []byte(vc.ID)))
+		require.NoError(t, store.Put(presentationNameDataKey(samplePresentationName), []byte(samplePresentationID)))
+
+		s, err := New(&mockprovider.Provider{StorageProviderValue: storeProvider})
+		require.NoError(t, err)
+		require.NoError(t, s.SavePresentation("migrated", &verifiable.Presentation{ID: "migrated"}))
+
+		migrator := migration.New(&mockprovider.Provider{StorageProviderValue: storeProvider})
+		require.NoError(t, migrator.Register(Migrations()...))
+
+		report, err := migrator.Run()
+		require.NoError(t, err)
+		require.Len(t, report.Stores, 1)
+		require.Equal(t, []string{
+			credentialNameDataKey(sampleCredentialName),
+			presentationNameDataKey(samplePresentationName),
+		}, report.Stores[0].Steps[0].Updated)
+
+		credentials, err := s.GetCredentials()
+		require.NoError(t, err)
+		require.Equal(t, []*Record{{
+			Name:      sampleCredentialName,
+			ID:        vc.ID,
+			Context:   vc.Context,
+			Type:      vc.Types,
+			SubjectID: "did:example:ebfeb1f712ebc6f1c276e12ec21",
+		}}, credentials)
+
+		// the presentation of the name record isn't in the store
+		id, err := s.GetPresentationIDByName(samplePresentationName)
+		require.NoError(t, err)
+		require.Equal(t, samplePresentationID, id)
+	})
+
+	t.Run("test migrate invalid credential is skipped", func(t *testing.T) {
+		storeProvider := mem.NewProvider()
+		store, err := storeProvider.OpenStore(NameSpace)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put(sampleCredentialID, []byte("invalid")))
+		require.NoError(t, store.Put(credentialNameDataKey(sampleCredentialName), []byte(sampleCredentialID)))
+
+		migrator := migration.New(&mockprovider.Provider{StorageProviderValue: storeProvider})
+		require.NoError(t, migrator.Register(Migrations()...))
+
+		report, err := migrator.Run()
+		require.NoError(t, err)
+		require.Len(t, report.Stores, 1)
+		require.Contains(t, report.Stores[0].Steps[0].Skipped[credentialNameDataKey(sampleCredentialName)], "failed to read sampleVCID: new credential failed")
+
+		s, err := New(&mockprovider.Provider{StorageProviderValue: storeProvider})
+		require.NoError(t, err)
+
+		id, err := s.GetCredentialIDByName(sampleCredentialName)
+		require.NoError(t, err)
+		require.Equal(t, sampleCredentialID, id)
+	})
+
+	t.Run("test migrate invalid presentation is skipped", func(t *testing.T) {
+		storeProvider := mem.NewProvider()
+		store, err := storeProvider.OpenStore(NameSpace)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put(samplePresentationID, []byte("invalid")))
+		require.NoError(t, store.Put(presentationNameDataKey(samplePresentationName), []byte(samplePresentationID)))
+
+		migrator := migration.New(&mockprovider.Provider{StorageProviderValue: storeProvider})
+		require.NoError(t, migrator.Register(Migrations()...))
+
+		report, err := migrator.Run()
+		require.NoError(t, err)
+		require.Len(t, report.Stores, 1)
+		require.Contains(t, report.Stores[0].Steps[0].Skipped[presentationNameDataKey(samplePresentationName)], "failed to read sampleVPID: new presentation failed")
+
+		s, err := New(&mockprovider.Provider{StorageProviderValue: storeProvider})
+		require.NoError(t, err)
+
+		id, err := s.GetPresentationIDByName(samplePresentationName)
+		require.NoError(t, err)
+		require.Equal(t, samplePresentationID, id)
+	})
+}
@@ -7,39 +7,130 @@ SPDX-License-Identifier: Apache-2.0
 package key
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcutil/base58"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 )
 
 const (
-	schemaV1                   = "https://w3id.org/did/v1"
-	ed25519VerificationKey2018 = "Ed25519VerificationKey2018"
-	x25519KeyAgreementKey2019  = "X25519KeyAgreementKey2019"
+	schemaV1                          = "https://w3id.org/did/v1"
+	ed25519VerificationKey2018        = "Ed25519VerificationKey2018"
+	x25519KeyAgreementKey2019         = "X25519KeyAgreementKey2019"
+	ecdsaSecp256k1VerificationKey2019 = "EcdsaSecp256k1VerificationKey2019"
+	jsonWebKey2020                    = "JsonWebKey2020"
 )
 
 const (
-	ed25519pub = 0xed // Ed25519 public key in multicodec table
-	x25519pub  = 0xec // Curve25519 public key in multicodec table
+	ed25519pub   = 0xed   // Ed25519 public key in multicodec table
+	x25519pub    = 0xec   // Curve25519 public key in multicodec table
+	secp256k1pub = 0xe7   // Secp256k1 public key in multicodec table
+	p256pub      = 0x1200 // P-256 public key in multicodec table
+	p384pub      = 0x1201 // P-384 public key in multicodec table
 )
 
 // Build builds new DID document.
+// Supported public key types are Ed25519VerificationKey2018, X25519KeyAgreementKey2019,
+// EcdsaSecp256k1VerificationKey2019 and JsonWebKey2020 (P-256 or P-384 curve, detected from the key size).
+// EC public keys can be passed either in compressed or in uncompressed form.
+// A did:key is derived from a single public key: if a key agreement key is set with vdriapi.WithKeyAgreement(), the
+// DID document is built from the key agreement key (X25519KeyAgreementKey2019 or JsonWebKey2020) instead of pubKey.
 func (v *VDRI) Build(pubKey *vdriapi.PubKey, opts ...vdriapi.DocOpts) (*did.Doc, error) {
-	if pubKey.Type != ed25519VerificationKey2018 {
+	docOpts := &vdriapi.CreateDIDOpts{}
+
+	for _, opt := range opts {
+		opt(docOpts)
+	}
+
+	if docOpts.KeyAgreement != nil {
+		return buildKeyAgreementDoc(docOpts.KeyAgreement)
+	}
+
+	pubKeyValue := base58.Decode(pubKey.Value)
+
+	switch pubKey.Type {
+	case ed25519VerificationKey2018:
+		return createDoc(ed25519pub, pubKeyValue)
+	case x25519KeyAgreementKey2019:
+		return createDoc(x25519pub, pubKeyValue)
+	case ecdsaSecp256k1VerificationKey2019:
+		return buildECDoc(secp256k1pub, pubKeyValue)
+	case jsonWebKey2020:
+		code, err := nistCurveCode(pubKeyValue)
+		if err != nil {
+			return nil, err
+		}
+
+		return buildECDoc(code, pubKeyValue)
+	default:
 		return nil, fmt.Errorf("not supported public key type: %s", pubKey.Type)
 	}
+}
+
+// buildKeyAgreementDoc creates DID doc for a key agreement key.
+func buildKeyAgreementDoc(pubKey *vdriapi.PubKey) (*did.Doc, error) {
+	pubKeyValue := base58.Decode(pubKey.Value)
+
+	switch pubKey.Type {
+	case x25519KeyAgreementKey2019:
+		return createDoc(x25519pub, pubKeyValue)
+	case jsonWebKey2020:
+		code, err := nistCurveCode(pubKeyValue)
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err := buildECDoc(code, pubKeyValue)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.KeyAgreement = []did.VerificationMethod{
+			*did.NewReferencedVerificationMethod(&doc.PublicKey[0], did.KeyAgreement, false),
+		}
 
-	return createDoc(base58.Decode(pubKey.Value))
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("not supported key agreement key type: %s", pubKey.Type)
+	}
+}
+
+// buildECDoc converts EC public key to its compressed form (used in did:key fingerprint) and creates DID doc.
+func buildECDoc(code uint64, pubKeyValue []byte) (*did.Doc, error) {
+	pubKey, err := unmarshalECPublicKey(code, pubKeyValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return createDoc(code, elliptic.MarshalCompressed(pubKey.Curve, pubKey.X, pubKey.Y))
+}
+
+// createDoc creates DID doc of did:key method for a public key of a given multicodec type.
+func createDoc(code uint64, pubKeyValue []byte) (*did.Doc, error) {
+	switch code {
+	case ed25519pub:
+		return createEd25519Doc(pubKeyValue)
+	case x25519pub:
+		return createX25519Doc(pubKeyValue)
+	case secp256k1pub, p256pub, p384pub:
+		return createECDoc(code, pubKeyValue)
+	default:
+		return nil, fmt.Errorf("not supported public key (multicodec code: %#x)", code)
+	}
 }
 
 //nolint:lll
-func createDoc(pubKeyValue []byte) (*did.Doc, error) {
+func createEd25519Doc(pubKeyValue []byte) (*did.Doc, error) {
 	methodID := keyFingerprint(multicodec(ed25519pub), pubKeyValue)
 	didKey := fmt.Sprintf("did:key:%s", methodID)
 	keyID := fmt.Sprintf("%s#%s", didKey, methodID)
@@ -68,6 +159,74 @@ func createDoc(pubKeyValue []byte) (*did.Doc, error) {
 	}, nil
 }
 
+// createX25519Doc creates DID doc for a raw X25519 key, which can be used for key agreement only.
+func createX25519Doc(pubKeyValue []byte) (*did.Doc, error) {
+	if len(pubKeyValue) != cryptoutil.Curve25519KeySize {
+		return nil, errors.New("invalid X25519 public key size")
+	}
+
+	methodID := keyFingerprint(multicodec(x25519pub), pubKeyValue)
+	didKey := fmt.Sprintf("did:key:%s", methodID)
+	keyID := fmt.Sprintf("%s#%s", didKey, methodID)
+
+	pubKey := did.NewPublicKeyFromBytes(keyID, x25519KeyAgreementKey2019, didKey, pubKeyValue)
+
+	// Created/Updated time
+	t := time.Now()
+
+	return &did.Doc{
+		Context:      []string{schemaV1},
+		ID:           didKey,
+		PublicKey:    []did.PublicKey{*pubKey},
+		KeyAgreement: []did.VerificationMethod{*did.NewReferencedVerificationMethod(pubKey, did.KeyAgreement, false)},
+		Created:      &t,
+		Updated:      &t,
+	}, nil
+}
+
+//nolint:lll
+func createECDoc(code uint64, pubKeyValue []byte) (*did.Doc, error) {
+	// compressed secp256k1, P-256 or P-384 public key is put into DID doc as JSON Web Key
+	ecPubKey, err := unmarshalECPublicKey(code, pubKeyValue)
+	if err != nil {
+		return nil, err
+	}
+
+	jwk, err := jose.JWKFromPublicKey(ecPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	methodID := keyFingerprint(multicodec(code), pubKeyValue)
+	didKey := fmt.Sprintf("did:key:%s", methodID)
+	keyID := fmt.Sprintf("%s#%s", didKey, methodID)
+
+	keyType := jsonWebKey2020
+	if code == secp256k1pub {
+		keyType = ecdsaSecp256k1VerificationKey2019
+	}
+
+	pubKey, err := did.NewPublicKeyFromJWK(keyID, keyType, didKey, jwk)
+	if err != nil {
+		return nil, err
+	}
+
+	// Created/Updated time
+	t := time.Now()
+
+	return &did.Doc{
+		Context:              []string{schemaV1},
+		ID:                   didKey,
+		PublicKey:            []did.PublicKey{*pubKey},
+		Authentication:       []did.VerificationMethod{*did.NewReferencedVerificationMethod(pubKey, did.Authentication, false)},
+		AssertionMethod:      []did.VerificationMethod{*did.NewReferencedVerificationMethod(pubKey, did.AssertionMethod, false)},
+		CapabilityDelegation: []did.VerificationMethod{*did.NewReferencedVerificationMethod(pubKey, did.CapabilityDelegation, false)},
+		CapabilityInvocation: []did.VerificationMethod{*did.NewReferencedVerificationMethod(pubKey, did.CapabilityInvocation, false)},
+		Created:              &t,
+		Updated:              &t,
+	}, nil
+}
+
 func keyFingerprint(multicodecValue, pubKeyValue []byte) string {
 	mcLength := len(multicodecValue)
 	buf := make([]uint8, mcLength+len(pubKeyValue))
@@ -91,8 +250,67 @@ func keyAgreement(didKey string, ed25519PubKey []byte) (*did.PublicKey, error) {
 }
 
 func multicodec(code uint64) []byte {
-	buf := make([]byte, 2)
-	binary.PutUvarint(buf, code)
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, code)
+
+	return buf[:n]
+}
+
+func curve(code uint64) (elliptic.Curve, error) {
+	switch code {
+	case secp256k1pub:
+		return btcec.S256(), nil
+	case p256pub:
+		return elliptic.P256(), nil
+	case p384pub:
+		return elliptic.P384(), nil
+	default:
+		return nil, fmt.Errorf("not supported EC public key (multicodec code: %#x)", code)
+	}
+}
+
+// nistCurveCode detects NIST curve of compressed or uncompressed EC public key by its size.
+func nistCurveCode(pubKeyValue []byte) (uint64, error) {
+	for _, code := range []uint64{p256pub, p384pub} {
+		c, err := curve(code)
+		if err != nil {
+			return 0, err
+		}
+
+		byteLen := (c.Params().BitSize + 7) / 8 //nolint:gomnd
+
+		if len(pubKeyValue) == 1+byteLen || len(pubKeyValue) == 1+2*byteLen {
+			return code, nil
+		}
+	}
+
+	return 0, errors.New("not supported JsonWebKey2020 public key: expected P-256 or P-384 EC key")
+}
+
+// unmarshalECPublicKey parses compressed or uncompressed EC public key.
+func unmarshalECPublicKey(code uint64, pubKeyValue []byte) (*ecdsa.PublicKey, error) {
+	c, err := curve(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if code == secp256k1pub {
+		pubKey, e := btcec.ParsePubKey(pubKeyValue, btcec.S256())
+		if e != nil {
+			return nil, fmt.Errorf("invalid secp256k1 public key: %w", e)
+		}
+
+		return pubKey.ToECDSA(), nil
+	}
+
+	x, y := elliptic.UnmarshalCompressed(c, pubKeyValue)
+	if x == nil {
+		x, y = elliptic.Unmarshal(c, pubKeyValue)
+	}
+
+	if x == nil {
+		return nil, fmt.Errorf("invalid %s public key", c.Params().Name)
+	}
 
-	return buf
+	return &ecdsa.PublicKey{Curve: c, X: x, Y: y}, nil
 }
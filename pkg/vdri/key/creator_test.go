@@ -7,8 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package key
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
 	"testing"
 
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/stretchr/testify/require"
 
@@ -21,6 +26,8 @@ const (
 	didKeyID       = "did:key:z6MkpTHR8VNsBxYAAWHut2Geadd9jSwuBV8xRoAnwWsdvktH#z6MkpTHR8VNsBxYAAWHut2Geadd9jSwuBV8xRoAnwWsdvktH" //nolint:lll
 	agreementKeyID = "did:key:z6MkpTHR8VNsBxYAAWHut2Geadd9jSwuBV8xRoAnwWsdvktH#z6LSbysY2xFMRpGMhb7tFTLMpeuPRaqaWM1yECx2AtzE3KCc" //nolint:lll
 
+	x25519DIDKey = "did:key:z6LSbysY2xFMRpGMhb7tFTLMpeuPRaqaWM1yECx2AtzE3KCc"
+
 	pubKeyBase58       = "B12NYF8RrR3h41TDCTJojY59usg3mbtbjnFs7Eud1Y6u"
 	keyAgreementBase58 = "JhNWeSVLMYccCk7iopQW4guaSJTojqpMEELgSLhKwRr"
 )
@@ -70,6 +77,116 @@ func TestBuild(t *testing.T) {
 
 		assertDoc(t, doc)
 	})
+
+	t.Run("build with X25519 key", func(t *testing.T) {
+		v := New()
+
+		pubKey := &vdriapi.PubKey{
+			Type:  x25519KeyAgreementKey2019,
+			Value: keyAgreementBase58,
+		}
+
+		doc, err := v.Build(pubKey)
+		require.NoError(t, err)
+		require.NotNil(t, doc)
+		require.Equal(t, x25519DIDKey, doc.ID)
+		require.Len(t, doc.KeyAgreement, 1)
+		require.Empty(t, doc.AssertionMethod)
+
+		_, err = v.Build(&vdriapi.PubKey{Type: x25519KeyAgreementKey2019, Value: base58.Encode([]byte("short"))})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid X25519 public key size")
+	})
+
+	t.Run("build with key agreement key", func(t *testing.T) {
+		v := New()
+
+		doc, err := v.Build(&vdriapi.PubKey{Type: ed25519VerificationKey2018, Value: pubKeyBase58},
+			vdriapi.WithKeyAgreement(&vdriapi.PubKey{Type: x25519KeyAgreementKey2019, Value: keyAgreementBase58}))
+		require.NoError(t, err)
+		require.Equal(t, x25519DIDKey, doc.ID)
+
+		privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		doc, err = v.Build(nil, vdriapi.WithKeyAgreement(&vdriapi.PubKey{
+			Type:  jsonWebKey2020,
+			Value: base58.Encode(elliptic.Marshal(elliptic.P256(), privKey.X, privKey.Y)),
+		}))
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(doc.ID, "did:key:zDn"))
+		require.Len(t, doc.KeyAgreement, 1)
+		require.Equal(t, doc.PublicKey[0].ID, doc.KeyAgreement[0].PublicKey.ID)
+
+		_, err = v.Build(nil, vdriapi.WithKeyAgreement(&vdriapi.PubKey{Type: jsonWebKey2020, Value: "abc"}))
+		require.Error(t, err)
+
+		_, err = v.Build(nil, vdriapi.WithKeyAgreement(&vdriapi.PubKey{Type: ed25519VerificationKey2018}))
+		require.EqualError(t, err, "not supported key agreement key type: Ed25519VerificationKey2018")
+	})
+
+	t.Run("build with EC keys", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			curve   elliptic.Curve
+			keyType string
+			prefix  string
+		}{
+			{name: "secp256k1", curve: btcec.S256(), keyType: ecdsaSecp256k1VerificationKey2019, prefix: "did:key:zQ3s"},
+			{name: "P-256", curve: elliptic.P256(), keyType: jsonWebKey2020, prefix: "did:key:zDn"},
+			{name: "P-384", curve: elliptic.P384(), keyType: jsonWebKey2020, prefix: "did:key:z82"},
+		}
+
+		for _, tc := range tests {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				privKey, err := ecdsa.GenerateKey(tc.curve, rand.Reader)
+				require.NoError(t, err)
+
+				v := New()
+
+				compressed := elliptic.MarshalCompressed(tc.curve, privKey.X, privKey.Y)
+				uncompressed := elliptic.Marshal(tc.curve, privKey.X, privKey.Y)
+
+				doc, err := v.Build(&vdriapi.PubKey{Type: tc.keyType, Value: base58.Encode(compressed)})
+				require.NoError(t, err)
+				require.True(t, strings.HasPrefix(doc.ID, tc.prefix))
+				require.Equal(t, tc.keyType, doc.PublicKey[0].Type)
+
+				jwk := doc.PublicKey[0].JSONWebKey()
+				require.NotNil(t, jwk)
+
+				ecPubKey, ok := jwk.Key.(*ecdsa.PublicKey)
+				require.True(t, ok)
+				require.Equal(t, privKey.X, ecPubKey.X)
+				require.Equal(t, privKey.Y, ecPubKey.Y)
+
+				docFromUncompressed, err := v.Build(&vdriapi.PubKey{Type: tc.keyType, Value: base58.Encode(uncompressed)})
+				require.NoError(t, err)
+				require.Equal(t, doc.ID, docFromUncompressed.ID)
+
+				resolvedDoc, err := v.Read(doc.ID)
+				require.NoError(t, err)
+				require.Equal(t, doc.PublicKey[0].ID, resolvedDoc.PublicKey[0].ID)
+			})
+		}
+	})
+
+	t.Run("build with invalid EC keys", func(t *testing.T) {
+		v := New()
+
+		_, err := v.Build(&vdriapi.PubKey{Type: jsonWebKey2020, Value: base58.Encode([]byte("invalid"))})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expected P-256 or P-384 EC key")
+
+		_, err = v.Build(&vdriapi.PubKey{Type: jsonWebKey2020, Value: base58.Encode(make([]byte, 33))})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid P-256 public key")
+
+		_, err = v.Build(&vdriapi.PubKey{Type: ecdsaSecp256k1VerificationKey2019, Value: base58.Encode(make([]byte, 33))})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid secp256k1 public key")
+	})
 }
 
 func assertDoc(t *testing.T, doc *did.Doc) {
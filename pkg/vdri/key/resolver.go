@@ -7,7 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package key
 
 import (
-	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"regexp"
 
@@ -28,26 +29,33 @@ func (v *VDRI) Read(didKey string, opts ...vdriapi.ResolveOpts) (*did.Doc, error
 		return nil, fmt.Errorf("invalid did:key method ID: %s", parsed.MethodSpecificID)
 	}
 
-	pubKey, err := pubKeyFromFingerprint(parsed.MethodSpecificID)
+	code, pubKey, err := pubKeyFromFingerprint(parsed.MethodSpecificID)
 	if err != nil {
 		return nil, err
 	}
 
-	return createDoc(pubKey)
+	return createDoc(code, pubKey)
 }
 
 func isValidMethodID(id string) bool {
-	r := regexp.MustCompile(`(z)([1-9a-km-zA-HJ-NP-Z]{46})`)
+	r := regexp.MustCompile(`^(z)([1-9a-km-zA-HJ-NP-Z]{46,})$`)
 	return r.MatchString(id)
 }
 
-func pubKeyFromFingerprint(fingerprint string) ([]byte, error) {
+func pubKeyFromFingerprint(fingerprint string) (uint64, []byte, error) {
 	// did:key:MULTIBASE(base58-btc, MULTICODEC(public-key-type, raw-public-key-bytes))
 	// https://w3c-ccg.github.io/did-method-key/#format
 	mc := base58.Decode(fingerprint[1:]) // skip leading "z"
-	if !bytes.Equal(multicodec(ed25519pub), mc[:2]) {
-		return nil, fmt.Errorf("not supported public key (multicodec code: %#x)", mc[0])
+
+	code, br := binary.Uvarint(mc)
+	if br <= 0 {
+		return 0, nil, errors.New("invalid did:key multicodec value")
 	}
 
-	return mc[2:], nil
+	switch code {
+	case ed25519pub, x25519pub, secp256k1pub, p256pub, p384pub:
+		return code, mc[br:], nil
+	default:
+		return 0, nil, fmt.Errorf("not supported public key (multicodec code: %#x)", code)
+	}
 }
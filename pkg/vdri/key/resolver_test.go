@@ -9,7 +9,10 @@ package key
 import (
 	"testing"
 
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 )
 
 func TestRead(t *testing.T) {
@@ -34,9 +37,11 @@ func TestRead(t *testing.T) {
 	t.Run("validate not supported public key", func(t *testing.T) {
 		v := New()
 
-		doc, err := v.Read("did:key:z6LSbysY2xFMRpGMhb7tFTLMpeuPRaqaWM1yECx2AtzE3KCc")
+		methodID := keyFingerprint(multicodec(0x1205), make([]byte, 256)) // RSA public key
+
+		doc, err := v.Read("did:key:" + methodID)
 		require.Error(t, err)
-		require.Contains(t, err.Error(), "not supported public key (multicodec code: 0xec)") // Curve25519 public key
+		require.Contains(t, err.Error(), "not supported public key (multicodec code: 0x1205)")
 		require.Nil(t, doc)
 	})
 
@@ -50,4 +55,89 @@ func TestRead(t *testing.T) {
 
 		assertDoc(t, doc)
 	})
+
+	t.Run("resolve X25519 key", func(t *testing.T) {
+		v := New()
+
+		doc, err := v.Read(x25519DIDKey)
+		require.NoError(t, err)
+		require.NotNil(t, doc)
+
+		require.Equal(t, x25519DIDKey, doc.ID)
+		require.Len(t, doc.PublicKey, 1)
+		require.Equal(t, x25519KeyAgreementKey2019, doc.PublicKey[0].Type)
+		require.Equal(t, base58.Decode(keyAgreementBase58), doc.PublicKey[0].Value)
+		require.Len(t, doc.KeyAgreement, 1)
+		require.False(t, doc.KeyAgreement[0].Embedded)
+		require.Empty(t, doc.Authentication)
+	})
+
+	t.Run("resolve EC keys", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			didKey  string
+			keyType string
+			crv     string
+		}{
+			{
+				name:    "secp256k1",
+				didKey:  "did:key:zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme",
+				keyType: ecdsaSecp256k1VerificationKey2019,
+				crv:     "secp256k1",
+			},
+			{
+				name:    "P-256",
+				didKey:  "did:key:zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169",
+				keyType: jsonWebKey2020,
+				crv:     "P-256",
+			},
+			{
+				name:    "P-384",
+				didKey:  "did:key:z82Lm1MpAkeJcix9K8TMiLd5NMAhnwkjjCBeWHXyu3U4oT2MVJJKXkcVBgjGhnLBn2Kaau9",
+				keyType: jsonWebKey2020,
+				crv:     "P-384",
+			},
+		}
+
+		for _, tc := range tests {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				v := New()
+
+				doc, err := v.Read(tc.didKey)
+				require.NoError(t, err)
+				require.NotNil(t, doc)
+
+				require.Equal(t, tc.didKey, doc.ID)
+				require.Len(t, doc.PublicKey, 1)
+				require.Equal(t, tc.keyType, doc.PublicKey[0].Type)
+
+				jwk := doc.PublicKey[0].JSONWebKey()
+				require.NotNil(t, jwk)
+				require.Equal(t, "EC", jwk.Kty)
+				require.Equal(t, tc.crv, jwk.Crv)
+
+				require.Len(t, doc.AssertionMethod, 1)
+				require.Equal(t, doc.PublicKey[0].ID, doc.AssertionMethod[0].PublicKey.ID)
+				require.Empty(t, doc.KeyAgreement)
+
+				docBytes, err := doc.JSONBytes()
+				require.NoError(t, err)
+
+				_, err = did.ParseDocument(docBytes)
+				require.NoError(t, err)
+			})
+		}
+	})
+
+	t.Run("resolve invalid EC key", func(t *testing.T) {
+		v := New()
+
+		methodID := keyFingerprint(multicodec(p256pub), []byte("invalid P-256 public key bytes!!!!"))
+
+		doc, err := v.Read("did:key:" + methodID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid P-256 public key")
+		require.Nil(t, doc)
+	})
 }
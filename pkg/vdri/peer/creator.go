@@ -63,13 +63,42 @@ func build(pubKey *vdriapi.PubKey, docOpts *vdriapi.CreateDIDOpts) (*did.Doc, er
 	// Created/Updated time
 	t := time.Now()
 
+	opts := []did.DocOption{
+		did.WithService(service),
+		did.WithCreatedTime(t),
+		did.WithUpdatedTime(t),
+	}
+
+	if docOpts.KeyAgreement != nil {
+		keyAgreement, err := keyAgreementMethod(docOpts.KeyAgreement)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, did.WithKeyAgreement([]did.VerificationMethod{*keyAgreement}))
+	}
+
 	return NewDoc(
 		[]did.PublicKey{publicKey},
 		[]did.VerificationMethod{
 			{PublicKey: publicKey},
 		},
-		did.WithService(service),
-		did.WithCreatedTime(t),
-		did.WithUpdatedTime(t),
+		opts...,
 	)
 }
+
+// keyAgreementMethod creates the embedded key agreement verification method of pubKey, using its JWK if set.
+func keyAgreementMethod(pubKey *vdriapi.PubKey) (*did.VerificationMethod, error) {
+	publicKey := did.NewPublicKeyFromBytes(pubKey.ID, pubKey.Type, "#id", base58.Decode(pubKey.Value))
+
+	if pubKey.JWK != nil {
+		var err error
+
+		publicKey, err = did.NewPublicKeyFromJWK(pubKey.ID, pubKey.Type, "#id", pubKey.JWK)
+		if err != nil {
+			return nil, fmt.Errorf("key agreement: %w", err)
+		}
+	}
+
+	return did.NewEmbeddedVerificationMethod(publicKey, did.KeyAgreement), nil
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	api "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
 	"github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 )
@@ -88,6 +90,60 @@ func TestBuild(t *testing.T) {
 	})
 }
 
+func TestDIDCreator_KeyAgreement(t *testing.T) {
+	c, err := New(&storage.MockStoreProvider{})
+	require.NoError(t, err)
+
+	x25519Key := make([]byte, 32)
+	_, err = rand.Read(x25519Key)
+	require.NoError(t, err)
+
+	t.Run("with JWK", func(t *testing.T) {
+		jwk, err := jose.JWKFromX25519Key(x25519Key)
+		require.NoError(t, err)
+
+		didDoc, err := c.Build(getSigningKey(), api.WithKeyAgreement(&api.PubKey{
+			ID:    "key1",
+			Value: base58.Encode(x25519Key),
+			Type:  "X25519KeyAgreementKey2019",
+			JWK:   jwk,
+		}))
+		require.NoError(t, err)
+		require.Len(t, didDoc.KeyAgreement, 1)
+		require.True(t, didDoc.KeyAgreement[0].Embedded)
+		require.Equal(t, "key1", didDoc.KeyAgreement[0].PublicKey.ID)
+		require.Equal(t, x25519Key, didDoc.KeyAgreement[0].PublicKey.Value)
+		require.Equal(t, jwk, didDoc.KeyAgreement[0].PublicKey.JSONWebKey())
+
+		docBytes, err := didDoc.JSONBytes()
+		require.NoError(t, err)
+
+		parsed, err := did.ParseDocument(docBytes)
+		require.NoError(t, err)
+		require.Equal(t, x25519Key, parsed.KeyAgreement[0].PublicKey.Value)
+	})
+
+	t.Run("without JWK", func(t *testing.T) {
+		didDoc, err := c.Build(getSigningKey(), api.WithKeyAgreement(&api.PubKey{
+			ID:    "key1",
+			Value: base58.Encode(x25519Key),
+			Type:  "X25519KeyAgreementKey2019",
+		}))
+		require.NoError(t, err)
+		require.Len(t, didDoc.KeyAgreement, 1)
+		require.Nil(t, didDoc.KeyAgreement[0].PublicKey.JSONWebKey())
+	})
+
+	t.Run("invalid JWK", func(t *testing.T) {
+		_, err := c.Build(getSigningKey(), api.WithKeyAgreement(&api.PubKey{
+			ID:  "key1",
+			JWK: &jose.JWK{},
+		}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create peer DID : key agreement")
+	})
+}
+
 func getSigningKey() *api.PubKey {
 	pub, _, err := ed25519.GenerateKey(rand.Reader)
 	if err != nil {
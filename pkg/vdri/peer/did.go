@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	multibase "github.com/multiformats/go-multibase"
 	multihash "github.com/multiformats/go-multihash"
@@ -114,10 +115,52 @@ func validateDID(doc *did.Doc) error {
 	return nil
 }
 
+// numBasisDoc is the document the numeric basis is computed from: the fields of the DID document known when the peer
+// DIDs were first computed, in their order, so that the fields added to the DID document since then don't change the
+// existing peer DIDs.
+type numBasisDoc struct {
+	Context              []string
+	ID                   string
+	PublicKey            []did.PublicKey
+	Service              []did.Service
+	Authentication       []did.VerificationMethod
+	AssertionMethod      []did.VerificationMethod
+	CapabilityDelegation []did.VerificationMethod
+	CapabilityInvocation []did.VerificationMethod
+	KeyAgreement         []did.VerificationMethod
+	Created              *time.Time
+	Updated              *time.Time
+	Proof                []numBasisProof
+}
+
+// numBasisProof is the proof of the numeric basis document, see numBasisDoc.
+type numBasisProof struct {
+	Type         string
+	Created      *time.Time
+	Creator      string
+	ProofValue   []byte
+	Domain       string
+	Nonce        []byte
+	ProofPurpose string
+}
+
 // calculateEncNumBasis is multicodec numeric basis.
 // Reference : https://openssi.github.io/peer-did-method-spec/index.html#dfn-multicodec-descriptor
 func calculateEncNumBasis(doc *did.Doc) (string, error) {
-	docBytes, err := json.Marshal(doc)
+	docBytes, err := json.Marshal(&numBasisDoc{
+		Context:              doc.Context,
+		ID:                   doc.ID,
+		PublicKey:            doc.PublicKey,
+		Service:              doc.Service,
+		Authentication:       doc.Authentication,
+		AssertionMethod:      doc.AssertionMethod,
+		CapabilityDelegation: doc.CapabilityDelegation,
+		CapabilityInvocation: doc.CapabilityInvocation,
+		KeyAgreement:         doc.KeyAgreement,
+		Created:              doc.Created,
+		Updated:              doc.Updated,
+		Proof:                numBasisProofs(doc.Proof),
+	})
 	if err != nil {
 		return "", err
 	}
@@ -131,3 +174,25 @@ func calculateEncNumBasis(doc *did.Doc) (string, error) {
 
 	return strings.Join(messageIdentifier, ""), nil
 }
+
+func numBasisProofs(proofs []did.Proof) []numBasisProof {
+	if proofs == nil {
+		return nil
+	}
+
+	result := make([]numBasisProof, len(proofs))
+
+	for i, p := range proofs {
+		result[i] = numBasisProof{
+			Type:         p.Type,
+			Created:      p.Created,
+			Creator:      p.Creator,
+			ProofValue:   p.ProofValue,
+			Domain:       p.Domain,
+			Nonce:        p.Nonce,
+			ProofPurpose: p.ProofPurpose,
+		}
+	}
+
+	return result
+}
@@ -24,6 +24,30 @@ func TestComputeDID(t *testing.T) {
 	require.Equal(t, "did:peer:1zQmPm3QtmoaidrLT6hBKR7wcepq4u6385ENbddYXLN3c2vx", peerDID)
 }
 
+func TestComputeDIDIgnoresNewDocFields(t *testing.T) {
+	storedDoc := genesisDoc()
+	storedDoc.Controller = []string{"did:example:controller"}
+	storedDoc.AlsoKnownAs = []string{"did:example:alias"}
+
+	peerDID, err := computeDid(storedDoc)
+	require.NoError(t, err)
+	require.Equal(t, "did:peer:1zQmPm3QtmoaidrLT6hBKR7wcepq4u6385ENbddYXLN3c2vx", peerDID)
+}
+
+func TestComputeDIDIgnoresProofVerificationMethod(t *testing.T) {
+	storedDoc := genesisDoc()
+	storedDoc.Proof = []did.Proof{{Type: "Ed25519Signature2018", Creator: "did:example:123#key-1"}}
+
+	peerDID, err := computeDid(storedDoc)
+	require.NoError(t, err)
+
+	storedDoc.Proof[0].VerificationMethod = "did:example:123#key-1"
+
+	withVerificationMethod, err := computeDid(storedDoc)
+	require.NoError(t, err)
+	require.Equal(t, peerDID, withVerificationMethod)
+}
+
 func TestComputeDIDError(t *testing.T) {
 	storedDoc := &did.Doc{ID: "did:peer:11"}
 	_, err := computeDid(storedDoc)
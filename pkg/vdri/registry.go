@@ -21,6 +21,10 @@ import (
 
 const (
 	defaultKeyType = "Ed25519VerificationKey2018"
+	didKeyMethod   = "key"
+
+	jsonWebKey2020            = "JsonWebKey2020"
+	x25519KeyAgreementKey2019 = "X25519KeyAgreementKey2019"
 )
 
 // Option is a vdri instance option
@@ -104,43 +108,105 @@ func (r *Registry) Create(didMethod string, opts ...vdriapi.DocOpts) (*diddoc.Do
 		id           string
 	)
 
-	if r.legacykms != nil {
-		_, base58PubKey, err = r.legacykms.CreateKeySet()
+	// a did:key with a key agreement key is built from the key agreement key alone, no signing key would be used
+	if didMethod != didKeyMethod || docOpts.KeyAgreementType == "" {
+		id, base58PubKey, err = r.createSigningKey()
 		if err != nil {
 			return nil, fmt.Errorf("failed to create DID: %w", err)
 		}
+	}
 
-		id = ""
-	} else {
-		id, _, err = r.kms.Create(kms.ED25519Type)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create DID: %w", err)
+	method, err := r.resolveVDRI(didMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	if docOpts.KeyAgreementType != "" {
+		keyAgreement, e := r.createKeyAgreementKey(docOpts.KeyAgreementType)
+		if e != nil {
+			return nil, fmt.Errorf("failed to create DID: %w", e)
 		}
 
-		pubKey, e := r.kms.ExportPubKeyBytes(id)
+		opts = append(opts, vdriapi.WithKeyAgreement(keyAgreement))
+	}
+
+	doc, err := method.Build(&vdriapi.PubKey{ID: id, Value: base58PubKey, Type: docOpts.KeyType},
+		r.applyDefaultDocOpts(docOpts, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.Store(doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// createSigningKey creates the DID signing key and returns its KMS key ID (empty for the legacy KMS) and its base58
+// encoded public key.
+func (r *Registry) createSigningKey() (string, string, error) {
+	if r.legacykms != nil {
+		_, base58PubKey, err := r.legacykms.CreateKeySet()
 		if err != nil {
-			return nil, fmt.Errorf("failed to create DID: %w", e)
+			return "", "", err
 		}
 
-		base58PubKey = base58.Encode(pubKey)
+		return "", base58PubKey, nil
 	}
 
-	method, err := r.resolveVDRI(didMethod)
+	id, _, err := r.kms.Create(kms.ED25519Type)
+	if err != nil {
+		return "", "", err
+	}
+
+	pubKey, err := r.kms.ExportPubKeyBytes(id)
+	if err != nil {
+		return "", "", err
+	}
+
+	return id, base58.Encode(pubKey), nil
+}
+
+// createKeyAgreementKey creates a key agreement key of type kt in the KMS and returns its public key as JWK.
+func (r *Registry) createKeyAgreementKey(kt kms.KeyType) (*vdriapi.PubKey, error) {
+	keyType := jsonWebKey2020
+
+	switch kt {
+	case kms.X25519ECDHKWType:
+		keyType = x25519KeyAgreementKey2019
+	case kms.NISTP256ECDHKWType, kms.NISTP384ECDHKWType:
+	default:
+		return nil, fmt.Errorf("key type '%s' is not a key agreement key type", kt)
+	}
+
+	if r.kms == nil {
+		return nil, errors.New("key agreement keys require a KMS")
+	}
+
+	keyID, _, err := r.kms.Create(kt)
 	if err != nil {
 		return nil, err
 	}
 
-	doc, err := method.Build(&vdriapi.PubKey{ID: id, Value: base58PubKey, Type: docOpts.KeyType},
-		r.applyDefaultDocOpts(docOpts, opts...)...)
+	pubKeyBytes, err := r.kms.ExportPubKeyBytes(keyID)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := r.Store(doc); err != nil {
+	jwk, err := kms.PubKeyBytesToJWK(pubKeyBytes, kt)
+	if err != nil {
 		return nil, err
 	}
 
-	return doc, nil
+	jwk.KeyID = keyID
+
+	rawPubKey, err := jwk.PublicKeyBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &vdriapi.PubKey{ID: keyID, Value: base58.Encode(rawPubKey), Type: keyType, JWK: jwk}, nil
 }
 
 // applyDefaultDocOpts applies default creator options to doc options
@@ -14,10 +14,16 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
 	mocklegacykms "github.com/hyperledger/aries-framework-go/pkg/mock/kms/legacykms"
 	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
 	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/key"
+	"github.com/hyperledger/aries-framework-go/pkg/vdri/peer"
 )
 
 func TestRegistry_New(t *testing.T) {
@@ -226,3 +232,97 @@ func TestRegistry_Create(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestRegistry_CreateWithKeyAgreement(t *testing.T) {
+	localKMS, err := localkms.New("local-lock://test/master/key/",
+		mockkms.NewProviderForKMS(mem.NewProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	peerVDRI, err := peer.New(mem.NewProvider())
+	require.NoError(t, err)
+
+	registry := New(&mockprovider.Provider{KMSValue: localKMS}, WithVDRI(peerVDRI), WithVDRI(key.New()))
+
+	t.Run("peer DID with X25519 key agreement key", func(t *testing.T) {
+		doc, err := registry.Create("peer", vdriapi.WithKeyAgreementType(kms.X25519ECDHKWType))
+		require.NoError(t, err)
+		require.Len(t, doc.KeyAgreement, 1)
+		require.True(t, doc.KeyAgreement[0].Embedded)
+
+		keyAgreement := doc.KeyAgreement[0].PublicKey
+		require.Equal(t, "X25519KeyAgreementKey2019", keyAgreement.Type)
+		require.Equal(t, "X25519", keyAgreement.JSONWebKey().Crv)
+		require.Equal(t, keyAgreement.ID, keyAgreement.JSONWebKey().KeyID)
+
+		// the key agreement key is in the KMS
+		_, err = localKMS.Get(keyAgreement.ID)
+		require.NoError(t, err)
+
+		resolved, err := registry.Resolve(doc.ID)
+		require.NoError(t, err)
+		require.Len(t, resolved.KeyAgreement, 1)
+		require.Equal(t, keyAgreement.Value, resolved.KeyAgreement[0].PublicKey.Value)
+	})
+
+	t.Run("did:key with P-256 key agreement key", func(t *testing.T) {
+		doc, err := registry.Create("key", vdriapi.WithKeyAgreementType(kms.NISTP256ECDHKWType))
+		require.NoError(t, err)
+		require.Len(t, doc.KeyAgreement, 1)
+		require.Equal(t, "JsonWebKey2020", doc.KeyAgreement[0].PublicKey.Type)
+		require.Equal(t, "P-256", doc.KeyAgreement[0].PublicKey.JSONWebKey().Crv)
+	})
+
+	t.Run("did:key with X25519 key agreement key", func(t *testing.T) {
+		doc, err := registry.Create("key", vdriapi.WithKeyAgreementType(kms.X25519ECDHKWType))
+		require.NoError(t, err)
+		require.Len(t, doc.KeyAgreement, 1)
+		require.Equal(t, "X25519KeyAgreementKey2019", doc.KeyAgreement[0].PublicKey.Type)
+	})
+
+	t.Run("did:key with key agreement key creates no signing key", func(t *testing.T) {
+		km := &keyTypesKMS{KeyManager: localKMS}
+		registry := New(&mockprovider.Provider{KMSValue: km}, WithVDRI(peerVDRI), WithVDRI(key.New()))
+
+		_, err := registry.Create("key", vdriapi.WithKeyAgreementType(kms.X25519ECDHKWType))
+		require.NoError(t, err)
+		require.Equal(t, []kms.KeyType{kms.X25519ECDHKWType}, km.created)
+
+		km.created = nil
+
+		_, err = registry.Create("peer", vdriapi.WithKeyAgreementType(kms.X25519ECDHKWType))
+		require.NoError(t, err)
+		require.Equal(t, []kms.KeyType{kms.ED25519Type, kms.X25519ECDHKWType}, km.created)
+	})
+
+	t.Run("unsupported key agreement key type", func(t *testing.T) {
+		_, err := registry.Create("peer", vdriapi.WithKeyAgreementType(kms.ED25519Type))
+		require.EqualError(t, err, "failed to create DID: key type 'ED25519' is not a key agreement key type")
+	})
+
+	t.Run("key agreement key without KMS", func(t *testing.T) {
+		registry := New(&mockprovider.Provider{LegacyKMSValue: &mocklegacykms.CloseableKMS{}}, WithVDRI(peerVDRI))
+
+		_, err := registry.Create("peer", vdriapi.WithKeyAgreementType(kms.X25519ECDHKWType))
+		require.EqualError(t, err, "failed to create DID: key agreement keys require a KMS")
+	})
+
+	t.Run("error from create key agreement key", func(t *testing.T) {
+		registry := New(&mockprovider.Provider{KMSValue: &mockkms.KeyManager{}}, WithVDRI(peerVDRI))
+
+		_, err := registry.Create("peer", vdriapi.WithKeyAgreementType(kms.X25519ECDHKWType))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create DID: pubKeyBytesToJWK")
+	})
+}
+
+// keyTypesKMS records the types of the keys created in the KMS.
+type keyTypesKMS struct {
+	kms.KeyManager
+	created []kms.KeyType
+}
+
+func (k *keyTypesKMS) Create(kt kms.KeyType) (string, interface{}, error) {
+	k.created = append(k.created, kt)
+
+	return k.KeyManager.Create(kt)
+}
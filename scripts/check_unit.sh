@@ -30,6 +30,20 @@ remove_docker_container
 
 docker run -p 5984:5984 -d --name CouchDBStoreTest couchdb:2.3.1 >/dev/null || true
 
+# PKCS#11 KMS tests run against SoftHSM when it is installed
+if [ -z "$PKCS11_LIB" ] && command -v softhsm2-util >/dev/null; then
+  SOFTHSM2_CONF=$(mktemp -d)/softhsm2.conf
+  mkdir -p "$(dirname "$SOFTHSM2_CONF")/tokens"
+  echo "directories.tokendir = $(dirname "$SOFTHSM2_CONF")/tokens" > "$SOFTHSM2_CONF"
+  export SOFTHSM2_CONF
+  softhsm2-util --init-token --free --label aries --pin 1234 --so-pin 1234 >/dev/null
+  for lib in /usr/lib/softhsm/libsofthsm2.so /usr/local/lib/softhsm/libsofthsm2.so; do
+    if [ -f "$lib" ]; then
+      export PKCS11_LIB=$lib
+    fi
+  done
+fi
+
 
 # Running aries-framework-go unit test
 PKGS=`go list github.com/hyperledger/aries-framework-go/... 2> /dev/null | \